@@ -0,0 +1,76 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/server"
+)
+
+// demoConfigName identifies the embedded demo configuration in logs and errors
+const demoConfigName = "examples/demo.yaml"
+
+// demoConfig is the configuration served by the demo command
+//
+//go:embed examples/demo.yaml
+var demoConfig []byte
+
+// createDemoCommand builds the command serving the built-in demo
+// configuration, so mockingjay can be explored without writing one first
+func createDemoCommand() *cobra.Command {
+	var port string
+	var debug, printConfig bool
+
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Start a server with a built-in example configuration",
+		Long: `Start a server with a built-in example configuration showing regex routes,
+header matching, fake data, delays and response headers. Open the server's
+root path for the list of routes to try, and print the configuration with
+--print to use it as a starting point for your own.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if printConfig {
+				_, err := cmd.OutOrStdout().Write(demoConfig)
+				return err
+			}
+			return runDemo(port, debug)
+		},
+	}
+
+	cmd.Flags().StringVarP(&port, "port", "p", "8080", "server port")
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "enable debug logging")
+	cmd.Flags().BoolVarP(&printConfig, "print", "", false, "print the demo configuration and exit")
+
+	return cmd
+}
+
+// runDemo serves the demo configuration on port until interrupted
+func runDemo(port string, debug bool) error {
+	level := new(slog.LevelVar)
+	logger := setupLogger(level, debug)
+
+	cfg, err := config.ParseConfig(demoConfigName, demoConfig)
+	if err != nil {
+		logger.Error("failed to load demo configuration", "error", err)
+		return err
+	}
+
+	addr := ":" + port
+	srv, err := server.NewServer(cfg, nil, addr, logger, version)
+	if err != nil {
+		logger.Error("failed to create server", "error", err)
+		return err
+	}
+
+	srv.SetLogLevelVar(level)
+
+	fmt.Printf("🐦 Serving the demo configuration, open http://localhost:%s/ to get started\n", port)
+
+	// The embedded configuration has no file to watch for changes
+	return serve(srv, logger, addr, false)
+}
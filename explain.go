@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// createExplainCommand builds the command describing how routes were
+// compiled, for reviewing configurations
+func createExplainCommand() *cobra.Command {
+	var configFiles []string
+	var route string
+
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Describe how the configured routes are matched and what their templates use",
+		Long: `Describe how each configured route was compiled: the regex its path compiles
+to and its named groups, the headers, cookies and other matchers requests must
+satisfy, and the template context fields and functions its templates use.
+
+Pick routes with --route, giving a method and either the path as written in
+the configuration or a request path the route matches, like "GET /users/42".
+The method can be left out to pick routes of any method.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := explainRoutes(cmd.OutOrStdout(), configFiles, route); err != nil {
+				// Errors are silenced by the root command, which logs its own
+				fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&configFiles, "config", "c", []string{"config.yaml"}, "path to a configuration file or directory, can be repeated")
+	cmd.Flags().StringVarP(&route, "route", "r", "", `route to describe, like "GET /users/42", instead of every route`)
+
+	return cmd
+}
+
+// explainRoutes compiles the routes of the configuration files and describes
+// those selected by spec, or all of them when it's empty
+func explainRoutes(w io.Writer, configFiles []string, spec string) error {
+	cfg, err := config.LoadConfigs(configFiles)
+	if err != nil {
+		return err
+	}
+
+	compiler, err := router.NewCompilerWithConfig(cfg)
+	if err != nil {
+		return err
+	}
+	routes, err := compiler.CompileRoutes(cfg.Routes)
+	if err != nil {
+		return err
+	}
+
+	// Explain routes in the order requests are matched against them, so the
+	// first route explained for a path is the one answering it
+	index := make(map[*router.Route]int, len(routes))
+	for i, route := range routes {
+		index[route] = i
+	}
+
+	method, path := parseRouteSpec(spec)
+	explained := 0
+	for _, route := range router.MatchOrder(routes, cfg.Server.MatchesSpecific()) {
+		i := index[route]
+		if spec != "" && !selectsRoute(route, method, path) {
+			continue
+		}
+		if explained > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := explainRoute(w, i, route, cfg.Routes[i]); err != nil {
+			return err
+		}
+		explained++
+	}
+
+	if explained == 0 && spec != "" {
+		return fmt.Errorf("no route matches %q", spec)
+	}
+	return nil
+}
+
+// parseRouteSpec splits a route given as "METHOD PATH", or as a path alone
+// for any method
+func parseRouteSpec(spec string) (string, string) {
+	method, path, ok := strings.Cut(strings.TrimSpace(spec), " ")
+	if !ok {
+		return "", method
+	}
+	return strings.ToUpper(method), strings.TrimSpace(path)
+}
+
+// selectsRoute reports whether a route has the given method, or any method
+// when it's empty, and either is written as path or matches it
+func selectsRoute(route *router.Route, method, path string) bool {
+	if method != "" && route.Method != method {
+		return false
+	}
+	return route.Pattern == path || route.MatchesPath(path)
+}
+
+// explainRoute describes a compiled route, the index-th of the configuration
+func explainRoute(w io.Writer, index int, route *router.Route, rc config.RouteConfig) error {
+	fmt.Fprintf(w, "%s %s\n", route.Method, route.Pattern)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "  %s:\t%s\n", label, value)
+		}
+	}
+
+	line("Route", fmt.Sprintf("routes[%d]", index))
+	if route.IsRegexp {
+		line("Regex", route.Regex.String())
+		var groups []string
+		for _, name := range route.Regex.SubexpNames() {
+			if name != "" {
+				groups = append(groups, name)
+			}
+		}
+		line("Named groups", strings.Join(groups, ", "))
+	} else {
+		line("Path", "matched exactly")
+	}
+	if route.Priority != 0 {
+		line("Priority", strconv.Itoa(route.Priority))
+	}
+	line("Responds with", route.TemplateSource)
+
+	for _, name := range slices.Sorted(maps.Keys(route.MatchHeaders)) {
+		line("Header "+name, route.MatchHeaders[name].String())
+	}
+	for _, name := range slices.Sorted(maps.Keys(route.MatchCookies)) {
+		line("Cookie "+name, route.MatchCookies[name].String())
+	}
+	if route.MatchSNI != nil {
+		line("Server name", route.MatchSNI.String())
+	}
+	if cert := route.MatchClientCert; cert != nil {
+		if cert.CN != nil {
+			line("Client cert CN", cert.CN.String())
+		}
+		if cert.SAN != nil {
+			line("Client cert SAN", cert.SAN.String())
+		}
+	}
+	line("Matchers", strings.Join(slices.Sorted(maps.Keys(rc.Match)), ", "))
+
+	usage := templatepkg.Inspect(route.Templates()...)
+	line("Context fields", strings.Join(usage.Fields, ", "))
+	line("Functions", strings.Join(usage.Functions, ", "))
+
+	return tw.Flush()
+}
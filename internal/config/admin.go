@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Scopes admin API tokens can be granted
+const (
+	AdminScopeRead   = "read"   // List state and reports, like routes, requests and metrics
+	AdminScopeMutate = "mutate" // Change state, like creating runtime routes or setting dependency statuses
+	AdminScopeReset  = "reset"  // Clear state, like the request journal, scenarios or call counts
+)
+
+// AdminScopes lists the scopes admin API tokens can be granted
+var AdminScopes = []string{AdminScopeRead, AdminScopeMutate, AdminScopeReset}
+
+// AdminConfig protects the admin API, which is open to anyone reaching the
+// server unless tokens are defined
+type AdminConfig struct {
+	Tokens []AdminTokenConfig `yaml:"tokens,omitempty"` // Bearer tokens accepted by the admin API, with what they can do
+}
+
+// AdminTokenConfig is a bearer token accepted by the admin API, granting the
+// scopes it lists
+type AdminTokenConfig struct {
+	Name     string   `yaml:"name"`                // Identifies the token in logs, e.g. "ci"
+	Token    string   `yaml:"token,omitempty"`     // The token itself
+	TokenEnv string   `yaml:"token_env,omitempty"` // Environment variable holding the token, instead of token
+	Scopes   []string `yaml:"scopes"`              // "read", "mutate" and "reset"
+}
+
+// Validate validates the admin API configuration
+func (ac *AdminConfig) Validate() error {
+	names := make(map[string]bool, len(ac.Tokens))
+	tokens := make(map[string]string, len(ac.Tokens))
+
+	for i, tc := range ac.Tokens {
+		field := fmt.Sprintf("admin.tokens[%d]", i)
+
+		if strings.TrimSpace(tc.Name) == "" {
+			return NewValidationError(field+".name", "token name cannot be empty")
+		}
+		if names[tc.Name] {
+			return NewValidationError(field+".name", fmt.Sprintf("duplicate token name %q", tc.Name))
+		}
+		names[tc.Name] = true
+
+		if (tc.Token == "") == (tc.TokenEnv == "") {
+			return NewValidationError(field, "exactly one of 'token' or 'token_env' must be set")
+		}
+		token, err := tc.GetToken()
+		if err != nil {
+			return NewValidationError(field+".token_env", err.Error())
+		}
+		if other, ok := tokens[token]; ok {
+			return NewValidationError(field+".token", fmt.Sprintf("token %q uses the same value as token %q", tc.Name, other))
+		}
+		tokens[token] = tc.Name
+
+		if len(tc.Scopes) == 0 {
+			return NewValidationError(field+".scopes", "at least one scope must be granted")
+		}
+		for _, scope := range tc.Scopes {
+			if !slices.Contains(AdminScopes, scope) {
+				return NewValidationError(field+".scopes", fmt.Sprintf("invalid scope %q, must be one of: %s", scope, strings.Join(AdminScopes, ", ")))
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetToken returns the value of the token, reading it from the environment
+// when it's given through token_env
+func (tc *AdminTokenConfig) GetToken() (string, error) {
+	if tc.TokenEnv == "" {
+		return tc.Token, nil
+	}
+
+	token := os.Getenv(tc.TokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("environment variable %q is not set or empty", tc.TokenEnv)
+	}
+	return token, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdminConfig_Validate(t *testing.T) {
+	t.Setenv("TEST_ADMIN_TOKEN", "from-env")
+
+	tests := []struct {
+		name        string
+		admin       AdminConfig
+		errContains string
+	}{
+		{name: "no tokens", admin: AdminConfig{}},
+		{
+			name: "tokens",
+			admin: AdminConfig{Tokens: []AdminTokenConfig{
+				{Name: "ci", Token: "ci-secret", Scopes: []string{AdminScopeRead, AdminScopeReset}},
+				{Name: "ops", TokenEnv: "TEST_ADMIN_TOKEN", Scopes: AdminScopes},
+			}},
+		},
+		{
+			name:        "missing name",
+			admin:       AdminConfig{Tokens: []AdminTokenConfig{{Token: "secret", Scopes: []string{AdminScopeRead}}}},
+			errContains: "token name cannot be empty",
+		},
+		{
+			name: "duplicate name",
+			admin: AdminConfig{Tokens: []AdminTokenConfig{
+				{Name: "ci", Token: "one", Scopes: []string{AdminScopeRead}},
+				{Name: "ci", Token: "two", Scopes: []string{AdminScopeRead}},
+			}},
+			errContains: `duplicate token name "ci"`,
+		},
+		{
+			name: "duplicate value",
+			admin: AdminConfig{Tokens: []AdminTokenConfig{
+				{Name: "ci", Token: "from-env", Scopes: []string{AdminScopeRead}},
+				{Name: "ops", TokenEnv: "TEST_ADMIN_TOKEN", Scopes: []string{AdminScopeRead}},
+			}},
+			errContains: `token "ops" uses the same value as token "ci"`,
+		},
+		{
+			name:        "no token",
+			admin:       AdminConfig{Tokens: []AdminTokenConfig{{Name: "ci", Scopes: []string{AdminScopeRead}}}},
+			errContains: "exactly one of 'token' or 'token_env'",
+		},
+		{
+			name:        "token and token_env",
+			admin:       AdminConfig{Tokens: []AdminTokenConfig{{Name: "ci", Token: "secret", TokenEnv: "TEST_ADMIN_TOKEN", Scopes: []string{AdminScopeRead}}}},
+			errContains: "exactly one of 'token' or 'token_env'",
+		},
+		{
+			name:        "unset environment variable",
+			admin:       AdminConfig{Tokens: []AdminTokenConfig{{Name: "ci", TokenEnv: "TEST_ADMIN_TOKEN_UNSET", Scopes: []string{AdminScopeRead}}}},
+			errContains: `environment variable "TEST_ADMIN_TOKEN_UNSET" is not set`,
+		},
+		{
+			name:        "no scopes",
+			admin:       AdminConfig{Tokens: []AdminTokenConfig{{Name: "ci", Token: "secret"}}},
+			errContains: "at least one scope",
+		},
+		{
+			name:        "invalid scope",
+			admin:       AdminConfig{Tokens: []AdminTokenConfig{{Name: "ci", Token: "secret", Scopes: []string{"write"}}}},
+			errContains: `invalid scope "write"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.admin.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
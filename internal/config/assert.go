@@ -0,0 +1,36 @@
+package config
+
+// AssertResponseConfig sets checks the rendered body of a route must pass
+// before it's sent, failing the request with a 500 otherwise, so template
+// bugs surface instead of feeding garbage to the client under test
+type AssertResponseConfig struct {
+	JSONValid bool `yaml:"json_valid,omitempty"` // The body must be valid JSON
+	MaxBytes  int  `yaml:"max_bytes,omitempty"`  // The body can't be larger than this (0 for no limit)
+}
+
+// validateAssertResponse validates the checks of a route's rendered bodies
+func (r *RouteConfig) validateAssertResponse() error {
+	if r.AssertResponse == nil {
+		return nil
+	}
+
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil || r.StaticDir != nil || r.Raw != nil {
+		return NewValidationError("assert_response", "'assert_response' cannot be combined with 'proxy', 'batch', 'websocket', 'static_dir' or 'raw'")
+	}
+	if r.Stream != nil {
+		return NewValidationError("assert_response", "'assert_response' cannot be combined with 'stream', since headers are sent before the body is known")
+	}
+
+	return r.AssertResponse.Validate()
+}
+
+// Validate validates an AssertResponseConfig
+func (a *AssertResponseConfig) Validate() error {
+	if a.MaxBytes < 0 {
+		return NewValidationError("assert_response.max_bytes", "cannot be negative")
+	}
+	if !a.JSONValid && a.MaxBytes == 0 {
+		return NewValidationError("assert_response", "at least one of 'json_valid' or 'max_bytes' must be set")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateAssertResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "json and size - valid",
+			route: RouteConfig{Path: "/users", Method: "GET", Template: "{}", AssertResponse: &AssertResponseConfig{JSONValid: true, MaxBytes: 1024}},
+		},
+		{
+			name:  "size only - valid",
+			route: RouteConfig{Path: "/users", Method: "GET", Template: "{}", AssertResponse: &AssertResponseConfig{MaxBytes: 1024}},
+		},
+		{
+			name:        "no checks - invalid",
+			route:       RouteConfig{Path: "/users", Method: "GET", Template: "{}", AssertResponse: &AssertResponseConfig{}},
+			errContains: "at least one of 'json_valid' or 'max_bytes' must be set",
+		},
+		{
+			name:        "negative size - invalid",
+			route:       RouteConfig{Path: "/users", Method: "GET", Template: "{}", AssertResponse: &AssertResponseConfig{MaxBytes: -1}},
+			errContains: `validation error in field "assert_response.max_bytes": cannot be negative`,
+		},
+		{
+			name:        "with stream - invalid",
+			route:       RouteConfig{Path: "/users", Method: "GET", Template: "{}", Stream: &StreamConfig{}, AssertResponse: &AssertResponseConfig{JSONValid: true}},
+			errContains: "'assert_response' cannot be combined with 'stream'",
+		},
+		{
+			name:        "with static dir - invalid",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: "."}, AssertResponse: &AssertResponseConfig{JSONValid: true}},
+			errContains: "'assert_response' cannot be combined with",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBatchMaxRequests is the default maximum number of sub-requests in
+// one batch
+const DefaultBatchMaxRequests = 20
+
+// BatchConfig turns a route into a batch endpoint, which splits JSON or
+// multipart/mixed batch requests into sub-requests, serves each of them with
+// the other routes and answers with the combined responses
+type BatchConfig struct {
+	MaxRequests int `yaml:"max_requests,omitempty"` // Most sub-requests in one batch (default: 20)
+}
+
+// validateBatch validates the batch settings of a route
+func (r *RouteConfig) validateBatch() error {
+	if r.Batch == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(r.Template) != "" || strings.TrimSpace(r.TemplateFile) != "" || len(r.Responses) > 0 || r.Sequence != nil {
+		return NewValidationError("batch", "'batch' cannot be combined with 'template', 'template_file', 'responses' or 'sequence'")
+	}
+
+	if r.Proxy != nil {
+		return NewValidationError("batch", "'batch' cannot be combined with 'proxy'")
+	}
+
+	if len(r.Faults) > 0 {
+		return NewValidationError("batch", "'batch' cannot be combined with 'faults'")
+	}
+
+	if len(r.ResponseHeaders) > 0 {
+		return NewValidationError("batch", "'batch' cannot be combined with 'response_headers', sub-responses carry their own headers")
+	}
+
+	if r.RequireToken != nil || r.Transaction != nil {
+		return NewValidationError("batch", "'batch' cannot be combined with 'require_token' or 'transaction', set them on the routes serving the sub-requests")
+	}
+
+	if r.ClockSkew != nil {
+		return NewValidationError("batch", "'batch' cannot be combined with 'clock_skew', sub-responses use the clock of their routes")
+	}
+
+	return r.Batch.Validate()
+}
+
+// Validate validates a BatchConfig
+func (b *BatchConfig) Validate() error {
+	if b.MaxRequests < 0 {
+		return NewValidationError("batch.max_requests", fmt.Sprintf("maximum number of sub-requests cannot be negative, got %d", b.MaxRequests))
+	}
+	return nil
+}
+
+// GetMaxRequests returns the maximum number of sub-requests in one batch,
+// using the default when unset
+func (b *BatchConfig) GetMaxRequests() int {
+	if b.MaxRequests == 0 {
+		return DefaultBatchMaxRequests
+	}
+	return b.MaxRequests
+}
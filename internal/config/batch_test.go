@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouteConfig_ValidateBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "batch route - valid",
+			route: RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}},
+		},
+		{
+			name:  "batch with delay - valid",
+			route: RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{MaxRequests: 50}, Delay: &DelayConfig{Min: time.Millisecond, Max: time.Millisecond}},
+		},
+		{
+			name:        "batch with template - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}, Template: "{}"},
+			errContains: "cannot be combined with 'template'",
+		},
+		{
+			name:        "batch with proxy - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}, Proxy: &ProxyConfig{URL: "http://localhost:9000"}},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "batch with faults - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}, Faults: []FaultConfig{{Type: "error"}}},
+			errContains: "cannot be combined with 'faults'",
+		},
+		{
+			name:        "batch with response headers - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}, ResponseHeaders: map[string]string{"X-Mock": "true"}},
+			errContains: "response_headers",
+		},
+		{
+			name:        "batch with token requirement - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}, RequireToken: &RequireTokenConfig{Name: "access"}},
+			errContains: "require_token",
+		},
+		{
+			name:        "batch with clock skew - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{}, ClockSkew: new(time.Duration)},
+			errContains: "clock_skew",
+		},
+		{
+			name:        "negative maximum - invalid",
+			route:       RouteConfig{Path: "/$batch", Method: "POST", Batch: &BatchConfig{MaxRequests: -1}},
+			errContains: "cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestBatchConfig_GetMaxRequests(t *testing.T) {
+	if got := (&BatchConfig{}).GetMaxRequests(); got != DefaultBatchMaxRequests {
+		t.Errorf("Expected default of %d requests, got %d", DefaultBatchMaxRequests, got)
+	}
+	if got := (&BatchConfig{MaxRequests: 5}).GetMaxRequests(); got != 5 {
+		t.Errorf("Expected 5 requests, got %d", got)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import "fmt"
+
+// BodyEncodingBase64 decodes rendered bodies from base64 before they're
+// written, so templates can produce binary payloads
+const BodyEncodingBase64 = "base64"
+
+// validateBodyEncoding validates the encoding a route's rendered bodies are
+// decoded from
+func (r *RouteConfig) validateBodyEncoding() error {
+	if r.BodyEncoding == "" {
+		return nil
+	}
+
+	if r.BodyEncoding != BodyEncodingBase64 {
+		return NewValidationError("body_encoding", fmt.Sprintf("invalid encoding %q, must be %q", r.BodyEncoding, BodyEncodingBase64))
+	}
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil {
+		return NewValidationError("body_encoding", "'body_encoding' cannot be combined with 'proxy', 'batch' or 'websocket'")
+	}
+	if r.EchoBody != nil || r.BodyFile != "" || r.StaticDir != nil {
+		return NewValidationError("body_encoding", "'body_encoding' only applies to templates, and cannot be combined with 'echo_body', 'body_file' or 'static_dir'")
+	}
+	if r.Stream != nil {
+		return NewValidationError("body_encoding", "'body_encoding' cannot be combined with 'stream', since bodies are decoded once fully rendered")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateBodyEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "base64",
+			route: RouteConfig{Path: "/avatar", Method: "GET", Template: "{{ fakeImagePNG 10 10 | b64enc }}", BodyEncoding: BodyEncodingBase64},
+		},
+		{
+			name:  "base64 responses",
+			route: RouteConfig{Path: "/avatar", Method: "GET", Responses: []ResponseConfig{{Template: "iVBORw0KGgo="}}, BodyEncoding: BodyEncodingBase64},
+		},
+		{
+			name:        "unknown encoding",
+			route:       RouteConfig{Path: "/avatar", Method: "GET", Template: "x", BodyEncoding: "hex"},
+			errContains: `invalid encoding "hex", must be "base64"`,
+		},
+		{
+			name:        "with proxy",
+			route:       RouteConfig{Path: "/avatar", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, BodyEncoding: BodyEncodingBase64},
+			errContains: "'body_encoding' cannot be combined with 'proxy', 'batch' or 'websocket'",
+		},
+		{
+			name:        "with echo_body",
+			route:       RouteConfig{Path: "/avatar", Method: "POST", EchoBody: &EchoBodyConfig{}, BodyEncoding: BodyEncodingBase64},
+			errContains: "'body_encoding' only applies to templates",
+		},
+		{
+			name:        "with stream",
+			route:       RouteConfig{Path: "/avatar", Method: "GET", Template: "x", Stream: &StreamConfig{}, BodyEncoding: BodyEncodingBase64},
+			errContains: "'body_encoding' cannot be combined with 'stream'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
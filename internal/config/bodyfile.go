@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// validateBodyFile validates a route serving a file as-is, which answers
+// with the file's bytes instead of templates
+func (r *RouteConfig) validateBodyFile() error {
+	if strings.TrimSpace(r.Template) != "" || strings.TrimSpace(r.TemplateFile) != "" {
+		return NewValidationError("body_file", "'body_file' cannot be combined with 'template' or 'template_file'")
+	}
+	if len(r.Responses) > 0 || r.Variants != nil || r.Stream != nil {
+		return NewValidationError("body_file", "'body_file' cannot be combined with 'responses', 'variants' or 'stream'")
+	}
+
+	info, err := os.Stat(r.BodyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewValidationError("body_file", fmt.Sprintf("body file %q does not exist", r.BodyFile))
+		}
+		return NewValidationError("body_file", fmt.Sprintf("cannot access body file %q: %v", r.BodyFile, err))
+	}
+	if info.IsDir() {
+		return NewValidationError("body_file", fmt.Sprintf("body file %q is a directory", r.BodyFile))
+	}
+	return nil
+}
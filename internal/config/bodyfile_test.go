@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateBodyFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(file, []byte("\x89PNG\r\n\x1a\n{{"), 0o644); err != nil {
+		t.Fatalf("Failed to write body file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "valid",
+			route: RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: file},
+		},
+		{
+			name:  "with response headers",
+			route: RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: file, ResponseHeaders: map[string]string{"Cache-Control": "max-age=60"}},
+		},
+		{
+			name:        "missing file",
+			route:       RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: filepath.Join(dir, "missing.png")},
+			errContains: "does not exist",
+		},
+		{
+			name:        "directory",
+			route:       RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: dir},
+			errContains: "is a directory",
+		},
+		{
+			name:        "with template",
+			route:       RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: file, Template: "logo"},
+			errContains: "'body_file' cannot be combined with 'template' or 'template_file'",
+		},
+		{
+			name:        "with responses",
+			route:       RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: file, Responses: []ResponseConfig{{Template: "logo"}}},
+			errContains: "'body_file' cannot be combined with 'responses', 'variants' or 'stream'",
+		},
+		{
+			name:        "with echo_body",
+			route:       RouteConfig{Path: "/logo.png", Method: "GET", BodyFile: file, EchoBody: &EchoBodyConfig{}},
+			errContains: "'body_file' cannot be combined with 'proxy', 'batch', 'websocket' or 'echo_body'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Checksum algorithms of response bodies
+const (
+	ChecksumMD5    = "md5"
+	ChecksumSHA1   = "sha1"
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+	ChecksumCRC32  = "crc32"
+	ChecksumCRC32C = "crc32c"
+)
+
+// ChecksumAlgorithms lists the supported checksum algorithms
+var ChecksumAlgorithms = []string{ChecksumMD5, ChecksumSHA1, ChecksumSHA256, ChecksumSHA512, ChecksumCRC32, ChecksumCRC32C}
+
+// validateChecksums validates the checksums a route sends of its bodies
+func (r *RouteConfig) validateChecksums() error {
+	if len(r.EmitChecksums) == 0 {
+		return nil
+	}
+
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil || r.StaticDir != nil || r.Raw != nil {
+		return NewValidationError("emit_checksums", "'emit_checksums' cannot be combined with 'proxy', 'batch', 'websocket', 'static_dir' or 'raw'")
+	}
+	if r.Stream != nil {
+		return NewValidationError("emit_checksums", "'emit_checksums' cannot be combined with 'stream', since headers are sent before the body is known")
+	}
+
+	for i, algorithm := range r.EmitChecksums {
+		if !slices.Contains(ChecksumAlgorithms, algorithm) {
+			return NewValidationError(fmt.Sprintf("emit_checksums[%d]", i), fmt.Sprintf("unknown algorithm %q, must be one of: %s", algorithm, strings.Join(ChecksumAlgorithms, ", ")))
+		}
+		if slices.Index(r.EmitChecksums, algorithm) != i {
+			return NewValidationError(fmt.Sprintf("emit_checksums[%d]", i), fmt.Sprintf("algorithm %q is listed more than once", algorithm))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateChecksums(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "algorithms - valid",
+			route: RouteConfig{Template: "ok", EmitChecksums: []string{"md5", "crc32c", "sha256"}},
+		},
+		{
+			name:  "body file - valid",
+			route: RouteConfig{BodyFile: "checksums_test.go", EmitChecksums: []string{"sha512"}},
+		},
+		{
+			name:        "unknown algorithm - invalid",
+			route:       RouteConfig{Template: "ok", EmitChecksums: []string{"sha256", "blake3"}},
+			errContains: `"emit_checksums[1]": unknown algorithm "blake3"`,
+		},
+		{
+			name:        "repeated algorithm - invalid",
+			route:       RouteConfig{Template: "ok", EmitChecksums: []string{"md5", "md5"}},
+			errContains: `algorithm "md5" is listed more than once`,
+		},
+		{
+			name:        "stream - invalid",
+			route:       RouteConfig{Template: "ok", Stream: &StreamConfig{}, EmitChecksums: []string{"md5"}},
+			errContains: "'emit_checksums' cannot be combined with 'stream'",
+		},
+		{
+			name:        "proxy - invalid",
+			route:       RouteConfig{Proxy: &ProxyConfig{URL: "http://localhost:9000"}, EmitChecksums: []string{"md5"}},
+			errContains: "'emit_checksums' cannot be combined with 'proxy'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := tt.route
+			route.Path, route.Method = "/object", "GET"
+
+			err := route.validateChecksums()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
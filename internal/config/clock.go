@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetClockTime returns the time the mock's clock is frozen at, or the zero
+// time when it follows the real one
+func (s ServerConfig) GetClockTime() (time.Time, error) {
+	if s.ClockTime == "" {
+		return time.Time{}, nil
+	}
+	return ParseClockTime(s.ClockTime)
+}
+
+// ParseClockTime parses a time to freeze the mock's clock at, either a date
+// or an RFC 3339 timestamp. Dates without a time refer to the start of the
+// day, UTC.
+func ParseClockTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, must be a date like \"2024-01-01\" or an RFC 3339 timestamp", value)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerConfig_GetClockTime(t *testing.T) {
+	tests := []struct {
+		clockTime string
+		want      time.Time
+		wantErr   bool
+	}{
+		{clockTime: "", want: time.Time{}},
+		{clockTime: "2030-01-01", want: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{clockTime: "2030-01-01T09:30:00-05:00", want: time.Date(2030, time.January, 1, 14, 30, 0, 0, time.UTC)},
+		{clockTime: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ServerConfig{ClockTime: tt.clockTime}.GetClockTime()
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("GetClockTime(%q) = %v, %v, want %v", tt.clockTime, got, err, tt.want)
+		}
+	}
+}
+
+func TestConfig_ValidateClockTime(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{ClockTime: "2030-13-01"},
+		Routes: []RouteConfig{{Path: "/", Method: "GET", Template: "ok"}},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server.clock_time") {
+		t.Errorf("Expected a server.clock_time error, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compression encodings
+const (
+	CompressionGzip    = "gzip"    // gzip, as described in RFC 1952
+	CompressionDeflate = "deflate" // zlib-wrapped deflate, as described in RFC 1950
+)
+
+// Compression negotiation violations, reproducing servers that get content
+// negotiation wrong
+const (
+	CompressionMislabel     = "mislabel"      // Send Content-Encoding without compressing the body
+	CompressionUnlabeled    = "unlabeled"     // Compress the body without sending Content-Encoding
+	CompressionIgnoreAccept = "ignore_accept" // Compress even when Accept-Encoding doesn't allow it
+	CompressionDouble       = "double"        // Compress the body twice, declaring it once
+)
+
+// CompressionConfig compresses a route's responses for clients accepting the
+// encoding, or breaks content negotiation on purpose to test how clients cope
+// with broken servers
+type CompressionConfig struct {
+	Encoding  string `yaml:"encoding,omitempty"`  // "gzip" (default) or "deflate"
+	Violation string `yaml:"violation,omitempty"` // How negotiation is broken, empty to follow it
+}
+
+// validateCompression validates the compression settings of a route
+func (r *RouteConfig) validateCompression() error {
+	if r.Compression == nil {
+		return nil
+	}
+
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil {
+		return NewValidationError("compression", "'compression' cannot be combined with 'proxy', 'batch' or 'websocket'")
+	}
+
+	return r.Compression.Validate()
+}
+
+// Validate validates a CompressionConfig
+func (c *CompressionConfig) Validate() error {
+	switch c.GetEncoding() {
+	case CompressionGzip, CompressionDeflate:
+	default:
+		return NewValidationError("compression.encoding", fmt.Sprintf("invalid encoding %q, must be %q or %q", c.Encoding, CompressionGzip, CompressionDeflate))
+	}
+
+	switch c.Violation {
+	case "", CompressionMislabel, CompressionUnlabeled, CompressionIgnoreAccept, CompressionDouble:
+	default:
+		valid := []string{CompressionMislabel, CompressionUnlabeled, CompressionIgnoreAccept, CompressionDouble}
+		return NewValidationError("compression.violation", fmt.Sprintf("invalid violation %q, must be one of: %s", c.Violation, strings.Join(valid, ", ")))
+	}
+
+	return nil
+}
+
+// GetEncoding returns the encoding, defaulting to gzip
+func (c *CompressionConfig) GetEncoding() string {
+	if c.Encoding == "" {
+		return CompressionGzip
+	}
+	return strings.ToLower(c.Encoding)
+}
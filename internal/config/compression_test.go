@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateCompression(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "default encoding - valid",
+			route: RouteConfig{Path: "/data", Method: "GET", Template: "{}", Compression: &CompressionConfig{}},
+		},
+		{
+			name:  "deflate with violation - valid",
+			route: RouteConfig{Path: "/data", Method: "GET", Template: "{}", Compression: &CompressionConfig{Encoding: "deflate", Violation: CompressionMislabel}},
+		},
+		{
+			name:  "uppercase encoding - valid",
+			route: RouteConfig{Path: "/data", Method: "GET", Template: "{}", Compression: &CompressionConfig{Encoding: "GZIP"}},
+		},
+		{
+			name:        "unknown encoding - invalid",
+			route:       RouteConfig{Path: "/data", Method: "GET", Template: "{}", Compression: &CompressionConfig{Encoding: "br"}},
+			errContains: `invalid encoding "br"`,
+		},
+		{
+			name:        "unknown violation - invalid",
+			route:       RouteConfig{Path: "/data", Method: "GET", Template: "{}", Compression: &CompressionConfig{Violation: "chaos"}},
+			errContains: `invalid violation "chaos"`,
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/data", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, Compression: &CompressionConfig{}},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "with websocket - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{}, Compression: &CompressionConfig{}},
+			errContains: "'websocket'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestCompressionConfig_GetEncoding(t *testing.T) {
+	if got := (&CompressionConfig{}).GetEncoding(); got != CompressionGzip {
+		t.Errorf("Expected default encoding %q, got %q", CompressionGzip, got)
+	}
+	if got := (&CompressionConfig{Encoding: "Deflate"}).GetEncoding(); got != CompressionDeflate {
+		t.Errorf("Expected encoding %q, got %q", CompressionDeflate, got)
+	}
+}
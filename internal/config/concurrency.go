@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConcurrencyConfig limits how many requests a route serves at once, like a
+// backend with a fixed number of workers. Requests over the limit wait in
+// line, in the order they arrived. In YAML it is either the limit alone or
+// a mapping.
+type ConcurrencyConfig struct {
+	Limit        int           `yaml:"limit"`                   // Requests served at once
+	MaxQueue     int           `yaml:"max_queue,omitempty"`     // Requests allowed to wait, beyond which they're rejected (default: unlimited)
+	QueueTimeout time.Duration `yaml:"queue_timeout,omitempty"` // Longest wait before a request is rejected (default: until the request times out)
+}
+
+// UnmarshalYAML accepts either the limit or a mapping with the settings
+func (c *ConcurrencyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var limit int
+	if err := unmarshal(&limit); err == nil {
+		*c = ConcurrencyConfig{Limit: limit}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings ConcurrencyConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("concurrency must be a number or a mapping with limit, max_queue and queue_timeout: %w", err)
+	}
+	*c = ConcurrencyConfig(decoded)
+	return nil
+}
+
+// validateConcurrency validates the concurrency limit of a route
+func (r *RouteConfig) validateConcurrency() error {
+	if r.Concurrency == nil {
+		return nil
+	}
+
+	if r.Concurrency.Limit <= 0 {
+		return NewValidationError("concurrency.limit", "limit must be at least 1")
+	}
+	if r.Concurrency.MaxQueue < 0 {
+		return NewValidationError("concurrency.max_queue", "max_queue cannot be negative")
+	}
+	if r.Concurrency.QueueTimeout < 0 {
+		return NewValidationError("concurrency.queue_timeout", "queue_timeout cannot be negative")
+	}
+
+	return nil
+}
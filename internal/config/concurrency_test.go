@@ -0,0 +1,101 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestConcurrencyConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        ConcurrencyConfig
+		errContains string
+	}{
+		{
+			name: "limit",
+			yaml: `concurrency: 2`,
+			want: ConcurrencyConfig{Limit: 2},
+		},
+		{
+			name: "settings",
+			yaml: "concurrency:\n  limit: 2\n  max_queue: 10\n  queue_timeout: 5s",
+			want: ConcurrencyConfig{Limit: 2, MaxQueue: 10, QueueTimeout: 5 * time.Second},
+		},
+		{
+			name:        "sequence",
+			yaml:        `concurrency: [2]`,
+			errContains: "concurrency must be a number or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Concurrency == nil || *route.Concurrency != tt.want {
+				t.Errorf("Expected concurrency %+v, got %+v", tt.want, route.Concurrency)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency ConcurrencyConfig
+		errContains string
+	}{
+		{
+			name:        "limit - valid",
+			concurrency: ConcurrencyConfig{Limit: 1},
+		},
+		{
+			name:        "queue settings - valid",
+			concurrency: ConcurrencyConfig{Limit: 4, MaxQueue: 10, QueueTimeout: time.Second},
+		},
+		{
+			name:        "zero limit - invalid",
+			concurrency: ConcurrencyConfig{},
+			errContains: "limit must be at least 1",
+		},
+		{
+			name:        "negative queue - invalid",
+			concurrency: ConcurrencyConfig{Limit: 1, MaxQueue: -1},
+			errContains: "max_queue cannot be negative",
+		},
+		{
+			name:        "negative timeout - invalid",
+			concurrency: ConcurrencyConfig{Limit: 1, QueueTimeout: -time.Second},
+			errContains: "queue_timeout cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := RouteConfig{Path: "/slow", Method: "GET", Template: "ok", Concurrency: &tt.concurrency}
+			err := route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"mime"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -11,20 +13,90 @@ import (
 	"github.com/goccy/go-yaml"
 
 	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
 	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
 )
 
 // Config represents the top-level configuration loaded from YAML
 type Config struct {
-	Routes     []RouteConfig     `yaml:"routes"`
-	Middleware middleware.Config `yaml:"middleware,omitempty"`
-	Server     ServerConfig      `yaml:"server,omitempty"`
-	Template   TemplateConfig    `yaml:"template,omitempty"`
+	Routes        []RouteConfig                `yaml:"routes"`
+	Middleware    middleware.Config            `yaml:"middleware,omitempty"`
+	Server        ServerConfig                 `yaml:"server,omitempty"`
+	Template      TemplateConfig               `yaml:"template,omitempty"`
+	Tenants       []TenantConfig               `yaml:"tenants,omitempty"`
+	Errors        ErrorsConfig                 `yaml:"errors,omitempty"`
+	TokenBucket   map[string]TokenConfig       `yaml:"token_bucket,omitempty"`
+	Transactions  map[string]TransactionConfig `yaml:"transactions,omitempty"`
+	Journal       JournalConfig                `yaml:"journal,omitempty"`
+	Storage       StorageConfig                `yaml:"storage,omitempty"`
+	Health        HealthConfig                 `yaml:"health,omitempty"`
+	Admin         AdminConfig                  `yaml:"admin,omitempty"`          // Tokens protecting the admin API
+	GRPC          *GRPCConfig                  `yaml:"grpc,omitempty"`           // Mocked gRPC methods served over HTTP/2
+	FallbackProxy string                       `yaml:"fallback_proxy,omitempty"` // Upstream requests matching no route are forwarded to
+	Include       []IncludeConfig              `yaml:"include,omitempty"`        // Files and URLs whose routes are pulled into this one
+
+	// Response headers every route sends, which a route's own response_headers override
+	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+
+	// Routes sharing a path prefix and defaults, added after the other routes
+	Groups []GroupConfig `yaml:"groups,omitempty"`
+
+	// Response served to requests matching no route, instead of the built-in 404
+	Fallback *FallbackConfig `yaml:"fallback,omitempty"`
+
+	included    []string        // Files loaded through include directives
+	includeDirs []string        // Directories include patterns are matched in
+	remote      []IncludeConfig // Remote includes, with their URLs resolved
+	warnings    []string        // Problems worked around while loading, like stale remote includes
 }
 
 // ServerConfig represents server-level configuration options
 type ServerConfig struct {
-	Timeouts TimeoutConfig `yaml:"timeouts,omitempty"`
+	Timeouts   TimeoutConfig `yaml:"timeouts,omitempty"`
+	DevMode    bool          `yaml:"dev_mode,omitempty"`    // Enables developer-facing diagnostic response headers
+	ClockSkew  time.Duration `yaml:"clock_skew,omitempty"`  // Offset of the mock's clock from the real time, e.g. "-5m"
+	ClockTime  string        `yaml:"clock_time,omitempty"`  // Time the mock's clock is frozen at, a date or RFC 3339 timestamp (default: the real time)
+	StrictHTTP string        `yaml:"strict_http,omitempty"` // "fix" or "reject" responses breaking basic HTTP rules (default: off)
+	HeaderVars bool          `yaml:"header_vars,omitempty"` // Exposes X-Mockingjay-Var-* request headers to templates as .Vars
+	BodyLimit  int64         `yaml:"body_limit,omitempty"`  // Largest request body read, in bytes (default: 10485760)
+	TLS        *TLSConfig    `yaml:"tls,omitempty"`         // Serves HTTPS, optionally asking for client certificates
+
+	// Content-Type of bodies whose route doesn't set one (default: inferred from the body)
+	DefaultContentType string `yaml:"default_content_type,omitempty"`
+
+	// Headers sent with every response, including built-in errors and the admin API
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// Limits after which the server shuts down gracefully and the process
+	// exits, for a supervisor to restart it (default: no limit)
+	MaxUptime   time.Duration `yaml:"max_uptime,omitempty"`   // Time the server runs for, e.g. "24h"
+	MaxRequests int64         `yaml:"max_requests,omitempty"` // Requests the server serves, not counting health checks
+
+	// How routes matching the same request are picked among: "order" (default) or "specific"
+	RouteMatching string `yaml:"route_matching,omitempty"`
+
+	// Sends configured response headers with the exact casing they're written with, like X-AMZ-Request-Id
+	PreserveHeaderCase bool `yaml:"preserve_header_case,omitempty"`
+
+	// Responses replacing the built-in template error and timeout responses
+	ErrorPages *ErrorPagesConfig `yaml:"error_pages,omitempty"`
+}
+
+// DefaultBodyLimit is the largest request body read when the server sets no
+// body_limit
+const DefaultBodyLimit = 10 << 20 // 10 MiB
+
+// GetBodyLimit returns the largest request body read, with the default applied
+func (sc *ServerConfig) GetBodyLimit() int64 {
+	if sc.BodyLimit == 0 {
+		return DefaultBodyLimit
+	}
+	return sc.BodyLimit
+}
+
+// ErrorsConfig represents how built-in error responses are written
+type ErrorsConfig struct {
+	Format string `yaml:"format,omitempty"` // "text" (default) or "json" for RFC 7807 problem+json documents
 }
 
 // TimeoutConfig represents timeout configuration options
@@ -39,7 +111,10 @@ type TimeoutConfig struct {
 
 // TemplateConfig represents template engine configuration options
 type TemplateConfig struct {
-	Delimiters DelimiterConfig `yaml:"delimiters,omitempty"`
+	Delimiters  DelimiterConfig   `yaml:"delimiters,omitempty"`
+	FakeSeed    *int64            `yaml:"fake_seed,omitempty"`    // Seeds random and fake data functions of every route, so responses repeat
+	Partials    map[string]string `yaml:"partials,omitempty"`     // Named templates every template can include with {{ template "name" . }}
+	PartialsDir string            `yaml:"partials_dir,omitempty"` // Directory of partials, each named after its file without the extension
 }
 
 // DelimiterConfig represents custom template delimiter configuration
@@ -92,16 +167,170 @@ func (dc *DelimiterConfig) GetWithDefaults() DelimiterConfig {
 
 // RouteConfig represents a single route configuration from YAML
 type RouteConfig struct {
-	Path            string            `yaml:"path"`
-	Method          string            `yaml:"method"`
-	Template        string            `yaml:"template,omitempty"`
-	TemplateFile    string            `yaml:"template_file,omitempty"`
-	MatchHeaders    map[string]string `yaml:"match_headers,omitempty"`
-	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+	Path            string                 `yaml:"path"`
+	Method          string                 `yaml:"method"`
+	Template        string                 `yaml:"template,omitempty"`
+	TemplateFile    string                 `yaml:"template_file,omitempty"`
+	MatchHeaders    map[string]string      `yaml:"match_headers,omitempty"`
+	MatchCookies    map[string]string      `yaml:"match_cookies,omitempty"`     // Matches request cookies by name, with literal or /regex/ values
+	MatchClientCert *ClientCertMatchConfig `yaml:"match_client_cert,omitempty"` // Matches the TLS client certificate of requests
+	MatchSNI        string                 `yaml:"match_sni,omitempty"`         // Matches the server name requested through TLS SNI
+	Match           map[string]any         `yaml:"match,omitempty"`             // Matchers of registered kinds, by name, with their settings
+	ResponseHeaders map[string]string      `yaml:"response_headers,omitempty"`
+	Responses       []ResponseConfig       `yaml:"responses,omitempty"`
+	Sequence        *SequenceConfig        `yaml:"sequence,omitempty"`
+	Variants        *VariantsConfig        `yaml:"variants,omitempty"` // Responses picked by a selector template, for A/B experiments
+	Proxy           *ProxyConfig           `yaml:"proxy,omitempty"`
+	Batch           *BatchConfig           `yaml:"batch,omitempty"`     // Serve batches of sub-requests with the other routes
+	WebSocket       *WebSocketConfig       `yaml:"websocket,omitempty"` // Upgrade to a WebSocket with scripted messages
+	Delay           *DelayConfig           `yaml:"delay,omitempty"`
+	RequireToken    *RequireTokenConfig    `yaml:"require_token,omitempty"`
+	Faults          []FaultConfig          `yaml:"faults,omitempty"`
+	Compression     *CompressionConfig     `yaml:"compression,omitempty"` // Compresses responses, optionally breaking negotiation
+	Stream          *StreamConfig          `yaml:"stream,omitempty"`      // Writes template output as it's rendered instead of buffering it
+	Raw             *RawConfig             `yaml:"raw,omitempty"`         // Parses requests from the connection, tolerating malformed framing as configured
+	ClockSkew       *time.Duration         `yaml:"clock_skew,omitempty"`  // Overrides the server's clock skew for this route
+	Expect          *ExpectConfig          `yaml:"expect,omitempty"`      // How often and in which order the route is expected to be called
+	Transaction     *TransactionStepConfig `yaml:"transaction,omitempty"` // Transition of a multi-step transaction the route performs
+	Deprecated      bool                   `yaml:"deprecated,omitempty"`  // Signals clients the route is deprecated with a Deprecation header
+	Sunset          string                 `yaml:"sunset,omitempty"`      // Date the deprecated route goes away, sent as a Sunset header
+
+	// Renders the same response for the same request, for snapshot tests
+	Deterministic *DeterministicConfig `yaml:"deterministic,omitempty"`
+
+	// Answers with the request body, optionally transformed, instead of templates
+	EchoBody *EchoBodyConfig `yaml:"echo_body,omitempty"`
+
+	// Answers with the bytes of a file as-is, instead of templates
+	BodyFile string `yaml:"body_file,omitempty"`
+
+	// Serves the files of a directory under the route's path, instead of templates
+	StaticDir *StaticDirConfig `yaml:"static_dir,omitempty"`
+
+	// Decodes rendered bodies before they're written, so templates can produce binary payloads
+	BodyEncoding string `yaml:"body_encoding,omitempty"`
+
+	// Holds the response back before its first byte, and pauses between the lines of its body
+	DelayFirstByte     *DelayConfig `yaml:"delay_first_byte,omitempty"`
+	DelayBetweenChunks *DelayConfig `yaml:"delay_between_chunks,omitempty"`
+
+	// Limits how many requests are served at once, queueing the others in arrival order
+	Concurrency *ConcurrencyConfig `yaml:"concurrency,omitempty"`
+
+	// Checksums of the body sent in headers like Content-MD5 and x-goog-hash, for clients verifying integrity
+	EmitChecksums []string `yaml:"emit_checksums,omitempty"`
+
+	// Checks the rendered body must pass before it's sent, failing the request with a 500 otherwise
+	AssertResponse *AssertResponseConfig `yaml:"assert_response,omitempty"`
+
+	// How HEAD requests are answered by GET routes, rendering the body or not and announcing its length
+	Head *HeadConfig `yaml:"head,omitempty"`
+
+	// HTML-escape what the route's body templates print, for routes serving HTML pages
+	EscapeHTML bool `yaml:"escape_html,omitempty"`
+
+	// Responses remembered by idempotency key and replayed to retries sending the same key
+	Idempotency *IdempotencyConfig `yaml:"idempotency,omitempty"`
+
+	// How the path is matched: "exact" (default), or "prefix" to also match every path under it
+	PathMatch string `yaml:"path_match,omitempty"`
+
+	// Routes with a higher priority are tried first, before routes listed earlier (default: 0)
+	Priority int `yaml:"priority,omitempty"`
+
+	// Middleware wrapping this route only, run inside the server's middleware in order
+	Middleware []middleware.MiddlewareConfig `yaml:"middleware,omitempty"`
+
+	remote bool // Set on routes loaded from remote includes, whose templates can't read local files
+}
+
+// FromRemote reports whether the route was loaded from a remote include, so
+// its templates must not read local files
+func (r *RouteConfig) FromRemote() bool {
+	return r.remote
 }
 
-// LoadConfig loads and validates a configuration from a YAML file
+// LoadConfig loads and validates a configuration from a YAML file, or from
+// every YAML file in a directory
 func LoadConfig(filename string) (*Config, error) {
+	return LoadConfigs([]string{filename})
+}
+
+// LoadConfigs loads the configuration files and directories at the given
+// paths, merges them into a single configuration and validates it. Routes
+// keep the order of their files, see ResolveConfigFiles.
+func LoadConfigs(paths []string) (*Config, error) {
+	files, err := ResolveConfigFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, NewLoadError("", fmt.Errorf("no configuration files given"))
+	}
+
+	var config Config
+	if len(files) == 1 {
+		parsed, err := parseConfigFile(files[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := parsed.loadIncludes(files[0], nil, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+		config = *parsed
+	} else {
+		owners := make(map[string]string)
+		seen := make(map[string]bool)
+		for _, file := range files {
+			// Skip files already pulled in by another file's include directive
+			if abs, err := filepath.Abs(file); err == nil && seen[abs] {
+				continue
+			}
+
+			parsed, err := parseConfigFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			// Validate routes while their file is still known, so errors point at it
+			for i, route := range parsed.Routes {
+				if err := route.Validate(); err != nil {
+					return nil, NewLoadError(file, fmt.Errorf("configuration validation failed: route[%d]: %w", i, err))
+				}
+			}
+
+			if err := parsed.loadIncludes(file, nil, seen); err != nil {
+				return nil, err
+			}
+
+			if err := config.merge(parsed, file, owners); err != nil {
+				return nil, NewLoadError(file, fmt.Errorf("failed to merge configuration: %w", err))
+			}
+			config.included = append(config.included, parsed.included...)
+			config.includeDirs = append(config.includeDirs, parsed.includeDirs...)
+			config.remote = append(config.remote, parsed.remote...)
+			config.warnings = append(config.warnings, parsed.warnings...)
+		}
+	}
+
+	name := strings.Join(files, ", ")
+
+	// Validate the configuration
+	if err := config.Validate(); err != nil {
+		return nil, NewLoadError(name, fmt.Errorf("configuration validation failed: %w", err))
+	}
+
+	// Load the configuration of every tenant hosted alongside this one
+	if err := config.loadTenants(); err != nil {
+		return nil, NewLoadError(name, err)
+	}
+
+	return &config, nil
+}
+
+// parseConfigFile reads and parses a single YAML configuration file
+func parseConfigFile(filename string) (*Config, error) {
 	// Check if file exists and is readable
 	if err := checkFileAccessibility(filename); err != nil {
 		return nil, NewLoadError(filename, err)
@@ -113,20 +342,52 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, NewLoadError(filename, fmt.Errorf("failed to read file: %w", err))
 	}
 
-	// Unmarshal YAML into Config struct
+	config, err := parseConfig(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve template files relative to the file declaring them
+	config.resolvePaths(filename)
+	return config, nil
+}
+
+// parseConfig unmarshals the YAML configuration data read from filename
+func parseConfig(filename string, data []byte) (*Config, error) {
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, NewLoadError(filename, fmt.Errorf("failed to parse YAML: %w", err))
 	}
 
-	// Validate the configuration
-	if err := config.Validate(); err != nil {
+	// Turn the routes of groups into plain routes
+	if err := config.expandGroups(); err != nil {
 		return nil, NewLoadError(filename, fmt.Errorf("configuration validation failed: %w", err))
 	}
 
 	return &config, nil
 }
 
+// ParseConfig parses and validates a configuration that isn't read from a
+// file, like one embedded in the binary. name identifies it in errors. Since
+// there's no file to resolve paths against, it can't include other files or
+// host tenants.
+func ParseConfig(name string, data []byte) (*Config, error) {
+	config, err := parseConfig(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Include) > 0 || len(config.Tenants) > 0 {
+		return nil, NewLoadError(name, fmt.Errorf("configuration validation failed: %w", NewValidationError("include", "embedded configurations cannot include files or host tenants")))
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, NewLoadError(name, fmt.Errorf("configuration validation failed: %w", err))
+	}
+
+	return config, nil
+}
+
 // checkFileAccessibility verifies that the file exists and is readable
 func checkFileAccessibility(filename string) error {
 	if strings.TrimSpace(filename) == "" {
@@ -157,7 +418,7 @@ func checkFileAccessibility(filename string) error {
 
 // Validate validates the Config and all its RouteConfigs
 func (c *Config) Validate() error {
-	if len(c.Routes) == 0 {
+	if len(c.Routes) == 0 && len(c.Tenants) == 0 && c.FallbackProxy == "" && c.Fallback == nil && c.GRPC == nil {
 		return &ValidationError{
 			Field:   "routes",
 			Message: "at least one route must be defined",
@@ -170,11 +431,136 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate tenant definitions
+	if err := c.validateTenants(); err != nil {
+		return err
+	}
+
+	// Validate middleware configuration
+	if err := c.Middleware.Validate(); err != nil {
+		return fmt.Errorf("middleware configuration: %w", err)
+	}
+
 	// Validate template configuration
 	if err := c.Template.Validate(); err != nil {
 		return fmt.Errorf("template configuration: %w", err)
 	}
 
+	// Validate the token bucket and the routes requiring its tokens
+	if err := c.validateTokens(); err != nil {
+		return err
+	}
+
+	// Validate the transaction state machines and the routes performing their transitions
+	if err := c.validateTransactions(); err != nil {
+		return err
+	}
+
+	// Validate request journal configuration
+	if err := c.Journal.Validate(); err != nil {
+		return fmt.Errorf("journal configuration: %w", err)
+	}
+
+	// Validate storage configuration
+	if err := c.Storage.Validate(); err != nil {
+		return fmt.Errorf("storage configuration: %w", err)
+	}
+
+	// Validate health check configuration
+	if err := c.Health.Validate(); err != nil {
+		return fmt.Errorf("health configuration: %w", err)
+	}
+
+	// Validate admin API configuration
+	if err := c.Admin.Validate(); err != nil {
+		return fmt.Errorf("admin configuration: %w", err)
+	}
+
+	// Validate error response configuration
+	if err := c.Errors.Validate(); err != nil {
+		return fmt.Errorf("errors configuration: %w", err)
+	}
+
+	// Validate the routes call expectations refer to
+	if err := c.validateExpectations(); err != nil {
+		return err
+	}
+
+	// Validate the upstream requests matching no route are forwarded to
+	if err := c.validateFallbackProxy(); err != nil {
+		return err
+	}
+
+	// Validate the response served to requests matching no route
+	if err := c.validateFallback(); err != nil {
+		return err
+	}
+
+	// Validate the headers sent with every response
+	if err := c.validateGlobalHeaders(); err != nil {
+		return err
+	}
+
+	// Validate the casing of response headers, when it's preserved
+	if err := c.validateHeaderCasing(); err != nil {
+		return err
+	}
+
+	// Validate the largest request body read
+	if c.Server.BodyLimit < 0 {
+		return NewValidationError("server.body_limit", "body_limit cannot be negative")
+	}
+
+	// Validate how routes matching the same request are picked among
+	if err := c.validateRouteMatching(); err != nil {
+		return err
+	}
+
+	// Validate the limits the server is recycled after
+	if c.Server.MaxUptime < 0 {
+		return NewValidationError("server.max_uptime", "max_uptime cannot be negative")
+	}
+	if c.Server.MaxRequests < 0 {
+		return NewValidationError("server.max_requests", "max_requests cannot be negative")
+	}
+
+	// Validate the content type of bodies routes don't label
+	if ct := c.Server.DefaultContentType; ct != "" {
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			return NewValidationError("server.default_content_type", fmt.Sprintf("invalid content type %q: %v", ct, err))
+		}
+	}
+
+	// Validate the time the mock's clock is frozen at
+	if _, err := c.Server.GetClockTime(); err != nil {
+		return NewValidationError("server.clock_time", err.Error())
+	}
+
+	// Validate the responses replacing built-in errors
+	if err := c.validateErrorPages(); err != nil {
+		return err
+	}
+
+	// Validate the strict HTTP mode and the responses it checks up front
+	if err := c.validateStrictHTTP(); err != nil {
+		return err
+	}
+
+	// Validate the TLS settings and the client certificates routes match
+	if err := c.validateTLS(); err != nil {
+		return err
+	}
+
+	// Validate that raw routes are served over plain HTTP
+	if err := c.validateRaw(); err != nil {
+		return err
+	}
+
+	// Validate the descriptor sets and the gRPC methods mocked from them
+	if err := c.validateGRPC(); err != nil {
+		return err
+	}
+
 	// Validate templates by attempting to compile them
 	if err := c.ValidateTemplates(); err != nil {
 		return fmt.Errorf("template validation failed: %w", err)
@@ -198,14 +584,105 @@ func (r *RouteConfig) Validate() error {
 		return err
 	}
 
-	// Validate exactly one of template or template_file is provided
-	if err := r.validateTemplateSource(); err != nil {
+	// Validate the response source: a WebSocket, a batch of sub-requests, an upstream proxy, the echoed body, a file or templates
+	if err := r.validateResponseSource(); err != nil {
 		return err
 	}
 
-	// Validate template file exists if template_file is specified
-	if r.TemplateFile != "" {
-		if err := r.validateTemplateFileExists(); err != nil {
+	// Validate the encoding rendered bodies are decoded from
+	if err := r.validateBodyEncoding(); err != nil {
+		return err
+	}
+
+	// Validate the artificial response delay
+	if r.Delay != nil {
+		if err := r.Delay.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate the delays applied while the response is written
+	if err := r.validateChunkDelays(); err != nil {
+		return err
+	}
+
+	// Validate the limit on requests served at once
+	if err := r.validateConcurrency(); err != nil {
+		return err
+	}
+
+	// Validate the injected faults
+	if err := r.validateFaults(); err != nil {
+		return err
+	}
+
+	// Validate the response compression
+	if err := r.validateCompression(); err != nil {
+		return err
+	}
+
+	// Validate the streaming of template output
+	if err := r.validateStream(); err != nil {
+		return err
+	}
+
+	// Validate the checksums sent of bodies
+	if err := r.validateChecksums(); err != nil {
+		return err
+	}
+
+	// Validate the checks of rendered bodies
+	if err := r.validateAssertResponse(); err != nil {
+		return err
+	}
+
+	// Validate the answers to HEAD requests
+	if err := r.validateHead(); err != nil {
+		return err
+	}
+
+	// Validate the escaping of HTML in bodies
+	if err := r.validateEscapeHTML(); err != nil {
+		return err
+	}
+
+	// Validate the replaying of responses by idempotency key
+	if err := r.validateIdempotency(); err != nil {
+		return err
+	}
+
+	// Validate the handling of malformed requests
+	if err := r.validateRaw(); err != nil {
+		return err
+	}
+
+	// Validate the seeding of random functions
+	if err := r.validateDeterministic(); err != nil {
+		return err
+	}
+
+	// Validate the token requirement
+	if r.RequireToken != nil {
+		if err := r.RequireToken.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate the transaction transition
+	if r.Transaction != nil {
+		if err := r.Transaction.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate the deprecation and sunset date
+	if err := r.validateDeprecation(); err != nil {
+		return err
+	}
+
+	// Validate the call expectations
+	if r.Expect != nil {
+		if err := r.Expect.Validate(); err != nil {
 			return err
 		}
 	}
@@ -215,11 +692,43 @@ func (r *RouteConfig) Validate() error {
 		return err
 	}
 
+	// Validate how the path is matched
+	if err := r.validatePathMatch(); err != nil {
+		return err
+	}
+
 	// Validate header matching patterns
 	if err := r.validateMatchHeaders(); err != nil {
 		return err
 	}
 
+	// Validate cookie matching patterns
+	if err := r.validateMatchCookies(); err != nil {
+		return err
+	}
+
+	// Validate client certificate matching patterns
+	if r.MatchClientCert != nil {
+		if err := r.MatchClientCert.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate the server name matching pattern
+	if err := r.validateMatchSNI(); err != nil {
+		return err
+	}
+
+	// Validate matchers of registered kinds
+	if err := r.validateMatch(); err != nil {
+		return err
+	}
+
+	// Validate the route's own middleware
+	if err := r.validateMiddleware(); err != nil {
+		return err
+	}
+
 	// Validate response headers
 	if err := r.validateResponseHeaders(); err != nil {
 		return err
@@ -262,11 +771,104 @@ func (r *RouteConfig) validateHTTPMethod() error {
 	}
 }
 
-// validateTemplateSource ensures exactly one of template or template_file is provided
+// validateResponseSource validates how the route produces its response:
+// WebSocket routes upgrade the connection, proxied routes forward to an
+// upstream, echoing routes answer with the request body, body file routes
+// answer with a file, all others render templates
+func (r *RouteConfig) validateResponseSource() error {
+	if r.Variants != nil && (r.WebSocket != nil || r.Batch != nil || r.Proxy != nil) {
+		return NewValidationError("variants", "'variants' cannot be combined with 'proxy', 'batch' or 'websocket'")
+	}
+
+	if r.EchoBody != nil && (r.WebSocket != nil || r.Batch != nil || r.Proxy != nil) {
+		return NewValidationError("echo_body", "'echo_body' cannot be combined with 'proxy', 'batch' or 'websocket'")
+	}
+
+	if r.BodyFile != "" && (r.WebSocket != nil || r.Batch != nil || r.Proxy != nil || r.EchoBody != nil) {
+		return NewValidationError("body_file", "'body_file' cannot be combined with 'proxy', 'batch', 'websocket' or 'echo_body'")
+	}
+
+	if r.StaticDir != nil && (r.WebSocket != nil || r.Batch != nil || r.Proxy != nil || r.EchoBody != nil || r.BodyFile != "") {
+		return NewValidationError("static_dir", "'static_dir' cannot be combined with 'proxy', 'batch', 'websocket', 'echo_body' or 'body_file'")
+	}
+
+	if r.WebSocket != nil {
+		return r.validateWebSocket()
+	}
+
+	if r.Batch != nil {
+		return r.validateBatch()
+	}
+
+	if r.Proxy != nil {
+		return r.validateProxy()
+	}
+
+	if r.EchoBody != nil {
+		return r.validateEchoBody()
+	}
+
+	if r.BodyFile != "" {
+		return r.validateBodyFile()
+	}
+
+	if r.StaticDir != nil {
+		return r.validateStaticDir()
+	}
+
+	// Validate exactly one of template, template_file or responses is provided
+	if err := r.validateTemplateSource(); err != nil {
+		return err
+	}
+
+	// Validate alternative responses
+	if err := r.validateResponses(); err != nil {
+		return err
+	}
+
+	// Validate sequenced responses
+	if err := r.validateSequence(); err != nil {
+		return err
+	}
+
+	// Validate responses picked by a selector
+	if err := r.validateVariants(); err != nil {
+		return err
+	}
+
+	// Validate template file exists if template_file is specified
+	if r.TemplateFile != "" {
+		if err := r.validateTemplateFileExists(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTemplateSource ensures exactly one of template or template_file is provided,
+// unless the route declares alternative responses or variants which carry their own templates
 func (r *RouteConfig) validateTemplateSource() error {
 	hasTemplate := strings.TrimSpace(r.Template) != ""
 	hasTemplateFile := strings.TrimSpace(r.TemplateFile) != ""
 
+	if r.Variants != nil {
+		if hasTemplate || hasTemplateFile {
+			return NewValidationError("variants", "'variants' cannot be combined with 'template' or 'template_file'")
+		}
+		return nil
+	}
+
+	if len(r.Responses) > 0 {
+		if hasTemplate || hasTemplateFile {
+			return &ValidationError{
+				Field:   "responses",
+				Message: "'responses' cannot be combined with 'template' or 'template_file'",
+			}
+		}
+		return nil
+	}
+
 	if !hasTemplate && !hasTemplateFile {
 		return &ValidationError{
 			Field:   "template",
@@ -284,25 +886,38 @@ func (r *RouteConfig) validateTemplateSource() error {
 	return nil
 }
 
-// validateTemplateFileExists checks if the template file exists and is readable
+// validateTemplateFileExists checks if the template file exists and is
+// readable, naming the absolute path it was looked for at otherwise
 func (r *RouteConfig) validateTemplateFileExists() error {
 	if _, err := os.Stat(r.TemplateFile); err != nil {
+		path := r.TemplateFile
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
 		if os.IsNotExist(err) {
 			return &ValidationError{
 				Field:   "template_file",
-				Message: fmt.Sprintf("template file %q does not exist", r.TemplateFile),
+				Message: fmt.Sprintf("template file %q does not exist", path),
 			}
 		}
 		return &ValidationError{
 			Field:   "template_file",
-			Message: fmt.Sprintf("cannot access template file %q: %v", r.TemplateFile, err),
+			Message: fmt.Sprintf("cannot access template file %q: %v", path, err),
 		}
 	}
 	return nil
 }
 
-// validateRegexPattern validates regex syntax if the path appears to be a regex
+// validateRegexPattern validates regex syntax if the path appears to be a
+// regex, and the parameters of path templates
 func (r *RouteConfig) validateRegexPattern() error {
+	if r.IsPathTemplate() {
+		if _, err := compilePathTemplate(r.Path); err != nil {
+			return NewValidationError("path", fmt.Sprintf("invalid path template %q: %v", r.Path, err))
+		}
+	}
+
 	if r.IsRegexPattern() {
 		// Extract the regex pattern (remove surrounding slashes)
 		pattern := strings.TrimPrefix(strings.TrimSuffix(r.Path, "/"), "/")
@@ -465,6 +1080,24 @@ func (tc *TemplateConfig) Validate() error {
 	return tc.Delimiters.Validate()
 }
 
+// GetFormat returns the error response format, defaulting to plain text
+func (ec *ErrorsConfig) GetFormat() string {
+	if ec.Format == "" {
+		return problem.FormatText
+	}
+	return ec.Format
+}
+
+// Validate validates the error response configuration
+func (ec *ErrorsConfig) Validate() error {
+	switch ec.GetFormat() {
+	case problem.FormatText, problem.FormatJSON:
+		return nil
+	default:
+		return NewValidationError("errors.format", fmt.Sprintf("invalid format %q, must be %q or %q", ec.Format, problem.FormatText, problem.FormatJSON))
+	}
+}
+
 // Validate validates delimiter configuration
 func (dc *DelimiterConfig) Validate() error {
 	// If both are empty, that's fine - we'll use defaults
@@ -523,9 +1156,15 @@ func (dc *DelimiterConfig) validateDelimiter(delimiter, side string) error {
 
 // ValidateTemplates validates all templates by attempting to compile them
 func (c *Config) ValidateTemplates() error {
-	// Create a template engine for validation with configured delimiters
-	delimiters := c.Template.Delimiters.GetWithDefaults()
-	engine := templatepkg.NewEngineWithDelimiters(delimiters.Left, delimiters.Right)
+	// Create a template engine for validation with configured delimiters and partials
+	engine, err := c.Template.NewEngine()
+	if err != nil {
+		return err
+	}
+
+	if err := c.validateGlobalHeaderTemplates(engine); err != nil {
+		return err
+	}
 
 	for i, route := range c.Routes {
 		if err := c.validateRouteTemplates(engine, route, i); err != nil {
@@ -533,7 +1172,15 @@ func (c *Config) ValidateTemplates() error {
 		}
 	}
 
-	return nil
+	if err := c.validateFallbackTemplates(engine); err != nil {
+		return err
+	}
+
+	if err := c.validateErrorPageTemplates(engine); err != nil {
+		return err
+	}
+
+	return c.validateGRPCTemplates(engine)
 }
 
 // validateRouteTemplates validates templates for a single route
@@ -548,6 +1195,71 @@ func (c *Config) validateRouteTemplates(engine *templatepkg.Engine, route RouteC
 		return err
 	}
 
+	// Validate the transaction ID template
+	if route.Transaction != nil {
+		templateName := fmt.Sprintf("validation_transaction_%d_%s_%s", routeIndex, route.GetNormalizedMethod(), sanitizeTemplateNameForValidation(route.Path))
+		if _, err := engine.CompileInlineTemplate(templateName, route.Transaction.ID); err != nil {
+			return fmt.Errorf("route[%d] transaction ID template compilation failed: %w", routeIndex, err)
+		}
+	}
+
+	// Validate the templates of alternative responses
+	for i, resp := range route.Responses {
+		variant := RouteConfig{
+			Path:            fmt.Sprintf("%s_response_%d", route.Path, i),
+			Method:          route.Method,
+			Template:        resp.Template,
+			TemplateFile:    resp.TemplateFile,
+			ResponseHeaders: resp.ResponseHeaders,
+		}
+		if err := c.validateMainTemplate(engine, variant, routeIndex); err != nil {
+			return fmt.Errorf("responses[%d]: %w", i, err)
+		}
+		if err := c.validateResponseHeaderTemplates(engine, variant, routeIndex); err != nil {
+			return fmt.Errorf("responses[%d]: %w", i, err)
+		}
+	}
+
+	// Validate the selector and templates of variants
+	if route.Variants != nil {
+		templateName := fmt.Sprintf("route_%d_variants_selector", routeIndex)
+		if _, err := engine.CompileInlineTemplate(templateName, route.Variants.Selector); err != nil {
+			return fmt.Errorf("route[%d] variants selector template compilation failed: %w", routeIndex, err)
+		}
+
+		for _, name := range route.Variants.Names() {
+			resp := route.Variants.Cases[name]
+			variant := RouteConfig{
+				Path:            fmt.Sprintf("%s_variant_%s", route.Path, name),
+				Method:          route.Method,
+				Template:        resp.Template,
+				TemplateFile:    resp.TemplateFile,
+				ResponseHeaders: resp.ResponseHeaders,
+			}
+			if err := c.validateMainTemplate(engine, variant, routeIndex); err != nil {
+				return fmt.Errorf("variants.cases[%s]: %w", name, err)
+			}
+			if err := c.validateResponseHeaderTemplates(engine, variant, routeIndex); err != nil {
+				return fmt.Errorf("variants.cases[%s]: %w", name, err)
+			}
+		}
+	}
+
+	// Validate the templates of scripted WebSocket messages
+	if route.WebSocket != nil {
+		for i, msg := range route.WebSocket.Messages {
+			variant := RouteConfig{
+				Path:         fmt.Sprintf("%s_websocket_%d", route.Path, i),
+				Method:       route.Method,
+				Template:     msg.Template,
+				TemplateFile: msg.TemplateFile,
+			}
+			if err := c.validateMainTemplate(engine, variant, routeIndex); err != nil {
+				return fmt.Errorf("websocket.messages[%d]: %w", i, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -574,6 +1286,10 @@ func (c *Config) validateMainTemplate(engine *templatepkg.Engine, route RouteCon
 // validateResponseHeaderTemplates validates response header templates for a route
 func (c *Config) validateResponseHeaderTemplates(engine *templatepkg.Engine, route RouteConfig, routeIndex int) error {
 	for headerName, headerValue := range route.ResponseHeaders {
+		// Empty values remove the header instead of rendering it
+		if strings.TrimSpace(headerValue) == "" {
+			continue
+		}
 		templateName := fmt.Sprintf("validation_header_%d_%s_%s_%s", routeIndex, route.GetNormalizedMethod(), sanitizeTemplateNameForValidation(route.Path), sanitizeTemplateNameForValidation(headerName))
 		_, err := engine.CompileInlineTemplate(templateName, headerValue)
 		if err != nil {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig_ValidYAML(t *testing.T) {
@@ -288,13 +289,13 @@ func TestLoadConfig_FileAccessErrors(t *testing.T) {
 	}
 }
 
-func TestLoadConfig_DirectoryInsteadOfFile(t *testing.T) {
-	// Create a temporary directory
+func TestLoadConfig_EmptyDirectory(t *testing.T) {
+	// Create a temporary directory with no configuration files
 	tmpDir := t.TempDir()
 
 	config, err := LoadConfig(tmpDir)
 	if err == nil {
-		t.Error("LoadConfig() expected error when given directory instead of file")
+		t.Error("LoadConfig() expected error when given a directory without config files")
 		return
 	}
 
@@ -302,8 +303,55 @@ func TestLoadConfig_DirectoryInsteadOfFile(t *testing.T) {
 		t.Error("LoadConfig() should return nil config on error")
 	}
 
-	if !strings.Contains(err.Error(), "is a directory") {
-		t.Errorf("LoadConfig() error = %v, want error containing 'is a directory'", err)
+	if !strings.Contains(err.Error(), "has no .yaml or .yml files") {
+		t.Errorf("LoadConfig() error = %v, want error containing 'has no .yaml or .yml files'", err)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "valid configuration",
+			data: "routes:\n  - path: /hello\n    method: GET\n    template: Hello\n",
+		},
+		{
+			name:    "invalid YAML",
+			data:    "routes: [",
+			wantErr: "failed to parse YAML",
+		},
+		{
+			name:    "invalid route",
+			data:    "routes:\n  - method: GET\n    template: Hello\n",
+			wantErr: "configuration validation failed",
+		},
+		{
+			name:    "includes",
+			data:    "include: [\"more.yaml\"]\nroutes:\n  - path: /hello\n    template: Hello\n",
+			wantErr: "cannot include files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig("embedded.yaml", []byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseConfig() unexpected error: %v", err)
+				}
+				if len(config.Routes) != 1 || config.Routes[0].Path != "/hello" {
+					t.Errorf("ParseConfig() routes = %+v, want the /hello route", config.Routes)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "embedded.yaml") {
+				t.Errorf("ParseConfig() error = %v, want error about embedded.yaml containing %q", err, tt.wantErr)
+			}
+		})
 	}
 }
 
@@ -1255,3 +1303,149 @@ routes:
 		})
 	}
 }
+
+func TestErrorsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		wantFormat string
+		wantErr    bool
+	}{
+		{name: "default", format: "", wantFormat: "text"},
+		{name: "text", format: "text", wantFormat: "text"},
+		{name: "json", format: "json", wantFormat: "json"},
+		{name: "unknown", format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := ErrorsConfig{Format: tt.format}
+			err := ec.Validate()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "errors.format") {
+					t.Errorf("Expected errors.format validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if got := ec.GetFormat(); got != tt.wantFormat {
+				t.Errorf("Expected format %q, got %q", tt.wantFormat, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_ClockSkew(t *testing.T) {
+	tmpFile := createTempFile(t, `server:
+  clock_skew: "-5m"
+routes:
+  - path: "/token"
+    method: GET
+    template: "token"
+    clock_skew: "90s"
+  - path: "/now"
+    method: GET
+    template: "now"`)
+	defer os.Remove(tmpFile)
+
+	config, err := LoadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+
+	if config.Server.ClockSkew != -5*time.Minute {
+		t.Errorf("Expected server clock skew -5m, got %v", config.Server.ClockSkew)
+	}
+	if skew := config.Routes[0].ClockSkew; skew == nil || *skew != 90*time.Second {
+		t.Errorf("Expected route clock skew 90s, got %v", skew)
+	}
+	if config.Routes[1].ClockSkew != nil {
+		t.Errorf("Expected no route clock skew, got %v", *config.Routes[1].ClockSkew)
+	}
+}
+
+func TestLoadConfig_BodyLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		server      string
+		want        int64
+		errContains string
+	}{
+		{name: "default", server: "dev_mode: false", want: DefaultBodyLimit},
+		{name: "custom", server: "body_limit: 1024", want: 1024},
+		{name: "negative", server: "body_limit: -1", errContains: "body_limit cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, `server:
+  `+tt.server+`
+routes:
+  - path: "/upload"
+    method: POST
+    template: "ok"`)
+			defer os.Remove(tmpFile)
+
+			config, err := LoadConfig(tmpFile)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+			if got := config.Server.GetBodyLimit(); got != tt.want {
+				t.Errorf("Expected body limit %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_RecycleLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		server      string
+		errContains string
+	}{
+		{name: "limits", server: "{max_uptime: 24h, max_requests: 10000}"},
+		{name: "negative uptime", server: "{max_uptime: -1h}", errContains: `validation error in field "server.max_uptime": max_uptime cannot be negative`},
+		{name: "negative requests", server: "{max_requests: -1}", errContains: `validation error in field "server.max_requests": max_requests cannot be negative`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, "server: "+tt.server+"\nroutes:\n  - {path: /, method: GET, template: ok}")
+			defer os.Remove(tmpFile)
+
+			config, err := LoadConfig(tmpFile)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+			if config.Server.MaxUptime != 24*time.Hour || config.Server.MaxRequests != 10000 {
+				t.Errorf("Expected the limits to be parsed, got %+v", config.Server)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateDefaultContentType(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{DefaultContentType: "json; charset"},
+		Routes: []RouteConfig{{Path: "/", Method: "GET", Template: "ok"}},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server.default_content_type") {
+		t.Errorf("Expected a server.default_content_type error, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// validateMatchCookies validates cookie matching patterns
+func (r *RouteConfig) validateMatchCookies() error {
+	for name, value := range r.MatchCookies {
+		// Cookie names are tokens, like header names
+		if strings.TrimSpace(name) == "" {
+			return NewValidationError("match_cookies", "cookie name cannot be empty")
+		}
+		for _, char := range name {
+			if !isValidHeaderNameChar(char) {
+				return NewValidationError("match_cookies", fmt.Sprintf("invalid character %q in cookie name %q", char, name))
+			}
+		}
+
+		if isRegexPattern(value) {
+			pattern := extractRegexPattern(value)
+			if _, err := regexp.Compile(pattern); err != nil {
+				return NewValidationError("match_cookies", fmt.Sprintf("invalid regex pattern %q for cookie %q: %v", pattern, name, err))
+			}
+		}
+	}
+	return nil
+}
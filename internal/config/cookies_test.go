@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateCookieMatching(t *testing.T) {
+	tests := []struct {
+		name        string
+		cookies     map[string]string
+		errContains string
+	}{
+		{name: "literal value", cookies: map[string]string{"session": "abc123"}},
+		{name: "regex value", cookies: map[string]string{"session": "/^[a-f0-9]+$/"}},
+		{name: "any value", cookies: map[string]string{"session": ""}},
+		{name: "empty name", cookies: map[string]string{" ": "abc"}, errContains: "cookie name cannot be empty"},
+		{name: "invalid name", cookies: map[string]string{"my session": "abc"}, errContains: `invalid character ' ' in cookie name "my session"`},
+		{name: "invalid regex", cookies: map[string]string{"session": "/[a-/"}, errContains: "invalid regex pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Routes: []RouteConfig{{Path: "/cart", Method: "GET", Template: "[]", MatchCookies: tt.cookies}},
+			}
+
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DelayConfig represents an artificial latency applied before a route responds.
+// In YAML it is either a single duration ("250ms") or a range with min and max
+// ({min: 100ms, max: 500ms}) from which a random delay is picked per request.
+type DelayConfig struct {
+	Min time.Duration `yaml:"min"` // Shortest delay
+	Max time.Duration `yaml:"max"` // Longest delay (equal to Min for a fixed delay)
+}
+
+// UnmarshalYAML accepts either a duration string or a min/max mapping
+func (d *DelayConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var fixed string
+	if err := unmarshal(&fixed); err == nil {
+		duration, err := time.ParseDuration(fixed)
+		if err != nil {
+			return fmt.Errorf("invalid delay %q: %w", fixed, err)
+		}
+		d.Min, d.Max = duration, duration
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type delayRange DelayConfig
+	var r delayRange
+	if err := unmarshal(&r); err != nil {
+		return fmt.Errorf("delay must be a duration like \"250ms\" or a mapping with min and max: %w", err)
+	}
+	*d = DelayConfig(r)
+	return nil
+}
+
+// Validate validates the delay bounds
+func (d *DelayConfig) Validate() error {
+	return d.validate("delay")
+}
+
+// validate validates the delay bounds, reporting errors against field
+func (d *DelayConfig) validate(field string) error {
+	if d.Min < 0 || d.Max < 0 {
+		return NewValidationError(field, "delay cannot be negative")
+	}
+
+	if d.Max < d.Min {
+		return NewValidationError(field, fmt.Sprintf("max delay %s cannot be shorter than min delay %s", d.Max, d.Min))
+	}
+
+	return nil
+}
+
+// validateChunkDelays validates the delays applied while a route's response
+// is written: before its first byte and between the chunks of its body
+func (r *RouteConfig) validateChunkDelays() error {
+	delays := []struct {
+		field string
+		delay *DelayConfig
+	}{
+		{"delay_first_byte", r.DelayFirstByte},
+		{"delay_between_chunks", r.DelayBetweenChunks},
+	}
+
+	for _, d := range delays {
+		if d.delay == nil {
+			continue
+		}
+
+		if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil || r.StaticDir != nil {
+			return NewValidationError(d.field, fmt.Sprintf("'%s' cannot be combined with 'proxy', 'batch', 'websocket' or 'static_dir'", d.field))
+		}
+		if r.Stream != nil {
+			return NewValidationError(d.field, fmt.Sprintf("'%s' cannot be combined with 'stream', which writes output as it's rendered", d.field))
+		}
+		if len(r.Faults) > 0 {
+			return NewValidationError(d.field, fmt.Sprintf("'%s' cannot be combined with 'faults', which control how the body is written", d.field))
+		}
+		if err := d.delay.validate(d.field); err != nil {
+			return err
+		}
+	}
+
+	if r.DelayBetweenChunks != nil && r.Compression != nil {
+		return NewValidationError("delay_between_chunks", "'delay_between_chunks' cannot be combined with 'compression', since compressed bodies have no lines to split")
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestDelayConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        DelayConfig
+		errContains string
+	}{
+		{
+			name: "fixed duration",
+			yaml: `delay: 250ms`,
+			want: DelayConfig{Min: 250 * time.Millisecond, Max: 250 * time.Millisecond},
+		},
+		{
+			name: "range",
+			yaml: "delay:\n  min: 100ms\n  max: 1s",
+			want: DelayConfig{Min: 100 * time.Millisecond, Max: time.Second},
+		},
+		{
+			name: "JSON range",
+			yaml: `{"delay": {"min": "1s", "max": "2s"}}`,
+			want: DelayConfig{Min: time.Second, Max: 2 * time.Second},
+		},
+		{
+			name:        "invalid duration",
+			yaml:        `delay: soon`,
+			errContains: `invalid delay "soon"`,
+		},
+		{
+			name:        "sequence",
+			yaml:        `delay: [1s, 2s]`,
+			errContains: "delay must be a duration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Delay == nil || *route.Delay != tt.want {
+				t.Errorf("Expected delay %+v, got %+v", tt.want, route.Delay)
+			}
+		})
+	}
+}
+
+func TestDelayConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		delay       DelayConfig
+		errContains string
+	}{
+		{name: "fixed", delay: DelayConfig{Min: time.Second, Max: time.Second}},
+		{name: "range", delay: DelayConfig{Min: 0, Max: time.Second}},
+		{name: "negative", delay: DelayConfig{Min: -time.Second, Max: time.Second}, errContains: "cannot be negative"},
+		{name: "inverted", delay: DelayConfig{Min: 2 * time.Second, Max: time.Second}, errContains: "cannot be shorter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.delay.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateChunkDelays(t *testing.T) {
+	delay := &DelayConfig{Min: 100 * time.Millisecond, Max: 200 * time.Millisecond}
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "first byte and between chunks",
+			route: RouteConfig{Path: "/events", Method: "GET", Template: "ok", DelayFirstByte: delay, DelayBetweenChunks: delay},
+		},
+		{
+			name:  "first byte with compression",
+			route: RouteConfig{Path: "/events", Method: "GET", Template: "ok", DelayFirstByte: delay, Compression: &CompressionConfig{}},
+		},
+		{
+			name:        "negative",
+			route:       RouteConfig{Path: "/events", Method: "GET", Template: "ok", DelayFirstByte: &DelayConfig{Min: -time.Second, Max: time.Second}},
+			errContains: `"delay_first_byte": delay cannot be negative`,
+		},
+		{
+			name:        "inverted",
+			route:       RouteConfig{Path: "/events", Method: "GET", Template: "ok", DelayBetweenChunks: &DelayConfig{Min: time.Second, Max: 0}},
+			errContains: `"delay_between_chunks": max delay`,
+		},
+		{
+			name:        "proxy",
+			route:       RouteConfig{Path: "/events", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, DelayFirstByte: delay},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "stream",
+			route:       RouteConfig{Path: "/events", Method: "GET", Template: "ok", Stream: &StreamConfig{}, DelayBetweenChunks: delay},
+			errContains: "cannot be combined with 'stream'",
+		},
+		{
+			name:        "faults",
+			route:       RouteConfig{Path: "/events", Method: "GET", Template: "ok", Faults: []FaultConfig{{Type: FaultReset}}, DelayFirstByte: delay},
+			errContains: "cannot be combined with 'faults'",
+		},
+		{
+			name:        "between chunks with compression",
+			route:       RouteConfig{Path: "/events", Method: "GET", Template: "ok", Compression: &CompressionConfig{}, DelayBetweenChunks: delay},
+			errContains: "cannot be combined with 'compression'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
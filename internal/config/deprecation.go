@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sunsetLayouts are the layouts sunset dates can be written in
+var sunsetLayouts = []string{time.DateOnly, time.RFC3339, http.TimeFormat}
+
+// validateDeprecation validates the deprecation settings of a route
+func (r *RouteConfig) validateDeprecation() error {
+	if r.Sunset == "" {
+		return nil
+	}
+
+	if !r.Deprecated {
+		return NewValidationError("sunset", "'sunset' requires 'deprecated: true'")
+	}
+
+	if _, err := r.GetSunset(); err != nil {
+		return NewValidationError("sunset", err.Error())
+	}
+	return nil
+}
+
+// GetSunset returns the date a deprecated route goes away, or the zero time
+// when it has none. Dates without a time refer to the start of the day, UTC.
+func (r *RouteConfig) GetSunset() (time.Time, error) {
+	if r.Sunset == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range sunsetLayouts {
+		if sunset, err := time.Parse(layout, r.Sunset); err == nil {
+			return sunset.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid sunset date %q, must be a date like \"2025-12-31\" or an RFC 3339 timestamp", r.Sunset)
+}
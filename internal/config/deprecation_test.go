@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouteConfig_ValidateDeprecation(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		sunset      time.Time
+		errContains string
+	}{
+		{name: "not deprecated", route: RouteConfig{}},
+		{name: "deprecated without sunset", route: RouteConfig{Deprecated: true}},
+		{name: "sunset date", route: RouteConfig{Deprecated: true, Sunset: "2026-12-31"}, sunset: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "sunset timestamp", route: RouteConfig{Deprecated: true, Sunset: "2026-12-31T18:30:00+02:00"}, sunset: time.Date(2026, 12, 31, 16, 30, 0, 0, time.UTC)},
+		{name: "sunset HTTP date", route: RouteConfig{Deprecated: true, Sunset: "Thu, 31 Dec 2026 23:59:59 GMT"}, sunset: time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{name: "sunset without deprecation", route: RouteConfig{Sunset: "2026-12-31"}, errContains: "'sunset' requires 'deprecated: true'"},
+		{name: "invalid sunset", route: RouteConfig{Deprecated: true, Sunset: "next year"}, errContains: `invalid sunset date "next year"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.route.Path, tt.route.Method, tt.route.Template = "/old", "GET", "gone soon"
+
+			err := tt.route.Validate()
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			sunset, err := tt.route.GetSunset()
+			if err != nil || !sunset.Equal(tt.sunset) {
+				t.Errorf("Expected sunset %v, got %v, %v", tt.sunset, sunset, err)
+			}
+		})
+	}
+}
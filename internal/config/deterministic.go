@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultDeterministicTime is the time deterministic routes freeze their
+// clock at, unless they set their own
+var DefaultDeterministicTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DeterministicConfig makes a route render the same response for the same
+// request, so snapshot tests against the mock stop flaking: random and fake
+// data functions are seeded from the route and the request, and the clock is
+// frozen. In YAML it is either true, for the defaults, or a mapping.
+type DeterministicConfig struct {
+	Seed string `yaml:"seed,omitempty"` // Mixed into the seed, to get different values without changing requests
+	Time string `yaml:"time,omitempty"` // Time the clock is frozen at, a date or RFC 3339 timestamp (default: 2024-01-01T00:00:00Z)
+}
+
+// UnmarshalYAML accepts either a boolean or a mapping with the settings
+func (d *DeterministicConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("deterministic can't be false, remove it instead")
+		}
+		*d = DeterministicConfig{}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings DeterministicConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("deterministic must be true or a mapping with seed and time: %w", err)
+	}
+	*d = DeterministicConfig(decoded)
+	return nil
+}
+
+// validateDeterministic validates the deterministic settings of a route
+func (r *RouteConfig) validateDeterministic() error {
+	if r.Deterministic == nil {
+		return nil
+	}
+
+	if r.Proxy != nil || r.Batch != nil {
+		return NewValidationError("deterministic", "'deterministic' cannot be combined with 'proxy' or 'batch', which don't render templates")
+	}
+
+	if _, err := r.Deterministic.GetTime(); err != nil {
+		return NewValidationError("deterministic.time", err.Error())
+	}
+	return nil
+}
+
+// GetTime returns the time the clock is frozen at, using the default when
+// unset
+func (d *DeterministicConfig) GetTime() (time.Time, error) {
+	if d.Time == "" {
+		return DefaultDeterministicTime, nil
+	}
+	return ParseClockTime(d.Time)
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestDeterministicConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        DeterministicConfig
+		errContains string
+	}{
+		{
+			name: "enabled",
+			yaml: `deterministic: true`,
+			want: DeterministicConfig{},
+		},
+		{
+			name: "settings",
+			yaml: "deterministic:\n  seed: v2\n  time: \"2025-06-01T12:00:00Z\"",
+			want: DeterministicConfig{Seed: "v2", Time: "2025-06-01T12:00:00Z"},
+		},
+		{
+			name:        "disabled",
+			yaml:        `deterministic: false`,
+			errContains: "can't be false",
+		},
+		{
+			name:        "sequence",
+			yaml:        `deterministic: [v2]`,
+			errContains: "deterministic must be true or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Deterministic == nil || *route.Deterministic != tt.want {
+				t.Errorf("Expected deterministic %+v, got %+v", tt.want, route.Deterministic)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateDeterministic(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "defaults - valid",
+			route: RouteConfig{Path: "/users", Method: "GET", Template: "{{ fakeName }}", Deterministic: &DeterministicConfig{}},
+		},
+		{
+			name:  "date - valid",
+			route: RouteConfig{Path: "/users", Method: "GET", Template: "{{ fakeName }}", Deterministic: &DeterministicConfig{Time: "2025-06-01"}},
+		},
+		{
+			name:        "invalid time",
+			route:       RouteConfig{Path: "/users", Method: "GET", Template: "{{ fakeName }}", Deterministic: &DeterministicConfig{Time: "June 1st"}},
+			errContains: "deterministic.time",
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/users", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, Deterministic: &DeterministicConfig{}},
+			errContains: "cannot be combined with 'proxy' or 'batch'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestDeterministicConfig_GetTime(t *testing.T) {
+	tests := []struct {
+		time string
+		want time.Time
+	}{
+		{time: "", want: DefaultDeterministicTime},
+		{time: "2025-06-01", want: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{time: "2025-06-01T12:00:00+02:00", want: time.Date(2025, time.June, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := (&DeterministicConfig{Time: tt.time}).GetTime()
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("GetTime(%q) = %v, %v, want %v", tt.time, got, err, tt.want)
+		}
+	}
+}
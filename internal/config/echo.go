@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EchoBodyConfig answers with the request body, so create endpoints can
+// return what clients sent plus the fields a server would assign, without a
+// template per resource. In YAML it is either true, to echo bodies as-is, or
+// a mapping with the transforms applied to JSON bodies: select, then remove,
+// then set.
+type EchoBodyConfig struct {
+	Status int               `yaml:"status,omitempty"` // Response status code (default: 200)
+	Select string            `yaml:"select,omitempty"` // Dotted path of the part of the body to echo, e.g. "data.user"
+	Remove []string          `yaml:"remove,omitempty"` // Dotted paths of fields removed from the echoed body
+	Set    map[string]string `yaml:"set,omitempty"`    // Fields set on the echoed body, by dotted path, to the output of templates
+}
+
+// UnmarshalYAML accepts either a boolean or a mapping with the transforms
+func (e *EchoBodyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("echo_body can't be false, remove it instead")
+		}
+		*e = EchoBodyConfig{}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings EchoBodyConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("echo_body must be true or a mapping with status, select, remove and set: %w", err)
+	}
+	*e = EchoBodyConfig(decoded)
+	return nil
+}
+
+// HasTransforms checks if the echoed body is transformed rather than sent back as-is
+func (e *EchoBodyConfig) HasTransforms() bool {
+	return e.Select != "" || len(e.Remove) > 0 || len(e.Set) > 0
+}
+
+// validateEchoBody validates a route echoing request bodies, which answers
+// with the body instead of templates
+func (r *RouteConfig) validateEchoBody() error {
+	if strings.TrimSpace(r.Template) != "" || strings.TrimSpace(r.TemplateFile) != "" {
+		return NewValidationError("echo_body", "'echo_body' cannot be combined with 'template' or 'template_file'")
+	}
+	if len(r.Responses) > 0 || r.Variants != nil || r.Stream != nil {
+		return NewValidationError("echo_body", "'echo_body' cannot be combined with 'responses', 'variants' or 'stream'")
+	}
+
+	return r.EchoBody.Validate()
+}
+
+// Validate validates an EchoBodyConfig
+func (e *EchoBodyConfig) Validate() error {
+	if e.Status != 0 && (e.Status < 100 || e.Status > 599) {
+		return NewValidationError("echo_body.status", fmt.Sprintf("invalid HTTP status code %d, must be between 100 and 599", e.Status))
+	}
+
+	if e.Select != "" && !isDottedPath(e.Select) {
+		return NewValidationError("echo_body.select", fmt.Sprintf("invalid path %q, must be field names separated by dots", e.Select))
+	}
+	for _, path := range e.Remove {
+		if !isDottedPath(path) {
+			return NewValidationError("echo_body.remove", fmt.Sprintf("invalid path %q, must be field names separated by dots", path))
+		}
+	}
+	for path := range e.Set {
+		if !isDottedPath(path) {
+			return NewValidationError("echo_body.set", fmt.Sprintf("invalid path %q, must be field names separated by dots", path))
+		}
+	}
+	return nil
+}
+
+// isDottedPath checks if path is made of non-empty segments separated by dots
+func isDottedPath(path string) bool {
+	return !slices.Contains(strings.Split(path, "."), "")
+}
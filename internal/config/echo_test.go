@@ -0,0 +1,125 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestEchoBodyConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        EchoBodyConfig
+		errContains string
+	}{
+		{
+			name: "enabled",
+			yaml: `echo_body: true`,
+			want: EchoBodyConfig{},
+		},
+		{
+			name: "transforms",
+			yaml: "echo_body:\n  status: 201\n  select: data\n  remove: [password]\n  set:\n    id: \"{{ uuidv4 }}\"",
+			want: EchoBodyConfig{Status: 201, Select: "data", Remove: []string{"password"}, Set: map[string]string{"id": "{{ uuidv4 }}"}},
+		},
+		{
+			name:        "disabled",
+			yaml:        `echo_body: false`,
+			errContains: "can't be false",
+		},
+		{
+			name:        "sequence",
+			yaml:        `echo_body: [id]`,
+			errContains: "echo_body must be true or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.EchoBody == nil || !reflect.DeepEqual(*route.EchoBody, tt.want) {
+				t.Errorf("Expected echo_body %+v, got %+v", tt.want, route.EchoBody)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateEchoBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "as-is - valid",
+			route: RouteConfig{Path: "/users", Method: "POST", EchoBody: &EchoBodyConfig{}},
+		},
+		{
+			name:  "transforms - valid",
+			route: RouteConfig{Path: "/users", Method: "POST", EchoBody: &EchoBodyConfig{Status: 201, Select: "data.0", Remove: []string{"meta.secret"}, Set: map[string]string{"meta.id": "1"}}},
+		},
+		{
+			name:        "with template - invalid",
+			route:       RouteConfig{Path: "/users", Method: "POST", Template: "hi", EchoBody: &EchoBodyConfig{}},
+			errContains: "cannot be combined with 'template' or 'template_file'",
+		},
+		{
+			name:        "with responses - invalid",
+			route:       RouteConfig{Path: "/users", Method: "POST", Responses: []ResponseConfig{{Template: "hi"}}, EchoBody: &EchoBodyConfig{}},
+			errContains: "cannot be combined with 'responses', 'variants' or 'stream'",
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/users", Method: "POST", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, EchoBody: &EchoBodyConfig{}},
+			errContains: "cannot be combined with 'proxy', 'batch' or 'websocket'",
+		},
+		{
+			name:        "invalid status",
+			route:       RouteConfig{Path: "/users", Method: "POST", EchoBody: &EchoBodyConfig{Status: 99}},
+			errContains: "echo_body.status",
+		},
+		{
+			name:        "invalid select",
+			route:       RouteConfig{Path: "/users", Method: "POST", EchoBody: &EchoBodyConfig{Select: "data..user"}},
+			errContains: "echo_body.select",
+		},
+		{
+			name:        "invalid remove",
+			route:       RouteConfig{Path: "/users", Method: "POST", EchoBody: &EchoBodyConfig{Remove: []string{""}}},
+			errContains: "echo_body.remove",
+		},
+		{
+			name:        "invalid set",
+			route:       RouteConfig{Path: "/users", Method: "POST", EchoBody: &EchoBodyConfig{Set: map[string]string{"id.": "1"}}},
+			errContains: "echo_body.set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// ErrorPagesConfig represents the responses replacing built-in errors, so
+// they can be written in the error format of the API being mocked
+type ErrorPagesConfig struct {
+	TemplateError *ErrorPageConfig `yaml:"template_error,omitempty"` // Replaces the 500 sent when a template fails to render
+	Timeout       *ErrorPageConfig `yaml:"timeout,omitempty"`        // Replaces the 408 sent when a request times out
+}
+
+// ErrorPageConfig represents a response replacing a built-in error
+type ErrorPageConfig struct {
+	Status       int               `yaml:"status,omitempty"`        // Response status code (default: the built-in error's)
+	Template     string            `yaml:"template,omitempty"`      // Inline response template
+	TemplateFile string            `yaml:"template_file,omitempty"` // Response template file
+	Headers      map[string]string `yaml:"headers,omitempty"`       // Response header templates
+}
+
+// Pages returns the configured error pages by their field name, in a fixed
+// order, skipping the ones left out
+func (ep *ErrorPagesConfig) Pages() iter.Seq2[string, *ErrorPageConfig] {
+	return func(yield func(string, *ErrorPageConfig) bool) {
+		if ep == nil {
+			return
+		}
+		if ep.TemplateError != nil && !yield("template_error", ep.TemplateError) {
+			return
+		}
+		if ep.Timeout != nil {
+			yield("timeout", ep.Timeout)
+		}
+	}
+}
+
+// validateErrorPages validates the responses replacing built-in errors
+func (c *Config) validateErrorPages() error {
+	for name, page := range c.Server.ErrorPages.Pages() {
+		resp := ResponseConfig{
+			Status:          page.Status,
+			Template:        page.Template,
+			TemplateFile:    page.TemplateFile,
+			ResponseHeaders: page.Headers,
+		}
+		if err := resp.Validate(); err != nil {
+			return fmt.Errorf("server.error_pages.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateErrorPageTemplates validates the templates of the error pages by
+// attempting to compile them
+func (c *Config) validateErrorPageTemplates(engine *templatepkg.Engine) error {
+	for name, page := range c.Server.ErrorPages.Pages() {
+		var err error
+		switch {
+		case page.Template != "":
+			_, err = engine.CompileInlineTemplate("validation_error_page_"+name, page.Template)
+		case page.TemplateFile != "":
+			_, err = engine.CompileFileTemplate(page.TemplateFile)
+		}
+		if err != nil {
+			return fmt.Errorf("server.error_pages.%s template compilation failed: %w", name, err)
+		}
+
+		for header, value := range page.Headers {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			templateName := "validation_error_page_" + name + "_header_" + sanitizeTemplateNameForValidation(header)
+			if _, err := engine.CompileInlineTemplate(templateName, value); err != nil {
+				return fmt.Errorf("server.error_pages.%s response header %q template compilation failed: %w", name, header, err)
+			}
+		}
+	}
+	return nil
+}
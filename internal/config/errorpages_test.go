@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateErrorPages(t *testing.T) {
+	routes := []RouteConfig{{Path: "/users", Method: "GET", Template: "[]"}}
+
+	tests := []struct {
+		name        string
+		pages       *ErrorPagesConfig
+		errContains string
+	}{
+		{name: "not configured"},
+		{name: "valid", pages: &ErrorPagesConfig{
+			TemplateError: &ErrorPageConfig{Template: `{"error":"{{ .Error.Code }}"}`, Headers: map[string]string{"Content-Type": "application/json"}},
+			Timeout:       &ErrorPageConfig{Status: 504, Template: `{"error":"timeout"}`},
+		}},
+		{name: "missing template", pages: &ErrorPagesConfig{Timeout: &ErrorPageConfig{}}, errContains: `server.error_pages.timeout: validation error in field "template"`},
+		{name: "invalid status", pages: &ErrorPagesConfig{TemplateError: &ErrorPageConfig{Status: 42, Template: "x"}}, errContains: "server.error_pages.template_error: validation error in field \"status\""},
+		{name: "invalid template", pages: &ErrorPagesConfig{TemplateError: &ErrorPageConfig{Template: "{{ .Error "}}, errContains: "server.error_pages.template_error template compilation failed"},
+		{name: "invalid header template", pages: &ErrorPagesConfig{Timeout: &ErrorPageConfig{Template: "x", Headers: map[string]string{"X-Code": "{{ .Error "}}}, errContains: `server.error_pages.timeout: validation error in field "response_headers": invalid template syntax in response header "X-Code"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Routes: routes, Server: ServerConfig{ErrorPages: tt.pages}}
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestErrorPagesConfig_Pages(t *testing.T) {
+	var names []string
+	for name := range (*ErrorPagesConfig)(nil).Pages() {
+		names = append(names, name)
+	}
+	if len(names) != 0 {
+		t.Errorf("Expected no pages, got %v", names)
+	}
+
+	pages := &ErrorPagesConfig{TemplateError: &ErrorPageConfig{Template: "a"}, Timeout: &ErrorPageConfig{Template: "b"}}
+	for name := range pages.Pages() {
+		names = append(names, name)
+	}
+	if strings.Join(names, ",") != "template_error,timeout" {
+		t.Errorf("Expected template_error and timeout in order, got %v", names)
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+// validateEscapeHTML validates that a route escaping HTML renders its bodies
+// from templates
+func (r *RouteConfig) validateEscapeHTML() error {
+	if !r.EscapeHTML {
+		return nil
+	}
+
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil || r.StaticDir != nil {
+		return NewValidationError("escape_html", "'escape_html' cannot be combined with 'proxy', 'batch', 'websocket' or 'static_dir'")
+	}
+	if r.EchoBody != nil || r.BodyFile != "" || r.BodyEncoding != "" {
+		return NewValidationError("escape_html", "'escape_html' cannot be combined with 'echo_body', 'body_file' or 'body_encoding', which don't render HTML")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateEscapeHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "template - valid",
+			route: RouteConfig{Path: "/page", Method: "GET", Template: "<h1>{{ .Query.q }}</h1>", EscapeHTML: true},
+		},
+		{
+			name:  "responses - valid",
+			route: RouteConfig{Path: "/page", Method: "GET", Responses: []ResponseConfig{{Template: "<p>{{ .Params.id }}</p>"}}, EscapeHTML: true},
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/page", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, EscapeHTML: true},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "with body file - invalid",
+			route:       RouteConfig{Path: "/page", Method: "GET", BodyFile: "page.html", EscapeHTML: true},
+			errContains: "'body_file'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.validateEscapeHTML()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
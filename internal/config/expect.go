@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpectConfig declares how often and in which order a route is expected to
+// be called. Expectations are checked through the admin API, or when the
+// server stops with the --verify flag.
+type ExpectConfig struct {
+	Calls    *int     `yaml:"calls,omitempty"`     // Exact number of calls
+	MinCalls *int     `yaml:"min_calls,omitempty"` // Minimum number of calls
+	MaxCalls *int     `yaml:"max_calls,omitempty"` // Maximum number of calls
+	After    []string `yaml:"after,omitempty"`     // Routes, as "METHOD path", called before this route is first called
+}
+
+// ID returns the identifier of the route used by expectations and the
+// request journal, "METHOD path"
+func (r *RouteConfig) ID() string {
+	return r.GetNormalizedMethod() + " " + r.Path
+}
+
+// NormalizeRouteID normalizes a route identifier written as "METHOD path",
+// reporting false when it isn't in that form
+func NormalizeRouteID(id string) (string, bool) {
+	method, path, found := strings.Cut(strings.TrimSpace(id), " ")
+	path = strings.TrimSpace(path)
+	if !found || method == "" || path == "" {
+		return "", false
+	}
+	return strings.ToUpper(method) + " " + path, true
+}
+
+// Validate validates an ExpectConfig
+func (ec *ExpectConfig) Validate() error {
+	if ec.Calls == nil && ec.MinCalls == nil && ec.MaxCalls == nil && len(ec.After) == 0 {
+		return NewValidationError("expect", "at least one of 'calls', 'min_calls', 'max_calls' or 'after' must be specified")
+	}
+
+	if ec.Calls != nil && (ec.MinCalls != nil || ec.MaxCalls != nil) {
+		return NewValidationError("expect.calls", "'calls' cannot be combined with 'min_calls' or 'max_calls'")
+	}
+
+	for field, value := range map[string]*int{"calls": ec.Calls, "min_calls": ec.MinCalls, "max_calls": ec.MaxCalls} {
+		if value != nil && *value < 0 {
+			return NewValidationError("expect."+field, fmt.Sprintf("call count cannot be negative, got %d", *value))
+		}
+	}
+
+	if ec.MinCalls != nil && ec.MaxCalls != nil && *ec.MinCalls > *ec.MaxCalls {
+		return NewValidationError("expect.min_calls", fmt.Sprintf("min_calls (%d) cannot be greater than max_calls (%d)", *ec.MinCalls, *ec.MaxCalls))
+	}
+
+	for i, id := range ec.After {
+		if _, ok := NormalizeRouteID(id); !ok {
+			return NewValidationError(fmt.Sprintf("expect.after[%d]", i), fmt.Sprintf("invalid route %q, must be written as \"METHOD path\"", id))
+		}
+	}
+
+	return nil
+}
+
+// validateExpectations checks that the routes expectations are ordered
+// after exist
+func (c *Config) validateExpectations() error {
+	ids := make(map[string]bool, len(c.Routes))
+	for _, route := range c.Routes {
+		ids[route.ID()] = true
+	}
+
+	for i, route := range c.Routes {
+		if route.Expect == nil {
+			continue
+		}
+
+		for j, id := range route.Expect.After {
+			normalized, _ := NormalizeRouteID(id)
+			field := fmt.Sprintf("route[%d].expect.after[%d]", i, j)
+
+			switch {
+			case normalized == route.ID():
+				return NewValidationError(field, "a route cannot be expected to be called after itself")
+			case !ids[normalized]:
+				return NewValidationError(field, fmt.Sprintf("no route matches %q", id))
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestNormalizeRouteID(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected string
+		ok       bool
+	}{
+		{id: "GET /users", expected: "GET /users", ok: true},
+		{id: "post  /users/42 ", expected: "POST /users/42", ok: true},
+		{id: "GET /^/users/\\d+$/", expected: "GET /^/users/\\d+$/", ok: true},
+		{id: "/users"},
+		{id: "GET "},
+		{id: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, ok := NormalizeRouteID(tt.id)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("NormalizeRouteID(%q) = %q, %v, expected %q, %v", tt.id, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestExpectConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		expect      ExpectConfig
+		errContains string
+	}{
+		{name: "exact", expect: ExpectConfig{Calls: intPtr(2)}},
+		{name: "never", expect: ExpectConfig{Calls: intPtr(0)}},
+		{name: "range", expect: ExpectConfig{MinCalls: intPtr(1), MaxCalls: intPtr(3)}},
+		{name: "order only", expect: ExpectConfig{After: []string{"POST /login"}}},
+		{name: "empty", errContains: "at least one of"},
+		{name: "exact and range", expect: ExpectConfig{Calls: intPtr(1), MinCalls: intPtr(1)}, errContains: "cannot be combined"},
+		{name: "negative", expect: ExpectConfig{MaxCalls: intPtr(-1)}, errContains: "cannot be negative"},
+		{name: "inverted range", expect: ExpectConfig{MinCalls: intPtr(3), MaxCalls: intPtr(1)}, errContains: "cannot be greater than"},
+		{name: "malformed route", expect: ExpectConfig{After: []string{"/login"}}, errContains: `must be written as "METHOD path"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.expect.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateExpectations(t *testing.T) {
+	login := RouteConfig{Path: "/login", Method: "post", Template: "ok"}
+
+	tests := []struct {
+		name        string
+		after       string
+		errContains string
+	}{
+		{name: "existing route", after: "POST /login"},
+		{name: "method case is ignored", after: "post /login"},
+		{name: "unknown route", after: "GET /login", errContains: `no route matches "GET /login"`},
+		{name: "itself", after: "GET /profile", errContains: "after itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Routes: []RouteConfig{
+				login,
+				{Path: "/profile", Method: "GET", Template: "{}", Expect: &ExpectConfig{After: []string{tt.after}}},
+			}}
+
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// FallbackConfig represents the response served to requests matching no
+// route, in place of the built-in 404, so it can look like the errors of the
+// API being mocked
+type FallbackConfig struct {
+	Status       int               `yaml:"status,omitempty"`        // Response status code (default: 404)
+	Template     string            `yaml:"template,omitempty"`      // Inline response template
+	TemplateFile string            `yaml:"template_file,omitempty"` // Response template file
+	Headers      map[string]string `yaml:"headers,omitempty"`       // Response header templates
+}
+
+// Route returns the route serving the fallback response. It has no path, as
+// it's only served when no route matched.
+func (f *FallbackConfig) Route() RouteConfig {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusNotFound
+	}
+
+	return RouteConfig{
+		Responses: []ResponseConfig{{
+			Status:          status,
+			Template:        f.Template,
+			TemplateFile:    f.TemplateFile,
+			ResponseHeaders: f.Headers,
+		}},
+	}
+}
+
+// validateFallback validates the response served to requests matching no
+// route, when present
+func (c *Config) validateFallback() error {
+	if c.Fallback == nil {
+		return nil
+	}
+
+	if c.FallbackProxy != "" {
+		return NewValidationError("fallback", "'fallback' cannot be combined with 'fallback_proxy'")
+	}
+
+	resp := ResponseConfig{
+		Status:          c.Fallback.Status,
+		Template:        c.Fallback.Template,
+		TemplateFile:    c.Fallback.TemplateFile,
+		ResponseHeaders: c.Fallback.Headers,
+	}
+	if err := resp.Validate(); err != nil {
+		return fmt.Errorf("fallback: %w", err)
+	}
+
+	return nil
+}
+
+// validateFallbackTemplates validates the templates of the fallback response
+// by attempting to compile them
+func (c *Config) validateFallbackTemplates(engine *templatepkg.Engine) error {
+	if c.Fallback == nil {
+		return nil
+	}
+
+	var err error
+	switch {
+	case c.Fallback.Template != "":
+		_, err = engine.CompileInlineTemplate("validation_fallback", c.Fallback.Template)
+	case c.Fallback.TemplateFile != "":
+		_, err = engine.CompileFileTemplate(c.Fallback.TemplateFile)
+	}
+	if err != nil {
+		return fmt.Errorf("fallback template compilation failed: %w", err)
+	}
+
+	for name, value := range c.Fallback.Headers {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		templateName := "validation_fallback_header_" + sanitizeTemplateNameForValidation(name)
+		if _, err := engine.CompileInlineTemplate(templateName, value); err != nil {
+			return fmt.Errorf("fallback response header %q template compilation failed: %w", name, err)
+		}
+	}
+
+	return nil
+}
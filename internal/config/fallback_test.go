@@ -0,0 +1,91 @@
+package config
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateFallback(t *testing.T) {
+	routes := []RouteConfig{{Path: "/users", Method: "GET", Template: "[]"}}
+
+	tests := []struct {
+		name        string
+		config      Config
+		errContains string
+	}{
+		{name: "not configured", config: Config{Routes: routes}},
+		{name: "valid", config: Config{Routes: routes, Fallback: &FallbackConfig{Template: `{"error":"not_found"}`, Headers: map[string]string{"Content-Type": "application/json"}}}},
+		{name: "fallback without routes", config: Config{Fallback: &FallbackConfig{Status: 410, Template: "gone"}}},
+		{name: "missing template", config: Config{Routes: routes, Fallback: &FallbackConfig{Status: 404}}, errContains: `fallback: validation error in field "template"`},
+		{name: "invalid status", config: Config{Routes: routes, Fallback: &FallbackConfig{Status: 999, Template: "x"}}, errContains: "invalid HTTP status code 999"},
+		{name: "invalid header", config: Config{Routes: routes, Fallback: &FallbackConfig{Template: "x", Headers: map[string]string{"Bad Header": "x"}}}, errContains: "fallback:"},
+		{name: "with fallback proxy", config: Config{Routes: routes, FallbackProxy: "http://localhost:9000", Fallback: &FallbackConfig{Template: "x"}}, errContains: "'fallback' cannot be combined with 'fallback_proxy'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateFallbackTemplates(t *testing.T) {
+	cfg := Config{Fallback: &FallbackConfig{Template: "{{ .Method "}}
+	if err := cfg.ValidateTemplates(); err == nil || !strings.Contains(err.Error(), "fallback template compilation failed") {
+		t.Errorf("Expected a fallback template error, got %v", err)
+	}
+
+	cfg = Config{Fallback: &FallbackConfig{Template: "x", Headers: map[string]string{"X-Path": "{{ .Path "}}}
+	if err := cfg.ValidateTemplates(); err == nil || !strings.Contains(err.Error(), `fallback response header "X-Path" template compilation failed`) {
+		t.Errorf("Expected a fallback header template error, got %v", err)
+	}
+}
+
+func TestFallbackConfig_Route(t *testing.T) {
+	route := (&FallbackConfig{Template: "missing"}).Route()
+	if len(route.Responses) != 1 || route.Responses[0].Status != http.StatusNotFound {
+		t.Fatalf("Expected a single 404 response, got %+v", route.Responses)
+	}
+
+	route = (&FallbackConfig{Status: http.StatusGone, Template: "gone", Headers: map[string]string{"X-Reason": "gone"}}).Route()
+	if resp := route.Responses[0]; resp.Status != http.StatusGone || resp.Template != "gone" || resp.ResponseHeaders["X-Reason"] != "gone" {
+		t.Errorf("Expected the configured response, got %+v", resp)
+	}
+}
+
+func TestLoadConfig_FallbackTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "not-found.json"), []byte(`{"error":"not_found"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "config.yaml")
+	yaml := "fallback:\n  status: 404\n  template_file: not-found.json\n"
+	if err := os.WriteFile(file, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := filepath.Join(dir, "not-found.json")
+	if cfg.Fallback.TemplateFile != want {
+		t.Errorf("Expected the template file to resolve to %s, got %s", want, cfg.Fallback.TemplateFile)
+	}
+	if !slices.Contains(cfg.WatchFiles(), want) {
+		t.Errorf("Expected %s to be watched, got %v", want, cfg.WatchFiles())
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Fault types
+const (
+	FaultError    = "error"     // Respond with a 5xx error instead of the rendered response
+	FaultReset    = "reset"     // Close the connection without responding
+	FaultTruncate = "truncate"  // Send part of the body, then close the connection
+	FaultSlowBody = "slow_body" // Stream the body a few bytes at a time
+)
+
+// Default fault settings
+const (
+	DefaultFaultStatus    = 500
+	DefaultFaultChunkSize = 1
+	DefaultFaultInterval  = 100 * time.Millisecond
+)
+
+// FaultConfig represents a fault injected into a route's responses to test
+// how clients cope with unreliable servers
+type FaultConfig struct {
+	Type        string        `yaml:"type"`                  // "error", "reset", "truncate" or "slow_body"
+	Probability *float64      `yaml:"probability,omitempty"` // Chance of injecting the fault, from 0 to 1 (default: 1)
+	Status      int           `yaml:"status,omitempty"`      // Status code of "error" faults (default: 500)
+	Bytes       int           `yaml:"bytes,omitempty"`       // Body bytes sent by "truncate" faults (default: half the body)
+	ChunkSize   int           `yaml:"chunk_size,omitempty"`  // Bytes written at a time by "slow_body" faults (default: 1)
+	Interval    time.Duration `yaml:"interval,omitempty"`    // Pause between chunks of "slow_body" faults (default: 100ms)
+}
+
+// validateFaults validates the faults of a route
+func (r *RouteConfig) validateFaults() error {
+	if len(r.Faults) > 0 && r.Proxy != nil {
+		return NewValidationError("faults", "'faults' cannot be combined with 'proxy'")
+	}
+
+	for i, fault := range r.Faults {
+		if err := fault.Validate(); err != nil {
+			return fmt.Errorf("faults[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// faultSettings lists the optional settings that apply to each fault type
+var faultSettings = map[string][]string{
+	FaultError:    {"status"},
+	FaultReset:    {},
+	FaultTruncate: {"bytes"},
+	FaultSlowBody: {"chunk_size", "interval"},
+}
+
+// Validate validates a single FaultConfig
+func (f *FaultConfig) Validate() error {
+	allowed, found := faultSettings[f.Type]
+	if !found {
+		valid := []string{FaultError, FaultReset, FaultTruncate, FaultSlowBody}
+		return NewValidationError("type", fmt.Sprintf("invalid fault type %q, must be one of: %s", f.Type, strings.Join(valid, ", ")))
+	}
+
+	if f.Probability != nil && (*f.Probability < 0 || *f.Probability > 1) {
+		return NewValidationError("probability", fmt.Sprintf("probability %v must be between 0 and 1", *f.Probability))
+	}
+
+	// Reject settings that don't apply to the fault type, as they're likely mistakes
+	settings := []struct {
+		name string
+		set  bool
+	}{
+		{"status", f.Status != 0},
+		{"bytes", f.Bytes != 0},
+		{"chunk_size", f.ChunkSize != 0},
+		{"interval", f.Interval != 0},
+	}
+	for _, setting := range settings {
+		if setting.set && !slices.Contains(allowed, setting.name) {
+			return NewValidationError(setting.name, fmt.Sprintf("'%s' cannot be used with %q faults", setting.name, f.Type))
+		}
+	}
+
+	if f.Status != 0 && (f.Status < 500 || f.Status > 599) {
+		return NewValidationError("status", fmt.Sprintf("status %d must be between 500 and 599", f.Status))
+	}
+
+	if f.Bytes < 0 || f.ChunkSize < 0 || f.Interval < 0 {
+		return NewValidationError(f.Type, "fault settings cannot be negative")
+	}
+
+	return nil
+}
+
+// GetProbability returns the chance of injecting the fault, defaulting to always
+func (f *FaultConfig) GetProbability() float64 {
+	if f.Probability == nil {
+		return 1
+	}
+	return *f.Probability
+}
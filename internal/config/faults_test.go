@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFaultConfig_Validate(t *testing.T) {
+	half := 0.5
+	tooLikely := 1.5
+
+	tests := []struct {
+		name        string
+		fault       FaultConfig
+		errContains string
+	}{
+		{name: "error with status", fault: FaultConfig{Type: FaultError, Status: 503, Probability: &half}},
+		{name: "reset", fault: FaultConfig{Type: FaultReset}},
+		{name: "truncate with bytes", fault: FaultConfig{Type: FaultTruncate, Bytes: 10}},
+		{name: "slow body", fault: FaultConfig{Type: FaultSlowBody, ChunkSize: 4, Interval: 10 * time.Millisecond}},
+		{name: "unknown type", fault: FaultConfig{Type: "explode"}, errContains: `invalid fault type "explode"`},
+		{name: "probability out of range", fault: FaultConfig{Type: FaultReset, Probability: &tooLikely}, errContains: "between 0 and 1"},
+		{name: "non 5xx status", fault: FaultConfig{Type: FaultError, Status: 404}, errContains: "between 500 and 599"},
+		{name: "setting of another type", fault: FaultConfig{Type: FaultReset, Bytes: 10}, errContains: `'bytes' cannot be used with "reset" faults`},
+		{name: "negative bytes", fault: FaultConfig{Type: FaultTruncate, Bytes: -1}, errContains: "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fault.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateFaults(t *testing.T) {
+	route := RouteConfig{
+		Path:     "/api",
+		Method:   "GET",
+		Template: "ok",
+		Faults:   []FaultConfig{{Type: FaultReset}, {Type: "nope"}},
+	}
+	if err := route.Validate(); err == nil || !strings.Contains(err.Error(), "faults[1]") {
+		t.Errorf("Expected the invalid fault to be reported by index, got %v", err)
+	}
+
+	route = RouteConfig{
+		Path:   "/api",
+		Method: "GET",
+		Proxy:  &ProxyConfig{URL: "https://api.example.com"},
+		Faults: []FaultConfig{{Type: FaultReset}},
+	}
+	if err := route.Validate(); err == nil || !strings.Contains(err.Error(), "'proxy'") {
+		t.Errorf("Expected faults to be rejected on proxy routes, got %v", err)
+	}
+}
+
+func TestFaultConfig_GetProbability(t *testing.T) {
+	if got := (&FaultConfig{}).GetProbability(); got != 1 {
+		t.Errorf("Expected default probability 1, got %v", got)
+	}
+
+	never := 0.0
+	if got := (&FaultConfig{Probability: &never}).GetProbability(); got != 0 {
+		t.Errorf("Expected probability 0, got %v", got)
+	}
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// configExtensions are the file extensions loaded from configuration directories
+var configExtensions = []string{".yaml", ".yml"}
+
+// IsConfigFile reports whether a file inside a configuration directory is
+// loaded as part of the configuration
+func IsConfigFile(name string) bool {
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, ".") {
+		return false
+	}
+
+	ext := strings.ToLower(filepath.Ext(base))
+	for _, allowed := range configExtensions {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveConfigFiles expands the given configuration paths into the files to
+// load, in order. Files are kept as given, while directories are walked
+// recursively and contribute their YAML files sorted by path. Hidden files and
+// directories are skipped, and files listed more than once are loaded once.
+func ResolveConfigFiles(paths []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	add := func(file string) {
+		if key := filepath.Clean(file); !seen[key] {
+			seen[key] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			add(path) // Reported when the file is loaded
+			continue
+		}
+
+		found, err := configFilesInDir(path)
+		if err != nil {
+			return nil, NewLoadError(path, err)
+		}
+		if len(found) == 0 {
+			return nil, NewLoadError(path, fmt.Errorf("config directory %q has no %s files", path, strings.Join(configExtensions, " or ")))
+		}
+		for _, file := range found {
+			add(file)
+		}
+	}
+
+	return files, nil
+}
+
+// configFilesInDir returns the configuration files inside a directory and its
+// subdirectories, in lexical order
+func configFilesInDir(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if IsConfigFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory %q: %w", dir, err)
+	}
+
+	return files, nil
+}
+
+// WatchPaths returns the paths to watch for changes to the given configuration
+// paths: files as given, and directories along with all their subdirectories,
+// so files added to them are noticed too
+func WatchPaths(paths []string) []string {
+	var watch []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			watch = append(watch, path)
+			continue
+		}
+
+		_ = filepath.WalkDir(path, func(sub string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil // Unreadable entries are reported when the configuration loads
+			}
+			if sub != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			watch = append(watch, sub)
+			return nil
+		})
+	}
+
+	return watch
+}
+
+// WatchFiles returns the files and directories the configuration depends on
+// beyond the paths it was loaded from: files pulled in by include directives,
+// the directories include patterns are matched in, so new matches are noticed,
+// partials, gRPC descriptor sets and the files responses are rendered from
+func (c *Config) WatchFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+
+	add := func(file string) {
+		if key := filepath.Clean(file); file != "" && !seen[key] {
+			seen[key] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, file := range c.included {
+		add(file)
+	}
+	for _, dir := range c.includeDirs {
+		add(dir)
+	}
+	if c.Template.PartialsDir != "" {
+		partials, _ := templatepkg.PartialFiles(c.Template.PartialsDir) // Unreadable directories fail validation
+		for _, file := range partials {
+			add(file)
+		}
+	}
+	if c.GRPC != nil {
+		for _, file := range c.GRPC.DescriptorSets {
+			add(file)
+		}
+	}
+	for _, file := range c.templateFiles() {
+		add(file)
+	}
+
+	return files
+}
+
+// templateFiles returns the template and body files responses are rendered
+// from, across routes, the fallback response, error pages and gRPC methods,
+// possibly more than once
+func (c *Config) templateFiles() []string {
+	var files []string
+
+	add := func(file string) {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	for _, route := range c.Routes {
+		add(route.TemplateFile)
+		add(route.BodyFile)
+		for _, response := range route.Responses {
+			add(response.TemplateFile)
+		}
+		if route.Variants != nil {
+			for _, name := range route.Variants.Names() {
+				add(route.Variants.Cases[name].TemplateFile)
+			}
+		}
+		if route.WebSocket != nil {
+			for _, msg := range route.WebSocket.Messages {
+				add(msg.TemplateFile)
+			}
+		}
+	}
+	if c.Fallback != nil {
+		add(c.Fallback.TemplateFile)
+	}
+	for _, page := range c.Server.ErrorPages.Pages() {
+		add(page.TemplateFile)
+	}
+	if c.GRPC != nil {
+		for _, method := range c.GRPC.Methods {
+			add(method.TemplateFile)
+		}
+	}
+
+	return files
+}
+
+// merge adds the configuration parsed from file to c. Routes and tenants are
+// appended in file order and named entries, like tokens, are combined, while
+// every other top-level section can only be defined by one file. owners tracks
+// which file defined what, across calls.
+func (c *Config) merge(other *Config, file string, owners map[string]string) error {
+	dst := reflect.ValueOf(c).Elem()
+	src := reflect.ValueOf(other).Elem()
+
+	for i := range dst.NumField() {
+		field := dst.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		value := src.Field(i)
+		if name == "" || name == "-" || value.IsZero() {
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Slice:
+			dst.Field(i).Set(reflect.AppendSlice(dst.Field(i), value))
+
+		case reflect.Map:
+			if dst.Field(i).IsNil() {
+				dst.Field(i).Set(reflect.MakeMap(field.Type))
+			}
+
+			iter := value.MapRange()
+			for iter.Next() {
+				key := fmt.Sprintf("%s.%v", name, iter.Key())
+				if owner, ok := owners[key]; ok {
+					return NewValidationError(name, fmt.Sprintf("%q is already defined in %q", iter.Key(), owner))
+				}
+				owners[key] = file
+				dst.Field(i).SetMapIndex(iter.Key(), iter.Value())
+			}
+
+		default:
+			if owner, ok := owners[name]; ok {
+				return NewValidationError(name, fmt.Sprintf("already defined in %q, top-level settings can only be defined in one file", owner))
+			}
+			owners[name] = file
+			dst.Field(i).Set(value)
+		}
+	}
+
+	return nil
+}
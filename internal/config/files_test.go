@@ -0,0 +1,305 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles creates files with the given contents under dir, creating
+// parent directories as needed
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %q: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %q: %v", name, err)
+		}
+	}
+}
+
+func TestIsConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"users.yaml", true},
+		{"dir/users.yml", true},
+		{"USERS.YAML", true},
+		{"users.json", false},
+		{"users.yaml.swp", false},
+		{".users.yaml", false},
+		{"users", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsConfigFile(tt.name); got != tt.expected {
+			t.Errorf("IsConfigFile(%q) = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestResolveConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"b-orders.yaml":          "",
+		"a-users.yml":            "",
+		"notes.txt":              "",
+		".hidden.yaml":           "",
+		"payments/charges.yaml":  "",
+		".git/config.yaml":       "",
+		"z-extra/refunds.yaml":   "",
+		"payments/README.md":     "",
+		"payments/accounts.yaml": "",
+	})
+	extra := filepath.Join(dir, "a-users.yml")
+
+	files, err := ResolveConfigFiles([]string{dir, extra, "standalone.yaml"})
+	if err != nil {
+		t.Fatalf("ResolveConfigFiles() unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, file := range files {
+		if rel, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = filepath.ToSlash(rel)
+		}
+		got = append(got, file)
+	}
+
+	expected := []string{
+		"a-users.yml",
+		"b-orders.yaml",
+		"payments/accounts.yaml",
+		"payments/charges.yaml",
+		"z-extra/refunds.yaml",
+		"standalone.yaml",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected files %v, got %v", expected, got)
+	}
+
+	t.Run("empty directory", func(t *testing.T) {
+		_, err := ResolveConfigFiles([]string{t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "has no .yaml or .yml files") {
+			t.Errorf("Expected empty directory error, got %v", err)
+		}
+	})
+}
+
+func TestWatchPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"users.yaml":            "",
+		"payments/charges.yaml": "",
+		".git/config":           "",
+	})
+
+	got := WatchPaths([]string{dir, "missing.yaml"})
+	expected := []string{dir, filepath.Join(dir, "payments"), "missing.yaml"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected watch paths %v, got %v", expected, got)
+	}
+}
+
+func TestConfig_WatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"main.yaml": `include:
+  - shared.yaml
+  - "services/*.yaml"
+routes:
+  - path: /users
+    method: GET
+    template_file: ` + filepath.Join(dir, "users.tmpl") + `
+  - path: /orders
+    method: GET
+    responses:
+      - template_file: ` + filepath.Join(dir, "orders.tmpl") + `
+      - template_file: ` + filepath.Join(dir, "users.tmpl") + `
+  - path: /feed
+    method: GET
+    websocket:
+      messages:
+        - template_file: ` + filepath.Join(dir, "greeting.tmpl") + `
+  - path: /checkout
+    method: GET
+    variants:
+      selector: '{{ .Headers.Get "X-Experiment" }}'
+      default: control
+      cases:
+        control:
+          template_file: ` + filepath.Join(dir, "users.tmpl") + `
+        treatment:
+          template_file: ` + filepath.Join(dir, "checkout.tmpl") + `
+  - path: /logo.png
+    method: GET
+    body_file: ` + filepath.Join(dir, "logo.png"),
+		"shared.yaml": `routes:
+  - path: /health
+    method: GET
+    template: "ok"`,
+		"services/billing.yaml": `routes:
+  - path: /invoices
+    method: GET
+    template_file: ` + filepath.Join(dir, "invoices.tmpl"),
+		"users.tmpl":    "users",
+		"orders.tmpl":   "orders",
+		"invoices.tmpl": "invoices",
+		"greeting.tmpl": "hello",
+		"checkout.tmpl": "checkout",
+		"logo.png":      "\x89PNG",
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "shared.yaml"),
+		filepath.Join(dir, "services/billing.yaml"),
+		filepath.Join(dir, "services"),
+		filepath.Join(dir, "users.tmpl"),
+		filepath.Join(dir, "orders.tmpl"),
+		filepath.Join(dir, "greeting.tmpl"),
+		filepath.Join(dir, "checkout.tmpl"),
+		filepath.Join(dir, "logo.png"),
+		filepath.Join(dir, "invoices.tmpl"),
+	}
+	if got := cfg.WatchFiles(); !slices.Equal(got, expected) {
+		t.Errorf("Expected watch files %v, got %v", expected, got)
+	}
+}
+
+func TestLoadConfigs(t *testing.T) {
+	t.Run("merges directory and files in order", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"routes/users.yaml": `routes:
+  - path: /users
+    method: GET
+    template: "users"`,
+			"routes/orders.yaml": `routes:
+  - path: /orders
+    method: GET
+    template: "orders"
+token_bucket:
+  access:
+    ttl: 1m`,
+			"settings.yaml": `server:
+  dev_mode: true
+token_bucket:
+  refresh:
+    ttl: 1h`,
+		})
+		extra := filepath.Join(t.TempDir(), "health.yaml")
+		writeConfigFiles(t, filepath.Dir(extra), map[string]string{"health.yaml": `routes:
+  - path: /ping
+    method: GET
+    template: "pong"`})
+
+		cfg, err := LoadConfigs([]string{dir, extra})
+		if err != nil {
+			t.Fatalf("LoadConfigs() unexpected error: %v", err)
+		}
+
+		var paths []string
+		for _, route := range cfg.Routes {
+			paths = append(paths, route.Path)
+		}
+		if expected := []string{"/orders", "/users", "/ping"}; !slices.Equal(paths, expected) {
+			t.Errorf("Expected routes %v, got %v", expected, paths)
+		}
+		if !cfg.Server.DevMode {
+			t.Error("Expected server settings from settings.yaml")
+		}
+		if len(cfg.TokenBucket) != 2 {
+			t.Errorf("Expected 2 tokens, got %v", cfg.TokenBucket)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		errContains []string
+	}{
+		{
+			name: "route error names its file",
+			files: map[string]string{
+				"a.yaml": `routes:
+  - path: /ok
+    method: GET
+    template: "ok"`,
+				"b.yaml": `routes:
+  - path: /bad
+    method: FETCH
+    template: "bad"`,
+			},
+			errContains: []string{"b.yaml", "route[0]", "invalid HTTP method"},
+		},
+		{
+			name: "section defined twice",
+			files: map[string]string{
+				"a.yaml": `routes:
+  - path: /ok
+    method: GET
+    template: "ok"
+errors:
+  format: json`,
+				"b.yaml": `errors:
+  format: text`,
+			},
+			errContains: []string{"b.yaml", `field "errors"`, "a.yaml", "only be defined in one file"},
+		},
+		{
+			name: "token defined twice",
+			files: map[string]string{
+				"a.yaml": `routes:
+  - path: /ok
+    method: GET
+    template: "ok"
+token_bucket:
+  access:
+    ttl: 1m`,
+				"b.yaml": `token_bucket:
+  access:
+    ttl: 5m`,
+			},
+			errContains: []string{"b.yaml", `"access" is already defined`},
+		},
+		{
+			name: "no routes in any file",
+			files: map[string]string{
+				"a.yaml": `server:
+  dev_mode: true`,
+				"b.yaml": `errors:
+  format: json`,
+			},
+			errContains: []string{"at least one route must be defined"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFiles(t, dir, tt.files)
+
+			_, err := LoadConfigs([]string{dir})
+			if err == nil {
+				t.Fatal("LoadConfigs() expected error but got none")
+			}
+			for _, want := range tt.errContains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfigs() error = %v, want error containing %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+)
+
+// GroupConfig represents routes sharing a path prefix and defaults, so mocks
+// of large APIs don't repeat them on every route. Routes keep the settings
+// they define themselves, and groups can hold groups of their own.
+type GroupConfig struct {
+	Prefix          string              `yaml:"prefix,omitempty"`           // Path prepended to the path of every route, like "/api/v1"
+	MatchHeaders    map[string]string   `yaml:"match_headers,omitempty"`    // Headers every route requires, unless a route matches the same header
+	MatchCookies    map[string]string   `yaml:"match_cookies,omitempty"`    // Cookies every route requires, unless a route matches the same cookie
+	ResponseHeaders map[string]string   `yaml:"response_headers,omitempty"` // Headers every route sends, unless a route sets the same header
+	Delay           *DelayConfig        `yaml:"delay,omitempty"`            // Delay of routes without their own
+	RequireToken    *RequireTokenConfig `yaml:"require_token,omitempty"`    // Token required by routes without their own
+	Faults          []FaultConfig       `yaml:"faults,omitempty"`           // Faults of routes without their own
+	Compression     *CompressionConfig  `yaml:"compression,omitempty"`      // Compression of routes without their own
+	Concurrency     *ConcurrencyConfig  `yaml:"concurrency,omitempty"`      // Concurrency limit of each route without its own
+	Routes          []RouteConfig       `yaml:"routes,omitempty"`
+	Groups          []GroupConfig       `yaml:"groups,omitempty"` // Nested groups, adding to this group's prefix and defaults
+
+	// Middleware wrapping every route, around the middleware of nested groups and routes
+	Middleware []middleware.MiddlewareConfig `yaml:"middleware,omitempty"`
+}
+
+// expandGroups moves the routes of groups into the configuration's routes,
+// after the routes defined outside groups and in the order groups are
+// defined, so the rest of the configuration only deals with plain routes
+func (c *Config) expandGroups() error {
+	for i, group := range c.Groups {
+		routes, err := group.expand(fmt.Sprintf("groups[%d]", i), GroupConfig{})
+		if err != nil {
+			return err
+		}
+		c.Routes = append(c.Routes, routes...)
+	}
+
+	c.Groups = nil
+	return nil
+}
+
+// expand returns the routes of the group and of its nested groups, with the
+// prefix and defaults of parent, the group containing it, applied. field
+// locates the group in errors.
+func (g GroupConfig) expand(field string, parent GroupConfig) ([]RouteConfig, error) {
+	if g.Prefix != "" && !strings.HasPrefix(g.Prefix, "/") {
+		return nil, NewValidationError(field+".prefix", fmt.Sprintf("prefix %q must start with '/'", g.Prefix))
+	}
+	if _, err := compilePathTemplate(g.Prefix); err != nil {
+		return nil, NewValidationError(field+".prefix", fmt.Sprintf("invalid prefix %q: %v", g.Prefix, err))
+	}
+	if len(g.Routes) == 0 && len(g.Groups) == 0 {
+		return nil, NewValidationError(field, "group must define routes or groups")
+	}
+
+	g = g.inherit(parent)
+
+	routes := make([]RouteConfig, 0, len(g.Routes))
+	for i, route := range g.Routes {
+		route, err := g.apply(route)
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("%s.routes[%d].path", field, i), err.Error())
+		}
+		routes = append(routes, route)
+	}
+
+	for i, nested := range g.Groups {
+		nestedRoutes, err := nested.expand(fmt.Sprintf("%s.groups[%d]", field, i), g)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, nestedRoutes...)
+	}
+
+	return routes, nil
+}
+
+// inherit returns the group with the prefix of parent prepended to its own,
+// and with the defaults of parent it doesn't override
+func (g GroupConfig) inherit(parent GroupConfig) GroupConfig {
+	g.Prefix = strings.TrimSuffix(parent.Prefix, "/") + strings.TrimSuffix(g.Prefix, "/")
+	g.MatchHeaders = mergeHeaderDefaults(parent.MatchHeaders, g.MatchHeaders)
+	g.MatchCookies = mergeDefaults(parent.MatchCookies, g.MatchCookies)
+	g.ResponseHeaders = mergeHeaderDefaults(parent.ResponseHeaders, g.ResponseHeaders)
+	g.Delay = copyDefault(g.Delay, parent.Delay)
+	g.RequireToken = copyDefault(g.RequireToken, parent.RequireToken)
+	g.Compression = copyDefault(g.Compression, parent.Compression)
+	g.Concurrency = copyDefault(g.Concurrency, parent.Concurrency)
+	if g.Faults == nil {
+		g.Faults = parent.Faults
+	}
+	g.Middleware = concatDefaults(parent.Middleware, g.Middleware)
+	return g
+}
+
+// apply returns route with the group's prefix and the defaults it doesn't
+// override. Settings are copied, so routes never share them.
+func (g GroupConfig) apply(route RouteConfig) (RouteConfig, error) {
+	switch {
+	case g.Prefix == "":
+	case route.IsRegexPattern():
+		// Regexes get the prefix, as a regex in case it names parameters,
+		// right after their start anchor, since unanchored ones match
+		// anywhere in the path
+		pattern := route.GetRegexPattern()
+		if !strings.HasPrefix(pattern, "^") {
+			return route, fmt.Errorf("regex path %q must start with '^' to be prefixed with %q", route.Path, g.Prefix)
+		}
+		prefix, err := compilePathTemplate(g.Prefix)
+		if err != nil {
+			return route, fmt.Errorf("invalid prefix %q: %w", g.Prefix, err)
+		}
+		route.Path = "/" + strings.TrimSuffix(prefix, "$") + strings.TrimPrefix(pattern, "^") + "/"
+	case route.Path == "" || route.Path == "/":
+		// The group's own path is its prefix, without a trailing slash,
+		// which would make it a regex
+		route.Path = g.Prefix
+	default:
+		route.Path = g.Prefix + "/" + strings.TrimPrefix(route.Path, "/")
+	}
+
+	route.MatchHeaders = mergeHeaderDefaults(g.MatchHeaders, route.MatchHeaders)
+	route.MatchCookies = mergeDefaults(g.MatchCookies, route.MatchCookies)
+	route.ResponseHeaders = mergeHeaderDefaults(g.ResponseHeaders, route.ResponseHeaders)
+	route.Delay = copyDefault(route.Delay, g.Delay)
+	route.RequireToken = copyDefault(route.RequireToken, g.RequireToken)
+	route.Compression = copyDefault(route.Compression, g.Compression)
+	route.Concurrency = copyDefault(route.Concurrency, g.Concurrency)
+	if route.Faults == nil {
+		route.Faults = slices.Clone(g.Faults)
+	}
+	route.Middleware = concatDefaults(g.Middleware, route.Middleware)
+	return route, nil
+}
+
+// mergeDefaults returns a copy of defaults with the entries of overrides
+// added on top, or nil when both are empty
+func mergeDefaults(defaults, overrides map[string]string) map[string]string {
+	if len(defaults) == 0 && len(overrides) == 0 {
+		return overrides
+	}
+
+	merged := maps.Clone(defaults)
+	if merged == nil {
+		merged = make(map[string]string, len(overrides))
+	}
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// mergeHeaderDefaults merges headers like mergeDefaults, matching their names
+// regardless of case
+func mergeHeaderDefaults(defaults, overrides map[string]string) map[string]string {
+	defaults = maps.Clone(defaults)
+	for name := range overrides {
+		maps.DeleteFunc(defaults, func(def, _ string) bool { return strings.EqualFold(def, name) })
+	}
+	return mergeDefaults(defaults, overrides)
+}
+
+// concatDefaults returns a new slice with the entries of defaults followed by
+// those of values, or values when there are no defaults
+func concatDefaults[T any](defaults, values []T) []T {
+	if len(defaults) == 0 {
+		return values
+	}
+	return slices.Concat(defaults, values)
+}
+
+// copyDefault returns value when it's set, or a copy of def otherwise
+func copyDefault[T any](value, def *T) *T {
+	if value != nil || def == nil {
+		return value
+	}
+	copied := *def
+	return &copied
+}
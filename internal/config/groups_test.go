@@ -0,0 +1,187 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfig_Groups(t *testing.T) {
+	data := `
+routes:
+  - path: /health
+    method: GET
+    template: ok
+groups:
+  - prefix: /api/v1/
+    match_headers:
+      X-Api-Key: secret
+    response_headers:
+      Cache-Control: no-store
+      X-Version: "1"
+    delay: 10ms
+    faults:
+      - type: error
+        probability: 0.1
+    routes:
+      - path: /
+        method: GET
+        template: index
+      - path: '/^/users/(?P<id>\d+)$/'
+        method: GET
+        template: user
+        delay: 50ms
+        response_headers:
+          x-version: "2"
+      - path: /users
+        method: POST
+        template: created
+        faults: []
+    groups:
+      - prefix: /admin
+        match_headers:
+          x-api-key: admin
+        concurrency: 2
+        routes:
+          - path: /stats
+            method: GET
+            template: stats
+`
+	cfg, err := ParseConfig("groups.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(cfg.Groups) != 0 {
+		t.Errorf("Expected groups to be expanded, got %d left", len(cfg.Groups))
+	}
+
+	var paths []string
+	for _, route := range cfg.Routes {
+		paths = append(paths, route.Method+" "+route.Path)
+	}
+	expected := []string{"GET /health", "GET /api/v1", `GET /^/api/v1/users/(?P<id>\d+)$/`, "POST /api/v1/users", "GET /api/v1/admin/stats"}
+	if strings.Join(paths, ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("Expected routes %v, got %v", expected, paths)
+	}
+
+	health, index, user, create, stats := cfg.Routes[0], cfg.Routes[1], cfg.Routes[2], cfg.Routes[3], cfg.Routes[4]
+	if health.MatchHeaders != nil || health.Delay != nil {
+		t.Errorf("Expected routes outside groups to be left alone, got %+v", health)
+	}
+
+	if !maps.Equal(index.MatchHeaders, map[string]string{"X-Api-Key": "secret"}) || index.Delay == nil || index.Delay.Min != 10*time.Millisecond || len(index.Faults) != 1 {
+		t.Errorf("Expected the group's defaults, got %+v", index)
+	}
+	if !maps.Equal(user.ResponseHeaders, map[string]string{"Cache-Control": "no-store", "x-version": "2"}) {
+		t.Errorf("Expected the route's header to replace the group's, got %v", user.ResponseHeaders)
+	}
+	if user.Delay.Min != 50*time.Millisecond || index.Delay == user.Delay {
+		t.Errorf("Expected the route's own delay, got %+v", user.Delay)
+	}
+	if len(create.Faults) != 0 {
+		t.Errorf("Expected an empty list to turn faults off, got %+v", create.Faults)
+	}
+
+	if !maps.Equal(stats.MatchHeaders, map[string]string{"x-api-key": "admin"}) {
+		t.Errorf("Expected the nested group's header to replace its parent's, got %v", stats.MatchHeaders)
+	}
+	if stats.Concurrency == nil || stats.Concurrency.Limit != 2 || stats.Delay == nil || len(stats.ResponseHeaders) != 2 {
+		t.Errorf("Expected the defaults of both groups, got %+v", stats)
+	}
+}
+
+func TestParseConfig_GroupMiddleware(t *testing.T) {
+	data := `
+groups:
+  - prefix: /admin
+    middleware:
+      - type: basicauth
+        config: {username: admin, password: secret}
+    routes:
+      - path: /stats
+        method: GET
+        template: stats
+        middleware:
+          - type: timeout
+            config: {duration: 5s}
+    groups:
+      - prefix: /reports
+        middleware:
+          - type: bodylimit
+            config: {max_bytes: 1024}
+        routes:
+          - path: /daily
+            method: GET
+            template: daily
+`
+	cfg, err := ParseConfig("groups.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	types := func(route RouteConfig) string {
+		var names []string
+		for _, mc := range route.Middleware {
+			names = append(names, mc.Type)
+		}
+		return strings.Join(names, ", ")
+	}
+
+	if got := types(cfg.Routes[0]); got != "basicauth, timeout" {
+		t.Errorf("Expected the group's middleware around the route's own, got %q", got)
+	}
+	if got := types(cfg.Routes[1]); got != "basicauth, bodylimit" {
+		t.Errorf("Expected the parent group's middleware around the nested group's, got %q", got)
+	}
+
+	// Routes get their own copy, so appending to one doesn't leak into another
+	cfg.Routes[0].Middleware[0].Type = "changed"
+	if cfg.Routes[1].Middleware[0].Type != "basicauth" {
+		t.Errorf("Expected routes not to share middleware, got %q", cfg.Routes[1].Middleware[0].Type)
+	}
+}
+
+func TestParseConfig_GroupErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		errContains string
+	}{
+		{
+			name:        "relative prefix",
+			yaml:        "groups:\n  - prefix: api\n    routes:\n      - {path: /a, method: GET, template: a}",
+			errContains: `validation error in field "groups[0].prefix": prefix "api" must start with '/'`,
+		},
+		{
+			name:        "empty group",
+			yaml:        "groups:\n  - prefix: /api\n    groups:\n      - prefix: /v1",
+			errContains: `validation error in field "groups[0].groups[0]": group must define routes or groups`,
+		},
+		{
+			name:        "unanchored regex path",
+			yaml:        "groups:\n  - prefix: /api\n    routes:\n      - {path: '/users$/', method: GET, template: a}",
+			errContains: `validation error in field "groups[0].routes[0].path": regex path "/users$/" must start with '^' to be prefixed with "/api"`,
+		},
+		{
+			name:        "invalid middleware",
+			yaml:        "groups:\n  - prefix: /api\n    middleware:\n      - {type: timeout, config: {duration: soon}}\n    routes:\n      - {path: /a, method: GET, template: a}",
+			errContains: `validation error in field "middleware[0]": invalid timeout middleware config field "duration"`,
+		},
+		{
+			name:        "invalid route",
+			yaml:        "groups:\n  - prefix: /api\n    routes:\n      - {path: /a, method: FETCH, template: a}",
+			errContains: "route[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig("groups.yaml", []byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
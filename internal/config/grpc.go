@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	grpcpkg "github.com/patrickdappollonio/mockingjay/internal/grpc"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// GRPCConfig serves mocked gRPC methods over HTTP/2, described by protobuf
+// descriptor sets as written by "protoc --descriptor_set_out --include_imports"
+type GRPCConfig struct {
+	DescriptorSets []string           `yaml:"descriptor_sets"` // FileDescriptorSet files describing the services
+	Methods        []GRPCMethodConfig `yaml:"methods"`         // Mocked methods
+}
+
+// GRPCMethodConfig mocks a gRPC method. Templates render the JSON form of the
+// response message, or a JSON array of messages for server streaming methods.
+type GRPCMethodConfig struct {
+	Method       string `yaml:"method"`                  // Full method name, e.g. "greeter.v1.Greeter/SayHello"
+	Template     string `yaml:"template,omitempty"`      // Inline template rendering the response
+	TemplateFile string `yaml:"template_file,omitempty"` // File with the template rendering the response
+	Status       string `yaml:"status,omitempty"`        // Status code name answered with, e.g. "NOT_FOUND" (default: "OK")
+	Message      string `yaml:"message,omitempty"`       // Status message answered with
+}
+
+// GetStatus returns the status code the method answers with, using the
+// default when unset
+func (mc *GRPCMethodConfig) GetStatus() grpcpkg.Code {
+	code, _ := grpcpkg.ParseCode(mc.Status)
+	return code
+}
+
+// Validate validates the gRPC settings, loading the descriptor sets and
+// resolving the mocked methods in them
+func (gc *GRPCConfig) Validate() error {
+	if len(gc.DescriptorSets) == 0 {
+		return NewValidationError("grpc.descriptor_sets", "at least one descriptor set must be specified")
+	}
+
+	files, err := grpcpkg.LoadDescriptorSets(gc.DescriptorSets)
+	if err != nil {
+		return NewValidationError("grpc.descriptor_sets", err.Error())
+	}
+
+	if len(gc.Methods) == 0 {
+		return NewValidationError("grpc.methods", "at least one method must be specified")
+	}
+
+	seen := make(map[string]bool)
+	for i, method := range gc.Methods {
+		field := fmt.Sprintf("grpc.methods[%d]", i)
+
+		if _, err := grpcpkg.FindMethod(files, method.Method); err != nil {
+			return NewValidationError(field+".method", err.Error())
+		}
+
+		name, _ := grpcpkg.NormalizeMethodName(method.Method)
+		if seen[name] {
+			return NewValidationError(field+".method", fmt.Sprintf("method %q is mocked more than once", name))
+		}
+		seen[name] = true
+
+		if strings.TrimSpace(method.Template) != "" && strings.TrimSpace(method.TemplateFile) != "" {
+			return NewValidationError(field, "'template' and 'template_file' cannot both be specified")
+		}
+
+		if method.Status != "" {
+			if _, ok := grpcpkg.ParseCode(method.Status); !ok {
+				return NewValidationError(field+".status", fmt.Sprintf("unknown status %q, must be one of: %s", method.Status, strings.Join(grpcpkg.CodeNames(), ", ")))
+			}
+		}
+
+		hasTemplate := strings.TrimSpace(method.Template) != "" || strings.TrimSpace(method.TemplateFile) != ""
+		if method.GetStatus() == grpcpkg.OK && !hasTemplate {
+			return NewValidationError(field, "either 'template' or 'template_file' must be specified, unless answering with a non-OK 'status'")
+		}
+		if method.GetStatus() != grpcpkg.OK && hasTemplate {
+			return NewValidationError(field, "'template' and 'template_file' cannot be combined with a non-OK 'status', errors carry no messages")
+		}
+		if method.GetStatus() == grpcpkg.OK && method.Message != "" {
+			return NewValidationError(field+".message", "'message' requires a non-OK 'status'")
+		}
+	}
+
+	return nil
+}
+
+// validateGRPC validates the gRPC settings, when present
+func (c *Config) validateGRPC() error {
+	if c.GRPC == nil {
+		return nil
+	}
+	return c.GRPC.Validate()
+}
+
+// validateGRPCTemplates validates the templates of the mocked gRPC methods by
+// attempting to compile them
+func (c *Config) validateGRPCTemplates(engine *templatepkg.Engine) error {
+	if c.GRPC == nil {
+		return nil
+	}
+
+	for i, method := range c.GRPC.Methods {
+		var err error
+		switch {
+		case method.Template != "":
+			templateName := fmt.Sprintf("validation_grpc_%d_%s", i, sanitizeTemplateNameForValidation(method.Method))
+			_, err = engine.CompileInlineTemplate(templateName, method.Template)
+		case method.TemplateFile != "":
+			_, err = engine.CompileFileTemplate(method.TemplateFile)
+		}
+		if err != nil {
+			return fmt.Errorf("grpc.methods[%d] template compilation failed: %w", i, err)
+		}
+	}
+
+	return nil
+}
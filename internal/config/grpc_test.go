@@ -0,0 +1,119 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testDescriptorSet describes a greeter service with unary, server streaming
+// and client streaming methods, shared with the grpc package's tests
+var testDescriptorSet = filepath.Join("..", "grpc", "testdata", "greeter.pb")
+
+func TestGRPCConfig_Validate(t *testing.T) {
+	descriptors := []string{testDescriptorSet}
+
+	tests := []struct {
+		name        string
+		grpc        GRPCConfig
+		errContains string
+	}{
+		{
+			name: "templated method",
+			grpc: GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: `{"name": "{{ .Body.name }}"}`}}},
+		},
+		{
+			name: "failing method",
+			grpc: GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "/greeter.v1.Greeter/SayHello", Status: "not_found", Message: "no such greeting"}}},
+		},
+		{
+			name:        "no descriptor sets",
+			grpc:        GRPCConfig{Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{}"}}},
+			errContains: "at least one descriptor set",
+		},
+		{
+			name:        "missing descriptor set",
+			grpc:        GRPCConfig{DescriptorSets: []string{filepath.Join(t.TempDir(), "missing.pb")}, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{}"}}},
+			errContains: "failed to read descriptor set",
+		},
+		{
+			name:        "no methods",
+			grpc:        GRPCConfig{DescriptorSets: descriptors},
+			errContains: "at least one method",
+		},
+		{
+			name:        "unknown method",
+			grpc:        GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayBye", Template: "{}"}}},
+			errContains: `method "SayBye" not found`,
+		},
+		{
+			name: "duplicate method",
+			grpc: GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{
+				{Method: "greeter.v1.Greeter/SayHello", Template: "{}"},
+				{Method: "/greeter.v1.Greeter/SayHello", Template: "{}"},
+			}},
+			errContains: "mocked more than once",
+		},
+		{
+			name:        "template and template file",
+			grpc:        GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{}", TemplateFile: "hello.json"}}},
+			errContains: "cannot both be specified",
+		},
+		{
+			name:        "unknown status",
+			grpc:        GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Status: "GONE"}}},
+			errContains: `unknown status "GONE"`,
+		},
+		{
+			name:        "no template",
+			grpc:        GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello"}}},
+			errContains: "either 'template' or 'template_file' must be specified",
+		},
+		{
+			name:        "template with error status",
+			grpc:        GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{}", Status: "INTERNAL"}}},
+			errContains: "cannot be combined with a non-OK 'status'",
+		},
+		{
+			name:        "message without error status",
+			grpc:        GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{}", Message: "hello"}}},
+			errContains: "'message' requires a non-OK 'status'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.grpc.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateGRPC(t *testing.T) {
+	descriptors := []string{testDescriptorSet}
+
+	// gRPC methods are enough to serve without any route
+	cfg := &Config{GRPC: &GRPCConfig{DescriptorSets: descriptors, Methods: []GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{}"}}}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Their templates are compiled along with the routes'
+	cfg.GRPC.Methods[0].Template = "{{ .Body.name"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "grpc.methods[0] template compilation failed") {
+		t.Errorf("Expected a template compilation error, got %v", err)
+	}
+
+	cfg.GRPC.Methods[0].Template = "{}"
+	if got := cfg.WatchFiles(); len(got) != 1 || got[0] != descriptors[0] {
+		t.Errorf("Expected the descriptor set to be watched, got %v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeadConfig sets how a GET route answers HEAD requests, which it serves
+// without a body when no route handles HEAD for the path
+type HeadConfig struct {
+	Render        *bool `yaml:"render,omitempty"`         // Render the body and discard it, for templates with side effects (default: true)
+	ContentLength *bool `yaml:"content_length,omitempty"` // Send the Content-Length of the body a GET would get (default: the value of render)
+}
+
+// validateHead validates how a route answers HEAD requests
+func (r *RouteConfig) validateHead() error {
+	if r.Head == nil {
+		return nil
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(r.Method), http.MethodGet) {
+		return NewValidationError("head", "'head' can only be used on GET routes")
+	}
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil || r.StaticDir != nil || r.Raw != nil || r.Stream != nil {
+		return NewValidationError("head", "'head' cannot be combined with 'proxy', 'batch', 'websocket', 'static_dir', 'raw' or 'stream'")
+	}
+
+	return r.Head.Validate()
+}
+
+// Validate validates a HeadConfig
+func (h *HeadConfig) Validate() error {
+	if h.GetContentLength() && !h.GetRender() {
+		return NewValidationError("head.content_length", "the Content-Length of the body needs 'render', since the body isn't known without rendering it")
+	}
+	return nil
+}
+
+// GetRender returns whether the body is rendered, defaulting to true
+func (h *HeadConfig) GetRender() bool {
+	if h.Render == nil {
+		return true
+	}
+	return *h.Render
+}
+
+// GetContentLength returns whether the Content-Length of the body is sent,
+// defaulting to whether it's rendered
+func (h *HeadConfig) GetContentLength() bool {
+	if h.ContentLength == nil {
+		return h.GetRender()
+	}
+	return *h.ContentLength
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateHead(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "defaults - valid",
+			route: RouteConfig{Path: "/report", Method: "GET", Template: "{}", Head: &HeadConfig{}},
+		},
+		{
+			name:  "skip rendering - valid",
+			route: RouteConfig{Path: "/report", Method: "get", Template: "{}", Head: &HeadConfig{Render: &no}},
+		},
+		{
+			name:  "render without content length - valid",
+			route: RouteConfig{Path: "/report", Method: "GET", Template: "{}", Head: &HeadConfig{ContentLength: &no}},
+		},
+		{
+			name:        "content length without rendering - invalid",
+			route:       RouteConfig{Path: "/report", Method: "GET", Template: "{}", Head: &HeadConfig{Render: &no, ContentLength: &yes}},
+			errContains: "head.content_length",
+		},
+		{
+			name:        "POST route - invalid",
+			route:       RouteConfig{Path: "/report", Method: "POST", Template: "{}", Head: &HeadConfig{}},
+			errContains: "only be used on GET routes",
+		},
+		{
+			name:        "with stream - invalid",
+			route:       RouteConfig{Path: "/report", Method: "GET", Template: "{}", Stream: &StreamConfig{}, Head: &HeadConfig{}},
+			errContains: "cannot be combined with",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestHeadConfig_Defaults(t *testing.T) {
+	no := false
+
+	tests := []struct {
+		name              string
+		head              HeadConfig
+		wantRender        bool
+		wantContentLength bool
+	}{
+		{name: "unset", head: HeadConfig{}, wantRender: true, wantContentLength: true},
+		{name: "no rendering", head: HeadConfig{Render: &no}, wantRender: false, wantContentLength: false},
+		{name: "no content length", head: HeadConfig{ContentLength: &no}, wantRender: true, wantContentLength: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.head.GetRender(); got != tt.wantRender {
+				t.Errorf("Expected render %v, got %v", tt.wantRender, got)
+			}
+			if got := tt.head.GetContentLength(); got != tt.wantContentLength {
+				t.Errorf("Expected content length %v, got %v", tt.wantContentLength, got)
+			}
+		})
+	}
+}
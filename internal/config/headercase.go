@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// framingHeaders are the response headers net/http reads to frame responses,
+// which are always sent canonical, as it wouldn't see them otherwise
+var framingHeaders = []string{"Connection", "Content-Encoding", "Content-Length", "Content-Type", "Date", "Trailer", "Transfer-Encoding"}
+
+// HeaderCasing returns the names of the configured response headers whose
+// casing differs from Go's canonical one, by their canonical name, when the
+// server preserves header casing, or nil otherwise. Headers are named the
+// same way across the configuration, see validateHeaderCasing.
+func (c *Config) HeaderCasing() map[string]string {
+	casing, _ := c.headerCasing()
+	return casing
+}
+
+// validateHeaderCasing validates that response headers aren't written with
+// different casings, when the server preserves header casing, since only one
+// of them can be sent
+func (c *Config) validateHeaderCasing() error {
+	_, err := c.headerCasing()
+	return err
+}
+
+// headerCasing collects the casing of the headers set by server.headers and
+// the response_headers of the configuration, its routes and their responses,
+// and the headers of the fallback response and error pages
+func (c *Config) headerCasing() (map[string]string, error) {
+	if !c.Server.PreserveHeaderCase {
+		return nil, nil
+	}
+
+	casing := make(map[string]string)
+	add := func(headers map[string]string) error {
+		for name := range headers {
+			canonical := http.CanonicalHeaderKey(name)
+			if name == canonical || slices.Contains(framingHeaders, canonical) {
+				continue
+			}
+			if seen, ok := casing[canonical]; ok && seen != name {
+				names := []string{seen, name}
+				slices.Sort(names)
+				return NewValidationError("server.preserve_header_case", fmt.Sprintf("header %q is written both as %q and %q, use a single casing", canonical, names[0], names[1]))
+			}
+			casing[canonical] = name
+		}
+		return nil
+	}
+
+	sources := []map[string]string{c.Server.Headers, c.ResponseHeaders}
+	for _, route := range c.Routes {
+		sources = append(sources, route.ResponseHeaders)
+		for _, resp := range route.Responses {
+			sources = append(sources, resp.ResponseHeaders)
+		}
+		if route.Variants != nil {
+			for _, resp := range route.Variants.Cases {
+				sources = append(sources, resp.ResponseHeaders)
+			}
+		}
+	}
+	if c.Fallback != nil {
+		sources = append(sources, c.Fallback.Headers)
+	}
+	for _, page := range c.Server.ErrorPages.Pages() {
+		sources = append(sources, page.Headers)
+	}
+
+	for _, headers := range sources {
+		if err := add(headers); err != nil {
+			return nil, err
+		}
+	}
+	return casing, nil
+}
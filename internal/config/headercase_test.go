@@ -0,0 +1,43 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestConfig_HeaderCasing(t *testing.T) {
+	cfg := &Config{
+		Server:          ServerConfig{PreserveHeaderCase: true, Headers: map[string]string{"x-powered-by": "mockingjay"}},
+		ResponseHeaders: map[string]string{"X-AMZ-Request-Id": "{{ .RequestID }}", "X-Version": "1"},
+		Routes: []RouteConfig{
+			{
+				Path:            "/",
+				Method:          "GET",
+				ResponseHeaders: map[string]string{"ETag": `"v1"`, "content-type": "text/plain"},
+				Responses:       []ResponseConfig{{Template: "ok", ResponseHeaders: map[string]string{"X-AMZ-Request-Id": "fixed"}}},
+			},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{"X-Powered-By": "x-powered-by", "X-Amz-Request-Id": "X-AMZ-Request-Id", "Etag": "ETag"}
+	if got := cfg.HeaderCasing(); !maps.Equal(got, expected) {
+		t.Errorf("Expected casing %v, got %v", expected, got)
+	}
+
+	cfg.Server.PreserveHeaderCase = false
+	if got := cfg.HeaderCasing(); got != nil {
+		t.Errorf("Expected no casing without the option, got %v", got)
+	}
+
+	cfg.Server.PreserveHeaderCase = true
+	cfg.Routes[0].Responses[0].ResponseHeaders = map[string]string{"x-amz-request-id": "fixed"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `validation error in field "server.preserve_header_case": header "X-Amz-Request-Id" is written both as "X-AMZ-Request-Id" and "x-amz-request-id"`) {
+		t.Errorf("Expected a casing conflict, got %v", err)
+	}
+}
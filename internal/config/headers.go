@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// validateGlobalHeaders validates the response headers every route sends and
+// the headers the server sends with every response
+func (c *Config) validateGlobalHeaders() error {
+	for name := range c.ResponseHeaders {
+		if err := validateHeaderField("response_headers", name); err != nil {
+			return err
+		}
+	}
+
+	for name, value := range c.Server.Headers {
+		if err := validateHeaderField("server.headers", name); err != nil {
+			return err
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return NewValidationError("server.headers", fmt.Sprintf("value of header %q cannot contain line breaks", name))
+		}
+	}
+
+	return nil
+}
+
+// validateHeaderField checks that name is a valid header name, reporting
+// problems under field
+func validateHeaderField(field, name string) error {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return NewValidationError(field, "header name cannot be empty")
+	}
+	for _, char := range trimmed {
+		if !isValidHeaderNameChar(char) {
+			return NewValidationError(field, fmt.Sprintf("invalid character %q in header name %q", char, name))
+		}
+	}
+	return nil
+}
+
+// validateGlobalHeaderTemplates compiles the response header templates every
+// route sends
+func (c *Config) validateGlobalHeaderTemplates(engine *templatepkg.Engine) error {
+	for name, value := range c.ResponseHeaders {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		templateName := "validation_global_header_" + sanitizeTemplateNameForValidation(name)
+		if _, err := engine.CompileInlineTemplate(templateName, value); err != nil {
+			return fmt.Errorf("response header %q template compilation failed: %w", name, err)
+		}
+	}
+	return nil
+}
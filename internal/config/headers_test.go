@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateGlobalHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		responseHeaders map[string]string
+		serverHeaders   map[string]string
+		errContains     string
+	}{
+		{
+			name:            "valid",
+			responseHeaders: map[string]string{"X-Request-Id": "{{ .RequestID }}"},
+			serverHeaders:   map[string]string{"Server": "mockingjay", "X-Mock": "true"},
+		},
+		{
+			name:            "invalid response header name",
+			responseHeaders: map[string]string{"X Mock": "true"},
+			errContains:     `"response_headers": invalid character ' ' in header name "X Mock"`,
+		},
+		{
+			name:          "empty server header name",
+			serverHeaders: map[string]string{" ": "true"},
+			errContains:   `"server.headers": header name cannot be empty`,
+		},
+		{
+			name:          "line break in server header value",
+			serverHeaders: map[string]string{"X-Mock": "a\r\nSet-Cookie: b"},
+			errContains:   `"server.headers": value of header "X-Mock" cannot contain line breaks`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Routes:          []RouteConfig{{Path: "/", Method: "GET", Template: "ok"}},
+				ResponseHeaders: tt.responseHeaders,
+				Server:          ServerConfig{Headers: tt.serverHeaders},
+			}
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTemplates_GlobalResponseHeaders(t *testing.T) {
+	cfg := &Config{
+		Routes:          []RouteConfig{{Path: "/", Method: "GET", Template: "ok"}},
+		ResponseHeaders: map[string]string{"X-Request-Id": "{{ .RequestID "},
+	}
+
+	err := cfg.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), `response header "X-Request-Id" template compilation failed`) {
+		t.Errorf("Expected a global response header compilation error, got %v", err)
+	}
+}
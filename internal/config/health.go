@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statuses a health check dependency can report
+const (
+	DependencyUp       = "up"
+	DependencyDegraded = "degraded"
+	DependencyDown     = "down"
+)
+
+// HealthConfig customizes the built-in health check endpoint
+type HealthConfig struct {
+	Dependencies []DependencyConfig `yaml:"dependencies,omitempty"` // Synthetic dependencies reported by the endpoint
+}
+
+// DependencyConfig is a synthetic dependency reported by the health check
+// endpoint, whose status can be changed at runtime through the admin API
+type DependencyConfig struct {
+	Name     string `yaml:"name"`               // Unique name, e.g. "database"
+	Status   string `yaml:"status,omitempty"`   // "up" (default), "degraded" or "down"
+	Message  string `yaml:"message,omitempty"`  // Optional detail reported alongside the status
+	Critical bool   `yaml:"critical,omitempty"` // Whether the server is unhealthy when this dependency is down
+}
+
+// IsDependencyStatus reports whether status is a known dependency status
+func IsDependencyStatus(status string) bool {
+	switch status {
+	case DependencyUp, DependencyDegraded, DependencyDown:
+		return true
+	}
+	return false
+}
+
+// GetWithDefaults returns the dependency with its status defaulted to "up"
+func (dc *DependencyConfig) GetWithDefaults() DependencyConfig {
+	config := *dc
+
+	// Apply default values if not set
+	if config.Status == "" {
+		config.Status = DependencyUp
+	}
+
+	return config
+}
+
+// Validate validates the health check configuration
+func (hc *HealthConfig) Validate() error {
+	names := make(map[string]bool, len(hc.Dependencies))
+
+	for i, dep := range hc.Dependencies {
+		field := fmt.Sprintf("health.dependencies[%d]", i)
+
+		if strings.TrimSpace(dep.Name) == "" {
+			return NewValidationError(field+".name", "dependency name cannot be empty")
+		}
+		if names[dep.Name] {
+			return NewValidationError(field+".name", fmt.Sprintf("duplicate dependency name %q", dep.Name))
+		}
+		names[dep.Name] = true
+
+		if dep.Status != "" && !IsDependencyStatus(dep.Status) {
+			return NewValidationError(field+".status", fmt.Sprintf("invalid status %q, must be one of: %s, %s, %s", dep.Status, DependencyUp, DependencyDegraded, DependencyDown))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDependencyConfig_GetWithDefaults(t *testing.T) {
+	if defaults := (&DependencyConfig{Name: "db"}).GetWithDefaults(); defaults.Status != DependencyUp {
+		t.Errorf("Expected dependencies to be up by default, got %+v", defaults)
+	}
+
+	custom := (&DependencyConfig{Name: "db", Status: DependencyDown, Critical: true}).GetWithDefaults()
+	if custom.Status != DependencyDown || !custom.Critical {
+		t.Errorf("Expected configured values to be kept, got %+v", custom)
+	}
+}
+
+func TestHealthConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		deps        []DependencyConfig
+		errContains string
+	}{
+		{name: "empty"},
+		{
+			name: "valid",
+			deps: []DependencyConfig{
+				{Name: "database", Critical: true},
+				{Name: "cache", Status: DependencyDegraded, Message: "high latency"},
+				{Name: "search", Status: DependencyDown},
+			},
+		},
+		{name: "missing name", deps: []DependencyConfig{{Status: DependencyUp}}, errContains: "dependency name cannot be empty"},
+		{
+			name:        "duplicate name",
+			deps:        []DependencyConfig{{Name: "database"}, {Name: "database"}},
+			errContains: `health.dependencies[1].name`,
+		},
+		{name: "invalid status", deps: []DependencyConfig{{Name: "database", Status: "sideways"}}, errContains: `invalid status "sideways"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := HealthConfig{Dependencies: tt.deps}
+			err := hc.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
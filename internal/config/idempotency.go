@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Defaults of idempotency keys, matching the APIs that popularized them
+const (
+	DefaultIdempotencyHeader = "Idempotency-Key"
+	DefaultIdempotencyTTL    = 24 * time.Hour
+)
+
+// IdempotencyConfig makes a route remember the first response it sends for
+// each idempotency key, and replay it to requests retrying with the same key
+// instead of serving them again. In YAML it is either true, for the defaults,
+// or a mapping.
+type IdempotencyConfig struct {
+	Header          string        `yaml:"header,omitempty"`           // Request header carrying the key (default: "Idempotency-Key")
+	TTL             time.Duration `yaml:"ttl,omitempty"`              // How long responses are remembered (default: 24h)
+	RejectConflicts bool          `yaml:"reject_conflicts,omitempty"` // Answer keys reused with a different request with a 409 Conflict instead of the remembered response
+}
+
+// UnmarshalYAML accepts either true or a mapping with the settings
+func (ic *IdempotencyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("idempotency can't be false, leave it out to disable idempotency keys")
+		}
+		*ic = IdempotencyConfig{}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings IdempotencyConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("idempotency must be true or a mapping with header, ttl and reject_conflicts: %w", err)
+	}
+	*ic = IdempotencyConfig(decoded)
+	return nil
+}
+
+// validateIdempotency validates the idempotency keys of a route
+func (r *RouteConfig) validateIdempotency() error {
+	if r.Idempotency == nil {
+		return nil
+	}
+
+	if r.WebSocket != nil || r.Stream != nil || r.Raw != nil {
+		return NewValidationError("idempotency", "'idempotency' cannot be combined with 'websocket', 'stream' or 'raw', whose responses can't be replayed")
+	}
+	for _, char := range r.Idempotency.Header {
+		if !isValidHeaderNameChar(char) {
+			return NewValidationError("idempotency.header", fmt.Sprintf("invalid character %q in header name %q", char, r.Idempotency.Header))
+		}
+	}
+	if r.Idempotency.TTL < 0 {
+		return NewValidationError("idempotency.ttl", "ttl cannot be negative")
+	}
+
+	return nil
+}
+
+// GetHeader returns the header carrying the key, defaulting to Idempotency-Key
+func (ic *IdempotencyConfig) GetHeader() string {
+	if strings.TrimSpace(ic.Header) == "" {
+		return DefaultIdempotencyHeader
+	}
+	return ic.Header
+}
+
+// GetTTL returns how long responses are remembered, defaulting to 24 hours
+func (ic *IdempotencyConfig) GetTTL() time.Duration {
+	if ic.TTL == 0 {
+		return DefaultIdempotencyTTL
+	}
+	return ic.TTL
+}
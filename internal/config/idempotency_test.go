@@ -0,0 +1,117 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestIdempotencyConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        IdempotencyConfig
+		errContains string
+	}{
+		{
+			name: "enabled",
+			yaml: `idempotency: true`,
+			want: IdempotencyConfig{},
+		},
+		{
+			name: "settings",
+			yaml: "idempotency:\n  header: X-Request-Key\n  ttl: 1h\n  reject_conflicts: true",
+			want: IdempotencyConfig{Header: "X-Request-Key", TTL: time.Hour, RejectConflicts: true},
+		},
+		{
+			name:        "disabled",
+			yaml:        `idempotency: false`,
+			errContains: "idempotency can't be false",
+		},
+		{
+			name:        "sequence",
+			yaml:        `idempotency: [true]`,
+			errContains: "idempotency must be true or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Idempotency == nil || *route.Idempotency != tt.want {
+				t.Errorf("Expected idempotency %+v, got %+v", tt.want, route.Idempotency)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateIdempotency(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "defaults - valid",
+			route: RouteConfig{Idempotency: &IdempotencyConfig{}},
+		},
+		{
+			name:  "settings - valid",
+			route: RouteConfig{Idempotency: &IdempotencyConfig{Header: "X-Request-Key", TTL: time.Minute, RejectConflicts: true}},
+		},
+		{
+			name:        "invalid header - invalid",
+			route:       RouteConfig{Idempotency: &IdempotencyConfig{Header: "Request Key"}},
+			errContains: `invalid character ' ' in header name "Request Key"`,
+		},
+		{
+			name:        "negative ttl - invalid",
+			route:       RouteConfig{Idempotency: &IdempotencyConfig{TTL: -time.Second}},
+			errContains: "ttl cannot be negative",
+		},
+		{
+			name:        "stream - invalid",
+			route:       RouteConfig{Idempotency: &IdempotencyConfig{}, Stream: &StreamConfig{}},
+			errContains: "'idempotency' cannot be combined with 'websocket', 'stream' or 'raw'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := tt.route
+			route.Path, route.Method, route.Template = "/payments", "POST", "ok"
+			err := route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyConfig_Defaults(t *testing.T) {
+	ic := &IdempotencyConfig{}
+	if got := ic.GetHeader(); got != "Idempotency-Key" {
+		t.Errorf("Expected the Idempotency-Key header, got %q", got)
+	}
+	if got := ic.GetTTL(); got != 24*time.Hour {
+		t.Errorf("Expected a TTL of 24h, got %v", got)
+	}
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// IncludeConfig is an entry of include: a local file or glob pattern, or the
+// URL of a remote file. In YAML it is either a string, read as a URL when it
+// starts with http:// or https://, or a mapping for remote files with a
+// pinned checksum or a refresh interval.
+type IncludeConfig struct {
+	Path    string        `yaml:"path,omitempty"`    // Local file or glob pattern, relative to the including file
+	URL     string        `yaml:"url,omitempty"`     // Remote file, fetched over HTTP(S) and cached on disk
+	SHA256  string        `yaml:"sha256,omitempty"`  // Expected hex SHA-256 checksum of the remote file
+	Refresh time.Duration `yaml:"refresh,omitempty"` // How often the remote file is checked for changes while running
+}
+
+// UnmarshalYAML accepts either a path or URL string, or a mapping
+func (ic *IncludeConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entry string
+	if err := unmarshal(&entry); err == nil {
+		if isRemote(entry) {
+			*ic = IncludeConfig{URL: entry}
+		} else {
+			*ic = IncludeConfig{Path: entry}
+		}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings IncludeConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("include must be a path, a URL or a mapping with url, sha256 and refresh: %w", err)
+	}
+	*ic = IncludeConfig(decoded)
+	return nil
+}
+
+// Validate validates an IncludeConfig
+func (ic *IncludeConfig) Validate() error {
+	if ic.URL == "" {
+		if strings.TrimSpace(ic.Path) == "" {
+			return NewValidationError("include", "include path cannot be empty")
+		}
+		if ic.SHA256 != "" || ic.Refresh != 0 {
+			return NewValidationError("include", "'sha256' and 'refresh' can only be used with 'url'")
+		}
+		return nil
+	}
+
+	if ic.Path != "" {
+		return NewValidationError("include", "'path' and 'url' cannot be combined")
+	}
+	if u, err := url.Parse(ic.URL); err != nil || !isRemote(ic.URL) || u.Host == "" {
+		return NewValidationError("include.url", fmt.Sprintf("invalid URL %q, must be an absolute http:// or https:// URL", ic.URL))
+	}
+	if ic.SHA256 != "" {
+		if sum, err := hex.DecodeString(ic.SHA256); err != nil || len(sum) != sha256.Size {
+			return NewValidationError("include.sha256", fmt.Sprintf("invalid checksum %q, must be 64 hexadecimal characters", ic.SHA256))
+		}
+	}
+	if strings.HasPrefix(ic.URL, "http://") && ic.SHA256 == "" {
+		return NewValidationError("include.url", fmt.Sprintf("plain http:// URL %q must pin the file with 'sha256', or use https://", ic.URL))
+	}
+	if ic.Refresh < 0 {
+		return NewValidationError("include.refresh", "refresh interval cannot be negative")
+	}
+	if ic.Refresh > 0 && ic.SHA256 != "" {
+		return NewValidationError("include.refresh", "'refresh' cannot be used with 'sha256', since a pinned file never changes")
+	}
+	return nil
+}
+
+// loadIncludes pulls the routes of the files listed under include into c,
+// which was parsed from file. Includes are resolved relative to the directory
+// of the file listing them, can be glob patterns or URLs, and can include
+// other files themselves. Entries of remote files are resolved relative to
+// their URL. Included routes follow the including file's own routes, in
+// include order. chain holds the files being included, to detect cycles, and
+// seen holds every file loaded so far, so each one is included only once.
+func (c *Config) loadIncludes(file string, chain []string, seen map[string]bool) error {
+	abs, err := includeKey(file)
+	if err != nil {
+		return NewLoadError(file, fmt.Errorf("failed to resolve path: %w", err))
+	}
+	chain = append(chain, abs)
+	seen[abs] = true
+
+	for i, inc := range c.Include {
+		if err := inc.Validate(); err != nil {
+			return NewLoadError(file, fmt.Errorf("include[%d]: %w", i, err))
+		}
+
+		var files []string
+		if isRemote(file) || inc.URL != "" {
+			if inc, err = remoteInclude(file, inc); err != nil {
+				return NewLoadError(file, fmt.Errorf("include[%d]: %w", i, err))
+			}
+			files = []string{inc.URL}
+		} else {
+			if files, err = resolveInclude(file, inc.Path); err != nil {
+				return NewLoadError(file, fmt.Errorf("include[%d]: %w", i, err))
+			}
+			if dir, ok := includeDir(file, inc.Path); ok {
+				c.includeDirs = append(c.includeDirs, dir)
+			}
+		}
+
+		for _, included := range files {
+			includedAbs, err := includeKey(included)
+			if err != nil {
+				return NewLoadError(included, fmt.Errorf("failed to resolve path: %w", err))
+			}
+
+			for j, link := range chain {
+				if link == includedAbs {
+					cycle := append(chain[j:len(chain):len(chain)], includedAbs)
+					return NewLoadError(file, NewValidationError(fmt.Sprintf("include[%d]", i), fmt.Sprintf("include cycle: %s", strings.Join(cycle, " -> "))))
+				}
+			}
+			if seen[includedAbs] {
+				continue
+			}
+
+			var parsed *Config
+			if isRemote(included) {
+				parsed, err = c.loadRemote(inc)
+			} else {
+				parsed, err = parseConfigFile(included)
+			}
+			if err != nil {
+				return err
+			}
+			if err := parsed.checkIncluded(); err != nil {
+				return NewLoadError(included, err)
+			}
+
+			// Validate routes while their file is still known, so errors point at it
+			for j, route := range parsed.Routes {
+				if err := route.Validate(); err != nil {
+					return NewLoadError(included, fmt.Errorf("configuration validation failed: route[%d]: %w", j, err))
+				}
+			}
+
+			if err := parsed.loadIncludes(included, chain, seen); err != nil {
+				return err
+			}
+
+			c.Routes = append(c.Routes, parsed.Routes...)
+			if !isRemote(included) {
+				c.included = append(c.included, included) // Remote files are followed by refreshing them instead
+			}
+			c.included = append(c.included, parsed.included...)
+			c.includeDirs = append(c.includeDirs, parsed.includeDirs...)
+			c.remote = append(c.remote, parsed.remote...)
+			c.warnings = append(c.warnings, parsed.warnings...)
+		}
+	}
+
+	return nil
+}
+
+// remoteInclude resolves an include entry of file, which is either a local
+// file or a URL, into a remote include with an absolute URL
+func remoteInclude(file string, inc IncludeConfig) (IncludeConfig, error) {
+	if !isRemote(file) {
+		return inc, nil
+	}
+
+	ref := inc.URL
+	if ref == "" {
+		if isGlob(inc.Path) || filepath.IsAbs(inc.Path) {
+			return inc, NewValidationError("include", fmt.Sprintf("remote files can only include URLs and relative paths without patterns, got %q", inc.Path))
+		}
+		ref = filepath.ToSlash(inc.Path)
+	}
+
+	base, err := url.Parse(file)
+	if err != nil {
+		return inc, NewValidationError("include", fmt.Sprintf("invalid URL %q: %v", file, err))
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return inc, NewValidationError("include", fmt.Sprintf("invalid include %q: %v", ref, err))
+	}
+
+	inc.Path = ""
+	inc.URL = resolved.String()
+	return inc, nil
+}
+
+// includeKey identifies an included file or URL, to detect cycles and files
+// included more than once
+func includeKey(file string) (string, error) {
+	if isRemote(file) {
+		return file, nil
+	}
+	return filepath.Abs(file)
+}
+
+// isRemote reports whether an include entry is an http:// or https:// URL
+func isRemote(entry string) bool {
+	return strings.HasPrefix(entry, "http://") || strings.HasPrefix(entry, "https://")
+}
+
+// resolveInclude returns the files matched by an include entry of file
+func resolveInclude(file, pattern string) ([]string, error) {
+	path := includePath(file, pattern)
+	if !isGlob(pattern) {
+		return []string{path}, nil // Reported when the file is loaded
+	}
+
+	files, err := filepath.Glob(path)
+	if err != nil {
+		return nil, NewValidationError("include", fmt.Sprintf("invalid include pattern %q: %v", pattern, err))
+	}
+	if len(files) == 0 {
+		return nil, NewValidationError("include", fmt.Sprintf("include pattern %q matches no files", pattern))
+	}
+	return files, nil
+}
+
+// includePath resolves an include entry of file relative to its directory
+func includePath(file, pattern string) string {
+	if filepath.IsAbs(pattern) {
+		return pattern
+	}
+	return filepath.Join(filepath.Dir(file), pattern)
+}
+
+// isGlob reports whether an include entry is a glob pattern
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// includeDir returns the directory an include pattern of file is matched in,
+// so files added to it can be noticed. Only glob patterns whose directory is
+// fixed have one.
+func includeDir(file, pattern string) (string, bool) {
+	if !isGlob(pattern) {
+		return "", false
+	}
+
+	dir := filepath.Dir(includePath(file, pattern))
+	if isGlob(dir) {
+		return "", false
+	}
+	return dir, true
+}
+
+// checkIncluded verifies that an included file only defines routes and
+// includes, since every other setting belongs to the main configuration
+func (c *Config) checkIncluded() error {
+	v := reflect.ValueOf(c).Elem()
+	for i := range v.NumField() {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" || name == "routes" || name == "include" {
+			continue
+		}
+		if !v.Field(i).IsZero() {
+			return NewValidationError(name, "included files can only define routes and include other files")
+		}
+	}
+	return nil
+}
+
+// IncludedFiles returns the files pulled in through include directives, so
+// callers can watch them for changes alongside the main configuration
+func (c *Config) IncludedFiles() []string {
+	return c.included
+}
+
+// RemoteIncludes returns the remote files pulled in through include
+// directives that are refreshed while running, with their URLs resolved,
+// including those of tenants
+func (c *Config) RemoteIncludes() []IncludeConfig {
+	var includes []IncludeConfig
+	for _, inc := range c.remote {
+		if inc.Refresh > 0 {
+			includes = append(includes, inc)
+		}
+	}
+	for _, tenant := range c.Tenants {
+		if tenant.Loaded != nil {
+			includes = append(includes, tenant.Loaded.RemoteIncludes()...)
+		}
+	}
+	return includes
+}
+
+// Warnings returns problems worked around while loading the configuration,
+// like remote includes served from a stale cached copy. Tenants report their
+// own warnings.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func routePaths(cfg *Config) []string {
+	var paths []string
+	for _, route := range cfg.Routes {
+		paths = append(paths, route.Path)
+	}
+	return paths
+}
+
+func TestLoadConfig_Include(t *testing.T) {
+	t.Run("pulls in routes relative to the including file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"main.yaml": `include:
+  - routes/users.yaml
+  - routes/billing/*.yaml
+routes:
+  - path: /health
+    method: GET
+    template: "ok"`,
+			"routes/users.yaml": `include:
+  - ../shared.yaml
+routes:
+  - path: /users
+    method: GET
+    template: "users"`,
+			"routes/billing/invoices.yaml": `routes:
+  - path: /invoices
+    method: GET
+    template: "invoices"`,
+			"routes/billing/payments.yaml": `routes:
+  - path: /payments
+    method: GET
+    template: "payments"`,
+			"shared.yaml": `routes:
+  - path: /shared
+    method: GET
+    template: "shared"`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+
+		if expected := []string{"/health", "/users", "/shared", "/invoices", "/payments"}; !slices.Equal(routePaths(cfg), expected) {
+			t.Errorf("Expected routes %v, got %v", expected, routePaths(cfg))
+		}
+
+		expected := []string{
+			filepath.Join(dir, "routes/users.yaml"),
+			filepath.Join(dir, "shared.yaml"),
+			filepath.Join(dir, "routes/billing/invoices.yaml"),
+			filepath.Join(dir, "routes/billing/payments.yaml"),
+		}
+		if !slices.Equal(cfg.IncludedFiles(), expected) {
+			t.Errorf("Expected included files %v, got %v", expected, cfg.IncludedFiles())
+		}
+	})
+
+	t.Run("includes each file once", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"main.yaml": `include:
+  - a.yaml
+  - b.yaml`,
+			"a.yaml": `include: [common.yaml]
+routes:
+  - path: /a
+    method: GET
+    template: "a"`,
+			"b.yaml": `include: [common.yaml]
+routes:
+  - path: /b
+    method: GET
+    template: "b"`,
+			"common.yaml": `routes:
+  - path: /common
+    method: GET
+    template: "common"`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+
+		if expected := []string{"/a", "/common", "/b"}; !slices.Equal(routePaths(cfg), expected) {
+			t.Errorf("Expected routes %v, got %v", expected, routePaths(cfg))
+		}
+	})
+
+	t.Run("files in a config directory are not loaded twice", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"a.yaml": `include: [b.yaml]
+routes:
+  - path: /a
+    method: GET
+    template: "a"`,
+			"b.yaml": `routes:
+  - path: /b
+    method: GET
+    template: "b"`,
+		})
+
+		cfg, err := LoadConfigs([]string{dir})
+		if err != nil {
+			t.Fatalf("LoadConfigs() unexpected error: %v", err)
+		}
+
+		if expected := []string{"/a", "/b"}; !slices.Equal(routePaths(cfg), expected) {
+			t.Errorf("Expected routes %v, got %v", expected, routePaths(cfg))
+		}
+	})
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		errContains []string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.yaml": `include: [a.yaml]
+routes:
+  - path: /main
+    method: GET
+    template: "main"`,
+				"a.yaml": `include: [b.yaml]`,
+				"b.yaml": `include: [a.yaml]`,
+			},
+			errContains: []string{"include cycle", "a.yaml -> ", "b.yaml -> ", "a.yaml"},
+		},
+		{
+			name: "file including itself",
+			files: map[string]string{
+				"main.yaml": `include: [main.yaml]
+routes:
+  - path: /main
+    method: GET
+    template: "main"`,
+			},
+			errContains: []string{"include cycle"},
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"main.yaml": `include: [missing.yaml]`,
+			},
+			errContains: []string{"missing.yaml", "does not exist"},
+		},
+		{
+			name: "pattern without matches",
+			files: map[string]string{
+				"main.yaml": `include: ["routes/*.yaml"]`,
+			},
+			errContains: []string{"include[0]", `"routes/*.yaml" matches no files`},
+		},
+		{
+			name: "empty include",
+			files: map[string]string{
+				"main.yaml": `include: [""]`,
+			},
+			errContains: []string{"include path cannot be empty"},
+		},
+		{
+			name: "included file with settings",
+			files: map[string]string{
+				"main.yaml": `include: [a.yaml]`,
+				"a.yaml": `server:
+  dev_mode: true
+routes:
+  - path: /a
+    method: GET
+    template: "a"`,
+			},
+			errContains: []string{"a.yaml", `field "server"`, "included files can only define routes"},
+		},
+		{
+			name: "route error names the included file",
+			files: map[string]string{
+				"main.yaml": `include: [a.yaml]`,
+				"a.yaml": `routes:
+  - path: /a
+    method: FETCH
+    template: "a"`,
+			},
+			errContains: []string{"a.yaml", "route[0]", "invalid HTTP method"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFiles(t, dir, tt.files)
+
+			_, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+			if err == nil {
+				t.Fatal("LoadConfig() expected error but got none")
+			}
+			for _, want := range tt.errContains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfig() error = %v, want error containing %q", err, want)
+				}
+			}
+		})
+	}
+}
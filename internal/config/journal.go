@@ -0,0 +1,54 @@
+package config
+
+import "strings"
+
+// Default journal bounds
+const (
+	DefaultJournalMaxEntries   = 10000
+	DefaultJournalMaxBodyBytes = 8192
+)
+
+// JournalConfig bounds the memory used by the request journal and optionally
+// persists it to disk
+type JournalConfig struct {
+	MaxEntries   int    `yaml:"max_entries,omitempty"`    // Requests kept in memory; the oldest are dropped first (default: 10000)
+	MaxBodyBytes int    `yaml:"max_body_bytes,omitempty"` // Request body bytes kept per entry (default: 8192)
+	Persist      bool   `yaml:"persist,omitempty"`        // Append entries to a file and reload them on startup
+	Path         string `yaml:"path,omitempty"`           // JSON Lines file entries are persisted to
+}
+
+// GetWithDefaults returns journal settings with sensible defaults
+func (jc *JournalConfig) GetWithDefaults() JournalConfig {
+	config := *jc
+
+	// Apply default values if not set
+	if config.MaxEntries == 0 {
+		config.MaxEntries = DefaultJournalMaxEntries
+	}
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = DefaultJournalMaxBodyBytes
+	}
+
+	return config
+}
+
+// Validate validates the journal configuration
+func (jc *JournalConfig) Validate() error {
+	if jc.MaxEntries < 0 {
+		return NewValidationError("journal.max_entries", "max_entries cannot be negative")
+	}
+
+	if jc.MaxBodyBytes < 0 {
+		return NewValidationError("journal.max_body_bytes", "max_body_bytes cannot be negative")
+	}
+
+	if jc.Persist && strings.TrimSpace(jc.Path) == "" {
+		return NewValidationError("journal.path", "'persist' requires a 'path' to write entries to")
+	}
+
+	if !jc.Persist && jc.Path != "" {
+		return NewValidationError("journal.path", "'path' requires 'persist: true'")
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJournalConfig_GetWithDefaults(t *testing.T) {
+	defaults := (&JournalConfig{}).GetWithDefaults()
+	if defaults.MaxEntries != DefaultJournalMaxEntries || defaults.MaxBodyBytes != DefaultJournalMaxBodyBytes {
+		t.Errorf("Unexpected defaults: %+v", defaults)
+	}
+
+	custom := (&JournalConfig{MaxEntries: 50, MaxBodyBytes: 100}).GetWithDefaults()
+	if custom.MaxEntries != 50 || custom.MaxBodyBytes != 100 {
+		t.Errorf("Expected configured values to be kept, got %+v", custom)
+	}
+}
+
+func TestJournalConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		journal     JournalConfig
+		errContains string
+	}{
+		{name: "empty", journal: JournalConfig{}},
+		{name: "bounds", journal: JournalConfig{MaxEntries: 100, MaxBodyBytes: 1024}},
+		{name: "persisted", journal: JournalConfig{Persist: true, Path: "journal.jsonl"}},
+		{name: "negative entries", journal: JournalConfig{MaxEntries: -1}, errContains: "max_entries cannot be negative"},
+		{name: "negative body bytes", journal: JournalConfig{MaxBodyBytes: -1}, errContains: "max_body_bytes cannot be negative"},
+		{name: "persist without path", journal: JournalConfig{Persist: true}, errContains: "requires a 'path'"},
+		{name: "path without persist", journal: JournalConfig{Path: "journal.jsonl"}, errContains: "requires 'persist: true'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.journal.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
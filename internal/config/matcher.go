@@ -0,0 +1,14 @@
+package config
+
+import "github.com/patrickdappollonio/mockingjay/internal/matcher"
+
+// validateMatch validates the settings of the route's matchers by building
+// them, so unknown kinds and bad settings are reported before serving
+func (r *RouteConfig) validateMatch() error {
+	for name, settings := range r.Match {
+		if _, err := matcher.Build(name, settings); err != nil {
+			return NewValidationError("match."+name, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestRouteConfig_ValidateMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		errContains string
+	}{
+		{
+			name: "jwt claims",
+			yaml: "path: /invoices\nmethod: GET\ntemplate: '[]'\nmatch:\n  jwt_claims:\n    sub: \"/^user-/\"\n    admin: true",
+		},
+		{
+			name:        "unknown kind",
+			yaml:        "path: /invoices\nmethod: GET\ntemplate: '[]'\nmatch:\n  geoip:\n    country: CA",
+			errContains: `unknown matcher "geoip"`,
+		},
+		{
+			name:        "invalid settings",
+			yaml:        "path: /invoices\nmethod: GET\ntemplate: '[]'\nmatch:\n  jwt_claims: [sub]",
+			errContains: "invalid settings: sequence was used where mapping is expected",
+		},
+		{
+			name:        "rejected by the kind",
+			yaml:        "path: /invoices\nmethod: GET\ntemplate: '[]'\nmatch:\n  jwt_claims:\n    sub: \"/[a-/\"",
+			errContains: `claim "sub": invalid regex pattern`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			if err := yaml.Unmarshal([]byte(tt.yaml), &route); err != nil {
+				t.Fatalf("Failed to parse route: %v", err)
+			}
+
+			err := route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+)
+
+// validateMiddleware validates the route's own middleware by creating it, so
+// unknown types and bad settings are reported before serving
+func (r *RouteConfig) validateMiddleware() error {
+	factory := middleware.NewFactory(slog.New(slog.DiscardHandler), nil)
+	for i, mc := range r.Middleware {
+		if _, err := factory.CreateMiddleware(mc); err != nil {
+			return NewValidationError(fmt.Sprintf("middleware[%d]", i), err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// NewEngine returns a template engine with the configured delimiters, which
+// every template compiled with it can include the configured partials in
+func (tc TemplateConfig) NewEngine() (*templatepkg.Engine, error) {
+	delimiters := tc.Delimiters.GetWithDefaults()
+	engine := templatepkg.NewEngineWithDelimiters(delimiters.Left, delimiters.Right)
+
+	for _, name := range slices.Sorted(maps.Keys(tc.Partials)) {
+		if err := engine.AddPartial(name, tc.Partials[name]); err != nil {
+			return nil, NewValidationError("template.partials."+name, err.Error())
+		}
+	}
+
+	if tc.PartialsDir != "" {
+		if err := engine.AddPartialDir(tc.PartialsDir); err != nil {
+			return nil, NewValidationError("template.partials_dir", err.Error())
+		}
+	}
+
+	return engine, nil
+}
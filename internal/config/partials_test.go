@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidatePartials(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "page.tmpl"), []byte(`{"items": {{ toJson . }}}`), 0o644); err != nil {
+		t.Fatalf("Failed to write partial: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template TemplateConfig
+		route    string
+		wantErr  string
+	}{
+		{
+			name:     "partials included by routes",
+			template: TemplateConfig{Partials: map[string]string{"envelope": `{"data": {{ . }}}`}, PartialsDir: dir},
+			route:    `{{ template "envelope" 1 }}{{ template "page" (list 1 2) }}`,
+		},
+		{
+			name:     "partials with custom delimiters",
+			template: TemplateConfig{Delimiters: DelimiterConfig{Left: "[[", Right: "]]"}, Partials: map[string]string{"envelope": `{"data": [[ . ]]}`}},
+			route:    `[[ template "envelope" 1 ]]`,
+		},
+		{
+			name:     "invalid partial",
+			template: TemplateConfig{Partials: map[string]string{"envelope": `{{ .data `}},
+			route:    `{{ template "envelope" . }}`,
+			wantErr:  "template.partials.envelope",
+		},
+		{
+			name:     "missing partials directory",
+			template: TemplateConfig{PartialsDir: filepath.Join(dir, "missing")},
+			route:    "ok",
+			wantErr:  "template.partials_dir",
+		},
+		{
+			name:     "partial defined twice",
+			template: TemplateConfig{Partials: map[string]string{"page": "{}"}, PartialsDir: dir},
+			route:    "ok",
+			wantErr:  "already defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Template: tt.template,
+				Routes:   []RouteConfig{{Path: "/", Method: "GET", Template: tt.route}},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfig_WatchFilesPartialsDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"page.tmpl", "envelope.tmpl", ".swap"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatalf("Failed to write partial: %v", err)
+		}
+	}
+
+	cfg := &Config{Template: TemplateConfig{PartialsDir: dir}}
+	expected := []string{filepath.Join(dir, "envelope.tmpl"), filepath.Join(dir, "page.tmpl")}
+	if files := cfg.WatchFiles(); !slices.Equal(files, expected) {
+		t.Errorf("Expected the partials to be watched, got %v, want %v", files, expected)
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Regexes of path parameters without their own
+const (
+	pathParamSegment  = "[^/]+" // {name} and :name match a single segment
+	pathParamWildcard = ".+"    // {name...} matches the rest of the path
+	pathParamRest     = ".*"    // A trailing /* matches the rest of the path, if any
+)
+
+// Ways route paths are matched
+const (
+	PathMatchExact  = "exact"  // The path only matches itself
+	PathMatchPrefix = "prefix" // The path also matches every path under it
+)
+
+// pathWildcard is the parameter a trailing "/*" captures the rest of the path
+// in
+const pathWildcard = "wildcard"
+
+// pathParamName matches the names of path parameters, which become the names
+// of regex groups
+var pathParamName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsPathTemplate returns true if the path names parameters, like
+// "/users/{id}" or "/users/:id", or ends with a "/*" wildcard, instead of
+// being literal or a regex
+func (r *RouteConfig) IsPathTemplate() bool {
+	return isPathTemplate(r.Path)
+}
+
+// isPathTemplate returns true if path names parameters with braces, or with a
+// colon starting a segment
+func isPathTemplate(path string) bool {
+	if isRegexPattern(path) {
+		return false
+	}
+	if strings.ContainsAny(path, "{}") || strings.HasSuffix(path, "/*") {
+		return true
+	}
+	for segment := range strings.SplitSeq(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok && pathParamName.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesPathRegex returns true if the route's path is matched with the regex of
+// PathRegex, rather than compared to request paths as-is
+func (r *RouteConfig) UsesPathRegex() bool {
+	return r.IsRegexPattern() || r.IsPathTemplate() || r.PathMatch == PathMatchPrefix
+}
+
+// PathRegex returns the regex the route's path is matched with: the regex of
+// /regex/ paths, or the one path templates and prefixes compile to
+func (r *RouteConfig) PathRegex() (string, error) {
+	switch {
+	case r.PathMatch == PathMatchPrefix:
+		// Prefixes match the path itself and the paths under it, but not
+		// paths merely starting with the same text, like /apis for /api
+		pattern, err := compilePathTemplate(strings.TrimSuffix(r.Path, "/"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(pattern, "$") + "(?:/.*)?$", nil
+	case r.IsPathTemplate():
+		return compilePathTemplate(r.Path)
+	}
+	return r.GetRegexPattern(), nil
+}
+
+// validatePathMatch validates how the path is matched
+func (r *RouteConfig) validatePathMatch() error {
+	switch r.PathMatch {
+	case "", PathMatchExact:
+		return nil
+	case PathMatchPrefix:
+		if r.IsRegexPattern() {
+			return NewValidationError("path_match", "regex paths can't be matched by prefix, leave out their '$' anchor instead")
+		}
+		return nil
+	}
+	return NewValidationError("path_match", fmt.Sprintf("invalid path match %q, must be one of: %s, %s", r.PathMatch, PathMatchExact, PathMatchPrefix))
+}
+
+// compilePathTemplate turns a path template into an anchored regex where each
+// parameter is a named group. Parameters are written as {name}, :name for a
+// whole segment, {name:regex} to restrict what they match, or {name...} to
+// match the rest of the path, which a trailing "/*" matches as "wildcard".
+// Everything else is matched literally.
+func compilePathTemplate(path string) (string, error) {
+	var pattern strings.Builder
+	seen := make(map[string]bool)
+
+	param := func(name, expr string) error {
+		if !pathParamName.MatchString(name) {
+			return fmt.Errorf("invalid parameter name %q, must be letters, digits and underscores, not starting with a digit", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("parameter %q is used twice", name)
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid regex for parameter %q: %w", name, err)
+		}
+		seen[name] = true
+		fmt.Fprintf(&pattern, "(?P<%s>%s)", name, expr)
+		return nil
+	}
+
+	pattern.WriteString("^")
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '{':
+			end := closingBrace(path, i)
+			if end < 0 {
+				return "", fmt.Errorf("unclosed '{' at position %d", i)
+			}
+
+			name, expr, restricted := strings.Cut(path[i+1:end], ":")
+			if rest, ok := strings.CutSuffix(name, "..."); ok && !restricted {
+				name, expr = rest, pathParamWildcard
+			} else if !restricted {
+				expr = pathParamSegment
+			}
+			if err := param(name, expr); err != nil {
+				return "", err
+			}
+			i = end + 1
+
+		case path[i] == '}':
+			return "", fmt.Errorf("unexpected '}' at position %d", i)
+
+		case path[i] == '*' && i == len(path)-1 && i > 0 && path[i-1] == '/':
+			if err := param(pathWildcard, pathParamRest); err != nil {
+				return "", err
+			}
+			i++
+
+		case path[i] == ':' && (i == 0 || path[i-1] == '/'):
+			end := strings.IndexByte(path[i:], '/')
+			if end < 0 {
+				end = len(path) - i
+			}
+			if name := path[i+1 : i+end]; pathParamName.MatchString(name) {
+				if err := param(name, pathParamSegment); err != nil {
+					return "", err
+				}
+				i += end
+				continue
+			}
+			pattern.WriteString(regexp.QuoteMeta(path[i : i+1]))
+			i++
+
+		default:
+			pattern.WriteString(regexp.QuoteMeta(path[i : i+1]))
+			i++
+		}
+	}
+	pattern.WriteString("$")
+
+	return pattern.String(), nil
+}
+
+// closingBrace returns the index of the brace closing the one at open, skipping
+// braces nested in parameter regexes like {id:[0-9]{3}}, or -1 if there's none
+func closingBrace(path string, open int) int {
+	depth := 0
+	for i := open; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
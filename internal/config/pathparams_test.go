@@ -0,0 +1,128 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsPathTemplate(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/users", expected: false},
+		{path: "/users/{id}", expected: true},
+		{path: "/users/:id", expected: true},
+		{path: "/v1/{name}:cancel", expected: true},
+		{path: "/v1/models/gpt:generate", expected: false},
+		{path: "/times/:", expected: false},
+		{path: "/api/v1/*", expected: true},
+		{path: "/api/v1*", expected: false},
+		{path: "/^/users/(?P<id>\\d+)$/", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			route := RouteConfig{Path: tt.path}
+			if got := route.IsPathTemplate(); got != tt.expected {
+				t.Errorf("IsPathTemplate(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompilePathTemplate(t *testing.T) {
+	tests := []struct {
+		path        string
+		expected    string
+		errContains string
+	}{
+		{path: "/users/{id}/posts/{postId}", expected: `^/users/(?P<id>[^/]+)/posts/(?P<postId>[^/]+)$`},
+		{path: "/users/:id/posts/:post_id", expected: `^/users/(?P<id>[^/]+)/posts/(?P<post_id>[^/]+)$`},
+		{path: "/orders/{id:[0-9]{4}}", expected: `^/orders/(?P<id>[0-9]{4})$`},
+		{path: "/files/{path...}", expected: `^/files/(?P<path>.+)$`},
+		{path: "/v1/{name}:cancel", expected: `^/v1/(?P<name>[^/]+):cancel$`},
+		{path: "/api.v2/{id}.json", expected: `^/api\.v2/(?P<id>[^/]+)\.json$`},
+		{path: "/api/v1/*", expected: `^/api/v1/(?P<wildcard>.*)$`},
+		{path: "/*", expected: `^/(?P<wildcard>.*)$`},
+		{path: "/users/{id", errContains: "unclosed '{' at position 7"},
+		{path: "/users/id}", errContains: "unexpected '}' at position 9"},
+		{path: "/users/{}", errContains: `invalid parameter name ""`},
+		{path: "/users/{1st}", errContains: `invalid parameter name "1st"`},
+		{path: "/users/{id}/friends/:id", errContains: `parameter "id" is used twice`},
+		{path: "/users/{id:[0-9]{4}", errContains: "unclosed '{' at position 7"},
+		{path: "/users/{id:(}", errContains: `invalid regex for parameter "id"`},
+		{path: "/{wildcard}/*", errContains: `parameter "wildcard" is used twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := compilePathTemplate(tt.path)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidatePathTemplate(t *testing.T) {
+	route := RouteConfig{Path: "/users/{id}/{id}", Method: "GET", Template: "ok"}
+	err := route.Validate()
+	if err == nil || !strings.Contains(err.Error(), `validation error in field "path": invalid path template "/users/{id}/{id}": parameter "id" is used twice`) {
+		t.Errorf("Expected a path template error, got %v", err)
+	}
+
+	route = RouteConfig{Path: "/assets/{name}", Method: "GET", StaticDir: &StaticDirConfig{Root: "."}}
+	if err := route.Validate(); err == nil || !strings.Contains(err.Error(), "need a literal path") {
+		t.Errorf("Expected static_dir to reject path templates, got %v", err)
+	}
+}
+
+func TestRouteConfig_PathMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		expected    string
+		errContains string
+	}{
+		{name: "prefix", route: RouteConfig{Path: "/api/v1", PathMatch: PathMatchPrefix}, expected: `^/api/v1(?:/.*)?$`},
+		{name: "root prefix", route: RouteConfig{Path: "/", PathMatch: PathMatchPrefix}, expected: `^(?:/.*)?$`},
+		{name: "template prefix", route: RouteConfig{Path: "/users/{id}", PathMatch: PathMatchPrefix}, expected: `^/users/(?P<id>[^/]+)(?:/.*)?$`},
+		{name: "exact", route: RouteConfig{Path: "/api/v1", PathMatch: PathMatchExact}, expected: "/api/v1"},
+		{name: "regex prefix", route: RouteConfig{Path: "/^/api/", PathMatch: PathMatchPrefix}, errContains: `validation error in field "path_match": regex paths can't be matched by prefix`},
+		{name: "unknown", route: RouteConfig{Path: "/api", PathMatch: "suffix"}, errContains: `invalid path match "suffix", must be one of: exact, prefix`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.route.Method, tt.route.Template = "GET", "ok"
+			err := tt.route.Validate()
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			got, err := tt.route.PathRegex()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
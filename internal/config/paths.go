@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// resolvePaths resolves the relative template_file, body_file and static_dir
+// paths of c, parsed from file, against the directory of file, so
+// configurations work from any working directory and included files can sit
+// next to their templates and fixtures.
+// Paths only found relative to the working directory, where they used to be
+// resolved, are kept with a warning.
+func (c *Config) resolvePaths(file string) {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return // Reported when the file's includes are loaded
+	}
+
+	resolve := func(kind string, path *string) {
+		if *path == "" || filepath.IsAbs(*path) {
+			return
+		}
+
+		resolved := filepath.Join(dir, *path)
+		if _, err := os.Stat(resolved); err != nil {
+			if _, err := os.Stat(*path); err == nil {
+				c.warnings = append(c.warnings, fmt.Sprintf("%s: %s %q was loaded relative to the working directory since it's not at %s, make the path relative to the configuration file", file, kind, *path, resolved))
+				return
+			}
+		}
+		*path = resolved
+	}
+
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		resolve("template file", &route.TemplateFile)
+		resolve("body file", &route.BodyFile)
+		if route.StaticDir != nil {
+			resolve("static directory", &route.StaticDir.Root)
+		}
+		for j := range route.Responses {
+			resolve("template file", &route.Responses[j].TemplateFile)
+		}
+		if route.Variants != nil {
+			for name, variant := range route.Variants.Cases {
+				resolve("template file", &variant.TemplateFile)
+				route.Variants.Cases[name] = variant
+			}
+		}
+		if route.WebSocket != nil {
+			for j := range route.WebSocket.Messages {
+				resolve("template file", &route.WebSocket.Messages[j].TemplateFile)
+			}
+		}
+	}
+	if c.Fallback != nil {
+		resolve("template file", &c.Fallback.TemplateFile)
+	}
+	for _, page := range c.Server.ErrorPages.Pages() {
+		resolve("template file", &page.TemplateFile)
+	}
+	if c.GRPC != nil {
+		for i := range c.GRPC.Methods {
+			resolve("template file", &c.GRPC.Methods[i].TemplateFile)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_TemplateFilePaths(t *testing.T) {
+	t.Run("resolves template files relative to the declaring file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"main.yaml": `include:
+  - routes/users.yaml
+routes:
+  - path: /health
+    method: GET
+    template_file: templates/health.tmpl`,
+			"templates/health.tmpl": `ok`,
+			"routes/users.yaml": `routes:
+  - path: /users
+    method: GET
+    responses:
+      - template_file: users.tmpl
+  - path: /ws
+    method: GET
+    websocket:
+      messages:
+        - template_file: ../templates/welcome.tmpl`,
+			"routes/users.tmpl":      `[]`,
+			"templates/welcome.tmpl": `hi`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+
+		got := []string{cfg.Routes[0].TemplateFile, cfg.Routes[1].Responses[0].TemplateFile, cfg.Routes[2].WebSocket.Messages[0].TemplateFile}
+		expected := []string{
+			filepath.Join(dir, "templates", "health.tmpl"),
+			filepath.Join(dir, "routes", "users.tmpl"),
+			filepath.Join(dir, "templates", "welcome.tmpl"),
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("Expected template file %q, got %q", expected[i], got[i])
+			}
+		}
+		if len(cfg.Warnings()) != 0 {
+			t.Errorf("Expected no warnings, got %v", cfg.Warnings())
+		}
+	})
+
+	t.Run("names the resolved path of missing files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"configs/main.yaml": `routes:
+  - path: /health
+    method: GET
+    template_file: missing.tmpl`,
+		})
+
+		_, err := LoadConfig(filepath.Join(dir, "configs", "main.yaml"))
+		if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "configs", "missing.tmpl")) {
+			t.Errorf("Expected an error naming the resolved path, got %v", err)
+		}
+	})
+
+	t.Run("falls back to the working directory with a warning", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"configs/main.yaml": `routes:
+  - path: /health
+    method: GET
+    template_file: templates/health.tmpl`,
+			"templates/health.tmpl": `ok`,
+		})
+		t.Chdir(dir)
+
+		cfg, err := LoadConfig(filepath.Join("configs", "main.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if got := cfg.Routes[0].TemplateFile; got != filepath.Join("templates", "health.tmpl") {
+			t.Errorf("Expected the path to be kept, got %q", got)
+		}
+		if warnings := cfg.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "relative to the working directory") {
+			t.Errorf("Expected a warning about the path, got %v", warnings)
+		}
+	})
+}
+
+func TestLoadConfig_BodyFileAndStaticDirPaths(t *testing.T) {
+	t.Run("resolves body files and static directories relative to the declaring file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"conf/main.yaml": `routes:
+  - path: /avatar.png
+    method: GET
+    body_file: b.bin
+  - path: /sdk
+    method: GET
+    static_dir: assets/sdk`,
+			"conf/b.bin":                 "\x89PNG",
+			"conf/assets/sdk/client.js":  "export {}",
+			"conf/assets/sdk/index.html": "<html></html>",
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "conf", "main.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+
+		if got, expected := cfg.Routes[0].BodyFile, filepath.Join(dir, "conf", "b.bin"); got != expected {
+			t.Errorf("Expected body file %q, got %q", expected, got)
+		}
+		if got, expected := cfg.Routes[1].StaticDir.Root, filepath.Join(dir, "conf", "assets", "sdk"); got != expected {
+			t.Errorf("Expected static directory %q, got %q", expected, got)
+		}
+		if len(cfg.Warnings()) != 0 {
+			t.Errorf("Expected no warnings, got %v", cfg.Warnings())
+		}
+	})
+
+	t.Run("names the resolved path of missing files and directories", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"conf/body.yaml": `routes:
+  - path: /avatar.png
+    method: GET
+    body_file: missing.bin`,
+			"conf/static.yaml": `routes:
+  - path: /sdk
+    method: GET
+    static_dir: missing`,
+		})
+
+		_, err := LoadConfig(filepath.Join(dir, "conf", "body.yaml"))
+		if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "conf", "missing.bin")) {
+			t.Errorf("Expected an error naming the resolved body file, got %v", err)
+		}
+
+		_, err = LoadConfig(filepath.Join(dir, "conf", "static.yaml"))
+		if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "conf", "missing")) {
+			t.Errorf("Expected an error naming the resolved static directory, got %v", err)
+		}
+	})
+
+	t.Run("falls back to the working directory with a warning", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"conf/main.yaml": `routes:
+  - path: /avatar.png
+    method: GET
+    body_file: fixtures/b.bin
+  - path: /sdk
+    method: GET
+    static_dir:
+      root: fixtures/sdk`,
+			"fixtures/b.bin":          "\x89PNG",
+			"fixtures/sdk/index.html": "<html></html>",
+		})
+		t.Chdir(dir)
+
+		cfg, err := LoadConfig(filepath.Join("conf", "main.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if got := cfg.Routes[0].BodyFile; got != filepath.Join("fixtures", "b.bin") {
+			t.Errorf("Expected the body file path to be kept, got %q", got)
+		}
+		if got := cfg.Routes[1].StaticDir.Root; got != filepath.Join("fixtures", "sdk") {
+			t.Errorf("Expected the static directory path to be kept, got %q", got)
+		}
+
+		warnings := cfg.Warnings()
+		if len(warnings) != 2 || !strings.Contains(warnings[0], "body file") || !strings.Contains(warnings[1], "static directory") {
+			t.Errorf("Expected warnings about both paths, got %v", warnings)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package config
+
+import "fmt"
+
+// Route matching strategies, deciding which of the routes matching a request
+// of the same priority answers it
+const (
+	RouteMatchingOrder    = "order"    // The route listed first
+	RouteMatchingSpecific = "specific" // Literal paths before regexes, then the longest path
+)
+
+// validateRouteMatching validates the route matching strategy
+func (c *Config) validateRouteMatching() error {
+	switch c.Server.RouteMatching {
+	case "", RouteMatchingOrder, RouteMatchingSpecific:
+		return nil
+	}
+	return NewValidationError("server.route_matching", fmt.Sprintf("invalid strategy %q, must be one of: %s, %s", c.Server.RouteMatching, RouteMatchingOrder, RouteMatchingSpecific))
+}
+
+// MatchesSpecific reports whether routes are matched most specific first,
+// rather than in the order they're listed
+func (sc *ServerConfig) MatchesSpecific() bool {
+	return sc.RouteMatching == RouteMatchingSpecific
+}
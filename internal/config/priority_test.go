@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateRouteMatching(t *testing.T) {
+	tests := []struct {
+		strategy    string
+		specific    bool
+		errContains string
+	}{
+		{strategy: ""},
+		{strategy: "order"},
+		{strategy: "specific", specific: true},
+		{strategy: "longest", errContains: `validation error in field "server.route_matching": invalid strategy "longest", must be one of: order, specific`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			cfg := &Config{
+				Server: ServerConfig{RouteMatching: tt.strategy},
+				Routes: []RouteConfig{{Path: "/", Method: "GET", Template: "ok", Priority: 5}},
+			}
+
+			err := cfg.Validate()
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := cfg.Server.MatchesSpecific(); got != tt.specific {
+				t.Errorf("MatchesSpecific() = %v, want %v", got, tt.specific)
+			}
+		})
+	}
+}
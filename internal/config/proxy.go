@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ProxyConfig forwards a route's requests to a real upstream instead of
+// rendering a template, turning mockingjay into a partial mock
+type ProxyConfig struct {
+	URL         string `yaml:"url"`                    // Upstream base URL, e.g. "https://api.example.com"
+	StripPrefix string `yaml:"strip_prefix,omitempty"` // Prefix removed from the request path before forwarding
+	Record      bool   `yaml:"record,omitempty"`       // Keep upstream responses for inspection through the admin API
+}
+
+// validateProxy validates the proxy settings of a route
+func (r *RouteConfig) validateProxy() error {
+	if r.Proxy == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(r.Template) != "" || strings.TrimSpace(r.TemplateFile) != "" || len(r.Responses) > 0 || r.Sequence != nil {
+		return NewValidationError("proxy", "'proxy' cannot be combined with 'template', 'template_file', 'responses' or 'sequence'")
+	}
+
+	if len(r.ResponseHeaders) > 0 {
+		return NewValidationError("proxy", "'proxy' cannot be combined with 'response_headers', upstream headers are passed through")
+	}
+
+	if r.RequireToken != nil {
+		return NewValidationError("proxy", "'proxy' cannot be combined with 'require_token'")
+	}
+
+	if r.ClockSkew != nil {
+		return NewValidationError("proxy", "'proxy' cannot be combined with 'clock_skew', upstream headers are passed through")
+	}
+
+	if r.Transaction != nil {
+		return NewValidationError("proxy", "'proxy' cannot be combined with 'transaction'")
+	}
+
+	return r.Proxy.Validate()
+}
+
+// Validate validates a ProxyConfig
+func (p *ProxyConfig) Validate() error {
+	if err := validateUpstreamURL("proxy.url", p.URL); err != nil {
+		return err
+	}
+
+	if p.StripPrefix != "" && !strings.HasPrefix(p.StripPrefix, "/") {
+		return NewValidationError("proxy.strip_prefix", fmt.Sprintf("prefix %q must start with '/'", p.StripPrefix))
+	}
+
+	return nil
+}
+
+// validateFallbackProxy validates the upstream requests matching no route
+// are forwarded to
+func (c *Config) validateFallbackProxy() error {
+	if c.FallbackProxy == "" {
+		return nil
+	}
+	return validateUpstreamURL("fallback_proxy", c.FallbackProxy)
+}
+
+// validateUpstreamURL validates the base URL of an upstream server
+func validateUpstreamURL(field, rawURL string) error {
+	if strings.TrimSpace(rawURL) == "" {
+		return NewValidationError(field, "upstream URL cannot be empty")
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return NewValidationError(field, fmt.Sprintf("invalid upstream URL %q: %v", rawURL, err))
+	}
+
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return NewValidationError(field, fmt.Sprintf("upstream URL %q must use http or https", rawURL))
+	}
+
+	if target.Host == "" {
+		return NewValidationError(field, fmt.Sprintf("upstream URL %q must include a host", rawURL))
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouteConfig_ValidateProxy(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "proxy route - valid",
+			route: RouteConfig{
+				Path:   "/^/api/.*$/",
+				Method: "GET",
+				Proxy:  &ProxyConfig{URL: "https://api.example.com/v2", StripPrefix: "/api", Record: true},
+			},
+		},
+		{
+			name: "proxy with header matching - valid",
+			route: RouteConfig{
+				Path:         "/users",
+				Method:       "GET",
+				MatchHeaders: map[string]string{"X-Real": "true"},
+				Proxy:        &ProxyConfig{URL: "http://localhost:9000"},
+			},
+		},
+		{
+			name: "proxy with template - invalid",
+			route: RouteConfig{
+				Path:     "/users",
+				Method:   "GET",
+				Template: "mocked",
+				Proxy:    &ProxyConfig{URL: "http://localhost:9000"},
+			},
+			wantErr:     true,
+			errContains: "cannot be combined",
+		},
+		{
+			name: "proxy with response headers - invalid",
+			route: RouteConfig{
+				Path:            "/users",
+				Method:          "GET",
+				ResponseHeaders: map[string]string{"X-Mock": "true"},
+				Proxy:           &ProxyConfig{URL: "http://localhost:9000"},
+			},
+			wantErr:     true,
+			errContains: "response_headers",
+		},
+		{
+			name: "proxy with clock skew - invalid",
+			route: RouteConfig{
+				Path:      "/users",
+				Method:    "GET",
+				ClockSkew: new(time.Duration),
+				Proxy:     &ProxyConfig{URL: "http://localhost:9000"},
+			},
+			wantErr:     true,
+			errContains: "clock_skew",
+		},
+		{
+			name: "missing url - invalid",
+			route: RouteConfig{
+				Path:   "/users",
+				Method: "GET",
+				Proxy:  &ProxyConfig{},
+			},
+			wantErr:     true,
+			errContains: "upstream URL cannot be empty",
+		},
+		{
+			name: "unsupported scheme - invalid",
+			route: RouteConfig{
+				Path:   "/users",
+				Method: "GET",
+				Proxy:  &ProxyConfig{URL: "ftp://files.example.com"},
+			},
+			wantErr:     true,
+			errContains: "must use http or https",
+		},
+		{
+			name: "missing host - invalid",
+			route: RouteConfig{
+				Path:   "/users",
+				Method: "GET",
+				Proxy:  &ProxyConfig{URL: "http:///path"},
+			},
+			wantErr:     true,
+			errContains: "must include a host",
+		},
+		{
+			name: "relative strip prefix - invalid",
+			route: RouteConfig{
+				Path:   "/users",
+				Method: "GET",
+				Proxy:  &ProxyConfig{URL: "http://localhost:9000", StripPrefix: "api"},
+			},
+			wantErr:     true,
+			errContains: "must start with '/'",
+		},
+		{
+			name: "invalid path regex is still checked - invalid",
+			route: RouteConfig{
+				Path:   "/[unclosed/",
+				Method: "GET",
+				Proxy:  &ProxyConfig{URL: "http://localhost:9000"},
+			},
+			wantErr:     true,
+			errContains: "regex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateFallbackProxy(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		errContains string
+	}{
+		{name: "not configured", config: Config{Routes: []RouteConfig{{Path: "/users", Method: "GET", Template: "[]"}}}},
+		{name: "valid", config: Config{Routes: []RouteConfig{{Path: "/users", Method: "GET", Template: "[]"}}, FallbackProxy: "https://api.example.com"}},
+		{name: "passthrough without routes", config: Config{FallbackProxy: "http://localhost:9000"}},
+		{name: "unsupported scheme", config: Config{FallbackProxy: "ftp://files.example.com"}, errContains: "fallback_proxy"},
+		{name: "missing host", config: Config{FallbackProxy: "http:///path"}, errContains: "must include a host"},
+		{name: "no routes and no fallback", config: Config{}, errContains: "at least one route must be defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
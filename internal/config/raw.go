@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Actions taken on malformed requests to raw routes
+const (
+	RawReject        = "reject"         // Answer with a 400 Bad Request and close the connection (default)
+	RawClose         = "close"          // Close the connection without answering
+	RawFirst         = "first"          // Use the first Content-Length
+	RawLast          = "last"           // Use the last Content-Length
+	RawChunked       = "chunked"        // Read the body as chunked
+	RawContentLength = "content_length" // Read the body by its Content-Length
+	RawIgnore        = "ignore"         // Ignore the Transfer-Encoding
+	RawTruncate      = "truncate"       // Keep the chunks read so far as the body
+)
+
+// RawConfig serves a route from the connection itself, parsing requests
+// without Go's HTTP server, so deliberately malformed requests reach the mock
+// and are handled as configured instead of being refused up front. It's
+// meant for testing how clients and proxies deal with ambiguous framing, like
+// in request smuggling.
+type RawConfig struct {
+	DuplicateContentLength  string `yaml:"duplicate_content_length,omitempty"`  // Content-Length headers with different values: "reject", "close", "first" or "last"
+	ConflictingFraming      string `yaml:"conflicting_framing,omitempty"`       // Both Content-Length and Transfer-Encoding: "reject", "close", "chunked" or "content_length"
+	InvalidTransferEncoding string `yaml:"invalid_transfer_encoding,omitempty"` // Transfer-Encoding other than "chunked": "reject", "close", "chunked" or "ignore"
+	InvalidChunkSize        string `yaml:"invalid_chunk_size,omitempty"`        // Chunks with a bad size line or missing CRLF: "reject", "close" or "truncate"
+	KeepAlive               bool   `yaml:"keep_alive,omitempty"`                // Read the bytes following a request as the next request, like a server open to smuggling
+}
+
+// validateRaw validates the raw settings of a route
+func (r *RouteConfig) validateRaw() error {
+	if r.Raw == nil {
+		return nil
+	}
+
+	if r.WebSocket != nil || r.Stream != nil {
+		return NewValidationError("raw", "'raw' cannot be combined with 'websocket' or 'stream'")
+	}
+	if r.MatchClientCert != nil || r.MatchSNI != "" {
+		return NewValidationError("raw", "'raw' cannot be combined with 'match_client_cert' or 'match_sni', since raw routes are served over plain HTTP")
+	}
+
+	return r.Raw.Validate()
+}
+
+// Validate validates a RawConfig
+func (rc *RawConfig) Validate() error {
+	checks := []struct {
+		field   string
+		value   string
+		allowed []string
+	}{
+		{"duplicate_content_length", rc.DuplicateContentLength, []string{RawReject, RawClose, RawFirst, RawLast}},
+		{"conflicting_framing", rc.ConflictingFraming, []string{RawReject, RawClose, RawChunked, RawContentLength}},
+		{"invalid_transfer_encoding", rc.InvalidTransferEncoding, []string{RawReject, RawClose, RawChunked, RawIgnore}},
+		{"invalid_chunk_size", rc.InvalidChunkSize, []string{RawReject, RawClose, RawTruncate}},
+	}
+
+	for _, check := range checks {
+		if check.value != "" && !slices.Contains(check.allowed, check.value) {
+			return NewValidationError("raw."+check.field, fmt.Sprintf("invalid action %q, must be one of: %s", check.value, strings.Join(check.allowed, ", ")))
+		}
+	}
+	return nil
+}
+
+// validateRaw verifies that raw routes are served over plain HTTP, since
+// their requests are read from the connection before any TLS handshake
+func (c *Config) validateRaw() error {
+	if c.Server.TLS == nil {
+		return nil
+	}
+
+	for i, route := range c.Routes {
+		if route.Raw != nil {
+			return fmt.Errorf("route[%d]: %w", i, NewValidationError("raw", "raw routes can't be served with 'server.tls'"))
+		}
+	}
+	return nil
+}
+
+// rawAction returns action, or "reject" when it's unset
+func rawAction(action string) string {
+	if action == "" {
+		return RawReject
+	}
+	return action
+}
+
+// GetDuplicateContentLength returns the action for differing Content-Length
+// headers, with the default applied
+func (rc *RawConfig) GetDuplicateContentLength() string {
+	return rawAction(rc.DuplicateContentLength)
+}
+
+// GetConflictingFraming returns the action for requests with both
+// Content-Length and Transfer-Encoding, with the default applied
+func (rc *RawConfig) GetConflictingFraming() string {
+	return rawAction(rc.ConflictingFraming)
+}
+
+// GetInvalidTransferEncoding returns the action for a Transfer-Encoding
+// other than "chunked", with the default applied
+func (rc *RawConfig) GetInvalidTransferEncoding() string {
+	return rawAction(rc.InvalidTransferEncoding)
+}
+
+// GetInvalidChunkSize returns the action for malformed chunks, with the
+// default applied
+func (rc *RawConfig) GetInvalidChunkSize() string {
+	return rawAction(rc.InvalidChunkSize)
+}
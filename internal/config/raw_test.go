@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateRaw(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "defaults - valid",
+			route: RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{}},
+		},
+		{
+			name: "all actions - valid",
+			route: RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{
+				DuplicateContentLength:  RawLast,
+				ConflictingFraming:      RawContentLength,
+				InvalidTransferEncoding: RawIgnore,
+				InvalidChunkSize:        RawTruncate,
+				KeepAlive:               true,
+			}},
+		},
+		{
+			name:        "unknown action - invalid",
+			route:       RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{DuplicateContentLength: "sum"}},
+			errContains: "raw.duplicate_content_length",
+		},
+		{
+			name:        "action of another anomaly - invalid",
+			route:       RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{InvalidChunkSize: RawFirst}},
+			errContains: "must be one of: reject, close, truncate",
+		},
+		{
+			name:        "with stream - invalid",
+			route:       RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{}, Stream: &StreamConfig{}},
+			errContains: "cannot be combined with 'websocket' or 'stream'",
+		},
+		{
+			name:        "with client certificates - invalid",
+			route:       RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{}, MatchClientCert: &ClientCertMatchConfig{CN: "client"}},
+			errContains: "'match_client_cert'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateRawWithTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+	cfg := &Config{
+		Server: ServerConfig{TLS: &TLSConfig{CertFile: certFile, KeyFile: keyFile}},
+		Routes: []RouteConfig{{Path: "/upload", Method: "POST", Template: "ok", Raw: &RawConfig{}}},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "raw routes can't be served with 'server.tls'") {
+		t.Errorf("Expected the raw route to be rejected with TLS, got %v", err)
+	}
+}
+
+func TestRawConfig_Defaults(t *testing.T) {
+	r := &RawConfig{}
+	for name, got := range map[string]string{
+		"duplicate_content_length":  r.GetDuplicateContentLength(),
+		"conflicting_framing":       r.GetConflictingFraming(),
+		"invalid_transfer_encoding": r.GetInvalidTransferEncoding(),
+		"invalid_chunk_size":        r.GetInvalidChunkSize(),
+	} {
+		if got != RawReject {
+			t.Errorf("Expected %s to default to %q, got %q", name, RawReject, got)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// CacheDirEnv names the environment variable that overrides the directory
+	// remote includes are cached in
+	CacheDirEnv = "MOCKINGJAY_CACHE_DIR"
+
+	remoteIncludeTimeout = 30 * time.Second
+	maxRemoteIncludeSize = 10 << 20 // 10 MiB
+)
+
+// remoteClient fetches remote includes
+var remoteClient = &http.Client{Timeout: remoteIncludeTimeout}
+
+// loadRemote fetches and parses a remote include. When the fetch fails, the
+// cached copy from a previous fetch is used instead, and a warning recorded.
+func (c *Config) loadRemote(inc IncludeConfig) (*Config, error) {
+	if err := inc.Validate(); err != nil {
+		return nil, NewLoadError(inc.URL, err)
+	}
+
+	data, err := fetchInclude(inc)
+	if err != nil {
+		cached, cacheErr := readCachedInclude(inc)
+		if cacheErr != nil {
+			return nil, NewLoadError(inc.URL, err)
+		}
+		c.warnings = append(c.warnings, fmt.Sprintf("using cached copy of %s: %v", inc.URL, err))
+		data = cached
+	}
+
+	parsed, err := parseConfig(inc.URL, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := parsed.checkRemote(); err != nil {
+		return nil, NewLoadError(inc.URL, fmt.Errorf("configuration validation failed: %w", err))
+	}
+	for i := range parsed.Routes {
+		parsed.Routes[i].remote = true
+	}
+
+	c.remote = append(c.remote, inc)
+	return parsed, nil
+}
+
+// checkRemote rejects routes of a remote file that reach into the local
+// filesystem, since whoever serves the file would otherwise decide which
+// files of the machine running the server get read or served. Calls to
+// dataFile are only caught here in body templates; the router compiles every
+// template of remote routes without it, see RouteConfig.FromRemote.
+func (c *Config) checkRemote() error {
+	check := func(field, template, templateFile string) error {
+		if templateFile != "" {
+			return NewValidationError(field+"template_file", fmt.Sprintf("remote files can't reference local files, got %q", templateFile))
+		}
+		if strings.Contains(template, "dataFile") {
+			return NewValidationError(field+"template", "remote files can't read local files with dataFile")
+		}
+		return nil
+	}
+
+	for i, route := range c.Routes {
+		prefix := fmt.Sprintf("route[%d].", i)
+		if err := check(prefix, route.Template, route.TemplateFile); err != nil {
+			return err
+		}
+		if route.BodyFile != "" {
+			return NewValidationError(prefix+"body_file", fmt.Sprintf("remote files can't reference local files, got %q", route.BodyFile))
+		}
+		if route.StaticDir != nil {
+			return NewValidationError(prefix+"static_dir", "remote files can't serve local directories")
+		}
+		for j, resp := range route.Responses {
+			if err := check(fmt.Sprintf("%sresponses[%d].", prefix, j), resp.Template, resp.TemplateFile); err != nil {
+				return err
+			}
+		}
+		if route.Variants != nil {
+			for _, name := range route.Variants.Names() {
+				variant := route.Variants.Cases[name]
+				if err := check(fmt.Sprintf("%svariants.cases[%s].", prefix, name), variant.Template, variant.TemplateFile); err != nil {
+					return err
+				}
+			}
+		}
+		if route.WebSocket != nil {
+			for j, msg := range route.WebSocket.Messages {
+				if err := check(fmt.Sprintf("%swebsocket.messages[%d].", prefix, j), msg.Template, msg.TemplateFile); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fetchInclude returns the contents of a remote include. Files with a pinned
+// checksum are served from the cache when it holds a matching copy, and every
+// other file is revalidated with the server.
+func fetchInclude(inc IncludeConfig) ([]byte, error) {
+	if inc.SHA256 != "" {
+		if data, err := readCachedInclude(inc); err == nil {
+			return data, nil
+		}
+	}
+
+	data, _, err := refreshInclude(inc)
+	return data, err
+}
+
+// RefreshInclude checks a remote include for changes, updating its cached
+// copy, and reports whether its contents changed
+func RefreshInclude(inc IncludeConfig) (bool, error) {
+	_, changed, err := refreshInclude(inc)
+	return changed, err
+}
+
+// refreshInclude fetches a remote include, sending the ETag of the cached
+// copy so unchanged files aren't downloaded again, and updates the cache. It
+// returns the file's contents and whether they differ from the cached copy.
+func refreshInclude(inc IncludeConfig) ([]byte, bool, error) {
+	file, err := remoteCacheFile(inc.URL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, inc.URL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid URL %q: %w", inc.URL, err)
+	}
+
+	cached, cacheErr := os.ReadFile(file)
+	if cacheErr == nil {
+		if etag, err := os.ReadFile(file + ".etag"); err == nil && len(etag) > 0 {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", inc.URL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && req.Header.Get("If-None-Match") != "":
+		if err := verifyChecksum(inc, cached); err != nil {
+			return nil, false, err
+		}
+		return cached, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("failed to fetch %s: unexpected status %s", inc.URL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteIncludeSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", inc.URL, err)
+	}
+	if len(data) > maxRemoteIncludeSize {
+		return nil, false, fmt.Errorf("failed to fetch %s: file is larger than %d bytes", inc.URL, maxRemoteIncludeSize)
+	}
+	if err := verifyChecksum(inc, data); err != nil {
+		return nil, false, err
+	}
+
+	changed := cacheErr != nil || !bytes.Equal(data, cached)
+	if err := writeCachedInclude(file, data, resp.Header.Get("ETag"), changed); err != nil {
+		return nil, false, err
+	}
+	return data, changed, nil
+}
+
+// verifyChecksum checks the contents of a remote include against its pinned
+// checksum, if it has one
+func verifyChecksum(inc IncludeConfig, data []byte) error {
+	if inc.SHA256 == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, inc.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", inc.URL, strings.ToLower(inc.SHA256), got)
+	}
+	return nil
+}
+
+// readCachedInclude returns the cached copy of a remote include, provided it
+// matches the include's pinned checksum
+func readCachedInclude(inc IncludeConfig) ([]byte, error) {
+	file, err := remoteCacheFile(inc.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(inc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeCachedInclude stores the contents and ETag of a remote include. The
+// contents are only rewritten when they changed, and are replaced atomically
+// so concurrent loads never read a partial file.
+func writeCachedInclude(file string, data []byte, etag string, changed bool) error {
+	if changed {
+		tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+		if err != nil {
+			return fmt.Errorf("failed to cache remote include: %w", err)
+		}
+
+		_, err = tmp.Write(data)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), file)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return fmt.Errorf("failed to cache remote include: %w", err)
+		}
+	}
+
+	if etag == "" {
+		if err := os.Remove(file + ".etag"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to cache remote include: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(file+".etag", []byte(etag), 0o644); err != nil {
+		return fmt.Errorf("failed to cache remote include: %w", err)
+	}
+	return nil
+}
+
+// remoteCacheFile returns the path a remote include is cached at, creating
+// the cache directory if needed. The directory is the user's cache directory
+// unless overridden through the MOCKINGJAY_CACHE_DIR environment variable.
+func remoteCacheFile(rawURL string) (string, error) {
+	dir := os.Getenv(CacheDirEnv)
+	if dir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find a cache directory for remote includes, set %s: %w", CacheDirEnv, err)
+		}
+		dir = filepath.Join(userDir, "mockingjay", "includes")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory for remote includes: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".yaml"), nil
+}
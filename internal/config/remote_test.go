@@ -0,0 +1,339 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// remoteFiles serves configuration files over HTTPS, with an ETag for each,
+// and counts the requests it gets
+type remoteFiles struct {
+	mu          sync.Mutex
+	files       map[string]string
+	requests    int
+	notModified int
+}
+
+func newRemoteFiles(t *testing.T, files map[string]string) (*remoteFiles, *httptest.Server) {
+	t.Helper()
+	t.Setenv(CacheDirEnv, t.TempDir())
+
+	rf := &remoteFiles{files: files}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rf.mu.Lock()
+		defer rf.mu.Unlock()
+
+		rf.requests++
+		content, ok := rf.files[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		etag := fmt.Sprintf("%q", checksum(content))
+		if r.Header.Get("If-None-Match") == etag {
+			rf.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, content)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := remoteClient
+	remoteClient = srv.Client()
+	t.Cleanup(func() { remoteClient = client })
+
+	return rf, srv
+}
+
+func (rf *remoteFiles) set(name, content string) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.files[name] = content
+}
+
+func (rf *remoteFiles) counts() (int, int) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.requests, rf.notModified
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+const remotePayments = `include:
+  - shared.yaml
+routes:
+  - path: /payments
+    method: GET
+    template: "payments"`
+
+const remoteShared = `routes:
+  - path: /shared
+    method: GET
+    template: "shared"`
+
+func TestLoadConfig_RemoteInclude(t *testing.T) {
+	loadMain := func(t *testing.T, include string) (*Config, error) {
+		t.Helper()
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"main.yaml": include + `
+routes:
+  - path: /health
+    method: GET
+    template: "ok"`,
+		})
+		return LoadConfig(filepath.Join(dir, "main.yaml"))
+	}
+
+	t.Run("pulls in routes from a URL and revalidates them with their ETag", func(t *testing.T) {
+		rf, srv := newRemoteFiles(t, map[string]string{"mocks/payments.yaml": remotePayments, "mocks/shared.yaml": remoteShared})
+		include := fmt.Sprintf("include:\n  - %s/mocks/payments.yaml", srv.URL)
+
+		cfg, err := loadMain(t, include)
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		if expected := []string{"/health", "/payments", "/shared"}; !slices.Equal(routePaths(cfg), expected) {
+			t.Errorf("Expected routes %v, got %v", expected, routePaths(cfg))
+		}
+		if len(cfg.IncludedFiles()) != 0 {
+			t.Errorf("Expected remote files not to be watched, got %v", cfg.IncludedFiles())
+		}
+		for _, route := range cfg.Routes {
+			if remote := route.Path != "/health"; route.FromRemote() != remote {
+				t.Errorf("Expected route %s to be marked as remote: %t", route.Path, remote)
+			}
+		}
+
+		if _, err := loadMain(t, include); err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		if requests, notModified := rf.counts(); requests != 4 || notModified != 2 {
+			t.Errorf("Expected the second load to revalidate both files, got %d requests and %d not modified", requests, notModified)
+		}
+	})
+
+	t.Run("falls back to the cached copy when the fetch fails", func(t *testing.T) {
+		_, srv := newRemoteFiles(t, map[string]string{"shared.yaml": remoteShared})
+		include := fmt.Sprintf("include:\n  - %s/shared.yaml", srv.URL)
+
+		if _, err := loadMain(t, include); err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		srv.Close()
+
+		cfg, err := loadMain(t, include)
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		if expected := []string{"/health", "/shared"}; !slices.Equal(routePaths(cfg), expected) {
+			t.Errorf("Expected routes %v, got %v", expected, routePaths(cfg))
+		}
+		if len(cfg.Warnings()) != 1 || !strings.Contains(cfg.Warnings()[0], "using cached copy") {
+			t.Errorf("Expected a warning about the cached copy, got %v", cfg.Warnings())
+		}
+	})
+
+	t.Run("serves pinned files from the cache", func(t *testing.T) {
+		rf, srv := newRemoteFiles(t, map[string]string{"shared.yaml": remoteShared})
+		include := fmt.Sprintf("include:\n  - url: %s/shared.yaml\n    sha256: %s", srv.URL, checksum(remoteShared))
+
+		for range 2 {
+			if _, err := loadMain(t, include); err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+		}
+		if requests, _ := rf.counts(); requests != 1 {
+			t.Errorf("Expected a single request, got %d", requests)
+		}
+	})
+
+	t.Run("rejects files not matching their pinned checksum", func(t *testing.T) {
+		_, srv := newRemoteFiles(t, map[string]string{"shared.yaml": remoteShared})
+		include := fmt.Sprintf("include:\n  - url: %s/shared.yaml\n    sha256: %s", srv.URL, checksum("something else"))
+
+		_, err := loadMain(t, include)
+		if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Errorf("Expected a checksum mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("fails when the file can't be fetched and isn't cached", func(t *testing.T) {
+		_, srv := newRemoteFiles(t, map[string]string{})
+		include := fmt.Sprintf("include:\n  - %s/missing.yaml", srv.URL)
+
+		_, err := loadMain(t, include)
+		if err == nil || !strings.Contains(err.Error(), "unexpected status 404") {
+			t.Errorf("Expected a fetch error, got %v", err)
+		}
+	})
+
+	t.Run("rejects patterns in remote files", func(t *testing.T) {
+		_, srv := newRemoteFiles(t, map[string]string{"all.yaml": "include:\n  - '*.yaml'"})
+		include := fmt.Sprintf("include:\n  - %s/all.yaml", srv.URL)
+
+		_, err := loadMain(t, include)
+		if err == nil || !strings.Contains(err.Error(), "remote files can only include URLs and relative paths") {
+			t.Errorf("Expected an error about the pattern, got %v", err)
+		}
+	})
+
+	t.Run("requires a pinned checksum over plain http", func(t *testing.T) {
+		t.Setenv(CacheDirEnv, t.TempDir())
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, remoteShared)
+		}))
+		defer srv.Close()
+
+		_, err := loadMain(t, fmt.Sprintf("include:\n  - %s/shared.yaml", srv.URL))
+		if err == nil || !strings.Contains(err.Error(), "must pin the file with 'sha256', or use https://") {
+			t.Errorf("Expected an error about the plain http URL, got %v", err)
+		}
+
+		cfg, err := loadMain(t, fmt.Sprintf("include:\n  - url: %s/shared.yaml\n    sha256: %s", srv.URL, checksum(remoteShared)))
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		if expected := []string{"/health", "/shared"}; !slices.Equal(routePaths(cfg), expected) {
+			t.Errorf("Expected routes %v, got %v", expected, routePaths(cfg))
+		}
+	})
+
+	t.Run("rejects local file references", func(t *testing.T) {
+		tests := map[string]struct {
+			route       string
+			errContains string
+		}{
+			"template file": {route: "template_file: users.tmpl", errContains: `route[0].template_file": remote files can't reference local files`},
+			"body file":     {route: "body_file: /etc/passwd", errContains: `route[0].body_file": remote files can't reference local files`},
+			"static dir":    {route: "static_dir: /", errContains: `route[0].static_dir": remote files can't serve local directories`},
+			"data file":     {route: `template: '{{ dataFile "/etc/app.yaml" }}'`, errContains: `route[0].template": remote files can't read local files`},
+			"response template file": {
+				route:       "responses:\n      - name: empty\n        template_file: empty.tmpl",
+				errContains: `route[0].responses[0].template_file"`,
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, srv := newRemoteFiles(t, map[string]string{"local.yaml": "routes:\n  - path: /local\n    method: GET\n    " + tt.route})
+
+				_, err := loadMain(t, fmt.Sprintf("include:\n  - %s/local.yaml", srv.URL))
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected an error containing %q, got %v", tt.errContains, err)
+				}
+			})
+		}
+	})
+
+	t.Run("lists the includes refreshed while running", func(t *testing.T) {
+		_, srv := newRemoteFiles(t, map[string]string{"payments.yaml": remotePayments, "shared.yaml": remoteShared})
+		include := fmt.Sprintf("include:\n  - url: %s/payments.yaml\n    refresh: 5m", srv.URL)
+
+		cfg, err := loadMain(t, include)
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+
+		includes := cfg.RemoteIncludes()
+		if len(includes) != 1 || includes[0].URL != srv.URL+"/payments.yaml" || includes[0].Refresh != 5*time.Minute {
+			t.Errorf("Expected only the included file with a refresh interval, got %+v", includes)
+		}
+	})
+}
+
+func TestRefreshInclude(t *testing.T) {
+	rf, srv := newRemoteFiles(t, map[string]string{"shared.yaml": remoteShared})
+	inc := IncludeConfig{URL: srv.URL + "/shared.yaml", Refresh: time.Minute}
+
+	if changed, err := RefreshInclude(inc); err != nil || !changed {
+		t.Fatalf("Expected the first fetch to change the cache, got %v, %v", changed, err)
+	}
+	if changed, err := RefreshInclude(inc); err != nil || changed {
+		t.Errorf("Expected an unchanged file, got %v, %v", changed, err)
+	}
+
+	rf.set("shared.yaml", remoteShared+"\n  - path: /other\n    method: GET\n    template: other")
+	if changed, err := RefreshInclude(inc); err != nil || !changed {
+		t.Errorf("Expected a changed file, got %v, %v", changed, err)
+	}
+}
+
+func TestIncludeConfig_UnmarshalYAML(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"main.yaml": `include:
+  - routes.yaml
+  - https://mocks.example.com/payments.yaml
+  - url: https://mocks.example.com/users.yaml
+    refresh: 10m`,
+	})
+
+	cfg, err := parseConfigFile(filepath.Join(dir, "main.yaml"))
+	if err != nil {
+		t.Fatalf("parseConfigFile() unexpected error: %v", err)
+	}
+
+	expected := []IncludeConfig{
+		{Path: "routes.yaml"},
+		{URL: "https://mocks.example.com/payments.yaml"},
+		{URL: "https://mocks.example.com/users.yaml", Refresh: 10 * time.Minute},
+	}
+	if !slices.Equal(cfg.Include, expected) {
+		t.Errorf("Expected includes %+v, got %+v", expected, cfg.Include)
+	}
+}
+
+func TestIncludeConfig_Validate(t *testing.T) {
+	sum := checksum("routes: []")
+
+	tests := []struct {
+		name        string
+		include     IncludeConfig
+		errContains string
+	}{
+		{name: "local path", include: IncludeConfig{Path: "routes/*.yaml"}},
+		{name: "URL", include: IncludeConfig{URL: "https://mocks.example.com/payments.yaml", Refresh: time.Minute}},
+		{name: "pinned URL", include: IncludeConfig{URL: "https://mocks.example.com/payments.yaml", SHA256: sum}},
+		{name: "pinned plain http URL", include: IncludeConfig{URL: "http://mocks.example.com/payments.yaml", SHA256: sum}},
+		{name: "plain http URL", include: IncludeConfig{URL: "http://mocks.example.com/payments.yaml"}, errContains: "must pin the file with 'sha256'"},
+		{name: "empty", include: IncludeConfig{}, errContains: "include path cannot be empty"},
+		{name: "path and URL", include: IncludeConfig{Path: "a.yaml", URL: "https://mocks.example.com/a.yaml"}, errContains: "cannot be combined"},
+		{name: "checksum on a path", include: IncludeConfig{Path: "a.yaml", SHA256: sum}, errContains: "can only be used with 'url'"},
+		{name: "relative URL", include: IncludeConfig{URL: "/payments.yaml"}, errContains: "must be an absolute http:// or https:// URL"},
+		{name: "other scheme", include: IncludeConfig{URL: "ftp://mocks.example.com/a.yaml"}, errContains: "must be an absolute http:// or https:// URL"},
+		{name: "invalid checksum", include: IncludeConfig{URL: "https://mocks.example.com/a.yaml", SHA256: "abc"}, errContains: "must be 64 hexadecimal characters"},
+		{name: "negative refresh", include: IncludeConfig{URL: "https://mocks.example.com/a.yaml", Refresh: -time.Second}, errContains: "cannot be negative"},
+		{name: "refresh on a pinned URL", include: IncludeConfig{URL: "https://mocks.example.com/a.yaml", SHA256: sum, Refresh: time.Minute}, errContains: "a pinned file never changes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.include.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ResponseConfig represents one of several possible responses for a route.
+// When a route declares responses, one is picked per request: responses whose
+// conditions match take priority, and ties are broken randomly by weight.
+type ResponseConfig struct {
+	Weight          int                `yaml:"weight,omitempty"`           // Relative selection weight (default: 1)
+	When            *ResponseCondition `yaml:"when,omitempty"`             // Conditions the request must meet
+	Status          int                `yaml:"status,omitempty"`           // Response status code (default: 200)
+	Template        string             `yaml:"template,omitempty"`         // Inline response template
+	TemplateFile    string             `yaml:"template_file,omitempty"`    // Response template file
+	ResponseHeaders map[string]string  `yaml:"response_headers,omitempty"` // Headers added on top of the route's
+}
+
+// ResponseCondition represents request attributes a response requires.
+// Values are literal strings or regex patterns wrapped in slashes.
+type ResponseCondition struct {
+	Headers map[string]string `yaml:"headers,omitempty"` // Request header values
+	Query   map[string]string `yaml:"query,omitempty"`   // Query parameter values
+	Body    map[string]string `yaml:"body,omitempty"`    // JSON body values by dotted path (e.g. "user.role")
+}
+
+// validateResponses validates the list of alternative responses of a route
+func (r *RouteConfig) validateResponses() error {
+	for i, resp := range r.Responses {
+		if err := resp.Validate(); err != nil {
+			return fmt.Errorf("responses[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates a single ResponseConfig
+func (rc *ResponseConfig) Validate() error {
+	hasTemplate := strings.TrimSpace(rc.Template) != ""
+	hasTemplateFile := strings.TrimSpace(rc.TemplateFile) != ""
+
+	if hasTemplate == hasTemplateFile {
+		return NewValidationError("template", "exactly one of 'template' or 'template_file' must be specified")
+	}
+
+	if hasTemplateFile {
+		route := RouteConfig{TemplateFile: rc.TemplateFile}
+		if err := route.validateTemplateFileExists(); err != nil {
+			return err
+		}
+	}
+
+	if rc.Weight < 0 {
+		return NewValidationError("weight", fmt.Sprintf("weight cannot be negative, got %d", rc.Weight))
+	}
+
+	if rc.Status != 0 && (rc.Status < 100 || rc.Status > 599) {
+		return NewValidationError("status", fmt.Sprintf("invalid HTTP status code %d, must be between 100 and 599", rc.Status))
+	}
+
+	route := RouteConfig{ResponseHeaders: rc.ResponseHeaders}
+	if err := route.validateResponseHeaders(); err != nil {
+		return err
+	}
+
+	if rc.When != nil {
+		if err := rc.When.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the patterns of a ResponseCondition
+func (c *ResponseCondition) Validate() error {
+	sections := []struct {
+		name   string
+		values map[string]string
+	}{
+		{"when.headers", c.Headers},
+		{"when.query", c.Query},
+		{"when.body", c.Body},
+	}
+
+	for _, section := range sections {
+		for key, value := range section.values {
+			if strings.TrimSpace(key) == "" {
+				return NewValidationError(section.name, "key cannot be empty")
+			}
+			if isRegexPattern(value) {
+				pattern := extractRegexPattern(value)
+				if _, err := regexp.Compile(pattern); err != nil {
+					return NewValidationError(section.name, fmt.Sprintf("invalid regex pattern %q for %q: %v", pattern, key, err))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetWeight returns the selection weight, defaulting to 1
+func (rc *ResponseConfig) GetWeight() int {
+	if rc.Weight == 0 {
+		return 1
+	}
+	return rc.Weight
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "weighted responses - valid",
+			route: RouteConfig{
+				Path:   "/flaky",
+				Method: "GET",
+				Responses: []ResponseConfig{
+					{Weight: 9, Template: "ok"},
+					{Weight: 1, Status: 503, Template: "unavailable"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "conditional responses - valid",
+			route: RouteConfig{
+				Path:   "/login",
+				Method: "POST",
+				Responses: []ResponseConfig{
+					{
+						When: &ResponseCondition{
+							Headers: map[string]string{"X-Tenant": "/^acme-/"},
+							Query:   map[string]string{"debug": "true"},
+							Body:    map[string]string{"user.role": "admin"},
+						},
+						Template: "welcome admin",
+					},
+					{Status: 401, Template: "denied"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "template and responses - invalid",
+			route: RouteConfig{
+				Path:      "/both",
+				Method:    "GET",
+				Template:  "route template",
+				Responses: []ResponseConfig{{Template: "ok"}},
+			},
+			wantErr:     true,
+			errContains: "cannot be combined with",
+		},
+		{
+			name: "response without template - invalid",
+			route: RouteConfig{
+				Path:      "/empty",
+				Method:    "GET",
+				Responses: []ResponseConfig{{Status: 204}},
+			},
+			wantErr:     true,
+			errContains: "responses[0]",
+		},
+		{
+			name: "negative weight - invalid",
+			route: RouteConfig{
+				Path:      "/weights",
+				Method:    "GET",
+				Responses: []ResponseConfig{{Weight: -1, Template: "ok"}},
+			},
+			wantErr:     true,
+			errContains: "weight cannot be negative",
+		},
+		{
+			name: "invalid status - invalid",
+			route: RouteConfig{
+				Path:      "/status",
+				Method:    "GET",
+				Responses: []ResponseConfig{{Status: 700, Template: "ok"}},
+			},
+			wantErr:     true,
+			errContains: "invalid HTTP status code 700",
+		},
+		{
+			name: "invalid condition regex - invalid",
+			route: RouteConfig{
+				Path:   "/regex",
+				Method: "GET",
+				Responses: []ResponseConfig{{
+					When:     &ResponseCondition{Query: map[string]string{"id": "/[unclosed/"}},
+					Template: "ok",
+				}},
+			},
+			wantErr:     true,
+			errContains: "when.query",
+		},
+		{
+			name: "empty condition key - invalid",
+			route: RouteConfig{
+				Path:   "/keys",
+				Method: "GET",
+				Responses: []ResponseConfig{{
+					When:     &ResponseCondition{Body: map[string]string{"": "x"}},
+					Template: "ok",
+				}},
+			},
+			wantErr:     true,
+			errContains: "key cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestResponseConfig_GetWeight(t *testing.T) {
+	tests := []struct {
+		weight int
+		want   int
+	}{
+		{weight: 0, want: 1},
+		{weight: 1, want: 1},
+		{weight: 7, want: 7},
+	}
+
+	for _, tt := range tests {
+		rc := ResponseConfig{Weight: tt.weight}
+		if got := rc.GetWeight(); got != tt.want {
+			t.Errorf("Expected weight %d for configured %d, got %d", tt.want, tt.weight, got)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import "fmt"
+
+// Sequence state scopes
+const (
+	SequencePerRoute  = "route"  // All clients share one position in the sequence
+	SequencePerClient = "client" // Each client advances through the sequence on its own
+)
+
+// SequenceConfig turns a route's responses into an ordered sequence: the first
+// call gets the first response, the second call the second one, and so on.
+// Useful to mock polling APIs and asynchronous jobs.
+type SequenceConfig struct {
+	Per          string `yaml:"per,omitempty"`           // "route" (default) or "client"
+	ClientHeader string `yaml:"client_header,omitempty"` // Header identifying clients (default: remote IP)
+	Loop         bool   `yaml:"loop,omitempty"`          // Start over after the last response instead of repeating it
+}
+
+// validateSequence validates the sequence settings of a route
+func (r *RouteConfig) validateSequence() error {
+	if r.Sequence == nil {
+		return nil
+	}
+
+	if len(r.Responses) == 0 {
+		return NewValidationError("sequence", "'sequence' requires 'responses' to step through")
+	}
+
+	for i, resp := range r.Responses {
+		if resp.When != nil {
+			return NewValidationError("sequence", fmt.Sprintf("responses[%d]: 'when' cannot be used in a sequence", i))
+		}
+		if resp.Weight != 0 {
+			return NewValidationError("sequence", fmt.Sprintf("responses[%d]: 'weight' cannot be used in a sequence", i))
+		}
+	}
+
+	switch r.Sequence.GetPer() {
+	case SequencePerRoute:
+		if r.Sequence.ClientHeader != "" {
+			return NewValidationError("sequence.client_header", fmt.Sprintf("'client_header' requires 'per: %s'", SequencePerClient))
+		}
+	case SequencePerClient:
+	default:
+		return NewValidationError("sequence.per", fmt.Sprintf("invalid value %q, must be %q or %q", r.Sequence.Per, SequencePerRoute, SequencePerClient))
+	}
+
+	return nil
+}
+
+// GetPer returns the sequence state scope, defaulting to per route
+func (s *SequenceConfig) GetPer() string {
+	if s.Per == "" {
+		return SequencePerRoute
+	}
+	return s.Per
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateSequence(t *testing.T) {
+	twoResponses := []ResponseConfig{
+		{Status: 202, Template: "pending"},
+		{Template: "done"},
+	}
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "per route sequence - valid",
+			route: RouteConfig{
+				Path:      "/jobs/1",
+				Method:    "GET",
+				Responses: twoResponses,
+				Sequence:  &SequenceConfig{},
+			},
+		},
+		{
+			name: "per client sequence with header - valid",
+			route: RouteConfig{
+				Path:      "/jobs/1",
+				Method:    "GET",
+				Responses: twoResponses,
+				Sequence:  &SequenceConfig{Per: "client", ClientHeader: "X-Client-ID", Loop: true},
+			},
+		},
+		{
+			name: "sequence without responses - invalid",
+			route: RouteConfig{
+				Path:     "/jobs/1",
+				Method:   "GET",
+				Template: "done",
+				Sequence: &SequenceConfig{},
+			},
+			wantErr:     true,
+			errContains: "requires 'responses'",
+		},
+		{
+			name: "sequence with conditions - invalid",
+			route: RouteConfig{
+				Path:   "/jobs/1",
+				Method: "GET",
+				Responses: []ResponseConfig{
+					{When: &ResponseCondition{Query: map[string]string{"a": "b"}}, Template: "a"},
+				},
+				Sequence: &SequenceConfig{},
+			},
+			wantErr:     true,
+			errContains: "'when' cannot be used",
+		},
+		{
+			name: "sequence with weights - invalid",
+			route: RouteConfig{
+				Path:      "/jobs/1",
+				Method:    "GET",
+				Responses: []ResponseConfig{{Weight: 2, Template: "a"}},
+				Sequence:  &SequenceConfig{},
+			},
+			wantErr:     true,
+			errContains: "'weight' cannot be used",
+		},
+		{
+			name: "unknown scope - invalid",
+			route: RouteConfig{
+				Path:      "/jobs/1",
+				Method:    "GET",
+				Responses: twoResponses,
+				Sequence:  &SequenceConfig{Per: "session"},
+			},
+			wantErr:     true,
+			errContains: "sequence.per",
+		},
+		{
+			name: "client header without per client - invalid",
+			route: RouteConfig{
+				Path:      "/jobs/1",
+				Method:    "GET",
+				Responses: twoResponses,
+				Sequence:  &SequenceConfig{ClientHeader: "X-Client-ID"},
+			},
+			wantErr:     true,
+			errContains: "sequence.client_header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
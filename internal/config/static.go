@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultStaticIndex is the file served for directories of static_dir routes
+// when they set no index
+const DefaultStaticIndex = "index.html"
+
+// StaticDirConfig serves the files of a directory under a route's path, so
+// a mock can host fixture assets, SDK stubs or downloadable artifacts. In
+// YAML it is either the directory, or a mapping with the settings below.
+type StaticDirConfig struct {
+	Root    string `yaml:"root"`              // Directory the files are served from
+	Index   string `yaml:"index,omitempty"`   // File served for directories (default: "index.html")
+	Listing bool   `yaml:"listing,omitempty"` // List the files of directories without an index file
+}
+
+// UnmarshalYAML accepts either the directory or a mapping with the settings
+func (s *StaticDirConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var root string
+	if err := unmarshal(&root); err == nil {
+		*s = StaticDirConfig{Root: root}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings StaticDirConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("static_dir must be a directory or a mapping with root, index and listing: %w", err)
+	}
+	*s = StaticDirConfig(decoded)
+	return nil
+}
+
+// GetIndex returns the file served for directories, with the default applied
+func (s *StaticDirConfig) GetIndex() string {
+	if s.Index == "" {
+		return DefaultStaticIndex
+	}
+	return s.Index
+}
+
+// validateStaticDir validates a route serving a directory, which answers
+// with its files instead of templates
+func (r *RouteConfig) validateStaticDir() error {
+	if strings.TrimSpace(r.Template) != "" || strings.TrimSpace(r.TemplateFile) != "" {
+		return NewValidationError("static_dir", "'static_dir' cannot be combined with 'template' or 'template_file'")
+	}
+	if len(r.Responses) > 0 || r.Variants != nil || r.Stream != nil {
+		return NewValidationError("static_dir", "'static_dir' cannot be combined with 'responses', 'variants' or 'stream'")
+	}
+	if len(r.Faults) > 0 || r.Compression != nil {
+		return NewValidationError("static_dir", "'static_dir' cannot be combined with 'faults' or 'compression'")
+	}
+	if r.UsesPathRegex() {
+		return NewValidationError("static_dir", "'static_dir' routes need a literal path to serve files under, like /assets, not a /regex/, a path template or a prefix")
+	}
+	if method := r.GetNormalizedMethod(); method != "GET" {
+		return NewValidationError("static_dir", fmt.Sprintf("'static_dir' routes only serve GET requests, got method %q", method))
+	}
+
+	return r.StaticDir.Validate()
+}
+
+// Validate validates a StaticDirConfig
+func (s *StaticDirConfig) Validate() error {
+	if strings.TrimSpace(s.Root) == "" {
+		return NewValidationError("static_dir.root", "directory cannot be empty")
+	}
+
+	info, err := os.Stat(s.Root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewValidationError("static_dir.root", fmt.Sprintf("directory %q does not exist", s.Root))
+		}
+		return NewValidationError("static_dir.root", fmt.Sprintf("cannot access directory %q: %v", s.Root, err))
+	}
+	if !info.IsDir() {
+		return NewValidationError("static_dir.root", fmt.Sprintf("%q is not a directory", s.Root))
+	}
+
+	if s.Index != "" && (s.Index != filepath.Base(s.Index) || s.Index == "." || s.Index == "..") {
+		return NewValidationError("static_dir.index", fmt.Sprintf("index %q must be a file name, without directories", s.Index))
+	}
+	return nil
+}
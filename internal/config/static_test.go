@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestStaticDirConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        StaticDirConfig
+		errContains string
+	}{
+		{
+			name: "directory",
+			yaml: `static_dir: ./assets`,
+			want: StaticDirConfig{Root: "./assets"},
+		},
+		{
+			name: "settings",
+			yaml: "static_dir:\n  root: ./downloads\n  index: default.htm\n  listing: true",
+			want: StaticDirConfig{Root: "./downloads", Index: "default.htm", Listing: true},
+		},
+		{
+			name:        "sequence",
+			yaml:        `static_dir: [./assets]`,
+			errContains: "static_dir must be a directory or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.StaticDir == nil || !reflect.DeepEqual(*route.StaticDir, tt.want) {
+				t.Errorf("Expected static_dir %+v, got %+v", tt.want, route.StaticDir)
+			}
+		})
+	}
+}
+
+func TestStaticDirConfig_GetIndex(t *testing.T) {
+	if got := (&StaticDirConfig{}).GetIndex(); got != DefaultStaticIndex {
+		t.Errorf("Expected default index %q, got %q", DefaultStaticIndex, got)
+	}
+	if got := (&StaticDirConfig{Index: "default.htm"}).GetIndex(); got != "default.htm" {
+		t.Errorf("Expected index %q, got %q", "default.htm", got)
+	}
+}
+
+func TestRouteConfig_ValidateStaticDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "sdk.js")
+	if err := os.WriteFile(file, []byte("export {}"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "valid",
+			route: RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: dir}},
+		},
+		{
+			name:  "with settings and response headers",
+			route: RouteConfig{Path: "/", Method: "get", StaticDir: &StaticDirConfig{Root: dir, Index: "default.htm", Listing: true}, ResponseHeaders: map[string]string{"Cache-Control": "max-age=60"}},
+		},
+		{
+			name:        "empty root",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{}},
+			errContains: "directory cannot be empty",
+		},
+		{
+			name:        "missing root",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: filepath.Join(dir, "missing")}},
+			errContains: "does not exist",
+		},
+		{
+			name:        "root is a file",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: file}},
+			errContains: "is not a directory",
+		},
+		{
+			name:        "index with directories",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: dir, Index: "../index.html"}},
+			errContains: "must be a file name",
+		},
+		{
+			name:        "regex path",
+			route:       RouteConfig{Path: "/assets/", Method: "GET", StaticDir: &StaticDirConfig{Root: dir}},
+			errContains: "need a literal path",
+		},
+		{
+			name:        "not GET",
+			route:       RouteConfig{Path: "/assets", Method: "POST", StaticDir: &StaticDirConfig{Root: dir}},
+			errContains: "only serve GET requests",
+		},
+		{
+			name:        "with template",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: dir}, Template: "assets"},
+			errContains: "'static_dir' cannot be combined with 'template' or 'template_file'",
+		},
+		{
+			name:        "with stream",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: dir}, Stream: &StreamConfig{}},
+			errContains: "'static_dir' cannot be combined with 'responses', 'variants' or 'stream'",
+		},
+		{
+			name:        "with compression",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: dir}, Compression: &CompressionConfig{}},
+			errContains: "'static_dir' cannot be combined with 'faults' or 'compression'",
+		},
+		{
+			name:        "with body_file",
+			route:       RouteConfig{Path: "/assets", Method: "GET", StaticDir: &StaticDirConfig{Root: dir}, BodyFile: file},
+			errContains: "'static_dir' cannot be combined with 'proxy', 'batch', 'websocket', 'echo_body' or 'body_file'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
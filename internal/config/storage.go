@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+// StorageConfig selects where captured data, like the request journal and the
+// state of sequenced routes, is kept
+type StorageConfig struct {
+	Driver string `yaml:"driver,omitempty"` // Storage driver name (default: "memory")
+	Path   string `yaml:"path,omitempty"`   // Where the driver keeps its data: a directory for "file", a database file for "sqlite"
+}
+
+// GetWithDefaults returns storage settings with sensible defaults
+func (sc *StorageConfig) GetWithDefaults() StorageConfig {
+	config := *sc
+
+	// Apply default values if not set
+	if config.Driver == "" {
+		config.Driver = storage.MemoryDriver
+	}
+
+	return config
+}
+
+// Validate validates the storage configuration
+func (sc *StorageConfig) Validate() error {
+	config := sc.GetWithDefaults()
+
+	drivers := storage.Drivers()
+	if !slices.Contains(drivers, config.Driver) {
+		return NewValidationError("storage.driver", fmt.Sprintf("unknown storage driver %q, available drivers: %s", config.Driver, strings.Join(drivers, ", ")))
+	}
+
+	hasPath := strings.TrimSpace(config.Path) != ""
+	if config.Driver == storage.MemoryDriver && hasPath {
+		return NewValidationError("storage.path", fmt.Sprintf("the %q driver doesn't use a 'path'", storage.MemoryDriver))
+	}
+	if config.Driver != storage.MemoryDriver && !hasPath {
+		return NewValidationError("storage.path", fmt.Sprintf("the %q driver requires a 'path'", config.Driver))
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStorageConfig_GetWithDefaults(t *testing.T) {
+	if defaults := (&StorageConfig{}).GetWithDefaults(); defaults.Driver != "memory" {
+		t.Errorf("Expected the memory driver by default, got %+v", defaults)
+	}
+
+	custom := (&StorageConfig{Driver: "file", Path: "data"}).GetWithDefaults()
+	if custom.Driver != "file" || custom.Path != "data" {
+		t.Errorf("Expected configured values to be kept, got %+v", custom)
+	}
+}
+
+func TestStorageConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		storage     StorageConfig
+		errContains string
+	}{
+		{name: "empty", storage: StorageConfig{}},
+		{name: "memory", storage: StorageConfig{Driver: "memory"}},
+		{name: "file", storage: StorageConfig{Driver: "file", Path: "./data"}},
+		{name: "sqlite", storage: StorageConfig{Driver: "sqlite", Path: "./mockingjay.db"}},
+		{name: "unknown driver", storage: StorageConfig{Driver: "redis", Path: "localhost:6379"}, errContains: `unknown storage driver "redis", available drivers: file, memory, sqlite`},
+		{name: "file without path", storage: StorageConfig{Driver: "file"}, errContains: "requires a 'path'"},
+		{name: "memory with path", storage: StorageConfig{Path: "./data"}, errContains: "doesn't use a 'path'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.storage.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default stream settings
+const (
+	DefaultStreamBufferSize    = 32 * 1024
+	DefaultStreamFlushInterval = 100 * time.Millisecond
+)
+
+// StreamConfig makes a route write its template output to the client as it's
+// rendered, instead of buffering the whole response, so large generated
+// payloads don't have to fit in memory. In YAML it is either true, for the
+// defaults, or a mapping.
+type StreamConfig struct {
+	BufferSize    int           `yaml:"buffer_size,omitempty"`    // Bytes held back before the response starts (default: 32768)
+	FlushInterval time.Duration `yaml:"flush_interval,omitempty"` // How often output is flushed to the client (default: 100ms)
+}
+
+// UnmarshalYAML accepts either a boolean or a mapping with the settings
+func (s *StreamConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("stream can't be false, remove it instead")
+		}
+		*s = StreamConfig{}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings StreamConfig
+	var decoded settings
+	if err := unmarshal(&decoded); err != nil {
+		return fmt.Errorf("stream must be true or a mapping with buffer_size and flush_interval: %w", err)
+	}
+	*s = StreamConfig(decoded)
+	return nil
+}
+
+// validateStream validates the stream settings of a route
+func (r *RouteConfig) validateStream() error {
+	if r.Stream == nil {
+		return nil
+	}
+
+	if r.Proxy != nil || r.Batch != nil || r.WebSocket != nil {
+		return NewValidationError("stream", "'stream' cannot be combined with 'proxy', 'batch' or 'websocket'")
+	}
+
+	if len(r.Faults) > 0 || r.Compression != nil {
+		return NewValidationError("stream", "'stream' cannot be combined with 'faults' or 'compression', which need the whole body")
+	}
+
+	return r.Stream.Validate()
+}
+
+// Validate validates a StreamConfig
+func (s *StreamConfig) Validate() error {
+	if s.BufferSize < 0 {
+		return NewValidationError("stream.buffer_size", fmt.Sprintf("buffer size cannot be negative, got %d", s.BufferSize))
+	}
+	if s.FlushInterval < 0 {
+		return NewValidationError("stream.flush_interval", fmt.Sprintf("flush interval cannot be negative, got %s", s.FlushInterval))
+	}
+	return nil
+}
+
+// GetBufferSize returns how many bytes are held back before the response
+// starts, using the default when unset
+func (s *StreamConfig) GetBufferSize() int {
+	if s.BufferSize == 0 {
+		return DefaultStreamBufferSize
+	}
+	return s.BufferSize
+}
+
+// GetFlushInterval returns how often output is flushed, using the default
+// when unset
+func (s *StreamConfig) GetFlushInterval() time.Duration {
+	if s.FlushInterval == 0 {
+		return DefaultStreamFlushInterval
+	}
+	return s.FlushInterval
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestStreamConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        StreamConfig
+		errContains string
+	}{
+		{
+			name: "enabled",
+			yaml: `stream: true`,
+			want: StreamConfig{},
+		},
+		{
+			name: "settings",
+			yaml: "stream:\n  buffer_size: 1024\n  flush_interval: 1s",
+			want: StreamConfig{BufferSize: 1024, FlushInterval: time.Second},
+		},
+		{
+			name:        "disabled",
+			yaml:        `stream: false`,
+			errContains: "can't be false",
+		},
+		{
+			name:        "sequence",
+			yaml:        `stream: [1024]`,
+			errContains: "stream must be true or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Stream == nil || *route.Stream != tt.want {
+				t.Errorf("Expected stream %+v, got %+v", tt.want, route.Stream)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "defaults - valid",
+			route: RouteConfig{Path: "/export.csv", Method: "GET", Template: "id", Stream: &StreamConfig{}},
+		},
+		{
+			name:  "with responses - valid",
+			route: RouteConfig{Path: "/export.csv", Method: "GET", Responses: []ResponseConfig{{Template: "id"}}, Stream: &StreamConfig{BufferSize: 1}},
+		},
+		{
+			name:        "with faults - invalid",
+			route:       RouteConfig{Path: "/export.csv", Method: "GET", Template: "id", Stream: &StreamConfig{}, Faults: []FaultConfig{{Type: "error"}}},
+			errContains: "cannot be combined with 'faults'",
+		},
+		{
+			name:        "with compression - invalid",
+			route:       RouteConfig{Path: "/export.csv", Method: "GET", Template: "id", Stream: &StreamConfig{}, Compression: &CompressionConfig{}},
+			errContains: "'compression'",
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/export.csv", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, Stream: &StreamConfig{}},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "negative buffer size - invalid",
+			route:       RouteConfig{Path: "/export.csv", Method: "GET", Template: "id", Stream: &StreamConfig{BufferSize: -1}},
+			errContains: "buffer size cannot be negative",
+		},
+		{
+			name:        "negative flush interval - invalid",
+			route:       RouteConfig{Path: "/export.csv", Method: "GET", Template: "id", Stream: &StreamConfig{FlushInterval: -time.Second}},
+			errContains: "flush interval cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestStreamConfig_Defaults(t *testing.T) {
+	s := &StreamConfig{}
+	if got := s.GetBufferSize(); got != DefaultStreamBufferSize {
+		t.Errorf("Expected default buffer size %d, got %d", DefaultStreamBufferSize, got)
+	}
+	if got := s.GetFlushInterval(); got != DefaultStreamFlushInterval {
+		t.Errorf("Expected default flush interval %s, got %s", DefaultStreamFlushInterval, got)
+	}
+
+	s = &StreamConfig{BufferSize: 10, FlushInterval: time.Second}
+	if s.GetBufferSize() != 10 || s.GetFlushInterval() != time.Second {
+		t.Errorf("Expected the configured settings, got %d and %s", s.GetBufferSize(), s.GetFlushInterval())
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// Strict HTTP modes, deciding what happens to generated responses breaking
+// basic HTTP rules
+const (
+	StrictHTTPFix    = "fix"    // Repair the response, e.g. by dropping the body of a 204
+	StrictHTTPReject = "reject" // Refuse the configuration, or fail the request with a 500
+)
+
+// StatusForbidsBody reports whether responses with the given status must not
+// have a body
+func StatusForbidsBody(status int) bool {
+	return (status >= 100 && status < 200) || status == http.StatusNoContent || status == http.StatusNotModified
+}
+
+// validateStrictHTTP validates the strict HTTP mode and, when rejecting
+// violations, checks the statuses and headers routes declare up front
+func (c *Config) validateStrictHTTP() error {
+	switch c.Server.StrictHTTP {
+	case "", StrictHTTPFix:
+		return nil
+	case StrictHTTPReject:
+	default:
+		return NewValidationError("server.strict_http", fmt.Sprintf("invalid mode %q, must be one of: %s, %s", c.Server.StrictHTTP, StrictHTTPFix, StrictHTTPReject))
+	}
+
+	engine, err := c.Template.NewEngine()
+	if err != nil {
+		return err
+	}
+
+	for i, route := range c.Routes {
+		for j, resp := range route.Responses {
+			if err := checkStrictResponse(engine, route, resp); err != nil {
+				return fmt.Errorf("route[%d] responses[%d]: %w", i, j, err)
+			}
+		}
+		if route.Variants != nil {
+			for _, name := range route.Variants.Names() {
+				if err := checkStrictResponse(engine, route, route.Variants.Cases[name]); err != nil {
+					return fmt.Errorf("route[%d] variants.cases[%s]: %w", i, name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkStrictResponse checks a route's alternative response against basic
+// HTTP rules
+func checkStrictResponse(engine *templatepkg.Engine, route RouteConfig, resp ResponseConfig) error {
+	status := resp.Status
+	switch {
+	case status >= 100 && status < 200:
+		return NewValidationError("status", fmt.Sprintf("status %d is informational and can't be used as a final response", status))
+
+	case status == http.StatusUnauthorized && !hasResponseHeader("WWW-Authenticate", route.ResponseHeaders, resp.ResponseHeaders):
+		return NewValidationError("response_headers", "status 401 responses must include a WWW-Authenticate header")
+
+	case status == http.StatusMethodNotAllowed && !hasResponseHeader("Allow", route.ResponseHeaders, resp.ResponseHeaders):
+		return NewValidationError("response_headers", "status 405 responses must include an Allow header")
+
+	case StatusForbidsBody(status):
+		var tmpl *template.Template
+		var err error
+		if resp.Template != "" {
+			tmpl, err = engine.CompileInlineTemplate("strict_http", resp.Template)
+		} else {
+			tmpl, err = engine.CompileFileTemplate(resp.TemplateFile)
+		}
+		if err != nil {
+			return nil // Reported by template validation
+		}
+		if tmpl.Tree != nil && hasStaticText(tmpl.Tree.Root) {
+			return NewValidationError("template", fmt.Sprintf("status %d responses must not have a body", status))
+		}
+	}
+
+	return nil
+}
+
+// hasResponseHeader reports whether any of the header sets declares the named
+// header, ignoring case
+func hasResponseHeader(name string, headerSets ...map[string]string) bool {
+	for _, headers := range headerSets {
+		for key := range headers {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasStaticText reports whether a template tree contains literal text other
+// than whitespace, which would end up in the response body. Text produced by
+// actions can only be checked once the template runs.
+func hasStaticText(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.TextNode:
+		return strings.TrimSpace(string(n.Text)) != ""
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if hasStaticText(child) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return hasStaticText(n.List) || hasStaticText(n.ElseList)
+	case *parse.RangeNode:
+		return hasStaticText(n.List) || hasStaticText(n.ElseList)
+	case *parse.WithNode:
+		return hasStaticText(n.List) || hasStaticText(n.ElseList)
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatusForbidsBody(t *testing.T) {
+	for status, expected := range map[int]bool{100: true, 103: true, 200: false, 204: true, 304: true, 401: false, 500: false} {
+		if got := StatusForbidsBody(status); got != expected {
+			t.Errorf("StatusForbidsBody(%d) = %v, expected %v", status, got, expected)
+		}
+	}
+}
+
+func TestConfig_ValidateStrictHTTP(t *testing.T) {
+	dir := t.TempDir()
+	bodyFile := filepath.Join(dir, "body.tmpl")
+	if err := os.WriteFile(bodyFile, []byte(`{"deleted": true}`), 0o644); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	route := func(responses ...ResponseConfig) RouteConfig {
+		return RouteConfig{Path: "/items", Method: "DELETE", Responses: responses}
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		route       RouteConfig
+		errContains string
+	}{
+		{name: "off allows anything", route: route(ResponseConfig{Status: 204, Template: "gone"})},
+		{name: "fix allows anything", mode: StrictHTTPFix, route: route(ResponseConfig{Status: 204, Template: "gone"})},
+		{name: "invalid mode", mode: "loose", route: route(ResponseConfig{Template: "ok"}), errContains: `invalid mode "loose"`},
+		{name: "empty 204", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 204, Template: "{{/* nothing */}}\n"})},
+		{name: "204 with body", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 204, Template: "gone"}), errContains: "status 204 responses must not have a body"},
+		{
+			name:        "304 with conditional body",
+			mode:        StrictHTTPReject,
+			route:       route(ResponseConfig{Status: 304, Template: `{{ if .Query.verbose }}not modified{{ end }}`}),
+			errContains: "status 304 responses must not have a body",
+		},
+		{name: "204 with file body", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 204, TemplateFile: bodyFile}), errContains: "must not have a body"},
+		{name: "informational status", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 102, Template: " "}), errContains: "status 102 is informational"},
+		{name: "401 without challenge", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 401, Template: "no"}), errContains: "WWW-Authenticate"},
+		{
+			name:  "401 with route challenge",
+			mode:  StrictHTTPReject,
+			route: RouteConfig{Path: "/items", Method: "GET", ResponseHeaders: map[string]string{"www-authenticate": "Basic"}, Responses: []ResponseConfig{{Status: 401, Template: "no"}}},
+		},
+		{name: "405 without allow", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 405, Template: "no"}), errContains: "route[0] responses[0]"},
+		{
+			name:        "variant without challenge",
+			mode:        StrictHTTPReject,
+			route:       RouteConfig{Path: "/items", Method: "GET", Variants: &VariantsConfig{Selector: "{{ .Query.v }}", Cases: map[string]ResponseConfig{"denied": {Status: 401, Template: "no"}}, Default: "denied"}},
+			errContains: "route[0] variants.cases[denied]",
+		},
+		{name: "405 with allow", mode: StrictHTTPReject, route: route(ResponseConfig{Status: 405, Template: "no", ResponseHeaders: map[string]string{"Allow": "GET"}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{StrictHTTP: tt.mode}, Routes: []RouteConfig{tt.route}}
+			err := cfg.validateStrictHTTP()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
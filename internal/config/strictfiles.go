@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+// CheckFiles checks that the configuration loaded from paths and the files
+// next to it agree: every file in the configuration tree, the directories of
+// the configuration paths, must be used by the configuration, and every
+// template and body file must live inside that tree. Hidden files and
+// directories, YAML files and the contents of static directories are left
+// alone. All problems are reported at once.
+func (c *Config) CheckFiles(paths []string) error {
+	var roots []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return NewLoadError(path, err)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			abs = filepath.Dir(abs)
+		}
+		roots = append(roots, abs)
+	}
+
+	inTree := func(file string) bool {
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, file); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var problems []string
+
+	// Files referenced from outside the tree may be stale copies living
+	// elsewhere on the machine, and won't be there when the tree is shared
+	seen := make(map[string]bool)
+	for _, file := range c.templateFiles() {
+		abs, err := filepath.Abs(file)
+		if err != nil || seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		if !inTree(abs) {
+			problems = append(problems, fmt.Sprintf("%q is referenced but outside the configuration tree", file))
+		}
+	}
+
+	used := make(map[string]bool)
+	var skipDirs []string
+	use := func(file string) {
+		if abs, err := filepath.Abs(file); file != "" && err == nil {
+			used[abs] = true
+		}
+	}
+	skip := func(dir string) {
+		if abs, err := filepath.Abs(dir); dir != "" && err == nil {
+			skipDirs = append(skipDirs, abs)
+		}
+	}
+
+	for _, file := range c.WatchFiles() {
+		use(file)
+	}
+	for _, route := range c.Routes {
+		if route.StaticDir != nil {
+			skip(route.StaticDir.Root)
+		}
+	}
+	if c.Server.TLS != nil {
+		use(c.Server.TLS.CertFile)
+		use(c.Server.TLS.KeyFile)
+		use(c.Server.TLS.ClientCAFile)
+	}
+	use(c.Journal.Path)
+	if c.Storage.Driver == storage.SQLiteDriver {
+		// The database, along with the files SQLite keeps next to it
+		for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+			use(c.Storage.Path + suffix)
+		}
+	} else {
+		skip(c.Storage.Path)
+	}
+
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if path != root && (strings.HasPrefix(d.Name(), ".") || slices.Contains(skipDirs, path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !used[path] && !IsConfigFile(path) && !strings.HasPrefix(d.Name(), ".") {
+				problems = append(problems, fmt.Sprintf("%q isn't referenced by the configuration", path))
+				used[path] = true // Report files under overlapping roots once
+			}
+			return nil
+		})
+		if err != nil {
+			return NewLoadError(root, fmt.Errorf("failed to read config directory %q: %w", root, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("strict files check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig_CheckFiles(t *testing.T) {
+	t.Run("every file is used", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"main.yaml": `routes:
+  - path: /users
+    method: GET
+    template_file: templates/users.tmpl
+  - path: /assets
+    method: GET
+    static_dir: public
+storage:
+  driver: sqlite
+  path: ` + filepath.Join(dir, "data", "mocks.db"),
+			"other.yaml":           "routes: []",
+			"data/mocks.db":        "sqlite",
+			"data/mocks.db-wal":    "wal",
+			"templates/users.tmpl": "users",
+			"public/app.js":        "app",
+			".git/HEAD":            "ref",
+			".notes":               "todo",
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		if err := cfg.CheckFiles([]string{filepath.Join(dir, "main.yaml")}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("relative body file and static directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"conf/main.yaml": `routes:
+  - path: /avatar.png
+    method: GET
+    body_file: fixtures/avatar.png
+  - path: /assets
+    method: GET
+    static_dir: public`,
+			"conf/fixtures/avatar.png": "png",
+			"conf/public/app.js":       "app",
+		})
+		t.Chdir(t.TempDir())
+
+		cfg, err := LoadConfig(filepath.Join(dir, "conf", "main.yaml"))
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		if err := cfg.CheckFiles([]string{filepath.Join(dir, "conf", "main.yaml")}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unused and outside files", func(t *testing.T) {
+		dir := t.TempDir()
+		outside := filepath.Join(t.TempDir(), "stale.tmpl")
+		writeConfigFiles(t, filepath.Dir(outside), map[string]string{"stale.tmpl": "stale"})
+		writeConfigFiles(t, dir, map[string]string{
+			"routes/users.yaml": `routes:
+  - path: /users
+    method: GET
+    template_file: ../templates/users.tmpl
+  - path: /orders
+    method: GET
+    template_file: ` + outside,
+			"templates/users.tmpl":  "users",
+			"templates/orders.tmpl": "orders",
+		})
+
+		cfg, err := LoadConfig(dir)
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+
+		err = cfg.CheckFiles([]string{dir})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		for _, want := range []string{
+			"strict files check failed",
+			`"` + outside + `" is referenced but outside the configuration tree`,
+			`"` + filepath.Join(dir, "templates", "orders.tmpl") + `" isn't referenced by the configuration`,
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error containing %q, got %v", want, err)
+			}
+		}
+		if strings.Contains(err.Error(), "users.tmpl") {
+			t.Errorf("Expected users.tmpl to be used, got %v", err)
+		}
+	})
+
+	t.Run("file outside a single config file's directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, map[string]string{
+			"mocks/main.yaml": `routes:
+  - path: /users
+    method: GET
+    template_file: ../users.tmpl`,
+			"users.tmpl": "users",
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "mocks", "main.yaml"))
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+
+		err = cfg.CheckFiles([]string{filepath.Join(dir, "mocks", "main.yaml")})
+		if err == nil || !strings.Contains(err.Error(), "users.tmpl\" is referenced but outside the configuration tree") {
+			t.Errorf("Expected an outside file error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TenantConfig represents an independent set of mocks hosted by the same process.
+// Each tenant has its own configuration file and is addressed either by a path
+// prefix on the main listener or by a dedicated port.
+type TenantConfig struct {
+	Name   string `yaml:"name"`             // Unique tenant name used in logs
+	Prefix string `yaml:"prefix,omitempty"` // Path prefix routed to this tenant (e.g. "/payments")
+	Port   string `yaml:"port,omitempty"`   // Dedicated port this tenant listens on
+	Config string `yaml:"config"`           // Path to the tenant's configuration file
+
+	// Loaded holds the tenant's parsed and validated configuration
+	Loaded *Config `yaml:"-"`
+}
+
+// validateTenants validates the tenant definitions of a configuration
+func (c *Config) validateTenants() error {
+	names := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	ports := make(map[string]bool)
+
+	for i, tenant := range c.Tenants {
+		if err := tenant.Validate(); err != nil {
+			return fmt.Errorf("tenants[%d]: %w", i, err)
+		}
+
+		if names[tenant.Name] {
+			return fmt.Errorf("tenants[%d]: %w", i, NewValidationError("name", fmt.Sprintf("duplicate tenant name %q", tenant.Name)))
+		}
+		names[tenant.Name] = true
+
+		if tenant.Prefix != "" {
+			if prefixes[tenant.Prefix] {
+				return fmt.Errorf("tenants[%d]: %w", i, NewValidationError("prefix", fmt.Sprintf("duplicate tenant prefix %q", tenant.Prefix)))
+			}
+			prefixes[tenant.Prefix] = true
+		}
+
+		if tenant.Port != "" {
+			if ports[tenant.Port] {
+				return fmt.Errorf("tenants[%d]: %w", i, NewValidationError("port", fmt.Sprintf("duplicate tenant port %q", tenant.Port)))
+			}
+			ports[tenant.Port] = true
+		}
+	}
+
+	return nil
+}
+
+// Validate validates a single TenantConfig
+func (t *TenantConfig) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return NewValidationError("name", "tenant name cannot be empty")
+	}
+
+	if strings.TrimSpace(t.Config) == "" {
+		return NewValidationError("config", fmt.Sprintf("tenant %q must specify a config file", t.Name))
+	}
+
+	hasPrefix := t.Prefix != ""
+	hasPort := t.Port != ""
+
+	if hasPrefix == hasPort {
+		return NewValidationError("prefix", fmt.Sprintf("tenant %q must specify exactly one of 'prefix' or 'port'", t.Name))
+	}
+
+	if hasPrefix {
+		if !strings.HasPrefix(t.Prefix, "/") || t.Prefix == "/" || strings.HasSuffix(t.Prefix, "/") {
+			return NewValidationError("prefix", fmt.Sprintf("tenant prefix %q must start with '/', not end with '/', and cannot be the root path", t.Prefix))
+		}
+	}
+
+	return nil
+}
+
+// loadTenants loads and validates the configuration file of every tenant
+func (c *Config) loadTenants() error {
+	for i := range c.Tenants {
+		tenant := &c.Tenants[i]
+
+		loaded, err := LoadConfig(tenant.Config)
+		if err != nil {
+			return fmt.Errorf("tenant %q: %w", tenant.Name, err)
+		}
+
+		if len(loaded.Tenants) > 0 {
+			return fmt.Errorf("tenant %q: %w", tenant.Name, NewValidationError("tenants", "tenant configurations cannot define nested tenants"))
+		}
+
+		tenant.Loaded = loaded
+	}
+
+	return nil
+}
+
+// MatchPrefix reports whether the given path belongs to this tenant's prefix
+func (t *TenantConfig) MatchPrefix(path string) bool {
+	if t.Prefix == "" {
+		return false
+	}
+	return path == t.Prefix || strings.HasPrefix(path, t.Prefix+"/")
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTenantConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenant  TenantConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid prefix tenant",
+			tenant:  TenantConfig{Name: "payments", Prefix: "/payments", Config: "payments.yaml"},
+			wantErr: false,
+		},
+		{
+			name:    "valid port tenant",
+			tenant:  TenantConfig{Name: "search", Port: "9090", Config: "search.yaml"},
+			wantErr: false,
+		},
+		{
+			name:    "missing name",
+			tenant:  TenantConfig{Prefix: "/payments", Config: "payments.yaml"},
+			wantErr: true,
+			errMsg:  "tenant name cannot be empty",
+		},
+		{
+			name:    "missing config",
+			tenant:  TenantConfig{Name: "payments", Prefix: "/payments"},
+			wantErr: true,
+			errMsg:  "must specify a config file",
+		},
+		{
+			name:    "neither prefix nor port",
+			tenant:  TenantConfig{Name: "payments", Config: "payments.yaml"},
+			wantErr: true,
+			errMsg:  "exactly one of 'prefix' or 'port'",
+		},
+		{
+			name:    "both prefix and port",
+			tenant:  TenantConfig{Name: "payments", Prefix: "/payments", Port: "9090", Config: "payments.yaml"},
+			wantErr: true,
+			errMsg:  "exactly one of 'prefix' or 'port'",
+		},
+		{
+			name:    "root prefix",
+			tenant:  TenantConfig{Name: "payments", Prefix: "/", Config: "payments.yaml"},
+			wantErr: true,
+			errMsg:  "cannot be the root path",
+		},
+		{
+			name:    "trailing slash prefix",
+			tenant:  TenantConfig{Name: "payments", Prefix: "/payments/", Config: "payments.yaml"},
+			wantErr: true,
+			errMsg:  "must start with '/'",
+		},
+		{
+			name:    "relative prefix",
+			tenant:  TenantConfig{Name: "payments", Prefix: "payments", Config: "payments.yaml"},
+			wantErr: true,
+			errMsg:  "must start with '/'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tenant.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestTenantConfig_MatchPrefix(t *testing.T) {
+	tenant := TenantConfig{Name: "payments", Prefix: "/payments"}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/payments", true},
+		{"/payments/", true},
+		{"/payments/charges/1", true},
+		{"/paymentsx", false},
+		{"/other", false},
+		{"/", false},
+	}
+
+	for _, tt := range tests {
+		if got := tenant.MatchPrefix(tt.path); got != tt.expected {
+			t.Errorf("MatchPrefix(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestLoadConfig_Tenants(t *testing.T) {
+	tenantFile := createTempFile(t, `routes:
+  - path: "/charges"
+    method: GET
+    template: "charges"`)
+	defer os.Remove(tenantFile)
+
+	t.Run("tenants without root routes", func(t *testing.T) {
+		rootFile := createTempFile(t, `tenants:
+  - name: payments
+    prefix: /payments
+    config: "`+tenantFile+`"`)
+		defer os.Remove(rootFile)
+
+		cfg, err := LoadConfig(rootFile)
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+
+		if len(cfg.Tenants) != 1 {
+			t.Fatalf("Expected 1 tenant, got %d", len(cfg.Tenants))
+		}
+		if cfg.Tenants[0].Loaded == nil {
+			t.Fatal("Expected tenant configuration to be loaded")
+		}
+		if len(cfg.Tenants[0].Loaded.Routes) != 1 {
+			t.Errorf("Expected tenant to have 1 route, got %d", len(cfg.Tenants[0].Loaded.Routes))
+		}
+	})
+
+	t.Run("duplicate tenant names", func(t *testing.T) {
+		rootFile := createTempFile(t, `tenants:
+  - name: payments
+    prefix: /payments
+    config: "`+tenantFile+`"
+  - name: payments
+    prefix: /billing
+    config: "`+tenantFile+`"`)
+		defer os.Remove(rootFile)
+
+		_, err := LoadConfig(rootFile)
+		if err == nil || !strings.Contains(err.Error(), "duplicate tenant name") {
+			t.Errorf("Expected duplicate tenant name error, got %v", err)
+		}
+	})
+
+	t.Run("missing tenant config file", func(t *testing.T) {
+		rootFile := createTempFile(t, `tenants:
+  - name: payments
+    prefix: /payments
+    config: "/nonexistent/payments.yaml"`)
+		defer os.Remove(rootFile)
+
+		_, err := LoadConfig(rootFile)
+		if err == nil || !strings.Contains(err.Error(), `tenant "payments"`) {
+			t.Errorf("Expected tenant load error, got %v", err)
+		}
+	})
+
+	t.Run("nested tenants rejected", func(t *testing.T) {
+		nestedFile := createTempFile(t, `tenants:
+  - name: inner
+    prefix: /inner
+    config: "`+tenantFile+`"`)
+		defer os.Remove(nestedFile)
+
+		rootFile := createTempFile(t, `tenants:
+  - name: outer
+    prefix: /outer
+    config: "`+nestedFile+`"`)
+		defer os.Remove(rootFile)
+
+		_, err := LoadConfig(rootFile)
+		if err == nil || !strings.Contains(err.Error(), "nested tenants") {
+			t.Errorf("Expected nested tenants error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// How the server asks clients for certificates
+const (
+	ClientAuthNone    = "none"    // Don't ask for client certificates (default)
+	ClientAuthRequest = "request" // Ask for a client certificate, but serve clients without one
+	ClientAuthRequire = "require" // Refuse the handshake of clients without a certificate
+)
+
+// TLSConfig makes the server listen with TLS instead of plain HTTP, and
+// optionally ask clients for certificates to mock services enforcing mutual TLS
+type TLSConfig struct {
+	CertFile     string `yaml:"cert_file"`                // PEM certificate of the server
+	KeyFile      string `yaml:"key_file"`                 // PEM private key of the server
+	ClientCAFile string `yaml:"client_ca_file,omitempty"` // PEM CAs client certificates must be signed by, unverified when unset
+	ClientAuth   string `yaml:"client_auth,omitempty"`    // "none" (default), "request" or "require"
+}
+
+// ClientCertMatchConfig matches requests by the TLS client certificate they
+// were sent with. Values are literals, or regexes when wrapped in slashes.
+type ClientCertMatchConfig struct {
+	CN  string `yaml:"cn,omitempty"`  // Common name of the certificate subject
+	SAN string `yaml:"san,omitempty"` // Any DNS, email, IP or URI subject alternative name
+}
+
+// GetClientAuth returns how the server asks clients for certificates, using
+// the default when unset
+func (tc *TLSConfig) GetClientAuth() string {
+	if tc.ClientAuth == "" {
+		return ClientAuthNone
+	}
+	return tc.ClientAuth
+}
+
+// Validate validates the TLS settings, loading the certificates they refer to
+func (tc *TLSConfig) Validate() error {
+	if strings.TrimSpace(tc.CertFile) == "" || strings.TrimSpace(tc.KeyFile) == "" {
+		return NewValidationError("server.tls", "both 'cert_file' and 'key_file' must be specified")
+	}
+
+	if _, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile); err != nil {
+		return NewValidationError("server.tls.cert_file", fmt.Sprintf("failed to load certificate: %v", err))
+	}
+
+	switch tc.GetClientAuth() {
+	case ClientAuthNone, ClientAuthRequest, ClientAuthRequire:
+	default:
+		return NewValidationError("server.tls.client_auth", fmt.Sprintf("unknown client auth %q, must be one of: %s, %s, %s", tc.ClientAuth, ClientAuthNone, ClientAuthRequest, ClientAuthRequire))
+	}
+
+	if tc.ClientCAFile != "" {
+		if _, err := tc.LoadClientCAs(); err != nil {
+			return NewValidationError("server.tls.client_ca_file", err.Error())
+		}
+		if tc.GetClientAuth() == ClientAuthNone {
+			return NewValidationError("server.tls.client_ca_file", "'client_ca_file' requires 'client_auth' to be \"request\" or \"require\"")
+		}
+	}
+
+	return nil
+}
+
+// LoadClientCAs loads the CAs client certificates must be signed by
+func (tc *TLSConfig) LoadClientCAs() (*x509.CertPool, error) {
+	data, err := os.ReadFile(tc.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CAs: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %q", tc.ClientCAFile)
+	}
+	return pool, nil
+}
+
+// Validate validates a ClientCertMatchConfig
+func (cm *ClientCertMatchConfig) Validate() error {
+	if cm.CN == "" && cm.SAN == "" {
+		return NewValidationError("match_client_cert", "at least one of 'cn' or 'san' must be specified")
+	}
+
+	for field, value := range map[string]string{"cn": cm.CN, "san": cm.SAN} {
+		if !isRegexPattern(value) {
+			continue
+		}
+		if _, err := regexp.Compile(extractRegexPattern(value)); err != nil {
+			return NewValidationError("match_client_cert."+field, fmt.Sprintf("invalid regex pattern %q: %v", extractRegexPattern(value), err))
+		}
+	}
+
+	return nil
+}
+
+// validateMatchSNI validates the server name pattern a route matches
+func (r *RouteConfig) validateMatchSNI() error {
+	if !isRegexPattern(r.MatchSNI) {
+		return nil
+	}
+	if _, err := regexp.Compile(extractRegexPattern(r.MatchSNI)); err != nil {
+		return NewValidationError("match_sni", fmt.Sprintf("invalid regex pattern %q: %v", extractRegexPattern(r.MatchSNI), err))
+	}
+	return nil
+}
+
+// validateTLS validates the TLS settings of the server, that the server asks
+// for the client certificates routes match, and that it serves the TLS
+// connections whose server names routes match
+func (c *Config) validateTLS() error {
+	if c.Server.TLS != nil {
+		if err := c.Server.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for i, route := range c.Routes {
+		if route.MatchSNI != "" && c.Server.TLS == nil {
+			return fmt.Errorf("route[%d]: %w", i, NewValidationError("match_sni", "matching server names requires 'server.tls'"))
+		}
+		if route.MatchClientCert == nil {
+			continue
+		}
+		if c.Server.TLS == nil || c.Server.TLS.GetClientAuth() == ClientAuthNone {
+			return fmt.Errorf("route[%d]: %w", i, NewValidationError("match_client_cert", "matching client certificates requires 'server.tls' with 'client_auth' set to \"request\" or \"require\""))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key to dir,
+// returning their paths
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		tls         TLSConfig
+		errContains string
+	}{
+		{name: "server certificate", tls: TLSConfig{CertFile: certFile, KeyFile: keyFile}},
+		{name: "inspected client certificates", tls: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequest}},
+		{name: "verified client certificates", tls: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire, ClientCAFile: certFile}},
+		{name: "missing key", tls: TLSConfig{CertFile: certFile}, errContains: "both 'cert_file' and 'key_file'"},
+		{name: "missing certificate file", tls: TLSConfig{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}, errContains: "failed to load certificate"},
+		{name: "unknown client auth", tls: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: "optional"}, errContains: `unknown client auth "optional"`},
+		{name: "client CAs without PEM", tls: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire, ClientCAFile: notPEM}, errContains: "no PEM certificates"},
+		{name: "client CAs without client auth", tls: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}, errContains: "requires 'client_auth'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tls.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateClientCertMatching(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+
+	tests := []struct {
+		name        string
+		tls         *TLSConfig
+		match       ClientCertMatchConfig
+		errContains string
+	}{
+		{name: "literal common name", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequest}, match: ClientCertMatchConfig{CN: "billing"}},
+		{name: "regex SAN", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire}, match: ClientCertMatchConfig{SAN: "/\\.internal$/"}},
+		{name: "no rules", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequest}, errContains: "at least one of 'cn' or 'san'"},
+		{name: "invalid regex", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequest}, match: ClientCertMatchConfig{CN: "/[a-/"}, errContains: "invalid regex pattern"},
+		{name: "without TLS", match: ClientCertMatchConfig{CN: "billing"}, errContains: "requires 'server.tls'"},
+		{name: "without client auth", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile}, match: ClientCertMatchConfig{CN: "billing"}, errContains: "requires 'server.tls'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := tt.match
+			cfg := &Config{
+				Server: ServerConfig{TLS: tt.tls},
+				Routes: []RouteConfig{{Path: "/invoices", Method: "GET", Template: "[]", MatchClientCert: &match}},
+			}
+
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateSNIMatching(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+
+	tests := []struct {
+		name        string
+		tls         *TLSConfig
+		matchSNI    string
+		errContains string
+	}{
+		{name: "literal name", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile}, matchSNI: "api.example.com"},
+		{name: "regex name", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile}, matchSNI: "/\\.example\\.com$/"},
+		{name: "invalid regex", tls: &TLSConfig{CertFile: certFile, KeyFile: keyFile}, matchSNI: "/[a-/", errContains: "match_sni"},
+		{name: "without TLS", matchSNI: "api.example.com", errContains: "matching server names requires 'server.tls'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: ServerConfig{TLS: tt.tls},
+				Routes: []RouteConfig{{Path: "/invoices", Method: "GET", Template: "[]", MatchSNI: tt.matchSNI}},
+			}
+
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TokenConfig defines a kind of token that routes can mint and require,
+// such as short-lived access tokens and longer-lived refresh tokens
+type TokenConfig struct {
+	TTL time.Duration `yaml:"ttl"` // How long a minted token stays valid
+}
+
+// RequireTokenConfig protects a route with tokens minted from the token bucket.
+// The token is read from the Authorization header by default, with an optional
+// "Bearer " prefix removed.
+type RequireTokenConfig struct {
+	Name   string `yaml:"name"`             // Token kind from the token bucket
+	Header string `yaml:"header,omitempty"` // Header carrying the token (default: Authorization)
+	Query  string `yaml:"query,omitempty"`  // Query parameter carrying the token
+	Body   string `yaml:"body,omitempty"`   // Dotted path of the body field carrying the token
+	Revoke bool   `yaml:"revoke,omitempty"` // Revoke the token once used, e.g. to rotate refresh tokens
+}
+
+// validateTokens validates the token bucket and that every route requiring a
+// token refers to a token kind defined in it
+func (c *Config) validateTokens() error {
+	names := make([]string, 0, len(c.TokenBucket))
+	for name, token := range c.TokenBucket {
+		if strings.TrimSpace(name) == "" {
+			return NewValidationError("token_bucket", "token name cannot be empty")
+		}
+		if token.TTL <= 0 {
+			return NewValidationError("token_bucket."+name+".ttl", "ttl must be greater than zero")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, route := range c.Routes {
+		if route.RequireToken == nil {
+			continue
+		}
+		if _, found := c.TokenBucket[route.RequireToken.Name]; !found {
+			return fmt.Errorf("route[%d]: %w", i, NewValidationError("require_token.name", fmt.Sprintf("unknown token %q, defined tokens: [%s]", route.RequireToken.Name, strings.Join(names, ", "))))
+		}
+	}
+
+	return nil
+}
+
+// Validate validates a RequireTokenConfig
+func (rt *RequireTokenConfig) Validate() error {
+	if strings.TrimSpace(rt.Name) == "" {
+		return NewValidationError("require_token.name", "token name cannot be empty")
+	}
+
+	sources := 0
+	for _, source := range []string{rt.Header, rt.Query, rt.Body} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return NewValidationError("require_token", "only one of 'header', 'query' or 'body' can be set")
+	}
+
+	return nil
+}
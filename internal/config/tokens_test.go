@@ -0,0 +1,111 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_ValidateTokens(t *testing.T) {
+	bucket := map[string]TokenConfig{
+		"access":  {TTL: 5 * time.Minute},
+		"refresh": {TTL: 24 * time.Hour},
+	}
+
+	tests := []struct {
+		name        string
+		bucket      map[string]TokenConfig
+		require     *RequireTokenConfig
+		errContains string
+	}{
+		{
+			name:    "known token - valid",
+			bucket:  bucket,
+			require: &RequireTokenConfig{Name: "access"},
+		},
+		{
+			name:   "bucket without protected routes - valid",
+			bucket: bucket,
+		},
+		{
+			name:        "unknown token",
+			bucket:      bucket,
+			require:     &RequireTokenConfig{Name: "session"},
+			errContains: `unknown token "session", defined tokens: [access, refresh]`,
+		},
+		{
+			name:        "no bucket",
+			require:     &RequireTokenConfig{Name: "access"},
+			errContains: `unknown token "access"`,
+		},
+		{
+			name:        "missing ttl",
+			bucket:      map[string]TokenConfig{"access": {}},
+			errContains: "token_bucket.access.ttl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				TokenBucket: tt.bucket,
+				Routes: []RouteConfig{
+					{Path: "/api/me", Method: "GET", Template: "ok", RequireToken: tt.require},
+				},
+			}
+
+			err := cfg.validateTokens()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRequireTokenConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		require     RequireTokenConfig
+		errContains string
+	}{
+		{name: "default header", require: RequireTokenConfig{Name: "access"}},
+		{name: "body field", require: RequireTokenConfig{Name: "refresh", Body: "refresh_token", Revoke: true}},
+		{name: "empty name", require: RequireTokenConfig{Query: "token"}, errContains: "token name cannot be empty"},
+		{name: "several sources", require: RequireTokenConfig{Name: "access", Header: "X-Token", Query: "token"}, errContains: "only one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.require.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateProxyRequireToken(t *testing.T) {
+	route := RouteConfig{
+		Path:         "/api/me",
+		Method:       "GET",
+		Proxy:        &ProxyConfig{URL: "https://api.example.com"},
+		RequireToken: &RequireTokenConfig{Name: "access"},
+	}
+
+	err := route.Validate()
+	if err == nil || !strings.Contains(err.Error(), "'require_token'") {
+		t.Errorf("Expected require_token to be rejected on proxy routes, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TransactionConfig defines a kind of multi-step transaction, such as a
+// payment that is authorized and then captured or voided, as a state machine.
+// Every transaction starts in the initial state and moves through the allowed
+// transitions, which routes perform by name.
+type TransactionConfig struct {
+	Initial     string                      `yaml:"initial"`     // State new transactions start in
+	Transitions map[string]TransitionConfig `yaml:"transitions"` // Allowed transitions by action name
+}
+
+// TransitionConfig is a transition between states of a transaction
+type TransitionConfig struct {
+	From []string `yaml:"from"` // States the transition can be performed from
+	To   string   `yaml:"to"`   // State the transaction moves to
+}
+
+// TransactionStepConfig makes a route perform a transition of a transaction.
+// Requests attempting a transition the transaction's state doesn't allow get
+// a 409 Conflict.
+type TransactionStepConfig struct {
+	Name   string `yaml:"name"`   // Transaction kind from the transactions section
+	ID     string `yaml:"id"`     // Template rendering the transaction ID, e.g. "{{ .Params.id }}"
+	Action string `yaml:"action"` // Transition performed by the route
+}
+
+// Validate validates a TransactionStepConfig
+func (ts *TransactionStepConfig) Validate() error {
+	if strings.TrimSpace(ts.Name) == "" {
+		return NewValidationError("transaction.name", "transaction name cannot be empty")
+	}
+	if strings.TrimSpace(ts.ID) == "" {
+		return NewValidationError("transaction.id", "transaction ID template cannot be empty")
+	}
+	if strings.TrimSpace(ts.Action) == "" {
+		return NewValidationError("transaction.action", "transaction action cannot be empty")
+	}
+	return nil
+}
+
+// validateTransactions validates the transaction state machines and that
+// every route performing a transition refers to one defined in them
+func (c *Config) validateTransactions() error {
+	names := make([]string, 0, len(c.Transactions))
+	for name, tx := range c.Transactions {
+		if strings.TrimSpace(name) == "" {
+			return NewValidationError("transactions", "transaction name cannot be empty")
+		}
+		if err := tx.validate("transactions." + name); err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, route := range c.Routes {
+		if route.Transaction == nil {
+			continue
+		}
+
+		tx, found := c.Transactions[route.Transaction.Name]
+		if !found {
+			return fmt.Errorf("route[%d]: %w", i, NewValidationError("transaction.name", fmt.Sprintf("unknown transaction %q, defined transactions: [%s]", route.Transaction.Name, strings.Join(names, ", "))))
+		}
+		if _, found := tx.Transitions[route.Transaction.Action]; !found {
+			return fmt.Errorf("route[%d]: %w", i, NewValidationError("transaction.action", fmt.Sprintf("unknown action %q of transaction %q, defined actions: [%s]", route.Transaction.Action, route.Transaction.Name, strings.Join(tx.Actions(), ", "))))
+		}
+	}
+
+	return nil
+}
+
+// validate validates the state machine of a transaction
+func (tc *TransactionConfig) validate(field string) error {
+	if strings.TrimSpace(tc.Initial) == "" {
+		return NewValidationError(field+".initial", "initial state cannot be empty")
+	}
+	if len(tc.Transitions) == 0 {
+		return NewValidationError(field+".transitions", "at least one transition must be defined")
+	}
+
+	for action, transition := range tc.Transitions {
+		transitionField := field + ".transitions." + action
+
+		if strings.TrimSpace(action) == "" {
+			return NewValidationError(field+".transitions", "action name cannot be empty")
+		}
+		if len(transition.From) == 0 {
+			return NewValidationError(transitionField+".from", "at least one state to transition from must be listed")
+		}
+		if slices.ContainsFunc(transition.From, func(state string) bool { return strings.TrimSpace(state) == "" }) {
+			return NewValidationError(transitionField+".from", "states cannot be empty")
+		}
+		if strings.TrimSpace(transition.To) == "" {
+			return NewValidationError(transitionField+".to", "state to transition to cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+// Actions returns the names of the transaction's transitions, sorted
+func (tc *TransactionConfig) Actions() []string {
+	actions := make([]string, 0, len(tc.Transitions))
+	for action := range tc.Transitions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
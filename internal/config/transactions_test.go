@@ -0,0 +1,126 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransactionStepConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		step        TransactionStepConfig
+		errContains string
+	}{
+		{name: "valid", step: TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "capture"}},
+		{name: "missing name", step: TransactionStepConfig{ID: "{{ .Params.id }}", Action: "capture"}, errContains: "transaction name cannot be empty"},
+		{name: "missing id", step: TransactionStepConfig{Name: "payment", Action: "capture"}, errContains: "transaction ID template cannot be empty"},
+		{name: "missing action", step: TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}"}, errContains: "transaction action cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.step.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTransactions(t *testing.T) {
+	payment := TransactionConfig{
+		Initial: "created",
+		Transitions: map[string]TransitionConfig{
+			"authorize": {From: []string{"created"}, To: "authorized"},
+			"capture":   {From: []string{"authorized"}, To: "captured"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		transaction TransactionConfig
+		step        TransactionStepConfig
+		errContains string
+	}{
+		{
+			name:        "valid",
+			transaction: payment,
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "capture"},
+		},
+		{
+			name:        "unknown transaction",
+			transaction: payment,
+			step:        TransactionStepConfig{Name: "order", ID: "{{ .Params.id }}", Action: "capture"},
+			errContains: `unknown transaction "order", defined transactions: [payment]`,
+		},
+		{
+			name:        "unknown action",
+			transaction: payment,
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "refund"},
+			errContains: `unknown action "refund" of transaction "payment", defined actions: [authorize, capture]`,
+		},
+		{
+			name:        "missing initial state",
+			transaction: TransactionConfig{Transitions: payment.Transitions},
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "capture"},
+			errContains: "initial state cannot be empty",
+		},
+		{
+			name:        "no transitions",
+			transaction: TransactionConfig{Initial: "created"},
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "capture"},
+			errContains: "at least one transition must be defined",
+		},
+		{
+			name: "transition without source states",
+			transaction: TransactionConfig{Initial: "created", Transitions: map[string]TransitionConfig{
+				"capture": {To: "captured"},
+			}},
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "capture"},
+			errContains: "transactions.payment.transitions.capture.from",
+		},
+		{
+			name: "transition without target state",
+			transaction: TransactionConfig{Initial: "created", Transitions: map[string]TransitionConfig{
+				"capture": {From: []string{"created"}},
+			}},
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "capture"},
+			errContains: "state to transition to cannot be empty",
+		},
+		{
+			name:        "invalid ID template",
+			transaction: payment,
+			step:        TransactionStepConfig{Name: "payment", ID: "{{ .Params.id", Action: "capture"},
+			errContains: "transaction ID template compilation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := tt.step
+			cfg := &Config{
+				Transactions: map[string]TransactionConfig{"payment": tt.transaction},
+				Routes: []RouteConfig{
+					{Path: "/^/payments/(?P<id>[^/]+)/capture$/", Method: "POST", Template: "{}", Transaction: &step},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
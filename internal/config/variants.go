@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// VariantsConfig picks a route's response by the output of a selector
+// template, such as the value of an experiment header, so clients handling
+// A/B experiments can be tested against every variant with one configuration.
+// Requests whose selector matches no variant get the default one.
+type VariantsConfig struct {
+	Selector string                    `yaml:"selector"` // Template whose trimmed output names the variant, e.g. '{{ .Headers.Get "X-Experiment" }}'
+	Cases    map[string]ResponseConfig `yaml:"cases"`    // Responses by variant name
+	Default  string                    `yaml:"default"`  // Variant served when the selector matches none
+}
+
+// validateVariants validates the variants of a route
+func (r *RouteConfig) validateVariants() error {
+	if r.Variants == nil {
+		return nil
+	}
+
+	if len(r.Responses) > 0 || r.Sequence != nil {
+		return NewValidationError("variants", "'variants' cannot be combined with 'responses' or 'sequence'")
+	}
+
+	return r.Variants.Validate()
+}
+
+// Validate validates a VariantsConfig
+func (v *VariantsConfig) Validate() error {
+	if strings.TrimSpace(v.Selector) == "" {
+		return NewValidationError("variants.selector", "selector template cannot be empty")
+	}
+
+	if len(v.Cases) == 0 {
+		return NewValidationError("variants.cases", "at least one variant must be defined")
+	}
+
+	for _, name := range v.Names() {
+		resp := v.Cases[name]
+		if strings.TrimSpace(name) == "" {
+			return NewValidationError("variants.cases", "variant name cannot be empty")
+		}
+		if resp.When != nil || resp.Weight != 0 {
+			return NewValidationError("variants.cases", fmt.Sprintf("variant %q: 'when' and 'weight' cannot be used in variants", name))
+		}
+		if err := resp.Validate(); err != nil {
+			return fmt.Errorf("variants.cases[%s]: %w", name, err)
+		}
+	}
+
+	if _, ok := v.Cases[v.Default]; !ok {
+		return NewValidationError("variants.default", fmt.Sprintf("default variant %q is not defined, must be one of: %s", v.Default, strings.Join(v.Names(), ", ")))
+	}
+
+	return nil
+}
+
+// Names returns the names of the variants, sorted
+func (v *VariantsConfig) Names() []string {
+	names := make([]string, 0, len(v.Cases))
+	for name := range v.Cases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteConfig_ValidateVariants(t *testing.T) {
+	selector := `{{ .Headers.Get "X-Experiment" }}`
+	cases := map[string]ResponseConfig{
+		"control":   {Template: "old checkout"},
+		"treatment": {Status: 201, Template: "new checkout"},
+	}
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "variants - valid",
+			route: RouteConfig{Path: "/checkout", Method: "GET", Variants: &VariantsConfig{Selector: selector, Cases: cases, Default: "control"}},
+		},
+		{
+			name:        "with template - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Template: "checkout", Variants: &VariantsConfig{Selector: selector, Cases: cases, Default: "control"}},
+			errContains: "'variants' cannot be combined with 'template'",
+		},
+		{
+			name:        "with responses - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Responses: []ResponseConfig{{Template: "ok"}}, Variants: &VariantsConfig{Selector: selector, Cases: cases, Default: "control"}},
+			errContains: "'variants' cannot be combined with 'responses'",
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Proxy: &ProxyConfig{URL: "http://localhost:9000"}, Variants: &VariantsConfig{Selector: selector, Cases: cases, Default: "control"}},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "empty selector - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Variants: &VariantsConfig{Selector: " ", Cases: cases, Default: "control"}},
+			errContains: "selector template cannot be empty",
+		},
+		{
+			name:        "no cases - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Variants: &VariantsConfig{Selector: selector, Default: "control"}},
+			errContains: "at least one variant",
+		},
+		{
+			name:        "unknown default - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Variants: &VariantsConfig{Selector: selector, Cases: cases, Default: "blue"}},
+			errContains: `default variant "blue" is not defined, must be one of: control, treatment`,
+		},
+		{
+			name:        "variant with condition - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Variants: &VariantsConfig{Selector: selector, Cases: map[string]ResponseConfig{"control": {Template: "ok", When: &ResponseCondition{}}}, Default: "control"}},
+			errContains: "'when' and 'weight' cannot be used in variants",
+		},
+		{
+			name:        "variant without template - invalid",
+			route:       RouteConfig{Path: "/checkout", Method: "GET", Variants: &VariantsConfig{Selector: selector, Cases: map[string]ResponseConfig{"control": {Status: 200}}, Default: "control"}},
+			errContains: "variants.cases[control]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTemplates_Variants(t *testing.T) {
+	cfg := &Config{Routes: []RouteConfig{{
+		Path:   "/checkout",
+		Method: "GET",
+		Variants: &VariantsConfig{
+			Selector: `{{ .Headers.Get "X-Experiment" `,
+			Cases:    map[string]ResponseConfig{"control": {Template: "ok"}},
+			Default:  "control",
+		},
+	}}}
+
+	err := cfg.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "variants selector template compilation failed") {
+		t.Errorf("Expected selector compilation error, got %v", err)
+	}
+
+	cfg.Routes[0].Variants.Selector = `{{ .Headers.Get "X-Experiment" }}`
+	cfg.Routes[0].Variants.Cases["control"] = ResponseConfig{Template: "{{ .Broken "}
+	err = cfg.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "variants.cases[control]") {
+		t.Errorf("Expected variant template compilation error, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WebSocket echo modes, deciding how a WebSocket route answers the messages
+// clients send
+const (
+	WebSocketEchoOff      = "off"      // Ignore client messages
+	WebSocketEchoRaw      = "raw"      // Send client messages back unchanged
+	WebSocketEchoTemplate = "template" // Answer each client message with the route's template
+)
+
+// WebSocketConfig turns a route into a WebSocket endpoint, which upgrades the
+// connection, sends a scripted sequence of messages and answers the messages
+// clients send. In YAML it is either true, for the defaults, or a mapping.
+type WebSocketConfig struct {
+	Messages []WebSocketMessageConfig `yaml:"messages,omitempty"` // Messages sent in order once the connection opens
+	Echo     string                   `yaml:"echo,omitempty"`     // "off", "raw" or "template", see GetEcho
+	Close    bool                     `yaml:"close,omitempty"`    // Close the connection after the last message
+}
+
+// WebSocketMessageConfig is one scripted message of a WebSocket route
+type WebSocketMessageConfig struct {
+	Template     string       `yaml:"template,omitempty"`      // Inline message template
+	TemplateFile string       `yaml:"template_file,omitempty"` // Message template file
+	Delay        *DelayConfig `yaml:"delay,omitempty"`         // Wait before sending, counted from the previous message
+}
+
+// UnmarshalYAML accepts either a boolean or a mapping with the settings
+func (w *WebSocketConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("websocket can't be false, remove it instead")
+		}
+		*w = WebSocketConfig{}
+		return nil
+	}
+
+	// Decode through an alias type so this method isn't called recursively
+	type settings WebSocketConfig
+	var s settings
+	if err := unmarshal(&s); err != nil {
+		return fmt.Errorf("websocket must be true or a mapping with messages, echo and close: %w", err)
+	}
+	*w = WebSocketConfig(s)
+	return nil
+}
+
+// validateWebSocket validates the WebSocket settings of a route
+func (r *RouteConfig) validateWebSocket() error {
+	if r.GetNormalizedMethod() != http.MethodGet {
+		return NewValidationError("websocket", fmt.Sprintf("WebSocket routes must use method GET, got %q", r.Method))
+	}
+
+	if len(r.Responses) > 0 || r.Sequence != nil {
+		return NewValidationError("websocket", "'websocket' cannot be combined with 'responses' or 'sequence'")
+	}
+
+	if r.Proxy != nil || r.Batch != nil {
+		return NewValidationError("websocket", "'websocket' cannot be combined with 'proxy' or 'batch'")
+	}
+
+	if len(r.Faults) > 0 {
+		return NewValidationError("websocket", "'websocket' cannot be combined with 'faults'")
+	}
+
+	hasTemplate := strings.TrimSpace(r.Template) != "" || strings.TrimSpace(r.TemplateFile) != ""
+	if strings.TrimSpace(r.Template) != "" && strings.TrimSpace(r.TemplateFile) != "" {
+		return NewValidationError("template", "only one of 'template' or 'template_file' can be specified, not both")
+	}
+	if r.TemplateFile != "" {
+		if err := r.validateTemplateFileExists(); err != nil {
+			return err
+		}
+	}
+
+	ws := r.WebSocket
+	for i, msg := range ws.Messages {
+		if err := msg.Validate(); err != nil {
+			return fmt.Errorf("websocket.messages[%d]: %w", i, err)
+		}
+	}
+
+	switch echo := ws.GetEcho(hasTemplate); echo {
+	case WebSocketEchoTemplate:
+		if !hasTemplate {
+			return NewValidationError("websocket.echo", "'echo: template' requires a 'template' or 'template_file' to answer messages with")
+		}
+	case WebSocketEchoOff, WebSocketEchoRaw:
+		if hasTemplate {
+			return NewValidationError("websocket.echo", fmt.Sprintf("the route's template is only used with 'echo: template', got %q", echo))
+		}
+	default:
+		return NewValidationError("websocket.echo", fmt.Sprintf("invalid echo mode %q, must be one of: %s, %s, %s", ws.Echo, WebSocketEchoOff, WebSocketEchoRaw, WebSocketEchoTemplate))
+	}
+
+	if ws.Close && ws.GetEcho(hasTemplate) != WebSocketEchoOff {
+		return NewValidationError("websocket.close", "'close' requires 'echo: off', as the connection closes after the last message")
+	}
+
+	return nil
+}
+
+// Validate validates a scripted WebSocket message
+func (m *WebSocketMessageConfig) Validate() error {
+	hasTemplate := strings.TrimSpace(m.Template) != ""
+	hasTemplateFile := strings.TrimSpace(m.TemplateFile) != ""
+
+	if hasTemplate == hasTemplateFile {
+		return NewValidationError("template", "exactly one of 'template' or 'template_file' must be specified")
+	}
+
+	if hasTemplateFile {
+		route := RouteConfig{TemplateFile: m.TemplateFile}
+		if err := route.validateTemplateFileExists(); err != nil {
+			return err
+		}
+	}
+
+	if m.Delay != nil {
+		if err := m.Delay.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetEcho returns the echo mode. It defaults to answering with the route's
+// template when it has one, to echoing messages back when there are no
+// scripted messages either, and to ignoring client messages otherwise.
+func (w *WebSocketConfig) GetEcho(hasTemplate bool) string {
+	switch {
+	case w.Echo != "":
+		return w.Echo
+	case hasTemplate:
+		return WebSocketEchoTemplate
+	case len(w.Messages) == 0:
+		return WebSocketEchoRaw
+	}
+	return WebSocketEchoOff
+}
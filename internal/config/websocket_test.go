@@ -0,0 +1,207 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestWebSocketConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		want        WebSocketConfig
+		errContains string
+	}{
+		{
+			name: "enabled",
+			yaml: `websocket: true`,
+			want: WebSocketConfig{},
+		},
+		{
+			name: "settings",
+			yaml: "websocket:\n  echo: raw\n  messages:\n    - template: hello\n      delay: 1s",
+			want: WebSocketConfig{
+				Echo:     WebSocketEchoRaw,
+				Messages: []WebSocketMessageConfig{{Template: "hello", Delay: &DelayConfig{Min: time.Second, Max: time.Second}}},
+			},
+		},
+		{
+			name:        "disabled",
+			yaml:        `websocket: false`,
+			errContains: "can't be false",
+		},
+		{
+			name:        "sequence",
+			yaml:        `websocket: [hello]`,
+			errContains: "websocket must be true or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route RouteConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &route)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.WebSocket == nil {
+				t.Fatal("Expected WebSocket settings, got nil")
+			}
+			if route.WebSocket.Echo != tt.want.Echo || len(route.WebSocket.Messages) != len(tt.want.Messages) {
+				t.Fatalf("Expected %+v, got %+v", tt.want, route.WebSocket)
+			}
+			for i, msg := range tt.want.Messages {
+				got := route.WebSocket.Messages[i]
+				if got.Template != msg.Template || *got.Delay != *msg.Delay {
+					t.Errorf("Expected message %d to be %+v, got %+v", i, msg, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteConfig_ValidateWebSocket(t *testing.T) {
+	hello := []WebSocketMessageConfig{{Template: "hello"}}
+
+	tests := []struct {
+		name        string
+		route       RouteConfig
+		errContains string
+	}{
+		{
+			name:  "echo server - valid",
+			route: RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{}},
+		},
+		{
+			name:  "scripted messages - valid",
+			route: RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Messages: hello, Close: true}},
+		},
+		{
+			name:  "template replies - valid",
+			route: RouteConfig{Path: "/ws", Method: "GET", Template: "{{ .Body }}", WebSocket: &WebSocketConfig{Messages: hello}},
+		},
+		{
+			name:  "with token requirement - valid",
+			route: RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{}, RequireToken: &RequireTokenConfig{Name: "access"}},
+		},
+		{
+			name:        "POST - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "POST", WebSocket: &WebSocketConfig{}},
+			errContains: "must use method GET",
+		},
+		{
+			name:        "with responses - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{}, Responses: []ResponseConfig{{Template: "{}"}}},
+			errContains: "cannot be combined with 'responses'",
+		},
+		{
+			name:        "with proxy - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{}, Proxy: &ProxyConfig{URL: "http://localhost:9000"}},
+			errContains: "cannot be combined with 'proxy'",
+		},
+		{
+			name:        "with faults - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{}, Faults: []FaultConfig{{Type: "error"}}},
+			errContains: "cannot be combined with 'faults'",
+		},
+		{
+			name:        "template and template file - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", Template: "{}", TemplateFile: "reply.tmpl", WebSocket: &WebSocketConfig{}},
+			errContains: "not both",
+		},
+		{
+			name:        "template echo without template - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Echo: WebSocketEchoTemplate}},
+			errContains: "requires a 'template'",
+		},
+		{
+			name:        "raw echo with template - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", Template: "{}", WebSocket: &WebSocketConfig{Echo: WebSocketEchoRaw}},
+			errContains: "only used with 'echo: template'",
+		},
+		{
+			name:        "unknown echo mode - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Echo: "loud"}},
+			errContains: `invalid echo mode "loud"`,
+		},
+		{
+			name:        "close while echoing - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Close: true}},
+			errContains: "'close' requires 'echo: off'",
+		},
+		{
+			name:        "message without template - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Messages: []WebSocketMessageConfig{{}}}},
+			errContains: "websocket.messages[0]",
+		},
+		{
+			name:        "message with missing template file - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Messages: []WebSocketMessageConfig{{TemplateFile: "/nonexistent/message.tmpl"}}}},
+			errContains: "does not exist",
+		},
+		{
+			name:        "message with negative delay - invalid",
+			route:       RouteConfig{Path: "/ws", Method: "GET", WebSocket: &WebSocketConfig{Messages: []WebSocketMessageConfig{{Template: "hello", Delay: &DelayConfig{Min: -time.Second, Max: -time.Second}}}}},
+			errContains: "delay cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestWebSocketConfig_GetEcho(t *testing.T) {
+	tests := []struct {
+		name        string
+		ws          WebSocketConfig
+		hasTemplate bool
+		want        string
+	}{
+		{name: "explicit", ws: WebSocketConfig{Echo: WebSocketEchoOff}, hasTemplate: true, want: WebSocketEchoOff},
+		{name: "with template", ws: WebSocketConfig{Messages: []WebSocketMessageConfig{{Template: "hi"}}}, hasTemplate: true, want: WebSocketEchoTemplate},
+		{name: "without messages", ws: WebSocketConfig{}, want: WebSocketEchoRaw},
+		{name: "with messages", ws: WebSocketConfig{Messages: []WebSocketMessageConfig{{Template: "hi"}}}, want: WebSocketEchoOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ws.GetEcho(tt.hasTemplate); got != tt.want {
+				t.Errorf("Expected echo mode %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTemplates_WebSocketMessages(t *testing.T) {
+	cfg := &Config{Routes: []RouteConfig{{
+		Path:      "/ws",
+		Method:    "GET",
+		WebSocket: &WebSocketConfig{Messages: []WebSocketMessageConfig{{Template: "{{ .Missing"}}},
+	}}}
+
+	err := cfg.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "websocket.messages[0]") {
+		t.Errorf("Expected a template error for websocket.messages[0], got %v", err)
+	}
+}
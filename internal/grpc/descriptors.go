@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// LoadDescriptorSets loads the protobuf files described by FileDescriptorSet
+// files, as written by "protoc --descriptor_set_out --include_imports". Files
+// described in more than one set are only loaded once.
+func LoadDescriptorSets(paths []string) (*protoregistry.Files, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+		}
+
+		var fds descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &fds); err != nil {
+			return nil, fmt.Errorf("invalid descriptor set %q: %w", path, err)
+		}
+
+		for _, file := range fds.GetFile() {
+			if !seen[file.GetName()] {
+				seen[file.GetName()] = true
+				set.File = append(set.File, file)
+			}
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor sets: %w", err)
+	}
+	return files, nil
+}
+
+// NormalizeMethodName normalizes a full method name written as
+// "package.Service/Method", with or without a leading slash, reporting false
+// when it isn't in that form
+func NormalizeMethodName(name string) (string, bool) {
+	service, method, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(name), "/"), "/")
+	if !found || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", false
+	}
+	return service + "/" + method, true
+}
+
+// FindMethod finds the descriptor of a method by its full name, written as
+// "package.Service/Method"
+func FindMethod(files *protoregistry.Files, name string) (protoreflect.MethodDescriptor, error) {
+	normalized, ok := NormalizeMethodName(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid method name %q, must be written as \"package.Service/Method\"", name)
+	}
+	service, method, _ := strings.Cut(normalized, "/")
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %q not found in the descriptor sets", service)
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", service)
+	}
+
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(method))
+	if methodDesc == nil {
+		return nil, fmt.Errorf("method %q not found in service %q", method, service)
+	}
+	return methodDesc, nil
+}
@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testDescriptorSet describes a greeter service with unary, server streaming
+// and client streaming methods, built from testdata/greeter.proto
+const testDescriptorSet = "testdata/greeter.pb"
+
+func TestLoadDescriptorSets(t *testing.T) {
+	path := testDescriptorSet
+
+	// Files described by more than one set are loaded once
+	files, err := LoadDescriptorSets([]string{path, path})
+	if err != nil {
+		t.Fatalf("Failed to load descriptor sets: %v", err)
+	}
+	if files.NumFiles() != 1 {
+		t.Errorf("Expected 1 file, got %d", files.NumFiles())
+	}
+
+	if _, err := LoadDescriptorSets([]string{filepath.Join(t.TempDir(), "missing.pb")}); err == nil {
+		t.Error("Expected an error for a missing descriptor set")
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.pb")
+	if err := os.WriteFile(invalid, []byte("not a descriptor set"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := LoadDescriptorSets([]string{invalid}); err == nil {
+		t.Error("Expected an error for an invalid descriptor set")
+	}
+}
+
+func TestFindMethod(t *testing.T) {
+	files, err := LoadDescriptorSets([]string{testDescriptorSet})
+	if err != nil {
+		t.Fatalf("Failed to load descriptor sets: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		streaming   bool
+		errContains string
+	}{
+		{name: "unary method", method: "greeter.v1.Greeter/SayHello"},
+		{name: "leading slash", method: "/greeter.v1.Greeter/SayHello"},
+		{name: "streaming method", method: "greeter.v1.Greeter/StreamHellos", streaming: true},
+		{name: "missing method", method: "greeter.v1.Greeter", errContains: "invalid method name"},
+		{name: "unknown service", method: "greeter.v1.Farewell/SayBye", errContains: `service "greeter.v1.Farewell" not found`},
+		{name: "message instead of service", method: "greeter.v1.HelloRequest/SayHello", errContains: "is not a service"},
+		{name: "unknown method", method: "greeter.v1.Greeter/SayBye", errContains: `method "SayBye" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, err := FindMethod(files, tt.method)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if desc.IsStreamingServer() != tt.streaming {
+				t.Errorf("Expected server streaming %v, got %v", tt.streaming, desc.IsStreamingServer())
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	files, err := LoadDescriptorSets([]string{testDescriptorSet})
+	if err != nil {
+		t.Fatalf("Failed to load descriptor sets: %v", err)
+	}
+	desc, err := FindMethod(files, "greeter.v1.Greeter/SayHello")
+	if err != nil {
+		t.Fatalf("Failed to find method: %v", err)
+	}
+
+	encoded, err := EncodeMessage(desc.Output(), []byte(`{"message": "Hello, Ada"}`))
+	if err != nil {
+		t.Fatalf("Failed to encode message: %v", err)
+	}
+
+	var buf bytes.Buffer
+	for range 2 {
+		if err := WriteMessage(&buf, encoded); err != nil {
+			t.Fatalf("Failed to write message: %v", err)
+		}
+	}
+
+	messages, err := ReadMessages(&buf)
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d, %v", len(messages), err)
+	}
+
+	// Decoded messages include unset fields
+	decoded, err := DecodeMessage(desc.Output(), messages[0])
+	if err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if got, _ := json.Marshal(decoded); string(got) != `{"count":0,"message":"Hello, Ada"}` {
+		t.Errorf("Unexpected decoded message: %s", got)
+	}
+
+	if _, err := EncodeMessage(desc.Output(), []byte(`{"unknown": true}`)); err == nil {
+		t.Error("Expected an error encoding an unknown field")
+	}
+
+	if _, err := ReadMessages(bytes.NewReader([]byte{1, 0, 0, 0, 0})); !errors.Is(err, ErrCompressed) {
+		t.Errorf("Expected ErrCompressed, got %v", err)
+	}
+	if _, err := ReadMessages(bytes.NewReader([]byte{0, 0, 0, 0, 5, 1})); err == nil {
+		t.Error("Expected an error reading a truncated message")
+	}
+}
+
+func TestCode(t *testing.T) {
+	if code, ok := ParseCode("not_found"); !ok || code != NotFound {
+		t.Errorf("Expected NOT_FOUND, got %v, %v", code, ok)
+	}
+	if _, ok := ParseCode("MISSING"); ok {
+		t.Error("Expected unknown codes to be rejected")
+	}
+	if Unauthenticated.String() != "UNAUTHENTICATED" || Code(42).String() != "CODE(42)" {
+		t.Errorf("Unexpected code names: %s, %s", Unauthenticated, Code(42))
+	}
+
+	if got := EncodeStatusMessage("100% not found: café"); got != "100%25 not found: caf%C3%A9" {
+		t.Errorf("Unexpected encoded message: %q", got)
+	}
+}
@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// MaxMessageSize is the largest message accepted in requests, matching the
+// default of gRPC servers
+const MaxMessageSize = 4 << 20
+
+// ErrCompressed is returned when reading a compressed message
+var ErrCompressed = errors.New("compressed messages are not supported")
+
+// ReadMessages reads the length-prefixed messages of a gRPC request body
+func ReadMessages(r io.Reader) ([][]byte, error) {
+	var messages [][]byte
+
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return messages, nil
+			}
+			return nil, fmt.Errorf("failed to read message header: %w", err)
+		}
+
+		if header[0] != 0 {
+			return nil, ErrCompressed
+		}
+
+		size := binary.BigEndian.Uint32(header[1:])
+		if size > MaxMessageSize {
+			return nil, fmt.Errorf("message of %d bytes exceeds the maximum of %d bytes", size, MaxMessageSize)
+		}
+
+		message := make([]byte, size)
+		if _, err := io.ReadFull(r, message); err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+}
+
+// WriteMessage writes an uncompressed length-prefixed message
+func WriteMessage(w io.Writer, message []byte) error {
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[1:], uint32(len(message)))
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(message)
+	return err
+}
+
+// DecodeMessage decodes a binary message of the given type into the value
+// its JSON form decodes to, keeping the field names of the .proto file and
+// including fields left unset
+func DecodeMessage(desc protoreflect.MessageDescriptor, data []byte) (any, error) {
+	message := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %w", desc.FullName(), err)
+	}
+
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// EncodeMessage encodes the JSON form of a message of the given type into
+// its binary form
+func EncodeMessage(desc protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	message := dynamicpb.NewMessage(desc)
+	if err := protojson.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %w", desc.FullName(), err)
+	}
+	return proto.Marshal(message)
+}
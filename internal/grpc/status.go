@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Code is a gRPC status code
+type Code int
+
+// gRPC status codes
+const (
+	OK Code = iota
+	Canceled
+	Unknown
+	InvalidArgument
+	DeadlineExceeded
+	NotFound
+	AlreadyExists
+	PermissionDenied
+	ResourceExhausted
+	FailedPrecondition
+	Aborted
+	OutOfRange
+	Unimplemented
+	Internal
+	Unavailable
+	DataLoss
+	Unauthenticated
+)
+
+// codeNames are the names of status codes, as written in configurations
+var codeNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+	"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+	"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// ParseCode parses a status code name, e.g. "NOT_FOUND", ignoring case
+func ParseCode(name string) (Code, bool) {
+	for code, codeName := range codeNames {
+		if strings.EqualFold(name, codeName) {
+			return Code(code), true
+		}
+	}
+	return 0, false
+}
+
+// CodeNames returns the names of every status code
+func CodeNames() []string {
+	return append([]string(nil), codeNames...)
+}
+
+// String returns the name of the status code
+func (c Code) String() string {
+	if c >= 0 && int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return fmt.Sprintf("CODE(%d)", int(c))
+}
+
+// EncodeStatusMessage percent-encodes a status message for the grpc-message
+// trailer, which only carries printable ASCII
+func EncodeStatusMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
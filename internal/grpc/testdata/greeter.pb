@@ -0,0 +1,16 @@
+
+
+greeter.proto
+greeter.v1""
+HelloRequest
+name (	Rname"<
+
+HelloReply
+message (	Rmessage
+count (Rcount2
+Greeter<
+SayHello.greeter.v1.HelloRequest.greeter.v1.HelloReplyB
+StreamHellos.greeter.v1.HelloRequest.greeter.v1.HelloReply0C
+CollectHellos.greeter.v1.HelloRequest.greeter.v1.HelloReply(>
+
+SayGoodbye.greeter.v1.HelloRequest.greeter.v1.HelloReplybproto3
\ No newline at end of file
@@ -0,0 +1,91 @@
+package matcher
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// newJWTClaimsMatcher matches requests whose bearer token carries claims
+// with matching values, given as literals or /regex/ patterns. Tokens are
+// decoded without verifying their signature, since the mock only routes on
+// them. Array claims, like "aud", match when any of their items does.
+func newJWTClaimsMatcher(claims map[string]string) (Matcher, error) {
+	if len(claims) == 0 {
+		return nil, errors.New("at least one claim is required")
+	}
+
+	matchers := make(map[string]func(string) bool, len(claims))
+	for claim, value := range claims {
+		match, err := valueMatcher(value)
+		if err != nil {
+			return nil, fmt.Errorf("claim %q: %w", claim, err)
+		}
+		matchers[claim] = match
+	}
+
+	return Func(func(req *http.Request) bool {
+		payload, ok := bearerClaims(req)
+		if !ok {
+			return false
+		}
+		for claim, match := range matchers {
+			if !claimMatches(payload[claim], match) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+// bearerClaims decodes the claims of the JWT in the request's Authorization
+// header
+func bearerClaims(req *http.Request) (map[string]any, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// claimMatches checks a claim's value, matching strings, numbers and
+// booleans by their text and arrays by any of their items. Missing claims
+// and objects never match.
+func claimMatches(value any, match func(string) bool) bool {
+	switch v := value.(type) {
+	case string:
+		return match(v)
+	case json.Number:
+		return match(v.String())
+	case bool:
+		return match(fmt.Sprint(v))
+	case []any:
+		for _, item := range v {
+			if claimMatches(item, match) {
+				return true
+			}
+		}
+	}
+	return false
+}
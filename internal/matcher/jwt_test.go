@@ -0,0 +1,69 @@
+package matcher
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testToken returns an unsigned JWT carrying claims, given as JSON
+func testToken(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(claims)) + ".sig"
+}
+
+func TestJWTClaimsMatcher(t *testing.T) {
+	m, err := Build("jwt_claims", map[string]any{"sub": "/^user-\\d+$/", "aud": "billing", "admin": "true"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      bool
+	}{
+		{name: "matching claims", authorization: "Bearer " + testToken(`{"sub":"user-42","aud":"billing","admin":true}`), expected: true},
+		{name: "audience in a list", authorization: "bearer " + testToken(`{"sub":"user-42","aud":["web","billing"],"admin":true}`), expected: true},
+		{name: "regex mismatch", authorization: "Bearer " + testToken(`{"sub":"service-1","aud":"billing","admin":true}`)},
+		{name: "missing claim", authorization: "Bearer " + testToken(`{"sub":"user-42","aud":"billing"}`)},
+		{name: "object claim", authorization: "Bearer " + testToken(`{"sub":"user-42","aud":{"name":"billing"},"admin":true}`)},
+		{name: "not a JWT", authorization: "Bearer opaque-token"},
+		{name: "invalid payload", authorization: "Bearer a.!!!.c"},
+		{name: "basic auth", authorization: "Basic dXNlcjpwYXNz"},
+		{name: "no authorization"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if got := m.Match(req); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJWTClaimsMatcher_InvalidSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    any
+		errContains string
+	}{
+		{name: "no claims", settings: map[string]any{}, errContains: "at least one claim is required"},
+		{name: "invalid regex", settings: map[string]any{"sub": "/[a-/"}, errContains: `claim "sub": invalid regex pattern`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build("jwt_claims", tt.settings)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package matcher holds the built-in kinds of request matchers, registered
+// with the public registry in pkg/matcher, and gives routes access to every
+// registered kind.
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	matcherpkg "github.com/patrickdappollonio/mockingjay/pkg/matcher"
+)
+
+// Matcher decides whether a route accepts a request whose method and path
+// already matched
+type Matcher = matcherpkg.Matcher
+
+// Func adapts a function to a Matcher
+type Func = matcherpkg.Func
+
+// Build creates the matcher registered under name from a route's settings
+func Build(name string, settings any) (Matcher, error) {
+	return matcherpkg.Build(name, settings)
+}
+
+// valueMatcher compiles a literal or /regex/ value into a function matching
+// strings against it
+func valueMatcher(value string) (func(string) bool, error) {
+	if len(value) <= 2 || !strings.HasPrefix(value, "/") || !strings.HasSuffix(value, "/") {
+		return func(s string) bool { return s == value }, nil
+	}
+
+	pattern := value[1 : len(value)-1]
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+	}
+	return regex.MatchString, nil
+}
+
+// Built-in matcher kinds
+func init() {
+	matcherpkg.Register("jwt_claims", newJWTClaimsMatcher)
+}
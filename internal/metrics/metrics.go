@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"maps"
+	"sync"
+)
+
+// Registry holds named counters and gauges describing what the mock server
+// has been doing, such as how many journal entries were dropped
+type Registry struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{values: make(map[string]int64)}
+}
+
+// Add increases the named counter by delta
+func (r *Registry) Add(name string, delta int64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] += delta
+}
+
+// Inc increases the named counter by one
+func (r *Registry) Inc(name string) {
+	r.Add(name, 1)
+}
+
+// Set sets the named gauge to value
+func (r *Registry) Set(name string, value int64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = value
+}
+
+// Max raises the named gauge to value, if it's higher than the current one
+func (r *Registry) Max(name string, value int64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = max(r.values[name], value)
+}
+
+// Get returns the current value of the named counter or gauge
+func (r *Registry) Get(name string) int64 {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[name]
+}
+
+// Snapshot returns a copy of every counter and gauge
+func (r *Registry) Snapshot() map[string]int64 {
+	if r == nil {
+		return map[string]int64{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.values)
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import "testing"
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	r.Inc("requests_total")
+	r.Add("requests_total", 2)
+	r.Set("entries", 10)
+	r.Set("entries", 7)
+	r.Max("longest", 5)
+	r.Max("longest", 3)
+
+	if got := r.Get("requests_total"); got != 3 {
+		t.Errorf("expected counter 3, got %d", got)
+	}
+	if got := r.Get("entries"); got != 7 {
+		t.Errorf("expected gauge 7, got %d", got)
+	}
+	if got := r.Get("longest"); got != 5 {
+		t.Errorf("expected max gauge 5, got %d", got)
+	}
+	if got := r.Get("missing"); got != 0 {
+		t.Errorf("expected unknown metric to be 0, got %d", got)
+	}
+
+	snapshot := r.Snapshot()
+	r.Inc("requests_total")
+	if snapshot["requests_total"] != 3 || len(snapshot) != 3 {
+		t.Errorf("expected snapshot to be unaffected by later changes, got %v", snapshot)
+	}
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var r *Registry
+
+	r.Inc("requests_total")
+	r.Set("entries", 1)
+	r.Max("longest", 1)
+	if got := r.Get("requests_total"); got != 0 {
+		t.Errorf("expected nil registry to report 0, got %d", got)
+	}
+	if snapshot := r.Snapshot(); len(snapshot) != 0 {
+		t.Errorf("expected empty snapshot, got %v", snapshot)
+	}
+}
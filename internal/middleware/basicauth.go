@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
 )
 
 // BasicAuthConfig represents basic authentication middleware configuration
@@ -121,13 +123,13 @@ func (b *BasicAuthMiddleware) Handler() func(http.Handler) http.Handler {
 			// Extract credentials from Authorization header
 			username, password, ok := r.BasicAuth()
 			if !ok {
-				b.unauthorized(w)
+				b.unauthorized(w, r)
 				return
 			}
 
 			// Validate credentials
 			if !b.validateCredentials(username, password) {
-				b.unauthorized(w)
+				b.unauthorized(w, r)
 				return
 			}
 
@@ -177,9 +179,11 @@ func (b *BasicAuthMiddleware) validateCredentials(username, password string) boo
 	return username == b.config.Username && password == b.config.Password
 }
 
-// unauthorized sends a 401 Unauthorized response with WWW-Authenticate header
-func (b *BasicAuthMiddleware) unauthorized(w http.ResponseWriter) {
+// unauthorized sends a 401 Unauthorized response with WWW-Authenticate header,
+// in the request's error format
+func (b *BasicAuthMiddleware) unauthorized(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("WWW-Authenticate", `Basic realm="`+b.config.Realm+`"`)
-	w.WriteHeader(http.StatusUnauthorized)
-	w.Write([]byte("401 Unauthorized"))
+	problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized,
+		"valid credentials are required",
+		"401 Unauthorized")
 }
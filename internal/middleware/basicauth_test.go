@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
 )
 
 func TestNewBasicAuthMiddleware(t *testing.T) {
@@ -412,3 +415,32 @@ func makeBasicAuthHeader(username, password string) string {
 	auth := username + ":" + password
 	return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 }
+
+func TestBasicAuthMiddleware_ProblemJSON(t *testing.T) {
+	middleware, err := NewBasicAuthMiddleware(BasicAuthConfig{Username: "admin", Password: "secret"})
+	if err != nil {
+		t.Fatalf("failed to create basic auth middleware: %v", err)
+	}
+	handler := problem.Middleware(problem.FormatJSON, middleware.Handler()(http.HandlerFunc(mockFinalHandler)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/private", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("Expected problem+json content type, got %q", ct)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("Expected a WWW-Authenticate challenge")
+	}
+
+	var doc problem.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode problem document %q: %v", rec.Body.String(), err)
+	}
+	if doc.Code != problem.CodeUnauthorized || doc.Instance != "/private" {
+		t.Errorf("Unexpected problem document: %+v", doc)
+	}
+}
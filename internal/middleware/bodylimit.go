@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+)
+
+// BodyLimitConfig represents body limit middleware configuration
+type BodyLimitConfig struct {
+	MaxBytes int64 `yaml:"max_bytes"` // Largest request body accepted, in bytes
+}
+
+// BodyLimitMiddleware rejects requests whose body is larger than a limit with
+// a 413 Payload Too Large
+type BodyLimitMiddleware struct {
+	config BodyLimitConfig
+}
+
+// NewBodyLimitMiddleware creates a new body limit middleware
+func NewBodyLimitMiddleware(config BodyLimitConfig) (*BodyLimitMiddleware, error) {
+	if config.MaxBytes <= 0 {
+		return nil, NewConfigError("bodylimit", "max_bytes", "must be greater than 0")
+	}
+
+	return &BodyLimitMiddleware{config: config}, nil
+}
+
+// Name returns the middleware name
+func (m *BodyLimitMiddleware) Name() string {
+	return "bodylimit"
+}
+
+// Handler returns the standard Go middleware handler. Requests declaring a
+// larger Content-Length are rejected right away, while other bodies are
+// capped so reading past the limit fails, which handlers answer with a 413.
+func (m *BodyLimitMiddleware) Handler() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > m.config.MaxBytes {
+				WritePayloadTooLarge(w, r, m.config.MaxBytes)
+				return
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, m.config.MaxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WritePayloadTooLarge writes a 413 Payload Too Large response for a request
+// whose body is larger than limit bytes
+func WritePayloadTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	detail := fmt.Sprintf("The request body is larger than the limit of %d bytes.", limit)
+	problem.Write(w, r, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, detail, "413 Payload Too Large\n\n"+detail)
+}
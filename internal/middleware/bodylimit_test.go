@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	middleware, err := NewBodyLimitMiddleware(BodyLimitConfig{MaxBytes: 8})
+	if err != nil {
+		t.Fatalf("NewBodyLimitMiddleware() unexpected error: %v", err)
+	}
+	if name := middleware.Name(); name != "bodylimit" {
+		t.Errorf("Expected name %q, got %q", "bodylimit", name)
+	}
+
+	// The handler echoes the body, answering reads past the limit with a 413
+	handler := NewChain(middleware).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+			WritePayloadTooLarge(w, r, tooLarge.Limit)
+			return
+		}
+		w.Write(body)
+	}))
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{name: "body within the limit", body: strings.NewReader("12345678"), wantStatus: http.StatusOK},
+		{name: "no body", body: nil, wantStatus: http.StatusOK},
+		{name: "declared length over the limit", body: strings.NewReader("123456789"), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "unknown length over the limit", body: io.MultiReader(strings.NewReader("123456789")), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/upload", tt.body)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusRequestEntityTooLarge && !strings.Contains(rec.Body.String(), "limit of 8 bytes") {
+				t.Errorf("Expected the limit in the response, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewBodyLimitMiddleware_InvalidConfig(t *testing.T) {
+	factory := NewFactory(nil, nil)
+	for _, values := range []map[string]interface{}{
+		{},
+		{"max_bytes": 0},
+		{"max_bytes": -1},
+		{"max_bytes": "1MB"},
+	} {
+		if _, err := factory.CreateMiddleware(MiddlewareConfig{Type: "bodylimit", Config: values}); err == nil {
+			t.Errorf("Expected an error for config %v", values)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package middleware
 import (
 	"fmt"
 	"log/slog"
-	"time"
+	"strings"
 
 	"github.com/justinas/alice"
+
+	"github.com/patrickdappollonio/mockingjay/internal/metrics"
+	middlewarepkg "github.com/patrickdappollonio/mockingjay/pkg/middleware"
 )
 
 // Config represents middleware configuration from YAML
@@ -16,181 +19,102 @@ type Config struct {
 // MiddlewareConfig represents a single middleware configuration
 type MiddlewareConfig struct {
 	Type   string                 `yaml:"type"`   // "cors", "logger", etc.
-	Config map[string]interface{} `yaml:"config"` // Type-specific configuration
+	Config map[string]interface{} `yaml:"config"` // Type-specific configuration, decoded into the registered config type
 }
 
-// Factory creates middleware instances from configuration
-type Factory struct {
-	logger *slog.Logger
-}
+// secretSettings are words that mark a setting as secret when they appear in its name
+var secretSettings = []string{"password", "secret", "token", "key"}
 
-// NewFactory creates a new middleware factory
-func NewFactory(logger *slog.Logger) *Factory {
-	return &Factory{logger: logger}
+// Redacted returns the middleware's settings with the values of secret
+// settings, such as passwords, replaced, so they can be shown to operators
+func (m MiddlewareConfig) Redacted() map[string]interface{} {
+	return redactSettings(m.Config)
 }
 
-// CreateMiddleware creates a middleware instance from configuration
-func (f *Factory) CreateMiddleware(config MiddlewareConfig) (Middleware, error) {
-	switch config.Type {
-	case "cors":
-		return f.createCORSMiddleware(config.Config)
-	case "logger":
-		return f.createLoggerMiddleware(config.Config)
-	case "basicauth":
-		return f.createBasicAuthMiddleware(config.Config)
-	case "timeout":
-		return f.createTimeoutMiddleware(config.Config)
-	default:
-		return nil, fmt.Errorf("unknown middleware type %q", config.Type)
+// redactSettings copies settings, replacing the values of secret ones, also
+// in nested mappings
+func redactSettings(settings map[string]interface{}) map[string]interface{} {
+	if settings == nil {
+		return nil
 	}
-}
-
-// CreateChain creates a middleware chain from configuration
-func (f *Factory) CreateChain(config Config) (alice.Chain, error) {
-	var middlewares []Middleware
 
-	for _, middlewareConfig := range config.Enabled {
-		middleware, err := f.CreateMiddleware(middlewareConfig)
-		if err != nil {
-			return alice.Chain{}, fmt.Errorf("failed to create middleware %s: %w", middlewareConfig.Type, err)
+	redacted := make(map[string]interface{}, len(settings))
+	for name, value := range settings {
+		if isSecretSetting(name) {
+			redacted[name] = redactedValue
+			continue
 		}
-		middlewares = append(middlewares, middleware)
-	}
-
-	return NewChain(middlewares...), nil
-}
-
-// createCORSMiddleware creates CORS middleware from config map
-func (f *Factory) createCORSMiddleware(configMap map[string]interface{}) (Middleware, error) {
-	config := CORSConfig{}
-
-	// Parse configuration with type assertions
-	if origins, ok := configMap["allow_origins"].([]interface{}); ok {
-		config.AllowOrigins = make([]string, len(origins))
-		for i, origin := range origins {
-			if str, ok := origin.(string); ok {
-				config.AllowOrigins[i] = str
-			}
-		}
-	}
-
-	if methods, ok := configMap["allow_methods"].([]interface{}); ok {
-		config.AllowMethods = make([]string, len(methods))
-		for i, method := range methods {
-			if str, ok := method.(string); ok {
-				config.AllowMethods[i] = str
-			}
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = redactSettings(nested)
 		}
+		redacted[name] = value
 	}
-
-	if headers, ok := configMap["allow_headers"].([]interface{}); ok {
-		config.AllowHeaders = make([]string, len(headers))
-		for i, header := range headers {
-			if str, ok := header.(string); ok {
-				config.AllowHeaders[i] = str
-			}
-		}
-	}
-
-	if credentials, ok := configMap["allow_credentials"].(bool); ok {
-		config.AllowCredentials = credentials
-	}
-
-	if maxAge, ok := configMap["max_age"].(int); ok {
-		config.MaxAge = maxAge
-	}
-
-	return NewCORSMiddleware(config), nil
+	return redacted
 }
 
-// createLoggerMiddleware creates logger middleware from config map
-func (f *Factory) createLoggerMiddleware(configMap map[string]interface{}) (Middleware, error) {
-	config := LoggerConfig{}
-
-	if format, ok := configMap["format"].(string); ok {
-		config.Format = format
-	}
-
-	if level, ok := configMap["level"].(string); ok {
-		config.Level = level
-	}
-
-	if skipPaths, ok := configMap["skip_paths"].([]interface{}); ok {
-		config.SkipPaths = make([]string, len(skipPaths))
-		for i, path := range skipPaths {
-			if str, ok := path.(string); ok {
-				config.SkipPaths[i] = str
-			}
+// isSecretSetting reports whether a setting's name marks it as secret
+func isSecretSetting(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range secretSettings {
+		if strings.Contains(name, word) {
+			return true
 		}
 	}
+	return false
+}
 
-	return NewLoggerMiddleware(f.logger, config), nil
+// Factory creates middleware instances from configuration
+type Factory struct {
+	logger  *slog.Logger
+	metrics *metrics.Registry // Where middleware count what they do, if anywhere
 }
 
-// createBasicAuthMiddleware creates basic auth middleware from config map
-func (f *Factory) createBasicAuthMiddleware(configMap map[string]interface{}) (Middleware, error) {
-	config := BasicAuthConfig{}
+// metricsUser is implemented by middleware that count what they do in the
+// server's metrics
+type metricsUser interface {
+	useMetrics(registry *metrics.Registry)
+}
 
-	if username, ok := configMap["username"].(string); ok {
-		config.Username = username
-	}
+// NewFactory creates a new middleware factory, whose middleware log to logger
+// and count what they do in registry, which can be nil
+func NewFactory(logger *slog.Logger, registry *metrics.Registry) *Factory {
+	return &Factory{logger: logger, metrics: registry}
+}
 
-	if password, ok := configMap["password"].(string); ok {
-		config.Password = password
+// CreateMiddleware creates a middleware instance from configuration
+func (f *Factory) CreateMiddleware(config MiddlewareConfig) (Middleware, error) {
+	middleware, err := middlewarepkg.New(config.Type, config.Config, f.logger)
+	if err != nil {
+		return nil, err
 	}
-
-	if realm, ok := configMap["realm"].(string); ok {
-		config.Realm = realm
+	if user, ok := middleware.(metricsUser); ok {
+		user.useMetrics(f.metrics)
 	}
+	return middleware, nil
+}
 
-	// Parse paths configuration
-	if pathsMap, ok := configMap["paths"].(map[string]interface{}); ok {
-		if includeList, ok := pathsMap["include"].([]interface{}); ok {
-			config.Paths.Include = make([]string, len(includeList))
-			for i, path := range includeList {
-				if str, ok := path.(string); ok {
-					config.Paths.Include[i] = str
-				}
-			}
-		}
-
-		if excludeList, ok := pathsMap["exclude"].([]interface{}); ok {
-			config.Paths.Exclude = make([]string, len(excludeList))
-			for i, path := range excludeList {
-				if str, ok := path.(string); ok {
-					config.Paths.Exclude[i] = str
-				}
-			}
+// Validate checks that every configured middleware can be created from its configuration
+func (c *Config) Validate() error {
+	factory := NewFactory(slog.New(slog.DiscardHandler), nil)
+	for i, middlewareConfig := range c.Enabled {
+		if _, err := factory.CreateMiddleware(middlewareConfig); err != nil {
+			return fmt.Errorf("middleware[%d] (%s): %w", i, middlewareConfig.Type, err)
 		}
 	}
-
-	// Validate required fields
-	if config.Username == "" {
-		return nil, fmt.Errorf("basic auth username is required")
-	}
-	if config.Password == "" {
-		return nil, fmt.Errorf("basic auth password is required")
-	}
-
-	return NewBasicAuthMiddleware(config)
+	return nil
 }
 
-// createTimeoutMiddleware creates timeout middleware from config map
-func (f *Factory) createTimeoutMiddleware(configMap map[string]interface{}) (Middleware, error) {
-	config := TimeoutConfig{}
+// CreateChain creates a middleware chain from configuration
+func (f *Factory) CreateChain(config Config) (alice.Chain, error) {
+	var middlewares []Middleware
 
-	// Parse duration from string or integer (seconds)
-	if duration, ok := configMap["duration"].(string); ok {
-		if parsed, err := time.ParseDuration(duration); err == nil {
-			config.Duration = parsed
-		} else {
-			return nil, fmt.Errorf("invalid timeout duration format: %v", err)
+	for _, middlewareConfig := range config.Enabled {
+		middleware, err := f.CreateMiddleware(middlewareConfig)
+		if err != nil {
+			return alice.Chain{}, fmt.Errorf("failed to create middleware %s: %w", middlewareConfig.Type, err)
 		}
-	} else if seconds, ok := configMap["duration"].(int); ok {
-		config.Duration = time.Duration(seconds) * time.Second
-	} else if seconds, ok := configMap["duration"].(float64); ok {
-		config.Duration = time.Duration(seconds) * time.Second
+		middlewares = append(middlewares, middleware)
 	}
 
-	return NewTimeoutMiddleware(config, f.logger), nil
+	return NewChain(middlewares...), nil
 }
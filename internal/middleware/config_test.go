@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// parseMiddlewareConfig decodes a YAML middleware config the same way config files are loaded
+func parseMiddlewareConfig(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+
+	var configMap map[string]interface{}
+	if err := yaml.Unmarshal([]byte(data), &configMap); err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	return configMap
+}
+
+func TestFactory_CreateMiddleware_InvalidValues(t *testing.T) {
+	factory := NewFactory(slog.New(slog.DiscardHandler), nil)
+
+	tests := []struct {
+		name       string
+		typ        string
+		config     string
+		errField   string
+		errMessage string
+	}{
+		{
+			name:       "cors origins as scalar",
+			typ:        "cors",
+			config:     `allow_origins: "https://example.com"`,
+			errField:   "allow_origins",
+			errMessage: "expected a list of strings, got string",
+		},
+		{
+			name:       "cors origins with non-string item",
+			typ:        "cors",
+			config:     `allow_origins: ["https://example.com", 42]`,
+			errField:   "allow_origins",
+			errMessage: "expected item 1 to be a string, got integer",
+		},
+		{
+			name:       "cors invalid max age",
+			typ:        "cors",
+			config:     `max_age: "one hour"`,
+			errField:   "max_age",
+			errMessage: "invalid duration",
+		},
+		{
+			name:       "cors credentials as string",
+			typ:        "cors",
+			config:     `allow_credentials: "yes"`,
+			errField:   "allow_credentials",
+			errMessage: "expected a boolean, got string",
+		},
+		{
+			name:       "logger format as list",
+			typ:        "logger",
+			config:     `format: ["json"]`,
+			errField:   "format",
+			errMessage: "expected a string, got list",
+		},
+		{
+			name:       "basic auth missing username",
+			typ:        "basicauth",
+			config:     "password: secret",
+			errField:   "username",
+			errMessage: "is required",
+		},
+		{
+			name:       "basic auth missing password",
+			typ:        "basicauth",
+			config:     "username: admin",
+			errField:   "password",
+			errMessage: "is required",
+		},
+		{
+			name:       "basic auth paths as list",
+			typ:        "basicauth",
+			config:     "username: admin\npassword: secret\npaths: [\"/admin\"]",
+			errField:   "paths",
+			errMessage: "expected a mapping, got list",
+		},
+		{
+			name:       "basic auth nested include as scalar",
+			typ:        "basicauth",
+			config:     "username: admin\npassword: secret\npaths:\n  include: /admin",
+			errField:   "paths.include",
+			errMessage: "expected a list of strings, got string",
+		},
+		{
+			name:       "timeout negative duration",
+			typ:        "timeout",
+			config:     `duration: "-5s"`,
+			errField:   "duration",
+			errMessage: "duration cannot be negative",
+		},
+		{
+			name:       "timeout duration as boolean",
+			typ:        "timeout",
+			config:     `duration: true`,
+			errField:   "duration",
+			errMessage: "expected a duration string or number of seconds, got boolean",
+		},
+		{
+			name:       "unknown field",
+			typ:        "cors",
+			config:     `allowed_origins: ["*"]`,
+			errField:   "",
+			errMessage: "unknown field(s): allowed_origins",
+		},
+		{
+			name:       "unknown nested field",
+			typ:        "basicauth",
+			config:     "username: admin\npassword: secret\npaths:\n  includes: [\"/admin\"]",
+			errField:   "",
+			errMessage: "unknown field(s): paths.includes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := factory.CreateMiddleware(MiddlewareConfig{
+				Type:   tt.typ,
+				Config: parseMiddlewareConfig(t, tt.config),
+			})
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("Expected a *ConfigError, got %T: %v", err, err)
+			}
+			if configErr.Middleware != tt.typ {
+				t.Errorf("Expected middleware %q, got %q", tt.typ, configErr.Middleware)
+			}
+			if configErr.Field != tt.errField {
+				t.Errorf("Expected field %q, got %q", tt.errField, configErr.Field)
+			}
+			if !strings.Contains(configErr.Message, tt.errMessage) {
+				t.Errorf("Expected message containing %q, got %q", tt.errMessage, configErr.Message)
+			}
+		})
+	}
+}
+
+func TestFactory_CreateMiddleware_Durations(t *testing.T) {
+	factory := NewFactory(slog.New(slog.DiscardHandler), nil)
+
+	t.Run("cors max age", func(t *testing.T) {
+		tests := []struct {
+			config   string
+			expected time.Duration
+		}{
+			{config: `max_age: 600`, expected: 10 * time.Minute},
+			{config: `max_age: "10m"`, expected: 10 * time.Minute},
+			{config: `max_age: 1.5`, expected: 1500 * time.Millisecond},
+		}
+
+		for _, tt := range tests {
+			mw, err := factory.CreateMiddleware(MiddlewareConfig{Type: "cors", Config: parseMiddlewareConfig(t, tt.config)})
+			if err != nil {
+				t.Fatalf("CreateMiddleware(%q) unexpected error: %v", tt.config, err)
+			}
+			if got := mw.(*CORSMiddleware).config.MaxAge; got != tt.expected {
+				t.Errorf("CreateMiddleware(%q) max age = %d, expected %d", tt.config, got, tt.expected)
+			}
+		}
+	})
+
+	t.Run("timeout duration", func(t *testing.T) {
+		tests := []struct {
+			config   string
+			expected time.Duration
+		}{
+			{config: `duration: 5`, expected: 5 * time.Second},
+			{config: `duration: "250ms"`, expected: 250 * time.Millisecond},
+			{config: `duration: 0.5`, expected: 500 * time.Millisecond},
+		}
+
+		for _, tt := range tests {
+			mw, err := factory.CreateMiddleware(MiddlewareConfig{Type: "timeout", Config: parseMiddlewareConfig(t, tt.config)})
+			if err != nil {
+				t.Fatalf("CreateMiddleware(%q) unexpected error: %v", tt.config, err)
+			}
+			if got := mw.(*TimeoutMiddleware).config.Duration; got != tt.expected {
+				t.Errorf("CreateMiddleware(%q) duration = %v, expected %v", tt.config, got, tt.expected)
+			}
+		}
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{Enabled: []MiddlewareConfig{
+		{Type: "cors", Config: map[string]interface{}{"max_age": "1h"}},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	invalid := Config{Enabled: []MiddlewareConfig{
+		{Type: "cors"},
+		{Type: "timeout", Config: map[string]interface{}{"duration": "soon"}},
+	}}
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "middleware[1] (timeout)") {
+		t.Errorf("Expected error to identify the failing middleware, got %q", err.Error())
+	}
+}
+
+func TestFactory_CreateMiddleware_UnknownType(t *testing.T) {
+	factory := NewFactory(slog.New(slog.DiscardHandler), nil)
+
+	_, err := factory.CreateMiddleware(MiddlewareConfig{Type: "nope"})
+	if err == nil || !strings.Contains(err.Error(), "basicauth, bodylimit, cors, logger, timeout") {
+		t.Errorf("Expected unknown type error listing registered types, got %v", err)
+	}
+}
+
+func TestMiddlewareConfig_Redacted(t *testing.T) {
+	mc := MiddlewareConfig{
+		Type:   "basicauth",
+		Config: parseMiddlewareConfig(t, "username: admin\npassword: hunter2\nupstream:\n  api_key: abc\n  url: http://localhost"),
+	}
+
+	redacted := mc.Redacted()
+	if redacted["username"] != "admin" {
+		t.Errorf("Expected username to be kept, got %v", redacted["username"])
+	}
+	if redacted["password"] != redactedValue {
+		t.Errorf("Expected password to be redacted, got %v", redacted["password"])
+	}
+
+	nested, ok := redacted["upstream"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected nested settings, got %v", redacted["upstream"])
+	}
+	if nested["api_key"] != redactedValue || nested["url"] != "http://localhost" {
+		t.Errorf("Expected only the nested key to be redacted, got %v", nested)
+	}
+
+	if mc.Config["password"] != "hunter2" {
+		t.Errorf("Expected the original settings to be left untouched, got %v", mc.Config["password"])
+	}
+}
@@ -1,28 +1,60 @@
 package middleware
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/metrics"
+)
+
+// corsReflectOrigin is the allow_origin value that echoes back any request origin
+const corsReflectOrigin = "reflect"
+
+// Metrics of the preflight requests answered by the CORS middleware
+const (
+	metricCORSPreflights        = "cors_preflights_total"         // Preflight requests answered
+	metricCORSPreflightsBlocked = "cors_preflights_blocked_total" // Preflights whose request browsers won't send
 )
 
+// corsOtherOrigin is the origin label counting preflights from origins that
+// aren't listed in allow_origins, so clients can't create metrics at will
+const corsOtherOrigin = "other"
+
+// corsSafelistedMethods are the methods browsers send without checking the
+// allowed methods of a preflight response
+var corsSafelistedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
 // CORSConfig represents CORS middleware configuration
 type CORSConfig struct {
-	AllowOrigins     []string `yaml:"allow_origins"`
-	AllowMethods     []string `yaml:"allow_methods"`
-	AllowHeaders     []string `yaml:"allow_headers"`
-	ExposeHeaders    []string `yaml:"expose_headers"`
-	AllowCredentials bool     `yaml:"allow_credentials"`
-	MaxAge           int      `yaml:"max_age"`
+	AllowOrigin      string        `yaml:"allow_origin"`  // Set to "reflect" to echo back any request origin
+	AllowOrigins     []string      `yaml:"allow_origins"` // Literal origins, "*", or regex patterns wrapped in /.../
+	AllowMethods     []string      `yaml:"allow_methods"`
+	AllowHeaders     []string      `yaml:"allow_headers"` // Use "*" to allow any requested header
+	ExposeHeaders    []string      `yaml:"expose_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+	OriginMetrics    bool          `yaml:"origin_metrics"` // Also count preflights by origin
 }
 
 // CORSMiddleware implements CORS (Cross-Origin Resource Sharing) support
 type CORSMiddleware struct {
-	config CORSConfig
+	config         CORSConfig
+	originMatchers []*PathMatcher // Compiled allowed origins
+	logger         *slog.Logger
+	metrics        *metrics.Registry // Counts answered preflights, when set
 }
 
 // NewCORSMiddleware creates a new CORS middleware with configuration
-func NewCORSMiddleware(config CORSConfig) *CORSMiddleware {
+func NewCORSMiddleware(config CORSConfig, logger *slog.Logger) (*CORSMiddleware, error) {
+	if config.AllowOrigin != "" && config.AllowOrigin != corsReflectOrigin {
+		return nil, NewConfigError("cors", "allow_origin", fmt.Sprintf("unsupported value %q, only %q is allowed", config.AllowOrigin, corsReflectOrigin))
+	}
+
 	// Set defaults if not specified
 	if len(config.AllowOrigins) == 0 {
 		config.AllowOrigins = []string{"*"}
@@ -34,10 +66,29 @@ func NewCORSMiddleware(config CORSConfig) *CORSMiddleware {
 		config.AllowHeaders = []string{"Content-Type", "Authorization"}
 	}
 	if config.MaxAge == 0 {
-		config.MaxAge = 3600 // 1 hour
+		config.MaxAge = time.Hour
 	}
 
-	return &CORSMiddleware{config: config}
+	// Regex origins must match the whole origin, so anchor them
+	origins := make([]string, len(config.AllowOrigins))
+	for i, origin := range config.AllowOrigins {
+		if isRegexPath(origin) {
+			origin = "/^(?:" + extractRegexPath(origin) + ")$/"
+		}
+		origins[i] = origin
+	}
+
+	matchers, err := compilePathMatchers(origins)
+	if err != nil {
+		return nil, NewConfigError("cors", "allow_origins", fmt.Sprintf("invalid origin pattern: %v", err))
+	}
+
+	return &CORSMiddleware{config: config, originMatchers: matchers, logger: logger}, nil
+}
+
+// useMetrics makes the middleware count the preflights it answers in registry
+func (c *CORSMiddleware) useMetrics(registry *metrics.Registry) {
+	c.metrics = registry
 }
 
 // Name returns the middleware name
@@ -50,12 +101,24 @@ func (c *CORSMiddleware) Handler() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
 
 			// Check if origin is allowed
-			if len(c.config.AllowOrigins) == 1 && c.config.AllowOrigins[0] == "*" {
+			originAllowed := true
+			switch {
+			case c.config.AllowOrigin == corsReflectOrigin:
+				if origin != "" {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				w.Header().Add("Vary", "Origin")
+			case len(c.config.AllowOrigins) == 1 && c.config.AllowOrigins[0] == "*":
 				w.Header().Set("Access-Control-Allow-Origin", "*")
-			} else if c.isOriginAllowed(origin) {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
+			default:
+				originAllowed = c.isOriginAllowed(origin)
+				if originAllowed {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				w.Header().Add("Vary", "Origin")
 			}
 
 			// Set other CORS headers
@@ -63,8 +126,11 @@ func (c *CORSMiddleware) Handler() func(http.Handler) http.Handler {
 				w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.config.AllowMethods, ", "))
 			}
 
-			if len(c.config.AllowHeaders) > 0 {
-				w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.config.AllowHeaders, ", "))
+			if allowHeaders := c.allowedHeaders(r, preflight); allowHeaders != "" {
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if preflight && c.allowsAnyHeader() {
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
 			}
 
 			if len(c.config.ExposeHeaders) > 0 {
@@ -76,10 +142,13 @@ func (c *CORSMiddleware) Handler() func(http.Handler) http.Handler {
 			}
 
 			if c.config.MaxAge > 0 {
-				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.config.MaxAge))
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.config.MaxAge/time.Second)))
 			}
 
 			// Handle preflight OPTIONS requests
+			if preflight {
+				c.recordPreflight(r, c.preflightProblem(r, originAllowed))
+			}
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
 				return
@@ -91,10 +160,126 @@ func (c *CORSMiddleware) Handler() func(http.Handler) http.Handler {
 	}
 }
 
-// isOriginAllowed checks if the origin is in the allowed origins list
+// preflightProblem explains why browsers won't send the request a preflight
+// asks about, given the preflight response, or returns "" when they will
+func (c *CORSMiddleware) preflightProblem(r *http.Request, originAllowed bool) string {
+	if !originAllowed {
+		return fmt.Sprintf("origin %q is not in allow_origins", r.Header.Get("Origin"))
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !slices.Contains(corsSafelistedMethods, method) && !slices.Contains(c.config.AllowMethods, method) {
+		return fmt.Sprintf("method %q is not in allow_methods", method)
+	}
+
+	if c.allowsAnyHeader() {
+		return ""
+	}
+	for requested := range strings.SplitSeq(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		requested = strings.TrimSpace(requested)
+		if requested == "" {
+			continue
+		}
+		allowed := slices.ContainsFunc(c.config.AllowHeaders, func(header string) bool {
+			return strings.EqualFold(header, requested)
+		})
+		if !allowed {
+			return fmt.Sprintf("header %q is not in allow_headers", requested)
+		}
+	}
+	return ""
+}
+
+// recordPreflight logs and counts a preflight request answered by the
+// middleware, which never reaches the routes. problem explains why browsers
+// won't send the actual request, if they won't.
+func (c *CORSMiddleware) recordPreflight(r *http.Request, problem string) {
+	origin := r.Header.Get("Origin")
+	attrs := []any{
+		"path", r.URL.Path,
+		"origin", origin,
+		"request_method", r.Header.Get("Access-Control-Request-Method"),
+		"request_headers", r.Header.Get("Access-Control-Request-Headers"),
+	}
+
+	originLabel := fmt.Sprintf("{origin=%q}", c.metricOrigin(origin))
+	c.metrics.Inc(metricCORSPreflights)
+	if c.config.OriginMetrics {
+		c.metrics.Inc(metricCORSPreflights + originLabel)
+	}
+
+	if problem == "" {
+		c.logger.Info("cors preflight answered", attrs...)
+		return
+	}
+
+	c.metrics.Inc(metricCORSPreflightsBlocked)
+	if c.config.OriginMetrics {
+		c.metrics.Inc(metricCORSPreflightsBlocked + originLabel)
+	}
+	c.logger.Warn("cors preflight blocked", append(attrs, "reason", problem)...)
+}
+
+// metricOrigin returns the origin label preflights from origin are counted
+// under: the origin itself when it's listed literally in allow_origins, the
+// pattern it matches when it's allowed by a regex, and "other" for any other
+// origin, including those only allowed by "*" or reflected back
+func (c *CORSMiddleware) metricOrigin(origin string) string {
+	if c.config.AllowOrigin == corsReflectOrigin || origin == "" {
+		return corsOtherOrigin
+	}
+
+	for i, matcher := range c.originMatchers {
+		if matcher.IsRegex {
+			if matcher.Regex.MatchString(origin) {
+				return c.config.AllowOrigins[i]
+			}
+			continue
+		}
+		if matcher.Literal != "*" && matcher.Literal == origin {
+			return origin
+		}
+	}
+	return corsOtherOrigin
+}
+
+// allowedHeaders returns the Access-Control-Allow-Headers value. When "*" is
+// allowed, preflight requests get back exactly the headers they asked for,
+// since browsers ignore the wildcard on credentialed requests.
+func (c *CORSMiddleware) allowedHeaders(r *http.Request, preflight bool) string {
+	if c.allowsAnyHeader() {
+		if requested := r.Header.Get("Access-Control-Request-Headers"); preflight && requested != "" {
+			return requested
+		}
+		return "*"
+	}
+	return strings.Join(c.config.AllowHeaders, ", ")
+}
+
+// allowsAnyHeader checks if the allowed headers include the "*" wildcard
+func (c *CORSMiddleware) allowsAnyHeader() bool {
+	for _, header := range c.config.AllowHeaders {
+		if header == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// isOriginAllowed checks if the origin matches any of the allowed origins
 func (c *CORSMiddleware) isOriginAllowed(origin string) bool {
-	for _, allowedOrigin := range c.config.AllowOrigins {
-		if allowedOrigin == "*" || allowedOrigin == origin {
+	if origin == "" {
+		return false
+	}
+
+	for _, matcher := range c.originMatchers {
+		if matcher.IsRegex {
+			if matcher.Regex.MatchString(origin) {
+				return true
+			}
+			continue
+		}
+		if matcher.Literal == "*" || matcher.Literal == origin {
 			return true
 		}
 	}
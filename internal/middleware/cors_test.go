@@ -1,9 +1,17 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/metrics"
 )
 
 func TestCORSMiddleware(t *testing.T) {
@@ -13,9 +21,12 @@ func TestCORSMiddleware(t *testing.T) {
 		AllowMethods:     []string{"GET", "POST", "PUT"},
 		AllowHeaders:     []string{"Content-Type", "Authorization"},
 		AllowCredentials: true,
-		MaxAge:           3600,
+		MaxAge:           time.Hour,
+	}
+	corsMiddleware, err := NewCORSMiddleware(config, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("failed to create CORS middleware: %v", err)
 	}
-	corsMiddleware := NewCORSMiddleware(config)
 
 	// Mock final handler
 	finalHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -117,7 +128,10 @@ func TestCORSMiddleware(t *testing.T) {
 
 func TestCORSDefaults(t *testing.T) {
 	// Create CORS middleware with empty config to test defaults
-	corsMiddleware := NewCORSMiddleware(CORSConfig{})
+	corsMiddleware, err := NewCORSMiddleware(CORSConfig{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("failed to create CORS middleware: %v", err)
+	}
 
 	// Mock final handler
 	finalHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -146,3 +160,318 @@ func TestCORSDefaults(t *testing.T) {
 		t.Errorf("expected methods %s, got %s", expectedMethods, methods)
 	}
 }
+
+func TestCORSOriginMatching(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         CORSConfig
+		origin         string
+		expectedOrigin string
+	}{
+		{
+			name:           "regex origin match",
+			config:         CORSConfig{AllowOrigins: []string{`/https:\/\/.*\.example\.com/`}},
+			origin:         "https://app.example.com",
+			expectedOrigin: "https://app.example.com",
+		},
+		{
+			name:           "regex origin is anchored",
+			config:         CORSConfig{AllowOrigins: []string{`/https:\/\/.*\.example\.com/`}},
+			origin:         "https://app.example.com.attacker.io",
+			expectedOrigin: "",
+		},
+		{
+			name:           "regex origin mismatch",
+			config:         CORSConfig{AllowOrigins: []string{`/https:\/\/.*\.example\.com/`}},
+			origin:         "http://app.example.com",
+			expectedOrigin: "",
+		},
+		{
+			name:           "literal and regex origins",
+			config:         CORSConfig{AllowOrigins: []string{"http://localhost:3000", `/https:\/\/.*\.example\.com/`}},
+			origin:         "http://localhost:3000",
+			expectedOrigin: "http://localhost:3000",
+		},
+		{
+			name:           "reflect mode echoes any origin",
+			config:         CORSConfig{AllowOrigin: "reflect", AllowCredentials: true},
+			origin:         "https://anything.test",
+			expectedOrigin: "https://anything.test",
+		},
+		{
+			name:           "reflect mode without origin",
+			config:         CORSConfig{AllowOrigin: "reflect"},
+			origin:         "",
+			expectedOrigin: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corsMiddleware, err := NewCORSMiddleware(tt.config, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatalf("failed to create CORS middleware: %v", err)
+			}
+			handler := corsMiddleware.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if origin := rr.Header().Get("Access-Control-Allow-Origin"); origin != tt.expectedOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.expectedOrigin, origin)
+			}
+			if vary := rr.Header().Get("Vary"); vary != "Origin" {
+				t.Errorf("expected Vary Origin, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestCORSExposeHeaders(t *testing.T) {
+	corsMiddleware, err := NewCORSMiddleware(CORSConfig{ExposeHeaders: []string{"X-Request-ID", "X-RateLimit-Remaining"}}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("failed to create CORS middleware: %v", err)
+	}
+	handler := corsMiddleware.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if expose := rr.Header().Get("Access-Control-Expose-Headers"); expose != "X-Request-ID, X-RateLimit-Remaining" {
+		t.Errorf("expected exposed headers, got %q", expose)
+	}
+}
+
+func TestCORSPreflightRequestHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowHeaders    []string
+		requestHeaders  string
+		expectedHeaders string
+		expectVary      bool
+	}{
+		{
+			name:            "wildcard reflects requested headers",
+			allowHeaders:    []string{"*"},
+			requestHeaders:  "X-Custom, Content-Type",
+			expectedHeaders: "X-Custom, Content-Type",
+			expectVary:      true,
+		},
+		{
+			name:            "wildcard without requested headers",
+			allowHeaders:    []string{"*"},
+			expectedHeaders: "*",
+			expectVary:      true,
+		},
+		{
+			name:            "explicit list is returned as configured",
+			allowHeaders:    []string{"Content-Type", "X-Token"},
+			requestHeaders:  "X-Other",
+			expectedHeaders: "Content-Type, X-Token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corsMiddleware, err := NewCORSMiddleware(CORSConfig{AllowHeaders: tt.allowHeaders}, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatalf("failed to create CORS middleware: %v", err)
+			}
+			handler := corsMiddleware.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Error("preflight request reached the next handler")
+			}))
+
+			req := httptest.NewRequest("OPTIONS", "/test", nil)
+			req.Header.Set("Origin", "http://localhost:3000")
+			req.Header.Set("Access-Control-Request-Method", "PUT")
+			if tt.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.requestHeaders)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusNoContent {
+				t.Errorf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+			}
+			if headers := rr.Header().Get("Access-Control-Allow-Headers"); headers != tt.expectedHeaders {
+				t.Errorf("expected Access-Control-Allow-Headers %q, got %q", tt.expectedHeaders, headers)
+			}
+
+			varies := strings.Join(rr.Header().Values("Vary"), ", ")
+			if got := strings.Contains(varies, "Access-Control-Request-Headers"); got != tt.expectVary {
+				t.Errorf("expected Vary on Access-Control-Request-Headers to be %v, got %q", tt.expectVary, varies)
+			}
+		})
+	}
+}
+
+func TestCORSInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config CORSConfig
+		field  string
+	}{
+		{
+			name:   "invalid origin regex",
+			config: CORSConfig{AllowOrigins: []string{"/https://[unclosed/"}},
+			field:  "allow_origins",
+		},
+		{
+			name:   "unknown allow_origin mode",
+			config: CORSConfig{AllowOrigin: "mirror"},
+			field:  "allow_origin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCORSMiddleware(tt.config, slog.New(slog.DiscardHandler))
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("expected ConfigError, got %v", err)
+			}
+			if configErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, configErr.Field)
+			}
+		})
+	}
+}
+
+func TestCORSPreflightMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		origin         string
+		method         string
+		requestHeaders string
+		expectedReason string
+		expectedLabel  string
+	}{
+		{name: "allowed", origin: "https://app.example.com", method: "PUT", requestHeaders: "content-type"},
+		{name: "safelisted method", origin: "https://app.example.com", method: "POST"},
+		{name: "allowed by regex", origin: "http://localhost:5173", method: "PUT", expectedLabel: `/http:\/\/localhost:\d+/`},
+		{name: "origin", origin: "https://evil.example.com", method: "PUT", expectedReason: `origin "https://evil.example.com" is not in allow_origins`, expectedLabel: corsOtherOrigin},
+		{name: "method", origin: "https://app.example.com", method: "PATCH", expectedReason: `method "PATCH" is not in allow_methods`},
+		{name: "header", origin: "https://app.example.com", method: "GET", requestHeaders: "Content-Type, X-Trace", expectedReason: `header "X-Trace" is not in allow_headers`},
+	}
+
+	config := map[string]interface{}{
+		"allow_origins":  []string{"https://app.example.com", `/http:\/\/localhost:\d+/`},
+		"allow_methods":  []string{"GET", "PUT"},
+		"origin_metrics": true,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs strings.Builder
+			registry := metrics.NewRegistry()
+			factory := NewFactory(slog.New(slog.NewTextHandler(&logs, nil)), registry)
+
+			chain, err := factory.CreateChain(Config{Enabled: []MiddlewareConfig{{Type: "cors", Config: config}}})
+			if err != nil {
+				t.Fatalf("failed to create chain: %v", err)
+			}
+
+			req := httptest.NewRequest("OPTIONS", "/orders", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.requestHeaders)
+			}
+			chain.Then(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+
+			blocked := int64(0)
+			if tt.expectedReason != "" {
+				blocked = 1
+			}
+			label := tt.expectedLabel
+			if label == "" {
+				label = tt.origin
+			}
+			expected := map[string]int64{
+				metricCORSPreflights:                                            1,
+				metricCORSPreflightsBlocked:                                     blocked,
+				metricCORSPreflights + fmt.Sprintf("{origin=%q}", label):        1,
+				metricCORSPreflightsBlocked + fmt.Sprintf("{origin=%q}", label): blocked,
+			}
+			for name, want := range expected {
+				if got := registry.Get(name); got != want {
+					t.Errorf("expected %s to be %d, got %d", name, want, got)
+				}
+			}
+
+			if tt.expectedReason == "" {
+				if !strings.Contains(logs.String(), "cors preflight answered") {
+					t.Errorf("expected the preflight to be logged, got %q", logs.String())
+				}
+				return
+			}
+			if !strings.Contains(logs.String(), "cors preflight blocked") || !strings.Contains(logs.String(), strconv.Quote(tt.expectedReason)) {
+				t.Errorf("expected the preflight to be logged as blocked because %s, got %q", tt.expectedReason, logs.String())
+			}
+		})
+	}
+}
+
+func TestCORSOriginMetricsBounded(t *testing.T) {
+	for _, config := range []map[string]interface{}{
+		{"allow_origins": []string{"*"}, "origin_metrics": true},
+		{"allow_origin": "reflect", "origin_metrics": true},
+	} {
+		registry := metrics.NewRegistry()
+		factory := NewFactory(slog.New(slog.DiscardHandler), registry)
+		chain, err := factory.CreateChain(Config{Enabled: []MiddlewareConfig{{Type: "cors", Config: config}}})
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+
+		// Origins only allowed by a wildcard share a single label
+		for i := range 3 {
+			req := httptest.NewRequest("OPTIONS", "/orders", nil)
+			req.Header.Set("Origin", fmt.Sprintf("https://client-%d.example.com", i))
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			chain.Then(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		expected := map[string]int64{
+			metricCORSPreflights: 3,
+			metricCORSPreflights + fmt.Sprintf("{origin=%q}", corsOtherOrigin): 3,
+		}
+		if got := registry.Snapshot(); len(got) != len(expected) {
+			t.Errorf("expected metrics %v, got %v", expected, got)
+		}
+		for name, want := range expected {
+			if got := registry.Get(name); got != want {
+				t.Errorf("expected %s to be %d, got %d", name, want, got)
+			}
+		}
+	}
+}
+
+func TestCORSNonPreflightNotCounted(t *testing.T) {
+	registry := metrics.NewRegistry()
+	factory := NewFactory(slog.New(slog.DiscardHandler), registry)
+	chain, err := factory.CreateChain(Config{Enabled: []MiddlewareConfig{{Type: "cors"}}})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+
+	for _, method := range []string{"GET", "OPTIONS"} {
+		req := httptest.NewRequest(method, "/orders", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		chain.Then(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := registry.Snapshot(); len(got) != 0 {
+		t.Errorf("expected no preflight metrics, got %v", got)
+	}
+}
@@ -0,0 +1,11 @@
+package middleware
+
+import middlewarepkg "github.com/patrickdappollonio/mockingjay/pkg/middleware"
+
+// ConfigError represents an invalid value in a middleware configuration
+type ConfigError = middlewarepkg.ConfigError
+
+// NewConfigError creates a new ConfigError
+func NewConfigError(middleware, field, message string) *ConfigError {
+	return middlewarepkg.NewConfigError(middleware, field, message)
+}
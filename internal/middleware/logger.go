@@ -1,39 +1,139 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
+// redactedSuffix marks a captured header whose value must not be logged
+const redactedSuffix = "(redacted)"
+
+// redactedValue replaces the value of redacted headers in logs and of secret
+// middleware settings
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are always redacted when captured, even without the suffix
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// Formats of access logs
+const (
+	LogFormatText = "text" // Logged through the server's logger, along with its other logs
+	LogFormatJSON = "json" // One JSON object per request on standard output, for tooling to parse
+)
+
 // LoggerConfig represents logger middleware configuration
 type LoggerConfig struct {
-	Format    string   `yaml:"format"`     // "json" or "text"
-	Level     string   `yaml:"level"`      // "debug", "info", "warn", "error"
-	Fields    []string `yaml:"fields"`     // Additional fields to log
-	SkipPaths []string `yaml:"skip_paths"` // Paths to skip logging
+	Format            string   `yaml:"format"`             // "json" or "text"
+	Level             string   `yaml:"level"`              // "debug", "info", "warn", "error"
+	Fields            []string `yaml:"fields"`             // Additional fields to log
+	SkipPaths         []string `yaml:"skip_paths"`         // Paths to skip logging
+	CaptureHeaders    []string `yaml:"capture_headers"`    // Request/response headers to log, e.g. "Authorization(redacted)"
+	CaptureBodyBytes  int      `yaml:"capture_body_bytes"` // Bytes of request and response bodies to log (0 for none)
+	CorrelationHeader string   `yaml:"correlation_header"` // Header carrying the ID correlating a request's logs, generated when missing
+}
+
+// capturedHeader represents a header to include in access logs
+type capturedHeader struct {
+	name   string // Canonical header name
+	redact bool   // Whether the value is replaced before logging
 }
 
 // LoggerMiddleware implements request logging
 type LoggerMiddleware struct {
-	logger *slog.Logger
-	config LoggerConfig
+	logger  *slog.Logger
+	config  LoggerConfig
+	capture []capturedHeader
 }
 
-// NewLoggerMiddleware creates a new logger middleware
-func NewLoggerMiddleware(logger *slog.Logger, config LoggerConfig) *LoggerMiddleware {
+// NewLoggerMiddleware creates a new logger middleware. Text access logs go
+// to logger, while JSON ones are written to standard output.
+func NewLoggerMiddleware(logger *slog.Logger, config LoggerConfig) (*LoggerMiddleware, error) {
+	return newLoggerMiddleware(logger, config, os.Stdout)
+}
+
+// newLoggerMiddleware creates a new logger middleware writing JSON access
+// logs to out
+func newLoggerMiddleware(logger *slog.Logger, config LoggerConfig, out io.Writer) (*LoggerMiddleware, error) {
 	// Set defaults
 	if config.Format == "" {
-		config.Format = "text"
+		config.Format = LogFormatText
 	}
 	if config.Level == "" {
 		config.Level = "info"
 	}
 
+	switch config.Format {
+	case LogFormatText:
+	case LogFormatJSON:
+		logger = slog.New(slog.NewJSONHandler(out, nil))
+	default:
+		return nil, NewConfigError("logger", "format", fmt.Sprintf("invalid format %q, must be one of: %s, %s", config.Format, LogFormatText, LogFormatJSON))
+	}
+
+	if config.CaptureBodyBytes < 0 {
+		return nil, NewConfigError("logger", "capture_body_bytes", "cannot be negative")
+	}
+	if config.CorrelationHeader != "" {
+		config.CorrelationHeader = http.CanonicalHeaderKey(strings.TrimSpace(config.CorrelationHeader))
+		if strings.ContainsAny(config.CorrelationHeader, " ()") {
+			return nil, NewConfigError("logger", "correlation_header", fmt.Sprintf("invalid header name %q", config.CorrelationHeader))
+		}
+	}
+
+	capture, err := parseCaptureHeaders(config.CaptureHeaders)
+	if err != nil {
+		return nil, err
+	}
+
 	return &LoggerMiddleware{
-		logger: logger,
-		config: config,
+		logger:  logger,
+		config:  config,
+		capture: capture,
+	}, nil
+}
+
+// parseCaptureHeaders parses the capture_headers entries into canonical header
+// names, honoring the "(redacted)" suffix
+func parseCaptureHeaders(entries []string) ([]capturedHeader, error) {
+	captured := make([]capturedHeader, 0, len(entries))
+
+	for _, entry := range entries {
+		name := strings.TrimSpace(entry)
+		redact := false
+
+		if strings.HasSuffix(strings.ToLower(name), redactedSuffix) {
+			name = strings.TrimSpace(name[:len(name)-len(redactedSuffix)])
+			redact = true
+		}
+
+		if name == "" {
+			return nil, NewConfigError("logger", "capture_headers", fmt.Sprintf("invalid header entry %q: header name cannot be empty", entry))
+		}
+		if strings.ContainsAny(name, " ()") {
+			return nil, NewConfigError("logger", "capture_headers", fmt.Sprintf("invalid header entry %q: use a header name optionally followed by %q", entry, redactedSuffix))
+		}
+
+		name = http.CanonicalHeaderKey(name)
+		captured = append(captured, capturedHeader{
+			name:   name,
+			redact: redact || sensitiveHeaders[name],
+		})
 	}
+
+	return captured, nil
 }
 
 // Name returns the middleware name
@@ -53,6 +153,30 @@ func (l *LoggerMiddleware) Handler() func(http.Handler) http.Handler {
 
 			start := time.Now()
 
+			// Tag the request with its correlation ID, passing it on to the
+			// handler and back to the client
+			var correlationID string
+			if header := l.config.CorrelationHeader; header != "" {
+				correlationID = r.Header.Get(header)
+				if correlationID == "" {
+					correlationID = newCorrelationID()
+					r.Header.Set(header, correlationID)
+				}
+				w.Header().Set(header, correlationID)
+			}
+
+			// Keep the start of the bodies as they're read and written
+			var requestBody *captureReader
+			if l.config.CaptureBodyBytes > 0 {
+				if r.Body != nil && r.Body != http.NoBody {
+					requestBody = &captureReader{ReadCloser: r.Body, max: l.config.CaptureBodyBytes}
+					r.Body = requestBody
+				}
+				if wrapper, ok := w.(*ResponseWriter); ok {
+					wrapper.CaptureBody(l.config.CaptureBodyBytes)
+				}
+			}
+
 			// Continue to next handler
 			next.ServeHTTP(w, r)
 
@@ -68,7 +192,7 @@ func (l *LoggerMiddleware) Handler() func(http.Handler) http.Handler {
 				size = wrapper.Size()
 			}
 
-			l.logger.Info("request processed",
+			attrs := []any{
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", status,
@@ -76,11 +200,93 @@ func (l *LoggerMiddleware) Handler() func(http.Handler) http.Handler {
 				"duration_ms", duration.Milliseconds(),
 				"remote_addr", r.RemoteAddr,
 				"user_agent", r.UserAgent(),
-			)
+			}
+
+			if correlationID != "" {
+				attrs = append(attrs, "correlation_id", correlationID)
+			}
+
+			// Add the allowlisted headers, if any were sent or returned
+			if len(l.capture) > 0 {
+				if group, ok := l.headerGroup("request_headers", r.Header); ok {
+					attrs = append(attrs, group)
+				}
+				if group, ok := l.headerGroup("response_headers", w.Header()); ok {
+					attrs = append(attrs, group)
+				}
+			}
+
+			// Add the start of the bodies, if any were read or written
+			if requestBody != nil && requestBody.size > 0 {
+				attrs = append(attrs, bodyGroup("request_body", requestBody.captured, requestBody.size))
+			}
+			if wrapper, ok := w.(*ResponseWriter); ok && l.config.CaptureBodyBytes > 0 && size > 0 {
+				attrs = append(attrs, bodyGroup("response_body", wrapper.CapturedBody(), size))
+			}
+
+			l.logger.Info("request processed", attrs...)
 		})
 	}
 }
 
+// headerGroup builds a log group with the captured headers present in h.
+// It returns false if none of them are present.
+func (l *LoggerMiddleware) headerGroup(name string, h http.Header) (slog.Attr, bool) {
+	var attrs []any
+	for _, header := range l.capture {
+		values := h.Values(header.name)
+		if len(values) == 0 {
+			continue
+		}
+
+		value := strings.Join(values, ", ")
+		if header.redact {
+			value = redactedValue
+		}
+		attrs = append(attrs, slog.String(header.name, value))
+	}
+
+	if len(attrs) == 0 {
+		return slog.Attr{}, false
+	}
+	return slog.Group(name, attrs...), true
+}
+
+// bodyGroup builds a log group with the start of a body and its full size
+func bodyGroup(name string, captured []byte, size int) slog.Attr {
+	return slog.Group(name,
+		slog.String("snippet", string(captured)),
+		slog.Int("size", size),
+		slog.Bool("truncated", len(captured) < size),
+	)
+}
+
+// captureReader keeps the start of a request body as the handler reads it,
+// counting the bytes read
+type captureReader struct {
+	io.ReadCloser
+	max      int    // Bytes kept in captured
+	captured []byte // Start of the body read so far
+	size     int    // Bytes read so far
+}
+
+// Read reads from the body, keeping what fits in the capture
+func (c *captureReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.size += n
+	if room := c.max - len(c.captured); room > 0 {
+		c.captured = append(c.captured, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// newCorrelationID returns a random ID for requests without one
+func newCorrelationID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b[:])
+}
+
 // shouldSkipPath checks if a path should be skipped from logging
 func (l *LoggerMiddleware) shouldSkipPath(path string) bool {
 	for _, skipPath := range l.config.SkipPaths {
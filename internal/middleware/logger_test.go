@@ -0,0 +1,245 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoggerMiddleware_CaptureHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	loggerMiddleware, err := NewLoggerMiddleware(logger, LoggerConfig{
+		CaptureHeaders: []string{"X-Request-ID", "Authorization", "X-Session(redacted)", "X-Missing", "Content-Type"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger middleware: %v", err)
+	}
+
+	handler := NewChain(loggerMiddleware).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Session", "session-value")
+	req.Header.Set("X-Not-Captured", "ignored")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry struct {
+		Status          int               `json:"status"`
+		RequestHeaders  map[string]string `json:"request_headers"`
+		ResponseHeaders map[string]string `json:"response_headers"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log entry %q: %v", buf.String(), err)
+	}
+
+	if entry.Status != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, entry.Status)
+	}
+
+	expectedRequest := map[string]string{
+		"X-Request-Id":  "abc-123",
+		"Authorization": "[REDACTED]",
+		"X-Session":     "[REDACTED]",
+	}
+	if len(entry.RequestHeaders) != len(expectedRequest) {
+		t.Errorf("expected request headers %v, got %v", expectedRequest, entry.RequestHeaders)
+	}
+	for name, want := range expectedRequest {
+		if got := entry.RequestHeaders[name]; got != want {
+			t.Errorf("expected request header %s %q, got %q", name, want, got)
+		}
+	}
+
+	if got := entry.ResponseHeaders["Content-Type"]; got != "application/json" || len(entry.ResponseHeaders) != 1 {
+		t.Errorf("expected only Content-Type in response headers, got %v", entry.ResponseHeaders)
+	}
+}
+
+func TestLoggerMiddleware_NoCapturedHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	loggerMiddleware, err := NewLoggerMiddleware(logger, LoggerConfig{CaptureHeaders: []string{"X-Request-ID"}})
+	if err != nil {
+		t.Fatalf("failed to create logger middleware: %v", err)
+	}
+
+	handler := NewChain(loggerMiddleware).ThenFunc(mockFinalHandler)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log entry %q: %v", buf.String(), err)
+	}
+	if _, ok := entry["request_headers"]; ok {
+		t.Errorf("expected no request_headers group, got %v", entry["request_headers"])
+	}
+	if _, ok := entry["response_headers"]; ok {
+		t.Errorf("expected no response_headers group, got %v", entry["response_headers"])
+	}
+}
+
+func TestParseCaptureHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []capturedHeader
+		wantErr bool
+	}{
+		{
+			name:    "plain and redacted entries",
+			entries: []string{"x-request-id", "X-Token (redacted)", "cookie"},
+			want: []capturedHeader{
+				{name: "X-Request-Id", redact: false},
+				{name: "X-Token", redact: true},
+				{name: "Cookie", redact: true},
+			},
+		},
+		{
+			name:    "case-insensitive suffix",
+			entries: []string{"X-Secret(REDACTED)"},
+			want:    []capturedHeader{{name: "X-Secret", redact: true}},
+		},
+		{
+			name:    "empty name",
+			entries: []string{"(redacted)"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown modifier",
+			entries: []string{"X-Token(masked)"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCaptureHeaders(tt.entries)
+			if tt.wantErr {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || configErr.Field != "capture_headers" {
+					t.Fatalf("expected capture_headers ConfigError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerMiddleware_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	loggerMiddleware, err := newLoggerMiddleware(slog.New(slog.DiscardHandler), LoggerConfig{
+		Format:            LogFormatJSON,
+		CaptureHeaders:    []string{"Content-Type"},
+		CaptureBodyBytes:  8,
+		CorrelationHeader: "x-request-id",
+	}, &buf)
+	if err != nil {
+		t.Fatalf("failed to create logger middleware: %v", err)
+	}
+
+	handler := NewChain(loggerMiddleware).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Seen-Request-ID", r.Header.Get("X-Request-ID"))
+		w.Write([]byte(`{"received": ` + strconv.Itoa(len(body)) + `}`))
+	})
+
+	type bodyEntry struct {
+		Snippet   string `json:"snippet"`
+		Size      int    `json:"size"`
+		Truncated bool   `json:"truncated"`
+	}
+	type logEntry struct {
+		Msg           string     `json:"msg"`
+		Status        int        `json:"status"`
+		CorrelationID string     `json:"correlation_id"`
+		RequestBody   *bodyEntry `json:"request_body"`
+		ResponseBody  *bodyEntry `json:"response_body"`
+	}
+
+	// Requests with an ID keep it, and their bodies are cut at the limit
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"id":1}`))
+	req.Header.Set("X-Request-ID", "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var entry logEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log entry %q: %v", buf.String(), err)
+	}
+	if entry.Msg != "request processed" || entry.Status != http.StatusOK {
+		t.Errorf("expected a processed request with status 200, got %+v", entry)
+	}
+	if entry.CorrelationID != "abc-123" || rec.Header().Get("X-Request-ID") != "abc-123" {
+		t.Errorf("expected correlation ID abc-123 logged and returned, got %q and %q", entry.CorrelationID, rec.Header().Get("X-Request-ID"))
+	}
+	if entry.RequestBody == nil || *entry.RequestBody != (bodyEntry{Snippet: `{"id":1}`, Size: 8}) {
+		t.Errorf("expected the whole request body, got %+v", entry.RequestBody)
+	}
+	if entry.ResponseBody == nil || *entry.ResponseBody != (bodyEntry{Snippet: `{"receiv`, Size: 15, Truncated: true}) {
+		t.Errorf("expected a truncated response body, got %+v", entry.ResponseBody)
+	}
+
+	// Requests without an ID get one, which the handler sees too
+	buf.Reset()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/orders", nil))
+
+	entry = logEntry{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log entry %q: %v", buf.String(), err)
+	}
+	if entry.CorrelationID == "" || rec.Header().Get("X-Seen-Request-ID") != entry.CorrelationID {
+		t.Errorf("expected a generated correlation ID passed to the handler, got %q and %q", entry.CorrelationID, rec.Header().Get("X-Seen-Request-ID"))
+	}
+	if entry.RequestBody != nil {
+		t.Errorf("expected no request body, got %+v", entry.RequestBody)
+	}
+}
+
+func TestNewLoggerMiddleware_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LoggerConfig
+		field  string
+	}{
+		{name: "unknown format", config: LoggerConfig{Format: "xml"}, field: "format"},
+		{name: "negative body capture", config: LoggerConfig{CaptureBodyBytes: -1}, field: "capture_body_bytes"},
+		{name: "invalid correlation header", config: LoggerConfig{CorrelationHeader: "X Request"}, field: "correlation_header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLoggerMiddleware(slog.New(slog.DiscardHandler), tt.config)
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || configErr.Field != tt.field {
+				t.Errorf("expected %s ConfigError, got %v", tt.field, err)
+			}
+		})
+	}
+}
@@ -6,16 +6,12 @@ import (
 	"net/http"
 
 	"github.com/justinas/alice"
+
+	middlewarepkg "github.com/patrickdappollonio/mockingjay/pkg/middleware"
 )
 
 // Middleware represents a configurable middleware component
-type Middleware interface {
-	// Name returns the middleware name for logging and identification
-	Name() string
-
-	// Handler returns the standard Go middleware handler
-	Handler() func(http.Handler) http.Handler
-}
+type Middleware = middlewarepkg.Middleware
 
 // ResponseWriter wraps http.ResponseWriter to capture response metadata
 // and implements all the optional interfaces that http.ResponseWriter may support
@@ -24,6 +20,8 @@ type ResponseWriter struct {
 	status      int
 	size        int
 	wroteHeader bool
+	captureMax  int    // Bytes of the body kept in captured, 0 to keep none
+	captured    []byte // Start of the body written, when capturing
 }
 
 // NewResponseWriter creates a new ResponseWriter wrapper
@@ -49,6 +47,17 @@ func (rw *ResponseWriter) Written() bool {
 	return rw.wroteHeader
 }
 
+// CaptureBody keeps up to limit bytes of the body written from now on, for
+// CapturedBody
+func (rw *ResponseWriter) CaptureBody(limit int) {
+	rw.captureMax = limit
+}
+
+// CapturedBody returns the start of the body kept since CaptureBody was called
+func (rw *ResponseWriter) CapturedBody() []byte {
+	return rw.captured
+}
+
 // WriteHeader captures the status code and calls the underlying WriteHeader
 func (rw *ResponseWriter) WriteHeader(code int) {
 	if rw.wroteHeader {
@@ -66,9 +75,18 @@ func (rw *ResponseWriter) Write(b []byte) (int, error) {
 	}
 	n, err := rw.ResponseWriter.Write(b)
 	rw.size += n
+	if room := rw.captureMax - len(rw.captured); room > 0 {
+		rw.captured = append(rw.captured, b[:min(n, room)]...)
+	}
 	return n, err
 }
 
+// Unwrap returns the underlying ResponseWriter, letting http.ResponseController
+// reach features like write deadlines that this wrapper doesn't implement
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Hijack implements http.Hijacker interface
 func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
@@ -107,3 +107,20 @@ func TestEmptyChain(t *testing.T) {
 		t.Error("final handler header not set")
 	}
 }
+
+func TestResponseWriter_Unwrap(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rw := NewResponseWriter(rr)
+
+	if rw.Unwrap() != rr {
+		t.Error("expected Unwrap to return the wrapped writer")
+	}
+
+	// ResponseController reaches the recorder's Flush through the wrapper
+	if err := http.NewResponseController(rw).Flush(); err != nil {
+		t.Errorf("expected flush to succeed, got %v", err)
+	}
+	if !rr.Flushed {
+		t.Error("expected the wrapped writer to be flushed")
+	}
+}
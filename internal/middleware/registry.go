@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log/slog"
+
+	middlewarepkg "github.com/patrickdappollonio/mockingjay/pkg/middleware"
+)
+
+// Built-in middleware types
+func init() {
+	middlewarepkg.Register("cors", func(config CORSConfig, logger *slog.Logger) (Middleware, error) {
+		return NewCORSMiddleware(config, logger)
+	})
+
+	middlewarepkg.Register("logger", func(config LoggerConfig, logger *slog.Logger) (Middleware, error) {
+		return NewLoggerMiddleware(logger, config)
+	})
+
+	middlewarepkg.Register("basicauth", func(config BasicAuthConfig, _ *slog.Logger) (Middleware, error) {
+		if config.Username == "" {
+			return nil, NewConfigError("basicauth", "username", "is required")
+		}
+		if config.Password == "" {
+			return nil, NewConfigError("basicauth", "password", "is required")
+		}
+		return NewBasicAuthMiddleware(config)
+	})
+
+	middlewarepkg.Register("timeout", func(config TimeoutConfig, logger *slog.Logger) (Middleware, error) {
+		return NewTimeoutMiddleware(config, logger), nil
+	})
+
+	middlewarepkg.Register("bodylimit", func(config BodyLimitConfig, _ *slog.Logger) (Middleware, error) {
+		return NewBodyLimitMiddleware(config)
+	})
+}
@@ -1,10 +1,16 @@
 package middleware
 
 import (
+	"bufio"
 	"context"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
 )
 
 // TimeoutConfig represents timeout middleware configuration
@@ -47,6 +53,11 @@ func (m *TimeoutMiddleware) Handler() func(http.Handler) http.Handler {
 			// Replace the request context with our timeout context
 			r = r.WithContext(ctx)
 
+			// The handler writes through a guarded writer, so once the request
+			// times out, the middleware owns the response and late writes are
+			// dropped instead of racing with the 408
+			tw := newTimeoutWriter(w)
+
 			// Create a channel to track handler completion
 			done := make(chan struct{}, 1)
 
@@ -57,7 +68,7 @@ func (m *TimeoutMiddleware) Handler() func(http.Handler) http.Handler {
 					recover()
 					close(done)
 				}()
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(NewResponseWriter(tw), r)
 			}()
 
 			// Wait for either completion or timeout
@@ -66,7 +77,18 @@ func (m *TimeoutMiddleware) Handler() func(http.Handler) http.Handler {
 				// Handler completed normally
 				return
 			case <-ctx.Done():
-				// Timeout occurred - send 408 response
+				// Timeout occurred - send 408 response, unless the handler
+				// already started its own
+				if !tw.timeout() {
+					m.logger.Warn("request timeout after the response started",
+						"path", r.URL.Path,
+						"method", r.Method,
+						"timeout", m.config.Duration,
+						"remote_addr", r.RemoteAddr,
+					)
+					return
+				}
+
 				m.logger.Warn("request timeout",
 					"path", r.URL.Path,
 					"method", r.Method,
@@ -74,11 +96,121 @@ func (m *TimeoutMiddleware) Handler() func(http.Handler) http.Handler {
 					"remote_addr", r.RemoteAddr,
 				)
 
-				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-				w.WriteHeader(http.StatusRequestTimeout)
-				w.Write([]byte("408 Request Timeout\n\nThe request exceeded the configured timeout."))
+				problem.Write(w, r, http.StatusRequestTimeout, problem.CodeRequestTimeout,
+					"The request exceeded the configured timeout.",
+					"408 Request Timeout\n\nThe request exceeded the configured timeout.")
 				return
 			}
 		})
 	}
 }
+
+// timeoutWriter guards the ResponseWriter of a request from the handler once
+// the request times out. The handler gets headers of its own, copied to the
+// response when it writes the status, so setting them never races with the
+// middleware writing the 408.
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+
+	mu          sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+}
+
+// newTimeoutWriter creates a guarded writer for w, starting with the headers
+// set on it so far
+func newTimeoutWriter(w http.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{w: w, header: w.Header().Clone()}
+}
+
+// timeout hands the response over to the middleware, reporting whether it can
+// still write one, which it can't when the handler already wrote the status
+func (tw *timeoutWriter) timeout() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.timedOut = true
+	return !tw.wroteHeader
+}
+
+// Header returns the handler's headers
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader sends the handler's headers and status, unless the request
+// timed out
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeaderLocked(code)
+}
+
+// writeHeaderLocked sends the status; the caller must hold tw.mu
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+
+	dst := tw.w.Header()
+	clear(dst)
+	maps.Copy(dst, tw.header)
+	tw.w.WriteHeader(code)
+}
+
+// Write writes to the response, failing with http.ErrHandlerTimeout once the
+// request timed out
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+// Flush implements http.Flusher, doing nothing once the request timed out
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	if flusher, ok := tw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, handing the connection to the handler
+// unless the request timed out, after which the middleware owns it
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	hijacker, ok := tw.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		tw.wroteHeader = true // The middleware can't answer on a hijacked connection
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter, letting http.ResponseController
+// reach features like write deadlines. Writing features are implemented above,
+// so they stay guarded.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
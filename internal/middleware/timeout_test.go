@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
 )
 
 func TestTimeoutMiddleware(t *testing.T) {
@@ -106,3 +111,85 @@ func TestTimeoutMiddleware_Name(t *testing.T) {
 		t.Errorf("Expected middleware name 'timeout', got %q", name)
 	}
 }
+
+func TestTimeoutMiddleware_ProblemJSON(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	middleware := NewTimeoutMiddleware(TimeoutConfig{Duration: 20 * time.Millisecond}, logger)
+
+	// A handler that gives up silently once the request is cancelled
+	finalHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	handler := problem.Middleware(problem.FormatJSON, NewChain(middleware).Then(finalHandler))
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestTimeout {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestTimeout, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("Expected problem+json content type, got %q", ct)
+	}
+
+	var doc problem.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode problem document %q: %v", rec.Body.String(), err)
+	}
+	if doc.Code != problem.CodeRequestTimeout || doc.Instance != "/slow" {
+		t.Errorf("Unexpected problem document: %+v", doc)
+	}
+}
+
+func TestTimeoutMiddleware_LateWrites(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	middleware := NewTimeoutMiddleware(TimeoutConfig{Duration: 20 * time.Millisecond}, logger)
+
+	// A handler that ignores cancellation and writes after the timeout
+	written := make(chan error, 1)
+	finalHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("X-Late", "true")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte("late"))
+		written <- err
+	})
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	rec := httptest.NewRecorder()
+	NewChain(middleware).Then(finalHandler).ServeHTTP(rec, req)
+
+	if err := <-written; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("Expected late writes to fail with ErrHandlerTimeout, got %v", err)
+	}
+	if rec.Code != http.StatusRequestTimeout || rec.Header().Get("X-Late") != "" {
+		t.Errorf("Expected the 408 alone, got %d with headers %v", rec.Code, rec.Header())
+	}
+	if body := rec.Body.String(); strings.Contains(body, "late") {
+		t.Errorf("Expected the late write to be dropped, got %q", body)
+	}
+}
+
+func TestTimeoutMiddleware_StartedResponse(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	middleware := NewTimeoutMiddleware(TimeoutConfig{Duration: 20 * time.Millisecond}, logger)
+
+	// A handler that started its response before the timeout keeps its status
+	finalHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Started", "true")
+		w.WriteHeader(http.StatusAccepted)
+		<-r.Context().Done()
+	})
+
+	rec := httptest.NewRecorder()
+	NewChain(middleware).Then(finalHandler).ServeHTTP(rec, httptest.NewRequest("GET", "/slow", nil))
+
+	if rec.Code != http.StatusAccepted || rec.Header().Get("X-Started") != "true" {
+		t.Errorf("Expected the handler's 202 and headers, got %d with headers %v", rec.Code, rec.Header())
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected no 408 body after the response started, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,103 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Error response formats
+const (
+	FormatText = "text" // Plain text bodies (default)
+	FormatJSON = "json" // RFC 7807 problem+json documents
+)
+
+// ContentType is the media type of problem+json documents
+const ContentType = "application/problem+json"
+
+// Stable machine-readable error codes included in problem documents
+const (
+	CodeRouteNotFound       = "route_not_found"
+	CodeUnauthorized        = "unauthorized"
+	CodeRequestTimeout      = "request_timeout"
+	CodePayloadTooLarge     = "payload_too_large"
+	CodeInternalError       = "internal_error"
+	CodeTemplateError       = "template_error"
+	CodeBadGateway          = "bad_gateway"
+	CodeInjectedFault       = "injected_fault"
+	CodeInvalidTransition   = "invalid_transition"
+	CodeInvalidBatch        = "invalid_batch"
+	CodeInvalidQuery        = "invalid_query"
+	CodeUpgradeRequired     = "upgrade_required"
+	CodeInvalidHandshake    = "invalid_handshake"
+	CodeInvalidEncoding     = "invalid_encoding"
+	CodeUnknownEncoding     = "unknown_encoding"
+	CodeFileNotFound        = "file_not_found"
+	CodeQueueFull           = "queue_full"
+	CodeInvalidTime         = "invalid_time"
+	CodeIdempotencyConflict = "idempotency_conflict"
+)
+
+// Problem represents an RFC 7807 problem details document
+type Problem struct {
+	Type     string `json:"type"`               // Problem type URI ("about:blank" for plain HTTP errors)
+	Title    string `json:"title"`              // Short summary of the problem type
+	Status   int    `json:"status"`             // HTTP status code
+	Detail   string `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string `json:"instance,omitempty"` // Request path the problem occurred on
+	Code     string `json:"code"`               // Stable machine-readable error code
+}
+
+// New creates a Problem for the given status code
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// formatKey is the context key holding the error format of a request
+type formatKey struct{}
+
+// WithFormat returns a context carrying the error format for built-in errors
+func WithFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, formatKey{}, format)
+}
+
+// FormatFromContext returns the error format carried by ctx, defaulting to text
+func FormatFromContext(ctx context.Context) string {
+	if format, ok := ctx.Value(formatKey{}).(string); ok && format != "" {
+		return format
+	}
+	return FormatText
+}
+
+// Middleware sets the error format on every request passing through it, so
+// built-in errors written further down the chain use that format
+func Middleware(format string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithFormat(r.Context(), format)))
+	})
+}
+
+// Write sends a built-in error response in the request's error format. The
+// text body is sent as-is in text mode; in JSON mode a problem document is
+// built from the status, code and detail instead.
+func Write(w http.ResponseWriter, r *http.Request, status int, code, detail, text string) {
+	if FormatFromContext(r.Context()) != FormatJSON {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(text))
+		return
+	}
+
+	p := New(status, code, detail)
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(p) // Headers are already sent, nothing else to do on failure
+}
@@ -0,0 +1,88 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		expectedType string
+		expectedBody string
+	}{
+		{
+			name:         "default is text",
+			format:       "",
+			expectedType: "text/plain; charset=utf-8",
+			expectedBody: "404 Not Found: no route matches GET /missing",
+		},
+		{
+			name:         "explicit text",
+			format:       FormatText,
+			expectedType: "text/plain; charset=utf-8",
+			expectedBody: "404 Not Found: no route matches GET /missing",
+		},
+		{
+			name:         "json",
+			format:       FormatJSON,
+			expectedType: ContentType,
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"detail":"no route matches GET /missing","instance":"/missing","code":"route_not_found"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/missing", nil)
+			if tt.format != "" {
+				req = req.WithContext(WithFormat(req.Context(), tt.format))
+			}
+			rr := httptest.NewRecorder()
+
+			Write(rr, req, http.StatusNotFound, CodeRouteNotFound, "no route matches GET /missing", "404 Not Found: no route matches GET /missing")
+
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.expectedType {
+				t.Errorf("expected Content-Type %q, got %q", tt.expectedType, got)
+			}
+			if got := rr.Body.String(); got != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, got)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	handler := Middleware(FormatJSON, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FormatFromContext(r.Context())
+		Write(w, r, http.StatusRequestTimeout, CodeRequestTimeout, "too slow", "408 Request Timeout")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/slow", nil))
+
+	if seen != FormatJSON {
+		t.Errorf("expected format %q in context, got %q", FormatJSON, seen)
+	}
+
+	var p Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if p.Status != http.StatusRequestTimeout || p.Code != CodeRequestTimeout || p.Title != "Request Timeout" || p.Instance != "/slow" {
+		t.Errorf("unexpected problem document: %+v", p)
+	}
+}
+
+func TestFormatFromContext_Default(t *testing.T) {
+	if got := FormatFromContext(context.Background()); got != FormatText {
+		t.Errorf("expected default format %q, got %q", FormatText, got)
+	}
+}
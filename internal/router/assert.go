@@ -0,0 +1,38 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// ResponseAssertion represents the checks a route's rendered body must pass
+// before it's sent
+type ResponseAssertion struct {
+	JSONValid bool // Whether the body must be valid JSON
+	MaxBytes  int  // Largest body allowed, 0 for no limit
+}
+
+// compileResponseAssertion converts the checks of a route's rendered bodies
+func compileResponseAssertion(ac *config.AssertResponseConfig) *ResponseAssertion {
+	return &ResponseAssertion{JSONValid: ac.JSONValid, MaxBytes: ac.MaxBytes}
+}
+
+// Check returns an error describing the first check body fails, or nil when
+// it passes them all or there are none
+func (a *ResponseAssertion) Check(body []byte) error {
+	if a == nil {
+		return nil
+	}
+
+	if a.MaxBytes > 0 && len(body) > a.MaxBytes {
+		return fmt.Errorf("body is %d bytes, over the limit of %d", len(body), a.MaxBytes)
+	}
+	if a.JSONValid {
+		if err := json.Unmarshal(body, new(json.RawMessage)); err != nil {
+			return fmt.Errorf("body isn't valid JSON: %w", err)
+		}
+	}
+	return nil
+}
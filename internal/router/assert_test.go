@@ -0,0 +1,49 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_AssertResponse(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/users", Method: "GET", Template: "{}", AssertResponse: &config.AssertResponseConfig{JSONValid: true, MaxBytes: 16}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.AssertResponse == nil || *route.AssertResponse != (ResponseAssertion{JSONValid: true, MaxBytes: 16}) {
+		t.Errorf("Expected the route's assertions, got %+v", route.AssertResponse)
+	}
+}
+
+func TestResponseAssertion_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		assertion   *ResponseAssertion
+		body        string
+		errContains string
+	}{
+		{name: "no assertion", assertion: nil, body: "not json"},
+		{name: "valid json", assertion: &ResponseAssertion{JSONValid: true}, body: `{"id": 1}`},
+		{name: "invalid json", assertion: &ResponseAssertion{JSONValid: true}, body: `{"id": 1,}`, errContains: "body isn't valid JSON"},
+		{name: "empty body", assertion: &ResponseAssertion{JSONValid: true}, body: "", errContains: "body isn't valid JSON"},
+		{name: "within limit", assertion: &ResponseAssertion{MaxBytes: 4}, body: "1234"},
+		{name: "over limit", assertion: &ResponseAssertion{MaxBytes: 4}, body: "12345", errContains: "body is 5 bytes, over the limit of 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.assertion.Check([]byte(tt.body))
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package router
+
+import "github.com/patrickdappollonio/mockingjay/internal/config"
+
+// Batch represents a compiled batch endpoint, which serves the sub-requests
+// of a batch request with the other routes
+type Batch struct {
+	MaxRequests int // Most sub-requests in one batch
+}
+
+// compileBatch applies the defaults of a batch endpoint
+func compileBatch(bc *config.BatchConfig) *Batch {
+	return &Batch{MaxRequests: bc.GetMaxRequests()}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Batch(t *testing.T) {
+	tests := []struct {
+		name     string
+		batch    config.BatchConfig
+		expected int
+	}{
+		{name: "default maximum", expected: config.DefaultBatchMaxRequests},
+		{name: "custom maximum", batch: config.BatchConfig{MaxRequests: 50}, expected: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/$batch", Method: "POST", Batch: &tt.batch})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Batch == nil || route.Batch.MaxRequests != tt.expected {
+				t.Errorf("Expected a batch of at most %d requests, got %+v", tt.expected, route.Batch)
+			}
+			if route.Tmpl != nil || route.TemplateSource != "batch" {
+				t.Errorf("Expected a batch route without template, got source %q", route.TemplateSource)
+			}
+		})
+	}
+}
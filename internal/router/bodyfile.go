@@ -0,0 +1,42 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// BodyFile represents a file a route answers with as-is
+type BodyFile struct {
+	Path        string // Path of the file, as configured
+	Data        []byte // Contents of the file, read when the route is compiled
+	ContentType string // Content type detected from the file's extension or contents
+}
+
+// compileBodyFile reads the file a route answers with and detects its
+// content type
+func compileBodyFile(route *Route, routeConfig config.RouteConfig) error {
+	data, err := os.ReadFile(routeConfig.BodyFile)
+	if err != nil {
+		return err
+	}
+
+	route.BodyFile = &BodyFile{
+		Path:        routeConfig.BodyFile,
+		Data:        data,
+		ContentType: detectContentType(routeConfig.BodyFile, data),
+	}
+	return nil
+}
+
+// detectContentType returns the content type of a file by its extension,
+// sniffing its contents for extensions that aren't known
+func detectContentType(path string, data []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
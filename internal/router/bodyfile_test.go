@@ -0,0 +1,62 @@
+package router
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_BodyFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"logo.png":       "\x89PNG\r\n\x1a\n{{ not a template",
+		"report.pdf":     "%PDF-1.7",
+		"avatar.fixture": "\x89PNG\r\n\x1a\n",
+		"blob":           "\x00\x01\x02",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		file        string
+		contentType string
+	}{
+		{file: "logo.png", contentType: "image/png"},
+		{file: "report.pdf", contentType: "application/pdf"},
+		{file: "avatar.fixture", contentType: "image/png"},
+		{file: "blob", contentType: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/file", Method: "GET", BodyFile: path})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if route.BodyFile == nil || string(route.BodyFile.Data) != files[tt.file] {
+				t.Fatalf("Expected the file's contents, got %+v", route.BodyFile)
+			}
+			if route.BodyFile.ContentType != tt.contentType {
+				t.Errorf("Expected content type %q, got %q", tt.contentType, route.BodyFile.ContentType)
+			}
+			if route.Tmpl != nil || route.TemplateSource != path {
+				t.Errorf("Expected no template and source %q, got %v and %q", path, route.Tmpl, route.TemplateSource)
+			}
+		})
+	}
+}
+
+func TestCompileRoute_MissingBodyFile(t *testing.T) {
+	_, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/file", Method: "GET", BodyFile: filepath.Join(t.TempDir(), "missing.png")})
+	if err == nil || !strings.Contains(err.Error(), "failed to read body_file") {
+		t.Errorf("Expected a read error, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// ClientCertMatcher represents compiled rules matching the TLS client
+// certificate of requests
+type ClientCertMatcher struct {
+	CN  *HeaderMatcher // Matches the subject common name (nil to accept any)
+	SAN *HeaderMatcher // Matches any subject alternative name (nil to accept any)
+}
+
+// Match checks if the request was sent with a client certificate matching
+// every rule
+func (m *ClientCertMatcher) Match(req *http.Request) bool {
+	cert := templatepkg.NewClientCertInfo(req.TLS)
+	if cert == nil {
+		return false
+	}
+
+	if m.CN != nil && !m.CN.Match(cert.CommonName) {
+		return false
+	}
+	if m.SAN != nil && !slices.ContainsFunc(cert.SANs, m.SAN.Match) {
+		return false
+	}
+	return true
+}
+
+// compileClientCertMatcher compiles the client certificate rules of a route
+func compileClientCertMatcher(mc *config.ClientCertMatchConfig) (*ClientCertMatcher, error) {
+	cn, err := compileValueMatcher(mc.CN)
+	if err != nil {
+		return nil, err
+	}
+	san, err := compileValueMatcher(mc.SAN)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientCertMatcher{CN: cn, SAN: san}, nil
+}
+
+// compileValueMatcher compiles a literal or /regex/ value, returning nil for
+// an empty one
+func compileValueMatcher(value string) (*HeaderMatcher, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if !isHeaderRegexPattern(value) {
+		return &HeaderMatcher{Literal: value}, nil
+	}
+
+	regex, err := regexp.Compile(extractHeaderRegexPattern(value))
+	if err != nil {
+		return nil, err
+	}
+	return &HeaderMatcher{IsRegex: true, Regex: regex}, nil
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestClientCertMatcher_Match(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "billing"},
+		DNSNames: []string{"billing.internal", "billing.example.org"},
+	}
+
+	tests := []struct {
+		name     string
+		match    config.ClientCertMatchConfig
+		certs    []*x509.Certificate
+		expected bool
+	}{
+		{name: "literal common name", match: config.ClientCertMatchConfig{CN: "billing"}, certs: []*x509.Certificate{cert}, expected: true},
+		{name: "other common name", match: config.ClientCertMatchConfig{CN: "orders"}, certs: []*x509.Certificate{cert}},
+		{name: "regex on any SAN", match: config.ClientCertMatchConfig{SAN: "/\\.example\\.org$/"}, certs: []*x509.Certificate{cert}, expected: true},
+		{name: "both rules", match: config.ClientCertMatchConfig{CN: "/^bill/", SAN: "billing.internal"}, certs: []*x509.Certificate{cert}, expected: true},
+		{name: "one rule failing", match: config.ClientCertMatchConfig{CN: "billing", SAN: "orders.internal"}, certs: []*x509.Certificate{cert}},
+		{name: "no certificate", match: config.ClientCertMatchConfig{CN: "billing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := compileClientCertMatcher(&tt.match)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			req := httptest.NewRequest("GET", "https://localhost/invoices", nil)
+			req.TLS = &tls.ConnectionState{PeerCertificates: tt.certs}
+			if got := matcher.Match(req); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileClientCertMatcher_InvalidRegex(t *testing.T) {
+	if _, err := compileClientCertMatcher(&config.ClientCertMatchConfig{SAN: "/[a-/"}); err == nil {
+		t.Error("Expected an error for an invalid regex")
+	}
+}
+
+func TestRoute_MatchSNI(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/invoices", Method: "GET", Template: "[]", MatchSNI: "/^api\\./"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tls      *tls.ConnectionState
+		expected bool
+	}{
+		{name: "matching name", tls: &tls.ConnectionState{ServerName: "api.example.com"}, expected: true},
+		{name: "other name", tls: &tls.ConnectionState{ServerName: "www.example.com"}},
+		{name: "no name", tls: &tls.ConnectionState{}},
+		{name: "plain HTTP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "https://localhost/invoices", nil)
+			req.TLS = tt.tls
+			if _, got := route.MatchRequest(req); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -2,17 +2,22 @@ package router
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 	"text/template"
 
 	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/matcher"
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
 	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
 )
 
 // Compiler handles the compilation of route configurations into executable routes
 type Compiler struct {
-	engine *templatepkg.Engine
+	engine     *templatepkg.Engine
+	middleware *middleware.Factory // Creates the middleware of routes, nil for one logging to the default logger
 }
 
 // NewCompiler creates a new route compiler with a template engine using default delimiters
@@ -22,27 +27,48 @@ func NewCompiler() *Compiler {
 	}
 }
 
-// NewCompilerWithConfig creates a new route compiler with a template engine configured from Config
-func NewCompilerWithConfig(cfg *config.Config) *Compiler {
-	delimiters := cfg.Template.Delimiters.GetWithDefaults()
+// NewCompilerWithConfig creates a new route compiler with a template engine
+// configured from Config, failing when its partials can't be parsed
+func NewCompilerWithConfig(cfg *config.Config) (*Compiler, error) {
+	engine, err := cfg.Template.NewEngine()
+	if err != nil {
+		return nil, err
+	}
+	return &Compiler{engine: engine}, nil
+}
+
+// NewCompilerWithEngine creates a new route compiler that reuses an existing template engine
+func NewCompilerWithEngine(engine *templatepkg.Engine) *Compiler {
 	return &Compiler{
-		engine: templatepkg.NewEngineWithDelimiters(delimiters.Left, delimiters.Right),
+		engine: engine,
 	}
 }
 
 // CompileRoute compiles a RouteConfig into an executable Route
 func (c *Compiler) CompileRoute(routeConfig config.RouteConfig) (*Route, error) {
+	// Templates of remote includes can't read local files, whatever field
+	// they're in
+	if routeConfig.FromRemote() {
+		c = &Compiler{engine: c.engine.WithoutLocalFiles(), middleware: c.middleware}
+	}
+
 	route := &Route{
-		Pattern: routeConfig.Path,
-		Method:  routeConfig.GetNormalizedMethod(),
+		Pattern:  routeConfig.Path,
+		Method:   routeConfig.GetNormalizedMethod(),
+		Priority: routeConfig.Priority,
 	}
+	route.Info = templatepkg.RouteInfo{Pattern: route.Pattern, Method: route.Method}
 
-	// Determine if this is a regex pattern
-	route.IsRegexp = routeConfig.IsRegexPattern()
+	// Determine if this is a regex pattern, which path templates like
+	// "/users/{id}" and prefixes are matched with
+	route.IsRegexp = routeConfig.UsesPathRegex()
 
 	// Compile regex if needed
 	if route.IsRegexp {
-		pattern := routeConfig.GetRegexPattern()
+		pattern, err := routeConfig.PathRegex()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile path template %q: %w", routeConfig.Path, err)
+		}
 		regex, err := regexp.Compile(pattern)
 		if err != nil {
 			return nil, fmt.Errorf("failed to compile regex pattern %q: %w", pattern, err)
@@ -55,12 +81,200 @@ func (c *Compiler) CompileRoute(routeConfig config.RouteConfig) (*Route, error)
 		return nil, fmt.Errorf("failed to compile header matchers for route %q: %w", routeConfig.Path, err)
 	}
 
+	// Compile cookie matching patterns
+	cookies, err := compileCookieMatchers(routeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile cookie matchers for route %q: %w", routeConfig.Path, err)
+	}
+	route.MatchCookies = cookies
+
+	// Compile client certificate matching patterns
+	if routeConfig.MatchClientCert != nil {
+		matcher, err := compileClientCertMatcher(routeConfig.MatchClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile client certificate matcher for route %q: %w", routeConfig.Path, err)
+		}
+		route.MatchClientCert = matcher
+	}
+
+	// Compile the server name matching pattern
+	sni, err := compileValueMatcher(routeConfig.MatchSNI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile server name matcher for route %q: %w", routeConfig.Path, err)
+	}
+	route.MatchSNI = sni
+
+	// Build the matchers of registered kinds
+	for _, name := range slices.Sorted(maps.Keys(routeConfig.Match)) {
+		m, err := matcher.Build(name, routeConfig.Match[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %q matcher for route %q: %w", name, routeConfig.Path, err)
+		}
+		route.Matchers = append(route.Matchers, m)
+	}
+
 	// Compile response header templates
 	if err := c.compileResponseHeaders(route, routeConfig); err != nil {
 		return nil, fmt.Errorf("failed to compile response headers for route %q: %w", routeConfig.Path, err)
 	}
 
-	// Compile the template
+	// Create the route's own middleware
+	if err := c.compileMiddleware(route, routeConfig); err != nil {
+		return nil, fmt.Errorf("failed to create middleware for route %q: %w", routeConfig.Path, err)
+	}
+
+	// Set the artificial response delay, and those applied while it's written
+	route.Delay = compileDelay(routeConfig.Delay)
+	route.DelayFirstByte = compileDelay(routeConfig.DelayFirstByte)
+	route.DelayBetweenChunks = compileDelay(routeConfig.DelayBetweenChunks)
+
+	// Set the limit on requests served at once
+	route.Concurrency = compileConcurrency(routeConfig.Concurrency)
+
+	// Set how responses are remembered by idempotency key
+	route.Idempotency = compileIdempotency(routeConfig.Idempotency)
+
+	// Set the faults injected into the route's responses
+	if len(routeConfig.Faults) > 0 {
+		route.Faults = compileFaults(routeConfig.Faults)
+	}
+
+	// Set how the route's responses are compressed
+	if routeConfig.Compression != nil {
+		route.Compression = compileCompression(routeConfig.Compression)
+	}
+
+	// Set the checksums sent of the route's bodies
+	route.Checksums = routeConfig.EmitChecksums
+
+	// Set the checks the route's rendered bodies must pass
+	if routeConfig.AssertResponse != nil {
+		route.AssertResponse = compileResponseAssertion(routeConfig.AssertResponse)
+	}
+
+	// Set how the route answers HEAD requests
+	if routeConfig.Head != nil {
+		route.Head = compileHead(routeConfig.Head)
+	}
+
+	// Set how the route streams its template output
+	if routeConfig.Stream != nil {
+		route.Stream = compileStream(routeConfig.Stream)
+	}
+
+	// Set how the route's requests are parsed from the connection
+	if routeConfig.Raw != nil {
+		route.Raw = compileRaw(routeConfig.Raw)
+	}
+
+	// Set the token the route requires
+	if routeConfig.RequireToken != nil {
+		route.RequireToken = compileTokenRequirement(routeConfig.RequireToken)
+	}
+
+	// Compile the transaction transition the route performs
+	if routeConfig.Transaction != nil {
+		if err := c.compileTransactionStep(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to compile transaction for route %q: %w", routeConfig.Path, err)
+		}
+	}
+
+	// Set the route's clock skew
+	route.ClockSkew = routeConfig.ClockSkew
+
+	// Set the encoding rendered bodies are decoded from
+	route.BodyEncoding = routeConfig.BodyEncoding
+
+	// Seed the route's random functions and freeze its clock
+	if routeConfig.Deterministic != nil {
+		deterministic, err := compileDeterministic(routeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile deterministic settings for route %q: %w", routeConfig.Path, err)
+		}
+		route.Deterministic = deterministic
+	}
+
+	// Set the route's call expectations
+	if routeConfig.Expect != nil {
+		route.Expect = compileExpectation(routeConfig.Expect)
+	}
+
+	// Compile the route's deprecation
+	deprecation, err := compileDeprecation(routeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile deprecation for route %q: %w", routeConfig.Path, err)
+	}
+	route.Deprecation = deprecation
+
+	// WebSocket endpoints script their messages and answer client messages
+	// with the route's template, if any
+	if routeConfig.WebSocket != nil {
+		if err := c.compileWebSocket(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to compile WebSocket for route %q: %w", routeConfig.Path, err)
+		}
+		route.TemplateSource = "websocket"
+		return route, nil
+	}
+
+	// Batch endpoints dispatch their sub-requests and have no templates
+	if routeConfig.Batch != nil {
+		route.Batch = compileBatch(routeConfig.Batch)
+		route.TemplateSource = "batch"
+		return route, nil
+	}
+
+	// Proxied routes forward to their upstream and have no templates
+	if routeConfig.Proxy != nil {
+		if err := c.compileProxy(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to compile proxy for route %q: %w", routeConfig.Path, err)
+		}
+		route.TemplateSource = "proxy"
+		return route, nil
+	}
+
+	// Echoing routes answer with the request body and have no templates
+	if routeConfig.EchoBody != nil {
+		if err := c.compileEchoBody(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to compile echo_body for route %q: %w", routeConfig.Path, err)
+		}
+		route.TemplateSource = "echo"
+		return route, nil
+	}
+
+	// Body file routes answer with a file as-is and have no templates
+	if routeConfig.BodyFile != "" {
+		if err := compileBodyFile(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to read body_file for route %q: %w", routeConfig.Path, err)
+		}
+		route.TemplateSource = routeConfig.BodyFile
+		return route, nil
+	}
+
+	// Static directory routes serve files under their path and have no templates
+	if routeConfig.StaticDir != nil {
+		compileStaticDir(route, routeConfig)
+		route.TemplateSource = "static"
+		return route, nil
+	}
+
+	// Compile either the alternative responses or the single template
+	if len(routeConfig.Responses) > 0 {
+		if err := c.compileResponses(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to compile responses for route %q: %w", routeConfig.Path, err)
+		}
+		route.TemplateSource = "responses"
+		return route, nil
+	}
+
+	// Compile the variants picked by the route's selector
+	if routeConfig.Variants != nil {
+		if err := c.compileVariants(route, routeConfig); err != nil {
+			return nil, fmt.Errorf("failed to compile variants for route %q: %w", routeConfig.Path, err)
+		}
+		route.TemplateSource = "variants"
+		return route, nil
+	}
+
 	tmpl, err := c.compileTemplate(routeConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile template for route %q: %w", routeConfig.Path, err)
@@ -77,20 +291,29 @@ func (c *Compiler) CompileRoute(routeConfig config.RouteConfig) (*Route, error)
 	return route, nil
 }
 
-// compileTemplate compiles the template for a route configuration
+// compileTemplate compiles the template for a route configuration, escaping
+// what it prints for routes serving HTML
 func (c *Compiler) compileTemplate(routeConfig config.RouteConfig) (*template.Template, error) {
-	if routeConfig.Template != "" {
+	var tmpl *template.Template
+	var err error
+	switch {
+	case routeConfig.Template != "":
 		// Inline template
 		templateName := fmt.Sprintf("route_%s_%s", routeConfig.GetNormalizedMethod(), sanitizeTemplateName(routeConfig.Path))
-		return c.engine.CompileInlineTemplate(templateName, routeConfig.Template)
-	}
+		tmpl, err = c.engine.CompileInlineTemplate(templateName, routeConfig.Template)
 
-	if routeConfig.TemplateFile != "" {
+	case routeConfig.TemplateFile != "":
 		// File template
-		return c.engine.CompileFileTemplate(routeConfig.TemplateFile)
+		tmpl, err = c.engine.CompileFileTemplate(routeConfig.TemplateFile)
+
+	default:
+		return nil, fmt.Errorf("no template source specified")
+	}
+	if err != nil || !routeConfig.EscapeHTML {
+		return tmpl, err
 	}
 
-	return nil, fmt.Errorf("no template source specified")
+	return templatepkg.EscapeHTML(tmpl)
 }
 
 // CompileRoutes compiles multiple route configurations
@@ -231,7 +454,7 @@ func (c *Compiler) compileResponseHeaders(route *Route, routeConfig config.Route
 			sanitizeTemplateName(routeConfig.Path),
 			sanitizeTemplateName(headerName))
 
-		headerTemplate, err := c.engine.CompileInlineTemplate(templateName, headerValue)
+		headerTemplate, err := c.compileHeaderTemplate(templateName, headerValue)
 		if err != nil {
 			return fmt.Errorf("failed to compile response header template for %q: %w", headerName, err)
 		}
@@ -241,3 +464,33 @@ func (c *Compiler) compileResponseHeaders(route *Route, routeConfig config.Route
 
 	return nil
 }
+
+// CompileResponseHeaders compiles the response headers every route sends,
+// returning nil when there are none
+func (c *Compiler) CompileResponseHeaders(headers map[string]string) (map[string]*template.Template, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	compiled := make(map[string]*template.Template, len(headers))
+	for headerName, headerValue := range headers {
+		templateName := "response_header_global_" + sanitizeTemplateName(headerName)
+		headerTemplate, err := c.compileHeaderTemplate(templateName, headerValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile response header template for %q: %w", headerName, err)
+		}
+		compiled[canonicalizeHeaderName(headerName)] = headerTemplate
+	}
+
+	return compiled, nil
+}
+
+// compileHeaderTemplate compiles the value of a response header. Empty
+// values, which remove a header set by the global response headers or the
+// server, compile to a template rendering nothing.
+func (c *Compiler) compileHeaderTemplate(name, value string) (*template.Template, error) {
+	if strings.TrimSpace(value) == "" {
+		return template.New(name).Parse("")
+	}
+	return c.engine.CompileInlineTemplate(name, value)
+}
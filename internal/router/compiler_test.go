@@ -1,6 +1,8 @@
 package router
 
 import (
+	"maps"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -205,6 +207,89 @@ func TestCompiler_CompileRoute_RegexPaths(t *testing.T) {
 	}
 }
 
+func TestCompiler_CompileRoute_PathTemplates(t *testing.T) {
+	compiler := NewCompiler()
+
+	tests := []struct {
+		path       string
+		request    string
+		wantMatch  bool
+		wantParams map[string]string
+	}{
+		{path: "/users/{id}/posts/{postId}", request: "/users/42/posts/7", wantMatch: true, wantParams: map[string]string{"id": "42", "postId": "7"}},
+		{path: "/users/{id}/posts/{postId}", request: "/users/42/posts/7/comments", wantMatch: false},
+		{path: "/users/:id", request: "/users/ada", wantMatch: true, wantParams: map[string]string{"id": "ada"}},
+		{path: "/users/:id", request: "/users/", wantMatch: false},
+		{path: "/orders/{id:[0-9]+}", request: "/orders/123", wantMatch: true, wantParams: map[string]string{"id": "123"}},
+		{path: "/orders/{id:[0-9]+}", request: "/orders/abc", wantMatch: false},
+		{path: "/files/{path...}", request: "/files/docs/guide.txt", wantMatch: true, wantParams: map[string]string{"path": "docs/guide.txt"}},
+		{path: "/v1/{name}:cancel", request: "/v1/op-1:cancel", wantMatch: true, wantParams: map[string]string{"name": "op-1"}},
+		{path: "/api/v1/*", request: "/api/v1/users/42", wantMatch: true, wantParams: map[string]string{"wildcard": "users/42"}},
+		{path: "/api/v1/*", request: "/api/v1/", wantMatch: true, wantParams: map[string]string{"wildcard": ""}},
+		{path: "/api/v1/*", request: "/api/v1", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.request, func(t *testing.T) {
+			route, err := compiler.CompileRoute(config.RouteConfig{Path: tt.path, Method: "GET", Template: "ok"})
+			if err != nil {
+				t.Fatalf("CompileRoute() error = %v", err)
+			}
+			if !route.IsRegexp || route.Pattern != tt.path {
+				t.Errorf("Expected a regex route keeping its pattern %q, got %q (regex: %v)", tt.path, route.Pattern, route.IsRegexp)
+			}
+
+			req := httptest.NewRequest("GET", tt.request, nil)
+			match, ok := route.MatchRequest(req)
+			if ok != tt.wantMatch {
+				t.Fatalf("MatchRequest(%q) = %v, want %v", tt.request, ok, tt.wantMatch)
+			}
+			if ok && !maps.Equal(match.Params, tt.wantParams) {
+				t.Errorf("Expected params %v, got %v", tt.wantParams, match.Params)
+			}
+		})
+	}
+}
+
+func TestCompiler_CompileRoute_PrefixMatch(t *testing.T) {
+	compiler := NewCompiler()
+
+	tests := []struct {
+		path       string
+		request    string
+		wantMatch  bool
+		wantParams map[string]string
+	}{
+		{path: "/api", request: "/api", wantMatch: true},
+		{path: "/api", request: "/api/", wantMatch: true},
+		{path: "/api", request: "/api/v1/users", wantMatch: true},
+		{path: "/api", request: "/apis", wantMatch: false},
+		{path: "/", request: "/anything/at/all", wantMatch: true},
+		{path: "/tenants/{tenant}", request: "/tenants/acme/users", wantMatch: true, wantParams: map[string]string{"tenant": "acme"}},
+		{path: "/tenants/{tenant}", request: "/tenants", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.request, func(t *testing.T) {
+			route, err := compiler.CompileRoute(config.RouteConfig{Path: tt.path, Method: "GET", Template: "ok", PathMatch: config.PathMatchPrefix})
+			if err != nil {
+				t.Fatalf("CompileRoute() error = %v", err)
+			}
+			if !route.IsRegexp {
+				t.Errorf("Expected prefixes to be matched with a regex")
+			}
+
+			match, ok := route.MatchRequest(httptest.NewRequest("GET", tt.request, nil))
+			if ok != tt.wantMatch {
+				t.Fatalf("MatchRequest(%q) = %v, want %v", tt.request, ok, tt.wantMatch)
+			}
+			if ok && len(tt.wantParams) > 0 && !maps.Equal(match.Params, tt.wantParams) {
+				t.Errorf("Expected params %v, got %v", tt.wantParams, match.Params)
+			}
+		})
+	}
+}
+
 func TestCompiler_CompileRoute_InvalidRegexPatterns(t *testing.T) {
 	compiler := NewCompiler()
 
@@ -963,3 +1048,43 @@ func TestCompiler_CompileResponseHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestCompiler_CompileRoute_Info(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:     "/^/users/(?P<id>\\d+)$/",
+		Method:   "get",
+		Template: "ok",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if route.Info.Pattern != "/^/users/(?P<id>\\d+)$/" {
+		t.Errorf("Expected info pattern %q, got %q", "/^/users/(?P<id>\\d+)$/", route.Info.Pattern)
+	}
+	if route.Info.Method != "GET" {
+		t.Errorf("Expected info method %q, got %q", "GET", route.Info.Method)
+	}
+}
+
+func TestCompiler_CompileGlobalResponseHeaders(t *testing.T) {
+	compiler := NewCompiler()
+
+	headers, err := compiler.CompileResponseHeaders(map[string]string{"x-mock": "mockingjay", "X-Request-Id": "{{ .RequestID }}"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(headers) != 2 || headers["x-mock"] == nil || headers["x-request-id"] == nil {
+		t.Errorf("Expected canonical x-mock and x-request-id templates, got %v", headers)
+	}
+
+	if headers, err := compiler.CompileResponseHeaders(nil); err != nil || headers != nil {
+		t.Errorf("Expected no templates without headers, got %v, %v", headers, err)
+	}
+
+	if _, err := compiler.CompileResponseHeaders(map[string]string{"X-Broken": "{{ .Broken "}); err == nil || !strings.Contains(err.Error(), `"X-Broken"`) {
+		t.Errorf("Expected a compilation error naming the header, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Compression represents how a route compresses its responses
+type Compression struct {
+	Encoding  string // Content coding, "gzip" or "deflate"
+	Violation string // How content negotiation is broken, empty to follow it
+}
+
+// compileCompression applies the defaults of a route's compression
+func compileCompression(cc *config.CompressionConfig) *Compression {
+	return &Compression{Encoding: cc.GetEncoding(), Violation: cc.Violation}
+}
+
+// Accepted reports whether an Accept-Encoding header value allows the
+// encoding, either by name or through "*", with a non-zero quality
+func (c *Compression) Accepted(acceptEncoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(name), "q") {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		switch coding {
+		case c.Encoding:
+			// An explicit entry wins over the wildcard
+			return quality > 0
+		case "*":
+			wildcard = quality > 0
+		}
+	}
+	return wildcard
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Compression(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:        "/data",
+		Method:      "GET",
+		Template:    "{}",
+		Compression: &config.CompressionConfig{Violation: config.CompressionMislabel},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Compression == nil || route.Compression.Encoding != config.CompressionGzip || route.Compression.Violation != config.CompressionMislabel {
+		t.Errorf("Expected gzip compression with a mislabel violation, got %+v", route.Compression)
+	}
+}
+
+func TestCompression_Accepted(t *testing.T) {
+	tests := []struct {
+		name           string
+		encoding       string
+		acceptEncoding string
+		want           bool
+	}{
+		{name: "no header", encoding: "gzip", acceptEncoding: "", want: false},
+		{name: "listed", encoding: "gzip", acceptEncoding: "gzip, deflate, br", want: true},
+		{name: "listed with quality", encoding: "deflate", acceptEncoding: "gzip;q=1.0, deflate;q=0.5", want: true},
+		{name: "case insensitive", encoding: "gzip", acceptEncoding: "GZIP", want: true},
+		{name: "identity only", encoding: "gzip", acceptEncoding: "identity", want: false},
+		{name: "refused", encoding: "gzip", acceptEncoding: "gzip;q=0, identity", want: false},
+		{name: "wildcard", encoding: "gzip", acceptEncoding: "*", want: true},
+		{name: "wildcard with explicit refusal", encoding: "gzip", acceptEncoding: "*, gzip;q=0", want: false},
+		{name: "refused wildcard", encoding: "deflate", acceptEncoding: "gzip, *;q=0", want: false},
+		{name: "other encoding", encoding: "deflate", acceptEncoding: "gzip", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Compression{Encoding: tt.encoding}
+			if got := c.Accepted(tt.acceptEncoding); got != tt.want {
+				t.Errorf("Accepted(%q) = %v, want %v", tt.acceptEncoding, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Reasons a request can't get a slot of a route with limited concurrency
+var (
+	ErrQueueFull    = errors.New("too many requests are already waiting")
+	ErrQueueTimeout = errors.New("gave up waiting for a free slot")
+)
+
+// Concurrency limits how many requests a route serves at once. Requests over
+// the limit wait in line and get slots in the order they arrived, so a burst
+// of requests is served fairly instead of in whatever order goroutines wake
+// up.
+type Concurrency struct {
+	Limit        int           // Requests served at once
+	MaxQueue     int           // Requests allowed to wait (0 for no limit)
+	QueueTimeout time.Duration // Longest wait for a slot (0 to wait until the request is cancelled)
+
+	mu       sync.Mutex
+	inFlight int             // Requests holding a slot
+	waiters  []chan struct{} // Requests waiting for a slot, oldest first, closed when they get one
+}
+
+// Acquire waits for a free slot, returning how long the request waited. A
+// request that had to wait calls onQueued once, when it joins the line. It
+// fails with ErrQueueFull when the line is too long to join, ErrQueueTimeout
+// when it waited for QueueTimeout, or the context's error if the request is
+// cancelled first. Requests that get a slot must call Release when they're
+// done.
+func (c *Concurrency) Acquire(ctx context.Context, onQueued func()) (time.Duration, error) {
+	c.mu.Lock()
+	if c.inFlight < c.Limit && len(c.waiters) == 0 {
+		c.inFlight++
+		c.mu.Unlock()
+		return 0, nil
+	}
+	if c.MaxQueue > 0 && len(c.waiters) >= c.MaxQueue {
+		c.mu.Unlock()
+		return 0, ErrQueueFull
+	}
+	ready := make(chan struct{})
+	c.waiters = append(c.waiters, ready)
+	c.mu.Unlock()
+
+	onQueued()
+	start := time.Now()
+
+	var timeout <-chan time.Time
+	if c.QueueTimeout > 0 {
+		timer := time.NewTimer(c.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-ready:
+		return time.Since(start), nil
+	case <-timeout:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// Leave the line, unless a slot was handed over meanwhile, in which
+	// case it's passed on to the next request
+	c.mu.Lock()
+	if i := slices.Index(c.waiters, ready); i >= 0 {
+		c.waiters = slices.Delete(c.waiters, i, i+1)
+		c.mu.Unlock()
+	} else {
+		c.mu.Unlock()
+		c.Release()
+	}
+	return time.Since(start), err
+}
+
+// Release frees the slot of a request, handing it to the request that has
+// been waiting the longest
+func (c *Concurrency) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.waiters) > 0 {
+		close(c.waiters[0])
+		c.waiters = c.waiters[1:]
+		return
+	}
+	c.inFlight--
+}
+
+// compileConcurrency converts a concurrency configuration, returning nil when
+// it's unset
+func compileConcurrency(cc *config.ConcurrencyConfig) *Concurrency {
+	if cc == nil {
+		return nil
+	}
+	return &Concurrency{Limit: cc.Limit, MaxQueue: cc.MaxQueue, QueueTimeout: cc.QueueTimeout}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// waitForQueue waits until c has n requests waiting
+func waitForQueue(t *testing.T, c *Concurrency, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		queued := len(c.waiters)
+		c.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d requests to be waiting", n)
+}
+
+func TestConcurrency_FirstComeFirstServed(t *testing.T) {
+	c := &Concurrency{Limit: 1}
+	if wait, err := c.Acquire(context.Background(), func() { t.Error("Expected the first request not to wait") }); err != nil || wait != 0 {
+		t.Fatalf("Expected a free slot, got %s and %v", wait, err)
+	}
+
+	// Queue requests one at a time, so their arrival order is known
+	served := make(chan int, 3)
+	for i := range 3 {
+		go func() {
+			if _, err := c.Acquire(context.Background(), func() {}); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			served <- i
+		}()
+		waitForQueue(t, c, i+1)
+	}
+
+	for want := range 3 {
+		c.Release()
+		if got := <-served; got != want {
+			t.Fatalf("Expected request %d to be served next, got %d", want, got)
+		}
+	}
+	c.Release()
+
+	if c.inFlight != 0 || len(c.waiters) != 0 {
+		t.Errorf("Expected every slot to be free, got %d in flight and %d waiting", c.inFlight, len(c.waiters))
+	}
+}
+
+func TestConcurrency_QueueLimits(t *testing.T) {
+	c := &Concurrency{Limit: 1, MaxQueue: 1, QueueTimeout: 50 * time.Millisecond}
+	if _, err := c.Acquire(context.Background(), func() {}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	timedOut := make(chan error, 1)
+	go func() {
+		_, err := c.Acquire(context.Background(), func() {})
+		timedOut <- err
+	}()
+	waitForQueue(t, c, 1)
+
+	// The line is full
+	if _, err := c.Acquire(context.Background(), func() { t.Error("Expected a full line not to be joined") }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+
+	if err := <-timedOut; !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("Expected ErrQueueTimeout, got %v", err)
+	}
+	waitForQueue(t, c, 0)
+
+	// Cancelled requests leave the line
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := c.Acquire(ctx, func() {})
+		cancelled <- err
+	}()
+	waitForQueue(t, c, 1)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context's error, got %v", err)
+	}
+
+	c.Release()
+	if c.inFlight != 0 || len(c.waiters) != 0 {
+		t.Errorf("Expected every slot to be free, got %d in flight and %d waiting", c.inFlight, len(c.waiters))
+	}
+}
+
+func TestCompiler_CompileRoute_Concurrency(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:        "/slow",
+		Method:      "GET",
+		Template:    "ok",
+		Concurrency: &config.ConcurrencyConfig{Limit: 2, MaxQueue: 5, QueueTimeout: time.Second},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if c := route.Concurrency; c == nil || c.Limit != 2 || c.MaxQueue != 5 || c.QueueTimeout != time.Second {
+		t.Errorf("Unexpected compiled concurrency: %+v", route.Concurrency)
+	}
+
+	route, err = compiler.CompileRoute(config.RouteConfig{Path: "/fast", Method: "GET", Template: "ok"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Concurrency != nil {
+		t.Errorf("Expected no concurrency limit, got %+v", route.Concurrency)
+	}
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// compileCookieMatchers compiles cookie matching patterns for a route. Empty
+// values compile to a nil matcher, which only requires the cookie to be sent.
+func compileCookieMatchers(routeConfig config.RouteConfig) (map[string]*HeaderMatcher, error) {
+	if len(routeConfig.MatchCookies) == 0 {
+		return nil, nil
+	}
+
+	matchers := make(map[string]*HeaderMatcher, len(routeConfig.MatchCookies))
+	for name, value := range routeConfig.MatchCookies {
+		matcher, err := compileValueMatcher(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern %q for cookie %q: %w", extractHeaderRegexPattern(value), name, err)
+		}
+		matchers[name] = matcher
+	}
+	return matchers, nil
+}
+
+// matchesCookies checks if the request sends every cookie the route matches,
+// with matching values. Cookie names are case-sensitive.
+func (r *Route) matchesCookies(req *http.Request) bool {
+	for name, matcher := range r.MatchCookies {
+		cookie, err := req.Cookie(name)
+		if err != nil {
+			return false
+		}
+		if matcher != nil && !matcher.Match(cookie.Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestRoute_MatchCookies(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:         "/cart",
+		Method:       "GET",
+		Template:     "[]",
+		MatchCookies: map[string]string{"session": "/^[a-f0-9]+$/", "region": "eu", "consent": ""},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cookies  []*http.Cookie
+		expected bool
+	}{
+		{
+			name:     "all cookies match",
+			cookies:  []*http.Cookie{{Name: "session", Value: "abc123"}, {Name: "region", Value: "eu"}, {Name: "consent", Value: "yes"}},
+			expected: true,
+		},
+		{
+			name:     "empty value only requires the cookie",
+			cookies:  []*http.Cookie{{Name: "session", Value: "abc123"}, {Name: "region", Value: "eu"}, {Name: "consent", Value: ""}},
+			expected: true,
+		},
+		{
+			name:    "regex mismatch",
+			cookies: []*http.Cookie{{Name: "session", Value: "xyz"}, {Name: "region", Value: "eu"}, {Name: "consent", Value: "yes"}},
+		},
+		{
+			name:    "literal mismatch",
+			cookies: []*http.Cookie{{Name: "session", Value: "abc123"}, {Name: "region", Value: "us"}, {Name: "consent", Value: "yes"}},
+		},
+		{
+			name:    "names are case-sensitive",
+			cookies: []*http.Cookie{{Name: "Session", Value: "abc123"}, {Name: "region", Value: "eu"}, {Name: "consent", Value: "yes"}},
+		},
+		{
+			name:    "missing cookie",
+			cookies: []*http.Cookie{{Name: "session", Value: "abc123"}, {Name: "region", Value: "eu"}},
+		},
+		{
+			name: "no cookies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/cart", nil)
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			if _, got := route.MatchRequest(req); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileRoute_InvalidCookieRegex(t *testing.T) {
+	_, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:         "/cart",
+		Method:       "GET",
+		Template:     "[]",
+		MatchCookies: map[string]string{"session": "/[a-/"},
+	})
+	if err == nil {
+		t.Fatal("Expected an error for an invalid cookie regex")
+	}
+}
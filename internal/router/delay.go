@@ -0,0 +1,34 @@
+package router
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Delay represents the artificial latency applied before a route responds
+type Delay struct {
+	Min time.Duration // Shortest delay
+	Max time.Duration // Longest delay (equal to Min for a fixed delay)
+}
+
+// Duration returns the delay to apply to a request, picked uniformly at
+// random between Min and Max
+func (d *Delay) Duration() time.Duration {
+	if d == nil {
+		return 0
+	}
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	return d.Min + time.Duration(rand.Int64N(int64(d.Max-d.Min)+1))
+}
+
+// compileDelay converts a delay configuration, returning nil when it's unset
+func compileDelay(dc *config.DelayConfig) *Delay {
+	if dc == nil {
+		return nil
+	}
+	return &Delay{Min: dc.Min, Max: dc.Max}
+}
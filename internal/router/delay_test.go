@@ -0,0 +1,76 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestDelay_Duration(t *testing.T) {
+	var none *Delay
+	if got := none.Duration(); got != 0 {
+		t.Errorf("Expected no delay for nil Delay, got %s", got)
+	}
+
+	fixed := &Delay{Min: 200 * time.Millisecond, Max: 200 * time.Millisecond}
+	if got := fixed.Duration(); got != 200*time.Millisecond {
+		t.Errorf("Expected fixed delay of 200ms, got %s", got)
+	}
+
+	jitter := &Delay{Min: 100 * time.Millisecond, Max: 300 * time.Millisecond}
+	for range 100 {
+		if got := jitter.Duration(); got < jitter.Min || got > jitter.Max {
+			t.Fatalf("Expected delay between %s and %s, got %s", jitter.Min, jitter.Max, got)
+		}
+	}
+}
+
+func TestCompiler_CompileRoute_Delay(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:     "/slow",
+		Method:   "GET",
+		Template: "ok",
+		Delay:    &config.DelayConfig{Min: time.Second, Max: 2 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Delay == nil || route.Delay.Min != time.Second || route.Delay.Max != 2*time.Second {
+		t.Errorf("Unexpected compiled delay: %+v", route.Delay)
+	}
+
+	route, err = compiler.CompileRoute(config.RouteConfig{Path: "/fast", Method: "GET", Template: "ok"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Delay != nil {
+		t.Errorf("Expected no delay, got %+v", route.Delay)
+	}
+}
+
+func TestCompiler_CompileRoute_ChunkDelays(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:               "/events",
+		Method:             "GET",
+		Template:           "ok",
+		DelayFirstByte:     &config.DelayConfig{Min: time.Second, Max: time.Second},
+		DelayBetweenChunks: &config.DelayConfig{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.DelayFirstByte == nil || route.DelayFirstByte.Min != time.Second {
+		t.Errorf("Unexpected compiled first byte delay: %+v", route.DelayFirstByte)
+	}
+	if route.DelayBetweenChunks == nil || route.DelayBetweenChunks.Min != 10*time.Millisecond || route.DelayBetweenChunks.Max != 50*time.Millisecond {
+		t.Errorf("Unexpected compiled delay between chunks: %+v", route.DelayBetweenChunks)
+	}
+	if route.Delay != nil {
+		t.Errorf("Expected no delay, got %+v", route.Delay)
+	}
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Deprecation represents a compiled route deprecation, signaled to clients
+// with Deprecation and Sunset response headers
+type Deprecation struct {
+	Sunset time.Time // When the route goes away, zero when unknown
+}
+
+// compileDeprecation compiles the deprecation of a route, or returns nil
+// when it isn't deprecated
+func compileDeprecation(rc config.RouteConfig) (*Deprecation, error) {
+	if !rc.Deprecated {
+		return nil, nil
+	}
+
+	sunset, err := rc.GetSunset()
+	if err != nil {
+		return nil, err
+	}
+	return &Deprecation{Sunset: sunset}, nil
+}
+
+// SetHeaders sets the headers signaling the deprecation on a response
+func (d *Deprecation) SetHeaders(h http.Header) {
+	h.Set("Deprecation", "true")
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.Format(http.TimeFormat))
+	}
+}
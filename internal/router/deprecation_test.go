@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Deprecation(t *testing.T) {
+	tests := []struct {
+		name        string
+		deprecated  bool
+		sunset      string
+		deprecation string
+		sunsetDate  string
+	}{
+		{name: "not deprecated"},
+		{name: "deprecated", deprecated: true, deprecation: "true"},
+		{name: "deprecated with sunset", deprecated: true, sunset: "2026-12-31", deprecation: "true", sunsetDate: "Thu, 31 Dec 2026 00:00:00 GMT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/old", Method: "GET", Template: "old", Deprecated: tt.deprecated, Sunset: tt.sunset})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if (route.Deprecation != nil) != tt.deprecated {
+				t.Fatalf("Expected deprecated %v, got %+v", tt.deprecated, route.Deprecation)
+			}
+			if route.Deprecation == nil {
+				return
+			}
+
+			headers := http.Header{}
+			route.Deprecation.SetHeaders(headers)
+			if headers.Get("Deprecation") != tt.deprecation || headers.Get("Sunset") != tt.sunsetDate {
+				t.Errorf("Expected Deprecation %q and Sunset %q, got %q and %q", tt.deprecation, tt.sunsetDate, headers.Get("Deprecation"), headers.Get("Sunset"))
+			}
+		})
+	}
+}
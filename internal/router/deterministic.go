@@ -0,0 +1,27 @@
+package router
+
+import (
+	"strings"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Deterministic represents how a route renders the same response for the
+// same request
+type Deterministic struct {
+	Key  string    // Identifies the route in the seed of its random functions
+	Time time.Time // Time the route's clock is frozen at
+}
+
+// compileDeterministic keys the seed of a deterministic route by its method,
+// path and own seed, and resolves the time its clock is frozen at
+func compileDeterministic(rc config.RouteConfig) (*Deterministic, error) {
+	at, err := rc.Deterministic.GetTime()
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.ToUpper(rc.Method) + " " + rc.Path + " " + rc.Deterministic.Seed
+	return &Deterministic{Key: key, Time: at}, nil
+}
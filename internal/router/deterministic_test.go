@@ -0,0 +1,33 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Deterministic(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:          "/users",
+		Method:        "get",
+		Template:      "{{ fakeName }}",
+		Deterministic: &config.DeterministicConfig{Seed: "v2", Time: "2025-06-01"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := Deterministic{Key: "GET /users v2", Time: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)}
+	if route.Deterministic == nil || *route.Deterministic != want {
+		t.Errorf("Expected deterministic %+v, got %+v", want, route.Deterministic)
+	}
+
+	route, err = NewCompiler().CompileRoute(config.RouteConfig{Path: "/users", Method: "GET", Template: "{{ fakeName }}"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Deterministic != nil {
+		t.Errorf("Expected no deterministic settings, got %+v", route.Deterministic)
+	}
+}
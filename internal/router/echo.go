@@ -0,0 +1,138 @@
+package router
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// EchoBody represents how a route answers with the request body
+type EchoBody struct {
+	Status     int          // Default status code (0 means 200)
+	Transforms bool         // Whether JSON bodies are transformed rather than echoed as-is
+	Select     []string     // Path of the part of the body to echo (empty for the whole body)
+	Remove     [][]string   // Paths of fields removed from the echoed body
+	Set        []*EchoField // Fields set on the echoed body, in path order
+}
+
+// EchoField is a field set on echoed bodies to the output of a template
+type EchoField struct {
+	Path []string           // Path of the field
+	Tmpl *template.Template // Compiled template of the field's value
+}
+
+// compileEchoBody splits the paths of a route's echo transforms and compiles
+// the templates of the fields it sets
+func (c *Compiler) compileEchoBody(route *Route, routeConfig config.RouteConfig) error {
+	ec := routeConfig.EchoBody
+	echo := &EchoBody{Status: ec.Status, Transforms: ec.HasTransforms()}
+
+	if ec.Select != "" {
+		echo.Select = strings.Split(ec.Select, ".")
+	}
+	for _, path := range ec.Remove {
+		echo.Remove = append(echo.Remove, strings.Split(path, "."))
+	}
+
+	paths := make([]string, 0, len(ec.Set))
+	for path := range ec.Set {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		templateName := fmt.Sprintf("echo_body_%s_%s_%s",
+			routeConfig.GetNormalizedMethod(),
+			sanitizeTemplateName(routeConfig.Path),
+			sanitizeTemplateName(path))
+
+		tmpl, err := c.engine.CompileInlineTemplate(templateName, ec.Set[path])
+		if err != nil {
+			return fmt.Errorf("failed to compile template of field %q: %w", path, err)
+		}
+		echo.Set = append(echo.Set, &EchoField{Path: strings.Split(path, "."), Tmpl: tmpl})
+	}
+
+	route.EchoBody = echo
+	return nil
+}
+
+// Transform applies the echo transforms to a parsed JSON body: the selected
+// part is kept, then fields are removed, then values are set, keyed by the
+// dotted path of their field. When the selected part is an array, fields are
+// removed from and set on each of its objects. It returns nil when the
+// selected part doesn't exist.
+func (e *EchoBody) Transform(body any, values map[string]any) any {
+	for _, segment := range e.Select {
+		next, ok := childAt(body, segment)
+		if !ok {
+			return nil
+		}
+		body = next
+	}
+
+	items := []any{body}
+	if list, ok := body.([]any); ok {
+		items = list
+	}
+
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, path := range e.Remove {
+			removePath(object, path)
+		}
+		for _, field := range e.Set {
+			setPath(object, field.Path, values[strings.Join(field.Path, ".")])
+		}
+	}
+	return body
+}
+
+// childAt returns a field of an object or an element of an array
+func childAt(node any, segment string) (any, bool) {
+	switch node := node.(type) {
+	case map[string]any:
+		value, ok := node[segment]
+		return value, ok
+	case []any:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(node) {
+			return nil, false
+		}
+		return node[index], true
+	}
+	return nil, false
+}
+
+// removePath deletes the field at path of an object, if it exists
+func removePath(object map[string]any, path []string) {
+	for _, segment := range path[:len(path)-1] {
+		child, ok := object[segment].(map[string]any)
+		if !ok {
+			return
+		}
+		object = child
+	}
+	delete(object, path[len(path)-1])
+}
+
+// setPath sets the field at path of an object, creating the objects leading
+// to it and replacing whatever isn't an object on the way
+func setPath(object map[string]any, path []string, value any) {
+	for _, segment := range path[:len(path)-1] {
+		child, ok := object[segment].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			object[segment] = child
+		}
+		object = child
+	}
+	object[path[len(path)-1]] = value
+}
@@ -0,0 +1,96 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_EchoBody(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:   "/users",
+		Method: "POST",
+		EchoBody: &config.EchoBodyConfig{
+			Status: 201,
+			Select: "data.user",
+			Remove: []string{"password"},
+			Set:    map[string]string{"meta.id": "{{ uuidv4 }}", "id": "{{ randInt 1 9 }}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	echo := route.EchoBody
+	if echo == nil || echo.Status != 201 || !echo.Transforms || route.TemplateSource != "echo" {
+		t.Fatalf("Expected a compiled echo_body, got %+v", echo)
+	}
+	if !reflect.DeepEqual(echo.Select, []string{"data", "user"}) || !reflect.DeepEqual(echo.Remove, [][]string{{"password"}}) {
+		t.Errorf("Expected the paths to be split, got %v and %v", echo.Select, echo.Remove)
+	}
+	if len(echo.Set) != 2 || echo.Set[0].Path[0] != "id" || echo.Set[1].Tmpl == nil {
+		t.Errorf("Expected the set fields in path order, got %+v", echo.Set)
+	}
+
+	if _, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:     "/users",
+		Method:   "POST",
+		EchoBody: &config.EchoBodyConfig{Set: map[string]string{"id": "{{ .Missing"}},
+	}); err == nil {
+		t.Error("Expected an error for an invalid field template")
+	}
+}
+
+func TestEchoBody_Transform(t *testing.T) {
+	values := map[string]any{"id": 7, "meta.created": "now"}
+	echo := &EchoBody{
+		Remove: [][]string{{"password"}, {"meta", "internal"}, {"missing", "field"}},
+		Set:    []*EchoField{{Path: []string{"id"}}, {Path: []string{"meta", "created"}}},
+	}
+
+	tests := []struct {
+		name string
+		path []string
+		body any
+		want any
+	}{
+		{
+			name: "object",
+			body: map[string]any{"name": "Ada", "password": "x", "meta": map[string]any{"internal": true}},
+			want: map[string]any{"name": "Ada", "id": 7, "meta": map[string]any{"created": "now"}},
+		},
+		{
+			name: "intermediate objects created",
+			body: map[string]any{"meta": "replaced"},
+			want: map[string]any{"id": 7, "meta": map[string]any{"created": "now"}},
+		},
+		{
+			name: "selected array",
+			path: []string{"data"},
+			body: map[string]any{"data": []any{map[string]any{"password": "x"}, "scalar"}},
+			want: []any{map[string]any{"id": 7, "meta": map[string]any{"created": "now"}}, "scalar"},
+		},
+		{
+			name: "selected element",
+			path: []string{"data", "1"},
+			body: map[string]any{"data": []any{"a", "b"}},
+			want: "b",
+		},
+		{
+			name: "missing selection",
+			path: []string{"data", "5"},
+			body: map[string]any{"data": []any{"a"}},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			echo.Select = tt.path
+			if got := echo.Transform(tt.body, values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transform() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
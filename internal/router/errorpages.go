@@ -0,0 +1,72 @@
+package router
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// ErrorPage is a compiled response replacing a built-in error
+type ErrorPage struct {
+	Status  int                           // Status sent, zero to keep the built-in error's
+	Tmpl    *template.Template            // Body template
+	Headers map[string]*template.Template // Response header templates, by lowercase name
+}
+
+// ErrorPages are the compiled responses replacing built-in errors, nil for
+// the ones sent as-is
+type ErrorPages struct {
+	TemplateError *ErrorPage // Replaces the 500 sent when a template fails to render
+	Timeout       *ErrorPage // Replaces the 408 sent when a request times out
+}
+
+// CompileErrorPages compiles the responses replacing built-in errors
+func (c *Compiler) CompileErrorPages(ep *config.ErrorPagesConfig) (ErrorPages, error) {
+	var pages ErrorPages
+	for name, pc := range ep.Pages() {
+		page, err := c.compileErrorPage(name, pc)
+		if err != nil {
+			return ErrorPages{}, fmt.Errorf("failed to compile error page %q: %w", name, err)
+		}
+
+		switch name {
+		case "template_error":
+			pages.TemplateError = page
+		case "timeout":
+			pages.Timeout = page
+		}
+	}
+	return pages, nil
+}
+
+// compileErrorPage compiles the template and headers of an error page
+func (c *Compiler) compileErrorPage(name string, pc *config.ErrorPageConfig) (*ErrorPage, error) {
+	page := &ErrorPage{Status: pc.Status}
+
+	var err error
+	switch {
+	case pc.Template != "":
+		page.Tmpl, err = c.engine.CompileInlineTemplate("error_page_"+name, pc.Template)
+	case pc.TemplateFile != "":
+		page.Tmpl, err = c.engine.CompileFileTemplate(pc.TemplateFile)
+	default:
+		err = fmt.Errorf("no template source specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pc.Headers) > 0 {
+		page.Headers = make(map[string]*template.Template, len(pc.Headers))
+	}
+	for headerName, headerValue := range pc.Headers {
+		tmpl, err := c.compileHeaderTemplate("error_page_"+name+"_header_"+sanitizeTemplateName(headerName), headerValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile response header template for %q: %w", headerName, err)
+		}
+		page.Headers[canonicalizeHeaderName(headerName)] = tmpl
+	}
+
+	return page, nil
+}
@@ -0,0 +1,38 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompiler_CompileErrorPages(t *testing.T) {
+	compiler := NewCompiler()
+
+	pages, err := compiler.CompileErrorPages(nil)
+	if err != nil || pages.TemplateError != nil || pages.Timeout != nil {
+		t.Fatalf("Expected no error pages, got %+v, %v", pages, err)
+	}
+
+	pages, err = compiler.CompileErrorPages(&config.ErrorPagesConfig{
+		Timeout: &config.ErrorPageConfig{Status: http.StatusGatewayTimeout, Template: "timeout", Headers: map[string]string{"Retry-After": "5"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if pages.TemplateError != nil {
+		t.Errorf("Expected no template error page, got %+v", pages.TemplateError)
+	}
+	if pages.Timeout == nil || pages.Timeout.Status != http.StatusGatewayTimeout || pages.Timeout.Tmpl == nil {
+		t.Fatalf("Expected a compiled timeout page, got %+v", pages.Timeout)
+	}
+	if pages.Timeout.Headers["retry-after"] == nil {
+		t.Errorf("Expected the page's headers to be compiled, got %v", pages.Timeout.Headers)
+	}
+
+	_, err = compiler.CompileErrorPages(&config.ErrorPagesConfig{TemplateError: &config.ErrorPageConfig{Template: "{{ .Error "}})
+	if err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Expectation represents how often and in which order a route is expected to
+// be called
+type Expectation struct {
+	Min   int      // Fewest calls expected
+	Max   int      // Most calls expected, negative when unbounded
+	After []string // Routes, as "METHOD path", called before this route is first called
+}
+
+// compileExpectation resolves the call bounds of a route's expectations
+func compileExpectation(ec *config.ExpectConfig) *Expectation {
+	exp := &Expectation{Max: -1}
+
+	switch {
+	case ec.Calls != nil:
+		exp.Min, exp.Max = *ec.Calls, *ec.Calls
+	default:
+		if ec.MinCalls != nil {
+			exp.Min = *ec.MinCalls
+		}
+		if ec.MaxCalls != nil {
+			exp.Max = *ec.MaxCalls
+		}
+	}
+
+	for _, id := range ec.After {
+		if normalized, ok := config.NormalizeRouteID(id); ok {
+			exp.After = append(exp.After, normalized)
+		}
+	}
+	return exp
+}
+
+// Allows reports whether the number of calls meets the expected bounds
+func (e *Expectation) Allows(calls int) bool {
+	return calls >= e.Min && (e.Max < 0 || calls <= e.Max)
+}
+
+// String describes the expected number of calls
+func (e *Expectation) String() string {
+	switch {
+	case e.Min == e.Max:
+		return fmt.Sprintf("exactly %d", e.Min)
+	case e.Max < 0:
+		return fmt.Sprintf("at least %d", e.Min)
+	case e.Min == 0:
+		return fmt.Sprintf("at most %d", e.Max)
+	default:
+		return fmt.Sprintf("between %d and %d", e.Min, e.Max)
+	}
+}
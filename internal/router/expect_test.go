@@ -0,0 +1,54 @@
+package router
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileExpectation(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name        string
+		config      config.ExpectConfig
+		description string
+		allowed     []int
+		rejected    []int
+	}{
+		{name: "exact", config: config.ExpectConfig{Calls: intPtr(2)}, description: "exactly 2", allowed: []int{2}, rejected: []int{0, 1, 3}},
+		{name: "never", config: config.ExpectConfig{Calls: intPtr(0)}, description: "exactly 0", allowed: []int{0}, rejected: []int{1}},
+		{name: "minimum", config: config.ExpectConfig{MinCalls: intPtr(1)}, description: "at least 1", allowed: []int{1, 100}, rejected: []int{0}},
+		{name: "maximum", config: config.ExpectConfig{MaxCalls: intPtr(3)}, description: "at most 3", allowed: []int{0, 3}, rejected: []int{4}},
+		{name: "range", config: config.ExpectConfig{MinCalls: intPtr(1), MaxCalls: intPtr(3)}, description: "between 1 and 3", allowed: []int{1, 2, 3}, rejected: []int{0, 4}},
+		{name: "order only", config: config.ExpectConfig{After: []string{"POST /login"}}, description: "at least 0", allowed: []int{0, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := compileExpectation(&tt.config)
+			if got := exp.String(); got != tt.description {
+				t.Errorf("Expected description %q, got %q", tt.description, got)
+			}
+			for _, calls := range tt.allowed {
+				if !exp.Allows(calls) {
+					t.Errorf("Expected %d calls to be allowed", calls)
+				}
+			}
+			for _, calls := range tt.rejected {
+				if exp.Allows(calls) {
+					t.Errorf("Expected %d calls to be rejected", calls)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileExpectation_NormalizesAfter(t *testing.T) {
+	exp := compileExpectation(&config.ExpectConfig{After: []string{"post /login", " GET  /users "}})
+
+	if expected := []string{"POST /login", "GET /users"}; !slices.Equal(exp.After, expected) {
+		t.Errorf("Expected routes %v, got %v", expected, exp.After)
+	}
+}
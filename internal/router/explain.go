@@ -0,0 +1,65 @@
+package router
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"text/template"
+)
+
+// Templates returns every template a route renders: the body templates of
+// its responses, variants and WebSocket messages, the variant selector, the
+// response headers, the fields set on echoed bodies and the transaction ID
+func (r *Route) Templates() []*template.Template {
+	var tmpls []*template.Template
+	add := func(tmpl *template.Template) {
+		if tmpl != nil {
+			tmpls = append(tmpls, tmpl)
+		}
+	}
+	addHeaders := func(headers map[string]*template.Template) {
+		for _, name := range slices.Sorted(maps.Keys(headers)) {
+			add(headers[name])
+		}
+	}
+
+	add(r.Tmpl)
+	addHeaders(r.ResponseHeaders)
+	for _, resp := range r.Responses {
+		add(resp.Tmpl)
+		addHeaders(resp.ResponseHeaders)
+	}
+	if r.Variants != nil {
+		add(r.Variants.Selector)
+		for _, name := range slices.Sorted(maps.Keys(r.Variants.Cases)) {
+			add(r.Variants.Cases[name].Tmpl)
+			addHeaders(r.Variants.Cases[name].ResponseHeaders)
+		}
+	}
+	if r.WebSocket != nil {
+		for _, msg := range r.WebSocket.Messages {
+			add(msg.Tmpl)
+		}
+	}
+	if r.EchoBody != nil {
+		for _, field := range r.EchoBody.Set {
+			add(field.Tmpl)
+		}
+	}
+	if r.Transaction != nil {
+		add(r.Transaction.ID)
+	}
+	return tmpls
+}
+
+// String describes what a header matcher accepts, a regex between slashes or
+// a quoted literal
+func (m *HeaderMatcher) String() string {
+	if m == nil {
+		return "any value"
+	}
+	if m.IsRegex {
+		return "/" + m.Regex.String() + "/"
+	}
+	return strconv.Quote(m.Literal)
+}
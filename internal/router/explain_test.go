@@ -0,0 +1,55 @@
+package router
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestRoute_Templates(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:   "/orders",
+		Method: "GET",
+		Responses: []config.ResponseConfig{
+			{Template: "one", ResponseHeaders: map[string]string{"X-One": "1"}},
+			{Template: "two"},
+		},
+		ResponseHeaders: map[string]string{"X-Route": "route", "X-Id": "{{ .RequestID }}"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var names []string
+	for _, tmpl := range route.Templates() {
+		names = append(names, tmpl.Root.String())
+	}
+
+	expected := []string{"{{.RequestID}}", "route", "one", "1", "two"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected templates %q, got %q", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected template %d to be %q, got %q", i, expected[i], names[i])
+		}
+	}
+}
+
+func TestHeaderMatcher_String(t *testing.T) {
+	tests := []struct {
+		matcher  *HeaderMatcher
+		expected string
+	}{
+		{matcher: &HeaderMatcher{Literal: "application/json"}, expected: `"application/json"`},
+		{matcher: &HeaderMatcher{IsRegex: true, Regex: regexp.MustCompile(`^Bearer .+`)}, expected: `/^Bearer .+/`},
+		{matcher: nil, expected: "any value"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.matcher.String(); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
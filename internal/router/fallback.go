@@ -0,0 +1,21 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// CompileFallback compiles the response served to requests matching no route
+// into a route without a path, returning nil when none is configured
+func (c *Compiler) CompileFallback(fc *config.FallbackConfig) (*Route, error) {
+	if fc == nil {
+		return nil, nil
+	}
+
+	route, err := c.CompileRoute(fc.Route())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile fallback response: %w", err)
+	}
+	return route, nil
+}
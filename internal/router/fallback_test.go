@@ -0,0 +1,35 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompiler_CompileFallback(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileFallback(nil)
+	if err != nil || route != nil {
+		t.Fatalf("Expected no fallback route, got %v, %v", route, err)
+	}
+
+	route, err = compiler.CompileFallback(&config.FallbackConfig{Template: "missing", Headers: map[string]string{"X-Reason": "missing"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Pattern != "" {
+		t.Errorf("Expected the fallback route to have no pattern, got %q", route.Pattern)
+	}
+	if len(route.Responses) != 1 || route.Responses[0].Status != http.StatusNotFound {
+		t.Fatalf("Expected a single 404 response, got %+v", route.Responses)
+	}
+	if route.Responses[0].ResponseHeaders["x-reason"] == nil {
+		t.Errorf("Expected the fallback headers to be compiled")
+	}
+
+	if _, err := compiler.CompileFallback(&config.FallbackConfig{Template: "{{ .Request "}); err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Fault represents a compiled fault injected into a route's responses
+type Fault struct {
+	Type        string        // One of the config.Fault* types
+	Probability float64       // Chance of injecting the fault, from 0 to 1
+	Status      int           // Status code of error faults
+	Bytes       int           // Body bytes sent by truncate faults (0 for half the body)
+	ChunkSize   int           // Bytes written at a time by slow body faults
+	Interval    time.Duration // Pause between chunks of slow body faults
+}
+
+// compileFaults applies defaults to the faults of a route
+func compileFaults(faults []config.FaultConfig) []*Fault {
+	compiled := make([]*Fault, 0, len(faults))
+	for _, f := range faults {
+		fault := &Fault{
+			Type:        f.Type,
+			Probability: f.GetProbability(),
+			Status:      f.Status,
+			Bytes:       f.Bytes,
+			ChunkSize:   f.ChunkSize,
+			Interval:    f.Interval,
+		}
+
+		switch f.Type {
+		case config.FaultError:
+			if fault.Status == 0 {
+				fault.Status = config.DefaultFaultStatus
+			}
+		case config.FaultSlowBody:
+			if fault.ChunkSize == 0 {
+				fault.ChunkSize = config.DefaultFaultChunkSize
+			}
+			if fault.Interval == 0 {
+				fault.Interval = config.DefaultFaultInterval
+			}
+		}
+
+		compiled = append(compiled, fault)
+	}
+	return compiled
+}
+
+// PickFault rolls the route's faults in order and returns the first one to
+// be injected, or nil if the response should be served normally
+func (r *Route) PickFault() *Fault {
+	return pickFault(r.Faults, rand.Float64)
+}
+
+// pickFault returns the first fault whose roll, drawn from random, falls
+// within its probability
+func pickFault(faults []*Fault, random func() float64) *Fault {
+	for _, fault := range faults {
+		if fault.Probability >= 1 || random() < fault.Probability {
+			return fault
+		}
+	}
+	return nil
+}
+
+// TruncatedLength returns how many bytes of a body of the given size are sent
+// before the connection is closed, which is never the whole body
+func (f *Fault) TruncatedLength(size int) int {
+	if f.Bytes == 0 {
+		return size / 2
+	}
+	return max(min(f.Bytes, size-1), 0)
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileFaults_Defaults(t *testing.T) {
+	faults := compileFaults([]config.FaultConfig{
+		{Type: config.FaultError},
+		{Type: config.FaultSlowBody},
+		{Type: config.FaultSlowBody, ChunkSize: 8, Interval: time.Second},
+	})
+
+	if faults[0].Status != config.DefaultFaultStatus || faults[0].Probability != 1 {
+		t.Errorf("Unexpected error fault defaults: %+v", faults[0])
+	}
+	if faults[1].ChunkSize != config.DefaultFaultChunkSize || faults[1].Interval != config.DefaultFaultInterval {
+		t.Errorf("Unexpected slow body defaults: %+v", faults[1])
+	}
+	if faults[2].ChunkSize != 8 || faults[2].Interval != time.Second {
+		t.Errorf("Expected configured slow body settings to be kept, got %+v", faults[2])
+	}
+}
+
+func TestPickFault(t *testing.T) {
+	rare := &Fault{Type: config.FaultReset, Probability: 0.1}
+	likely := &Fault{Type: config.FaultError, Probability: 0.5}
+	always := &Fault{Type: config.FaultTruncate, Probability: 1}
+
+	tests := []struct {
+		name   string
+		faults []*Fault
+		rolls  []float64
+		want   *Fault
+	}{
+		{name: "no faults", want: nil},
+		{name: "first fault triggers", faults: []*Fault{rare, likely}, rolls: []float64{0.05}, want: rare},
+		{name: "falls through to second", faults: []*Fault{rare, likely}, rolls: []float64{0.2, 0.3}, want: likely},
+		{name: "none trigger", faults: []*Fault{rare, likely}, rolls: []float64{0.9, 0.9}, want: nil},
+		{name: "certain fault", faults: []*Fault{always}, want: always},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rolls := tt.rolls
+			random := func() float64 {
+				roll := rolls[0]
+				rolls = rolls[1:]
+				return roll
+			}
+
+			if got := pickFault(tt.faults, random); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFault_TruncatedLength(t *testing.T) {
+	tests := []struct {
+		bytes, size, want int
+	}{
+		{bytes: 0, size: 100, want: 50},
+		{bytes: 10, size: 100, want: 10},
+		{bytes: 500, size: 100, want: 99},
+		{bytes: 5, size: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		f := &Fault{Type: config.FaultTruncate, Bytes: tt.bytes}
+		if got := f.TruncatedLength(tt.size); got != tt.want {
+			t.Errorf("TruncatedLength(%d) with bytes %d: expected %d, got %d", tt.size, tt.bytes, tt.want, got)
+		}
+	}
+}
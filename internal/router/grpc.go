@@ -0,0 +1,66 @@
+package router
+
+import (
+	"fmt"
+	"text/template"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	grpcpkg "github.com/patrickdappollonio/mockingjay/internal/grpc"
+)
+
+// GRPCMethod represents a compiled mock of a gRPC method
+type GRPCMethod struct {
+	Name    string                        // Full method name, e.g. "greeter.v1.Greeter/SayHello"
+	Desc    protoreflect.MethodDescriptor // Request and response types of the method
+	Tmpl    *template.Template            // Renders the JSON response, nil when answering with an error
+	Code    grpcpkg.Code                  // Status answered with
+	Message string                        // Status message answered with
+}
+
+// GRPCMethods are mocked gRPC methods, keyed by the path they are called on
+type GRPCMethods map[string]*GRPCMethod
+
+// Path returns the HTTP/2 path the method is called on
+func (m *GRPCMethod) Path() string {
+	return "/" + m.Name
+}
+
+// CompileGRPC compiles the mocked gRPC methods. It returns nil when no gRPC
+// methods are configured.
+func (c *Compiler) CompileGRPC(gc *config.GRPCConfig) (GRPCMethods, error) {
+	if gc == nil {
+		return nil, nil
+	}
+
+	files, err := grpcpkg.LoadDescriptorSets(gc.DescriptorSets)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make(GRPCMethods, len(gc.Methods))
+	for _, mc := range gc.Methods {
+		desc, err := grpcpkg.FindMethod(files, mc.Method)
+		if err != nil {
+			return nil, err
+		}
+
+		name, _ := grpcpkg.NormalizeMethodName(mc.Method)
+		method := &GRPCMethod{Name: name, Desc: desc, Code: mc.GetStatus(), Message: mc.Message}
+
+		switch {
+		case mc.Template != "":
+			method.Tmpl, err = c.engine.CompileInlineTemplate("grpc_"+sanitizeTemplateName(name), mc.Template)
+		case mc.TemplateFile != "":
+			method.Tmpl, err = c.engine.CompileFileTemplate(mc.TemplateFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile template of gRPC method %q: %w", name, err)
+		}
+
+		methods[method.Path()] = method
+	}
+
+	return methods, nil
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	grpcpkg "github.com/patrickdappollonio/mockingjay/internal/grpc"
+)
+
+// testDescriptorSet describes a greeter service with unary, server streaming
+// and client streaming methods, shared with the grpc package's tests
+var testDescriptorSet = filepath.Join("..", "grpc", "testdata", "greeter.pb")
+
+func TestCompileGRPC(t *testing.T) {
+	methods, err := NewCompiler().CompileGRPC(nil)
+	if err != nil || methods != nil {
+		t.Errorf("Expected no methods without gRPC settings, got %v, %v", methods, err)
+	}
+
+	methods, err = NewCompiler().CompileGRPC(&config.GRPCConfig{
+		DescriptorSets: []string{testDescriptorSet},
+		Methods: []config.GRPCMethodConfig{
+			{Method: "/greeter.v1.Greeter/SayHello", Template: "{}"},
+			{Method: "greeter.v1.Greeter/StreamHellos", Status: "UNAVAILABLE", Message: "try again later"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	unary := methods["/greeter.v1.Greeter/SayHello"]
+	if unary == nil || unary.Name != "greeter.v1.Greeter/SayHello" || unary.Tmpl == nil || unary.Code != grpcpkg.OK {
+		t.Errorf("Unexpected unary method: %+v", unary)
+	}
+
+	streaming := methods["/greeter.v1.Greeter/StreamHellos"]
+	if streaming == nil || !streaming.Desc.IsStreamingServer() || streaming.Tmpl != nil || streaming.Code != grpcpkg.Unavailable || streaming.Message != "try again later" {
+		t.Errorf("Unexpected streaming method: %+v", streaming)
+	}
+
+	_, err = NewCompiler().CompileGRPC(&config.GRPCConfig{
+		DescriptorSets: []string{testDescriptorSet},
+		Methods:        []config.GRPCMethodConfig{{Method: "greeter.v1.Greeter/SayHello", Template: "{{ .Body"}},
+	})
+	if err == nil {
+		t.Error("Expected an error compiling an invalid template")
+	}
+}
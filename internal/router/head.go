@@ -0,0 +1,26 @@
+package router
+
+import "github.com/patrickdappollonio/mockingjay/internal/config"
+
+// Head represents how a GET route answers HEAD requests
+type Head struct {
+	Render        bool // Whether the body is rendered, then discarded
+	ContentLength bool // Whether the Content-Length of the body is sent
+}
+
+// compileHead applies the defaults of a route's answers to HEAD requests
+func compileHead(hc *config.HeadConfig) *Head {
+	return &Head{Render: hc.GetRender(), ContentLength: hc.GetContentLength()}
+}
+
+// RendersBody reports whether HEAD requests render the body, which they do
+// unless the route says otherwise
+func (h *Head) RendersBody() bool {
+	return h == nil || h.Render
+}
+
+// SendsContentLength reports whether HEAD requests are answered with the
+// Content-Length of the body, which they are unless the route says otherwise
+func (h *Head) SendsContentLength() bool {
+	return h == nil || h.ContentLength
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Head(t *testing.T) {
+	no := false
+
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/report", Method: "GET", Template: "{}", Head: &config.HeadConfig{Render: &no}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Head == nil || *route.Head != (Head{Render: false, ContentLength: false}) {
+		t.Errorf("Expected a head skipping the body and its length, got %+v", route.Head)
+	}
+	if route.Head.RendersBody() || route.Head.SendsContentLength() {
+		t.Errorf("Expected HEAD requests to skip the body and its length")
+	}
+
+	// Routes without settings answer HEAD requests like GET ones, minus the body
+	var defaults *Head
+	if !defaults.RendersBody() || !defaults.SendsContentLength() {
+		t.Errorf("Expected HEAD requests to render the body and send its length by default")
+	}
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Idempotency represents how a route remembers its responses by idempotency
+// key, to replay them to retries
+type Idempotency struct {
+	Header          string        // Request header carrying the key
+	TTL             time.Duration // How long responses are remembered
+	RejectConflicts bool          // Whether keys reused with a different request get a 409 Conflict
+}
+
+// compileIdempotency applies the defaults of a route's idempotency keys,
+// returning nil when they're unset
+func compileIdempotency(ic *config.IdempotencyConfig) *Idempotency {
+	if ic == nil {
+		return nil
+	}
+	return &Idempotency{Header: ic.GetHeader(), TTL: ic.GetTTL(), RejectConflicts: ic.RejectConflicts}
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompiler_CompileRoute_Idempotency(t *testing.T) {
+	compiler := NewCompiler()
+
+	tests := []struct {
+		name     string
+		config   *config.IdempotencyConfig
+		expected *Idempotency
+	}{
+		{name: "unset", config: nil, expected: nil},
+		{name: "defaults", config: &config.IdempotencyConfig{}, expected: &Idempotency{Header: "Idempotency-Key", TTL: 24 * time.Hour}},
+		{
+			name:     "settings",
+			config:   &config.IdempotencyConfig{Header: "X-Request-Key", TTL: time.Minute, RejectConflicts: true},
+			expected: &Idempotency{Header: "X-Request-Key", TTL: time.Minute, RejectConflicts: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := compiler.CompileRoute(config.RouteConfig{Path: "/payments", Method: "POST", Template: "ok", Idempotency: tt.config})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tt.expected == nil {
+				if route.Idempotency != nil {
+					t.Errorf("Expected no idempotency keys, got %+v", route.Idempotency)
+				}
+				return
+			}
+			if route.Idempotency == nil || *route.Idempotency != *tt.expected {
+				t.Errorf("Expected idempotency %+v, got %+v", tt.expected, route.Idempotency)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package router
+
+import (
+	"regexp/syntax"
+	"slices"
+	"strings"
+)
+
+// Index narrows down the routes a request may match, so large configurations
+// don't try every route, and run every regex, on every request. Routes are
+// grouped by method, then literal paths are looked up exactly, and regexes
+// anchored to the start of the path, like those of path templates and
+// prefixes, by the literal text they start with. Only the routes left are
+// tried, in match order, which keeps the outcome of trying all of them.
+type Index struct {
+	routes  []*Route                // Routes in match order, see MatchOrder
+	methods map[string]*methodIndex // Positions of routes in routes, by method
+}
+
+// methodIndex holds the positions of the routes of a method
+type methodIndex struct {
+	exact    map[string][]int // Literal routes, by path
+	prefixes *prefixNode      // Anchored regexes and static directories, by the text their paths start with
+	anyPath  []int            // Regexes that can match anywhere in the path
+}
+
+// NewIndex indexes routes, in the order given by MatchOrder
+func NewIndex(routes []*Route, specific bool) *Index {
+	ix := &Index{
+		routes:  MatchOrder(routes, specific),
+		methods: make(map[string]*methodIndex),
+	}
+
+	for pos, route := range ix.routes {
+		method := strings.ToUpper(route.Method)
+		mi := ix.methods[method]
+		if mi == nil {
+			mi = &methodIndex{exact: make(map[string][]int), prefixes: &prefixNode{}}
+			ix.methods[method] = mi
+		}
+
+		switch {
+		case route.StaticDir != nil:
+			mi.prefixes.insert(route.StaticDir.Prefix, pos)
+		case !route.IsRegexp:
+			mi.exact[route.Pattern] = append(mi.exact[route.Pattern], pos)
+		default:
+			if prefix, ok := anchoredPrefix(route); ok {
+				mi.prefixes.insert(prefix, pos)
+			} else {
+				mi.anyPath = append(mi.anyPath, pos)
+			}
+		}
+	}
+
+	return ix
+}
+
+// Candidates returns the routes that may match a request with the given
+// method and path, in match order. Routes not returned can't match it.
+func (ix *Index) Candidates(method, path string) []*Route {
+	mi := ix.methods[strings.ToUpper(method)]
+	if mi == nil {
+		return nil
+	}
+
+	positions := slices.Clone(mi.exact[path])
+	positions = mi.prefixes.collect(path, positions)
+	positions = append(positions, mi.anyPath...)
+	slices.Sort(positions)
+
+	candidates := make([]*Route, len(positions))
+	for i, pos := range positions {
+		candidates[i] = ix.routes[pos]
+	}
+	return candidates
+}
+
+// anchoredPrefix returns the literal text every path matched by a regex route
+// starts with, and false when the regex isn't anchored to the start of the
+// path and could match anywhere in it
+func anchoredPrefix(route *Route) (string, bool) {
+	if route.Regex == nil {
+		return "", false
+	}
+
+	re, err := syntax.Parse(route.Regex.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	first := re
+	if re.Op == syntax.OpConcat && len(re.Sub) > 0 {
+		first = re.Sub[0]
+	}
+	if first.Op != syntax.OpBeginText {
+		return "", false
+	}
+
+	prefix, _ := route.Regex.LiteralPrefix()
+	return prefix, true
+}
+
+// prefixNode is a node of a radix tree of path prefixes, holding the routes
+// whose paths start with the prefixes of the nodes leading to it, followed by
+// its own
+type prefixNode struct {
+	prefix   string
+	routes   []int
+	children []*prefixNode // Children start with distinct bytes
+}
+
+// insert adds the route at pos under prefix, splitting nodes sharing only
+// part of it
+func (n *prefixNode) insert(prefix string, pos int) {
+	for prefix != "" {
+		child := n.child(prefix[0])
+		if child == nil {
+			n.children = append(n.children, &prefixNode{prefix: prefix, routes: []int{pos}})
+			return
+		}
+
+		common := commonPrefixLen(prefix, child.prefix)
+		if common < len(child.prefix) {
+			split := &prefixNode{prefix: child.prefix[:common], children: []*prefixNode{child}}
+			child.prefix = child.prefix[common:]
+			n.children[slices.Index(n.children, child)] = split
+			child = split
+		}
+
+		n = child
+		prefix = prefix[common:]
+	}
+	n.routes = append(n.routes, pos)
+}
+
+// collect appends the routes of every node whose prefix path starts with
+func (n *prefixNode) collect(path string, into []int) []int {
+	for {
+		into = append(into, n.routes...)
+		if path == "" {
+			return into
+		}
+
+		child := n.child(path[0])
+		if child == nil || !strings.HasPrefix(path, child.prefix) {
+			return into
+		}
+		path = path[len(child.prefix):]
+		n = child
+	}
+}
+
+// child returns the child starting with b, if any
+func (n *prefixNode) child(b byte) *prefixNode {
+	for _, child := range n.children {
+		if child.prefix[0] == b {
+			return child
+		}
+	}
+	return nil
+}
+
+// commonPrefixLen returns the length of the longest prefix of a and b
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
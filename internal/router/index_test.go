@@ -0,0 +1,174 @@
+package router
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// indexTestRoutes are routes of every kind the index files differently
+var indexTestRoutes = []config.RouteConfig{
+	{Path: "/users", Method: "GET", Template: "users"},
+	{Path: "/users", Method: "POST", Template: "create"},
+	{Path: "/users/me", Method: "GET", Template: "me"},
+	{Path: "/users/{id}", Method: "GET", Template: "user"},
+	{Path: "/users/{id}/posts/{post}", Method: "GET", Template: "post"},
+	{Path: "/^/users/(?P<id>\\d+)/avatar$/", Method: "GET", Template: "avatar"},
+	{Path: "/api/v1/*", Method: "GET", Template: "v1"},
+	{Path: "/api", Method: "GET", Template: "api", PathMatch: config.PathMatchPrefix},
+	{Path: "/assets", Method: "GET", StaticDir: &config.StaticDirConfig{Root: "."}},
+	{Path: "/health$/", Method: "GET", Template: "unanchored"},
+	{Path: "/^(?i)/admin$/", Method: "GET", Template: "admin"},
+	{Path: "/^/a|^/b$/", Method: "GET", Template: "alternation"},
+	{Path: "/", Method: "GET", Template: "root"},
+	{Path: "/^/.*$/", Method: "GET", Template: "catch-all", Priority: -1},
+}
+
+func TestIndex_MatchesLinearScan(t *testing.T) {
+	compiler := NewCompiler()
+	routes, err := compiler.CompileRoutes(indexTestRoutes)
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+
+	requests := []string{
+		"GET /users", "POST /users", "DELETE /users", "GET /users/me", "GET /users/42",
+		"GET /users/42/posts/7", "GET /users/42/avatar", "GET /api/v1/things", "GET /api/v1",
+		"GET /api", "GET /api/v2/x", "GET /apis", "GET /assets/app.js", "GET /assets",
+		"GET /status/health", "GET /ADMIN", "GET /admin", "GET /alpha", "GET /b", "GET /",
+		"GET /nothing/here", "get /users",
+	}
+
+	for _, specific := range []bool{false, true} {
+		ix := NewIndex(routes, specific)
+		for _, spec := range requests {
+			var method, path string
+			fmt.Sscan(spec, &method, &path)
+			req := httptest.NewRequest("GET", path, nil)
+			req.Method = method
+
+			// The first route matching through the index must be the one a
+			// scan of every route finds
+			var want, got *Route
+			for _, route := range MatchOrder(routes, specific) {
+				if _, ok := route.MatchRequest(req); ok {
+					want = route
+					break
+				}
+			}
+			candidates := ix.Candidates(method, path)
+			for _, route := range candidates {
+				if _, ok := route.MatchRequest(req); ok {
+					got = route
+					break
+				}
+			}
+
+			if got != want {
+				t.Errorf("%s (specific: %v): expected %v, got %v", spec, specific, want, got)
+			}
+			if len(candidates) == len(routes) {
+				t.Errorf("%s (specific: %v): expected fewer candidates than routes", spec, specific)
+			}
+		}
+	}
+}
+
+func TestIndex_Candidates(t *testing.T) {
+	compiler := NewCompiler()
+	routes, err := compiler.CompileRoutes(indexTestRoutes)
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+	ix := NewIndex(routes, false)
+
+	var patterns []string
+	for _, route := range ix.Candidates("GET", "/users/42") {
+		patterns = append(patterns, route.Pattern)
+	}
+	expected := []string{"/users/{id}", "/users/{id}/posts/{post}", "/^/users/(?P<id>\\d+)/avatar$/", "/health$/", "/^(?i)/admin$/", "/^/a|^/b$/", "/^/.*$/"}
+	if !slices.Equal(patterns, expected) {
+		t.Errorf("Expected candidates %q, got %q", expected, patterns)
+	}
+
+	if candidates := ix.Candidates("PATCH", "/users"); candidates != nil {
+		t.Errorf("Expected no candidates for a method without routes, got %v", candidates)
+	}
+}
+
+func TestPrefixNode(t *testing.T) {
+	root := &prefixNode{}
+	for pos, prefix := range []string{"/users/", "/users", "/api/v1/", "/api/v2/", "/", "", "/api/v1/"} {
+		root.insert(prefix, pos)
+	}
+
+	tests := []struct {
+		path     string
+		expected []int
+	}{
+		{path: "/users/42", expected: []int{5, 4, 1, 0}},
+		{path: "/users", expected: []int{5, 4, 1}},
+		{path: "/api/v1/x", expected: []int{5, 4, 2, 6}},
+		{path: "/api/v3", expected: []int{5, 4}},
+		{path: "", expected: []int{5}},
+	}
+
+	for _, tt := range tests {
+		if got := root.collect(tt.path, nil); !slices.Equal(got, tt.expected) {
+			t.Errorf("collect(%q) = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+// benchmarkRoutes returns n routes like those of a large mock suite: literal
+// paths and path templates spread over many resources
+func benchmarkRoutes(b *testing.B, n int) []*Route {
+	b.Helper()
+
+	configs := make([]config.RouteConfig, 0, n)
+	for i := range n / 2 {
+		configs = append(configs,
+			config.RouteConfig{Path: fmt.Sprintf("/api/v1/resource%d", i), Method: "GET", Template: "list"},
+			config.RouteConfig{Path: fmt.Sprintf("/api/v1/resource%d/{id}", i), Method: "GET", Template: "item"},
+		)
+	}
+
+	routes, err := NewCompiler().CompileRoutes(configs)
+	if err != nil {
+		b.Fatalf("CompileRoutes() error = %v", err)
+	}
+	return routes
+}
+
+// benchmarkMatch matches a request for one of the last routes, as the worst
+// case of a scan, with find returning the routes to try
+func benchmarkMatch(b *testing.B, n int, find func(routes []*Route) func(method, path string) []*Route) {
+	routes := benchmarkRoutes(b, n)
+	candidates := find(routes)
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/resource%d/42", n/2-1), nil)
+
+	b.ResetTimer()
+	for b.Loop() {
+		for _, route := range candidates(req.Method, req.URL.Path) {
+			if _, ok := route.MatchRequest(req); ok {
+				break
+			}
+		}
+	}
+}
+
+func linearScan(routes []*Route) func(method, path string) []*Route {
+	return func(string, string) []*Route { return routes }
+}
+
+func indexed(routes []*Route) func(method, path string) []*Route {
+	return NewIndex(routes, false).Candidates
+}
+
+func BenchmarkMatch_Linear_100(b *testing.B)   { benchmarkMatch(b, 100, linearScan) }
+func BenchmarkMatch_Linear_1000(b *testing.B)  { benchmarkMatch(b, 1000, linearScan) }
+func BenchmarkMatch_Indexed_100(b *testing.B)  { benchmarkMatch(b, 100, indexed) }
+func BenchmarkMatch_Indexed_1000(b *testing.B) { benchmarkMatch(b, 1000, indexed) }
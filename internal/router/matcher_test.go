@@ -0,0 +1,62 @@
+package router
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestRoute_Matchers(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:     "/invoices",
+		Method:   "GET",
+		Template: "[]",
+		Match:    map[string]any{"jwt_claims": map[string]any{"scope": "/\\binvoices:read\\b/"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(route.Matchers) != 1 {
+		t.Fatalf("Expected 1 matcher, got %d", len(route.Matchers))
+	}
+
+	token := func(claims string) string {
+		return "Bearer e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      bool
+	}{
+		{name: "matching scope", authorization: token(`{"scope":"invoices:read invoices:write"}`), expected: true},
+		{name: "other scope", authorization: token(`{"scope":"users:read"}`)},
+		{name: "no token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/invoices", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if _, got := route.MatchRequest(req); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileRoute_UnknownMatcher(t *testing.T) {
+	_, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:     "/invoices",
+		Method:   "GET",
+		Template: "[]",
+		Match:    map[string]any{"geoip": map[string]any{"country": "CA"}},
+	})
+	if err == nil {
+		t.Fatal("Expected an error for an unknown matcher kind")
+	}
+}
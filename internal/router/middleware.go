@@ -0,0 +1,33 @@
+package router
+
+import (
+	"log/slog"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+)
+
+// UseMiddlewareFactory makes the compiler create the middleware of routes with
+// factory, so it logs and counts what it does like the server's middleware
+func (c *Compiler) UseMiddlewareFactory(factory *middleware.Factory) {
+	c.middleware = factory
+}
+
+// compileMiddleware creates the chain of the route's own middleware
+func (c *Compiler) compileMiddleware(route *Route, routeConfig config.RouteConfig) error {
+	if len(routeConfig.Middleware) == 0 {
+		return nil
+	}
+
+	factory := c.middleware
+	if factory == nil {
+		factory = middleware.NewFactory(slog.Default(), nil)
+	}
+
+	chain, err := factory.CreateChain(middleware.Config{Enabled: routeConfig.Middleware})
+	if err != nil {
+		return err
+	}
+	route.Middleware = chain.Then
+	return nil
+}
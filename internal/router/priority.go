@@ -0,0 +1,29 @@
+package router
+
+import (
+	"cmp"
+	"slices"
+)
+
+// MatchOrder returns the routes in the order requests are matched against
+// them: highest priority first, and routes of the same priority in the order
+// they're listed. When specific is set, routes of the same priority are
+// tried most specific first instead, with literal paths before regexes and
+// longer paths before shorter ones, so a broad regex listed early doesn't
+// shadow the routes after it. routes isn't modified.
+func MatchOrder(routes []*Route, specific bool) []*Route {
+	ordered := slices.Clone(routes)
+	slices.SortStableFunc(ordered, func(a, b *Route) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 || !specific {
+			return c
+		}
+		if a.IsRegexp != b.IsRegexp {
+			if a.IsRegexp {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(len(b.Pattern), len(a.Pattern))
+	})
+	return ordered
+}
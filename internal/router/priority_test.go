@@ -0,0 +1,55 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestMatchOrder(t *testing.T) {
+	compiler := NewCompiler()
+	routes, err := compiler.CompileRoutes([]config.RouteConfig{
+		{Path: "/^/users/.*$/", Method: "GET", Template: "any user"},
+		{Path: "/users/{id}", Method: "GET", Template: "user"},
+		{Path: "/users/me", Method: "GET", Template: "me"},
+		{Path: "/users", Method: "GET", Template: "users"},
+		{Path: "/health", Method: "GET", Template: "health", Priority: -1},
+		{Path: "/^/.*$/", Method: "GET", Template: "catch-all", Priority: 10},
+	})
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		specific bool
+		expected []string
+	}{
+		{
+			name:     "order",
+			expected: []string{"/^/.*$/", "/^/users/.*$/", "/users/{id}", "/users/me", "/users", "/health"},
+		},
+		{
+			name:     "specific",
+			specific: true,
+			expected: []string{"/^/.*$/", "/users/me", "/users", "/^/users/.*$/", "/users/{id}", "/health"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patterns []string
+			for _, route := range MatchOrder(routes, tt.specific) {
+				patterns = append(patterns, route.Pattern)
+			}
+			if strings.Join(patterns, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected order %v, got %v", tt.expected, patterns)
+			}
+		})
+	}
+
+	if routes[0].Pattern != "/^/users/.*$/" {
+		t.Errorf("Expected the routes to be left in their order, got %q first", routes[0].Pattern)
+	}
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Proxy represents a compiled upstream that a route forwards requests to
+type Proxy struct {
+	Target      *url.URL // Upstream base URL
+	StripPrefix string   // Prefix removed from the request path before forwarding
+	Record      bool     // Whether upstream responses are recorded
+}
+
+// compileProxy parses the upstream settings of a proxied route
+func (c *Compiler) compileProxy(route *Route, routeConfig config.RouteConfig) error {
+	target, err := url.Parse(routeConfig.Proxy.URL)
+	if err != nil {
+		return fmt.Errorf("invalid upstream URL %q: %w", routeConfig.Proxy.URL, err)
+	}
+
+	route.Proxy = &Proxy{
+		Target:      target,
+		StripPrefix: routeConfig.Proxy.StripPrefix,
+		Record:      routeConfig.Proxy.Record,
+	}
+	return nil
+}
+
+// CompileFallbackProxy parses the upstream that requests matching no route
+// are forwarded to, returning nil when none is configured
+func CompileFallbackProxy(rawURL string) (*Proxy, error) {
+	if rawURL == "" {
+		return nil, nil
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fallback upstream URL %q: %w", rawURL, err)
+	}
+	return &Proxy{Target: target}, nil
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompiler_CompileRoute_Proxy(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:   "/^/api/.*$/",
+		Method: "GET",
+		Proxy:  &config.ProxyConfig{URL: "https://api.example.com/v2", StripPrefix: "/api", Record: true},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if route.Tmpl != nil {
+		t.Error("Expected no template for a proxied route")
+	}
+	if route.TemplateSource != "proxy" {
+		t.Errorf("Expected template source %q, got %q", "proxy", route.TemplateSource)
+	}
+	if route.Proxy == nil {
+		t.Fatal("Expected compiled proxy, got nil")
+	}
+	if got := route.Proxy.Target.String(); got != "https://api.example.com/v2" {
+		t.Errorf("Expected target %q, got %q", "https://api.example.com/v2", got)
+	}
+	if route.Proxy.StripPrefix != "/api" || !route.Proxy.Record {
+		t.Errorf("Unexpected proxy settings: %+v", route.Proxy)
+	}
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Raw represents how a route's requests are parsed from the connection, and
+// what is done with the malformed ones
+type Raw struct {
+	DuplicateContentLength  string // Action for Content-Length headers with different values
+	ConflictingFraming      string // Action for requests with both Content-Length and Transfer-Encoding
+	InvalidTransferEncoding string // Action for a Transfer-Encoding other than "chunked"
+	InvalidChunkSize        string // Action for chunks with a bad size line or missing CRLF
+	KeepAlive               bool   // Read the bytes following a request as the next request
+}
+
+// DefaultRaw is how requests are parsed on raw connections when they don't
+// match a raw route: every malformed request is rejected
+var DefaultRaw = &Raw{
+	DuplicateContentLength:  config.RawReject,
+	ConflictingFraming:      config.RawReject,
+	InvalidTransferEncoding: config.RawReject,
+	InvalidChunkSize:        config.RawReject,
+}
+
+// compileRaw applies the defaults of a route's raw settings
+func compileRaw(rc *config.RawConfig) *Raw {
+	return &Raw{
+		DuplicateContentLength:  rc.GetDuplicateContentLength(),
+		ConflictingFraming:      rc.GetConflictingFraming(),
+		InvalidTransferEncoding: rc.GetInvalidTransferEncoding(),
+		InvalidChunkSize:        rc.GetInvalidChunkSize(),
+		KeepAlive:               rc.KeepAlive,
+	}
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Raw(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      config.RawConfig
+		expected Raw
+	}{
+		{name: "defaults", expected: *DefaultRaw},
+		{
+			name:     "custom",
+			raw:      config.RawConfig{DuplicateContentLength: config.RawFirst, InvalidChunkSize: config.RawTruncate, KeepAlive: true},
+			expected: Raw{DuplicateContentLength: config.RawFirst, ConflictingFraming: config.RawReject, InvalidTransferEncoding: config.RawReject, InvalidChunkSize: config.RawTruncate, KeepAlive: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/upload", Method: "POST", Template: "ok", Raw: &tt.raw})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Raw == nil || *route.Raw != tt.expected {
+				t.Errorf("Expected raw %+v, got %+v", tt.expected, route.Raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package router
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// ResponseCondition represents compiled request conditions for a response
+type ResponseCondition struct {
+	Headers map[string]*HeaderMatcher // Header matchers keyed by canonical header name
+	Query   map[string]*HeaderMatcher // Query parameter matchers
+	Body    map[string]*HeaderMatcher // JSON body matchers keyed by dotted path
+}
+
+// Response represents one compiled alternative response of a route
+type Response struct {
+	Weight          int                           // Relative selection weight
+	Condition       *ResponseCondition            // Request conditions (nil matches every request)
+	Status          int                           // Default status code (0 means 200)
+	Tmpl            *template.Template            // Compiled body template
+	ResponseHeaders map[string]*template.Template // Compiled response header templates
+}
+
+// Sequence represents how a route steps through its responses on successive calls
+type Sequence struct {
+	PerClient    bool   // Whether each client has its own position in the sequence
+	ClientHeader string // Header identifying clients (empty means by remote IP)
+	Loop         bool   // Whether to start over after the last response
+}
+
+// ResponseAt returns the response to serve for the given zero-based call number
+// of a sequenced route. Once the sequence is exhausted, the last response is
+// repeated, or the sequence starts over when looping.
+func (r *Route) ResponseAt(call int) *Response {
+	n := len(r.Responses)
+	if n == 0 || call < 0 {
+		return nil
+	}
+
+	if call >= n {
+		if r.Sequence != nil && r.Sequence.Loop {
+			call %= n
+		} else {
+			call = n - 1
+		}
+	}
+
+	return r.Responses[call]
+}
+
+// Matches checks if the request data satisfies the response's conditions
+func (c *ResponseCondition) Matches(headers http.Header, query url.Values, body interface{}) bool {
+	for name, matcher := range c.Headers {
+		value := headers.Get(name)
+		if value == "" || !matcher.Match(value) {
+			return false
+		}
+	}
+
+	for name, matcher := range c.Query {
+		if !query.Has(name) || !matcher.Match(query.Get(name)) {
+			return false
+		}
+	}
+
+	for path, matcher := range c.Body {
+		value, ok := lookupBodyPath(body, path)
+		if !ok || !matcher.Match(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lookupBodyPath resolves a dotted path such as "user.roles.0" in a parsed JSON body
+func lookupBodyPath(body interface{}, path string) (string, bool) {
+	current := body
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return "", false
+			}
+			current = value
+		case []interface{}:
+			var index int
+			if _, err := fmt.Sscanf(segment, "%d", &index); err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			current = node[index]
+		default:
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case map[string]interface{}, []interface{}:
+		return "", false
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// SelectResponse picks the response to serve for a request. Responses whose
+// conditions match take priority over unconditional ones; among the candidates,
+// one is chosen randomly according to its weight. Returns nil if the route has
+// no alternative responses or none are eligible.
+func (r *Route) SelectResponse(headers http.Header, query url.Values, body interface{}) *Response {
+	if len(r.Responses) == 0 {
+		return nil
+	}
+
+	var conditional, unconditional []*Response
+	for _, resp := range r.Responses {
+		if resp.Condition == nil {
+			unconditional = append(unconditional, resp)
+			continue
+		}
+		if resp.Condition.Matches(headers, query, body) {
+			conditional = append(conditional, resp)
+		}
+	}
+
+	candidates := unconditional
+	if len(conditional) > 0 {
+		candidates = conditional
+	}
+
+	return pickWeighted(candidates, rand.IntN)
+}
+
+// pickWeighted chooses a response with probability proportional to its weight
+func pickWeighted(candidates []*Response, intN func(int) int) *Response {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, resp := range candidates {
+		total += resp.Weight
+	}
+	if total <= 0 {
+		return candidates[0]
+	}
+
+	n := intN(total)
+	for _, resp := range candidates {
+		if n < resp.Weight {
+			return resp
+		}
+		n -= resp.Weight
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+// compileHeaderMatchers compiles literal or /regex/ values into header matchers
+func compileHeaderMatchers(values map[string]string, canonicalize func(string) string) (map[string]*HeaderMatcher, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	matchers := make(map[string]*HeaderMatcher, len(values))
+	for key, value := range values {
+		matcher := &HeaderMatcher{Literal: value}
+		if isHeaderRegexPattern(value) {
+			pattern := extractHeaderRegexPattern(value)
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex pattern %q for %q: %w", pattern, key, err)
+			}
+			matcher = &HeaderMatcher{IsRegex: true, Regex: regex}
+		}
+		matchers[canonicalize(key)] = matcher
+	}
+
+	return matchers, nil
+}
+
+// compileResponses compiles the alternative responses of a route
+func (c *Compiler) compileResponses(route *Route, routeConfig config.RouteConfig) error {
+	for i, respConfig := range routeConfig.Responses {
+		resp, err := c.compileResponse(routeConfig, fmt.Sprintf("response_%d", i), respConfig)
+		if err != nil {
+			return fmt.Errorf("response %d: %w", i, err)
+		}
+
+		if respConfig.When != nil {
+			condition := &ResponseCondition{}
+			if condition.Headers, err = compileHeaderMatchers(respConfig.When.Headers, http.CanonicalHeaderKey); err != nil {
+				return fmt.Errorf("response %d header condition: %w", i, err)
+			}
+			if condition.Query, err = compileHeaderMatchers(respConfig.When.Query, identity); err != nil {
+				return fmt.Errorf("response %d query condition: %w", i, err)
+			}
+			if condition.Body, err = compileHeaderMatchers(respConfig.When.Body, identity); err != nil {
+				return fmt.Errorf("response %d body condition: %w", i, err)
+			}
+			resp.Condition = condition
+		}
+
+		route.Responses = append(route.Responses, resp)
+	}
+
+	if seq := routeConfig.Sequence; seq != nil {
+		route.Sequence = &Sequence{
+			PerClient:    seq.GetPer() == config.SequencePerClient,
+			ClientHeader: seq.ClientHeader,
+			Loop:         seq.Loop,
+		}
+	}
+
+	return nil
+}
+
+// compileResponse compiles the template, status and headers of one of a
+// route's responses, named after the route and suffix
+func (c *Compiler) compileResponse(routeConfig config.RouteConfig, suffix string, respConfig config.ResponseConfig) (*Response, error) {
+	variant := config.RouteConfig{
+		Path:            fmt.Sprintf("%s_%s", routeConfig.Path, suffix),
+		Method:          routeConfig.Method,
+		Template:        respConfig.Template,
+		TemplateFile:    respConfig.TemplateFile,
+		ResponseHeaders: respConfig.ResponseHeaders,
+		EscapeHTML:      routeConfig.EscapeHTML,
+	}
+
+	resp := &Response{
+		Weight: respConfig.GetWeight(),
+		Status: respConfig.Status,
+	}
+
+	tmpl, err := c.compileTemplate(variant)
+	if err != nil {
+		return nil, err
+	}
+	resp.Tmpl = tmpl
+
+	// Reuse the route header compiler to build this response's header templates
+	headerRoute := &Route{}
+	if err := c.compileResponseHeaders(headerRoute, variant); err != nil {
+		return nil, err
+	}
+	resp.ResponseHeaders = headerRoute.ResponseHeaders
+
+	return resp, nil
+}
+
+// identity returns its input unchanged
+func identity(s string) string {
+	return s
+}
@@ -0,0 +1,252 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompiler_CompileRoute_Responses(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:   "/orders",
+		Method: "POST",
+		Responses: []config.ResponseConfig{
+			{
+				When:            &config.ResponseCondition{Headers: map[string]string{"x-plan": "/^pro$/"}},
+				Status:          201,
+				Template:        "created",
+				ResponseHeaders: map[string]string{"X-Plan": "pro"},
+			},
+			{Weight: 3, Template: "queued"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if route.Tmpl != nil {
+		t.Error("Expected no route-level template when responses are set")
+	}
+	if route.TemplateSource != "responses" {
+		t.Errorf("Expected template source %q, got %q", "responses", route.TemplateSource)
+	}
+	if len(route.Responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(route.Responses))
+	}
+
+	first := route.Responses[0]
+	if first.Status != 201 || first.Weight != 1 {
+		t.Errorf("Expected status 201 and weight 1, got %d and %d", first.Status, first.Weight)
+	}
+	if first.Condition == nil || first.Condition.Headers["X-Plan"] == nil || !first.Condition.Headers["X-Plan"].IsRegex {
+		t.Errorf("Expected a regex condition on canonical header X-Plan, got %+v", first.Condition)
+	}
+	if len(first.ResponseHeaders) != 1 {
+		t.Errorf("Expected 1 response header, got %d", len(first.ResponseHeaders))
+	}
+	if route.Responses[1].Weight != 3 || route.Responses[1].Condition != nil {
+		t.Errorf("Expected unconditional response with weight 3, got %+v", route.Responses[1])
+	}
+}
+
+func TestCompiler_CompileRoute_ResponsesInvalidCondition(t *testing.T) {
+	compiler := NewCompiler()
+
+	_, err := compiler.CompileRoute(config.RouteConfig{
+		Path:   "/broken",
+		Method: "GET",
+		Responses: []config.ResponseConfig{{
+			When:     &config.ResponseCondition{Query: map[string]string{"id": "/[unclosed/"}},
+			Template: "ok",
+		}},
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid condition regex, got nil")
+	}
+}
+
+func TestRoute_SelectResponse(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:   "/login",
+		Method: "POST",
+		Responses: []config.ResponseConfig{
+			{
+				When:     &config.ResponseCondition{Body: map[string]string{"user.roles.0": "admin"}},
+				Template: "admin",
+			},
+			{
+				When:     &config.ResponseCondition{Query: map[string]string{"locked": "true"}, Headers: map[string]string{"X-Client": "web"}},
+				Template: "locked",
+			},
+			{Template: "default"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		headers http.Header
+		query   url.Values
+		body    interface{}
+		want    int
+	}{
+		{
+			name: "body path match",
+			body: map[string]interface{}{"user": map[string]interface{}{"roles": []interface{}{"admin"}}},
+			want: 0,
+		},
+		{
+			name:    "query and header match",
+			headers: http.Header{"X-Client": {"web"}},
+			query:   url.Values{"locked": {"true"}},
+			want:    1,
+		},
+		{
+			name:  "partial condition falls back to default",
+			query: url.Values{"locked": {"true"}},
+			want:  2,
+		},
+		{
+			name: "non-JSON body falls back to default",
+			body: "admin",
+			want: 2,
+		},
+		{
+			name: "no conditions met",
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := route.SelectResponse(tt.headers, tt.query, tt.body)
+			if got != route.Responses[tt.want] {
+				t.Errorf("Expected response %d, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRoute_SelectResponse_NoCandidates(t *testing.T) {
+	route := &Route{
+		Responses: []*Response{{
+			Weight:    1,
+			Condition: &ResponseCondition{Query: map[string]*HeaderMatcher{"a": {Literal: "b"}}},
+		}},
+	}
+
+	if got := route.SelectResponse(nil, nil, nil); got != nil {
+		t.Errorf("Expected nil response, got %+v", got)
+	}
+	if got := (&Route{}).SelectResponse(nil, nil, nil); got != nil {
+		t.Errorf("Expected nil response for route without responses, got %+v", got)
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	a := &Response{Weight: 1}
+	b := &Response{Weight: 3}
+	candidates := []*Response{a, b}
+
+	tests := []struct {
+		roll int
+		want *Response
+	}{
+		{roll: 0, want: a},
+		{roll: 1, want: b},
+		{roll: 3, want: b},
+	}
+
+	for _, tt := range tests {
+		got := pickWeighted(candidates, func(n int) int {
+			if n != 4 {
+				t.Errorf("Expected total weight 4, got %d", n)
+			}
+			return tt.roll
+		})
+		if got != tt.want {
+			t.Errorf("Expected roll %d to pick weight %d, got weight %d", tt.roll, tt.want.Weight, got.Weight)
+		}
+	}
+}
+
+func TestLookupBodyPath(t *testing.T) {
+	body := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":   "ada",
+			"age":    float64(36),
+			"active": true,
+			"tags":   []interface{}{"x", "y"},
+		},
+	}
+
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: "user.name", want: "ada", wantOK: true},
+		{path: "user.age", want: "36", wantOK: true},
+		{path: "user.active", want: "true", wantOK: true},
+		{path: "user.tags.1", want: "y", wantOK: true},
+		{path: "user.tags.5", wantOK: false},
+		{path: "user.tags", wantOK: false},
+		{path: "user.missing", wantOK: false},
+		{path: "user.name.first", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := lookupBodyPath(body, tt.path)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestRoute_ResponseAt(t *testing.T) {
+	compiler := NewCompiler()
+
+	for _, loop := range []bool{false, true} {
+		route, err := compiler.CompileRoute(config.RouteConfig{
+			Path:   "/jobs/1",
+			Method: "GET",
+			Responses: []config.ResponseConfig{
+				{Status: 202, Template: "pending"},
+				{Template: "done"},
+			},
+			Sequence: &config.SequenceConfig{Per: "client", ClientHeader: "X-Client-ID", Loop: loop},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if route.Sequence == nil || !route.Sequence.PerClient || route.Sequence.ClientHeader != "X-Client-ID" || route.Sequence.Loop != loop {
+			t.Fatalf("Unexpected compiled sequence: %+v", route.Sequence)
+		}
+
+		want := []int{0, 1, 1, 1}
+		if loop {
+			want = []int{0, 1, 0, 1}
+		}
+
+		for call, index := range want {
+			if got := route.ResponseAt(call); got != route.Responses[index] {
+				t.Errorf("loop=%v: expected call %d to return response %d", loop, call, index)
+			}
+		}
+	}
+
+	if got := (&Route{}).ResponseAt(0); got != nil {
+		t.Errorf("Expected nil response for route without responses, got %+v", got)
+	}
+}
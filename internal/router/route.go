@@ -5,6 +5,10 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/matcher"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
 )
 
 // HeaderMatcher represents a compiled header matching rule
@@ -27,14 +31,117 @@ type Route struct {
 	// Header matching
 	MatchHeaders map[string]*HeaderMatcher // Compiled header matchers
 
+	// Cookie matching, by cookie name (nil matchers only require the cookie)
+	MatchCookies map[string]*HeaderMatcher
+
+	// Client certificate matching (nil when any request matches)
+	MatchClientCert *ClientCertMatcher
+
+	// Server name matching against TLS SNI (nil when any request matches)
+	MatchSNI *HeaderMatcher
+
+	// Matchers of registered kinds, sorted by kind name (nil for none)
+	Matchers []matcher.Matcher
+
 	// Template
 	Tmpl *template.Template // Compiled template for rendering responses
 
 	// Response headers
 	ResponseHeaders map[string]*template.Template // Compiled response header templates
 
+	// Alternative responses (when set, Tmpl is nil and one response is picked per request)
+	Responses []*Response
+	Sequence  *Sequence // Serve responses in order instead of by condition and weight (nil if unset)
+
+	// Responses picked by the output of a selector template (when set, Tmpl is nil)
+	Variants *Variants
+
+	// Upstream proxy (when set, the route forwards requests instead of rendering templates)
+	Proxy *Proxy
+
+	// Batch endpoint (when set, the route serves the sub-requests of batches instead of rendering templates)
+	Batch *Batch
+
+	// Echoing of the request body (when set, the route answers with the body instead of rendering templates)
+	EchoBody *EchoBody
+
+	// File answered with as-is (when set, the route sends the file instead of rendering templates)
+	BodyFile *BodyFile
+
+	// Directory served under the route's path (when set, the route matches paths under it and sends files instead of rendering templates)
+	StaticDir *StaticDir
+
+	// Encoding rendered bodies are decoded from before they're written ("" to write them as rendered)
+	BodyEncoding string
+
+	// WebSocket endpoint (when set, the route upgrades the connection and Tmpl, if any, answers client messages)
+	WebSocket *WebSocket
+
+	// Artificial latency applied before responding (nil for none)
+	Delay *Delay
+
+	// Latency applied right before the first byte of the response is written (nil for none)
+	DelayFirstByte *Delay
+
+	// Latency applied between the lines of the response body, which are flushed one at a time (nil for none)
+	DelayBetweenChunks *Delay
+
+	// Limit on requests served at once, queueing the others in arrival order (nil for no limit)
+	Concurrency *Concurrency
+
+	// Responses remembered by idempotency key and replayed to retries (nil to serve every request)
+	Idempotency *Idempotency
+
+	// Faults injected into responses, rolled in order (nil for none)
+	Faults []*Fault
+
+	// Compression of responses, possibly breaking content negotiation on purpose (nil for none)
+	Compression *Compression
+
+	// Algorithms of the checksums of the body sent in headers, in order (nil for none)
+	Checksums []string
+
+	// Checks the rendered body must pass before it's sent (nil for none)
+	AssertResponse *ResponseAssertion
+
+	// How HEAD requests are answered when the route serves them for GET (nil for the defaults)
+	Head *Head
+
+	// Streaming of template output as it's rendered (nil to buffer whole responses)
+	Stream *Stream
+
+	// Parsing of requests from the connection, tolerating malformed ones (nil to use Go's HTTP server)
+	Raw *Raw
+
+	// Token that requests must present (nil when the route is unprotected)
+	RequireToken *TokenRequirement
+
+	// Transition of a multi-step transaction performed by the route (nil for none)
+	Transaction *TransactionStep
+
+	// Offset of the route's clock from the real time, overriding the server's (nil to use the server's)
+	ClockSkew *time.Duration
+
+	// Seeding of random functions and freezing of the clock, so responses repeat (nil for random responses)
+	Deterministic *Deterministic
+
+	// How often and in which order the route is expected to be called (nil for no expectations)
+	Expect *Expectation
+
+	// Deprecation signaled to clients in response headers (nil when the route isn't deprecated)
+	Deprecation *Deprecation
+
+	// Route metadata exposed to templates as .Route
+	Info templatepkg.RouteInfo
+
+	// Routes with a higher priority are matched first (0 by default)
+	Priority int
+
+	// Middleware wrapping the route's handling, outermost first (nil for none)
+	Middleware func(http.Handler) http.Handler
+
 	// Template source info (for debugging/logging)
-	TemplateSource string // "inline" or filename
+	TemplateSource string // "inline", "responses", "proxy", "batch", "websocket", "echo", "static" or filename
 }
 
 // RouteMatch represents the result of matching a route against a request
@@ -69,9 +176,40 @@ func (r *Route) MatchRequest(req *http.Request) (*RouteMatch, bool) {
 		return nil, false
 	}
 
+	// Check cookie matching
+	if !r.matchesCookies(req) {
+		return nil, false
+	}
+
+	// Check the client certificate
+	if r.MatchClientCert != nil && !r.MatchClientCert.Match(req) {
+		return nil, false
+	}
+
+	// Check the server name requested through SNI
+	if r.MatchSNI != nil && (req.TLS == nil || !r.MatchSNI.Match(req.TLS.ServerName)) {
+		return nil, false
+	}
+
+	// Check the matchers of registered kinds
+	for _, m := range r.Matchers {
+		if !m.Match(req) {
+			return nil, false
+		}
+	}
+
 	return match, true
 }
 
+// MatchesPath checks if the route's path pattern matches the given path,
+// regardless of the method and headers
+func (r *Route) MatchesPath(path string) bool {
+	if r.IsRegexp {
+		return r.Regex != nil && r.Regex.MatchString(path)
+	}
+	return path == r.Pattern || (r.StaticDir != nil && r.StaticDir.Contains(path))
+}
+
 // matchesMethod checks if the route's method matches the request method
 func (r *Route) matchesMethod(method string) bool {
 	return strings.EqualFold(r.Method, method)
@@ -106,7 +244,7 @@ func (r *Route) matchRegexPattern(path string) (*RouteMatch, bool) {
 
 // matchLiteralPattern matches the request path against the literal pattern
 func (r *Route) matchLiteralPattern(path string) (*RouteMatch, bool) {
-	if path == r.Pattern {
+	if path == r.Pattern || (r.StaticDir != nil && r.StaticDir.Contains(path)) {
 		return &RouteMatch{
 			Route:  r,
 			Params: make(map[string]string), // Empty params for literal matches
@@ -159,13 +297,18 @@ func (r *Route) matchesHeaders(req *http.Request) bool {
 
 // matchHeaderValue checks if a header value matches the expected pattern
 func (r *Route) matchHeaderValue(value string, matcher *HeaderMatcher) bool {
-	if matcher.IsRegex {
+	return matcher.Match(value)
+}
+
+// Match checks if a value matches the literal or regex pattern
+func (m *HeaderMatcher) Match(value string) bool {
+	if m.IsRegex {
 		// Regex pattern matching
-		return matcher.Regex.MatchString(value)
+		return m.Regex.MatchString(value)
 	}
 
 	// Literal string matching (exact match)
-	return value == matcher.Literal
+	return value == m.Literal
 }
 
 // getHeaderIgnoreCase gets a header value by name, ignoring case
@@ -697,6 +697,29 @@ func TestRoute_matchRegexPattern_NilRegex(t *testing.T) {
 	}
 }
 
+func TestRoute_MatchesPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    *Route
+		path     string
+		expected bool
+	}{
+		{name: "literal match", route: &Route{Pattern: "/users", Method: "GET"}, path: "/users", expected: true},
+		{name: "literal mismatch", route: &Route{Pattern: "/users", Method: "GET"}, path: "/orders"},
+		{name: "regex match", route: &Route{Pattern: `^/users/\d+$`, Method: "POST", IsRegexp: true, Regex: regexp.MustCompile(`^/users/\d+$`)}, path: "/users/42", expected: true},
+		{name: "regex mismatch", route: &Route{Pattern: `^/users/\d+$`, Method: "POST", IsRegexp: true, Regex: regexp.MustCompile(`^/users/\d+$`)}, path: "/users/abc"},
+		{name: "nil regex", route: &Route{Pattern: "^/users$", Method: "GET", IsRegexp: true}, path: "/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.route.MatchesPath(tt.path); got != tt.expected {
+				t.Errorf("MatchesPath(%q) = %v, expected %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
 // Performance benchmarks
 func BenchmarkRoute_MatchRequest_Literal(b *testing.B) {
 	route := &Route{
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// StaticDir represents a directory whose files a route serves under its path
+type StaticDir struct {
+	Prefix  string // Path the files are served under, without a trailing slash
+	Root    string // Directory the files are served from
+	Index   string // File served for directories
+	Listing bool   // Whether directories without an index file list their files
+}
+
+// compileStaticDir compiles the directory a route serves
+func compileStaticDir(route *Route, routeConfig config.RouteConfig) {
+	route.StaticDir = &StaticDir{
+		Prefix:  strings.TrimSuffix(routeConfig.Path, "/"),
+		Root:    routeConfig.StaticDir.Root,
+		Index:   routeConfig.StaticDir.GetIndex(),
+		Listing: routeConfig.StaticDir.Listing,
+	}
+}
+
+// Contains checks if path is the directory's prefix or lies under it
+func (s *StaticDir) Contains(path string) bool {
+	rest, ok := strings.CutPrefix(path, s.Prefix)
+	return ok && (rest == "" || strings.HasPrefix(rest, "/"))
+}
+
+// FilePath returns the path of the requested file relative to the directory,
+// always starting with a slash
+func (s *StaticDir) FilePath(path string) string {
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(path, s.Prefix), "/")
+}
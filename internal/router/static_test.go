@@ -0,0 +1,63 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_StaticDir(t *testing.T) {
+	dir := t.TempDir()
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/assets", Method: "GET", StaticDir: &config.StaticDirConfig{Root: dir}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := StaticDir{Prefix: "/assets", Root: dir, Index: config.DefaultStaticIndex}
+	if route.StaticDir == nil || *route.StaticDir != want {
+		t.Fatalf("Expected static dir %+v, got %+v", want, route.StaticDir)
+	}
+	if route.Tmpl != nil || route.TemplateSource != "static" {
+		t.Errorf("Expected no template and source %q, got %v and %q", "static", route.Tmpl, route.TemplateSource)
+	}
+}
+
+func TestRoute_MatchRequest_StaticDir(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matches bool
+		file    string
+	}{
+		{pattern: "/assets", path: "/assets", matches: true, file: "/"},
+		{pattern: "/assets", path: "/assets/", matches: true, file: "/"},
+		{pattern: "/assets", path: "/assets/js/sdk.js", matches: true, file: "/js/sdk.js"},
+		{pattern: "/assets", path: "/assetsx/sdk.js", matches: false},
+		{pattern: "/assets", path: "/other", matches: false},
+		{pattern: "/", path: "/index.html", matches: true, file: "/index.html"},
+		{pattern: "/", path: "/", matches: true, file: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: tt.pattern, Method: "GET", StaticDir: &config.StaticDirConfig{Root: t.TempDir()}})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			_, matches := route.MatchRequest(httptest.NewRequest("GET", tt.path, nil))
+			if matches != tt.matches {
+				t.Fatalf("Expected match %v, got %v", tt.matches, matches)
+			}
+			if route.MatchesPath(tt.path) != tt.matches {
+				t.Errorf("Expected MatchesPath to agree with MatchRequest")
+			}
+			if tt.matches {
+				if got := route.StaticDir.FilePath(tt.path); got != tt.file {
+					t.Errorf("Expected file path %q, got %q", tt.file, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Stream represents how a route writes its template output as it's rendered
+type Stream struct {
+	BufferSize    int           // Bytes held back before the response starts
+	FlushInterval time.Duration // How often output is flushed to the client
+}
+
+// compileStream applies the defaults of a route's streaming
+func compileStream(sc *config.StreamConfig) *Stream {
+	return &Stream{BufferSize: sc.GetBufferSize(), FlushInterval: sc.GetFlushInterval()}
+}
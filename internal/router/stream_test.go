@@ -0,0 +1,31 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Stream(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   config.StreamConfig
+		expected Stream
+	}{
+		{name: "defaults", expected: Stream{BufferSize: config.DefaultStreamBufferSize, FlushInterval: config.DefaultStreamFlushInterval}},
+		{name: "custom", stream: config.StreamConfig{BufferSize: 512, FlushInterval: time.Second}, expected: Stream{BufferSize: 512, FlushInterval: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/export.csv", Method: "GET", Template: "id", Stream: &tt.stream})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.Stream == nil || *route.Stream != tt.expected {
+				t.Errorf("Expected stream %+v, got %+v", tt.expected, route.Stream)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// TokenRequirement represents a compiled token check protecting a route
+type TokenRequirement struct {
+	Name   string // Token kind from the token bucket
+	Header string // Header carrying the token (empty when read from query or body)
+	Query  string // Query parameter carrying the token
+	Body   string // Dotted path of the body field carrying the token
+	Revoke bool   // Whether the token is revoked once used
+}
+
+// compileTokenRequirement resolves where a route reads its required token from
+func compileTokenRequirement(rt *config.RequireTokenConfig) *TokenRequirement {
+	req := &TokenRequirement{
+		Name:   rt.Name,
+		Header: rt.Header,
+		Query:  rt.Query,
+		Body:   rt.Body,
+		Revoke: rt.Revoke,
+	}
+
+	if req.Header == "" && req.Query == "" && req.Body == "" {
+		req.Header = "Authorization"
+	}
+	return req
+}
+
+// Extract returns the token presented by a request, or an empty string if
+// there is none. Tokens read from headers may carry a "Bearer " prefix.
+func (t *TokenRequirement) Extract(headers http.Header, query url.Values, body interface{}) string {
+	switch {
+	case t.Header != "":
+		value := strings.TrimSpace(headers.Get(t.Header))
+		if scheme, token, found := strings.Cut(value, " "); found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return value
+	case t.Query != "":
+		return query.Get(t.Query)
+	default:
+		value, _ := lookupBodyPath(body, t.Body)
+		return value
+	}
+}
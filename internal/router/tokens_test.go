@@ -0,0 +1,68 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestTokenRequirement_Extract(t *testing.T) {
+	body := map[string]interface{}{
+		"grant": map[string]interface{}{"refresh_token": "from-body"},
+	}
+
+	tests := []struct {
+		name    string
+		require config.RequireTokenConfig
+		headers http.Header
+		query   url.Values
+		want    string
+	}{
+		{
+			name:    "bearer authorization by default",
+			require: config.RequireTokenConfig{Name: "access"},
+			headers: http.Header{"Authorization": {"Bearer abc123"}},
+			want:    "abc123",
+		},
+		{
+			name:    "lowercase bearer scheme",
+			require: config.RequireTokenConfig{Name: "access"},
+			headers: http.Header{"Authorization": {"bearer abc123"}},
+			want:    "abc123",
+		},
+		{
+			name:    "custom header without scheme",
+			require: config.RequireTokenConfig{Name: "access", Header: "X-Api-Token"},
+			headers: http.Header{"X-Api-Token": {"abc123"}},
+			want:    "abc123",
+		},
+		{
+			name:    "query parameter",
+			require: config.RequireTokenConfig{Name: "access", Query: "access_token"},
+			query:   url.Values{"access_token": {"abc123"}},
+			want:    "abc123",
+		},
+		{
+			name:    "body field",
+			require: config.RequireTokenConfig{Name: "refresh", Body: "grant.refresh_token"},
+			want:    "from-body",
+		},
+		{
+			name:    "missing token",
+			require: config.RequireTokenConfig{Name: "access"},
+			headers: http.Header{},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirement := compileTokenRequirement(&tt.require)
+			if got := requirement.Extract(tt.headers, tt.query, body); got != tt.want {
+				t.Errorf("Expected token %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// TransactionStep represents a compiled transition of a multi-step
+// transaction that a route performs
+type TransactionStep struct {
+	Name   string             // Transaction kind
+	Action string             // Transition performed by the route
+	ID     *template.Template // Renders the ID of the transaction from the request
+}
+
+// compileTransactionStep compiles the transaction ID template of a route
+func (c *Compiler) compileTransactionStep(route *Route, routeConfig config.RouteConfig) error {
+	templateName := fmt.Sprintf("transaction_%s_%s", routeConfig.GetNormalizedMethod(), sanitizeTemplateName(routeConfig.Path))
+
+	idTemplate, err := c.engine.CompileInlineTemplate(templateName, routeConfig.Transaction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to compile transaction ID template: %w", err)
+	}
+
+	route.Transaction = &TransactionStep{
+		Name:   routeConfig.Transaction.Name,
+		Action: routeConfig.Transaction.Action,
+		ID:     idTemplate,
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+func TestCompileRoute_Transaction(t *testing.T) {
+	compiler := NewCompiler()
+
+	route, err := compiler.CompileRoute(config.RouteConfig{
+		Path:        "/^/payments/(?P<id>[^/]+)/capture$/",
+		Method:      "POST",
+		Template:    "{}",
+		Transaction: &config.TransactionStepConfig{Name: "payment", ID: "pay_{{ .Params.id }}", Action: "capture"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	step := route.Transaction
+	if step == nil {
+		t.Fatal("Expected a compiled transaction step")
+	}
+	if step.Name != "payment" || step.Action != "capture" {
+		t.Errorf("Expected payment capture, got %s %s", step.Name, step.Action)
+	}
+
+	var id strings.Builder
+	ctx := &templatepkg.TemplateContext{Params: map[string]string{"id": "123"}}
+	if err := compiler.GetEngine().ExecuteTemplate(step.ID, &id, ctx); err != nil {
+		t.Fatalf("Expected no error rendering the ID, got %v", err)
+	}
+	if id.String() != "pay_123" {
+		t.Errorf("Expected ID pay_123, got %q", id.String())
+	}
+}
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Variants represents a route's responses picked by the output of a selector
+// template
+type Variants struct {
+	Selector *template.Template   // Compiled template whose trimmed output names the variant
+	Cases    map[string]*Response // Responses by variant name
+	Default  string               // Variant served when the selector matches none
+}
+
+// Select returns the variant named by a selector's output, or the default
+// one when no variant has that name, along with the name of the variant
+func (v *Variants) Select(selected string) (string, *Response) {
+	name := strings.TrimSpace(selected)
+	if resp, ok := v.Cases[name]; ok {
+		return name, resp
+	}
+	return v.Default, v.Cases[v.Default]
+}
+
+// compileVariants compiles the selector and responses of a route's variants
+func (c *Compiler) compileVariants(route *Route, routeConfig config.RouteConfig) error {
+	vc := routeConfig.Variants
+
+	templateName := fmt.Sprintf("variants_%s_%s", routeConfig.GetNormalizedMethod(), sanitizeTemplateName(routeConfig.Path))
+	selector, err := c.engine.CompileInlineTemplate(templateName, vc.Selector)
+	if err != nil {
+		return fmt.Errorf("failed to compile selector template: %w", err)
+	}
+
+	variants := &Variants{Selector: selector, Cases: make(map[string]*Response, len(vc.Cases)), Default: vc.Default}
+	for _, name := range vc.Names() {
+		resp, err := c.compileResponse(routeConfig, "variant_"+name, vc.Cases[name])
+		if err != nil {
+			return fmt.Errorf("variant %q: %w", name, err)
+		}
+		variants.Cases[name] = resp
+	}
+
+	route.Variants = variants
+	return nil
+}
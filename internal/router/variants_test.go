@@ -0,0 +1,65 @@
+package router
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_Variants(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{
+		Path:   "/checkout",
+		Method: "GET",
+		Variants: &config.VariantsConfig{
+			Selector: `{{ .Headers.Get "X-Experiment" }}`,
+			Cases: map[string]config.ResponseConfig{
+				"control":   {Template: "old"},
+				"treatment": {Status: 201, Template: "new", ResponseHeaders: map[string]string{"X-Variant": "treatment"}},
+			},
+			Default: "control",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if route.Tmpl != nil || route.TemplateSource != "variants" {
+		t.Errorf("Expected a variants route without a template, got source %q", route.TemplateSource)
+	}
+	if route.Variants == nil || route.Variants.Selector == nil || len(route.Variants.Cases) != 2 {
+		t.Fatalf("Expected compiled variants, got %+v", route.Variants)
+	}
+
+	tests := []struct {
+		selected   string
+		wantName   string
+		wantBody   string
+		wantStatus int
+	}{
+		{selected: "treatment", wantName: "treatment", wantBody: "new", wantStatus: 201},
+		{selected: "  treatment\n", wantName: "treatment", wantBody: "new", wantStatus: 201},
+		{selected: "control", wantName: "control", wantBody: "old"},
+		{selected: "unknown", wantName: "control", wantBody: "old"},
+		{selected: "", wantName: "control", wantBody: "old"},
+	}
+
+	for _, tt := range tests {
+		name, resp := route.Variants.Select(tt.selected)
+		if name != tt.wantName {
+			t.Errorf("Select(%q): expected variant %q, got %q", tt.selected, tt.wantName, name)
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := resp.Tmpl.Execute(&buf, nil); err != nil {
+			t.Fatalf("Failed to render variant %q: %v", name, err)
+		}
+		if buf.String() != tt.wantBody || resp.Status != tt.wantStatus {
+			t.Errorf("Select(%q): expected %q with status %d, got %q with status %d", tt.selected, tt.wantBody, tt.wantStatus, buf.String(), resp.Status)
+		}
+	}
+
+	if _, resp := route.Variants.Select("treatment"); resp.ResponseHeaders["x-variant"] == nil {
+		t.Errorf("Expected the variant's response headers to be compiled")
+	}
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// WebSocket represents a compiled WebSocket endpoint, which upgrades the
+// connection, sends its scripted messages and answers client messages
+// according to its echo mode. Replies of the "template" mode are rendered
+// with the route's Tmpl.
+type WebSocket struct {
+	Messages []*WebSocketMessage // Messages sent in order once the connection opens
+	Echo     string              // How client messages are answered: "off", "raw" or "template"
+	Close    bool                // Close the connection after the last message
+}
+
+// WebSocketMessage represents a compiled scripted message
+type WebSocketMessage struct {
+	Tmpl  *template.Template // Compiled message template
+	Delay *Delay             // Wait before sending, counted from the previous message (nil for none)
+}
+
+// compileWebSocket compiles the scripted messages of a WebSocket route and
+// the template answering client messages, if any
+func (c *Compiler) compileWebSocket(route *Route, routeConfig config.RouteConfig) error {
+	wc := routeConfig.WebSocket
+	hasTemplate := strings.TrimSpace(routeConfig.Template) != "" || strings.TrimSpace(routeConfig.TemplateFile) != ""
+
+	ws := &WebSocket{Echo: wc.GetEcho(hasTemplate), Close: wc.Close}
+	for i, msgConfig := range wc.Messages {
+		variant := config.RouteConfig{
+			Path:         fmt.Sprintf("%s_websocket_%d", routeConfig.Path, i),
+			Method:       routeConfig.Method,
+			Template:     msgConfig.Template,
+			TemplateFile: msgConfig.TemplateFile,
+		}
+
+		tmpl, err := c.compileTemplate(variant)
+		if err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+
+		msg := &WebSocketMessage{Tmpl: tmpl}
+		if msgConfig.Delay != nil {
+			msg.Delay = &Delay{Min: msgConfig.Delay.Min, Max: msgConfig.Delay.Max}
+		}
+		ws.Messages = append(ws.Messages, msg)
+	}
+
+	if hasTemplate {
+		tmpl, err := c.compileTemplate(routeConfig)
+		if err != nil {
+			return err
+		}
+		route.Tmpl = tmpl
+	}
+
+	route.WebSocket = ws
+	return nil
+}
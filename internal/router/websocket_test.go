@@ -0,0 +1,87 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestCompileRoute_WebSocket(t *testing.T) {
+	tests := []struct {
+		name         string
+		route        config.RouteConfig
+		messages     int
+		echo         string
+		wantTemplate bool
+	}{
+		{
+			name:  "echo server",
+			route: config.RouteConfig{Path: "/ws", Method: "GET", WebSocket: &config.WebSocketConfig{}},
+			echo:  config.WebSocketEchoRaw,
+		},
+		{
+			name: "scripted messages",
+			route: config.RouteConfig{Path: "/ws", Method: "GET", WebSocket: &config.WebSocketConfig{Messages: []config.WebSocketMessageConfig{
+				{Template: "hello"},
+				{Template: "{{ .Params.id }}", Delay: &config.DelayConfig{Min: time.Second, Max: time.Second}},
+			}}},
+			messages: 2,
+			echo:     config.WebSocketEchoOff,
+		},
+		{
+			name:         "template replies",
+			route:        config.RouteConfig{Path: "/ws", Method: "GET", Template: "{{ .Body }}", WebSocket: &config.WebSocketConfig{}},
+			echo:         config.WebSocketEchoTemplate,
+			wantTemplate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := NewCompiler().CompileRoute(tt.route)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if route.WebSocket == nil || route.TemplateSource != "websocket" {
+				t.Fatalf("Expected a WebSocket route, got source %q", route.TemplateSource)
+			}
+			if len(route.WebSocket.Messages) != tt.messages {
+				t.Errorf("Expected %d messages, got %d", tt.messages, len(route.WebSocket.Messages))
+			}
+			if route.WebSocket.Echo != tt.echo {
+				t.Errorf("Expected echo mode %q, got %q", tt.echo, route.WebSocket.Echo)
+			}
+			if (route.Tmpl != nil) != tt.wantTemplate {
+				t.Errorf("Expected template: %v, got %v", tt.wantTemplate, route.Tmpl != nil)
+			}
+		})
+	}
+}
+
+func TestCompileRoute_WebSocketMessageDelay(t *testing.T) {
+	route, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/ws", Method: "GET", WebSocket: &config.WebSocketConfig{Messages: []config.WebSocketMessageConfig{
+		{Template: "first"},
+		{Template: "second", Delay: &config.DelayConfig{Min: 250 * time.Millisecond, Max: 250 * time.Millisecond}},
+	}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	messages := route.WebSocket.Messages
+	if messages[0].Delay != nil {
+		t.Errorf("Expected no delay for the first message, got %+v", messages[0].Delay)
+	}
+	if got := messages[1].Delay.Duration(); got != 250*time.Millisecond {
+		t.Errorf("Expected a 250ms delay for the second message, got %v", got)
+	}
+}
+
+func TestCompileRoute_WebSocketInvalidMessage(t *testing.T) {
+	_, err := NewCompiler().CompileRoute(config.RouteConfig{Path: "/ws", Method: "GET", WebSocket: &config.WebSocketConfig{Messages: []config.WebSocketMessageConfig{
+		{Template: "{{ .Unclosed"},
+	}}})
+	if err == nil {
+		t.Fatal("Expected an error for an invalid message template, got nil")
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// adminPrefix is the path prefix reserved for the admin API
+const adminPrefix = "/__admin/"
+
+// isAdminPath reports whether the request path belongs to the admin API
+func isAdminPath(path string) bool {
+	return strings.HasPrefix(path, adminPrefix)
+}
+
+// newAdminMux builds the router for the admin API endpoints, each requiring
+// the scope of what it does when tokens protect the admin API
+func (s *Server) newAdminMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /__admin/routes", s.requireScope(config.AdminScopeRead, s.handleListRuntimeRoutes))
+	mux.HandleFunc("POST /__admin/routes", s.requireScope(config.AdminScopeMutate, s.handleCreateRuntimeRoute))
+	mux.HandleFunc("DELETE /__admin/routes", s.requireScope(config.AdminScopeReset, s.handleDeleteAllRuntimeRoutes))
+	mux.HandleFunc("DELETE /__admin/routes/{id}", s.requireScope(config.AdminScopeMutate, s.handleDeleteRuntimeRoute))
+	mux.HandleFunc("GET /__admin/routes/usage", s.requireScope(config.AdminScopeRead, s.handleRouteUsage))
+
+	mux.HandleFunc("GET /__admin/sessions", s.requireScope(config.AdminScopeRead, s.handleListSessions))
+	mux.HandleFunc("POST /__admin/sessions", s.requireScope(config.AdminScopeMutate, s.handleOpenSession))
+	mux.HandleFunc("DELETE /__admin/sessions", s.requireScope(config.AdminScopeReset, s.handleCloseAllSessions))
+	mux.HandleFunc("DELETE /__admin/sessions/{id}", s.requireScope(config.AdminScopeMutate, s.handleCloseSession))
+
+	mux.HandleFunc("GET /__admin/scenarios", s.requireScope(config.AdminScopeRead, s.handleListScenarios))
+	mux.HandleFunc("DELETE /__admin/scenarios", s.requireScope(config.AdminScopeReset, s.handleResetScenarios))
+
+	mux.HandleFunc("GET /__admin/recordings", s.requireScope(config.AdminScopeRead, s.handleListRecordings))
+	mux.HandleFunc("DELETE /__admin/recordings", s.requireScope(config.AdminScopeReset, s.handleDeleteRecordings))
+
+	mux.HandleFunc("GET /__admin/tokens", s.requireScope(config.AdminScopeRead, s.handleListTokens))
+	mux.HandleFunc("DELETE /__admin/tokens", s.requireScope(config.AdminScopeReset, s.handleRevokeTokens))
+
+	mux.HandleFunc("GET /__admin/transactions", s.requireScope(config.AdminScopeRead, s.handleListTransactions))
+	mux.HandleFunc("DELETE /__admin/transactions", s.requireScope(config.AdminScopeReset, s.handleResetTransactions))
+
+	mux.HandleFunc("GET /__admin/idempotency", s.requireScope(config.AdminScopeRead, s.handleListIdempotency))
+	mux.HandleFunc("DELETE /__admin/idempotency", s.requireScope(config.AdminScopeReset, s.handleResetIdempotency))
+
+	mux.HandleFunc("GET /__admin/requests", s.requireScope(config.AdminScopeRead, s.handleListRequests))
+	mux.HandleFunc("DELETE /__admin/requests", s.requireScope(config.AdminScopeReset, s.handleDeleteRequests))
+	mux.HandleFunc("GET /__admin/requests/stream", s.requireScope(config.AdminScopeRead, s.handleStreamRequests))
+	mux.HandleFunc("GET /__admin/requests/count", s.requireScope(config.AdminScopeRead, s.handleCountRequests))
+
+	mux.HandleFunc("GET /__admin/health/dependencies", s.requireScope(config.AdminScopeRead, s.handleListDependencies))
+	mux.HandleFunc("PUT /__admin/health/dependencies/{name}", s.requireScope(config.AdminScopeMutate, s.handleSetDependency))
+	mux.HandleFunc("DELETE /__admin/health/dependencies", s.requireScope(config.AdminScopeReset, s.handleResetDependencies))
+
+	mux.HandleFunc("GET /__admin/verify", s.requireScope(config.AdminScopeRead, s.handleVerify))
+	mux.HandleFunc("DELETE /__admin/verify", s.requireScope(config.AdminScopeReset, s.handleResetCalls))
+
+	mux.HandleFunc("GET /__admin/metrics", s.requireScope(config.AdminScopeRead, s.handleMetrics))
+	mux.HandleFunc("GET /__admin/config", s.requireScope(config.AdminScopeRead, s.handleConfigSummary))
+	mux.HandleFunc("GET /__admin/log-level", s.requireScope(config.AdminScopeRead, s.handleGetLogLevel))
+	mux.HandleFunc("PUT /__admin/log-level", s.requireScope(config.AdminScopeMutate, s.handleSetLogLevel))
+
+	mux.HandleFunc("/__admin/", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminError(w, http.StatusNotFound, "unknown admin endpoint "+r.Method+" "+r.URL.Path)
+	})
+
+	return mux
+}
+
+// adminErrorResponse represents the JSON body returned by admin endpoints on failure
+type adminErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON encodes v as the JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v) // Headers are already sent, nothing else to do on failure
+}
+
+// writeAdminError writes a JSON error response for the admin API
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, adminErrorResponse{Error: message})
+}
+
+// handleMetrics reports the server's counters and gauges
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"metrics": s.metrics.Snapshot()})
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// adminRealm is the realm of the challenge sent to unauthenticated admin
+// API requests
+const adminRealm = "mockingjay-admin"
+
+// adminToken is a bearer token accepted by the admin API
+type adminToken struct {
+	name   string
+	token  string
+	scopes []string
+}
+
+// newAdminTokens resolves the tokens accepted by the admin API
+func newAdminTokens(ac config.AdminConfig) ([]adminToken, error) {
+	tokens := make([]adminToken, 0, len(ac.Tokens))
+	for _, tc := range ac.Tokens {
+		token, err := tc.GetToken()
+		if err != nil {
+			return nil, fmt.Errorf("admin token %q: %w", tc.Name, err)
+		}
+		tokens = append(tokens, adminToken{name: tc.Name, token: token, scopes: tc.Scopes})
+	}
+	return tokens, nil
+}
+
+// requireScope wraps an admin endpoint so it's only served to requests
+// bearing a token granted scope. Without tokens configured, the admin API is
+// open and every request is served.
+func (s *Server) requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokens := s.routingFor(r).adminTokens
+		if len(tokens) == 0 {
+			next(w, r)
+			return
+		}
+
+		bearer, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", adminRealm))
+			writeAdminError(w, http.StatusUnauthorized, "the admin API requires a bearer token")
+			return
+		}
+
+		idx := slices.IndexFunc(tokens, func(t adminToken) bool {
+			return subtle.ConstantTimeCompare([]byte(t.token), []byte(bearer)) == 1
+		})
+		if idx < 0 {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=%q", adminRealm, "invalid_token"))
+			writeAdminError(w, http.StatusUnauthorized, "invalid admin API token")
+			return
+		}
+
+		if token := tokens[idx]; !slices.Contains(token.scopes, scope) {
+			s.logger.Warn("admin API request denied",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"token", token.name,
+				"scope", scope,
+			)
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=%q, scope=%q", adminRealm, "insufficient_scope", scope))
+			writeAdminError(w, http.StatusForbidden, fmt.Sprintf("token %q lacks the %q scope", token.name, scope))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// bearerToken returns the token of a request's Authorization header, if it
+// uses the Bearer scheme
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
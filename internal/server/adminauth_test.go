@@ -0,0 +1,96 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_AdminTokens(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}})
+	cfg.Admin.Tokens = []config.AdminTokenConfig{
+		{Name: "viewer", Token: "viewer-token", Scopes: []string{config.AdminScopeRead}},
+		{Name: "ci", Token: "ci-token", Scopes: []string{config.AdminScopeRead, config.AdminScopeReset}},
+		{Name: "admin", Token: "admin-token", Scopes: config.AdminScopes},
+	}
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		authorization string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{name: "no token", method: "GET", path: "/__admin/requests", wantStatus: 401, wantChallenge: `Bearer realm="mockingjay-admin"`},
+		{name: "basic credentials", method: "GET", path: "/__admin/requests", authorization: "Basic dXNlcjpwYXNz", wantStatus: 401},
+		{name: "unknown token", method: "GET", path: "/__admin/requests", authorization: "Bearer nope", wantStatus: 401, wantChallenge: `error="invalid_token"`},
+		{name: "read", method: "GET", path: "/__admin/requests", authorization: "Bearer viewer-token", wantStatus: 200},
+		{name: "lowercase scheme", method: "GET", path: "/__admin/requests", authorization: "bearer viewer-token", wantStatus: 200},
+		{name: "reset without scope", method: "DELETE", path: "/__admin/requests", authorization: "Bearer viewer-token", wantStatus: 403, wantChallenge: `scope="reset"`},
+		{name: "reset", method: "DELETE", path: "/__admin/requests", authorization: "Bearer ci-token", wantStatus: 200},
+		{name: "mutate without scope", method: "PUT", path: "/__admin/log-level", body: `{"level": "debug"}`, authorization: "Bearer ci-token", wantStatus: 403},
+		{name: "mutate", method: "POST", path: "/__admin/routes", body: `{"path": "/new", "method": "GET", "template": "ok"}`, authorization: "Bearer admin-token", wantStatus: 201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers map[string]string
+			if tt.authorization != "" {
+				headers = map[string]string{"Authorization": tt.authorization}
+			}
+			resp, err := ts.makeRequest(tt.method, tt.path, strings.NewReader(tt.body), headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode, body)
+			}
+			if challenge := resp.Header.Get("WWW-Authenticate"); !strings.Contains(challenge, tt.wantChallenge) {
+				t.Errorf("Expected challenge containing %q, got %q", tt.wantChallenge, challenge)
+			}
+		})
+	}
+
+	// Routes served by the mock aren't affected
+	resp, err := ts.makeRequest("GET", "/", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "ok" {
+		t.Errorf("Expected mock routes to stay open, got %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestServer_Integration_AdminTokensReload(t *testing.T) {
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}}))
+
+	status := func() int {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/__admin/metrics", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+		return resp.StatusCode
+	}
+
+	if code := status(); code != 200 {
+		t.Errorf("Expected the admin API to be open without tokens, got %d", code)
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}})
+	cfg.Admin.Tokens = []config.AdminTokenConfig{{Name: "ci", Token: "secret", Scopes: []string{config.AdminScopeRead}}}
+	if err := ts.Server.applyConfig(cfg); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	if code := status(); code != 401 {
+		t.Errorf("Expected the admin API to require a token after the reload, got %d", code)
+	}
+}
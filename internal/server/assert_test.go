@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_AssertResponse(t *testing.T) {
+	assertion := &config.AssertResponseConfig{JSONValid: true, MaxBytes: 32}
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/valid", Method: "GET", Template: `{"id": {{ .Query.Get "id" }}}`, AssertResponse: assertion},
+		{Path: "/large", Method: "GET", Template: `{"padding": "` + strings.Repeat("x", 64) + `"}`, AssertResponse: assertion},
+		{Path: "/unchecked", Method: "GET", Template: `{"id": ,}`},
+	})
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/valid?id=42", wantStatus: http.StatusOK},
+		{path: "/valid", wantStatus: http.StatusInternalServerError}, // Renders {"id": }
+		{path: "/large", wantStatus: http.StatusInternalServerError},
+		{path: "/unchecked", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode, body)
+			}
+			if tt.wantStatus == http.StatusOK && strings.Contains(body, "Internal Server Error") {
+				t.Errorf("Expected the rendered body, got %q", body)
+			}
+		})
+	}
+}
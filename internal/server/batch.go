@@ -0,0 +1,343 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// batchContextKey marks the sub-requests of a batch, so batches can't nest
+type batchContextKey struct{}
+
+// batchRequest is a sub-request of a JSON batch
+type batchRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"` // JSON sent as-is, or a string sent as its contents
+}
+
+// batchResponse is the response to a sub-request of a JSON batch
+type batchResponse struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"` // JSON bodies as-is, anything else as a string
+}
+
+// subRequest is a sub-request of a batch, ready to be served
+type subRequest struct {
+	ID  string // Identifier echoed in the sub-request's response
+	Req *http.Request
+}
+
+// serveBatch splits a batch request into its sub-requests, serves them in
+// order with the matching routes and writes the combined responses. Batches
+// are JSON, unless sent as multipart/mixed. Sub-requests are served with the
+// routing of the batch request.
+func (s *Server) serveBatch(rt *routing, w http.ResponseWriter, r *http.Request, batch *router.Batch) int {
+	if r.Context().Value(batchContextKey{}) != nil {
+		s.handleInvalidBatch(w, r, errors.New("batch requests cannot be nested"))
+		return http.StatusBadRequest
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+		middleware.WritePayloadTooLarge(w, r, tooLarge.Limit)
+		return http.StatusRequestEntityTooLarge
+	}
+	if err != nil {
+		s.handleInvalidBatch(w, r, fmt.Errorf("failed to read batch: %w", err))
+		return http.StatusBadRequest
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	multipartBatch := mediaType == "multipart/mixed"
+
+	var requests []subRequest
+	var wrapped bool
+	if multipartBatch {
+		requests, err = parseMultipartBatch(r, raw, params["boundary"])
+	} else {
+		requests, wrapped, err = parseJSONBatch(r, raw)
+	}
+	if err != nil {
+		s.handleInvalidBatch(w, r, err)
+		return http.StatusBadRequest
+	}
+
+	if len(requests) > batch.MaxRequests {
+		s.handleInvalidBatch(w, r, fmt.Errorf("batch has %d requests, at most %d are allowed", len(requests), batch.MaxRequests))
+		return http.StatusBadRequest
+	}
+
+	recorders := make([]*httptest.ResponseRecorder, 0, len(requests))
+	for _, sub := range requests {
+		recorders = append(recorders, s.serveSubRequest(rt, sub.Req))
+	}
+
+	if multipartBatch {
+		writeMultipartBatch(w, requests, recorders)
+		return http.StatusOK
+	}
+
+	responses := make([]batchResponse, 0, len(requests))
+	for i, rec := range recorders {
+		responses = append(responses, newBatchResponse(requests[i].ID, rec))
+	}
+	if wrapped {
+		writeJSON(w, http.StatusOK, map[string][]batchResponse{"responses": responses})
+	} else {
+		writeJSON(w, http.StatusOK, responses)
+	}
+	return http.StatusOK
+}
+
+// serveSubRequest serves a sub-request of a batch with the matching route,
+// recording it in the journal like any other request
+func (s *Server) serveSubRequest(rt *routing, r *http.Request) *httptest.ResponseRecorder {
+	start := time.Now()
+	rec := httptest.NewRecorder()
+
+	journalBody, truncated := captureRequestBody(r, s.journal.bodyLimit())
+	rw := middleware.NewResponseWriter(rec)
+	route := s.routeRequest(rt, rw, r, start)
+	s.journal.add(newJournalEntry(r, journalBody, truncated, rw.Status(), route, start))
+
+	return rec
+}
+
+// parseJSONBatch parses a JSON batch, either an array of requests or an
+// object with a "requests" array, reporting whether it was the latter so the
+// responses are wrapped the same way
+func parseJSONBatch(parent *http.Request, raw []byte) ([]subRequest, bool, error) {
+	var requests []batchRequest
+	wrapped := !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("["))
+
+	if wrapped {
+		var body struct {
+			Requests []batchRequest `json:"requests"`
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil, false, fmt.Errorf("invalid JSON batch: %w", err)
+		}
+		if body.Requests == nil {
+			return nil, false, errors.New(`JSON batch must be an array of requests or an object with a "requests" array`)
+		}
+		requests = body.Requests
+	} else if err := json.Unmarshal(raw, &requests); err != nil {
+		return nil, false, fmt.Errorf("invalid JSON batch: %w", err)
+	}
+
+	subs := make([]subRequest, 0, len(requests))
+	for i, br := range requests {
+		if br.ID == "" {
+			br.ID = strconv.Itoa(i + 1)
+		}
+
+		req, err := br.newRequest(parent)
+		if err != nil {
+			return nil, false, fmt.Errorf("request %q: %w", br.ID, err)
+		}
+		subs = append(subs, subRequest{ID: br.ID, Req: req})
+	}
+
+	return subs, wrapped, nil
+}
+
+// newRequest builds the HTTP request of a JSON batch sub-request
+func (br batchRequest) newRequest(parent *http.Request) (*http.Request, error) {
+	method := strings.ToUpper(br.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	var contentType string
+	if len(br.Body) > 0 && string(br.Body) != "null" {
+		var text string
+		if err := json.Unmarshal(br.Body, &text); err == nil {
+			body = strings.NewReader(text)
+		} else {
+			body, contentType = bytes.NewReader(br.Body), "application/json"
+		}
+	}
+
+	target, err := batchTarget(br.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(parent.Context(), method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range br.Headers {
+		req.Header.Set(name, value)
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return asSubRequest(parent, req, target), nil
+}
+
+// parseMultipartBatch parses a multipart/mixed batch, whose parts are
+// application/http requests
+func parseMultipartBatch(parent *http.Request, raw []byte, boundary string) ([]subRequest, error) {
+	if boundary == "" {
+		return nil, errors.New("multipart batch has no boundary")
+	}
+
+	var subs []subRequest
+	reader := multipart.NewReader(bytes.NewReader(raw), boundary)
+	for i := 1; ; i++ {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return subs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart batch: %w", err)
+		}
+
+		id := part.Header.Get("Content-ID")
+		req, err := readBatchPart(parent, part)
+		if err != nil {
+			if id == "" {
+				id = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("request %q: %w", id, err)
+		}
+		subs = append(subs, subRequest{ID: id, Req: req})
+	}
+}
+
+// readBatchPart reads the HTTP request in a part of a multipart batch
+func readBatchPart(parent *http.Request, part *multipart.Part) (*http.Request, error) {
+	data, err := io.ReadAll(part)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP request: %w", err)
+	}
+
+	// Parts often omit Content-Length, in which case the rest of the part is
+	// the body
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(body) == 0 {
+		body, _ = io.ReadAll(reader)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	target, err := batchTarget(req.RequestURI)
+	if err != nil {
+		return nil, err
+	}
+	return asSubRequest(parent, req.WithContext(parent.Context()), target), nil
+}
+
+// batchTarget returns the path and query a sub-request is sent to, ignoring
+// the scheme and host of absolute URLs
+func batchTarget(raw string) (*url.URL, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("url cannot be empty")
+	}
+
+	target, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", raw, err)
+	}
+
+	path := target.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return &url.URL{Path: path, RawQuery: target.RawQuery}, nil
+}
+
+// asSubRequest makes req a sub-request of the batch request parent, sent to
+// target from the same client
+func asSubRequest(parent, req *http.Request, target *url.URL) *http.Request {
+	req = req.WithContext(context.WithValue(req.Context(), batchContextKey{}, true))
+	req.URL = target
+	req.RequestURI = target.RequestURI()
+	req.Host = parent.Host
+	req.RemoteAddr = parent.RemoteAddr
+	return req
+}
+
+// newBatchResponse builds the JSON batch response of a served sub-request
+func newBatchResponse(id string, rec *httptest.ResponseRecorder) batchResponse {
+	resp := batchResponse{ID: id, Status: rec.Code, Headers: make(map[string]string)}
+	for name := range rec.Header() {
+		resp.Headers[name] = rec.Header().Get(name)
+	}
+
+	switch body := rec.Body.Bytes(); {
+	case len(body) == 0:
+	case json.Valid(body):
+		resp.Body = body
+	default:
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
+
+// writeMultipartBatch writes the responses of a multipart batch as
+// application/http parts, echoing the Content-ID of each request
+func writeMultipartBatch(w http.ResponseWriter, requests []subRequest, recorders []*httptest.ResponseRecorder) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	for i, rec := range recorders {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "application/http")
+		header.Set("Content-Transfer-Encoding", "binary")
+		if requests[i].ID != "" {
+			header.Set("Content-ID", requests[i].ID)
+		}
+
+		part, _ := mw.CreatePart(header) // Writes to a buffer can't fail
+		resp := rec.Result()
+		resp.ContentLength = int64(rec.Body.Len())
+		_ = resp.Write(part)
+	}
+	_ = mw.Close()
+
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleInvalidBatch handles batch requests that can't be split into
+// sub-requests
+func (s *Server) handleInvalidBatch(w http.ResponseWriter, r *http.Request, err error) {
+	detail := err.Error()
+	problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidBatch, detail, "400 Bad Request: "+detail+"\n")
+}
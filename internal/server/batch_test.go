@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func batchTestConfig() *config.Config {
+	return createTestConfig([]config.RouteConfig{
+		{Path: "/$batch", Method: "POST", Batch: &config.BatchConfig{MaxRequests: 3}},
+		{Path: "/^/users/(?P<id>\\d+)$/", Method: "GET", Template: `{"id":{{ .Params.id }}}`},
+		{Path: "/users", Method: "POST", Template: `{"name":"{{ .Body.name }}"}`, ResponseHeaders: map[string]string{"Location": "/users/3"}},
+		{Path: "/ping", Method: "GET", Template: "pong"},
+	})
+}
+
+func TestServer_Integration_JSONBatch(t *testing.T) {
+	ts := NewTestServer(t, batchTestConfig())
+
+	batch := func(body string) (int, string) {
+		t.Helper()
+		resp, err := ts.makeRequest("POST", "/$batch", strings.NewReader(body), map[string]string{"Content-Type": "application/json"})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp.StatusCode, readResponseBody(t, resp)
+	}
+
+	// Arrays of requests are answered with arrays of responses
+	status, body := batch(`[
+		{"id": "a", "method": "GET", "url": "/users/1"},
+		{"method": "POST", "url": "/users", "body": {"name": "Ada"}},
+		{"url": "https://api.example.com/ping"}
+	]`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", status, body)
+	}
+
+	var responses []batchResponse
+	if err := json.Unmarshal([]byte(body), &responses); err != nil {
+		t.Fatalf("Failed to parse batch response %q: %v", body, err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(responses))
+	}
+
+	expected := []struct {
+		id     string
+		status int
+		body   string
+	}{
+		{"a", http.StatusOK, `{"id":1}`},
+		{"2", http.StatusOK, `{"name":"Ada"}`},
+		{"3", http.StatusOK, `"pong"`},
+	}
+	for i, e := range expected {
+		got := responses[i]
+		compact, _ := json.Marshal(got.Body)
+		if got.ID != e.id || got.Status != e.status || string(compact) != e.body {
+			t.Errorf("Response %d: expected %s %d %s, got %s %d %s", i, e.id, e.status, e.body, got.ID, got.Status, compact)
+		}
+	}
+	if got := responses[1].Headers["Location"]; got != "/users/3" {
+		t.Errorf("Expected the sub-response headers, got Location %q", got)
+	}
+
+	// Objects with a "requests" array are answered with a "responses" array,
+	// and unmatched sub-requests get their own 404
+	status, body = batch(`{"requests": [{"id": "1", "method": "DELETE", "url": "/users/1"}]}`)
+	var wrapped struct {
+		Responses []batchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal([]byte(body), &wrapped); err != nil || status != http.StatusOK {
+		t.Fatalf("Expected wrapped responses, got %d %q: %v", status, body, err)
+	}
+	if len(wrapped.Responses) != 1 || wrapped.Responses[0].Status != http.StatusNotFound {
+		t.Errorf("Expected a single 404 response, got %+v", wrapped.Responses)
+	}
+
+	// Sub-requests are recorded in the journal along with the batch itself
+	resp, err := ts.makeRequest("GET", "/__admin/requests/count", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var count map[string]int
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &count); err != nil {
+		t.Fatalf("Failed to parse count: %v", err)
+	}
+	if count["count"] != 6 {
+		t.Errorf("Expected 6 journaled requests, got %d", count["count"])
+	}
+
+	invalid := []struct {
+		name     string
+		body     string
+		contains string
+	}{
+		{"malformed JSON", `[{"url": `, "invalid JSON batch"},
+		{"object without requests", `{"calls": []}`, `"requests" array`},
+		{"missing url", `[{"method": "GET"}]`, `request "1": url cannot be empty`},
+		{"too many requests", `[{"url": "/ping"}, {"url": "/ping"}, {"url": "/ping"}, {"url": "/ping"}]`, "at most 3 are allowed"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := batch(tt.body)
+			if status != http.StatusBadRequest || !strings.Contains(body, tt.contains) {
+				t.Errorf("Expected status 400 containing %q, got %d %q", tt.contains, status, body)
+			}
+		})
+	}
+
+	// Batches can't be nested
+	_, body = batch(`[{"method": "POST", "url": "/$batch", "body": []}]`)
+	if err := json.Unmarshal([]byte(body), &responses); err != nil || len(responses) != 1 || responses[0].Status != http.StatusBadRequest {
+		t.Errorf("Expected a nested batch to be rejected, got %q", body)
+	}
+}
+
+func TestServer_Integration_MultipartBatch(t *testing.T) {
+	ts := NewTestServer(t, batchTestConfig())
+
+	body := strings.Join([]string{
+		"--batch_1",
+		"Content-Type: application/http",
+		"Content-ID: first",
+		"",
+		"GET /users/7 HTTP/1.1",
+		"",
+		"",
+		"--batch_1",
+		"Content-Type: application/http",
+		"Content-ID: second",
+		"",
+		"POST /users HTTP/1.1",
+		"Content-Type: application/json",
+		"",
+		`{"name":"Grace"}`,
+		"--batch_1--",
+		"",
+	}, "\r\n")
+
+	resp, err := ts.makeRequest("POST", "/$batch", strings.NewReader(body), map[string]string{"Content-Type": "multipart/mixed; boundary=batch_1"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Expected a multipart/mixed response, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	expected := []struct {
+		id   string
+		body string
+	}{
+		{"first", `{"id":7}`},
+		{"second", `{"name":"Grace"}`},
+	}
+
+	reader := multipart.NewReader(resp.Body, params["boundary"])
+	for _, e := range expected {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Expected a response part for %q, got %v", e.id, err)
+		}
+		if got := part.Header.Get("Content-ID"); got != e.id {
+			t.Errorf("Expected Content-ID %q, got %q", e.id, got)
+		}
+
+		subResp, err := http.ReadResponse(bufio.NewReader(part), nil)
+		if err != nil {
+			t.Fatalf("Failed to read response of %q: %v", e.id, err)
+		}
+		subBody, _ := io.ReadAll(subResp.Body)
+		if subResp.StatusCode != http.StatusOK || string(subBody) != e.body {
+			t.Errorf("Expected %q to answer 200 %s, got %d %s", e.id, e.body, subResp.StatusCode, subBody)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("Expected exactly %d response parts, got %v", len(expected), err)
+	}
+
+	// Multipart batches need a boundary
+	resp, err = ts.makeRequest("POST", "/$batch", strings.NewReader(body), map[string]string{"Content-Type": "multipart/mixed"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if readResponseBody(t, resp); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a boundary, got %d", resp.StatusCode)
+	}
+}
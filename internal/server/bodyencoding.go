@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// renderEncodedBody renders tmpl and writes the body it encodes to w, for
+// routes whose templates produce binary payloads as text
+func (s *Server) renderEncodedBody(rt *routing, w io.Writer, encoding string, tmpl *template.Template, ctx *templatepkg.TemplateContext) error {
+	var rendered bytes.Buffer
+	if err := rt.engine.ExecuteTemplate(tmpl, &rendered, ctx); err != nil {
+		return err
+	}
+
+	body, err := decodeBody(encoding, rendered.String())
+	if err != nil {
+		return fmt.Errorf("failed to decode %s body: %w", encoding, err)
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// decodeBody decodes a rendered body. Whitespace is ignored, so templates
+// can wrap and indent what they render, and base64 padding is optional.
+func decodeBody(encoding, rendered string) ([]byte, error) {
+	compact := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, rendered)
+
+	switch encoding {
+	case config.BodyEncodingBase64:
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(compact, "="))
+	default:
+		return nil, fmt.Errorf("unknown body encoding %q", encoding)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		rendered    string
+		want        string
+		errContains string
+	}{
+		{name: "padded", rendered: "aGk=", want: "hi"},
+		{name: "unpadded", rendered: "aGk", want: "hi"},
+		{name: "wrapped and indented", rendered: "\n  iVBO\n  Rw0K\n", want: "\x89PNG\r\n"},
+		{name: "empty", rendered: "  ", want: ""},
+		{name: "invalid", rendered: "not base64!", errContains: "illegal base64 data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeBody(config.BodyEncodingBase64, tt.rendered)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_BodyEncoding(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/avatar.png", Method: "GET", Template: "{{ fakeImagePNG 8 4 | b64enc }}", BodyEncoding: config.BodyEncodingBase64},
+		{
+			Path:            "/blob",
+			Method:          "GET",
+			Template:        "{{ rawBytes 0 1 2 255 | b64enc }}",
+			BodyEncoding:    config.BodyEncodingBase64,
+			ResponseHeaders: map[string]string{"Content-Type": "application/octet-stream"},
+		},
+		{Path: "/broken", Method: "GET", Template: "not base64!", BodyEncoding: config.BodyEncodingBase64},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	t.Run("image", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/avatar.png", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body := readResponseBody(t, resp)
+
+		img, err := png.Decode(bytes.NewReader([]byte(body)))
+		if err != nil {
+			t.Fatalf("Expected a PNG body, got %v", err)
+		}
+		if size := img.Bounds().Size(); size.X != 8 || size.Y != 4 {
+			t.Errorf("Expected an 8x4 image, got %v", size)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("Expected a sniffed Content-Type of image/png, got %q", got)
+		}
+	})
+
+	t.Run("raw bytes", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/blob", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if body := readResponseBody(t, resp); body != "\x00\x01\x02\xff" {
+			t.Errorf("Expected the decoded bytes, got %q", body)
+		}
+	})
+
+	t.Run("invalid base64", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/broken", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body := readResponseBody(t, resp)
+		if resp.StatusCode != 500 || !strings.Contains(body, "template") {
+			t.Errorf("Expected a 500 template error, got %d %q", resp.StatusCode, body)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_BodyFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Bytes that would break the template engine, and aren't valid UTF-8
+	png := "\x89PNG\r\n\x1a\n{{ .Body }}\xff\x00"
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte(png), 0o644); err != nil {
+		t.Fatalf("Failed to write body file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.fixture"), []byte("%PDF-1.7"), 0o644); err != nil {
+		t.Fatalf("Failed to write body file: %v", err)
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/logo.png", Method: "GET", BodyFile: filepath.Join(dir, "logo.png")},
+		{Path: "/sniffed", Method: "GET", BodyFile: filepath.Join(dir, "user.fixture")},
+		{
+			Path:            "/override",
+			Method:          "GET",
+			BodyFile:        filepath.Join(dir, "logo.png"),
+			ResponseHeaders: map[string]string{"Content-Type": "application/x-custom", "X-Request": "{{ .RequestID }}"},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path        string
+		body        string
+		contentType string
+	}{
+		{path: "/logo.png", body: png, contentType: "image/png"},
+		{path: "/sniffed", body: "%PDF-1.7", contentType: "application/pdf"},
+		{path: "/override", body: png, contentType: "application/x-custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != 200 || body != tt.body {
+				t.Errorf("Expected the file's bytes, got %d %q", resp.StatusCode, body)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.contentType, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_BodyLimit(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/echo", Method: "POST", Template: "{{ .Body }}"},
+	})
+	cfg.Server.BodyLimit = 16
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "body within the limit", body: strings.NewReader("hello"), wantStatus: 200, wantBody: "hello"},
+		{name: "declared length over the limit", body: strings.NewReader(strings.Repeat("a", 17)), wantStatus: 413, wantBody: "limit of 16 bytes"},
+		{name: "chunked body over the limit", body: io.MultiReader(strings.NewReader(strings.Repeat("a", 17))), wantStatus: 413, wantBody: "limit of 16 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest("POST", "/echo", tt.body, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode, body)
+			}
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("Expected body containing %q, got %q", tt.wantBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"net/http"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// crc32cTable computes CRC32C checksums, which use the Castagnoli polynomial
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// setChecksumHeaders sets the checksums of body in the headers storage APIs
+// send them in, so clients verifying what they downloaded can be tested:
+// Content-MD5, Google Cloud Storage's x-goog-hash, Amazon S3's
+// x-amz-checksum-* and RFC 9530's Content-Digest. Every checksum is base64
+// encoded.
+func setChecksumHeaders(h http.Header, algorithms []string, body []byte) {
+	for _, algorithm := range algorithms {
+		switch algorithm {
+		case config.ChecksumMD5:
+			sum := md5.Sum(body)
+			value := base64.StdEncoding.EncodeToString(sum[:])
+			h.Set("Content-MD5", value)
+			h.Add("X-Goog-Hash", "md5="+value)
+
+		case config.ChecksumSHA1:
+			sum := sha1.Sum(body)
+			h.Set("X-Amz-Checksum-Sha1", base64.StdEncoding.EncodeToString(sum[:]))
+
+		case config.ChecksumSHA256:
+			sum := sha256.Sum256(body)
+			value := base64.StdEncoding.EncodeToString(sum[:])
+			h.Set("X-Amz-Checksum-Sha256", value)
+			h.Add("Content-Digest", "sha-256=:"+value+":")
+
+		case config.ChecksumSHA512:
+			sum := sha512.Sum512(body)
+			h.Add("Content-Digest", "sha-512=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+
+		case config.ChecksumCRC32:
+			h.Set("X-Amz-Checksum-Crc32", encodeCRC32(crc32.ChecksumIEEE(body)))
+
+		case config.ChecksumCRC32C:
+			value := encodeCRC32(crc32.Checksum(body, crc32cTable))
+			h.Set("X-Amz-Checksum-Crc32c", value)
+			h.Add("X-Goog-Hash", "crc32c="+value)
+		}
+	}
+}
+
+// encodeCRC32 returns a CRC32 checksum as its big-endian bytes in base64, the
+// way storage APIs send them
+func encodeCRC32(sum uint32) string {
+	return base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, sum))
+}
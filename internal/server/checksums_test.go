@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestSetChecksumHeaders(t *testing.T) {
+	h := http.Header{}
+	setChecksumHeaders(h, config.ChecksumAlgorithms, []byte("hello world"))
+
+	expected := map[string][]string{
+		"Content-Md5":           {"XrY7u+Ae7tCTyyK7j1rNww=="},
+		"X-Goog-Hash":           {"md5=XrY7u+Ae7tCTyyK7j1rNww==", "crc32c=yZRlqg=="},
+		"X-Amz-Checksum-Sha1":   {"Kq5sNclPz7QV2+lfQIuc6R7oRu0="},
+		"X-Amz-Checksum-Sha256": {"uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="},
+		"X-Amz-Checksum-Crc32":  {"DUoRhQ=="},
+		"X-Amz-Checksum-Crc32c": {"yZRlqg=="},
+		"Content-Digest": {
+			"sha-256=:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=:",
+			"sha-512=:MJ7MSJwS1utMxA9QyQLytNDtd+5RGnx6m808qG1M2G+YndNbxf9JlnDaNCVbRbDP2DDoH2Bdz33FVC6TrpzXbw==:",
+		},
+	}
+
+	for name, values := range expected {
+		if got := h.Values(name); !slices.Equal(got, values) {
+			t.Errorf("Expected %s %q, got %q", name, values, got)
+		}
+	}
+	if len(h) != len(expected) {
+		t.Errorf("Expected %d headers, got %v", len(expected), h)
+	}
+}
+
+func TestServer_Integration_Checksums(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:          "/object",
+			Method:        "GET",
+			Template:      "hello world",
+			EmitChecksums: []string{"md5", "crc32c"},
+		},
+		{
+			Path:          "/compressed",
+			Method:        "GET",
+			Template:      strings.Repeat("hello world ", 100),
+			Compression:   &config.CompressionConfig{},
+			EmitChecksums: []string{"md5"},
+		},
+		{
+			Path:          "/truncated",
+			Method:        "GET",
+			Template:      "hello world",
+			EmitChecksums: []string{"md5"},
+			Faults:        []config.FaultConfig{{Type: config.FaultTruncate}},
+		},
+		{Path: "/plain", Method: "GET", Template: "hello world"},
+	})
+	ts := NewTestServer(t, cfg)
+
+	// The test client asks for and decodes gzip on its own
+	tests := []struct {
+		path    string
+		wantMD5 string
+		wantGCS []string
+	}{
+		{path: "/object", wantMD5: "XrY7u+Ae7tCTyyK7j1rNww==", wantGCS: []string{"md5=XrY7u+Ae7tCTyyK7j1rNww==", "crc32c=yZRlqg=="}},
+		{path: "/truncated", wantMD5: "XrY7u+Ae7tCTyyK7j1rNww==", wantGCS: []string{"md5=XrY7u+Ae7tCTyyK7j1rNww=="}},
+		{path: "/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := resp.Header.Get("Content-MD5"); got != tt.wantMD5 {
+				t.Errorf("Expected Content-MD5 %q, got %q", tt.wantMD5, got)
+			}
+			if got := resp.Header.Values("X-Goog-Hash"); !slices.Equal(got, tt.wantGCS) {
+				t.Errorf("Expected x-goog-hash %q, got %q", tt.wantGCS, got)
+			}
+		})
+	}
+
+	// Compressed bodies are checksummed as they're sent, compressed
+	resp, err := ts.makeRequest("GET", "/compressed", nil, map[string]string{"Accept-Encoding": "gzip"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected a gzip response, got %q", resp.Header.Get("Content-Encoding"))
+	}
+	h := http.Header{}
+	setChecksumHeaders(h, []string{"md5"}, []byte(body))
+	if got := resp.Header.Get("Content-MD5"); got != h.Get("Content-MD5") {
+		t.Errorf("Expected the checksum of the compressed body %q, got %q", h.Get("Content-MD5"), got)
+	}
+}
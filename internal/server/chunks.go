@@ -0,0 +1,33 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// writeChunks writes a response body a line at a time, flushing each line
+// and pausing for delay between them, so clients reading the response as it
+// arrives see whole records trickle in. It stops with the request context's
+// error when the request is cancelled between lines.
+func writeChunks(w http.ResponseWriter, r *http.Request, body []byte, delay *router.Delay) error {
+	rc := http.NewResponseController(w)
+
+	for i, line := range bytes.SplitAfter(body, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if i > 0 {
+			if err := sleepContext(r.Context(), delay.Duration()); err != nil {
+				return err
+			}
+		}
+
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		_ = rc.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bufio"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_DelayFirstByte(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:           "/slow-start",
+			Method:         "GET",
+			Template:       "ready",
+			DelayFirstByte: &config.DelayConfig{Min: 150 * time.Millisecond, Max: 150 * time.Millisecond},
+		},
+	})
+	cfg.Server.DevMode = true
+	ts := NewTestServer(t, cfg)
+
+	start := time.Now()
+	resp, err := ts.makeRequest("GET", "/slow-start", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the response to start after at least 150ms, took %s", elapsed)
+	}
+	if got := resp.Header.Get(headerInjectedDelay); got != "150ms" {
+		t.Errorf("Expected injected delay header %q, got %q", "150ms", got)
+	}
+	if body := readResponseBody(t, resp); body != "ready" {
+		t.Errorf("Expected body %q, got %q", "ready", body)
+	}
+}
+
+func TestServer_Integration_DelayBetweenChunks(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:               "/events",
+			Method:             "GET",
+			Template:           "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n",
+			DelayBetweenChunks: &config.DelayConfig{Min: 100 * time.Millisecond, Max: 100 * time.Millisecond},
+		},
+	})
+	ts := NewTestServer(t, cfg)
+
+	start := time.Now()
+	resp, err := ts.makeRequest("GET", "/events", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The first line is sent right away, and each of the others after a pause
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("Expected the response to start right away, took %s", elapsed)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	want := []string{"{\"id\":1}\n", "{\"id\":2}\n", "{\"id\":3}\n"}
+	for i, expected := range want {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read line %d: %v", i+1, err)
+		}
+		if line != expected {
+			t.Errorf("Expected line %d to be %q, got %q", i+1, expected, line)
+		}
+		if minimum := time.Duration(i) * 100 * time.Millisecond; time.Since(start) < minimum {
+			t.Errorf("Expected line %d after at least %s, got it after %s", i+1, minimum, time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// headerClockTime is the request header setting the time on the mock's clock
+// for a single request
+const headerClockTime = "X-Mock-Time"
+
+// clockFor returns the clock a route's response is served with: the server's
+// clock, skewed by the route's own offset when it sets one, and frozen at a
+// fixed time for deterministic routes or when the server sets one
+func (rt *routing) clockFor(route *router.Route) templatepkg.Clock {
+	skew := rt.clockSkew
+	if route != nil && route.ClockSkew != nil {
+		skew = *route.ClockSkew
+	}
+	if route != nil && route.Deterministic != nil {
+		return templatepkg.NewClockAt(route.Deterministic.Time, skew)
+	}
+	if !rt.clockTime.IsZero() {
+		return templatepkg.NewClockAt(rt.clockTime, skew)
+	}
+	return templatepkg.NewClock(skew)
+}
+
+// requestClock returns the clock a request to route is served with, which is
+// set to the time of the X-Mock-Time header, unskewed, when the request sends
+// one. It fails when the header's time can't be parsed.
+func (rt *routing) requestClock(route *router.Route, r *http.Request) (templatepkg.Clock, error) {
+	value := r.Header.Get(headerClockTime)
+	if value == "" {
+		return rt.clockFor(route), nil
+	}
+
+	at, err := config.ParseClockTime(value)
+	if err != nil {
+		return templatepkg.Clock{}, err
+	}
+	return templatepkg.NewClockAt(at, 0), nil
+}
+
+// clockTime returns the time the server's clock is frozen at, or the zero time
+// when it follows the real one. The configuration was validated, so the time
+// parses.
+func clockTime(sc config.ServerConfig) time.Time {
+	at, _ := sc.GetClockTime()
+	return at
+}
+
+// setDateHeader sets the Date header from a skewed or fixed clock. Otherwise,
+// the header the HTTP server adds on its own is already correct.
+func setDateHeader(w http.ResponseWriter, clock templatepkg.Clock) {
+	if clock.Skew() != 0 || clock.Fixed() {
+		w.Header().Set("Date", clock.HTTPDate())
+	}
+}
+
+// handleInvalidTime handles requests whose X-Mock-Time header can't be parsed
+func (s *Server) handleInvalidTime(w http.ResponseWriter, r *http.Request, err error) {
+	detail := fmt.Sprintf("%s header: %s", headerClockTime, err)
+	problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidTime, detail, "400 Bad Request: "+detail+"\n")
+}
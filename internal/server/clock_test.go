@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_ClockSkew(t *testing.T) {
+	noSkew := time.Duration(0)
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/token",
+			Method:          "GET",
+			Template:        `{"exp":{{ .Clock.UnixIn "1h" }}}`,
+			ResponseHeaders: map[string]string{"Expires": `{{ .Clock.HTTPDateIn "1h" }}`},
+		},
+		{Path: "/accurate", Method: "GET", Template: "ok", ClockSkew: &noSkew},
+	})
+	cfg.Server.ClockSkew = -10 * time.Minute
+
+	ts := NewTestServer(t, cfg)
+
+	// dateOffset returns how far a response's Date header is from the real time
+	dateOffset := func(resp *http.Response) time.Duration {
+		t.Helper()
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			t.Fatalf("Failed to parse Date header %q: %v", resp.Header.Get("Date"), err)
+		}
+		return date.Sub(time.Now()).Round(time.Minute)
+	}
+
+	resp, err := ts.makeRequest("GET", "/token", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+
+	if offset := dateOffset(resp); offset != -10*time.Minute {
+		t.Errorf("Expected a Date 10 minutes behind, got %v", offset)
+	}
+
+	// Expirations are relative to the skewed clock, so they end up 50 minutes from now
+	exp, err := strconv.ParseInt(body[len(`{"exp":`):len(body)-1], 10, 64)
+	if err != nil {
+		t.Fatalf("Failed to parse expiry from %q: %v", body, err)
+	}
+	if offset := time.Until(time.Unix(exp, 0)).Round(time.Minute); offset != 50*time.Minute {
+		t.Errorf("Expected the token to expire in 50 minutes, got %v", offset)
+	}
+	expires, err := http.ParseTime(resp.Header.Get("Expires"))
+	if err != nil || expires.Unix() != exp {
+		t.Errorf("Expected Expires to match the token expiry, got %q (%v)", resp.Header.Get("Expires"), err)
+	}
+
+	// A route can opt out of the server's skew
+	resp, err = ts.makeRequest("GET", "/accurate", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if offset := dateOffset(resp); offset != 0 {
+		t.Errorf("Expected an accurate Date, got an offset of %v", offset)
+	}
+
+	// Built-in errors are served with the skewed clock too
+	resp, err = ts.makeRequest("GET", "/missing", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if offset := dateOffset(resp); offset != -10*time.Minute {
+		t.Errorf("Expected a not found Date 10 minutes behind, got %v", offset)
+	}
+}
+
+func TestServer_Integration_ClockTime(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/session",
+			Method:   "GET",
+			Template: `{"now":"{{ .Now.Format "2006-01-02T15:04:05Z07:00" }}","func":"{{ now | date "2006-01-02" }}","expired":{{ .Now.After (toDate "2006-01-02" "2030-06-01") }}}`,
+		},
+	})
+	cfg.Server.ClockTime = "2030-01-01T12:00:00Z"
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name       string
+		mockTime   string
+		wantStatus int
+		wantBody   string
+		wantDate   string
+	}{
+		{
+			name:       "configured time",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"now":"2030-01-01T12:00:00Z","func":"2030-01-01","expired":false}`,
+			wantDate:   "Tue, 01 Jan 2030 12:00:00 GMT",
+		},
+		{
+			name:       "time from the request",
+			mockTime:   "2030-07-01T08:00:00+02:00",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"now":"2030-07-01T06:00:00Z","func":"2030-07-01","expired":true}`,
+			wantDate:   "Mon, 01 Jul 2030 06:00:00 GMT",
+		},
+		{
+			name:       "invalid time from the request",
+			mockTime:   "next week",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.mockTime != "" {
+				headers[headerClockTime] = tt.mockTime
+			}
+
+			resp, err := ts.makeRequest("GET", "/session", nil, headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode, body)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("Expected body %s, got %s", tt.wantBody, body)
+			}
+			if tt.wantDate != "" && resp.Header.Get("Date") != tt.wantDate {
+				t.Errorf("Expected Date %q, got %q", tt.wantDate, resp.Header.Get("Date"))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// compressResponse applies a route's compression to a response body and
+// returns the body to send. Without a violation, the body is only compressed
+// for clients accepting the encoding, while violations apply to every
+// response, whatever the client accepts.
+func compressResponse(w http.ResponseWriter, r *http.Request, c *router.Compression, body []byte) ([]byte, error) {
+	if c == nil || len(body) == 0 {
+		return body, nil
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if c.Violation == "" && !c.Accepted(r.Header.Get("Accept-Encoding")) {
+		return body, nil
+	}
+
+	// Compress first, so a failure leaves the headers untouched
+	passes := 1
+	switch c.Violation {
+	case config.CompressionMislabel:
+		passes = 0
+	case config.CompressionDouble:
+		passes = 2
+	}
+	for range passes {
+		compressed, err := encodeBody(c.Encoding, body)
+		if err != nil {
+			return nil, err
+		}
+		body = compressed
+	}
+
+	if c.Violation != config.CompressionUnlabeled {
+		w.Header().Set("Content-Encoding", c.Encoding)
+	}
+	return body, nil
+}
+
+// encodeBody compresses a body with the given content coding
+func encodeBody(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var zw io.WriteCloser
+	switch encoding {
+	case config.CompressionDeflate:
+		zw = zlib.NewWriter(&buf)
+	default:
+		zw = gzip.NewWriter(&buf)
+	}
+
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// decompress decodes a body compressed with the given content coding
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var r io.ReadCloser
+	var err error
+	switch encoding {
+	case config.CompressionDeflate:
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	default:
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	}
+	if err != nil {
+		t.Fatalf("Failed to read %s body %q: %v", encoding, body, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to decompress %s body: %v", encoding, err)
+	}
+	return string(data)
+}
+
+func TestServer_Integration_Compression(t *testing.T) {
+	const payload = `{"message": "hello"}`
+	route := func(path string, compression config.CompressionConfig) config.RouteConfig {
+		return config.RouteConfig{Path: path, Method: "GET", Template: payload, Compression: &compression}
+	}
+
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{
+		route("/negotiated", config.CompressionConfig{}),
+		route("/deflate", config.CompressionConfig{Encoding: config.CompressionDeflate}),
+		route("/mislabel", config.CompressionConfig{Violation: config.CompressionMislabel}),
+		route("/unlabeled", config.CompressionConfig{Violation: config.CompressionUnlabeled}),
+		route("/ignore-accept", config.CompressionConfig{Violation: config.CompressionIgnoreAccept}),
+		route("/double", config.CompressionConfig{Violation: config.CompressionDouble}),
+	}))
+
+	// Keep the client from negotiating and decompressing on its own
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string // Expected Content-Encoding header
+		layers         int    // Times the body is compressed
+		layerEncoding  string
+	}{
+		{name: "accepted", path: "/negotiated", acceptEncoding: "gzip, deflate", wantEncoding: "gzip", layers: 1},
+		{name: "identity only", path: "/negotiated", acceptEncoding: "identity"},
+		{name: "no header", path: "/negotiated"},
+		{name: "deflate", path: "/deflate", acceptEncoding: "deflate", wantEncoding: "deflate", layers: 1, layerEncoding: config.CompressionDeflate},
+		{name: "deflate not accepted", path: "/deflate", acceptEncoding: "gzip"},
+		{name: "mislabel", path: "/mislabel", acceptEncoding: "gzip", wantEncoding: "gzip"},
+		{name: "unlabeled", path: "/unlabeled", acceptEncoding: "identity", layers: 1},
+		{name: "ignore accept", path: "/ignore-accept", acceptEncoding: "identity", wantEncoding: "gzip", layers: 1},
+		{name: "double", path: "/double", acceptEncoding: "gzip", wantEncoding: "gzip", layers: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.BaseURL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+
+			if got := resp.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Expected Content-Encoding %q, got %q", tt.wantEncoding, got)
+			}
+			if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Expected Vary: Accept-Encoding, got %q", got)
+			}
+
+			got := string(body)
+			for range tt.layers {
+				got = decompress(t, tt.layerEncoding, []byte(got))
+			}
+			if got != payload {
+				t.Errorf("Expected body %q after %d decompressions, got %q", payload, tt.layers, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// Metrics of routes with a concurrency limit, reported for each route as
+// name{route="METHOD /pattern"}
+const (
+	metricRouteInFlight   = "route_requests_in_flight"      // Requests holding a slot
+	metricRouteQueueDepth = "route_queue_depth"             // Requests waiting for a slot
+	metricRouteQueued     = "route_requests_queued_total"   // Requests that had to wait for a slot
+	metricRouteRejected   = "route_requests_rejected_total" // Requests turned away by a full queue or a queue timeout
+	metricRouteQueueWait  = "route_queue_wait_ms_total"     // Milliseconds requests spent waiting for a slot
+	metricRouteQueueMaxMs = "route_queue_wait_max_ms"       // Longest wait for a slot, in milliseconds
+)
+
+// routeMetric returns the name of a metric of route
+func routeMetric(name string, route *router.Route) string {
+	return fmt.Sprintf("%s{route=%q}", name, scenarioRouteID(route))
+}
+
+// acquireSlot waits for a slot of a route with a concurrency limit, recording
+// the wait in inj and the server's metrics. When no slot could be had, because
+// the queue was full, the request waited too long or it was cancelled, it
+// answers the request and returns false with the status it answered with.
+// Requests that got a slot must release it with releaseSlot.
+func (s *Server) acquireSlot(rt *routing, w http.ResponseWriter, r *http.Request, route *router.Route, inj *injections, start time.Time) (int, bool) {
+	depth := routeMetric(metricRouteQueueDepth, route)
+
+	queued := false
+	wait, err := route.Concurrency.Acquire(r.Context(), func() {
+		queued = true
+		s.metrics.Inc(routeMetric(metricRouteQueued, route))
+		s.metrics.Add(depth, 1)
+	})
+	if queued {
+		s.metrics.Add(depth, -1)
+		s.metrics.Add(routeMetric(metricRouteQueueWait, route), wait.Milliseconds())
+		s.metrics.Max(routeMetric(metricRouteQueueMaxMs, route), wait.Milliseconds())
+	}
+	inj.QueueWait = &wait
+
+	if err == nil {
+		s.metrics.Add(routeMetric(metricRouteInFlight, route), 1)
+		return 0, true
+	}
+
+	if rt.devMode {
+		inj.setHeaders(w.Header())
+	}
+
+	switch {
+	case errors.Is(err, router.ErrQueueFull), errors.Is(err, router.ErrQueueTimeout):
+		s.metrics.Inc(routeMetric(metricRouteRejected, route))
+		s.handleQueueFull(w, r, route, err)
+		return http.StatusServiceUnavailable, false
+
+	default:
+		return s.handleRequestTimeout(w, r, time.Since(start)), false
+	}
+}
+
+// releaseSlot frees the slot a request got with acquireSlot
+func (s *Server) releaseSlot(route *router.Route) {
+	s.metrics.Add(routeMetric(metricRouteInFlight, route), -1)
+	route.Concurrency.Release()
+}
+
+// handleQueueFull handles requests turned away by a route's concurrency limit
+func (s *Server) handleQueueFull(w http.ResponseWriter, r *http.Request, route *router.Route, err error) {
+	detail := fmt.Sprintf("the route reached its concurrency limit of %d and %s", route.Concurrency.Limit, err)
+	problem.Write(w, r, http.StatusServiceUnavailable, problem.CodeQueueFull, detail, "503 Service Unavailable: "+detail+"\n")
+
+	s.logger.Warn("request rejected by concurrency limit",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"route", route.Pattern,
+		"reason", err,
+	)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_Concurrency(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:        "/slow",
+			Method:      "GET",
+			Template:    "done",
+			Delay:       &config.DelayConfig{Min: 100 * time.Millisecond, Max: 100 * time.Millisecond},
+			Concurrency: &config.ConcurrencyConfig{Limit: 1},
+		},
+	})
+	cfg.Server.DevMode = true
+	ts := NewTestServer(t, cfg)
+
+	// Three requests at once are served one after the other
+	var wg sync.WaitGroup
+	waits := make([]string, 3)
+	for i := range waits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := ts.makeRequest("GET", "/slow", nil, nil)
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			if body := readResponseBody(t, resp); resp.StatusCode != http.StatusOK || body != "done" {
+				t.Errorf("Expected 200 done, got %d %q", resp.StatusCode, body)
+			}
+			waits[i] = resp.Header.Get(headerQueueWait)
+		}()
+	}
+	wg.Wait()
+
+	longest := time.Duration(0)
+	for _, wait := range waits {
+		d, err := time.ParseDuration(wait)
+		if err != nil {
+			t.Fatalf("Expected %s to hold a duration, got %q", headerQueueWait, wait)
+		}
+		longest = max(longest, d)
+	}
+	if longest < 150*time.Millisecond {
+		t.Errorf("Expected the last request to wait for the other two, waits were %v", waits)
+	}
+
+	route := `{route="GET /slow"}`
+	if got := ts.metrics.Get(metricRouteQueued + route); got != 2 {
+		t.Errorf("Expected 2 queued requests, got %d", got)
+	}
+	if got := ts.metrics.Get(metricRouteQueueDepth + route); got != 0 {
+		t.Errorf("Expected an empty queue, got %d", got)
+	}
+	if got := ts.metrics.Get(metricRouteInFlight + route); got != 0 {
+		t.Errorf("Expected no requests in flight, got %d", got)
+	}
+	if total, longest := ts.metrics.Get(metricRouteQueueWait+route), ts.metrics.Get(metricRouteQueueMaxMs+route); longest < 150 || total < longest {
+		t.Errorf("Expected waits to be recorded, got a total of %dms and a longest of %dms", total, longest)
+	}
+}
+
+func TestServer_Integration_ConcurrencyQueueFull(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:        "/slow",
+			Method:      "GET",
+			Template:    "done",
+			Delay:       &config.DelayConfig{Min: 300 * time.Millisecond, Max: 300 * time.Millisecond},
+			Concurrency: &config.ConcurrencyConfig{Limit: 1, QueueTimeout: 50 * time.Millisecond},
+		},
+	})
+	ts := NewTestServer(t, cfg)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := ts.makeRequest("GET", "/slow", nil, nil)
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+			return
+		}
+		readResponseBody(t, resp)
+	}()
+
+	// Give the first request time to take the only slot
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := ts.makeRequest("GET", "/slow", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+	<-done
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(body, "concurrency limit of 1 and gave up waiting") {
+		t.Errorf("Expected the body to explain the limit, got %q", body)
+	}
+	if got := resp.Header.Get(headerQueueWait); got != "" {
+		t.Errorf("Expected no %s header outside dev mode, got %q", headerQueueWait, got)
+	}
+	if got := ts.metrics.Get(metricRouteRejected + `{route="GET /slow"}`); got != 1 {
+		t.Errorf("Expected 1 rejected request, got %d", got)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Content types of the bodies inferContentType recognizes
+const (
+	contentTypeJSON = "application/json"
+	contentTypeXML  = "application/xml"
+	contentTypeHTML = "text/html; charset=utf-8"
+)
+
+// setDefaultContentType labels a body the route left without a Content-Type,
+// with the server's default content type when it sets one, or with the type
+// inferred from the body. When partial, body is only the start of a streamed
+// response.
+func setDefaultContentType(h http.Header, defaultType string, body []byte, partial bool) {
+	if h.Get("Content-Type") != "" || len(body) == 0 {
+		return
+	}
+
+	contentType := defaultType
+	if contentType == "" {
+		contentType = inferContentType(body, partial)
+	}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+}
+
+// inferContentType guesses the content type of a rendered body: JSON objects
+// and arrays, XML documents and HTML pages. Anything else gets "", leaving
+// the HTTP server to sniff it as usual. When partial, body is only the start
+// of the response, so JSON is told by its first character and XML by its
+// declaration.
+func inferContentType(body []byte, partial bool) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return ""
+	}
+
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		if partial || json.Valid(trimmed) {
+			return contentTypeJSON
+		}
+		return ""
+	}
+
+	sniffed := http.DetectContentType(body)
+	switch {
+	case strings.HasPrefix(sniffed, "text/html"):
+		return contentTypeHTML
+	case strings.HasPrefix(sniffed, "text/xml"):
+		return contentTypeXML
+	case trimmed[0] == '<' && !partial && isXML(trimmed):
+		return contentTypeXML
+	}
+	return ""
+}
+
+// isXML reports whether body is a well-formed XML document
+func isXML(body []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	elements := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return elements > 0
+		}
+		if err != nil {
+			return false
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			elements++
+		}
+	}
+}
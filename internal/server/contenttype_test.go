@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestInferContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		partial  bool
+		expected string
+	}{
+		{name: "object", body: `{"id": 1}`, expected: contentTypeJSON},
+		{name: "array with whitespace", body: "\n  [1, 2]\n", expected: contentTypeJSON},
+		{name: "invalid JSON", body: `{id: 1}`, expected: ""},
+		{name: "partial JSON", body: `[{"id": 1}, {"id"`, partial: true, expected: contentTypeJSON},
+		{name: "JSON scalar", body: `42`, expected: ""},
+		{name: "XML declaration", body: `<?xml version="1.0"?><order/>`, expected: contentTypeXML},
+		{name: "XML element", body: `<order><id>1</id></order>`, expected: contentTypeXML},
+		{name: "partial XML element", body: `<order><id>1</id>`, partial: true, expected: ""},
+		{name: "HTML page", body: `<!DOCTYPE html><html><body>hi</body></html>`, expected: contentTypeHTML},
+		{name: "HTML fragment", body: `<p>hello</p>`, expected: contentTypeHTML},
+		{name: "plain text", body: `hello world`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inferContentType([]byte(tt.body), tt.partial); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_DefaultContentType(t *testing.T) {
+	routes := []config.RouteConfig{
+		{Path: "/json", Method: "GET", Template: `{"id": 1}`},
+		{Path: "/xml", Method: "GET", Template: `<order><id>1</id></order>`},
+		{Path: "/text", Method: "GET", Template: `hello`},
+		{Path: "/labeled", Method: "GET", Template: `{"id": 1}`, ResponseHeaders: map[string]string{"Content-Type": "application/vnd.api+json"}},
+		{Path: "/stream", Method: "GET", Template: `[{{ range $i, $_ := until 3 }}{{ if $i }},{{ end }}{{ $i }}{{ end }}]`, Stream: &config.StreamConfig{BufferSize: 2}},
+	}
+
+	tests := []struct {
+		name        string
+		defaultType string
+		expected    map[string]string
+	}{
+		{
+			name: "inferred",
+			expected: map[string]string{
+				"/json":    "application/json",
+				"/xml":     "application/xml",
+				"/text":    "text/plain; charset=utf-8",
+				"/labeled": "application/vnd.api+json",
+				"/stream":  "application/json",
+			},
+		},
+		{
+			name:        "server default",
+			defaultType: "application/hal+json",
+			expected: map[string]string{
+				"/json":    "application/hal+json",
+				"/xml":     "application/hal+json",
+				"/text":    "application/hal+json",
+				"/labeled": "application/vnd.api+json",
+				"/stream":  "application/hal+json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig(routes)
+			cfg.Server.DefaultContentType = tt.defaultType
+			ts := NewTestServer(t, cfg)
+
+			for path, expected := range tt.expected {
+				resp, err := ts.makeRequest("GET", path, nil, nil)
+				if err != nil {
+					t.Fatalf("Request failed: %v", err)
+				}
+				readResponseBody(t, resp)
+
+				if resp.StatusCode != http.StatusOK {
+					t.Errorf("%s: expected status 200, got %d", path, resp.StatusCode)
+				}
+				if got := resp.Header.Get("Content-Type"); got != expected {
+					t.Errorf("%s: expected Content-Type %q, got %q", path, expected, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+)
+
+// acceptedRequestEncodings lists the content codings request bodies can be
+// sent with, as advertised when a request uses another one
+const acceptedRequestEncodings = "gzip, deflate"
+
+// decompressRequestBody replaces a request body sent with a Content-Encoding
+// with its decompressed bytes, so routes see the same body as if it had been
+// sent uncompressed. The decompressed body is capped at limit, like any other
+// body. Requests with an unknown coding or a corrupt body are answered with
+// an error right away, whose status is returned, or 0 when the request can
+// be served.
+func decompressRequestBody(w http.ResponseWriter, r *http.Request, limit int64) int {
+	header := r.Header.Get("Content-Encoding")
+	if header == "" || r.Body == nil || r.Body == http.NoBody {
+		return 0
+	}
+
+	// Codings are listed in the order they were applied, so undo them backwards
+	codings := strings.Split(header, ",")
+	slices.Reverse(codings)
+
+	body := r.Body
+	for _, coding := range codings {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		var decoded io.Reader
+		var err error
+		switch coding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			decoded, err = gzip.NewReader(body)
+		case "deflate":
+			decoded, err = newDeflateReader(body)
+		default:
+			w.Header().Set("Accept-Encoding", acceptedRequestEncodings)
+			detail := fmt.Sprintf("request bodies with Content-Encoding %q are not supported, use one of: %s", coding, acceptedRequestEncodings)
+			problem.Write(w, r, http.StatusUnsupportedMediaType, problem.CodeUnknownEncoding, detail, "415 Unsupported Media Type: "+detail)
+			return http.StatusUnsupportedMediaType
+		}
+		if err != nil {
+			detail := fmt.Sprintf("failed to decompress the %s request body: %v", coding, err)
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidEncoding, detail, "400 Bad Request: "+detail)
+			return http.StatusBadRequest
+		}
+
+		body = struct {
+			io.Reader
+			io.Closer
+		}{decoded, body}
+	}
+
+	r.Body = http.MaxBytesReader(w, body, limit)
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return 0
+}
+
+// newDeflateReader returns a reader of a deflate body. Since many clients
+// send raw deflate data rather than the zlib format HTTP asks for, bodies
+// without a zlib header are read as raw deflate.
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	// A zlib header uses the deflate method and is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// compress returns data compressed with w, as created by newWriter
+func compress(t *testing.T, data string, newWriter func(io.Writer) io.WriteCloser) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestServer_Integration_CompressedRequestBodies(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/echo", Method: "POST", Template: `{{ .Body.name }} {{ len .RawBody }} {{ .Headers.Get "Content-Encoding" }}`},
+	})
+	cfg.Server.BodyLimit = 64
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	payload := `{"name": "Ada"}`
+	gzipped := compress(t, payload, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	zlibbed := compress(t, payload, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })
+	deflated := compress(t, payload, func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	})
+	twice := compress(t, string(zlibbed), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	bomb := compress(t, strings.Repeat(" ", 1000), func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipped, wantStatus: 200, wantBody: "Ada 15 "},
+		{name: "x-gzip", encoding: "X-Gzip", body: gzipped, wantStatus: 200, wantBody: "Ada 15 "},
+		{name: "zlib deflate", encoding: "deflate", body: zlibbed, wantStatus: 200, wantBody: "Ada 15 "},
+		{name: "raw deflate", encoding: "deflate", body: deflated, wantStatus: 200, wantBody: "Ada 15 "},
+		{name: "several codings", encoding: "deflate, identity, gzip", body: twice, wantStatus: 200, wantBody: "Ada 15 "},
+		{name: "identity", encoding: "identity", body: []byte(payload), wantStatus: 200, wantBody: "Ada 15 "},
+		{name: "unknown coding", encoding: "br", body: []byte("whatever"), wantStatus: 415, wantBody: `Content-Encoding "br" are not supported`},
+		{name: "corrupt body", encoding: "gzip", body: []byte(payload), wantStatus: 400, wantBody: "failed to decompress the gzip request body"},
+		{name: "decompressed over the limit", encoding: "gzip", body: bomb, wantStatus: 413, wantBody: "limit of 64 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Content-Type": "application/json", "Content-Encoding": tt.encoding}
+			resp, err := ts.makeRequest("POST", "/echo", bytes.NewReader(tt.body), headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode, body)
+			}
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("Expected body containing %q, got %q", tt.wantBody, body)
+			}
+			if tt.wantStatus == 415 && resp.Header.Get("Accept-Encoding") != "gzip, deflate" {
+				t.Errorf("Expected the accepted encodings to be advertised, got %q", resp.Header.Get("Accept-Encoding"))
+			}
+		})
+	}
+}
+
+func TestServer_Integration_CompressedRequestJournal(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/upload", Method: "POST", Template: "ok"},
+	})
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	body := compress(t, "hello", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	resp, err := ts.makeRequest("POST", "/upload", bytes.NewReader(body), map[string]string{"Content-Encoding": "gzip"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	entries, _ := ts.journal.find(journalQuery{Limit: 10})
+	if len(entries) != 1 || entries[0].Body != "hello" {
+		t.Errorf("Expected the journal to record the decompressed body, got %+v", entries)
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Overall statuses reported by the health check endpoint
+const (
+	healthHealthy   = "healthy"
+	healthDegraded  = "degraded"
+	healthUnhealthy = "unhealthy"
+)
+
+// DependencyStatus is the state of a synthetic dependency reported by the
+// health check endpoint
+type DependencyStatus struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Critical   bool   `json:"critical"`
+	Overridden bool   `json:"overridden"` // Whether the status was changed through the admin API
+}
+
+// dependencyOverride is a status set through the admin API, replacing the
+// configured one until it's reset
+type dependencyOverride struct {
+	Status  string
+	Message string
+}
+
+// dependencyStore holds the synthetic dependencies of the health check.
+// Overrides made through the admin API survive configuration reloads as long
+// as the dependency is still configured.
+type dependencyStore struct {
+	mu         sync.Mutex
+	configured []config.DependencyConfig     // Configured dependencies, in order, with defaults applied
+	overrides  map[string]dependencyOverride // Statuses set through the admin API by dependency name
+}
+
+// newDependencyStore creates a store for the given health check configuration
+func newDependencyStore(health config.HealthConfig) *dependencyStore {
+	ds := &dependencyStore{overrides: make(map[string]dependencyOverride)}
+	ds.configure(health)
+	return ds
+}
+
+// configure replaces the configured dependencies, dropping overrides of
+// dependencies that no longer exist
+func (ds *dependencyStore) configure(health config.HealthConfig) {
+	configured := make([]config.DependencyConfig, 0, len(health.Dependencies))
+	names := make(map[string]bool, len(health.Dependencies))
+	for _, dep := range health.Dependencies {
+		configured = append(configured, dep.GetWithDefaults())
+		names[dep.Name] = true
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	ds.configured = configured
+	for name := range ds.overrides {
+		if !names[name] {
+			delete(ds.overrides, name)
+		}
+	}
+}
+
+// list returns the current status of every dependency, in configuration order
+func (ds *dependencyStore) list() []DependencyStatus {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	statuses := make([]DependencyStatus, 0, len(ds.configured))
+	for _, dep := range ds.configured {
+		statuses = append(statuses, ds.statusOf(dep))
+	}
+	return statuses
+}
+
+// statusOf returns the current status of a configured dependency. Callers
+// must hold ds.mu.
+func (ds *dependencyStore) statusOf(dep config.DependencyConfig) DependencyStatus {
+	status := DependencyStatus{Name: dep.Name, Status: dep.Status, Message: dep.Message, Critical: dep.Critical}
+	if override, ok := ds.overrides[dep.Name]; ok {
+		status.Status = override.Status
+		status.Message = override.Message
+		status.Overridden = true
+	}
+	return status
+}
+
+// set overrides the status of the named dependency, reporting false when no
+// such dependency is configured
+func (ds *dependencyStore) set(name string, override dependencyOverride) (DependencyStatus, bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	for _, dep := range ds.configured {
+		if dep.Name == name {
+			ds.overrides[name] = override
+			return ds.statusOf(dep), true
+		}
+	}
+	return DependencyStatus{}, false
+}
+
+// reset removes the override of the named dependency, or of all dependencies
+// when name is empty, and returns how many were removed
+func (ds *dependencyStore) reset(name string) int {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if name != "" {
+		if _, ok := ds.overrides[name]; !ok {
+			return 0
+		}
+		delete(ds.overrides, name)
+		return 1
+	}
+
+	count := len(ds.overrides)
+	clear(ds.overrides)
+	return count
+}
+
+// overallStatus summarizes dependency statuses: unhealthy when a critical
+// dependency is down, degraded when any other dependency isn't up, and
+// healthy otherwise
+func overallStatus(deps []DependencyStatus) string {
+	status := healthHealthy
+	for _, dep := range deps {
+		switch {
+		case dep.Status == config.DependencyDown && dep.Critical:
+			return healthUnhealthy
+		case dep.Status != config.DependencyUp:
+			status = healthDegraded
+		}
+	}
+	return status
+}
+
+// handleListDependencies lists the synthetic dependencies of the health check
+func (s *Server) handleListDependencies(w http.ResponseWriter, _ *http.Request) {
+	deps := s.dependencies.list()
+	writeJSON(w, http.StatusOK, map[string]any{"status": overallStatus(deps), "dependencies": deps})
+}
+
+// dependencyRequest is the body accepted when changing a dependency's status
+type dependencyRequest struct {
+	Status  string `yaml:"status"`
+	Message string `yaml:"message"`
+}
+
+// handleSetDependency changes the status of a synthetic dependency, so
+// clients reacting to a degraded health check can be exercised
+func (s *Server) handleSetDependency(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	var req dependencyRequest
+	if err := yaml.Unmarshal(body, &req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse dependency status: %v", err))
+		return
+	}
+
+	if !config.IsDependencyStatus(req.Status) {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q, must be one of: %s, %s, %s", req.Status, config.DependencyUp, config.DependencyDegraded, config.DependencyDown))
+		return
+	}
+
+	name := r.PathValue("name")
+	dep, found := s.dependencies.set(name, dependencyOverride{Status: req.Status, Message: req.Message})
+	if !found {
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("health check dependency %q not found", name))
+		return
+	}
+
+	s.logger.Info("health check dependency changed", "name", name, "status", req.Status)
+	writeJSON(w, http.StatusOK, dep)
+}
+
+// handleResetDependencies restores the configured statuses of synthetic
+// dependencies. The optional "name" query parameter limits the reset to one
+// dependency.
+func (s *Server) handleResetDependencies(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	count := s.dependencies.reset(name)
+
+	s.logger.Info("health check dependencies reset", "name", name, "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"reset": count})
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestOverallStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     []DependencyStatus
+		expected string
+	}{
+		{name: "no dependencies", expected: healthHealthy},
+		{name: "all up", deps: []DependencyStatus{{Status: "up"}, {Status: "up", Critical: true}}, expected: healthHealthy},
+		{name: "degraded", deps: []DependencyStatus{{Status: "up"}, {Status: "degraded", Critical: true}}, expected: healthDegraded},
+		{name: "non-critical down", deps: []DependencyStatus{{Status: "down"}}, expected: healthDegraded},
+		{name: "critical down", deps: []DependencyStatus{{Status: "degraded"}, {Status: "down", Critical: true}}, expected: healthUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overallStatus(tt.deps); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDependencyStore_Configure(t *testing.T) {
+	ds := newDependencyStore(config.HealthConfig{Dependencies: []config.DependencyConfig{
+		{Name: "database", Critical: true},
+		{Name: "cache"},
+	}})
+
+	ds.set("database", dependencyOverride{Status: "down"})
+	ds.set("cache", dependencyOverride{Status: "degraded"})
+
+	// Overrides of dependencies still configured survive a reload
+	ds.configure(config.HealthConfig{Dependencies: []config.DependencyConfig{{Name: "database", Critical: true}}})
+
+	deps := ds.list()
+	if len(deps) != 1 || deps[0].Status != "down" || !deps[0].Overridden {
+		t.Errorf("Expected the database override to survive the reload, got %+v", deps)
+	}
+	if _, found := ds.overrides["cache"]; found {
+		t.Error("Expected the override of a removed dependency to be dropped")
+	}
+}
+
+func TestServer_Integration_HealthDependencies(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/ping", Method: "GET", Template: "pong"},
+	})
+	cfg.Health = config.HealthConfig{Dependencies: []config.DependencyConfig{
+		{Name: "database", Critical: true},
+		{Name: "cache", Status: "degraded", Message: "high latency"},
+	}}
+
+	ts := NewTestServer(t, cfg)
+
+	health := func() (int, HealthCheckResponse) {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/health", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var body HealthCheckResponse
+		if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &body); err != nil {
+			t.Fatalf("Failed to decode health check: %v", err)
+		}
+		return resp.StatusCode, body
+	}
+	admin := func(method, path, body string) (int, string) {
+		t.Helper()
+		resp, err := ts.makeRequest(method, path, strings.NewReader(body), nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp.StatusCode, readResponseBody(t, resp)
+	}
+
+	code, body := health()
+	if code != http.StatusOK || body.Status != "degraded" || len(body.Dependencies) != 2 {
+		t.Fatalf("Expected a degraded health check with 2 dependencies, got %d %+v", code, body)
+	}
+	if body.Dependencies[0].Status != "up" || body.Dependencies[1].Message != "high latency" {
+		t.Errorf("Expected configured dependency statuses, got %+v", body.Dependencies)
+	}
+
+	// Taking a critical dependency down makes the server unhealthy
+	status, resp := admin("PUT", "/__admin/health/dependencies/database", `{"status": "down", "message": "connection refused"}`)
+	if status != http.StatusOK || !strings.Contains(resp, `"overridden": true`) {
+		t.Fatalf("Expected the dependency to be updated, got %d %s", status, resp)
+	}
+
+	code, body = health()
+	if code != http.StatusServiceUnavailable || body.Status != "unhealthy" {
+		t.Errorf("Expected an unhealthy 503 health check, got %d %q", code, body.Status)
+	}
+	if body.Dependencies[0].Message != "connection refused" {
+		t.Errorf("Expected the overridden message, got %+v", body.Dependencies[0])
+	}
+
+	errorCases := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "unknown dependency", path: "/__admin/health/dependencies/queue", body: `{"status": "down"}`, status: http.StatusNotFound},
+		{name: "invalid status", path: "/__admin/health/dependencies/cache", body: `{"status": "sideways"}`, status: http.StatusBadRequest},
+		{name: "missing status", path: "/__admin/health/dependencies/cache", body: `{}`, status: http.StatusBadRequest},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, resp := admin("PUT", tt.path, tt.body); status != tt.status {
+				t.Errorf("Expected status %d, got %d %s", tt.status, status, resp)
+			}
+		})
+	}
+
+	status, resp = admin("GET", "/__admin/health/dependencies", "")
+	if status != http.StatusOK || !strings.Contains(resp, `"status": "unhealthy"`) {
+		t.Errorf("Expected the dependency list with the overall status, got %d %s", status, resp)
+	}
+
+	// Resetting restores the configured statuses
+	status, resp = admin("DELETE", "/__admin/health/dependencies", "")
+	if status != http.StatusOK || !strings.Contains(resp, `"reset": 1`) {
+		t.Errorf("Expected one override to be reset, got %d %s", status, resp)
+	}
+
+	code, body = health()
+	if code != http.StatusOK || body.Status != "degraded" || body.Dependencies[0].Overridden {
+		t.Errorf("Expected the configured statuses after a reset, got %d %+v", code, body)
+	}
+}
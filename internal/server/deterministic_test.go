@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_DeterministicRoute(t *testing.T) {
+	template := `{"name": "{{ fakeName }}", "id": "{{ uuidv4 }}", "score": {{ randInt 0 1000000 }}, "at": "{{ now | date "2006-01-02T15:04:05Z07:00" }}", "request": "{{ .RequestID }}"}`
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/users",
+			Method:          "POST",
+			Template:        template,
+			ResponseHeaders: map[string]string{"X-Trace": "{{ randAlphaNum 16 }}", "X-Session": "{{ fakeUUID }}"},
+			Deterministic:   &config.DeterministicConfig{Time: "2025-06-01T12:00:00Z"},
+		},
+		{Path: "/random", Method: "POST", Template: template},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	call := func(path, body string) (*http.Response, string) {
+		t.Helper()
+		resp, err := ts.makeRequest("POST", path, strings.NewReader(body), map[string]string{"Content-Type": "application/json"})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp, readResponseBody(t, resp)
+	}
+
+	firstResp, first := call("/users", `{"page": 1}`)
+	secondResp, second := call("/users", `{"page": 1}`)
+	if first != second {
+		t.Errorf("Expected the same request to get the same response, got:\n%s\n%s", first, second)
+	}
+	for _, header := range []string{"X-Trace", "X-Session", "Date"} {
+		if firstResp.Header.Get(header) != secondResp.Header.Get(header) {
+			t.Errorf("Expected header %s to repeat, got %q and %q", header, firstResp.Header.Get(header), secondResp.Header.Get(header))
+		}
+	}
+	if !strings.Contains(first, `"at": "2025-06-01T12:00:00Z"`) {
+		t.Errorf("Expected now to be frozen at the route's time, got %s", first)
+	}
+	if got := firstResp.Header.Get("Date"); got != "Sun, 01 Jun 2025 12:00:00 GMT" {
+		t.Errorf("Expected the Date header at the route's time, got %q", got)
+	}
+
+	if _, other := call("/users", `{"page": 2}`); other == first {
+		t.Errorf("Expected a different request to get a different response, got %s", other)
+	}
+
+	// Other routes stay random
+	_, random1 := call("/random", `{"page": 1}`)
+	_, random2 := call("/random", `{"page": 1}`)
+	if random1 == random2 {
+		t.Errorf("Expected routes that aren't deterministic to vary, got %s twice", random1)
+	}
+}
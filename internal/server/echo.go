@@ -0,0 +1,54 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// renderEcho writes the response body of a route echoing request bodies.
+// Bodies are echoed byte for byte, unless the route transforms them and
+// they're JSON, in which case the transformed body is written as JSON.
+func (s *Server) renderEcho(rt *routing, w io.Writer, echo *router.EchoBody, ctx *templatepkg.TemplateContext) error {
+	if !echo.Transforms {
+		_, err := io.WriteString(w, ctx.RawBody)
+		return err
+	}
+
+	// Decode the body again, keeping numbers as written
+	decoder := json.NewDecoder(strings.NewReader(ctx.RawBody))
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil || decoder.More() {
+		_, err := io.WriteString(w, ctx.RawBody)
+		return err
+	}
+
+	values := make(map[string]any, len(echo.Set))
+	for _, field := range echo.Set {
+		var buf bytes.Buffer
+		if err := rt.engine.ExecuteTemplate(field.Tmpl, &buf, ctx); err != nil {
+			return fmt.Errorf("failed to render field %q: %w", strings.Join(field.Path, "."), err)
+		}
+		values[strings.Join(field.Path, ".")] = echoValue(buf.Bytes())
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(echo.Transform(body, values))
+}
+
+// echoValue returns the value of a field set on echoed bodies: template
+// output that is valid JSON, like numbers, booleans or toJson's output, is
+// inserted as such, and anything else as a string
+func echoValue(output []byte) any {
+	if trimmed := bytes.TrimSpace(output); json.Valid(trimmed) {
+		return json.RawMessage(trimmed)
+	}
+	return string(output)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_EchoBody(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/echo", Method: "POST", EchoBody: &config.EchoBodyConfig{}},
+		{
+			Path:            "/users",
+			Method:          "POST",
+			ResponseHeaders: map[string]string{"Location": "/users/{{ .RequestID }}"},
+			EchoBody: &config.EchoBodyConfig{
+				Status: 201,
+				Select: "user",
+				Remove: []string{"password"},
+				Set: map[string]string{
+					"id":          "{{ .RequestID | toJson }}",
+					"active":      "true",
+					"meta.source": "{{ .Headers.Get \"X-Source\" }}",
+				},
+			},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name            string
+		path            string
+		body            string
+		contentType     string
+		wantStatus      int
+		wantBody        string
+		wantContentType string
+	}{
+		{
+			name:            "as-is",
+			path:            "/echo",
+			body:            "{ \"b\": 1,\n  \"a\": 2 }",
+			contentType:     "application/json",
+			wantStatus:      200,
+			wantBody:        "{ \"b\": 1,\n  \"a\": 2 }",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "as-is without JSON",
+			path:            "/echo",
+			body:            "plain <text>",
+			contentType:     "text/plain",
+			wantStatus:      200,
+			wantBody:        "plain <text>",
+			wantContentType: "text/plain",
+		},
+		{
+			name:            "transformed",
+			path:            "/users",
+			body:            `{"user": {"name": "Ada <3", "password": "hunter2", "id": 12345678901234567890}}`,
+			contentType:     "application/json",
+			wantStatus:      201,
+			wantBody:        `{"active":true,"id":"req-1","meta":{"source":"signup"},"name":"Ada <3"}` + "\n",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "transformed array",
+			path:            "/users",
+			body:            `{"user": [{"name": "Ada", "age": 12345678901234567890}]}`,
+			contentType:     "application/json",
+			wantStatus:      201,
+			wantBody:        `[{"active":true,"age":12345678901234567890,"id":"req-1","meta":{"source":"signup"},"name":"Ada"}]` + "\n",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "not JSON",
+			path:            "/users",
+			body:            "name=Ada",
+			contentType:     "application/x-www-form-urlencoded",
+			wantStatus:      201,
+			wantBody:        "name=Ada",
+			wantContentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:            "missing selection",
+			path:            "/users",
+			body:            `{"name": "Ada"}`,
+			contentType:     "application/json",
+			wantStatus:      201,
+			wantBody:        "null\n",
+			wantContentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Content-Type": tt.contentType, "X-Request-ID": "req-1", "X-Source": "signup"}
+			resp, err := ts.makeRequest("POST", tt.path, strings.NewReader(tt.body), headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if body != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, body)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.wantContentType, got)
+			}
+			if tt.path == "/users" && resp.Header.Get("Location") != "/users/req-1" {
+				t.Errorf("Expected the route's headers, got Location %q", resp.Header.Get("Location"))
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// writeErrorPage answers with page in place of the built-in error info
+// describes, returning the status it answered with. It returns zero without
+// writing anything when page is nil or fails to render, for the built-in error
+// to be sent instead.
+func (s *Server) writeErrorPage(w http.ResponseWriter, r *http.Request, page *router.ErrorPage, info templatepkg.ErrorInfo) int {
+	if page == nil {
+		return 0
+	}
+
+	rt := s.routingFor(r)
+	ctx := templatepkg.NewErrorContext(r, info)
+	ctx.Tokens = s.tokens.forSession(sessionID(s.requestSession(r)))
+	ctx.Logger = s.logger
+	ctx.Clock = rt.clockFor(nil)
+
+	body, headers, err := renderErrorPage(rt, page, ctx)
+	if err != nil {
+		s.logger.Error("error page execution error",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"code", info.Code,
+			"error", err,
+		)
+		return 0
+	}
+
+	// The page describes its own body, not the one of the response it
+	// replaces
+	w.Header().Del("Content-Type")
+	for name, value := range headers {
+		if value == "" {
+			w.Header().Del(name)
+			continue
+		}
+		w.Header().Set(name, value)
+	}
+	setCookies(w, ctx.Response)
+	if contentType := ctx.Response.ContentType(); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	setDefaultContentType(w.Header(), rt.contentType, body, false)
+
+	status := ctx.Response.StatusOr(cmp.Or(page.Status, info.Status))
+	w.WriteHeader(status)
+	_, _ = w.Write(body) // Headers are already sent, nothing else to do on failure
+	return status
+}
+
+// renderErrorPage renders the body and headers of an error page
+func renderErrorPage(rt *routing, page *router.ErrorPage, ctx *templatepkg.TemplateContext) ([]byte, map[string]string, error) {
+	var body bytes.Buffer
+	if err := rt.engine.ExecuteTemplate(page.Tmpl, &body, ctx); err != nil {
+		return nil, nil, err
+	}
+
+	headers := make(map[string]string, len(page.Headers))
+	for name, tmpl := range page.Headers {
+		var buf bytes.Buffer
+		if err := rt.engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to execute template for header %q: %w", name, err)
+		}
+		headers[name] = strings.TrimSpace(buf.String())
+	}
+	return body.Bytes(), headers, nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_ErrorPages(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/broken",
+			Method:          "GET",
+			Template:        `{{ fail "boom" }}`,
+			ResponseHeaders: map[string]string{"Content-Type": "text/html"},
+		},
+		{
+			Path:     "/slow",
+			Method:   "GET",
+			Template: "done",
+			Delay:    &config.DelayConfig{Min: 500 * time.Millisecond, Max: 500 * time.Millisecond},
+		},
+	})
+	cfg.Errors.Format = "json"
+	cfg.Server.ErrorPages = &config.ErrorPagesConfig{
+		TemplateError: &config.ErrorPageConfig{
+			Template: `{"error":{"code":"{{ .Error.Code }}","path":"{{ .Request.URL.Path }}"}}`,
+			Headers:  map[string]string{"Content-Type": "application/json", "X-Error-Status": "{{ .Error.Status }}"},
+		},
+		Timeout: &config.ErrorPageConfig{
+			Status:   http.StatusGatewayTimeout,
+			Template: `{"error":{"code":"timeout"}}`,
+		},
+	}
+
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", slog.New(slog.DiscardHandler), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// A request whose context is already done times out while being delayed
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		path                string
+		ctx                 context.Context
+		expectedStatus      int
+		expectedBody        string
+		expectedContentType string
+	}{
+		{path: "/broken", ctx: context.Background(), expectedStatus: http.StatusInternalServerError, expectedBody: `{"error":{"code":"template_error","path":"/broken"}}`, expectedContentType: "application/json"},
+		{path: "/slow", ctx: expired, expectedStatus: http.StatusGatewayTimeout, expectedBody: `{"error":{"code":"timeout"}}`, expectedContentType: "application/json"},
+		{path: "/missing", ctx: context.Background(), expectedStatus: http.StatusNotFound, expectedContentType: "application/problem+json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(tt.ctx, "GET", tt.path, nil)
+			resp := httptest.NewRecorder()
+			srv.handler().ServeHTTP(resp, req)
+
+			if resp.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.Code)
+			}
+			if tt.expectedBody != "" && resp.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %s, got %s", tt.expectedBody, resp.Body.String())
+			}
+			if ct := resp.Header().Get("Content-Type"); ct != tt.expectedContentType {
+				t.Errorf("Expected content type %q, got %q", tt.expectedContentType, ct)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/broken", nil)
+	resp := httptest.NewRecorder()
+	srv.handler().ServeHTTP(resp, req)
+	if got := resp.Header().Get("X-Error-Status"); got != "500" {
+		t.Errorf("Expected the page's header to render the error status, got %q", got)
+	}
+}
+
+func TestServer_Integration_BrokenErrorPage(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/broken", Method: "GET", Template: `{{ fail "boom" }}`},
+	})
+	cfg.Server.ErrorPages = &config.ErrorPagesConfig{
+		TemplateError: &config.ErrorPageConfig{Template: `{{ fail "page" }}`},
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	// A page failing to render gives way to the built-in error
+	resp, err := ts.makeRequest("GET", "/broken", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusInternalServerError || body != "500 Internal Server Error: response template cannot be rendered due to an error in the template\n" {
+		t.Errorf("Expected the built-in template error, got %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// ExpectationResult is the outcome of checking a route's call expectations
+type ExpectationResult struct {
+	Route    string   `json:"route"`              // The route, as "METHOD path"
+	Expected string   `json:"expected"`           // The expected number of calls, e.g. "exactly 2"
+	Calls    int      `json:"calls"`              // How many times the route was called
+	Met      bool     `json:"met"`                // Whether every expectation of the route was met
+	Problems []string `json:"problems,omitempty"` // Why the expectations weren't met
+}
+
+// callStats is how often a route was called, and when it was first and last
+// called
+type callStats struct {
+	Calls int
+	First int64     // Position of the first call among all calls
+	Last  time.Time // Time of the last call
+}
+
+// callKey identifies the calls of a route made under a session
+type callKey struct {
+	Route   string // Route ID, as "METHOD path"
+	Session string // Admin session the calls were made under (empty outside sessions)
+}
+
+// callStore counts the calls of every route, keyed by "METHOD path" and
+// session. Counts survive configuration reloads, so expectations can be
+// checked after the routes changed.
+type callStore struct {
+	mu    sync.Mutex
+	seq   int64
+	since time.Time // When calls started being counted
+	stats map[callKey]*callStats
+}
+
+// newCallStore creates an empty call store
+func newCallStore() *callStore {
+	return &callStore{since: time.Now(), stats: make(map[callKey]*callStats)}
+}
+
+// record counts a call of the route with the given ID made under session, or
+// outside sessions when it's empty
+func (cs *callStore) record(id, session string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.seq++
+	key := callKey{Route: id, Session: session}
+	stats, ok := cs.stats[key]
+	if !ok {
+		stats = &callStats{First: cs.seq}
+		cs.stats[key] = stats
+	}
+	stats.Calls++
+	stats.Last = time.Now()
+}
+
+// get returns the calls of the route with the given ID made under session, or
+// outside sessions when it's empty
+func (cs *callStore) get(id, session string) callStats {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if stats, ok := cs.stats[callKey{Route: id, Session: session}]; ok {
+		return *stats
+	}
+	return callStats{}
+}
+
+// total returns the calls of the route with the given ID made both inside and
+// outside sessions
+func (cs *callStore) total(id string) callStats {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var total callStats
+	for key, stats := range cs.stats {
+		if key.Route != id {
+			continue
+		}
+		if total.Calls == 0 || stats.First < total.First {
+			total.First = stats.First
+		}
+		if stats.Last.After(total.Last) {
+			total.Last = stats.Last
+		}
+		total.Calls += stats.Calls
+	}
+	return total
+}
+
+// reset forgets the calls made under session, or outside sessions when it's
+// empty, and returns how many routes had been called
+func (cs *callStore) reset(session string) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	count := 0
+	for key := range cs.stats {
+		if key.Session == session {
+			delete(cs.stats, key)
+			count++
+		}
+	}
+	if session == "" {
+		cs.since = time.Now()
+	}
+	return count
+}
+
+// resetSession forgets the calls made under the given session and returns how
+// many routes had been called
+func (cs *callStore) resetSession(session string) int {
+	return cs.reset(session)
+}
+
+// checkExpectations checks the expectations of the given routes against the
+// calls made under session so far, or outside sessions when it's empty.
+// Routes sharing a method and path are checked once.
+func (cs *callStore) checkExpectations(routes []*router.Route, session string) []ExpectationResult {
+	results := make([]ExpectationResult, 0)
+	checked := make(map[string]bool)
+
+	for _, route := range routes {
+		id := scenarioRouteID(route)
+		if route.Expect == nil || checked[id] {
+			continue
+		}
+		checked[id] = true
+
+		stats := cs.get(id, session)
+		result := ExpectationResult{Route: id, Expected: route.Expect.String(), Calls: stats.Calls}
+
+		if !route.Expect.Allows(stats.Calls) {
+			result.Problems = append(result.Problems, fmt.Sprintf("called %d times, expected %s", stats.Calls, route.Expect))
+		}
+
+		// Ordering only matters once the route was called
+		if stats.Calls > 0 {
+			for _, before := range route.Expect.After {
+				if prior := cs.get(before, session); prior.Calls == 0 || prior.First > stats.First {
+					result.Problems = append(result.Problems, fmt.Sprintf("expected to be called after %q", before))
+				}
+			}
+		}
+
+		result.Met = len(result.Problems) == 0
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// VerifyExpectations checks the call expectations of every route against the
+// calls made outside sessions, returning the result of each route declaring
+// any
+func (s *Server) VerifyExpectations() []ExpectationResult {
+	return s.verifyExpectations("")
+}
+
+// verifyExpectations checks the call expectations of every route against the
+// calls made under session, or outside sessions when it's empty. The runtime
+// routes of the session take precedence, like they do when serving requests.
+func (s *Server) verifyExpectations(session string) []ExpectationResult {
+	rt := s.current()
+	routes := make([]*router.Route, 0, len(rt.routes))
+	if session != "" {
+		for _, rr := range s.runtimeRoutes.listSession(session) {
+			routes = append(routes, rr.Route)
+		}
+	}
+	for _, rr := range s.runtimeRoutes.listSession("") {
+		routes = append(routes, rr.Route)
+	}
+	routes = append(routes, rt.routes...)
+
+	return s.calls.checkExpectations(routes, session)
+}
+
+// handleVerify reports whether the call expectations of routes were met by
+// the calls made under the session the request is made under, or outside
+// sessions, answering with 417 Expectation Failed when any wasn't
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	results := s.verifyExpectations(sessionID(sess))
+
+	status, met := http.StatusOK, true
+	for _, result := range results {
+		if !result.Met {
+			status, met = http.StatusExpectationFailed, false
+			break
+		}
+	}
+
+	writeJSON(w, status, map[string]any{"met": met, "expectations": results})
+}
+
+// handleResetCalls forgets the calls counted so far under the session the
+// request is made under, or outside sessions, to check expectations again from
+// a clean slate
+func (s *Server) handleResetCalls(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	count := s.calls.reset(sessionID(sess))
+
+	s.logger.Info("route calls reset", "session", sessionID(sess), "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"reset": count})
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestServer_Integration_VerifyExpectations(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/login", Method: "POST", Template: "token", Expect: &config.ExpectConfig{Calls: intPtr(1)}},
+		{Path: "/profile", Method: "GET", Template: "{}", Expect: &config.ExpectConfig{MinCalls: intPtr(1), After: []string{"POST /login"}}},
+		{Path: "/admin", Method: "DELETE", Template: "no", Expect: &config.ExpectConfig{Calls: intPtr(0)}},
+		{Path: "/health-check", Method: "GET", Template: "ok"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	call := func(method, path string) {
+		t.Helper()
+		resp, err := ts.makeRequest(method, path, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+	}
+	verify := func() (int, map[string]ExpectationResult) {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/__admin/verify", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		var body struct {
+			Met          bool                `json:"met"`
+			Expectations []ExpectationResult `json:"expectations"`
+		}
+		if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &body); err != nil {
+			t.Fatalf("Failed to parse verification: %v", err)
+		}
+		if body.Met != (resp.StatusCode == http.StatusOK) {
+			t.Errorf("Expected met to agree with status %d", resp.StatusCode)
+		}
+
+		results := make(map[string]ExpectationResult)
+		for _, result := range body.Expectations {
+			results[result.Route] = result
+		}
+		return resp.StatusCode, results
+	}
+
+	// Nothing was called yet
+	status, results := verify()
+	if status != http.StatusExpectationFailed || len(results) != 3 {
+		t.Fatalf("Expected 3 expectations to be checked and fail, got %d %+v", status, results)
+	}
+	if results["DELETE /admin"].Met != true || results["POST /login"].Met {
+		t.Errorf("Unexpected results before any call: %+v", results)
+	}
+
+	// Calling out of order breaks the ordering expectation
+	call("GET", "/profile")
+	call("POST", "/login")
+	call("GET", "/health-check")
+
+	status, results = verify()
+	profile := results["GET /profile"]
+	if status != http.StatusExpectationFailed || profile.Met || profile.Calls != 1 || len(profile.Problems) != 1 {
+		t.Errorf("Expected the profile ordering expectation to fail, got %d %+v", status, profile)
+	}
+	if !results["POST /login"].Met {
+		t.Errorf("Expected the login call count to be met, got %+v", results["POST /login"])
+	}
+
+	// Starting over and calling in order meets every expectation
+	resp, err := ts.makeRequest("DELETE", "/__admin/verify", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "{\n  \"reset\": 3\n}\n" {
+		t.Errorf("Expected the calls of 3 routes to be reset, got %q", body)
+	}
+
+	call("POST", "/login")
+	call("GET", "/profile")
+	call("GET", "/profile")
+
+	if status, results = verify(); status != http.StatusOK {
+		t.Errorf("Expected every expectation to be met, got %d %+v", status, results)
+	}
+
+	// Extra calls break exact counts
+	call("POST", "/login")
+	if results := ts.Server.VerifyExpectations(); results[0].Met || results[0].Problems[0] != "called 2 times, expected exactly 1" {
+		t.Errorf("Expected the login expectation to fail, got %+v", results[0])
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_Fallback(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: "mocked"},
+	})
+	cfg.Fallback = &config.FallbackConfig{
+		Template: `{"error":{"code":"not_found","message":"no route for {{ .Request.Method }} {{ .Request.URL.Path }}"}}`,
+		Headers:  map[string]string{"Content-Type": "application/json", "X-Error-Path": "{{ .Request.URL.Path }}"},
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "mocked route", method: "GET", path: "/users", expectedStatus: http.StatusOK, expectedBody: "mocked"},
+		{name: "unmatched path", method: "GET", path: "/orders", expectedStatus: http.StatusNotFound, expectedBody: `{"error":{"code":"not_found","message":"no route for GET /orders"}}`},
+		{name: "unmatched method", method: "DELETE", path: "/users", expectedStatus: http.StatusNotFound, expectedBody: `{"error":{"code":"not_found","message":"no route for DELETE /users"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+			if tt.expectedStatus == http.StatusNotFound {
+				if got := resp.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("Expected Content-Type application/json, got %q", got)
+				}
+				if got := resp.Header.Get("X-Error-Path"); got != tt.path {
+					t.Errorf("Expected X-Error-Path %q, got %q", tt.path, got)
+				}
+			}
+		})
+	}
+
+	// Requests answered by the fallback are still journaled as matching no route
+	entries, _ := ts.journal.find(journalQuery{Limit: 10, Oldest: true})
+	if len(entries) != 3 || entries[0].Route == "" || entries[1].Route != "" || entries[2].Route != "" {
+		t.Errorf("Expected only the mocked route to be journaled as matched, got %+v", entries)
+	}
+}
+
+func TestServer_Integration_FallbackStatus(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: "mocked"},
+	})
+	cfg.Fallback = &config.FallbackConfig{Status: http.StatusGone, Template: "gone"}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	resp, err := ts.makeRequest("GET", "/orders", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); resp.StatusCode != http.StatusGone || body != "gone" {
+		t.Errorf("Expected 410 gone, got %d %q", resp.StatusCode, body)
+	}
+
+	// Templates can still pick their own status
+	cfg.Fallback = &config.FallbackConfig{Template: `{{ .Response.SetStatus 501 }}unsupported`}
+	if err := ts.Server.applyConfig(cfg); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+
+	resp, err = ts.makeRequest("GET", "/orders", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); resp.StatusCode != http.StatusNotImplemented || body != "unsupported" {
+		t.Errorf("Expected 501 unsupported, got %d %q", resp.StatusCode, body)
+	}
+
+	// Without a fallback, unmatched requests are answered with the built-in 404
+	cfg.Fallback = nil
+	if err := ts.Server.applyConfig(cfg); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+
+	resp, err = ts.makeRequest("GET", "/orders", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
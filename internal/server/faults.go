@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// writeFault sends a rendered response with a fault injected into it and
+// returns the status code reported in logs, or 0 when no response was sent
+func (s *Server) writeFault(w http.ResponseWriter, r *http.Request, fault *router.Fault, status int, body []byte) int {
+	switch fault.Type {
+	case config.FaultError:
+		problem.Write(w, r, fault.Status, problem.CodeInjectedFault,
+			"the response was replaced by a fault injected by the mock configuration",
+			fmt.Sprintf("%d %s: fault injected by the mock configuration\n", fault.Status, http.StatusText(fault.Status)))
+		return fault.Status
+
+	case config.FaultReset:
+		abortConnection(w, true)
+		return 0
+
+	case config.FaultTruncate:
+		// Announce the full length so clients notice the body is cut short
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		_, _ = w.Write(body[:fault.TruncatedLength(len(body))])
+		_ = http.NewResponseController(w).Flush()
+		abortConnection(w, false)
+		return status
+
+	case config.FaultSlowBody:
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+
+		rc := http.NewResponseController(w)
+		for len(body) > 0 {
+			n := min(fault.ChunkSize, len(body))
+			if _, err := w.Write(body[:n]); err != nil {
+				break
+			}
+			_ = rc.Flush()
+
+			body = body[n:]
+			if len(body) > 0 {
+				if err := sleepContext(r.Context(), fault.Interval); err != nil {
+					break
+				}
+			}
+		}
+		return status
+	}
+
+	return status
+}
+
+// abortConnection closes the client connection without completing the
+// response. With reset set, pending data is discarded and the peer receives a
+// TCP reset instead of an orderly close.
+func abortConnection(w http.ResponseWriter, reset bool) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// Connections that can't be hijacked, such as HTTP/2 streams, are
+		// aborted by net/http itself
+		panic(http.ErrAbortHandler)
+	}
+
+	if tcp, ok := conn.(*net.TCPConn); ok && reset {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
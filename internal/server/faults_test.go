@@ -0,0 +1,83 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_Faults(t *testing.T) {
+	body := strings.Repeat("x", 100)
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/error", Method: "GET", Template: body, Faults: []config.FaultConfig{{Type: config.FaultError, Status: 503}}},
+		{Path: "/reset", Method: "GET", Template: body, Faults: []config.FaultConfig{{Type: config.FaultReset}}},
+		{Path: "/truncate", Method: "GET", Template: body, Faults: []config.FaultConfig{{Type: config.FaultTruncate, Bytes: 10}}},
+		{Path: "/slow", Method: "GET", Template: "12345", Faults: []config.FaultConfig{{Type: config.FaultSlowBody, ChunkSize: 2, Interval: 20 * time.Millisecond}}},
+	})
+	cfg.Server.DevMode = true
+
+	ts := NewTestServer(t, cfg)
+
+	t.Run("error", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/error", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		got := readResponseBody(t, resp)
+
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", resp.StatusCode)
+		}
+		if !strings.Contains(got, "fault injected") {
+			t.Errorf("Expected an injected fault body, got %q", got)
+		}
+		if fault := resp.Header.Get(headerInjectedFault); fault != "error" {
+			t.Errorf("Expected %s to be %q, got %q", headerInjectedFault, "error", fault)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/reset", nil, nil)
+		if err == nil {
+			resp.Body.Close()
+			t.Fatal("Expected the connection to be reset")
+		}
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/truncate", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		got, err := io.ReadAll(resp.Body)
+		if err == nil {
+			t.Error("Expected reading a truncated body to fail")
+		}
+		if string(got) != body[:10] {
+			t.Errorf("Expected the first 10 bytes of the body, got %q", got)
+		}
+	})
+
+	t.Run("slow body", func(t *testing.T) {
+		start := time.Now()
+		resp, err := ts.makeRequest("GET", "/slow", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		got := readResponseBody(t, resp)
+
+		if got != "12345" {
+			t.Errorf("Expected the full body, got %q", got)
+		}
+		if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+			t.Errorf("Expected three chunks 20ms apart to take at least 40ms, took %s", elapsed)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	grpcpkg "github.com/patrickdappollonio/mockingjay/internal/grpc"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// grpcStatusError is a gRPC call failing with a status other than OK
+type grpcStatusError struct {
+	Code    grpcpkg.Code
+	Message string
+}
+
+func (e *grpcStatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// isGRPCRequest reports whether a request is a gRPC call, which are sent
+// with an application/grpc content type
+func isGRPCRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/grpc" || mediaType == "application/grpc+proto"
+}
+
+// serveGRPC serves a gRPC call with the mocked method it's sent to. The
+// status of the call is sent in the trailers, after the response messages.
+func (s *Server) serveGRPC(rt *routing, w http.ResponseWriter, r *http.Request, start time.Time) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+
+	err := s.callGRPC(rt, w, r)
+
+	code, message := grpcpkg.OK, ""
+	var statusErr *grpcStatusError
+	if errors.As(err, &statusErr) {
+		code, message = statusErr.Code, statusErr.Message
+	}
+
+	w.Header().Set("Grpc-Status", fmt.Sprintf("%d", code))
+	if message != "" {
+		w.Header().Set("Grpc-Message", grpcpkg.EncodeStatusMessage(message))
+	}
+
+	s.logger.Info("grpc call processed",
+		"method", strings.TrimPrefix(r.URL.Path, "/"),
+		"status", code.String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+		"remote_addr", r.RemoteAddr,
+	)
+}
+
+// callGRPC answers a gRPC call with the messages rendered by the template of
+// its mocked method, returning the status of the call as an error when it
+// isn't OK
+func (s *Server) callGRPC(rt *routing, w http.ResponseWriter, r *http.Request) error {
+	method, found := rt.grpcMethods[r.URL.Path]
+	if !found {
+		return &grpcStatusError{grpcpkg.Unimplemented, fmt.Sprintf("method %q is not mocked", strings.TrimPrefix(r.URL.Path, "/"))}
+	}
+
+	requests, err := grpcpkg.ReadMessages(r.Body)
+	if errors.Is(err, grpcpkg.ErrCompressed) {
+		return &grpcStatusError{grpcpkg.Unimplemented, err.Error()}
+	}
+	if err != nil {
+		return &grpcStatusError{grpcpkg.InvalidArgument, err.Error()}
+	}
+
+	// Requests of unary and server streaming methods carry a single message,
+	// client streaming methods get every message as an array
+	var body any
+	if method.Desc.IsStreamingClient() {
+		messages := make([]any, 0, len(requests))
+		for _, data := range requests {
+			message, err := grpcpkg.DecodeMessage(method.Desc.Input(), data)
+			if err != nil {
+				return &grpcStatusError{grpcpkg.InvalidArgument, err.Error()}
+			}
+			messages = append(messages, message)
+		}
+		body = messages
+	} else {
+		if len(requests) != 1 {
+			return &grpcStatusError{grpcpkg.InvalidArgument, fmt.Sprintf("expected exactly one request message, got %d", len(requests))}
+		}
+		if body, err = grpcpkg.DecodeMessage(method.Desc.Input(), requests[0]); err != nil {
+			return &grpcStatusError{grpcpkg.InvalidArgument, err.Error()}
+		}
+	}
+
+	if method.Code != grpcpkg.OK {
+		return &grpcStatusError{method.Code, method.Message}
+	}
+
+	responses, err := s.renderGRPC(rt, r, method, body)
+	if err != nil {
+		s.logger.Error("failed to render grpc response", "method", method.Name, "error", err)
+		return &grpcStatusError{grpcpkg.Internal, err.Error()}
+	}
+
+	controller := http.NewResponseController(w)
+	for _, response := range responses {
+		if err := grpcpkg.WriteMessage(w, response); err != nil {
+			return &grpcStatusError{grpcpkg.Unavailable, err.Error()}
+		}
+		_ = controller.Flush()
+	}
+
+	return nil
+}
+
+// renderGRPC renders the response messages of a gRPC call, which are a JSON
+// array of messages for server streaming methods
+func (s *Server) renderGRPC(rt *routing, r *http.Request, method *router.GRPCMethod, body any) ([][]byte, error) {
+	// The body was already read as protobuf messages, so build the context
+	// without it and expose the decoded messages instead
+	req := r.Clone(r.Context())
+	req.Body = http.NoBody
+
+	service, name, _ := strings.Cut(method.Name, "/")
+	ctx, err := rt.engine.BuildTemplateContext(req, map[string]string{"service": service, "method": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build template context: %w", err)
+	}
+	ctx.Body = body
+	ctx.Tokens = s.tokens.forSession(sessionID(s.requestSession(r)))
+	ctx.Logger = s.logger
+	ctx.Clock = rt.clockFor(nil)
+	if rt.headerVars {
+		ctx.Vars = templatepkg.HeaderVars(r.Header)
+	}
+
+	var buf bytes.Buffer
+	if err := rt.engine.ExecuteTemplate(method.Tmpl, &buf, ctx); err != nil {
+		return nil, err
+	}
+
+	output := bytes.TrimSpace(buf.Bytes())
+	if !method.Desc.IsStreamingServer() {
+		if len(output) == 0 {
+			output = []byte("{}")
+		}
+		message, err := grpcpkg.EncodeMessage(method.Desc.Output(), output)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{message}, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, fmt.Errorf("streaming method templates must render a JSON array of messages: %w", err)
+	}
+
+	messages := make([][]byte, 0, len(items))
+	for i, item := range items {
+		message, err := grpcpkg.EncodeMessage(method.Desc.Output(), item)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
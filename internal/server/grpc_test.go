@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	grpcpkg "github.com/patrickdappollonio/mockingjay/internal/grpc"
+)
+
+// testDescriptorSet describes a greeter service with unary, server streaming
+// and client streaming methods, shared with the grpc package's tests
+var testDescriptorSet = filepath.Join("..", "grpc", "testdata", "greeter.pb")
+
+// grpcTestServer starts a server speaking HTTP/2 without TLS, like gRPC
+// clients talking to a local mock
+func grpcTestServer(t *testing.T, cfg *config.Config) *httptest.Server {
+	t.Helper()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid configuration: %v", err)
+	}
+
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", slog.New(slog.NewTextHandler(io.Discard, nil)), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewUnstartedServer(srv.httpServer.Handler)
+	httpServer.Config.Protocols = srv.httpServer.Protocols
+	httpServer.Start()
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+// grpcResult is the outcome of a gRPC call
+type grpcResult struct {
+	Status   string
+	Message  string
+	Replies  []any // Decoded response messages
+	Protocol int   // Major HTTP version the call was made with
+}
+
+// callGRPCMethod makes a gRPC call over HTTP/2 without TLS, sending requests
+// as the JSON form of request messages
+func callGRPCMethod(t *testing.T, serverURL string, desc protoreflect.MethodDescriptor, requests ...string) grpcResult {
+	t.Helper()
+
+	var body bytes.Buffer
+	for _, request := range requests {
+		message, err := grpcpkg.EncodeMessage(desc.Input(), []byte(request))
+		if err != nil {
+			t.Fatalf("Failed to encode request: %v", err)
+		}
+		if err := grpcpkg.WriteMessage(&body, message); err != nil {
+			t.Fatalf("Failed to write request: %v", err)
+		}
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{Protocols: protocols}}
+
+	path := "/" + string(desc.Parent().FullName()) + "/" + string(desc.Name())
+	req, err := http.NewRequest("POST", serverURL+path, &body)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/grpc" {
+		t.Fatalf("Expected a 200 application/grpc response, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	messages, err := grpcpkg.ReadMessages(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response messages: %v", err)
+	}
+
+	message, _ := url.PathUnescape(resp.Trailer.Get("Grpc-Message"))
+	result := grpcResult{Status: resp.Trailer.Get("Grpc-Status"), Message: message, Protocol: resp.ProtoMajor}
+	for _, data := range messages {
+		reply, err := grpcpkg.DecodeMessage(desc.Output(), data)
+		if err != nil {
+			t.Fatalf("Failed to decode response message: %v", err)
+		}
+		result.Replies = append(result.Replies, reply)
+	}
+	return result
+}
+
+func TestServer_Integration_GRPC(t *testing.T) {
+	descriptors := []string{testDescriptorSet}
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/ping", Method: "GET", Template: "pong"}})
+	cfg.GRPC = &config.GRPCConfig{
+		DescriptorSets: descriptors,
+		Methods: []config.GRPCMethodConfig{
+			{Method: "greeter.v1.Greeter/SayHello", Template: `{"message": "Hello, {{ .Body.name }}", "count": 1}`},
+			{Method: "greeter.v1.Greeter/StreamHellos", Template: `[{{ range $i, $n := until 3 }}{{ if $i }},{{ end }}{"message": "Hello #{{ add $n 1 }}, {{ $.Body.name }}", "count": {{ add $n 1 }}}{{ end }}]`},
+			{Method: "greeter.v1.Greeter/CollectHellos", Template: `{"message": "{{ .Params.method }} got {{ range .Body }}{{ .name }} {{ end }}", "count": {{ len .Body }}}`},
+			{Method: "greeter.v1.Greeter/SayGoodbye", Status: "PERMISSION_DENIED", Message: "goodbyes are 100% forbidden"},
+		},
+	}
+
+	ts := grpcTestServer(t, cfg)
+
+	files, err := grpcpkg.LoadDescriptorSets(descriptors)
+	if err != nil {
+		t.Fatalf("Failed to load descriptor sets: %v", err)
+	}
+	method := func(name string) protoreflect.MethodDescriptor {
+		desc, err := grpcpkg.FindMethod(files, "greeter.v1.Greeter/"+name)
+		if err != nil {
+			t.Fatalf("Failed to find method: %v", err)
+		}
+		return desc
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		requests []string
+		status   string
+		message  string
+		replies  []string
+	}{
+		{
+			name:     "unary",
+			method:   "SayHello",
+			requests: []string{`{"name": "Ada"}`},
+			status:   "0",
+			replies:  []string{`{"count":1,"message":"Hello, Ada"}`},
+		},
+		{
+			name:     "server streaming",
+			method:   "StreamHellos",
+			requests: []string{`{"name": "Grace"}`},
+			status:   "0",
+			replies: []string{
+				`{"count":1,"message":"Hello #1, Grace"}`,
+				`{"count":2,"message":"Hello #2, Grace"}`,
+				`{"count":3,"message":"Hello #3, Grace"}`,
+			},
+		},
+		{
+			name:     "client streaming",
+			method:   "CollectHellos",
+			requests: []string{`{"name": "Ada"}`, `{"name": "Grace"}`},
+			status:   "0",
+			replies:  []string{`{"count":2,"message":"CollectHellos got Ada Grace "}`},
+		},
+		{
+			name:     "error status",
+			method:   "SayGoodbye",
+			requests: []string{`{"name": "Ada"}`},
+			status:   "7",
+			message:  "goodbyes are 100% forbidden",
+		},
+		{
+			name:    "unary without a request message",
+			method:  "SayHello",
+			status:  "3",
+			message: "expected exactly one request message, got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callGRPCMethod(t, ts.URL, method(tt.method), tt.requests...)
+			if result.Protocol != 2 {
+				t.Errorf("Expected an HTTP/2 call, got HTTP/%d", result.Protocol)
+			}
+			if result.Status != tt.status || result.Message != tt.message {
+				t.Errorf("Expected status %s %q, got %s %q", tt.status, tt.message, result.Status, result.Message)
+			}
+			if len(result.Replies) != len(tt.replies) {
+				t.Fatalf("Expected %d replies, got %d", len(tt.replies), len(result.Replies))
+			}
+			for i, reply := range result.Replies {
+				if got, _ := json.Marshal(reply); string(got) != tt.replies[i] {
+					t.Errorf("Expected reply %d to be %s, got %s", i, tt.replies[i], got)
+				}
+			}
+		})
+	}
+
+	// Methods of the descriptor sets that aren't mocked are unimplemented
+	cfg.GRPC.Methods = cfg.GRPC.Methods[1:]
+	ts = grpcTestServer(t, cfg)
+	if result := callGRPCMethod(t, ts.URL, method("SayHello"), `{"name": "Ada"}`); result.Status != "12" {
+		t.Errorf("Expected status UNIMPLEMENTED, got %s %q", result.Status, result.Message)
+	}
+
+	// Routes are still served alongside gRPC methods
+	resp, err := http.Get(ts.URL + "/ping")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "pong" {
+		t.Errorf("Expected routes to be served, got %q", body)
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// headRequest returns a copy of r asking for GET, to find the route serving
+// HEAD requests no route handles
+func headRequest(r *http.Request) *http.Request {
+	get := *r
+	get.Method = http.MethodGet
+	return &get
+}
+
+// writeHeadResponse answers a HEAD request with the headers of the response
+// a GET would get, announcing the length of its body when the route does.
+// The body itself is never written, so its content type is detected here the
+// way writing it would.
+func writeHeadResponse(w http.ResponseWriter, head *router.Head, status int, body []byte) {
+	if len(body) > 0 && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", http.DetectContentType(body))
+	}
+	if head.SendsContentLength() && body != nil && !config.StatusForbidsBody(status) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	} else {
+		w.Header().Del("Content-Length")
+	}
+	w.WriteHeader(status)
+}
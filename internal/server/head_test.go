@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_Head(t *testing.T) {
+	no := false
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/report", Method: "GET", Template: "hello world", ResponseHeaders: map[string]string{"X-Report": "yes"}},
+		{Path: "/skipped", Method: "GET", Template: `{{ fail "rendered" }}`, Head: &config.HeadConfig{Render: &no}},
+		{Path: "/unsized", Method: "GET", Template: "hello world", Head: &config.HeadConfig{ContentLength: &no}},
+		{Path: "/explicit", Method: "GET", Template: "from GET"},
+		{Path: "/explicit", Method: "HEAD", Template: "from HEAD!", ResponseHeaders: map[string]string{"X-Route": "head"}},
+	})
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path              string
+		wantStatus        int
+		wantContentLength string
+		wantHeader        string
+		wantHeaderValue   string
+	}{
+		{path: "/report", wantStatus: http.StatusOK, wantContentLength: "11", wantHeader: "X-Report", wantHeaderValue: "yes"},
+		{path: "/skipped", wantStatus: http.StatusOK, wantContentLength: ""},
+		{path: "/unsized", wantStatus: http.StatusOK, wantContentLength: ""},
+		{path: "/explicit", wantStatus: http.StatusOK, wantContentLength: "10", wantHeader: "X-Route", wantHeaderValue: "head"},
+		{path: "/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("HEAD", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if body != "" {
+				t.Errorf("Expected no body, got %q", body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if got := resp.Header.Get("Content-Length"); got != tt.wantContentLength {
+					t.Errorf("Expected Content-Length %q, got %q", tt.wantContentLength, got)
+				}
+			}
+			if tt.wantContentLength != "" && resp.Header.Get("Content-Type") != "text/plain; charset=utf-8" {
+				t.Errorf("Expected the content type of the body, got %q", resp.Header.Get("Content-Type"))
+			}
+			if tt.wantHeader != "" && resp.Header.Get(tt.wantHeader) != tt.wantHeaderValue {
+				t.Errorf("Expected %s %q, got %q", tt.wantHeader, tt.wantHeaderValue, resp.Header.Get(tt.wantHeader))
+			}
+		})
+	}
+
+	// GET requests still render the body
+	resp, err := ts.makeRequest("GET", "/skipped", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected the GET request to render the failing template, got %d", resp.StatusCode)
+	}
+}
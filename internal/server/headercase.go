@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// headerCaseWriter sends response headers with the casing they're configured
+// with, for legacy clients looking headers up case-sensitively. Headers are
+// kept canonical while the response is built, so they're read and replaced
+// as usual, and renamed right before they're written. HTTP/2 sends every
+// header in lowercase regardless.
+type headerCaseWriter struct {
+	http.ResponseWriter
+	names   map[string]string // Configured names of headers, by canonical name
+	renamed bool
+}
+
+// newHeaderCaseWriter returns w sending the headers in names with their
+// configured casing, or w itself when there are none
+func newHeaderCaseWriter(w http.ResponseWriter, names map[string]string) http.ResponseWriter {
+	if len(names) == 0 {
+		return w
+	}
+	return &headerCaseWriter{ResponseWriter: w, names: names}
+}
+
+// rename gives headers their configured names, once
+func (w *headerCaseWriter) rename() {
+	if w.renamed {
+		return
+	}
+	w.renamed = true
+
+	header := w.Header()
+	for canonical, name := range w.names {
+		if values, ok := header[canonical]; ok {
+			delete(header, canonical)
+			header[name] = values
+		}
+	}
+}
+
+// WriteHeader renames the headers of final responses before writing them,
+// leaving those of informational ones for the final response to send
+func (w *headerCaseWriter) WriteHeader(status int) {
+	if status >= http.StatusOK {
+		w.rename()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write renames the headers before the first write sends them
+func (w *headerCaseWriter) Write(b []byte) (int, error) {
+	w.rename()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush renames the headers before flushing sends them
+func (w *headerCaseWriter) Flush() {
+	w.rename()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack hands over the connection, for WebSockets and injected faults
+func (w *headerCaseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter, letting
+// http.ResponseController reach features like write deadlines
+func (w *headerCaseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
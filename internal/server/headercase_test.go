@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// rawResponse sends a GET request for path and returns the response as sent,
+// since http.Client canonicalizes the names of response headers
+func rawResponse(t *testing.T, addr, path string) string {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: mock\r\nConnection: close\r\n\r\n"); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	raw, err := io.ReadAll(bufio.NewReader(conn))
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return string(raw)
+}
+
+func TestServer_PreserveHeaderCase(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/orders",
+			Method:          "GET",
+			Template:        `{"orders": []}`,
+			ResponseHeaders: map[string]string{"X-AMZ-Request-Id": "{{ .RequestID }}", "content-type": "application/json", "ETag": `"v1"`},
+		},
+		{
+			Path:            "/quiet",
+			Method:          "GET",
+			Template:        "ok",
+			ResponseHeaders: map[string]string{"X-AMZ-Request-Id": ""},
+		},
+	})
+	cfg.Server.Headers = map[string]string{"x-powered-by": "mockingjay"}
+	cfg.Server.PreserveHeaderCase = true
+
+	srv, err := NewServer(cfg, nil, ":0", slog.New(slog.NewTextHandler(io.Discard, nil)), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	httpServer := httptest.NewServer(http.HandlerFunc(srv.dispatch))
+	defer httpServer.Close()
+	addr := strings.TrimPrefix(httpServer.URL, "http://")
+
+	resp := rawResponse(t, addr, "/orders")
+	for _, expected := range []string{"\r\nX-AMZ-Request-Id: ", "\r\nx-powered-by: mockingjay\r\n", "\r\nETag: \"v1\"\r\n", "\r\nContent-Type: application/json\r\n"} {
+		if !strings.Contains(resp, expected) {
+			t.Errorf("Expected the response to contain %q, got:\n%s", expected, resp)
+		}
+	}
+
+	// Headers are still found by their canonical name while the response is
+	// built, so empty values remove them
+	if resp := rawResponse(t, addr, "/quiet"); strings.Contains(strings.ToLower(resp), "x-amz-request-id") {
+		t.Errorf("Expected the header to be removed, got:\n%s", resp)
+	}
+
+	// Without the option, names are canonical
+	cfg.Server.PreserveHeaderCase = false
+	plain, err := NewServer(cfg, nil, ":0", slog.New(slog.NewTextHandler(io.Discard, nil)), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	plainServer := httptest.NewServer(http.HandlerFunc(plain.dispatch))
+	defer plainServer.Close()
+
+	if resp := rawResponse(t, strings.TrimPrefix(plainServer.URL, "http://"), "/orders"); !strings.Contains(resp, "\r\nX-Amz-Request-Id: ") {
+		t.Errorf("Expected a canonical header name, got:\n%s", resp)
+	}
+}
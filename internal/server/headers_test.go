@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_GlobalHeaders(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/users",
+			Method:   "GET",
+			Template: "users",
+		},
+		{
+			Path:            "/orders",
+			Method:          "GET",
+			Template:        "orders",
+			ResponseHeaders: map[string]string{"X-Mock": "orders", "Server": "", "Cache-Control": ""},
+		},
+	})
+	cfg.ResponseHeaders = map[string]string{"X-Mock": "mockingjay", "X-Route": "{{ .Route.Pattern }}", "Cache-Control": "no-store"}
+	cfg.Server.Headers = map[string]string{"Server": "mockingjay"}
+	ts := NewTestServer(t, cfg)
+	handler := ts.handler()
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		expected map[string]string
+	}{
+		{
+			name:     "global headers",
+			path:     "/users",
+			status:   http.StatusOK,
+			expected: map[string]string{"Server": "mockingjay", "X-Mock": "mockingjay", "X-Route": "/users", "Cache-Control": "no-store"},
+		},
+		{
+			name:     "route overrides",
+			path:     "/orders",
+			status:   http.StatusOK,
+			expected: map[string]string{"Server": "", "X-Mock": "orders", "X-Route": "/orders", "Cache-Control": ""},
+		},
+		{
+			name:     "built-in errors",
+			path:     "/missing",
+			status:   http.StatusNotFound,
+			expected: map[string]string{"Server": "mockingjay", "X-Mock": ""},
+		},
+		{
+			name:     "admin API",
+			path:     "/__admin/scenarios",
+			status:   http.StatusOK,
+			expected: map[string]string{"Server": "mockingjay", "X-Mock": ""},
+		},
+		{
+			name:     "health check",
+			path:     "/health",
+			status:   http.StatusOK,
+			expected: map[string]string{"Server": "mockingjay", "X-Mock": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Serve through the middleware chain, where server headers are set
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, httptest.NewRequest("GET", tt.path, nil))
+
+			if resp.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.Code)
+			}
+			for name, expected := range tt.expected {
+				if got := resp.Header().Get(name); got != expected {
+					t.Errorf("Expected %s to be %q, got %q", name, expected, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// headerIdempotentReplayed marks responses replayed for a retried
+// idempotency key, like the APIs the feature mimics do
+const headerIdempotentReplayed = "Idempotent-Replayed"
+
+// idempotencyKey identifies a remembered response by route, key and session,
+// so the same key can be used with different routes and by parallel sessions
+type idempotencyKey struct {
+	Route   string
+	Key     string
+	Session string // Admin session the request was made under (empty outside sessions)
+}
+
+// idempotentResponse is the response remembered for an idempotency key
+type idempotentResponse struct {
+	fingerprint [sha256.Size]byte // Digest of the request the response was served to
+	pending     bool              // Set while the first request is still being served
+	status      int
+	header      http.Header
+	body        []byte
+	expiresAt   time.Time
+}
+
+// idempotencyStore remembers the responses of routes with idempotency keys.
+// Responses survive configuration reloads, until they expire or are reset
+// through the admin API.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	responses map[idempotencyKey]*idempotentResponse
+	now       func() time.Time
+}
+
+// newIdempotencyStore creates an empty idempotency store
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{responses: make(map[idempotencyKey]*idempotentResponse), now: time.Now}
+}
+
+// begin looks up the response remembered for key. When there's none, it
+// reserves key for the request with the given fingerprint and returns nil,
+// and the request's response must then be stored with finish or dropped with
+// abandon.
+func (is *idempotencyStore) begin(key idempotencyKey, fingerprint [sha256.Size]byte, ttl time.Duration) *idempotentResponse {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	now := is.now()
+	for k, resp := range is.responses {
+		if !resp.pending && !now.Before(resp.expiresAt) {
+			delete(is.responses, k)
+		}
+	}
+
+	if resp, found := is.responses[key]; found {
+		copied := *resp
+		return &copied
+	}
+
+	is.responses[key] = &idempotentResponse{fingerprint: fingerprint, pending: true, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// finish stores the response served for a key reserved with begin
+func (is *idempotencyStore) finish(key idempotencyKey, status int, header http.Header, body []byte) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	resp, found := is.responses[key]
+	if !found {
+		return
+	}
+	resp.pending = false
+	resp.status = status
+	resp.header = header
+	resp.body = body
+}
+
+// abandon forgets a key reserved with begin whose request got no response,
+// so a retry is served again
+func (is *idempotencyStore) abandon(key idempotencyKey) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if resp, found := is.responses[key]; found && resp.pending {
+		delete(is.responses, key)
+	}
+}
+
+// reset forgets the responses of the route with the given ID, or of every
+// route when it's empty, remembered under session, or outside sessions when
+// it's empty, and returns how many were forgotten
+func (is *idempotencyStore) reset(route, session string) int {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	removed := 0
+	for key := range is.responses {
+		if key.Session != session || (route != "" && key.Route != route) {
+			continue
+		}
+		delete(is.responses, key)
+		removed++
+	}
+	return removed
+}
+
+// resetSession forgets the responses remembered under the given session and
+// returns how many were forgotten
+func (is *idempotencyStore) resetSession(session string) int {
+	return is.reset("", session)
+}
+
+// idempotencyState represents the JSON form of a remembered response
+type idempotencyState struct {
+	Route     string    `json:"route"`
+	Key       string    `json:"key"`
+	Session   string    `json:"session,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Pending   bool      `json:"pending"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// list returns the remembered responses that haven't expired, sorted by
+// route, key and session
+func (is *idempotencyStore) list() []idempotencyState {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	now := is.now()
+	states := make([]idempotencyState, 0, len(is.responses))
+	for key, resp := range is.responses {
+		if !resp.pending && !now.Before(resp.expiresAt) {
+			continue
+		}
+		states = append(states, idempotencyState{
+			Route:     key.Route,
+			Key:       key.Key,
+			Session:   key.Session,
+			Status:    resp.status,
+			Pending:   resp.pending,
+			ExpiresAt: resp.expiresAt,
+		})
+	}
+
+	slices.SortFunc(states, func(a, b idempotencyState) int {
+		if c := strings.Compare(a.Route, b.Route); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Session, b.Session)
+	})
+	return states
+}
+
+// idempotentWriter passes a response through to the client while recording
+// it, so it can be replayed to retries
+type idempotentWriter struct {
+	http.ResponseWriter
+	key    idempotencyKey
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status and headers of the response
+func (w *idempotentWriter) WriteHeader(status int) {
+	if w.status == 0 && status >= 200 {
+		w.status = status
+		w.header = w.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body of the response
+func (w *idempotentWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter, letting
+// http.ResponseController flush it and hijack its connection
+func (w *idempotentWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// requestFingerprint digests what makes a request different from another
+// sending the same idempotency key: its method, URL and body. The body is
+// left readable by the route serving the request.
+func requestFingerprint(r *http.Request) ([sha256.Size]byte, error) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	digest := sha256.New()
+	fmt.Fprintf(digest, "%s %s\n", r.Method, r.URL.RequestURI())
+	digest.Write(body)
+	return [sha256.Size]byte(digest.Sum(nil)), nil
+}
+
+// beginIdempotent replays the response remembered for the idempotency key of
+// a request, answering with a 409 Conflict while the first request with the
+// key is still being served, or when the route rejects keys reused with a
+// different request. It returns false with the status it answered with when
+// it did. Otherwise, it returns the writer the request must be served with,
+// which is nil for requests without a key, and whose response must then be
+// remembered with finishIdempotent.
+func (s *Server) beginIdempotent(w http.ResponseWriter, r *http.Request, route *router.Route) (*idempotentWriter, int, bool) {
+	value := r.Header.Get(route.Idempotency.Header)
+	if value == "" {
+		return nil, 0, true
+	}
+
+	fingerprint, err := requestFingerprint(r)
+	if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+		middleware.WritePayloadTooLarge(w, r, tooLarge.Limit)
+		return nil, http.StatusRequestEntityTooLarge, false
+	}
+	if err != nil {
+		s.handleServerError(w, r, fmt.Errorf("failed to read request body: %w", err))
+		return nil, http.StatusInternalServerError, false
+	}
+
+	key := idempotencyKey{Route: scenarioRouteID(route), Key: value, Session: sessionID(s.requestSession(r))}
+	remembered := s.idempotency.begin(key, fingerprint, route.Idempotency.TTL)
+	switch {
+	case remembered == nil:
+		return &idempotentWriter{ResponseWriter: w, key: key}, 0, true
+
+	case remembered.pending:
+		s.handleIdempotencyConflict(w, r, route, fmt.Sprintf("a request with the %s %q is still being served", route.Idempotency.Header, value))
+		return nil, http.StatusConflict, false
+
+	case remembered.fingerprint != fingerprint && route.Idempotency.RejectConflicts:
+		s.handleIdempotencyConflict(w, r, route, fmt.Sprintf("the %s %q was already used with a different request", route.Idempotency.Header, value))
+		return nil, http.StatusConflict, false
+	}
+
+	for name, values := range remembered.header {
+		if name != "Date" {
+			w.Header()[name] = slices.Clone(values)
+		}
+	}
+	w.Header().Set(headerIdempotentReplayed, "true")
+	w.WriteHeader(remembered.status)
+	_, _ = w.Write(remembered.body) // Headers are already sent, nothing else to do on failure
+	return nil, remembered.status, false
+}
+
+// finishIdempotent remembers the response written through w, unless the
+// request was cancelled or got no response, in which case a retry is served
+// again
+func (s *Server) finishIdempotent(w *idempotentWriter, r *http.Request) {
+	if w.status == 0 || r.Context().Err() != nil {
+		s.idempotency.abandon(w.key)
+		return
+	}
+	s.idempotency.finish(w.key, w.status, w.header, w.body.Bytes())
+}
+
+// handleIdempotencyConflict handles requests whose idempotency key can't be
+// replayed
+func (s *Server) handleIdempotencyConflict(w http.ResponseWriter, r *http.Request, route *router.Route, detail string) {
+	problem.Write(w, r, http.StatusConflict, problem.CodeIdempotencyConflict, detail, "409 Conflict: "+detail+"\n")
+
+	s.logger.Warn("idempotency key conflict",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"route", route.Pattern,
+		"reason", detail,
+	)
+}
+
+// handleListIdempotency lists the responses remembered by idempotency key
+func (s *Server) handleListIdempotency(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"idempotency": s.idempotency.list()})
+}
+
+// handleResetIdempotency forgets the responses remembered under the session
+// the request is made under, or outside sessions, so requests retrying with
+// their keys are served again. The optional "route" query parameter, like
+// "POST /payments", limits the reset to one route.
+func (s *Server) handleResetIdempotency(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	route := r.URL.Query().Get("route")
+	count := s.idempotency.reset(route, sessionID(sess))
+
+	s.logger.Info("idempotency keys reset", "route", route, "session", sessionID(sess), "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"reset": count})
+}
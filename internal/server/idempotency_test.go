@@ -0,0 +1,194 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	now := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store := newIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	key := idempotencyKey{Route: "POST /payments", Key: "abc"}
+	fingerprint := sha256.Sum256([]byte("request"))
+
+	if remembered := store.begin(key, fingerprint, time.Minute); remembered != nil {
+		t.Fatalf("Expected a new key, got %+v", remembered)
+	}
+	if remembered := store.begin(key, fingerprint, time.Minute); remembered == nil || !remembered.pending {
+		t.Fatalf("Expected the key to be pending, got %+v", remembered)
+	}
+
+	store.finish(key, http.StatusCreated, http.Header{"X-Id": {"1"}}, []byte("created"))
+	remembered := store.begin(key, fingerprint, time.Minute)
+	if remembered == nil || remembered.pending || remembered.status != http.StatusCreated || string(remembered.body) != "created" {
+		t.Fatalf("Expected the response to be remembered, got %+v", remembered)
+	}
+
+	// Responses are forgotten once they expire
+	now = now.Add(time.Minute)
+	if states := store.list(); len(states) != 0 {
+		t.Errorf("Expected expired responses not to be listed, got %+v", states)
+	}
+	if remembered := store.begin(key, fingerprint, time.Minute); remembered != nil {
+		t.Fatalf("Expected the expired key to be reserved again, got %+v", remembered)
+	}
+
+	// Abandoned keys are served again
+	store.abandon(key)
+	if remembered := store.begin(key, fingerprint, time.Minute); remembered != nil {
+		t.Fatalf("Expected the abandoned key to be reserved again, got %+v", remembered)
+	}
+
+	if count := store.reset("GET /other", ""); count != 0 {
+		t.Errorf("Expected no responses of another route to be reset, got %d", count)
+	}
+	if count := store.reset("POST /payments", ""); count != 1 {
+		t.Errorf("Expected 1 response to be reset, got %d", count)
+	}
+}
+
+func TestServer_Integration_Idempotency(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/payments",
+			Method:          "POST",
+			Template:        `{"id": "{{ uuidv4 }}", "amount": {{ .Body.amount }}}`,
+			ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+			Idempotency:     &config.IdempotencyConfig{},
+		},
+		{
+			Path:        "/refunds",
+			Method:      "POST",
+			Template:    `{"id": "{{ uuidv4 }}"}`,
+			Idempotency: &config.IdempotencyConfig{Header: "X-Request-Key", RejectConflicts: true},
+		},
+	})
+	ts := NewTestServer(t, cfg)
+
+	post := func(path, key, body string) (*http.Response, string) {
+		t.Helper()
+		headers := map[string]string{"Content-Type": "application/json"}
+		if key != "" {
+			headers["Idempotency-Key"] = key
+			headers["X-Request-Key"] = key
+		}
+		resp, err := ts.makeRequest("POST", path, strings.NewReader(body), headers)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp, readResponseBody(t, resp)
+	}
+
+	first, firstBody := post("/payments", "abc", `{"amount": 10}`)
+	if first.StatusCode != http.StatusOK || first.Header.Get(headerIdempotentReplayed) != "" {
+		t.Fatalf("Expected a fresh 200 response, got %d with %s %q", first.StatusCode, headerIdempotentReplayed, first.Header.Get(headerIdempotentReplayed))
+	}
+
+	// Retries with the same key get the same response, even with a different body
+	for _, body := range []string{`{"amount": 10}`, `{"amount": 20}`} {
+		resp, got := post("/payments", "abc", body)
+		if got != firstBody || resp.Header.Get(headerIdempotentReplayed) != "true" {
+			t.Errorf("Expected the response %s to be replayed, got %s with %s %q", firstBody, got, headerIdempotentReplayed, resp.Header.Get(headerIdempotentReplayed))
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Expected the replayed Content-Type, got %q", got)
+		}
+	}
+
+	// Other keys and requests without a key are served again
+	if _, got := post("/payments", "def", `{"amount": 10}`); got == firstBody {
+		t.Errorf("Expected a new response for another key, got %s", got)
+	}
+	if _, got := post("/payments", "", `{"amount": 10}`); got == firstBody {
+		t.Errorf("Expected a new response without a key, got %s", got)
+	}
+
+	// Keys are scoped per route, and routes can reject conflicting reuses
+	refund, refundBody := post("/refunds", "abc", `{"payment": 1}`)
+	if refund.StatusCode != http.StatusOK || refundBody == firstBody {
+		t.Fatalf("Expected a fresh refund, got %d %s", refund.StatusCode, refundBody)
+	}
+	if _, got := post("/refunds", "abc", `{"payment": 1}`); got != refundBody {
+		t.Errorf("Expected the refund %s to be replayed, got %s", refundBody, got)
+	}
+	conflict, conflictBody := post("/refunds", "abc", `{"payment": 2}`)
+	if conflict.StatusCode != http.StatusConflict || !strings.Contains(conflictBody, `X-Request-Key "abc" was already used with a different request`) {
+		t.Errorf("Expected a 409 for the conflicting reuse, got %d %q", conflict.StatusCode, conflictBody)
+	}
+
+	// The admin API lists and resets remembered responses
+	resp, err := ts.makeRequest("GET", "/__admin/idempotency", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var listed struct {
+		Idempotency []idempotencyState `json:"idempotency"`
+	}
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &listed); err != nil {
+		t.Fatalf("Failed to decode the listed keys: %v", err)
+	}
+	if len(listed.Idempotency) != 3 || listed.Idempotency[0].Route != "POST /payments" || listed.Idempotency[0].Key != "abc" {
+		t.Errorf("Expected 3 remembered responses, got %+v", listed.Idempotency)
+	}
+
+	resp, err = ts.makeRequest("DELETE", "/__admin/idempotency?route=POST+/payments", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); !strings.Contains(body, `"reset": 2`) {
+		t.Errorf("Expected 2 responses to be reset, got %s", body)
+	}
+	if _, got := post("/payments", "abc", `{"amount": 10}`); got == firstBody {
+		t.Errorf("Expected a new response after the reset, got %s", got)
+	}
+}
+
+func TestServer_Integration_IdempotencyInProgress(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:        "/payments",
+			Method:      "POST",
+			Template:    "charged",
+			Delay:       &config.DelayConfig{Min: 200 * time.Millisecond, Max: 200 * time.Millisecond},
+			Idempotency: &config.IdempotencyConfig{},
+		},
+	})
+	ts := NewTestServer(t, cfg)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, err := ts.makeRequest("POST", "/payments", nil, map[string]string{"Idempotency-Key": "abc"})
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+			return
+		}
+		if body := readResponseBody(t, resp); body != "charged" {
+			t.Errorf("Expected the first request to be served, got %q", body)
+		}
+	}()
+
+	// Give the first request time to reserve the key
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := ts.makeRequest("POST", "/payments", nil, map[string]string{"Idempotency-Key": "abc"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+	wg.Wait()
+
+	if resp.StatusCode != http.StatusConflict || !strings.Contains(body, `Idempotency-Key "abc" is still being served`) {
+		t.Errorf("Expected a 409 while the first request is served, got %d %q", resp.StatusCode, body)
+	}
+}
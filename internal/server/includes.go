@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// includeRefreshTick is how often remote includes are checked for being due
+// a refresh
+const includeRefreshTick = time.Second
+
+// includeRefresher tracks the remote includes checked for changes while
+// running, and when each one was last checked
+type includeRefresher struct {
+	mu       sync.Mutex
+	includes []config.IncludeConfig
+	checked  map[string]time.Time
+}
+
+// newIncludeRefresher creates a refresher for the remote includes of cfg
+func newIncludeRefresher(cfg *config.Config) *includeRefresher {
+	r := &includeRefresher{}
+	r.configure(cfg.RemoteIncludes())
+	return r
+}
+
+// configure replaces the includes to refresh. Every remote include was just
+// revalidated by loading the configuration, so all of them count as checked.
+func (r *includeRefresher) configure(includes []config.IncludeConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.includes = includes
+	r.checked = make(map[string]time.Time, len(includes))
+	for _, inc := range includes {
+		r.checked[inc.URL] = now
+	}
+}
+
+// due returns the includes whose refresh interval elapsed by now, marking
+// them as checked
+func (r *includeRefresher) due(now time.Time) []config.IncludeConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []config.IncludeConfig
+	for _, inc := range r.includes {
+		if now.Sub(r.checked[inc.URL]) >= inc.Refresh {
+			r.checked[inc.URL] = now
+			due = append(due, inc)
+		}
+	}
+	return due
+}
+
+// refreshIncludes checks remote includes for changes at their refresh
+// interval until ctx is done
+func (s *Server) refreshIncludes(ctx context.Context) {
+	ticker := time.NewTicker(includeRefreshTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.refreshDueIncludes(now)
+		}
+	}
+}
+
+// refreshDueIncludes checks the remote includes due a refresh by now, and
+// reloads the configuration once one of them changed. Reloading revalidates
+// every remote include, so the remaining ones don't need checking.
+func (s *Server) refreshDueIncludes(now time.Time) {
+	for _, inc := range s.includes.due(now) {
+		changed, err := config.RefreshInclude(inc)
+		if err != nil {
+			s.logger.Warn("failed to refresh remote include", "url", inc.URL, "error", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		s.logger.Info("remote include changed, reloading configuration", "url", inc.URL)
+		if err := s.ReloadConfig(); err != nil {
+			s.logger.Error("failed to reload configuration", "error", err)
+		}
+		return
+	}
+}
+
+// logConfigWarnings logs the problems worked around while loading cfg
+func (s *Server) logConfigWarnings(cfg *config.Config) {
+	for _, warning := range cfg.Warnings() {
+		s.logger.Warn("configuration loaded with warnings", "warning", warning)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_RemoteIncludeRefresh(t *testing.T) {
+	t.Setenv(config.CacheDirEnv, t.TempDir())
+
+	var mu sync.Mutex
+	body := "first"
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "routes:\n  - path: /users\n    method: GET\n    template: %q", body)
+	}))
+	defer upstream.Close()
+
+	// Remote includes are fetched with the default transport, which has to
+	// trust the test server's certificate
+	transport := http.DefaultTransport
+	http.DefaultTransport = upstream.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	mainFile := filepath.Join(t.TempDir(), "main.yaml")
+	content := fmt.Sprintf("include:\n  - url: %s/users.yaml\n    refresh: 1m\nroutes:\n  - path: /health\n    method: GET\n    template: ok", upstream.URL)
+	if err := os.WriteFile(mainFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+	if err := ts.Server.applyConfigFiles(cfg, []string{mainFile}); err != nil {
+		t.Fatalf("Failed to apply config: %v", err)
+	}
+
+	call := func() string {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/users", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+
+	if got := call(); got != "first" {
+		t.Fatalf("Expected the remote route's response %q, got %q", "first", got)
+	}
+
+	mu.Lock()
+	body = "second"
+	mu.Unlock()
+
+	// Not due yet, so the old routes are still served
+	ts.Server.refreshDueIncludes(time.Now())
+	if got := call(); got != "first" {
+		t.Errorf("Expected the remote include not to be refreshed yet, got %q", got)
+	}
+
+	ts.Server.refreshDueIncludes(time.Now().Add(time.Minute))
+	if got := call(); got != "second" {
+		t.Errorf("Expected the refreshed remote route's response %q, got %q", "second", got)
+	}
+}
+
+func TestServer_Integration_RemoteIncludeDataFile(t *testing.T) {
+	t.Setenv(config.CacheDirEnv, t.TempDir())
+
+	secret := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(secret, []byte(`{"variant": "b", "token": "hunter2"}`), 0o644); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+	read := fmt.Sprintf(`(dataFile %q)`, secret)
+
+	remote := fmt.Sprintf(`routes:
+  - path: /route-header
+    method: GET
+    template: ok
+    response_headers:
+      X-Token: '{{ index %[1]s "token" }}'
+  - path: /response-header
+    method: GET
+    responses:
+      - template: ok
+        response_headers:
+          X-Token: '{{ index %[1]s "token" }}'
+  - path: /selector
+    method: GET
+    variants:
+      selector: '{{ index %[1]s "variant" }}'
+      default: a
+      cases:
+        a:
+          template: a
+        b:
+          template: hunter2
+`, read)
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, remote)
+	}))
+	defer upstream.Close()
+
+	transport := http.DefaultTransport
+	http.DefaultTransport = upstream.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	// The same header template works in local files
+	mainFile := filepath.Join(t.TempDir(), "main.yaml")
+	content := fmt.Sprintf("include:\n  - %s/remote.yaml\nroutes:\n  - path: /local\n    method: GET\n    template: ok\n    response_headers:\n      X-Token: '{{ index %s \"token\" }}'", upstream.URL, read)
+	if err := os.WriteFile(mainFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	resp, err := ts.makeRequest("GET", "/local", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if got := resp.Header.Get("X-Token"); got != "hunter2" {
+		t.Fatalf("Expected the local route to read the data file, got %q", got)
+	}
+
+	for _, path := range []string{"/route-header", "/response-header", "/selector"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Errorf("Expected status 500, got %d: %s", resp.StatusCode, body)
+			}
+			if strings.Contains(body, "hunter2") || resp.Header.Get("X-Token") != "" {
+				t.Errorf("Expected the data file not to be read, got headers %v and body %q", resp.Header, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Diagnostic headers emitted in dev mode to explain artificial behavior
+const (
+	headerInjectedDelay = "X-Mockingjay-Injected-Delay"
+	headerInjectedFault = "X-Mockingjay-Fault"
+	headerVariant       = "X-Mockingjay-Variant"
+	headerQueueWait     = "X-Mockingjay-Queue-Wait"
+)
+
+// injections records the artificial latency and faults applied to a single request,
+// and the variant it was served, so that, in dev mode, they can be surfaced to the
+// client as response headers
+type injections struct {
+	Delay   time.Duration // Total artificial delay applied before responding
+	Faults  []string      // Names of the faults injected into the response
+	Variant string        // Name of the variant served, if the route has variants
+
+	// Time waited for a slot, on routes limiting how many requests they serve at once
+	QueueWait *time.Duration
+}
+
+// setHeaders writes the diagnostic headers for any recorded injections.
+// Nothing is written when no delay, fault, variant or queue was applied.
+func (inj *injections) setHeaders(h http.Header) {
+	if inj == nil {
+		return
+	}
+
+	if inj.Delay > 0 {
+		h.Set(headerInjectedDelay, inj.Delay.String())
+	}
+
+	if len(inj.Faults) > 0 {
+		h.Set(headerInjectedFault, strings.Join(inj.Faults, ", "))
+	}
+
+	if inj.Variant != "" {
+		h.Set(headerVariant, inj.Variant)
+	}
+
+	if inj.QueueWait != nil {
+		h.Set(headerQueueWait, inj.QueueWait.String())
+	}
+}
+
+// sleepContext waits for d, returning early with the context's error if it is
+// cancelled first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInjections_SetHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		inj           *injections
+		expectedDelay string
+		expectedFault string
+	}{
+		{
+			name:          "nil injections",
+			inj:           nil,
+			expectedDelay: "",
+			expectedFault: "",
+		},
+		{
+			name:          "nothing injected",
+			inj:           &injections{},
+			expectedDelay: "",
+			expectedFault: "",
+		},
+		{
+			name:          "delay only",
+			inj:           &injections{Delay: 250 * time.Millisecond},
+			expectedDelay: "250ms",
+			expectedFault: "",
+		},
+		{
+			name:          "delay and faults",
+			inj:           &injections{Delay: time.Second, Faults: []string{"status_500", "truncate"}},
+			expectedDelay: "1s",
+			expectedFault: "status_500, truncate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			tt.inj.setHeaders(h)
+
+			if got := h.Get(headerInjectedDelay); got != tt.expectedDelay {
+				t.Errorf("Expected %s %q, got %q", headerInjectedDelay, tt.expectedDelay, got)
+			}
+			if got := h.Get(headerInjectedFault); got != tt.expectedFault {
+				t.Errorf("Expected %s %q, got %q", headerInjectedFault, tt.expectedFault, got)
+			}
+		})
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Expected sleep to complete, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := sleepContext(ctx, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected sleep to stop when the context was cancelled, took %s", elapsed)
+	}
+}
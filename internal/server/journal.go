@@ -0,0 +1,513 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/metrics"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+const (
+	defaultJournalLimit = 100  // Entries returned per page when no limit is given
+	maxJournalLimit     = 1000 // Largest page size that can be requested
+)
+
+// Journal metric names
+const (
+	metricJournalRecorded      = "journal_requests_recorded_total" // Requests added to the journal
+	metricJournalDropped       = "journal_entries_dropped_total"   // Entries dropped to stay within max_entries
+	metricJournalTruncated     = "journal_bodies_truncated_total"  // Bodies cut short to stay within max_body_bytes
+	metricJournalPersistErrors = "journal_persist_errors_total"    // Entries that couldn't be written to disk
+	metricJournalEntries       = "journal_entries"                 // Entries currently held in memory
+	metricJournalStreamDropped = "journal_stream_dropped_total"    // Entries not delivered to slow stream subscribers
+)
+
+// journalSubscriberBuffer is how many entries a stream subscriber can fall
+// behind before new entries are dropped for it
+const journalSubscriberBuffer = 256
+
+// journalEntry is a request served by the mock, as recorded in the journal
+type journalEntry struct {
+	ID            int64       `json:"id"`
+	Timestamp     time.Time   `json:"timestamp"`
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	Query         string      `json:"query,omitempty"`
+	Headers       http.Header `json:"headers"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+	Route         string      `json:"route,omitempty"` // Matched route as "METHOD pattern", empty when none matched
+	Status        int         `json:"status"`
+	Duration      string      `json:"duration"`
+	RemoteAddr    string      `json:"remote_addr"`
+}
+
+// newJournalEntry builds the journal entry of a served request
+func newJournalEntry(r *http.Request, body []byte, truncated bool, status int, route *router.Route, start time.Time) journalEntry {
+	entry := journalEntry{
+		Timestamp:     start,
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         r.URL.RawQuery,
+		Headers:       r.Header.Clone(),
+		Body:          string(body),
+		BodyTruncated: truncated,
+		Status:        status,
+		Duration:      time.Since(start).String(),
+		RemoteAddr:    r.RemoteAddr,
+	}
+
+	if route != nil {
+		entry.Route = scenarioRouteID(route)
+	}
+	return entry
+}
+
+// captureRequestBody reads up to limit bytes of the request body for the
+// journal, leaving the full body readable by the route serving the request.
+// It reports whether the body was longer than limit.
+func captureRequestBody(r *http.Request, limit int) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, false
+	}
+
+	captured, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), r.Body), r.Body}
+	if err != nil {
+		return nil, false
+	}
+
+	if len(captured) > limit {
+		return captured[:limit], true
+	}
+	return captured, false
+}
+
+// journal holds the most recent requests in the order they were received,
+// writing every entry through to a storage collection
+type journal struct {
+	mu           sync.Mutex
+	entries      []journalEntry
+	nextID       int64
+	maxEntries   int
+	maxBodyBytes int
+	driverStore  storage.Collection // Collection of the storage driver
+	store        storage.Collection // Collection entries are persisted to, the driver's unless a path is set
+	path         string             // File entries are persisted to, when set in the journal configuration
+	logger       *slog.Logger
+	metrics      *metrics.Registry
+	subscribers  map[chan journalEntry]struct{} // Live streams receiving new entries
+	streamsEnded bool                           // Set on shutdown, when no new streams are accepted
+}
+
+// newJournal creates a journal with the given bounds, restoring the entries
+// kept in store
+func newJournal(cfg config.JournalConfig, store storage.Collection, logger *slog.Logger, registry *metrics.Registry) (*journal, error) {
+	j := &journal{
+		driverStore: store,
+		logger:      logger,
+		metrics:     registry,
+		subscribers: make(map[chan journalEntry]struct{}),
+	}
+	if err := j.configure(cfg); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// configure applies new bounds, dropping the oldest entries if there are now
+// too many, and switches to persisting to a file when the path changed.
+// Entries already persisted are loaded when the journal is still empty.
+func (j *journal) configure(cfg config.JournalConfig) error {
+	cfg = cfg.GetWithDefaults()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.maxEntries = cfg.MaxEntries
+	j.maxBodyBytes = cfg.MaxBodyBytes
+
+	var path string
+	if cfg.Persist {
+		path = cfg.Path
+	}
+	if j.store != nil && path == j.path {
+		j.trim()
+		return nil
+	}
+
+	if err := j.closeFile(); err != nil {
+		return err
+	}
+
+	store := j.driverStore
+	if path != "" {
+		file, err := storage.OpenFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to open journal file: %w", err)
+		}
+		store = file
+	}
+	j.store, j.path = store, path
+
+	if len(j.entries) == 0 {
+		if err := j.load(); err != nil {
+			return err
+		}
+	}
+	j.trim()
+	return nil
+}
+
+// load restores previously persisted entries
+func (j *journal) load() error {
+	records, err := j.store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load journal: %w", err)
+	}
+
+	for _, record := range records {
+		var entry journalEntry
+		if err := json.Unmarshal(record.Value, &entry); err != nil {
+			return fmt.Errorf("failed to parse journal entry %q: %w", record.Key, err)
+		}
+		j.entries = append(j.entries, entry)
+		j.nextID = max(j.nextID, entry.ID)
+	}
+
+	// Restored entries beyond the bound were already counted as dropped
+	if excess := len(j.entries) - j.maxEntries; excess > 0 {
+		j.forget(j.entries[:excess])
+		j.entries = j.entries[excess:]
+	}
+	j.metrics.Set(metricJournalEntries, int64(len(j.entries)))
+	return nil
+}
+
+// trim drops the oldest entries beyond the bound
+func (j *journal) trim() {
+	if excess := len(j.entries) - j.maxEntries; excess > 0 {
+		j.forget(j.entries[:excess])
+		j.entries = j.entries[excess:]
+		j.metrics.Add(metricJournalDropped, int64(excess))
+	}
+	j.metrics.Set(metricJournalEntries, int64(len(j.entries)))
+}
+
+// forget removes dropped entries from the store
+func (j *journal) forget(entries []journalEntry) {
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, strconv.FormatInt(entry.ID, 10))
+	}
+
+	if err := j.store.Delete(keys...); err != nil {
+		j.metrics.Inc(metricJournalPersistErrors)
+		j.logger.Error("failed to remove dropped journal entries", "error", err)
+	}
+}
+
+// bodyLimit returns how many request body bytes are kept per entry
+func (j *journal) bodyLimit() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.maxBodyBytes
+}
+
+// add stores an entry, dropping the oldest one when full
+func (j *journal) add(entry journalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.nextID++
+	entry.ID = j.nextID
+
+	j.entries = append(j.entries, entry)
+	j.persist(entry)
+	j.trim()
+
+	j.metrics.Inc(metricJournalRecorded)
+	if entry.BodyTruncated {
+		j.metrics.Inc(metricJournalTruncated)
+	}
+
+	for ch := range j.subscribers {
+		select {
+		case ch <- entry:
+		default:
+			j.metrics.Inc(metricJournalStreamDropped)
+		}
+	}
+}
+
+// persist writes an entry to the store
+func (j *journal) persist(entry journalEntry) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = j.store.Put(strconv.FormatInt(entry.ID, 10), data)
+	}
+	if err != nil {
+		j.metrics.Inc(metricJournalPersistErrors)
+		j.logger.Error("failed to persist journal entry", "error", err)
+	}
+}
+
+// subscribe registers a live stream of new entries. When afterID is positive,
+// the entries already in the journal with a greater ID are returned as a
+// backlog, oldest first. The returned function must be called to unsubscribe.
+func (j *journal) subscribe(afterID int64) ([]journalEntry, <-chan journalEntry, func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var backlog []journalEntry
+	for _, entry := range j.entries {
+		if afterID > 0 && entry.ID > afterID {
+			backlog = append(backlog, entry)
+		}
+	}
+
+	ch := make(chan journalEntry, journalSubscriberBuffer)
+	if j.streamsEnded {
+		close(ch)
+		return backlog, ch, func() {}
+	}
+	j.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		delete(j.subscribers, ch)
+	}
+	return backlog, ch, unsubscribe
+}
+
+// endStreams closes the channel of every subscriber, so live streams finish
+// and don't hold up a graceful shutdown
+func (j *journal) endStreams() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for ch := range j.subscribers {
+		close(ch)
+		delete(j.subscribers, ch)
+	}
+	j.streamsEnded = true
+}
+
+// clear removes every entry, including persisted ones, and returns how many
+// were removed
+func (j *journal) clear() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	count := len(j.entries)
+	j.entries = nil
+	j.metrics.Set(metricJournalEntries, 0)
+
+	if err := j.store.Clear(); err != nil {
+		j.logger.Error("failed to clear persisted journal", "error", err)
+	}
+	return count
+}
+
+// close stops persisting entries to the journal's own file, if any. The
+// storage driver's collection is closed along with the driver.
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.closeFile()
+}
+
+// closeFile closes the journal's own file, if any, and goes back to
+// persisting through the storage driver
+func (j *journal) closeFile() error {
+	if j.path == "" {
+		return nil
+	}
+
+	err := j.store.Close()
+	j.store, j.path = j.driverStore, ""
+	if err != nil {
+		return fmt.Errorf("failed to close journal file: %w", err)
+	}
+	return nil
+}
+
+// journalQuery selects and pages through journal entries
+type journalQuery struct {
+	Since  time.Time      // Only entries received at or after this time
+	Until  time.Time      // Only entries received before this time
+	Method string         // Only entries with this method
+	Path   string         // Only entries with this exact path
+	PathRE *regexp.Regexp // Only entries whose path matches, for "/.../" path filters
+	Body   string         // Only entries whose body contains this text
+	Oldest bool           // Return the oldest entries first instead of the newest
+	Limit  int            // Maximum number of entries returned
+	Offset int            // Number of matching entries skipped
+}
+
+// parseJournalQuery parses the query parameters of a journal request
+func parseJournalQuery(values map[string][]string) (journalQuery, error) {
+	get := func(key string) string {
+		if v := values[key]; len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+
+	q := journalQuery{
+		Method: strings.ToUpper(get("method")),
+		Body:   get("body"),
+		Limit:  defaultJournalLimit,
+	}
+
+	for key, target := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		if raw := get(key); raw != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return q, fmt.Errorf("invalid %q timestamp %q, must be RFC 3339 such as 2006-01-02T15:04:05Z", key, raw)
+			}
+			*target = parsed
+		}
+	}
+
+	if path := get("path"); len(path) > 2 && strings.HasPrefix(path, "/") && strings.HasSuffix(path, "/") {
+		re, err := regexp.Compile(path[1 : len(path)-1])
+		if err != nil {
+			return q, fmt.Errorf("invalid path pattern %q: %v", path, err)
+		}
+		q.PathRE = re
+	} else {
+		q.Path = path
+	}
+
+	switch order := get("order"); order {
+	case "", "desc":
+	case "asc":
+		q.Oldest = true
+	default:
+		return q, fmt.Errorf("invalid order %q, must be \"asc\" or \"desc\"", order)
+	}
+
+	for key, target := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if raw := get(key); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return q, fmt.Errorf("invalid %q value %q, must be a non-negative integer", key, raw)
+			}
+			*target = n
+		}
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultJournalLimit
+	case q.Limit > maxJournalLimit:
+		q.Limit = maxJournalLimit
+	}
+
+	return q, nil
+}
+
+// matches reports whether an entry passes the query filters
+func (q journalQuery) matches(entry journalEntry) bool {
+	switch {
+	case !q.Since.IsZero() && entry.Timestamp.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !entry.Timestamp.Before(q.Until):
+		return false
+	case q.Method != "" && entry.Method != q.Method:
+		return false
+	case q.Path != "" && entry.Path != q.Path:
+		return false
+	case q.PathRE != nil && !q.PathRE.MatchString(entry.Path):
+		return false
+	case q.Body != "" && !strings.Contains(entry.Body, q.Body):
+		return false
+	}
+	return true
+}
+
+// find returns one page of the entries matching the query, along with the
+// total number of matching entries
+func (j *journal) find(q journalQuery) ([]journalEntry, int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	page := make([]journalEntry, 0)
+	total := 0
+	for i := range j.entries {
+		entry := j.entries[len(j.entries)-1-i]
+		if q.Oldest {
+			entry = j.entries[i]
+		}
+
+		if !q.matches(entry) {
+			continue
+		}
+		if total >= q.Offset && len(page) < q.Limit {
+			page = append(page, entry)
+		}
+		total++
+	}
+	return page, total
+}
+
+// journalPage represents the JSON response of the journal endpoint
+type journalPage struct {
+	Total    int            `json:"total"`
+	Offset   int            `json:"offset"`
+	Limit    int            `json:"limit"`
+	Requests []journalEntry `json:"requests"`
+}
+
+// handleListRequests lists journaled requests, newest first. Query parameters
+// filter by time ("since", "until"), "method", "path" (exact or "/regex/") and
+// "body" content, and page through results with "limit", "offset" and "order".
+func (s *Server) handleListRequests(w http.ResponseWriter, r *http.Request) {
+	q, err := parseJournalQuery(r.URL.Query())
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, total := s.journal.find(q)
+	writeJSON(w, http.StatusOK, journalPage{Total: total, Offset: q.Offset, Limit: q.Limit, Requests: entries})
+}
+
+// handleCountRequests counts journaled requests, so tests can verify how many
+// times the mock was called. It accepts the same filters as
+// handleListRequests, while paging parameters are ignored.
+func (s *Server) handleCountRequests(w http.ResponseWriter, r *http.Request) {
+	q, err := parseJournalQuery(r.URL.Query())
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q.Offset, q.Limit = 0, 0
+	_, total := s.journal.find(q)
+	writeJSON(w, http.StatusOK, map[string]int{"count": total})
+}
+
+// handleDeleteRequests empties the journal
+func (s *Server) handleDeleteRequests(w http.ResponseWriter, _ *http.Request) {
+	count := s.journal.clear()
+
+	s.logger.Info("request journal cleared", "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"deleted": count})
+}
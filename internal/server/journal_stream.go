@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// journalStreamKeepAlive is how often an idle stream sends a comment, so
+// proxies and clients don't close the connection
+const journalStreamKeepAlive = 15 * time.Second
+
+// handleStreamRequests streams journal entries as Server-Sent Events as they
+// arrive. It accepts the "method", "path" and "body" filters of the journal
+// listing, and replays entries missed since the Last-Event-ID header, if set.
+func (s *Server) handleStreamRequests(w http.ResponseWriter, r *http.Request) {
+	q, err := parseJournalQuery(r.URL.Query())
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var lastID int64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		lastID, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid Last-Event-ID %q", raw))
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+
+	// Streams outlive the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		writeAdminError(w, http.StatusInternalServerError, "failed to start stream: "+err.Error())
+		return
+	}
+
+	backlog, entries, unsubscribe := s.journal.subscribe(lastID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	send := func(entry journalEntry) error {
+		if !q.matches(entry) {
+			return nil
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: request\ndata: %s\n\n", entry.ID, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for _, entry := range backlog {
+		if err := send(entry); err != nil {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(journalStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			if err := send(entry); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestJournal_Subscribe(t *testing.T) {
+	j := newTestJournal(t, config.JournalConfig{})
+	for _, path := range []string{"/a", "/b", "/c"} {
+		j.add(journalEntry{Method: "GET", Path: path})
+	}
+
+	// A new stream gets no backlog
+	backlog, _, unsubscribe := j.subscribe(0)
+	unsubscribe()
+	if len(backlog) != 0 {
+		t.Errorf("Expected no backlog, got %d entries", len(backlog))
+	}
+
+	// A resumed stream gets the entries it missed, oldest first
+	backlog, entries, unsubscribe := j.subscribe(1)
+	defer unsubscribe()
+	if len(backlog) != 2 || backlog[0].Path != "/b" || backlog[1].Path != "/c" {
+		t.Fatalf("Expected backlog /b, /c, got %+v", backlog)
+	}
+
+	j.add(journalEntry{Method: "GET", Path: "/d"})
+	select {
+	case entry := <-entries:
+		if entry.Path != "/d" || entry.ID != 4 {
+			t.Errorf("Expected entry 4 for /d, got %+v", entry)
+		}
+	default:
+		t.Fatal("Expected the new entry to be delivered")
+	}
+
+	// Entries beyond the buffer are dropped rather than blocking the journal
+	for range journalSubscriberBuffer + 5 {
+		j.add(journalEntry{Method: "GET", Path: "/flood"})
+	}
+	if got := j.metrics.Get(metricJournalStreamDropped); got != 5 {
+		t.Errorf("Expected 5 dropped stream entries, got %d", got)
+	}
+
+	unsubscribe()
+	j.add(journalEntry{Method: "GET", Path: "/after"})
+	if len(entries) != journalSubscriberBuffer {
+		t.Errorf("Expected no deliveries after unsubscribing, got %d buffered", len(entries))
+	}
+}
+
+func TestJournal_EndStreams(t *testing.T) {
+	j := newTestJournal(t, config.JournalConfig{})
+
+	_, entries, unsubscribe := j.subscribe(0)
+	defer unsubscribe()
+
+	j.endStreams()
+	if _, ok := <-entries; ok {
+		t.Error("Expected the stream to be closed")
+	}
+
+	// Streams opened during shutdown end right away
+	_, late, lateUnsubscribe := j.subscribe(0)
+	defer lateUnsubscribe()
+	if _, ok := <-late; ok {
+		t.Error("Expected the late stream to be closed")
+	}
+}
+
+// streamEvent is a parsed Server-Sent Event
+type streamEvent struct {
+	id, event, data string
+}
+
+// readStreamEvent reads the next event from a stream, skipping comments
+func readStreamEvent(t *testing.T, r *bufio.Reader) streamEvent {
+	t.Helper()
+
+	var ev streamEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && ev.event != "":
+			return ev
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestServer_Integration_RequestStream(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: `[]`},
+		{Path: "/users", Method: "POST", Template: `{"created":true}`},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	// Journal a request before streaming so it can be replayed later
+	resp, err := ts.makeRequest("GET", "/users", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	openStream := func(query string, headers map[string]string) (*bufio.Reader, func()) {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, "GET", ts.BaseURL+"/__admin/requests/stream"+query, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Expected text/event-stream, got %q", ct)
+		}
+
+		return bufio.NewReader(resp.Body), func() {
+			cancel()
+			resp.Body.Close()
+		}
+	}
+
+	t.Run("live entries", func(t *testing.T) {
+		stream, closeStream := openStream("?method=POST", nil)
+		defer closeStream()
+
+		// Wait until the stream is subscribed before sending requests
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			ts.Server.journal.mu.Lock()
+			subscribed := len(ts.Server.journal.subscribers) > 0
+			ts.Server.journal.mu.Unlock()
+			if subscribed {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Stream never subscribed to the journal")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		for _, method := range []string{"GET", "POST"} {
+			resp, err := ts.makeRequest(method, "/users", strings.NewReader(`{"name":"alice"}`), nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			readResponseBody(t, resp)
+		}
+
+		// The GET is filtered out, so the first event is the POST
+		ev := readStreamEvent(t, stream)
+		if ev.event != "request" || ev.id != "3" {
+			t.Errorf("Expected request event with id 3, got %+v", ev)
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal([]byte(ev.data), &entry); err != nil {
+			t.Fatalf("Failed to parse event data %q: %v", ev.data, err)
+		}
+		if entry.Method != "POST" || entry.Route != "POST /users" || entry.Body != `{"name":"alice"}` {
+			t.Errorf("Unexpected streamed entry: %+v", entry)
+		}
+	})
+
+	t.Run("resume from last event", func(t *testing.T) {
+		stream, closeStream := openStream("", map[string]string{"Last-Event-ID": "1"})
+		defer closeStream()
+
+		for _, want := range []string{"2", "3"} {
+			if ev := readStreamEvent(t, stream); ev.id != want {
+				t.Errorf("Expected replayed event %s, got %+v", want, ev)
+			}
+		}
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, tc := range []struct {
+			query   string
+			headers map[string]string
+		}{
+			{query: "?path=/[/"},
+			{headers: map[string]string{"Last-Event-ID": "abc"}},
+		} {
+			resp, err := ts.makeRequest("GET", "/__admin/requests/stream"+tc.query, nil, tc.headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d: %s", resp.StatusCode, body)
+			}
+		}
+	})
+}
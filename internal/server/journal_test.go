@@ -0,0 +1,393 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/metrics"
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+func TestCaptureRequestBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader("0123456789"))
+
+	captured, truncated := captureRequestBody(req, 4)
+	if string(captured) != "0123" || !truncated {
+		t.Errorf("Expected truncated capture %q, got %q (truncated: %v)", "0123", captured, truncated)
+	}
+
+	rest, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	if string(rest) != "0123456789" {
+		t.Errorf("Expected the full body to remain readable, got %q", rest)
+	}
+
+	captured, truncated = captureRequestBody(httptest.NewRequest("GET", "/", nil), 4)
+	if captured != nil || truncated {
+		t.Errorf("Expected nothing captured without a body, got %q", captured)
+	}
+}
+
+func TestParseJournalQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		check       func(t *testing.T, q journalQuery)
+		errContains string
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			check: func(t *testing.T, q journalQuery) {
+				if q.Limit != defaultJournalLimit || q.Offset != 0 || q.Oldest {
+					t.Errorf("Unexpected defaults: %+v", q)
+				}
+			},
+		},
+		{
+			name:  "filters",
+			query: "method=post&path=/api/users&body=alice&since=2025-01-01T00:00:00Z&order=asc&limit=5&offset=10",
+			check: func(t *testing.T, q journalQuery) {
+				if q.Method != "POST" || q.Path != "/api/users" || q.Body != "alice" || !q.Oldest || q.Limit != 5 || q.Offset != 10 {
+					t.Errorf("Unexpected query: %+v", q)
+				}
+				if !q.Since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unexpected since: %s", q.Since)
+				}
+			},
+		},
+		{
+			name:  "regex path",
+			query: "path=" + url.QueryEscape("/^/api/.*$/"),
+			check: func(t *testing.T, q journalQuery) {
+				if q.PathRE == nil || !q.PathRE.MatchString("/api/users") || q.Path != "" {
+					t.Errorf("Expected a path pattern, got %+v", q)
+				}
+			},
+		},
+		{
+			name:  "limit capped",
+			query: "limit=100000",
+			check: func(t *testing.T, q journalQuery) {
+				if q.Limit != maxJournalLimit {
+					t.Errorf("Expected limit %d, got %d", maxJournalLimit, q.Limit)
+				}
+			},
+		},
+		{name: "invalid timestamp", query: "until=yesterday", errContains: `invalid "until" timestamp`},
+		{name: "invalid limit", query: "limit=-1", errContains: `invalid "limit" value`},
+		{name: "invalid order", query: "order=random", errContains: "invalid order"},
+		{name: "invalid path pattern", query: "path=" + url.QueryEscape("/[/"), errContains: "invalid path pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			q, err := parseJournalQuery(values)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			tt.check(t, q)
+		})
+	}
+}
+
+// newMemoryCollection returns a collection of the default memory storage driver
+func newMemoryCollection(t *testing.T) storage.Collection {
+	t.Helper()
+
+	driver, err := storage.Open(storage.MemoryDriver, "")
+	if err != nil {
+		t.Fatalf("Failed to open memory storage: %v", err)
+	}
+	collection, err := driver.Open("test")
+	if err != nil {
+		t.Fatalf("Failed to open memory collection: %v", err)
+	}
+	return collection
+}
+
+// newTestJournal creates a journal with the given bounds and its own metrics
+func newTestJournal(t *testing.T, cfg config.JournalConfig) *journal {
+	t.Helper()
+
+	j, err := newJournal(cfg, newMemoryCollection(t), slog.New(slog.DiscardHandler), metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create journal: %v", err)
+	}
+	t.Cleanup(func() { _ = j.close() })
+	return j
+}
+
+func TestJournal_Find(t *testing.T) {
+	j := newTestJournal(t, config.JournalConfig{})
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, path := range []string{"/a", "/b", "/a", "/c", "/a"} {
+		j.add(journalEntry{Timestamp: base.Add(time.Duration(i) * time.Minute), Method: "GET", Path: path, Body: "n" + string(rune('0'+i))})
+	}
+
+	ids := func(entries []journalEntry) []int64 {
+		var out []int64
+		for _, e := range entries {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		query     journalQuery
+		wantIDs   []int64
+		wantTotal int
+	}{
+		{name: "newest first", query: journalQuery{Limit: 10}, wantIDs: []int64{5, 4, 3, 2, 1}, wantTotal: 5},
+		{name: "oldest first", query: journalQuery{Limit: 2, Oldest: true}, wantIDs: []int64{1, 2}, wantTotal: 5},
+		{name: "path with paging", query: journalQuery{Path: "/a", Limit: 1, Offset: 1}, wantIDs: []int64{3}, wantTotal: 3},
+		{name: "time window", query: journalQuery{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute), Limit: 10}, wantIDs: []int64{3, 2}, wantTotal: 2},
+		{name: "body search", query: journalQuery{Body: "n3", Limit: 10}, wantIDs: []int64{4}, wantTotal: 1},
+		{name: "no matches", query: journalQuery{Method: "POST", Limit: 10}, wantIDs: nil, wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total := j.find(tt.query)
+			got := ids(entries)
+			if total != tt.wantTotal || len(got) != len(tt.wantIDs) {
+				t.Fatalf("Expected ids %v (total %d), got %v (total %d)", tt.wantIDs, tt.wantTotal, got, total)
+			}
+			for i := range got {
+				if got[i] != tt.wantIDs[i] {
+					t.Fatalf("Expected ids %v, got %v", tt.wantIDs, got)
+				}
+			}
+		})
+	}
+}
+
+func TestJournal_DiscardsOldest(t *testing.T) {
+	j := newTestJournal(t, config.JournalConfig{MaxEntries: 10})
+	for range 15 {
+		j.add(journalEntry{Method: "GET", Path: "/"})
+	}
+
+	entries, total := j.find(journalQuery{Limit: 1, Oldest: true})
+	if total != 10 {
+		t.Errorf("Expected 10 entries, got %d", total)
+	}
+	if entries[0].ID != 6 {
+		t.Errorf("Expected the oldest entries to be discarded, first ID is %d", entries[0].ID)
+	}
+
+	if dropped := j.metrics.Get(metricJournalDropped); dropped != 5 {
+		t.Errorf("Expected 5 dropped entries, got %d", dropped)
+	}
+	if recorded := j.metrics.Get(metricJournalRecorded); recorded != 15 {
+		t.Errorf("Expected 15 recorded entries, got %d", recorded)
+	}
+
+	// Lowering the bound drops the oldest entries right away
+	if err := j.configure(config.JournalConfig{MaxEntries: 4}); err != nil {
+		t.Fatalf("Failed to configure journal: %v", err)
+	}
+	if _, total := j.find(journalQuery{Limit: 10}); total != 4 {
+		t.Errorf("Expected 4 entries after lowering the bound, got %d", total)
+	}
+	if dropped := j.metrics.Get(metricJournalDropped); dropped != 11 {
+		t.Errorf("Expected 11 dropped entries, got %d", dropped)
+	}
+	if entries := j.metrics.Get(metricJournalEntries); entries != 4 {
+		t.Errorf("Expected the entries gauge to be 4, got %d", entries)
+	}
+}
+
+func TestJournal_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	cfg := config.JournalConfig{MaxEntries: 3, Persist: true, Path: path}
+
+	j := newTestJournal(t, cfg)
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		j.add(journalEntry{Method: "GET", Path: p})
+	}
+	if err := j.close(); err != nil {
+		t.Fatalf("Failed to close journal: %v", err)
+	}
+
+	// A new journal picks up where the previous one left off
+	restored := newTestJournal(t, cfg)
+	entries, total := restored.find(journalQuery{Limit: 10, Oldest: true})
+	if total != 3 || entries[0].Path != "/b" || entries[2].Path != "/d" {
+		t.Fatalf("Expected the 3 most recent entries to be restored, got %+v", entries)
+	}
+
+	restored.add(journalEntry{Method: "GET", Path: "/e"})
+	entries, _ = restored.find(journalQuery{Limit: 1})
+	if entries[0].ID != 5 {
+		t.Errorf("Expected IDs to continue after restoring, got %d", entries[0].ID)
+	}
+
+	// Clearing the journal also clears the file
+	restored.clear()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read journal file: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected an empty journal file after clearing, got %q", data)
+	}
+}
+
+func TestServer_Integration_RequestJournal(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "POST", Template: `{"created":true}`},
+		{Path: "/users", Method: "GET", Template: `[]`},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{"POST", "/users", `{"name":"alice"}`},
+		{"POST", "/users", `{"name":"bob"}`},
+		{"GET", "/users?page=2", ""},
+		{"GET", "/missing", ""},
+	}
+	for _, r := range requests {
+		resp, err := ts.makeRequest(r.method, r.path, strings.NewReader(r.body), map[string]string{"Content-Type": "application/json"})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+	}
+
+	// Admin and health check requests are not journaled
+	if resp, err := ts.makeRequest("GET", "/health", nil, nil); err == nil {
+		readResponseBody(t, resp)
+	}
+
+	list := func(query string) journalPage {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/__admin/requests"+query, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body := readResponseBody(t, resp)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, body)
+		}
+
+		var page journalPage
+		if err := json.Unmarshal([]byte(body), &page); err != nil {
+			t.Fatalf("Failed to parse journal %q: %v", body, err)
+		}
+		return page
+	}
+
+	page := list("")
+	if page.Total != 4 {
+		t.Fatalf("Expected 4 journaled requests, got %d", page.Total)
+	}
+	newest := page.Requests[0]
+	if newest.Path != "/missing" || newest.Status != http.StatusNotFound || newest.Route != "" {
+		t.Errorf("Unexpected newest entry: %+v", newest)
+	}
+	if get := page.Requests[1]; get.Query != "page=2" || get.Route != "GET /users" || get.Status != http.StatusOK {
+		t.Errorf("Unexpected GET entry: %+v", get)
+	}
+
+	page = list("?method=post&body=bob")
+	if page.Total != 1 || page.Requests[0].Body != `{"name":"bob"}` {
+		t.Errorf("Expected the request mentioning bob, got %+v", page)
+	}
+
+	page = list("?path=/users&order=asc&limit=1&offset=1")
+	if page.Total != 3 || len(page.Requests) != 1 || page.Requests[0].Body != `{"name":"bob"}` {
+		t.Errorf("Expected the second request to /users, got %+v", page)
+	}
+
+	count := func(query string) int {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/__admin/requests/count"+query, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body := readResponseBody(t, resp)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, body)
+		}
+
+		var counted struct {
+			Count int `json:"count"`
+		}
+		if err := json.Unmarshal([]byte(body), &counted); err != nil {
+			t.Fatalf("Failed to parse count %q: %v", body, err)
+		}
+		return counted.Count
+	}
+
+	// Counting ignores paging, so verifying calls doesn't depend on the page size
+	for query, expected := range map[string]int{
+		"":                              4,
+		"?path=/users":                  3,
+		"?path=/users&method=GET":       1,
+		"?path=/users&limit=1&offset=2": 3,
+		"?path=/^/(users|missing)$/":    4,
+		"?path=/orders":                 0,
+	} {
+		if got := count(query); got != expected {
+			t.Errorf("Expected a count of %d for %q, got %d", expected, query, got)
+		}
+	}
+
+	resp, err := ts.makeRequest("GET", "/__admin/requests?limit=lots", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid limit, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/__admin/requests", nil)
+	resp, err = ts.Client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if page := list(""); page.Total != 0 {
+		t.Errorf("Expected an empty journal after clearing it, got %d entries", page.Total)
+	}
+
+	// Journal activity is reported through the metrics endpoint
+	resp, err = ts.makeRequest("GET", "/__admin/metrics", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var reported struct {
+		Metrics map[string]int64 `json:"metrics"`
+	}
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &reported); err != nil {
+		t.Fatalf("Failed to parse metrics: %v", err)
+	}
+	if reported.Metrics[metricJournalRecorded] != 4 || reported.Metrics[metricJournalEntries] != 0 {
+		t.Errorf("Unexpected journal metrics: %v", reported.Metrics)
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// logLevelSwitch changes the level of the server's logger at runtime, so
+// verbose logs can be turned on during an incident without a restart
+type logLevelSwitch struct {
+	mu       sync.Mutex
+	level    *slog.LevelVar // Level of the server's logger, nil when it can't be changed
+	revert   *time.Timer    // Restores the previous level after a temporary change
+	revertAt time.Time
+}
+
+// logLevelStatus describes the current log level
+type logLevelStatus struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"` // When a temporary change ends
+}
+
+// set changes the level. When d is positive, the previous level is restored
+// after d. Pending restores are dropped either way.
+func (l *logLevelSwitch) set(level slog.Level, d time.Duration) logLevelStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopRevert()
+	previous := l.level.Level()
+	l.level.Set(level)
+
+	if d > 0 {
+		var revert *time.Timer
+		revert = time.AfterFunc(d, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			// Skip restores replaced by a later change after they fired
+			if l.revert != revert {
+				return
+			}
+			l.level.Set(previous)
+			l.revert = nil
+		})
+		l.revert = revert
+		l.revertAt = time.Now().Add(d)
+	}
+
+	return l.statusLocked()
+}
+
+// toggle switches between debug and info, returning the new level
+func (l *logLevelSwitch) toggle() slog.Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopRevert()
+	level := slog.LevelDebug
+	if l.level.Level() <= slog.LevelDebug {
+		level = slog.LevelInfo
+	}
+	l.level.Set(level)
+	return level
+}
+
+// status returns the current level
+func (l *logLevelSwitch) status() logLevelStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.statusLocked()
+}
+
+// statusLocked returns the current level, with l.mu held
+func (l *logLevelSwitch) statusLocked() logLevelStatus {
+	status := logLevelStatus{Level: strings.ToLower(l.level.Level().String())}
+	if l.revert != nil {
+		revertAt := l.revertAt
+		status.RevertAt = &revertAt
+	}
+	return status
+}
+
+// stopRevert drops the pending restore of the previous level, with l.mu held
+func (l *logLevelSwitch) stopRevert() {
+	if l.revert != nil {
+		l.revert.Stop()
+		l.revert = nil
+	}
+}
+
+// SetLogLevelVar hands the server the level of its logger, so it can be
+// changed at runtime through ToggleDebug and the admin API
+func (s *Server) SetLogLevelVar(level *slog.LevelVar) {
+	s.logLevel.mu.Lock()
+	defer s.logLevel.mu.Unlock()
+
+	s.logLevel.stopRevert()
+	s.logLevel.level = level
+}
+
+// ToggleDebug switches logging between the debug and info levels, returning
+// the new level. It does nothing unless SetLogLevelVar was called.
+func (s *Server) ToggleDebug() slog.Level {
+	if !s.hasLogLevel() {
+		return slog.LevelInfo
+	}
+
+	level := s.logLevel.toggle()
+	s.logger.Info("log level changed", "level", level)
+	return level
+}
+
+// hasLogLevel reports whether the server's log level can be changed
+func (s *Server) hasLogLevel() bool {
+	s.logLevel.mu.Lock()
+	defer s.logLevel.mu.Unlock()
+	return s.logLevel.level != nil
+}
+
+// logLevelRequest is the body accepted when changing the log level
+type logLevelRequest struct {
+	Level    string        `yaml:"level"`
+	Duration time.Duration `yaml:"duration"` // Restores the previous level after this long, when set
+}
+
+// handleGetLogLevel reports the current log level
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, _ *http.Request) {
+	if !s.hasLogLevel() {
+		writeAdminError(w, http.StatusNotImplemented, "the log level can't be changed at runtime")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.logLevel.status())
+}
+
+// handleSetLogLevel changes the log level, optionally for a limited time
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if !s.hasLogLevel() {
+		writeAdminError(w, http.StatusNotImplemented, "the log level can't be changed at runtime")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	var req logLevelRequest
+	if err := yaml.Unmarshal(body, &req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse log level: %v", err))
+		return
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil || req.Level == "" {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid level %q, must be one of: debug, info, warn, error", req.Level))
+		return
+	}
+	if req.Duration < 0 {
+		writeAdminError(w, http.StatusBadRequest, "duration cannot be negative")
+		return
+	}
+
+	status := s.logLevel.set(level, req.Duration)
+	s.logger.Info("log level changed", "level", level, "duration", req.Duration)
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_ToggleDebug(t *testing.T) {
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}}))
+	defer ts.Close()
+
+	if level := ts.ToggleDebug(); level != slog.LevelInfo {
+		t.Errorf("Expected no change without a level, got %v", level)
+	}
+
+	var level slog.LevelVar
+	ts.SetLogLevelVar(&level)
+
+	if got := ts.ToggleDebug(); got != slog.LevelDebug || level.Level() != slog.LevelDebug {
+		t.Errorf("Expected debug after the first toggle, got %v", level.Level())
+	}
+	if got := ts.ToggleDebug(); got != slog.LevelInfo || level.Level() != slog.LevelInfo {
+		t.Errorf("Expected info after the second toggle, got %v", level.Level())
+	}
+}
+
+func TestServer_Integration_AdminLogLevel(t *testing.T) {
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}}))
+	defer ts.Close()
+
+	setLevel := func(body string) (int, logLevelStatus) {
+		t.Helper()
+		resp, err := ts.makeRequest("PUT", "/__admin/log-level", strings.NewReader(body), nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		raw := readResponseBody(t, resp)
+
+		var status logLevelStatus
+		if resp.StatusCode == 200 {
+			if err := json.Unmarshal([]byte(raw), &status); err != nil {
+				t.Fatalf("Failed to decode %q: %v", raw, err)
+			}
+		}
+		return resp.StatusCode, status
+	}
+
+	if code, _ := setLevel(`{"level": "debug"}`); code != 501 {
+		t.Errorf("Expected status 501 without a level, got %d", code)
+	}
+
+	var level slog.LevelVar
+	ts.SetLogLevelVar(&level)
+
+	code, status := setLevel(`{"level": "debug"}`)
+	if code != 200 || status.Level != "debug" || status.RevertAt != nil || level.Level() != slog.LevelDebug {
+		t.Errorf("Expected a permanent switch to debug, got %d %+v", code, status)
+	}
+
+	resp, err := ts.makeRequest("GET", "/__admin/log-level", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); !strings.Contains(body, `"level": "debug"`) {
+		t.Errorf("Expected the debug level to be reported, got %s", body)
+	}
+
+	code, status = setLevel(`{"level": "info"}`)
+	if code != 200 || level.Level() != slog.LevelInfo {
+		t.Fatalf("Expected a switch to info, got %d %+v", code, status)
+	}
+
+	// Temporary changes go back to the previous level
+	code, status = setLevel(`{"level": "debug", "duration": "50ms"}`)
+	if code != 200 || status.RevertAt == nil || level.Level() != slog.LevelDebug {
+		t.Fatalf("Expected a temporary switch to debug, got %d %+v", code, status)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for level.Level() != slog.LevelInfo && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if level.Level() != slog.LevelInfo {
+		t.Errorf("Expected the level to go back to info, got %v", level.Level())
+	}
+
+	for _, body := range []string{`{"level": "verbose"}`, `{}`, `{"level": "debug", "duration": "-1s"}`} {
+		if code, _ := setLevel(body); code != 400 {
+			t.Errorf("Expected status 400 for %s, got %d", body, code)
+		}
+	}
+}
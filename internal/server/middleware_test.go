@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_RouteMiddleware(t *testing.T) {
+	cfg, err := config.ParseConfig("middleware.yaml", []byte(`
+routes:
+  - path: /public
+    method: GET
+    template: public
+groups:
+  - prefix: /admin
+    middleware:
+      - type: basicauth
+        config: {username: admin, password: secret}
+    routes:
+      - path: /stats
+        method: GET
+        template: stats
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	resp, err := ts.makeRequest("GET", "/public", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); resp.StatusCode != http.StatusOK || body != "public" {
+		t.Errorf("Expected routes outside the group to be left alone, got %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = ts.makeRequest("GET", "/admin/stats", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the group's basic auth to reject the request, got %d", resp.StatusCode)
+	}
+
+	req, err := http.NewRequest("GET", ts.BaseURL+"/admin/stats", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.SetBasicAuth("admin", "secret")
+	resp, err = ts.Client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); resp.StatusCode != http.StatusOK || body != "stats" {
+		t.Errorf("Expected authenticated requests to reach the route, got %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,480 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// openAPIPath is the built-in endpoint serving the OpenAPI document of the
+// configured routes
+const openAPIPath = "/openapi.json"
+
+// exampleRenderTimeout bounds how long rendering one example body may take,
+// so templates that sleep don't stall the document
+const exampleRenderTimeout = 500 * time.Millisecond
+
+// openAPIDocument is the subset of an OpenAPI 3.0 document mockingjay generates
+type openAPIDocument struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    openAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*openAPIOperation `json:"paths"`
+}
+
+// openAPIInfo describes the API in an OpenAPI document
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// openAPIOperation describes a single method on a path
+type openAPIOperation struct {
+	Summary    string                      `json:"summary,omitempty"`
+	Parameters []openAPIParameter          `json:"parameters,omitempty"`
+	Responses  map[string]*openAPIResponse `json:"responses"`
+	Deprecated bool                        `json:"deprecated,omitempty"`
+}
+
+// openAPIParameter describes a path or header parameter of an operation
+type openAPIParameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Required    bool          `json:"required"`
+	Description string        `json:"description,omitempty"`
+	Schema      openAPISchema `json:"schema"`
+}
+
+// openAPISchema is the schema of a parameter or header
+type openAPISchema struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// openAPIResponse describes one response of an operation
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+// openAPIHeader describes a response header
+type openAPIHeader struct {
+	Schema  openAPISchema `json:"schema"`
+	Example string        `json:"example,omitempty"`
+}
+
+// openAPIMediaType holds the example body of a response
+type openAPIMediaType struct {
+	Example any `json:"example,omitempty"`
+}
+
+// handleOpenAPI serves the OpenAPI document of the configured routes
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPIDocument(s.routingFor(r)))
+}
+
+// openAPIDocument builds the OpenAPI document of the routes of rt. Routes
+// whose path is a regex that can't be expressed as an OpenAPI path template
+// are left out.
+func (s *Server) openAPIDocument(rt *routing) *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "mockingjay",
+			Description: "Routes served by this mock server",
+			Version:     s.appVersion,
+		},
+		Paths: make(map[string]map[string]*openAPIOperation),
+	}
+
+	for _, route := range rt.routes {
+		path, params, ok := openAPIPathTemplate(route)
+		if !ok {
+			continue
+		}
+
+		operations := doc.Paths[path]
+		if operations == nil {
+			operations = make(map[string]*openAPIOperation)
+			doc.Paths[path] = operations
+		}
+
+		// Several routes can serve the same method and path, for example
+		// with different header matches: merge their responses
+		method := strings.ToLower(route.Method)
+		operation := operations[method]
+		if operation == nil {
+			operation = &openAPIOperation{
+				Summary:    route.Method + " " + route.Pattern,
+				Parameters: openAPIParameters(route, params),
+				Responses:  make(map[string]*openAPIResponse),
+			}
+			operations[method] = operation
+		}
+		if route.Deprecation != nil {
+			operation.Deprecated = true
+		}
+
+		for status, response := range s.openAPIResponses(rt, route, path, params) {
+			if _, exists := operation.Responses[status]; !exists {
+				operation.Responses[status] = response
+			}
+		}
+	}
+
+	return doc
+}
+
+// openAPIPathTemplate converts a route pattern into an OpenAPI path template,
+// turning named capture groups into "{name}" parameters. It returns the names
+// of those parameters, and false if the pattern uses regex features beyond
+// literal text and named groups.
+func openAPIPathTemplate(route *router.Route) (string, []string, bool) {
+	if !route.IsRegexp {
+		return route.Pattern, nil, true
+	}
+
+	re, err := syntax.Parse(route.Regex.String(), syntax.Perl)
+	if err != nil {
+		return "", nil, false
+	}
+
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+	}
+
+	var path strings.Builder
+	var params []string
+	for _, part := range parts {
+		switch {
+		case part.Op == syntax.OpBeginText || part.Op == syntax.OpBeginLine ||
+			part.Op == syntax.OpEndText || part.Op == syntax.OpEndLine:
+			// Anchors don't contribute to the path
+		case part.Op == syntax.OpLiteral && part.Flags&syntax.FoldCase == 0:
+			path.WriteString(string(part.Rune))
+		case part.Op == syntax.OpCapture && part.Name != "":
+			path.WriteString("{" + part.Name + "}")
+			params = append(params, part.Name)
+		default:
+			return "", nil, false
+		}
+	}
+
+	if !strings.HasPrefix(path.String(), "/") {
+		return "", nil, false
+	}
+	return path.String(), params, true
+}
+
+// openAPIParameters lists the path parameters, required headers and required
+// cookies of a route
+func openAPIParameters(route *router.Route, pathParams []string) []openAPIParameter {
+	var params []openAPIParameter
+
+	for _, name := range pathParams {
+		param := openAPIParameter{Name: name, In: "path", Required: true, Schema: openAPISchema{Type: "string"}}
+		if pattern := captureGroupPattern(route, name); pattern != "" {
+			param.Schema.Pattern = "^" + pattern + "$"
+		}
+		params = append(params, param)
+	}
+
+	headers := make([]string, 0, len(route.MatchHeaders))
+	for name := range route.MatchHeaders {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+
+	for _, name := range headers {
+		matcher := route.MatchHeaders[name]
+		param := openAPIParameter{Name: http.CanonicalHeaderKey(name), In: "header", Required: true, Schema: openAPISchema{Type: "string"}}
+		if matcher.IsRegex {
+			param.Schema.Pattern = matcher.Regex.String()
+		} else {
+			param.Description = fmt.Sprintf("Must be %q", matcher.Literal)
+		}
+		params = append(params, param)
+	}
+
+	cookies := make([]string, 0, len(route.MatchCookies))
+	for name := range route.MatchCookies {
+		cookies = append(cookies, name)
+	}
+	sort.Strings(cookies)
+
+	for _, name := range cookies {
+		param := openAPIParameter{Name: name, In: "cookie", Required: true, Schema: openAPISchema{Type: "string"}}
+		switch matcher := route.MatchCookies[name]; {
+		case matcher == nil:
+		case matcher.IsRegex:
+			param.Schema.Pattern = matcher.Regex.String()
+		default:
+			param.Description = fmt.Sprintf("Must be %q", matcher.Literal)
+		}
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// captureGroupPattern returns the regex of a route's named capture group
+func captureGroupPattern(route *router.Route, name string) string {
+	re, err := syntax.Parse(route.Regex.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+
+	for _, part := range re.Sub {
+		if part.Op == syntax.OpCapture && part.Name == name && len(part.Sub) == 1 {
+			return part.Sub[0].String()
+		}
+	}
+	return ""
+}
+
+// openAPIResponses describes the responses of a route, keyed by status code,
+// with examples rendered from the route's templates
+func (s *Server) openAPIResponses(rt *routing, route *router.Route, path string, params []string) map[string]*openAPIResponse {
+	responses := make(map[string]*openAPIResponse)
+
+	if route.Proxy != nil {
+		responses["default"] = &openAPIResponse{Description: "Proxied to " + route.Proxy.Target.String()}
+		return responses
+	}
+
+	if route.Batch != nil {
+		responses["200"] = &openAPIResponse{Description: "Responses of the batched sub-requests"}
+		return responses
+	}
+
+	if route.WebSocket != nil {
+		responses["101"] = &openAPIResponse{Description: "Switches to a WebSocket connection"}
+		return responses
+	}
+
+	if echo := route.EchoBody; echo != nil {
+		status := echo.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		responses[strconv.Itoa(status)] = &openAPIResponse{Description: "The request body, echoed back"}
+		return responses
+	}
+
+	if bodyFile := route.BodyFile; bodyFile != nil {
+		mediaType, _, _ := strings.Cut(bodyFile.ContentType, ";")
+		responses["200"] = &openAPIResponse{
+			Description: "The contents of " + filepath.Base(bodyFile.Path),
+			Content:     map[string]openAPIMediaType{mediaType: {}},
+		}
+		return responses
+	}
+
+	if static := route.StaticDir; static != nil {
+		responses["200"] = &openAPIResponse{Description: "A file of " + static.Root}
+		responses["404"] = &openAPIResponse{Description: "The file doesn't exist"}
+		return responses
+	}
+
+	add := func(status int, tmpl *template.Template, headers ...map[string]*template.Template) {
+		key := strconv.Itoa(status)
+		if _, exists := responses[key]; exists {
+			return
+		}
+		responses[key] = s.openAPIResponse(rt, route, status, path, params, tmpl, headers...)
+	}
+
+	if variants := route.Variants; variants != nil {
+		names := make([]string, 0, len(variants.Cases))
+		for name := range variants.Cases {
+			if name != variants.Default {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		// Document the default variant first, so its example wins when
+		// variants share a status
+		for _, name := range append([]string{variants.Default}, names...) {
+			resp := variants.Cases[name]
+			status := resp.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			add(status, resp.Tmpl, route.ResponseHeaders, resp.ResponseHeaders)
+		}
+		return responses
+	}
+
+	if len(route.Responses) == 0 {
+		add(http.StatusOK, route.Tmpl, route.ResponseHeaders)
+		return responses
+	}
+
+	for _, resp := range route.Responses {
+		status := resp.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		add(status, resp.Tmpl, route.ResponseHeaders, resp.ResponseHeaders)
+	}
+	return responses
+}
+
+// openAPIResponse renders one example response of a route
+func (s *Server) openAPIResponse(rt *routing, route *router.Route, status int, path string, params []string, tmpl *template.Template, headerSets ...map[string]*template.Template) *openAPIResponse {
+	response := &openAPIResponse{Description: http.StatusText(status)}
+
+	ctx := s.exampleContext(rt, route, path, params)
+	contentType := ""
+
+	// Headers every route sends come first, so the route's own override them
+	headerSets = append([]map[string]*template.Template{rt.responseHeaders}, headerSets...)
+	for _, headers := range headerSets {
+		for name, headerTmpl := range headers {
+			value, ok := s.renderExample(rt, headerTmpl, ctx)
+			if !ok {
+				value = ""
+			}
+
+			// Headers rendering empty are left out of responses
+			if ok && value == "" {
+				delete(response.Headers, http.CanonicalHeaderKey(name))
+				continue
+			}
+
+			if strings.EqualFold(name, "Content-Type") {
+				contentType = value
+				continue
+			}
+
+			if response.Headers == nil {
+				response.Headers = make(map[string]openAPIHeader)
+			}
+			response.Headers[http.CanonicalHeaderKey(name)] = openAPIHeader{Schema: openAPISchema{Type: "string"}, Example: value}
+		}
+	}
+
+	// Decoded bodies are binary, and have no example
+	if route.BodyEncoding != "" {
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		response.Content = map[string]openAPIMediaType{contentType: {}}
+		return response
+	}
+
+	body, ok := s.renderExample(rt, tmpl, ctx)
+	if !ok || body == "" {
+		return response
+	}
+
+	var example any = body
+	var parsed any
+	if err := json.Unmarshal([]byte(body), &parsed); err == nil {
+		example = parsed
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	response.Content = map[string]openAPIMediaType{contentType: {Example: example}}
+	return response
+}
+
+// exampleContext builds the template context of a synthetic request to a
+// route, used to render example responses without side effects
+func (s *Server) exampleContext(rt *routing, route *router.Route, path string, params []string) *templatepkg.TemplateContext {
+	values := make(map[string]string, len(params))
+	for _, name := range params {
+		values[name] = name
+		path = strings.ReplaceAll(path, "{"+name+"}", name)
+	}
+
+	req := httptest.NewRequest(route.Method, path, nil)
+	for name, matcher := range route.MatchHeaders {
+		if !matcher.IsRegex {
+			req.Header.Set(name, matcher.Literal)
+		}
+	}
+	for name, matcher := range route.MatchCookies {
+		if matcher != nil && !matcher.IsRegex {
+			req.AddCookie(&http.Cookie{Name: name, Value: matcher.Literal})
+		}
+	}
+
+	ctx, err := rt.engine.BuildTemplateContext(req, values)
+	if err != nil {
+		return &templatepkg.TemplateContext{Request: req, Params: values, Response: templatepkg.NewResponse()}
+	}
+	ctx.Route = route.Info
+	ctx.Tokens = exampleTokens{store: s.tokens}
+	ctx.Clock = rt.clockFor(route)
+	return ctx
+}
+
+// renderExample renders a template for an example, giving up if it fails or
+// takes too long
+func (s *Server) renderExample(rt *routing, tmpl *template.Template, ctx *templatepkg.TemplateContext) (string, bool) {
+	if tmpl == nil {
+		return "", false
+	}
+
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- result{err: fmt.Errorf("template panicked: %v", recovered)}
+			}
+		}()
+
+		var buf bytes.Buffer
+		err := rt.engine.ExecuteTemplate(tmpl, &buf, ctx)
+		done <- result{out: buf.String(), err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.out, res.err == nil
+	case <-time.After(exampleRenderTimeout):
+		return "", false
+	}
+}
+
+// exampleTokens stands in for the token store when rendering examples, so
+// documenting a route doesn't mint real tokens
+type exampleTokens struct {
+	store *tokenStore
+}
+
+// Mint returns a placeholder token
+func (t exampleTokens) Mint(name string) (string, error) {
+	return "example-" + name + "-token", nil
+}
+
+// ExpiresIn returns the real lifetime of the named kind of token
+func (t exampleTokens) ExpiresIn(name string) (int, error) {
+	return t.store.ExpiresIn(name)
+}
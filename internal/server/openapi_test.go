@@ -0,0 +1,196 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+func TestOpenAPIPathTemplate(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		wantPath   string
+		wantParams []string
+		wantOK     bool
+	}{
+		{pattern: "/api/users", wantPath: "/api/users", wantOK: true},
+		{pattern: "/^/api/users/(?P<id>\\d+)$/", wantPath: "/api/users/{id}", wantParams: []string{"id"}, wantOK: true},
+		{pattern: "/^/orgs/(?P<org>[^/]+)/repos/(?P<repo>[^/]+)$/", wantPath: "/orgs/{org}/repos/{repo}", wantParams: []string{"org", "repo"}, wantOK: true},
+		{pattern: "/^/files/v1\\.0/readme$/", wantPath: "/files/v1.0/readme", wantOK: true},
+		{pattern: "/^/api/.*$/", wantOK: false},
+		{pattern: "/^/api/(\\d+)$/", wantOK: false},
+		{pattern: "/^/(?i)api$/", wantOK: false},
+	}
+
+	compiler := router.NewCompiler()
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			route, err := compiler.CompileRoute(config.RouteConfig{Path: tt.pattern, Method: "GET", Template: "ok"})
+			if err != nil {
+				t.Fatalf("Failed to compile route: %v", err)
+			}
+
+			path, params, ok := openAPIPathTemplate(route)
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok %v, got %v (path %q)", tt.wantOK, ok, path)
+			}
+			if !ok {
+				return
+			}
+			if path != tt.wantPath {
+				t.Errorf("Expected path %q, got %q", tt.wantPath, path)
+			}
+			if len(params) != len(tt.wantParams) {
+				t.Fatalf("Expected params %v, got %v", tt.wantParams, params)
+			}
+			for i := range params {
+				if params[i] != tt.wantParams[i] {
+					t.Errorf("Expected params %v, got %v", tt.wantParams, params)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_Integration_OpenAPI(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/^/api/users/(?P<id>\\d+)$/",
+			Method:          "GET",
+			MatchHeaders:    map[string]string{"X-Tenant": "acme"},
+			MatchCookies:    map[string]string{"region": "eu"},
+			ResponseHeaders: map[string]string{"Content-Type": "application/json", "X-User": "{{ .Params.id }}"},
+			Template:        `{"id": "{{ .Params.id }}", "tenant": "{{ .Headers.Get "X-Tenant" }}", "region": "{{ .Cookies.region }}"}`,
+		},
+		{
+			Path:   "/api/jobs",
+			Method: "POST",
+			Responses: []config.ResponseConfig{
+				{Status: http.StatusAccepted, Template: "queued"},
+				{Status: http.StatusServiceUnavailable, Template: "busy"},
+			},
+		},
+		{
+			Path:     "/oauth/token",
+			Method:   "POST",
+			Template: `{"access_token": "{{ .Tokens.Mint "access" }}", "expires_in": {{ .Tokens.ExpiresIn "access" }}}`,
+		},
+		{
+			Path:       "/slow",
+			Method:     "GET",
+			Template:   `{{ sleep "2s" }}late`,
+			Deprecated: true,
+		},
+		{
+			Path:      "/ws",
+			Method:    "GET",
+			Template:  "{{ .Body }}",
+			WebSocket: &config.WebSocketConfig{},
+		},
+		{
+			Path:   "/checkout",
+			Method: "GET",
+			Variants: &config.VariantsConfig{
+				Selector: `{{ .Headers.Get "X-Experiment" }}`,
+				Cases: map[string]config.ResponseConfig{
+					"a":       {Template: "variant a"},
+					"b":       {Status: http.StatusCreated, Template: "variant b"},
+					"control": {Template: "control"},
+				},
+				Default: "control",
+			},
+		},
+		{
+			Path:     "/^/anything/.*$/",
+			Method:   "GET",
+			Template: "not documented",
+		},
+	})
+	cfg.TokenBucket = map[string]config.TokenConfig{"access": {TTL: time.Minute}}
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("GET", "/openapi.json", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var doc openAPIDocument
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("Failed to parse OpenAPI document: %v", err)
+	}
+
+	if doc.OpenAPI != "3.0.3" || doc.Info.Version != "test-version" {
+		t.Errorf("Unexpected document header: %+v %+v", doc.OpenAPI, doc.Info)
+	}
+	if len(doc.Paths) != 6 {
+		t.Errorf("Expected 6 documented paths, got %d", len(doc.Paths))
+	}
+
+	// Path parameters, required headers and rendered examples
+	user := doc.Paths["/api/users/{id}"]["get"]
+	if user == nil {
+		t.Fatalf("Expected GET /api/users/{id} to be documented, got %v", doc.Paths)
+	}
+	if len(user.Parameters) != 3 || user.Parameters[0].In != "path" || user.Parameters[0].Schema.Pattern == "" || user.Parameters[1].Name != "X-Tenant" || user.Parameters[2].In != "cookie" {
+		t.Errorf("Unexpected parameters: %+v", user.Parameters)
+	}
+	ok := user.Responses["200"]
+	if ok == nil || ok.Headers["X-User"].Example != "id" {
+		t.Fatalf("Expected a 200 response with an X-User header example, got %+v", ok)
+	}
+	example, _ := ok.Content["application/json"].Example.(map[string]any)
+	if example["id"] != "id" || example["tenant"] != "acme" || example["region"] != "eu" {
+		t.Errorf("Unexpected example body: %+v", ok.Content)
+	}
+
+	// Each alternative response is documented by status
+	jobs := doc.Paths["/api/jobs"]["post"]
+	if jobs == nil || jobs.Responses["202"] == nil || jobs.Responses["503"] == nil {
+		t.Fatalf("Expected 202 and 503 responses for POST /api/jobs, got %+v", jobs)
+	}
+	if got := jobs.Responses["202"].Content["text/plain"].Example; got != "queued" {
+		t.Errorf("Expected text example %q, got %v", "queued", got)
+	}
+
+	// Documenting routes doesn't mint real tokens
+	token := doc.Paths["/oauth/token"]["post"].Responses["200"].Content["application/json"].Example.(map[string]any)
+	if token["access_token"] != "example-access-token" || token["expires_in"] != float64(60) {
+		t.Errorf("Unexpected token example: %+v", token)
+	}
+	if minted := ts.tokens.list(); len(minted) != 0 {
+		t.Errorf("Expected no tokens to be minted, got %d", len(minted))
+	}
+
+	// Slow templates are documented without an example
+	if slow := doc.Paths["/slow"]["get"].Responses["200"]; slow == nil || slow.Content != nil {
+		t.Errorf("Expected the slow route to have no example, got %+v", slow)
+	}
+
+	// WebSocket routes are documented by their handshake
+	if ws := doc.Paths["/ws"]["get"]; ws == nil || ws.Responses["101"] == nil || len(ws.Responses) != 1 {
+		t.Errorf("Expected a single 101 response for the WebSocket route, got %+v", ws)
+	}
+
+	// Variants are documented by status, preferring the default variant
+	checkout := doc.Paths["/checkout"]["get"]
+	if checkout == nil || len(checkout.Responses) != 2 || checkout.Responses["201"] == nil {
+		t.Fatalf("Expected 200 and 201 responses for the variants route, got %+v", checkout)
+	}
+	if example := checkout.Responses["200"].Content["text/plain"].Example; example != "control" {
+		t.Errorf("Expected the default variant as the 200 example, got %v", example)
+	}
+
+	// Deprecated routes are documented as deprecated operations
+	if !doc.Paths["/slow"]["get"].Deprecated || doc.Paths["/api/jobs"]["post"].Deprecated {
+		t.Error("Expected only the deprecated route to be documented as deprecated")
+	}
+}
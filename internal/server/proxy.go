@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+// maxRecordings is the number of upstream responses kept by recording proxy
+// routes; older recordings are discarded first
+const maxRecordings = 1000
+
+// recording is an upstream response captured by a recording proxy route
+type recording struct {
+	ID         int64       `json:"id"`
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	Upstream   string      `json:"upstream"`
+	Status     int         `json:"status"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+	RecordedAt time.Time   `json:"recorded_at"`
+}
+
+// recordingStore holds the most recent recordings in the order they happened
+type recordingStore struct {
+	mu      sync.Mutex
+	entries []recording
+	nextID  int64
+	store   storage.Collection // Where recordings are persisted
+	logger  *slog.Logger
+}
+
+// newRecordingStore creates a recording store, restoring the recordings kept
+// in store
+func newRecordingStore(store storage.Collection, logger *slog.Logger) (*recordingStore, error) {
+	rs := &recordingStore{store: store, logger: logger}
+
+	records, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recordings: %w", err)
+	}
+	for _, record := range records {
+		var rec recording
+		if err := json.Unmarshal(record.Value, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %q: %w", record.Key, err)
+		}
+		rs.entries = append(rs.entries, rec)
+		rs.nextID = max(rs.nextID, rec.ID)
+	}
+
+	return rs, nil
+}
+
+// add stores a recording, discarding the oldest one when full
+func (rs *recordingStore) add(rec recording) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.nextID++
+	rec.ID = rs.nextID
+
+	if len(rs.entries) >= maxRecordings {
+		if err := rs.store.Delete(strconv.FormatInt(rs.entries[0].ID, 10)); err != nil {
+			rs.logger.Error("failed to remove discarded recording", "error", err)
+		}
+		rs.entries = rs.entries[1:]
+	}
+	rs.entries = append(rs.entries, rec)
+
+	data, err := json.Marshal(rec)
+	if err == nil {
+		err = rs.store.Put(strconv.FormatInt(rec.ID, 10), data)
+	}
+	if err != nil {
+		rs.logger.Error("failed to persist recording", "path", rec.Path, "error", err)
+	}
+}
+
+// list returns a copy of the stored recordings
+func (rs *recordingStore) list() []recording {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return append([]recording{}, rs.entries...)
+}
+
+// clear removes every recording and returns how many were removed
+func (rs *recordingStore) clear() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	count := len(rs.entries)
+	rs.entries = nil
+	if err := rs.store.Clear(); err != nil {
+		rs.logger.Error("failed to clear persisted recordings", "error", err)
+	}
+	return count
+}
+
+// serveProxy forwards the request to an upstream and returns the status code
+// sent to the client
+func (s *Server) serveProxy(w http.ResponseWriter, r *http.Request, upstream *router.Proxy) int {
+	status := http.StatusBadGateway
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if upstream.StripPrefix != "" {
+				path := strings.TrimPrefix(pr.Out.URL.Path, upstream.StripPrefix)
+				if !strings.HasPrefix(path, "/") {
+					path = "/" + path
+				}
+				pr.Out.URL.Path = path
+				pr.Out.URL.RawPath = ""
+			}
+
+			pr.SetURL(upstream.Target)
+			pr.SetXForwarded()
+
+			// Ask for an uncompressed body so recordings are readable
+			if upstream.Record {
+				pr.Out.Header.Del("Accept-Encoding")
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			status = resp.StatusCode
+			if !upstream.Record {
+				return nil
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			s.recordings.add(recording{
+				Method:     r.Method,
+				Path:       r.URL.RequestURI(),
+				Upstream:   resp.Request.URL.String(),
+				Status:     resp.StatusCode,
+				Headers:    resp.Header.Clone(),
+				Body:       string(body),
+				RecordedAt: time.Now(),
+			})
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			status = http.StatusBadGateway
+			s.handleBadGateway(w, r, err)
+		},
+		ErrorLog: slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
+	}
+
+	proxy.ServeHTTP(w, r)
+	return status
+}
+
+// handleListRecordings lists the upstream responses captured by recording proxy routes
+func (s *Server) handleListRecordings(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"recordings": s.recordings.list()})
+}
+
+// handleDeleteRecordings removes every recorded upstream response
+func (s *Server) handleDeleteRecordings(w http.ResponseWriter, _ *http.Request) {
+	count := s.recordings.clear()
+	s.logger.Info("recordings cleared", "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"deleted": count})
+}
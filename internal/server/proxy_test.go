@@ -0,0 +1,227 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_ProxyRoutes(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "real")
+		w.WriteHeader(http.StatusTeapot)
+		fmt.Fprintf(w, "upstream %s %s?%s", r.Method, r.URL.Path, r.URL.RawQuery)
+	}))
+	defer upstream.Close()
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/api/mocked",
+			Method:   "GET",
+			Template: "mocked",
+		},
+		{
+			Path:   "/^/api/.*$/",
+			Method: "GET",
+			Proxy:  &config.ProxyConfig{URL: upstream.URL + "/v2", StripPrefix: "/api", Record: true},
+		},
+		{
+			Path:   "/passthrough",
+			Method: "POST",
+			Proxy:  &config.ProxyConfig{URL: upstream.URL},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "mocked route wins over proxy",
+			method:         "GET",
+			path:           "/api/mocked",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "mocked",
+		},
+		{
+			name:           "proxied with prefix stripped",
+			method:         "GET",
+			path:           "/api/users?page=2",
+			expectedStatus: http.StatusTeapot,
+			expectedBody:   "upstream GET /v2/users?page=2",
+		},
+		{
+			name:           "proxied without rewriting",
+			method:         "POST",
+			path:           "/passthrough",
+			expectedStatus: http.StatusTeapot,
+			expectedBody:   "upstream POST /passthrough?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+
+	// Only the recording route keeps upstream responses
+	resp, err := ts.makeRequest("GET", "/__admin/recordings", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var listed struct {
+		Recordings []recording `json:"recordings"`
+	}
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(listed.Recordings) != 1 {
+		t.Fatalf("Expected 1 recording, got %d", len(listed.Recordings))
+	}
+	rec := listed.Recordings[0]
+	if rec.Method != "GET" || rec.Path != "/api/users?page=2" || rec.Status != http.StatusTeapot {
+		t.Errorf("Unexpected recording: %+v", rec)
+	}
+	if !strings.HasSuffix(rec.Upstream, "/v2/users?page=2") {
+		t.Errorf("Expected upstream URL to end with /v2/users?page=2, got %q", rec.Upstream)
+	}
+	if rec.Body != "upstream GET /v2/users?page=2" || rec.Headers.Get("X-Upstream") != "real" {
+		t.Errorf("Expected recorded body and headers, got %q and %v", rec.Body, rec.Headers)
+	}
+
+	// Recordings can be cleared
+	resp, err = ts.makeRequest("DELETE", "/__admin/recordings", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "{\n  \"deleted\": 1\n}\n" {
+		t.Errorf("Expected one deleted recording, got %q", body)
+	}
+}
+
+func TestServer_Integration_ProxyUnreachable(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL := upstream.URL
+	upstream.Close()
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/down",
+			Method: "GET",
+			Proxy:  &config.ProxyConfig{URL: upstreamURL},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("GET", "/down", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected status %d, got %d", http.StatusBadGateway, resp.StatusCode)
+	}
+}
+
+func TestServer_Integration_FallbackProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "upstream %s %s", r.Method, r.URL.RequestURI())
+	}))
+	defer upstream.Close()
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: "mocked"},
+	})
+	cfg.FallbackProxy = upstream.URL
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "mocked route", method: "GET", path: "/users", expectedStatus: http.StatusOK, expectedBody: "mocked"},
+		{name: "unmatched path", method: "GET", path: "/orders?page=2", expectedStatus: http.StatusAccepted, expectedBody: "upstream GET /orders?page=2"},
+		{name: "unmatched method", method: "POST", path: "/users", expectedStatus: http.StatusAccepted, expectedBody: "upstream POST /users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+
+	// Without a fallback, unmatched requests are answered with a 404 again
+	cfg.FallbackProxy = ""
+	if err := ts.Server.applyConfig(cfg); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+
+	resp, err := ts.makeRequest("GET", "/orders", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestRecordingStore_DiscardsOldest(t *testing.T) {
+	store, err := newRecordingStore(newMemoryCollection(t), slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("Failed to create recording store: %v", err)
+	}
+	for i := range maxRecordings + 5 {
+		store.add(recording{Path: fmt.Sprintf("/%d", i)})
+	}
+
+	recordings := store.list()
+	if len(recordings) != maxRecordings {
+		t.Fatalf("Expected %d recordings, got %d", maxRecordings, len(recordings))
+	}
+	if recordings[0].Path != "/5" {
+		t.Errorf("Expected oldest kept recording to be /5, got %s", recordings[0].Path)
+	}
+}
@@ -0,0 +1,552 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// headerMalformed lists the anomalies tolerated in a request to a raw route,
+// so templates can tell which ones were sent
+const headerMalformed = "X-Mockingjay-Malformed"
+
+// Anomalies of malformed requests, as listed in X-Mockingjay-Malformed
+const (
+	anomalyDuplicateContentLength  = "duplicate_content_length"
+	anomalyConflictingFraming      = "conflicting_framing"
+	anomalyInvalidTransferEncoding = "invalid_transfer_encoding"
+	anomalyInvalidChunkSize        = "invalid_chunk_size"
+)
+
+// errRawClose ends a raw connection without answering the request
+var errRawClose = errors.New("connection closed on malformed request")
+
+// rawRequestError is a request on a raw connection that can't be served,
+// answered with status and closing the connection
+type rawRequestError struct {
+	status int
+	detail string
+}
+
+func (e *rawRequestError) Error() string {
+	return e.detail
+}
+
+// rawListener hands connections whose first request targets a raw route to
+// the server's own parser, and every other connection to net/http
+type rawListener struct {
+	net.Listener
+	server *Server
+	conns  chan net.Conn
+	errs   chan error
+	closed chan struct{}
+	once   sync.Once
+}
+
+// newRawListener starts accepting connections from ln
+func (s *Server) newRawListener(ln net.Listener) *rawListener {
+	l := &rawListener{
+		Listener: ln,
+		server:   s,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		closed:   make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+// acceptLoop accepts connections and sorts them until the listener closes.
+// Errors are handed to net/http, which backs off on temporary ones.
+func (l *rawListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.closed:
+				return
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go l.sort(conn)
+	}
+}
+
+// sort peeks at the request line of a connection's first request, serving
+// requests to raw routes itself and handing everything else to net/http
+// untouched
+func (l *rawListener) sort(conn net.Conn) {
+	br := bufio.NewReader(conn)
+
+	if timeout := l.server.httpServer.ReadHeaderTimeout; timeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+	line, ok := peekLine(br)
+	_ = conn.SetReadDeadline(time.Time{})
+
+	if ok {
+		if method, target, _, ok := parseRequestLine(line); ok {
+			if l.server.findRawRoute(method, target) != nil {
+				l.server.serveRaw(conn, br)
+				return
+			}
+		}
+	}
+
+	select {
+	case l.conns <- &peekedConn{Conn: conn, r: br}:
+	case <-l.closed:
+		conn.Close()
+	}
+}
+
+// Accept returns the next connection for net/http to serve
+func (l *rawListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting connections
+func (l *rawListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return l.Listener.Close()
+}
+
+// peekedConn is a connection whose first bytes were peeked at, which are
+// read again before the rest
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// peekLine returns the first line buffered by br without consuming it
+func peekLine(br *bufio.Reader) (string, bool) {
+	for {
+		buffered, _ := br.Peek(br.Buffered())
+		if i := bytes.IndexByte(buffered, '\n'); i >= 0 {
+			return strings.TrimRight(string(buffered[:i]), "\r"), true
+		}
+		if _, err := br.Peek(br.Buffered() + 1); err != nil {
+			return "", false // Closed, timed out, or a line longer than the buffer
+		}
+	}
+}
+
+// parseRequestLine splits an HTTP/1 request line into its parts
+func parseRequestLine(line string) (method, target, proto string, ok bool) {
+	method, rest, ok1 := strings.Cut(line, " ")
+	target, proto, ok2 := strings.Cut(rest, " ")
+	if !ok1 || !ok2 || method == "" || target == "" || !strings.HasPrefix(proto, "HTTP/1.") {
+		return "", "", "", false
+	}
+	return method, target, proto, true
+}
+
+// findRawRoute returns the raw settings of the route serving a request line,
+// or nil when it isn't a raw route
+func (s *Server) findRawRoute(method, target string) *router.Raw {
+	path := target
+	if u, err := url.ParseRequestURI(target); err == nil {
+		path = u.Path
+	}
+
+	for _, route := range s.current().index.Candidates(method, path) {
+		if route.Raw != nil && route.MatchesPath(path) {
+			return route.Raw
+		}
+	}
+	return nil
+}
+
+// serveRaw serves the requests of a raw connection, parsing them itself so
+// malformed ones can be tolerated. Connections serve a single request unless
+// its route keeps them alive.
+func (s *Server) serveRaw(conn net.Conn, br *bufio.Reader) {
+	defer conn.Close()
+
+	for {
+		if timeout := s.httpServer.ReadTimeout; timeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(timeout))
+		}
+
+		req, raw, err := s.readRawRequest(br)
+		if err != nil {
+			var reqErr *rawRequestError
+			if errors.As(err, &reqErr) {
+				s.logger.Info("malformed request rejected", "remote_addr", conn.RemoteAddr().String(), "error", reqErr.detail)
+				writeRawError(conn, reqErr)
+			} else if errors.Is(err, errRawClose) {
+				s.logger.Info("connection closed on malformed request", "remote_addr", conn.RemoteAddr().String())
+			}
+			return
+		}
+		req.RemoteAddr = conn.RemoteAddr().String()
+		_ = conn.SetReadDeadline(time.Time{})
+
+		keepAlive := raw.KeepAlive && !req.Close
+		if !s.serveRawRequest(conn, br, req, keepAlive) || !keepAlive {
+			return
+		}
+	}
+}
+
+// serveRawRequest serves a request read from a raw connection and writes the
+// response, reporting whether the connection is still usable
+func (s *Server) serveRawRequest(conn net.Conn, br *bufio.Reader, req *http.Request, keepAlive bool) (usable bool) {
+	rw := &rawResponseWriter{conn: conn, br: br, header: make(http.Header)}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if recovered != http.ErrAbortHandler {
+				s.logger.Error("panic serving raw request", "path", req.URL.Path, "panic", recovered)
+			}
+			usable = false
+		}
+	}()
+
+	s.httpServer.Handler.ServeHTTP(rw, req)
+	if rw.hijacked {
+		return false
+	}
+
+	if timeout := s.httpServer.WriteTimeout; timeout > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	if err := rw.writeResponse(req, keepAlive); err != nil {
+		return false
+	}
+	return true
+}
+
+// readRawRequest reads a request from a raw connection, applying the
+// settings of its route to malformed framing. Anomalies that were tolerated
+// are listed in the X-Mockingjay-Malformed request header.
+func (s *Server) readRawRequest(br *bufio.Reader) (*http.Request, *router.Raw, error) {
+	tp := textproto.NewReader(br)
+
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, nil, err
+	}
+	method, target, proto, ok := parseRequestLine(line)
+	if !ok {
+		return nil, nil, &rawRequestError{http.StatusBadRequest, fmt.Sprintf("malformed request line %q", line)}
+	}
+	major, minor, _ := http.ParseHTTPVersion(proto)
+
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, nil, &rawRequestError{http.StatusBadRequest, fmt.Sprintf("malformed headers: %v", err)}
+	}
+
+	u, err := url.ParseRequestURI(target)
+	if err != nil {
+		return nil, nil, &rawRequestError{http.StatusBadRequest, fmt.Sprintf("malformed request target %q", target)}
+	}
+
+	raw := s.findRawRoute(method, target)
+	if raw == nil {
+		raw = router.DefaultRaw
+	}
+
+	req := &http.Request{
+		Method:     method,
+		URL:        u,
+		Proto:      proto,
+		ProtoMajor: major,
+		ProtoMinor: minor,
+		Header:     http.Header(header),
+		Host:       header.Get("Host"),
+		RequestURI: target,
+	}
+	req.Header.Del(headerMalformed)
+	req.Close = (major == 1 && minor == 0 && !strings.EqualFold(req.Header.Get("Connection"), "keep-alive")) ||
+		strings.EqualFold(req.Header.Get("Connection"), "close")
+
+	body, anomalies, err := readRawBody(br, req.Header, raw, s.currentBodyLimit())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The rest of a truncated body can't be told apart from the next request
+	if slices.Contains(anomalies, anomalyInvalidChunkSize) {
+		req.Close = true
+	}
+	if len(anomalies) > 0 {
+		req.Header.Set(headerMalformed, strings.Join(anomalies, ","))
+		s.logger.Info("malformed request tolerated", "method", method, "path", u.Path, "anomalies", anomalies)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return req.WithContext(context.Background()), raw, nil
+}
+
+// currentBodyLimit returns the largest request body read
+func (s *Server) currentBodyLimit() int64 {
+	return s.current().bodyLimit
+}
+
+// readRawBody reads a request body framed by header, resolving ambiguous
+// framing with the actions of raw. It returns the body and the anomalies
+// that were tolerated.
+func readRawBody(br *bufio.Reader, header http.Header, raw *router.Raw, limit int64) ([]byte, []string, error) {
+	var anomalies []string
+
+	// Work out how the body is framed
+	lengths, err := contentLengths(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	length := int64(-1)
+	if len(lengths) > 0 {
+		length = lengths[0]
+	}
+	if len(lengths) > 1 {
+		anomalies = append(anomalies, anomalyDuplicateContentLength)
+		switch raw.DuplicateContentLength {
+		case config.RawFirst:
+		case config.RawLast:
+			length = lengths[len(lengths)-1]
+		default:
+			return nil, nil, rawAnomaly(raw.DuplicateContentLength, "Content-Length headers with different values")
+		}
+	}
+
+	chunked := false
+	if encodings := header.Values("Transfer-Encoding"); len(encodings) > 0 {
+		chunked = true
+		if len(encodings) != 1 || !strings.EqualFold(encodings[0], "chunked") {
+			anomalies = append(anomalies, anomalyInvalidTransferEncoding)
+			switch raw.InvalidTransferEncoding {
+			case config.RawChunked:
+			case config.RawIgnore:
+				chunked = false
+			default:
+				return nil, nil, rawAnomaly(raw.InvalidTransferEncoding, fmt.Sprintf("unsupported Transfer-Encoding %q", strings.Join(encodings, ", ")))
+			}
+		}
+	}
+
+	if chunked && length >= 0 {
+		anomalies = append(anomalies, anomalyConflictingFraming)
+		switch raw.ConflictingFraming {
+		case config.RawChunked:
+		case config.RawContentLength:
+			chunked = false
+		default:
+			return nil, nil, rawAnomaly(raw.ConflictingFraming, "both Content-Length and Transfer-Encoding are set")
+		}
+	}
+
+	// Read the body
+	if !chunked {
+		if length <= 0 {
+			return nil, anomalies, nil
+		}
+		if length > limit {
+			return nil, nil, &rawRequestError{http.StatusRequestEntityTooLarge, fmt.Sprintf("the request body is larger than the limit of %d bytes", limit)}
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(br, body); err != nil {
+			return nil, nil, err
+		}
+		return body, anomalies, nil
+	}
+
+	body, err := readChunkedBody(br, limit)
+	if err != nil {
+		var reqErr *rawRequestError
+		if errors.As(err, &reqErr) && reqErr.status == http.StatusBadRequest {
+			anomalies = append(anomalies, anomalyInvalidChunkSize)
+			if raw.InvalidChunkSize == config.RawTruncate {
+				return body, anomalies, nil
+			}
+			return nil, nil, rawAnomaly(raw.InvalidChunkSize, reqErr.detail)
+		}
+		return nil, nil, err
+	}
+	return body, anomalies, nil
+}
+
+// contentLengths returns the distinct Content-Length values of a request, in
+// the order they were sent
+func contentLengths(header http.Header) ([]int64, error) {
+	var lengths []int64
+	for _, value := range header.Values("Content-Length") {
+		for _, part := range strings.Split(value, ",") {
+			n, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil || n < 0 {
+				return nil, &rawRequestError{http.StatusBadRequest, fmt.Sprintf("invalid Content-Length %q", value)}
+			}
+			if !slices.Contains(lengths, n) {
+				lengths = append(lengths, n)
+			}
+		}
+	}
+	return lengths, nil
+}
+
+// readChunkedBody reads a chunked body and its trailers. Malformed chunks
+// return a 400 error along with the chunks read before them.
+func readChunkedBody(br *bufio.Reader, limit int64) ([]byte, error) {
+	var body []byte
+	for {
+		line, err := readCRLFLine(br)
+		if err != nil {
+			return body, err
+		}
+
+		sizeText, _, _ := strings.Cut(line, ";") // Chunk extensions are ignored
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeText), 16, 64)
+		if err != nil || size < 0 {
+			return body, &rawRequestError{http.StatusBadRequest, fmt.Sprintf("invalid chunk size %q", line)}
+		}
+
+		if size == 0 {
+			// Skip the trailers, up to the empty line ending the body
+			for {
+				trailer, err := readCRLFLine(br)
+				if err != nil {
+					return body, err
+				}
+				if trailer == "" {
+					return body, nil
+				}
+			}
+		}
+
+		if int64(len(body))+size > limit {
+			return nil, &rawRequestError{http.StatusRequestEntityTooLarge, fmt.Sprintf("the request body is larger than the limit of %d bytes", limit)}
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return body, err
+		}
+		body = append(body, chunk...)
+
+		if end, err := readCRLFLine(br); err != nil {
+			return body, err
+		} else if end != "" {
+			return body, &rawRequestError{http.StatusBadRequest, fmt.Sprintf("chunk of %d bytes isn't followed by CRLF", size)}
+		}
+	}
+}
+
+// readCRLFLine reads a line ending in CRLF, without the line ending
+func readCRLFLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", &rawRequestError{http.StatusBadRequest, fmt.Sprintf("line %q doesn't end in CRLF", strings.TrimRight(line, "\n"))}
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+// rawAnomaly returns the error ending a request with an anomaly its route
+// doesn't tolerate
+func rawAnomaly(action, detail string) error {
+	if action == config.RawClose {
+		return errRawClose
+	}
+	return &rawRequestError{http.StatusBadRequest, detail}
+}
+
+// writeRawError answers a request that can't be served and asks the client
+// to close the connection
+func writeRawError(conn net.Conn, err *rawRequestError) {
+	body := fmt.Sprintf("%d %s: %s\n", err.status, http.StatusText(err.status), err.detail)
+	fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%s",
+		err.status, http.StatusText(err.status), len(body), body)
+}
+
+// rawResponseWriter buffers the response to a request read from a raw
+// connection, which is written once the handler returns
+type rawResponseWriter struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func (w *rawResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *rawResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *rawResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// Flush does nothing, since the response is written once the handler returns
+func (w *rawResponseWriter) Flush() {}
+
+// Hijack hands the connection to the handler, as faults aborting it need
+func (w *rawResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.conn, bufio.NewReadWriter(w.br, bufio.NewWriter(w.conn)), nil
+}
+
+// writeResponse writes the buffered response, keeping the connection open
+// for the next request when keepAlive is set
+func (w *rawResponseWriter) writeResponse(req *http.Request, keepAlive bool) error {
+	w.WriteHeader(http.StatusOK)
+
+	// Fill in the headers net/http would have set
+	if w.header.Get("Date") == "" {
+		w.header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	if w.header.Get("Content-Type") == "" && w.body.Len() > 0 {
+		w.header.Set("Content-Type", http.DetectContentType(w.body.Bytes()))
+	}
+
+	resp := &http.Response{
+		StatusCode:    w.status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		ContentLength: int64(w.body.Len()),
+		Close:         !keepAlive,
+		Request:       req,
+	}
+	return resp.Write(w.conn)
+}
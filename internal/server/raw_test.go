@@ -0,0 +1,184 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// startRawServer serves cfg on a local listener that sorts raw connections,
+// returning its address
+func startRawServer(t *testing.T, cfg *config.Config) string {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", logger, "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go server.httpServer.Serve(server.newRawListener(ln))
+	t.Cleanup(func() { server.httpServer.Close() })
+
+	return ln.Addr().String()
+}
+
+// sendRaw writes request as-is and returns everything read back until the
+// server closes the connection
+func sendRaw(t *testing.T, addr, request string) string {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, request); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return string(response)
+}
+
+func TestServer_Integration_RawRoutes(t *testing.T) {
+	template := `got {{ .Body }} malformed={{ .Headers.Get "X-Mockingjay-Malformed" }}`
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/first", Method: "POST", Template: template, Raw: &config.RawConfig{DuplicateContentLength: config.RawFirst}},
+		{Path: "/last", Method: "POST", Template: template, Raw: &config.RawConfig{DuplicateContentLength: config.RawLast}},
+		{Path: "/strict", Method: "POST", Template: template, Raw: &config.RawConfig{}},
+		{Path: "/close", Method: "POST", Template: template, Raw: &config.RawConfig{DuplicateContentLength: config.RawClose}},
+		{Path: "/chunked", Method: "POST", Template: template, Raw: &config.RawConfig{ConflictingFraming: config.RawChunked, InvalidChunkSize: config.RawTruncate}},
+		{Path: "/length", Method: "POST", Template: template, Raw: &config.RawConfig{ConflictingFraming: config.RawContentLength, InvalidTransferEncoding: config.RawIgnore}},
+		{Path: "/plain", Method: "GET", Template: "plain"},
+	})
+	addr := startRawServer(t, cfg)
+
+	tests := []struct {
+		name     string
+		request  string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "first content length",
+			request:  "POST /first HTTP/1.1\r\nHost: x\r\nContent-Length: 3\r\nContent-Length: 5\r\n\r\nhello",
+			contains: []string{"200 OK", "got hel malformed=duplicate_content_length", "Connection: close"},
+		},
+		{
+			name:     "last content length",
+			request:  "POST /last HTTP/1.1\r\nHost: x\r\nContent-Length: 3, 5\r\n\r\nhello",
+			contains: []string{"200 OK", "got hello malformed=duplicate_content_length"},
+		},
+		{
+			name:     "rejected by default",
+			request:  "POST /strict HTTP/1.1\r\nHost: x\r\nContent-Length: 3\r\nContent-Length: 5\r\n\r\nhello",
+			contains: []string{"400 Bad Request", "Content-Length headers with different values"},
+			excludes: []string{"got"},
+		},
+		{
+			name:     "closed without answering",
+			request:  "POST /close HTTP/1.1\r\nHost: x\r\nContent-Length: 3\r\nContent-Length: 5\r\n\r\nhello",
+			excludes: []string{"HTTP/1.1"},
+		},
+		{
+			name:     "conflicting framing read as chunked",
+			request:  "POST /chunked HTTP/1.1\r\nHost: x\r\nContent-Length: 4\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\n\r\n",
+			contains: []string{"got abc malformed=conflicting_framing"},
+		},
+		{
+			name:     "bad chunk size truncated",
+			request:  "POST /chunked HTTP/1.1\r\nHost: x\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\nzz\r\n",
+			contains: []string{"got abc malformed=invalid_chunk_size"},
+		},
+		{
+			name:     "conflicting framing read by length",
+			request:  "POST /length HTTP/1.1\r\nHost: x\r\nContent-Length: 2\r\nTransfer-Encoding: chunked\r\n\r\nhi",
+			contains: []string{"got hi malformed=conflicting_framing"},
+		},
+		{
+			name:     "invalid transfer encoding ignored",
+			request:  "POST /length HTTP/1.1\r\nHost: x\r\nTransfer-Encoding: gzip\r\n\r\n",
+			contains: []string{"malformed=invalid_transfer_encoding"},
+		},
+		{
+			name:     "client header replaced",
+			request:  "POST /first HTTP/1.1\r\nHost: x\r\nX-Mockingjay-Malformed: forged\r\nContent-Length: 2\r\n\r\nhi",
+			contains: []string{"got hi malformed="},
+			excludes: []string{"forged"},
+		},
+		{
+			name:     "other routes served by net/http",
+			request:  "GET /plain HTTP/1.1\r\nHost: x\r\nConnection: close\r\n\r\n",
+			contains: []string{"200 OK", "plain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := sendRaw(t, addr, tt.request)
+			for _, want := range tt.contains {
+				if !strings.Contains(response, want) {
+					t.Errorf("Expected response to contain %q, got:\n%s", want, response)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(response, unwanted) {
+					t.Errorf("Expected response not to contain %q, got:\n%s", unwanted, response)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_Integration_RawKeepAlive(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/upload",
+			Method:   "POST",
+			Template: "upload {{ .Body }}",
+			Raw:      &config.RawConfig{DuplicateContentLength: config.RawFirst, KeepAlive: true},
+		},
+		{Path: "/admin", Method: "GET", Template: "smuggled"},
+	})
+	addr := startRawServer(t, cfg)
+
+	// The bytes past the first Content-Length are read as a second request
+	smuggled := "GET /admin HTTP/1.1\r\nHost: x\r\nConnection: close\r\n\r\n"
+	request := "POST /upload HTTP/1.1\r\nHost: x\r\nContent-Length: 2\r\nContent-Length: 999\r\n\r\nhi" + smuggled
+
+	response := sendRaw(t, addr, request)
+	if got := strings.Count(response, "HTTP/1.1 200 OK"); got != 2 {
+		t.Fatalf("Expected two responses, got %d:\n%s", got, response)
+	}
+	if !strings.Contains(response, "upload hi") || !strings.Contains(response, "smuggled") {
+		t.Errorf("Expected both the upload and the smuggled request to be answered, got:\n%s", response)
+	}
+}
+
+func TestServer_Integration_RawBodyLimit(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/upload", Method: "POST", Template: "ok", Raw: &config.RawConfig{}},
+	})
+	cfg.Server.BodyLimit = 4
+	addr := startRawServer(t, cfg)
+
+	response := sendRaw(t, addr, "POST /upload HTTP/1.1\r\nHost: x\r\nContent-Length: 10\r\n\r\n0123456789")
+	if !strings.Contains(response, "413 Request Entity Too Large") {
+		t.Errorf("Expected a 413 response, got:\n%s", response)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// Reasons the server is recycled for, as logged on shutdown
+const (
+	recycleMaxUptime   = "max_uptime reached"
+	recycleMaxRequests = "max_requests reached"
+)
+
+// recycler tells when the server has run for as long, or served as many
+// requests, as configured, so long-lived mocks can exit cleanly and be
+// restarted with a fresh state by their supervisor
+type recycler struct {
+	maxUptime   time.Duration // Zero when the server runs for any time
+	maxRequests int64         // Zero when the server serves any number of requests
+	requests    atomic.Int64  // Requests served, not counting health checks
+	timer       *time.Timer   // Fires once the server has run for maxUptime
+	reasons     chan string   // Receives the reason the server should stop for
+	once        sync.Once
+}
+
+// newRecycler returns a recycler for the limits of sc, which only change on
+// restart
+func newRecycler(sc config.ServerConfig) *recycler {
+	return &recycler{
+		maxUptime:   sc.MaxUptime,
+		maxRequests: sc.MaxRequests,
+		reasons:     make(chan string, 1),
+	}
+}
+
+// start starts counting the uptime
+func (rc *recycler) start() {
+	if rc.maxUptime > 0 {
+		rc.timer = time.AfterFunc(rc.maxUptime, func() { rc.recycle(recycleMaxUptime) })
+	}
+}
+
+// stop stops counting the uptime
+func (rc *recycler) stop() {
+	if rc.timer != nil {
+		rc.timer.Stop()
+	}
+}
+
+// countRequest counts a request, asking for a recycle once the limit is hit.
+// The request that hits it is still served, as shutting down waits for it.
+func (rc *recycler) countRequest() {
+	if rc.maxRequests <= 0 {
+		return
+	}
+	if rc.requests.Add(1) == rc.maxRequests {
+		rc.recycle(recycleMaxRequests)
+	}
+}
+
+// recycle asks for the server to stop for reason, only once
+func (rc *recycler) recycle(reason string) {
+	rc.once.Do(func() { rc.reasons <- reason })
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// startRecycledServer starts a server with the given limits, returning the
+// server and a channel receiving what Start returned
+func startRecycledServer(t *testing.T, sc config.ServerConfig) (*Server, <-chan error) {
+	t.Helper()
+
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}})
+	cfg.Server = sc
+
+	srv, err := NewServer(cfg, nil, "127.0.0.1:0", slog.New(slog.NewTextHandler(io.Discard, nil)), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(t.Context()) }()
+	return srv, done
+}
+
+// waitForStop waits for a server started by startRecycledServer to stop
+func waitForStop(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the server to stop")
+	}
+}
+
+func TestServer_RecycleMaxUptime(t *testing.T) {
+	_, done := startRecycledServer(t, config.ServerConfig{MaxUptime: 50 * time.Millisecond})
+	waitForStop(t, done)
+}
+
+func TestServer_RecycleMaxRequests(t *testing.T) {
+	srv, done := startRecycledServer(t, config.ServerConfig{MaxRequests: 2})
+
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	// Health checks aren't counted, and the last request is still served
+	for _, path := range []string{"/", "/health", "/health", "/"} {
+		if code := serve(path); code != http.StatusOK {
+			t.Errorf("Expected 200 for %s, got %d", path, code)
+		}
+	}
+	waitForStop(t, done)
+}
+
+func TestServer_RecycleWithoutLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/", Method: "GET", Template: "ok"}})
+	srv, err := NewServer(cfg, nil, "127.0.0.1:0", slog.New(slog.NewTextHandler(io.Discard, nil)), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(ctx) }()
+
+	for range 10 {
+		srv.recycler.countRequest()
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the server to keep running without limits, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	waitForStop(t, done)
+}
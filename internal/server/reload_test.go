@@ -0,0 +1,143 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_IncludeReload(t *testing.T) {
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "main.yaml")
+	usersFile := filepath.Join(dir, "users.yaml")
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %q: %v", path, err)
+		}
+	}
+	call := func(ts *TestServer, path string) string {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", path, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+
+	write(mainFile, `include: [users.yaml]
+routes:
+  - path: /health
+    method: GET
+    template: "ok"`)
+	write(usersFile, `routes:
+  - path: /users
+    method: GET
+    template: "first"`)
+
+	cfg, err := config.LoadConfig(mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+	if err := ts.Server.applyConfigFiles(cfg, []string{mainFile}); err != nil {
+		t.Fatalf("Failed to apply config: %v", err)
+	}
+
+	if files := ts.Server.ConfigFiles(); !slices.Equal(files, []string{mainFile, usersFile}) {
+		t.Errorf("Expected config files [%s %s], got %v", mainFile, usersFile, files)
+	}
+	if body := call(ts, "/users"); body != "first" {
+		t.Errorf("Expected included route response %q, got %q", "first", body)
+	}
+
+	// Changes to the included file are picked up on reload
+	write(usersFile, `routes:
+  - path: /users
+    method: GET
+    template: "second"`)
+	if err := ts.Server.ReloadConfig(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if body := call(ts, "/users"); body != "second" {
+		t.Errorf("Expected reloaded route response %q, got %q", "second", body)
+	}
+
+	// Dropping the include stops watching the file
+	write(mainFile, `routes:
+  - path: /health
+    method: GET
+    template: "ok"`)
+	if err := ts.Server.ReloadConfig(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if files := ts.Server.ConfigFiles(); !slices.Equal(files, []string{mainFile}) {
+		t.Errorf("Expected config files [%s], got %v", mainFile, files)
+	}
+}
+
+func TestServer_Integration_TemplateFileReload(t *testing.T) {
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "main.yaml")
+	templateFile := filepath.Join(dir, "users.tmpl")
+
+	if err := os.WriteFile(templateFile, []byte("first"), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := os.WriteFile(mainFile, []byte("routes:\n  - path: /users\n    method: GET\n    template_file: "+templateFile+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+	if err := ts.Server.applyConfigFiles(cfg, []string{mainFile}); err != nil {
+		t.Fatalf("Failed to apply config: %v", err)
+	}
+
+	if files := ts.Server.ConfigFiles(); !slices.Equal(files, []string{mainFile, templateFile}) {
+		t.Errorf("Expected config files [%s %s], got %v", mainFile, templateFile, files)
+	}
+
+	// Reloading recompiles the template from the file's new contents
+	if err := os.WriteFile(templateFile, []byte("second"), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := ts.Server.ReloadConfig(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	resp, err := ts.makeRequest("GET", "/users", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "second" {
+		t.Errorf("Expected reloaded template response %q, got %q", "second", body)
+	}
+
+	// A broken template keeps the previous one serving
+	if err := os.WriteFile(templateFile, []byte("{{ .Broken"), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := ts.Server.ReloadConfig(); err == nil {
+		t.Error("Expected reload to fail for a broken template")
+	}
+
+	resp, err = ts.makeRequest("GET", "/users", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "second" {
+		t.Errorf("Expected the previous template to keep serving, got %q", body)
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// routing is everything a configuration reload replaces: the routes, the
+// engine they were compiled with, the middleware chain and the settings that
+// go with them. A routing is never modified once it's in use; reloads build
+// a new one and swap it in, so requests read it without locking, and a
+// request keeps the routing it started with even if a reload happens while
+// it's being served.
+type routing struct {
+	routes          []*router.Route
+	index           *router.Index // Routes by method and path, in the order requests are matched against them
+	engine          *templatepkg.Engine
+	middlewareChain http.Handler       // Middleware chain handler
+	devMode         bool               // Emit diagnostic headers for injected delays and faults
+	clockSkew       time.Duration      // Offset of the mock's clock from the real time
+	clockTime       time.Time          // Time the mock's clock is frozen at, zero to follow the real time
+	strictHTTP      string             // How responses breaking basic HTTP rules are handled, empty when off
+	headerVars      bool               // Expose X-Mockingjay-Var-* request headers to templates
+	fakeSeed        string             // Seed of the random functions of every route, empty when unset
+	bodyLimit       int64              // Largest request body read, in bytes
+	contentType     string             // Content-Type of bodies routes don't label, empty to infer it
+	serverHeaders   map[string]string  // Headers sent with every response
+	middlewares     middleware.Config  // Enabled middleware, for the configuration summary
+	fallbackProxy   *router.Proxy      // Upstream requests matching no route are forwarded to, if any
+	fallback        *router.Route      // Route answering requests matching no route, if any
+	errorPages      router.ErrorPages  // Responses replacing built-in errors
+	grpcMethods     router.GRPCMethods // Mocked gRPC methods by the path they are called on
+	watchFiles      []string           // Included files and directories and template files, for hot-reload
+	tenants         []*tenant          // Isolated mock servers hosted by this process
+	adminTokens     []adminToken       // Tokens accepted by the admin API, which is open when there are none
+	headerCasing    map[string]string  // Configured names of response headers, by canonical name, when their casing is preserved
+	configPaths     []string           // Config files and directories the configuration is reloaded from
+
+	// Response header templates every route renders before its own
+	responseHeaders map[string]*template.Template
+}
+
+// routingKey is the request context key of the routing a request is served
+// with. It's keyed by server, so tenants don't pick up their host's routing.
+type routingKey struct {
+	server *Server
+}
+
+// current returns the routing new requests are served with
+func (s *Server) current() *routing {
+	return s.routing.Load()
+}
+
+// serveCurrent serves a request through the middleware chain of the current
+// routing, which the request keeps until it's answered, with the headers the
+// server sends with every response, written with their configured casing
+func (s *Server) serveCurrent(w http.ResponseWriter, r *http.Request) {
+	rt := s.current()
+	w = newHeaderCaseWriter(w, rt.headerCasing)
+	for name, value := range rt.serverHeaders {
+		w.Header().Set(name, value)
+	}
+	ctx := context.WithValue(r.Context(), routingKey{s}, rt)
+	rt.middlewareChain.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// routingFor returns the routing a request was taken in with, or the current
+// one for requests handed straight to the server
+func (s *Server) routingFor(r *http.Request) *routing {
+	if rt, ok := r.Context().Value(routingKey{s}).(*routing); ok {
+		return rt
+	}
+	return s.current()
+}
+
+// findTenant returns the prefix-addressed tenant that owns the given path, if any
+func (rt *routing) findTenant(path string) *tenant {
+	for _, t := range rt.tenants {
+		if t.config.MatchPrefix(path) {
+			return t
+		}
+	}
+	return nil
+}
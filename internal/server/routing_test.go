@@ -0,0 +1,94 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_ReloadDuringRequest(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/slow",
+			Method:   "GET",
+			Template: "first",
+			Delay:    &config.DelayConfig{Min: 300 * time.Millisecond, Max: 300 * time.Millisecond},
+		},
+	})
+	ts := NewTestServer(t, cfg)
+
+	inFlight := make(chan string, 1)
+	go func() {
+		resp, err := ts.makeRequest("GET", "/slow", nil, nil)
+		if err != nil {
+			inFlight <- "request failed: " + err.Error()
+			return
+		}
+		inFlight <- readResponseBody(t, resp)
+	}()
+
+	// Reload while the request waits for its delay
+	time.Sleep(100 * time.Millisecond)
+	reloaded := createTestConfig([]config.RouteConfig{
+		{Path: "/slow", Method: "GET", Template: "second"},
+	})
+	if err := ts.Server.applyConfig(reloaded); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	// The reload doesn't wait for requests being served
+	select {
+	case body := <-inFlight:
+		t.Fatalf("Expected the reload to finish before the in-flight request, got %q first", body)
+	default:
+	}
+
+	// The in-flight request finishes with the routes it started with
+	if body := <-inFlight; body != "first" {
+		t.Errorf("Expected in-flight request to be served by the old route, got %q", body)
+	}
+
+	resp, err := ts.makeRequest("GET", "/slow", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "second" {
+		t.Errorf("Expected new requests to be served by the reloaded route, got %q", body)
+	}
+}
+
+func TestServer_Integration_ConcurrentReloads(t *testing.T) {
+	configs := []*config.Config{
+		createTestConfig([]config.RouteConfig{{Path: "/value", Method: "GET", Template: "a"}}),
+		createTestConfig([]config.RouteConfig{{Path: "/value", Method: "GET", Template: "b"}}),
+	}
+	ts := NewTestServer(t, configs[0])
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				resp, err := ts.makeRequest("GET", "/value", nil, nil)
+				if err != nil {
+					t.Errorf("Request failed: %v", err)
+					return
+				}
+				if body := readResponseBody(t, resp); body != "a" && body != "b" {
+					t.Errorf("Expected a response from either configuration, got %q", body)
+				}
+			}
+		}()
+	}
+
+	for i := range 20 {
+		if err := ts.Server.applyConfig(configs[i%2]); err != nil {
+			t.Fatalf("Failed to reload config: %v", err)
+		}
+		_ = ts.Server.ConfigSummary()
+	}
+	wg.Wait()
+}
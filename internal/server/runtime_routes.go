@@ -0,0 +1,300 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// runtimeRoute is a route created through the admin API rather than the config file
+type runtimeRoute struct {
+	ID        string
+	Config    config.RouteConfig
+	Route     *router.Route
+	CreatedAt time.Time
+	ExpiresAt time.Time // Zero value means the route never expires
+	Session   string    // Session the route was created under, empty when it serves every request
+}
+
+// expired reports whether the route has passed its expiry time
+func (rr *runtimeRoute) expired(now time.Time) bool {
+	return !rr.ExpiresAt.IsZero() && !now.Before(rr.ExpiresAt)
+}
+
+// runtimeRouteStore holds routes created at runtime. They take precedence over
+// configured routes and survive configuration reloads.
+type runtimeRouteStore struct {
+	mu     sync.Mutex
+	routes []*runtimeRoute
+	nextID uint64
+	now    func() time.Time
+}
+
+// newRuntimeRouteStore creates an empty runtime route store
+func newRuntimeRouteStore() *runtimeRouteStore {
+	return &runtimeRouteStore{now: time.Now}
+}
+
+// add stores a compiled route, expiring after ttl when ttl is positive. Routes
+// created under a session only serve its requests, and expire with it.
+func (rs *runtimeRouteStore) add(cfg config.RouteConfig, route *router.Route, ttl time.Duration, sess *session) *runtimeRoute {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.nextID++
+	now := rs.now()
+
+	rr := &runtimeRoute{
+		ID:        strconv.FormatUint(rs.nextID, 10),
+		Config:    cfg,
+		Route:     route,
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		rr.ExpiresAt = now.Add(ttl)
+	}
+	if sess != nil {
+		rr.Session = sess.ID
+		if !sess.ExpiresAt.IsZero() && (rr.ExpiresAt.IsZero() || sess.ExpiresAt.Before(rr.ExpiresAt)) {
+			rr.ExpiresAt = sess.ExpiresAt
+		}
+	}
+
+	// Newest routes are matched first so they can override older ones
+	rs.routes = append([]*runtimeRoute{rr}, rs.routes...)
+	return rr
+}
+
+// remove deletes the route with the given ID created under session, or
+// outside sessions when it's empty, reporting whether it existed
+func (rs *runtimeRouteStore) remove(id, session string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.pruneLocked()
+
+	for i, rr := range rs.routes {
+		if rr.ID == id && rr.Session == session {
+			rs.routes = slices.Delete(rs.routes, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// clearSession deletes the runtime routes created under a session, or
+// outside sessions when it's empty, and returns how many were removed
+func (rs *runtimeRouteStore) clearSession(id string) int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.pruneLocked()
+
+	count := len(rs.routes)
+	rs.routes = slices.DeleteFunc(rs.routes, func(rr *runtimeRoute) bool {
+		return rr.Session == id
+	})
+	return count - len(rs.routes)
+}
+
+// list returns a snapshot of the routes that have not expired
+func (rs *runtimeRouteStore) list() []*runtimeRoute {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.pruneLocked()
+	return slices.Clone(rs.routes)
+}
+
+// listSession returns a snapshot of the unexpired routes created under a
+// session
+func (rs *runtimeRouteStore) listSession(id string) []*runtimeRoute {
+	return slices.DeleteFunc(rs.list(), func(rr *runtimeRoute) bool {
+		return rr.Session != id
+	})
+}
+
+// match returns the first unexpired runtime route matching the request.
+// Routes of the session named by the request's X-Mockingjay-Session header are
+// matched before the routes created outside sessions, and routes of other
+// sessions never are.
+func (rs *runtimeRouteStore) match(r *http.Request) *router.RouteMatch {
+	routes := rs.list()
+	if id := r.Header.Get(headerSession); id != "" {
+		if match := matchRuntimeRoutes(routes, id, r); match != nil {
+			return match
+		}
+	}
+	return matchRuntimeRoutes(routes, "", r)
+}
+
+// matchRuntimeRoutes returns the first of routes created under session,
+// or outside sessions when it's empty, matching the request
+func matchRuntimeRoutes(routes []*runtimeRoute, session string, r *http.Request) *router.RouteMatch {
+	for _, rr := range routes {
+		if rr.Session != session {
+			continue
+		}
+		if match, ok := rr.Route.MatchRequest(r); ok {
+			return match
+		}
+	}
+	return nil
+}
+
+// pruneLocked removes expired routes; the caller must hold rs.mu
+func (rs *runtimeRouteStore) pruneLocked() {
+	now := rs.now()
+	rs.routes = slices.DeleteFunc(rs.routes, func(rr *runtimeRoute) bool {
+		return rr.expired(now)
+	})
+}
+
+// runtimeRouteRequest is the body accepted when creating a runtime route.
+// It accepts every route field plus an optional time to live.
+type runtimeRouteRequest struct {
+	config.RouteConfig `yaml:",inline"`
+	ExpiresIn          time.Duration `yaml:"expires_in,omitempty"`
+}
+
+// runtimeRouteResponse describes a runtime route in admin API responses
+type runtimeRouteResponse struct {
+	ID        string     `json:"id"`
+	Method    string     `json:"method"`
+	Path      string     `json:"path"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Session   string     `json:"session,omitempty"`
+}
+
+// newRuntimeRouteResponse converts a runtime route into its API representation
+func newRuntimeRouteResponse(rr *runtimeRoute) runtimeRouteResponse {
+	resp := runtimeRouteResponse{
+		ID:        rr.ID,
+		Method:    rr.Route.Method,
+		Path:      rr.Route.Pattern,
+		CreatedAt: rr.CreatedAt,
+		Session:   rr.Session,
+	}
+	if !rr.ExpiresAt.IsZero() {
+		expiresAt := rr.ExpiresAt
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
+}
+
+// handleListRuntimeRoutes lists the active runtime routes, or those of the
+// session the request is made under
+func (s *Server) handleListRuntimeRoutes(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	routes := s.runtimeRoutes.list()
+	if sess != nil {
+		routes = s.runtimeRoutes.listSession(sess.ID)
+	}
+
+	resp := make([]runtimeRouteResponse, 0, len(routes))
+	for _, rr := range routes {
+		resp = append(resp, newRuntimeRouteResponse(rr))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"routes": resp})
+}
+
+// handleCreateRuntimeRoute validates, compiles and registers a new runtime route,
+// under the session the request is made under, if any. The body may be JSON or
+// YAML and uses the same fields as a configured route.
+func (s *Server) handleCreateRuntimeRoute(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	var req runtimeRouteRequest
+	if err := yaml.Unmarshal(body, &req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse route: %v", err))
+		return
+	}
+
+	if req.ExpiresIn < 0 {
+		writeAdminError(w, http.StatusBadRequest, "expires_in cannot be negative")
+		return
+	}
+
+	if err := req.RouteConfig.Validate(); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	compiler := router.NewCompilerWithEngine(s.current().engine)
+	compiler.UseMiddlewareFactory(middleware.NewFactory(s.logger, s.metrics))
+
+	route, err := compiler.CompileRoute(req.RouteConfig)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rr := s.runtimeRoutes.add(req.RouteConfig, route, req.ExpiresIn, sess)
+
+	s.logger.Info("runtime route created",
+		"id", rr.ID,
+		"method", route.Method,
+		"pattern", route.Pattern,
+		"expires_in", req.ExpiresIn,
+		"session", rr.Session,
+	)
+
+	writeJSON(w, http.StatusCreated, newRuntimeRouteResponse(rr))
+}
+
+// handleDeleteRuntimeRoute removes a single runtime route by ID. Routes can
+// only be removed under the session they were created under, or outside
+// sessions for the routes created outside them.
+func (s *Server) handleDeleteRuntimeRoute(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("id")
+	if !s.runtimeRoutes.remove(id, sessionID(sess)) {
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("runtime route %q not found", id))
+		return
+	}
+
+	s.logger.Info("runtime route deleted", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteAllRuntimeRoutes removes every runtime route, or those of the
+// session the request is made under
+func (s *Server) handleDeleteAllRuntimeRoutes(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	count := s.runtimeRoutes.clearSession(sessionID(sess))
+	s.logger.Info("runtime routes cleared", "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"deleted": count})
+}
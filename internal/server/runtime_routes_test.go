@@ -0,0 +1,225 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Admin_RuntimeRoutes(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/users",
+			Method:   "GET",
+			Template: "configured users",
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	// Create a runtime route overriding a configured one
+	resp, err := ts.makeRequest("POST", "/__admin/routes", strings.NewReader(`{
+		"path": "/users",
+		"method": "GET",
+		"template": "runtime users"
+	}`), map[string]string{"Content-Type": "application/json"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", resp.StatusCode, body)
+	}
+
+	var created runtimeRouteResponse
+	if err := json.Unmarshal([]byte(body), &created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.Method != "GET" || created.Path != "/users" {
+		t.Errorf("Unexpected created route: %+v", created)
+	}
+	if created.ExpiresAt != nil {
+		t.Errorf("Expected no expiry, got %v", created.ExpiresAt)
+	}
+
+	// The runtime route takes precedence
+	resp, err = ts.makeRequest("GET", "/users", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "runtime users" {
+		t.Errorf("Expected runtime route response, got %q", body)
+	}
+
+	// Listing includes the runtime route
+	resp, err = ts.makeRequest("GET", "/__admin/routes", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var listed struct {
+		Routes []runtimeRouteResponse `json:"routes"`
+	}
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(listed.Routes) != 1 || listed.Routes[0].ID != created.ID {
+		t.Errorf("Expected listed route %q, got %+v", created.ID, listed.Routes)
+	}
+
+	// Deleting restores the configured route
+	resp, err = ts.makeRequest("DELETE", "/__admin/routes/"+created.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+
+	resp, err = ts.makeRequest("GET", "/users", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "configured users" {
+		t.Errorf("Expected configured route response, got %q", body)
+	}
+
+	// Deleting an unknown route fails
+	resp, err = ts.makeRequest("DELETE", "/__admin/routes/"+created.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Admin_CreateRuntimeRouteErrors(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/", Method: "GET", Template: "root"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name   string
+		body   string
+		errMsg string
+	}{
+		{
+			name:   "invalid yaml",
+			body:   `{"path": `,
+			errMsg: "failed to parse route",
+		},
+		{
+			name:   "missing template",
+			body:   `{"path": "/x", "method": "GET"}`,
+			errMsg: "either 'template' or 'template_file' must be specified",
+		},
+		{
+			name:   "invalid template",
+			body:   `{"path": "/x", "method": "GET", "template": "{{ .Broken"}`,
+			errMsg: "failed to compile template",
+		},
+		{
+			name:   "negative expiry",
+			body:   `{"path": "/x", "method": "GET", "template": "x", "expires_in": "-1m"}`,
+			errMsg: "expires_in cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest("POST", "/__admin/routes", strings.NewReader(tt.body), nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", resp.StatusCode)
+			}
+			if !strings.Contains(body, tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %q", tt.errMsg, body)
+			}
+		})
+	}
+}
+
+func TestServer_Admin_RuntimeRouteExpiry(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/", Method: "GET", Template: "root"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	now := time.Now()
+	ts.runtimeRoutes.now = func() time.Time { return now }
+
+	resp, err := ts.makeRequest("POST", "/__admin/routes", strings.NewReader(`
+path: /temporary
+method: GET
+template: "still here"
+expires_in: 1h
+`), map[string]string{"Content-Type": "application/yaml"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var created runtimeRouteResponse
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.ExpiresAt == nil || !created.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Expected expiry one hour from now, got %v", created.ExpiresAt)
+	}
+
+	resp, err = ts.makeRequest("GET", "/temporary", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "still here" {
+		t.Errorf("Expected route before expiry, got %q", body)
+	}
+
+	// Move past the expiry time
+	now = now.Add(time.Hour)
+
+	resp, err = ts.makeRequest("GET", "/temporary", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 after expiry, got %d", resp.StatusCode)
+	}
+
+	if routes := ts.runtimeRoutes.list(); len(routes) != 0 {
+		t.Errorf("Expected expired route to be removed, got %d routes", len(routes))
+	}
+}
+
+func TestServer_Admin_UnknownEndpoint(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/", Method: "GET", Template: "root"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("GET", "/__admin/unknown", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(body, "unknown admin endpoint") {
+		t.Errorf("Expected unknown endpoint error, got %q", body)
+	}
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+// scenarioKey identifies the position of one client, or every client, in a
+// route's response sequence
+type scenarioKey struct {
+	Route   string // Route identifier, as "METHOD pattern"
+	Client  string // Client identifier (empty when state is shared by all clients)
+	Session string // Admin session the requests belong to (empty outside sessions)
+}
+
+// scenarioStore tracks how many times each sequenced route has been called.
+// State survives configuration reloads and is cleared through the admin API.
+type scenarioStore struct {
+	mu     sync.Mutex
+	calls  map[scenarioKey]int
+	store  storage.Collection // Where state is persisted
+	logger *slog.Logger
+}
+
+// newScenarioStore creates a scenario store, restoring the state kept in store
+func newScenarioStore(store storage.Collection, logger *slog.Logger) (*scenarioStore, error) {
+	ss := &scenarioStore{calls: make(map[scenarioKey]int), store: store, logger: logger}
+
+	records, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenario state: %w", err)
+	}
+	for _, record := range records {
+		var state scenarioState
+		if err := json.Unmarshal(record.Value, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario state %q: %w", record.Key, err)
+		}
+		ss.calls[scenarioKey{Route: state.Route, Client: state.Client}] = state.Calls
+	}
+
+	return ss, nil
+}
+
+// storageKey returns the key a scenario's state is persisted under
+func (key scenarioKey) storageKey() string {
+	data, _ := json.Marshal([]string{key.Route, key.Client}) // Marshaling strings can't fail
+	return string(data)
+}
+
+// next returns the zero-based call number for key and advances it
+func (ss *scenarioStore) next(key scenarioKey) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	call := ss.calls[key]
+	ss.calls[key] = call + 1
+
+	// The state of sessions goes away with them, so it isn't persisted
+	if key.Session != "" {
+		return call
+	}
+
+	data, err := json.Marshal(scenarioState{Route: key.Route, Client: key.Client, Calls: call + 1})
+	if err == nil {
+		err = ss.store.Put(key.storageKey(), data)
+	}
+	if err != nil {
+		ss.logger.Error("failed to persist scenario state", "route", key.Route, "error", err)
+	}
+	return call
+}
+
+// reset clears the state of the given route, or of every route when route is
+// empty, kept under session, or outside sessions when it's empty, and returns
+// how many entries were removed
+func (ss *scenarioStore) reset(route, session string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	count := 0
+	var removed []string
+	for key := range ss.calls {
+		if key.Session != session || (route != "" && key.Route != route) {
+			continue
+		}
+		delete(ss.calls, key)
+		count++
+		if key.Session == "" {
+			removed = append(removed, key.storageKey())
+		}
+	}
+
+	if err := ss.store.Delete(removed...); err != nil {
+		ss.logger.Error("failed to remove persisted scenario state", "route", route, "error", err)
+	}
+	return count
+}
+
+// resetSession clears the state of the given session and returns how many
+// entries were removed
+func (ss *scenarioStore) resetSession(session string) int {
+	return ss.reset("", session)
+}
+
+// scenarioState represents the JSON form of one scenario entry
+type scenarioState struct {
+	Route   string `json:"route"`
+	Client  string `json:"client,omitempty"`
+	Session string `json:"session,omitempty"`
+	Calls   int    `json:"calls"`
+}
+
+// list returns the current state sorted by route and client
+func (ss *scenarioStore) list() []scenarioState {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	states := make([]scenarioState, 0, len(ss.calls))
+	for key, calls := range ss.calls {
+		states = append(states, scenarioState{Route: key.Route, Client: key.Client, Session: key.Session, Calls: calls})
+	}
+
+	slices.SortFunc(states, func(a, b scenarioState) int {
+		if c := strings.Compare(a.Route, b.Route); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Client, b.Client); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Session, b.Session)
+	})
+	return states
+}
+
+// scenarioRouteID returns the identifier used for a route in scenario state
+func scenarioRouteID(route *router.Route) string {
+	return route.Method + " " + route.Pattern
+}
+
+// nextSequenceResponse advances the route's sequence for the requesting client
+// and returns the response to serve. Requests of a session advance a sequence
+// of their own.
+func (s *Server) nextSequenceResponse(route *router.Route, r *http.Request) *router.Response {
+	key := scenarioKey{Route: scenarioRouteID(route)}
+	if route.Sequence.PerClient {
+		key.Client = sequenceClientID(route.Sequence, r)
+	}
+	if sess := s.requestSession(r); sess != nil {
+		key.Session = sess.ID
+	}
+
+	return route.ResponseAt(s.scenarios.next(key))
+}
+
+// sequenceClientID identifies the client of a request, using the configured
+// header when present and falling back to the remote IP address
+func sequenceClientID(seq *router.Sequence, r *http.Request) string {
+	if seq.ClientHeader != "" {
+		if value := r.Header.Get(seq.ClientHeader); value != "" {
+			return value
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// handleListScenarios lists the current position of every sequenced route
+func (s *Server) handleListScenarios(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"scenarios": s.scenarios.list()})
+}
+
+// handleResetScenarios resets sequenced routes back to their first response,
+// for the session the request is made under or outside sessions. The optional
+// "route" query parameter, as "METHOD pattern", limits the reset to a single
+// route.
+func (s *Server) handleResetScenarios(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	route := r.URL.Query().Get("route")
+	count := s.scenarios.reset(route, sessionID(sess))
+
+	s.logger.Info("scenarios reset", "route", route, "session", sessionID(sess), "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"reset": count})
+}
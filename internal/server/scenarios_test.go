@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+func TestServer_Integration_SequencedResponses(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/jobs/1",
+			Method: "GET",
+			Responses: []config.ResponseConfig{
+				{Status: http.StatusAccepted, Template: `{"status":"pending"}`},
+				{Template: `{"status":"done"}`},
+			},
+			Sequence: &config.SequenceConfig{},
+		},
+		{
+			Path:   "/tokens",
+			Method: "POST",
+			Responses: []config.ResponseConfig{
+				{Template: "first"},
+				{Template: "second"},
+			},
+			Sequence: &config.SequenceConfig{Per: "client", ClientHeader: "X-Client-ID", Loop: true},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	call := func(method, path string, headers map[string]string) (int, string) {
+		t.Helper()
+		resp, err := ts.makeRequest(method, path, nil, headers)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body := readResponseBody(t, resp)
+		return resp.StatusCode, body
+	}
+
+	// Shared sequence: pending first, then done for every later call
+	expected := []struct {
+		status int
+		body   string
+	}{
+		{http.StatusAccepted, `{"status":"pending"}`},
+		{http.StatusOK, `{"status":"done"}`},
+		{http.StatusOK, `{"status":"done"}`},
+	}
+	for i, want := range expected {
+		status, body := call("GET", "/jobs/1", nil)
+		if status != want.status || body != want.body {
+			t.Errorf("Call %d: expected %d %q, got %d %q", i+1, want.status, want.body, status, body)
+		}
+	}
+
+	// Per-client sequences advance independently and loop
+	alice := map[string]string{"X-Client-ID": "alice"}
+	bob := map[string]string{"X-Client-ID": "bob"}
+	for i, tt := range []struct {
+		headers map[string]string
+		want    string
+	}{
+		{alice, "first"},
+		{alice, "second"},
+		{bob, "first"},
+		{alice, "first"},
+		{bob, "second"},
+	} {
+		if _, body := call("POST", "/tokens", tt.headers); body != tt.want {
+			t.Errorf("Call %d for %s: expected %q, got %q", i+1, tt.headers["X-Client-ID"], tt.want, body)
+		}
+	}
+
+	// The admin API lists the state of every sequence
+	status, body := call("GET", "/__admin/scenarios", nil)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	var listed struct {
+		Scenarios []scenarioState `json:"scenarios"`
+	}
+	if err := json.Unmarshal([]byte(body), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expectedStates := []scenarioState{
+		{Route: "GET /jobs/1", Calls: 3},
+		{Route: "POST /tokens", Client: "alice", Calls: 3},
+		{Route: "POST /tokens", Client: "bob", Calls: 2},
+	}
+	if len(listed.Scenarios) != len(expectedStates) {
+		t.Fatalf("Expected %d scenarios, got %+v", len(expectedStates), listed.Scenarios)
+	}
+	for i, want := range expectedStates {
+		if listed.Scenarios[i] != want {
+			t.Errorf("Expected scenario %+v, got %+v", want, listed.Scenarios[i])
+		}
+	}
+
+	// Resetting a single route only affects that route
+	status, body = call("DELETE", "/__admin/scenarios?route=GET+/jobs/1", nil)
+	if status != http.StatusOK || body != "{\n  \"reset\": 1\n}\n" {
+		t.Errorf("Expected one reset entry, got %d %q", status, body)
+	}
+	if status, _ := call("GET", "/jobs/1", nil); status != http.StatusAccepted {
+		t.Errorf("Expected sequence to restart with 202, got %d", status)
+	}
+	if _, body := call("POST", "/tokens", bob); body != "first" {
+		t.Errorf("Expected bob's looping sequence to continue, got %q", body)
+	}
+
+	// Resetting everything restarts every sequence
+	if status, body := call("DELETE", "/__admin/scenarios", nil); status != http.StatusOK || body != "{\n  \"reset\": 3\n}\n" {
+		t.Errorf("Expected three reset entries, got %d %q", status, body)
+	}
+	if _, body := call("POST", "/tokens", alice); body != "first" {
+		t.Errorf("Expected alice's sequence to restart, got %q", body)
+	}
+}
+
+func TestSequenceClientID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.RemoteAddr = "192.0.2.10:52311"
+
+	seq := &router.Sequence{PerClient: true, ClientHeader: "X-Client-ID"}
+	if got := sequenceClientID(seq, req); got != "192.0.2.10" {
+		t.Errorf("Expected remote IP fallback, got %q", got)
+	}
+
+	req.Header.Set("X-Client-ID", "alice")
+	if got := sequenceClientID(seq, req); got != "alice" {
+		t.Errorf("Expected header value, got %q", got)
+	}
+}
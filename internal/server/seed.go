@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// headerSeed is the request header overriding the seed of random and fake
+// data functions for a single request
+const headerSeed = "X-Mockingjay-Seed"
+
+// seedContext seeds the random and fake data functions of the templates
+// rendered for a request. Deterministic routes are always seeded, and other
+// routes when the configuration sets template.fake_seed or the request sends
+// an X-Mockingjay-Seed header, which takes precedence.
+func (rt *routing) seedContext(ctx *templatepkg.TemplateContext, route *router.Route, r *http.Request) {
+	seed := r.Header.Get(headerSeed)
+	if seed == "" {
+		seed = rt.fakeSeed
+	}
+
+	key := route.Method + " " + route.Pattern
+	if route.Deterministic != nil {
+		key = route.Deterministic.Key
+	} else if seed == "" {
+		return
+	}
+
+	if seed != "" {
+		key += " " + seed
+	}
+	ctx.Seed(key)
+}
+
+// fakeSeed returns the configured seed of random and fake data functions as
+// mixed into the seed of requests, or an empty string when it's unset
+func fakeSeed(tc config.TemplateConfig) string {
+	if tc.FakeSeed == nil {
+		return ""
+	}
+	return strconv.FormatInt(*tc.FakeSeed, 10)
+}
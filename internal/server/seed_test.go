@@ -0,0 +1,85 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_FakeSeed(t *testing.T) {
+	template := `{{ uuidv4 }} {{ fakeName }} {{ randInt 0 1000000 }}`
+	newServer := func(seed *int64) *TestServer {
+		cfg := createTestConfig([]config.RouteConfig{
+			{Path: "/users", Method: "GET", Template: template},
+			{Path: "/fixed", Method: "GET", Template: template, Deterministic: &config.DeterministicConfig{}},
+		})
+		cfg.Template.FakeSeed = seed
+		return NewTestServer(t, cfg)
+	}
+	call := func(ts *TestServer, path, seed string) string {
+		t.Helper()
+		var headers map[string]string
+		if seed != "" {
+			headers = map[string]string{headerSeed: seed}
+		}
+		resp, err := ts.makeRequest("GET", path, nil, headers)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+
+	seed, otherSeed := int64(42), int64(7)
+	seeded := newServer(&seed)
+	reseeded := newServer(&seed)
+	otherSeeded := newServer(&otherSeed)
+	unseeded := newServer(nil)
+
+	t.Run("configured seed repeats responses", func(t *testing.T) {
+		first := call(seeded, "/users", "")
+		if again := call(seeded, "/users", ""); again != first {
+			t.Errorf("Expected the same response twice, got %q and %q", first, again)
+		}
+		if other := call(reseeded, "/users", ""); other != first {
+			t.Errorf("Expected servers with the same seed to respond alike, got %q and %q", first, other)
+		}
+		if other := call(otherSeeded, "/users", ""); other == first {
+			t.Errorf("Expected another seed to give other values, got %q for both", first)
+		}
+	})
+
+	t.Run("unseeded routes stay random", func(t *testing.T) {
+		if first, again := call(unseeded, "/users", ""), call(unseeded, "/users", ""); first == again {
+			t.Errorf("Expected random responses without a seed, got %q twice", first)
+		}
+	})
+
+	t.Run("header seeds a single request", func(t *testing.T) {
+		first := call(unseeded, "/users", "abc")
+		if again := call(unseeded, "/users", "abc"); again != first {
+			t.Errorf("Expected the same response for the same seed header, got %q and %q", first, again)
+		}
+		if other := call(unseeded, "/users", "xyz"); other == first {
+			t.Errorf("Expected another seed header to give other values, got %q for both", first)
+		}
+	})
+
+	t.Run("header takes precedence over the configured seed", func(t *testing.T) {
+		if configured, header := call(seeded, "/users", "42"), call(unseeded, "/users", "42"); configured != header {
+			t.Errorf("Expected the header to replace the configured seed, got %q and %q", configured, header)
+		}
+		if configured, header := call(seeded, "/users", ""), call(seeded, "/users", "other"); configured == header {
+			t.Errorf("Expected the header to change the values, got %q for both", configured)
+		}
+	})
+
+	t.Run("deterministic routes mix in the seed", func(t *testing.T) {
+		plain := call(unseeded, "/fixed", "")
+		if again := call(unseeded, "/fixed", ""); again != plain {
+			t.Errorf("Expected deterministic responses to repeat, got %q and %q", plain, again)
+		}
+		if withHeader := call(unseeded, "/fixed", "abc"); withHeader == plain {
+			t.Errorf("Expected a seed header to give other values, got %q for both", plain)
+		}
+	})
+}
@@ -4,36 +4,58 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/metrics"
 	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
 	"github.com/patrickdappollonio/mockingjay/internal/router"
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
 	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
 )
 
 // Server represents the HTTP server with its routes and configuration
 type Server struct {
 	appVersion      string
-	routes          []*router.Route
-	engine          *templatepkg.Engine
 	logger          *slog.Logger
 	httpServer      *http.Server
-	configFile      string        // Path to config file for hot-reload
-	mu              sync.RWMutex  // Protects routes and engine during reload
-	startTime       time.Time     // Server start time for uptime calculation
-	middlewareChain http.Handler  // Middleware chain handler
-	shutdownTimeout time.Duration // Configurable shutdown timeout
+	routing         atomic.Pointer[routing] // Routes, engine and middleware new requests are served with
+	reloadMu        sync.Mutex              // Serializes configuration reloads
+	startTime       time.Time               // Server start time for uptime calculation
+	shutdownTimeout time.Duration           // Configurable shutdown timeout
+	timeouts        TimeoutsSummary         // Timeouts in use, which only change on restart
+	adminMux        *http.ServeMux          // Router for the admin API
+	runtimeRoutes   *runtimeRouteStore      // Routes created through the admin API
+	sessions        *sessionStore           // Admin sessions isolating the runtime state of parallel test suites
+	scenarios       *scenarioStore          // Positions of sequenced routes
+	recordings      *recordingStore         // Upstream responses captured by proxy routes
+	tokens          *tokenStore             // Tokens minted from the token bucket
+	transactions    *transactionStore       // States of multi-step transactions
+	idempotency     *idempotencyStore       // Responses remembered by idempotency key
+	dependencies    *dependencyStore        // Synthetic dependencies reported by the health check
+	calls           *callStore              // Calls of every route, for checking expectations
+	journal         *journal                // Requests served by the mock
+	websockets      *webSocketConns         // Open WebSocket connections, closed on shutdown
+	metrics         *metrics.Registry       // Counters describing the server's activity
+	storage         storage.Driver          // Where captured data is persisted
+	storageConfig   config.StorageConfig    // Storage settings in use, which only change on restart
+	includes        *includeRefresher       // Remote includes checked for changes while running
+	logLevel        *logLevelSwitch         // Level of the logger, when it can be changed at runtime
+	recycler        *recycler               // Stops the server after its maximum uptime or requests
 }
 
 // NewServer creates a new server instance with compiled routes
-func NewServer(cfg *config.Config, configFile, addr string, logger *slog.Logger, appVersion string) (*Server, error) {
+func NewServer(cfg *config.Config, configPaths []string, addr string, logger *slog.Logger, appVersion string) (*Server, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
@@ -42,45 +64,157 @@ func NewServer(cfg *config.Config, configFile, addr string, logger *slog.Logger,
 	}
 
 	// Create router compiler and compile routes
-	compiler := router.NewCompilerWithConfig(cfg)
+	registry := metrics.NewRegistry()
+	compiler, err := router.NewCompilerWithConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile template partials: %w", err)
+	}
+	compiler.UseMiddlewareFactory(middleware.NewFactory(logger, registry))
 	routes, err := compiler.CompileRoutes(cfg.Routes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile routes: %w", err)
 	}
+	fallbackProxy, err := router.CompileFallbackProxy(cfg.FallbackProxy)
+	if err != nil {
+		return nil, err
+	}
+	fallback, err := compiler.CompileFallback(cfg.Fallback)
+	if err != nil {
+		return nil, err
+	}
+	errorPages, err := compiler.CompileErrorPages(cfg.Server.ErrorPages)
+	if err != nil {
+		return nil, err
+	}
+	grpcMethods, err := compiler.CompileGRPC(cfg.GRPC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile gRPC methods: %w", err)
+	}
+	responseHeaders, err := compiler.CompileResponseHeaders(cfg.ResponseHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile response headers: %w", err)
+	}
+	adminTokens, err := newAdminTokens(cfg.Admin)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get timeout configuration with defaults
 	timeouts := cfg.Server.Timeouts.GetWithDefaults()
 
 	server := &Server{
-		routes:          routes,
-		engine:          compiler.GetEngine(),
+		appVersion:      appVersion,
 		logger:          logger,
-		configFile:      configFile,
 		startTime:       time.Now(),
 		shutdownTimeout: timeouts.Shutdown,
+		timeouts:        summarizeTimeouts(cfg.Server.Timeouts),
+		runtimeRoutes:   newRuntimeRouteStore(),
+		sessions:        newSessionStore(),
+		tokens:          newTokenStore(cfg.TokenBucket),
+		transactions:    newTransactionStore(cfg.Transactions),
+		idempotency:     newIdempotencyStore(),
+		dependencies:    newDependencyStore(cfg.Health),
+		calls:           newCallStore(),
+		websockets:      newWebSocketConns(),
+		metrics:         registry,
+		includes:        newIncludeRefresher(cfg),
+		logLevel:        &logLevelSwitch{},
+		recycler:        newRecycler(cfg.Server),
+	}
+	server.adminMux = server.newAdminMux()
+	server.logConfigWarnings(cfg)
+
+	// Create the stores of captured data on the configured storage
+	if err := server.openStores(cfg); err != nil {
+		return nil, err
 	}
 
 	// Create middleware chain
-	middlewareFactory := middleware.NewFactory(logger)
+	middlewareFactory := middleware.NewFactory(logger, server.metrics)
 	chain, err := middlewareFactory.CreateChain(cfg.Middleware)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create middleware chain: %w", err)
 	}
-	server.middlewareChain = chain.Then(server)
 
-	// Create HTTP server with middleware chain as handler
+	// Create the servers of all tenants hosted alongside this one
+	tenants, err := newTenants(cfg, logger, appVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	server.routing.Store(&routing{
+		routes:          routes,
+		index:           router.NewIndex(routes, cfg.Server.MatchesSpecific()),
+		engine:          compiler.GetEngine(),
+		middlewareChain: problem.Middleware(cfg.Errors.GetFormat(), chain.Then(server)),
+		devMode:         cfg.Server.DevMode,
+		clockSkew:       cfg.Server.ClockSkew,
+		clockTime:       clockTime(cfg.Server),
+		strictHTTP:      cfg.Server.StrictHTTP,
+		headerVars:      cfg.Server.HeaderVars,
+		fakeSeed:        fakeSeed(cfg.Template),
+		bodyLimit:       cfg.Server.GetBodyLimit(),
+		contentType:     cfg.Server.DefaultContentType,
+		serverHeaders:   cfg.Server.Headers,
+		middlewares:     cfg.Middleware,
+		fallbackProxy:   fallbackProxy,
+		fallback:        fallback,
+		errorPages:      errorPages,
+		grpcMethods:     grpcMethods,
+		watchFiles:      cfg.WatchFiles(),
+		tenants:         tenants,
+		adminTokens:     adminTokens,
+		headerCasing:    cfg.HeaderCasing(),
+		responseHeaders: responseHeaders,
+		configPaths:     configPaths,
+	})
+
+	// Create HTTP server dispatching to tenants or the middleware chain
 	server.httpServer = &http.Server{
 		Addr:              addr,
-		Handler:           server.middlewareChain,
+		Handler:           http.HandlerFunc(server.dispatch),
 		ReadTimeout:       timeouts.Read,
 		WriteTimeout:      timeouts.Write,
 		IdleTimeout:       timeouts.Idle,
 		ReadHeaderTimeout: timeouts.ReadHeader,
+		Protocols:         new(http.Protocols),
+	}
+
+	// Serve HTTP/2 alongside HTTP/1, also without TLS, as gRPC clients need it
+	server.httpServer.Protocols.SetHTTP1(true)
+	server.httpServer.Protocols.SetHTTP2(true)
+	server.httpServer.Protocols.SetUnencryptedHTTP2(true)
+
+	// Serve HTTPS when TLS is configured, which only changes on restart
+	if cfg.Server.TLS != nil {
+		if server.httpServer.TLSConfig, err = newTLSConfig(cfg.Server.TLS); err != nil {
+			return nil, err
+		}
 	}
 
 	return server, nil
 }
 
+// handler returns the middleware chain wrapping this server
+func (s *Server) handler() http.Handler {
+	return http.HandlerFunc(s.serveCurrent)
+}
+
+// dispatch routes requests addressed to a tenant prefix to that tenant, and
+// everything else through this server's own middleware chain
+func (s *Server) dispatch(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/health" {
+		s.recycler.countRequest()
+	}
+
+	if t := s.current().findTenant(r.URL.Path); t != nil {
+		t.ServeHTTP(w, r)
+		return
+	}
+
+	s.serveCurrent(w, r)
+}
+
 // ServeHTTP implements the http.Handler interface - main request handler
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
@@ -92,30 +226,340 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Acquire read lock to ensure thread-safe access to routes and engine
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	// Handle the OpenAPI document of the configured routes
+	if r.URL.Path == openAPIPath && r.Method == http.MethodGet {
+		s.handleOpenAPI(w, r)
+		s.logRequest(r, 200, time.Since(start), nil)
+		return
+	}
+
+	// Handle the admin API
+	if isAdminPath(r.URL.Path) {
+		s.adminMux.ServeHTTP(w, r)
+		return
+	}
+
+	// Serve the request with the routing it came in with, even if the
+	// configuration is reloaded meanwhile
+	rt := s.routingFor(r)
 
+	// Reject bodies declared larger than the limit, and cap the others
+	if !limitRequestBody(w, r, rt.bodyLimit) {
+		s.logRequest(r, http.StatusRequestEntityTooLarge, time.Since(start), nil)
+		return
+	}
+
+	// Decompress bodies sent with a Content-Encoding
+	if status := decompressRequestBody(w, r, rt.bodyLimit); status != 0 {
+		s.logRequest(r, status, time.Since(start), nil)
+		return
+	}
+
+	// Capture the request body before it's consumed, then record the request
+	// in the journal once it has been served
+	journalBody, truncated := captureRequestBody(r, s.journal.bodyLimit())
+	rw := middleware.NewResponseWriter(w)
+
+	// Serve gRPC calls with the mocked methods, and everything else with routes
+	var route *router.Route
+	if isGRPCRequest(r) {
+		s.serveGRPC(rt, rw, r, start)
+	} else {
+		route = s.routeRequest(rt, rw, r, start)
+	}
+	s.journal.add(newJournalEntry(r, journalBody, truncated, rw.Status(), route, start))
+}
+
+// routeRequest serves a request with the matching route of rt and returns
+// that route, or nil if no route matched
+func (s *Server) routeRequest(rt *routing, w http.ResponseWriter, r *http.Request, start time.Time) *router.Route {
 	// Find matching route
-	routeMatch := s.findMatchingRoute(r)
+	routeMatch := s.findMatchingRoute(rt, r)
+	if routeMatch == nil && rt.fallbackProxy != nil {
+		// Pass requests the mock doesn't cover through to the real upstream
+		status := s.serveProxy(w, r, rt.fallbackProxy)
+		s.logRequest(r, status, time.Since(start), nil)
+		return nil
+	}
+	if routeMatch == nil && rt.fallback != nil {
+		// Answer requests the mock doesn't cover with the configured
+		// fallback response, still reporting them as matching no route
+		s.serveRoute(rt, w, r, &router.RouteMatch{Route: rt.fallback, Params: map[string]string{}}, start)
+		return nil
+	}
 	if routeMatch == nil {
+		setDateHeader(w, rt.clockFor(nil))
 		s.handleNotFound(w, r)
 		s.logRequest(r, 404, time.Since(start), nil)
+		return nil
+	}
+
+	// Count the call, for checking the expectations of routes
+	s.calls.record(scenarioRouteID(routeMatch.Route), sessionID(s.requestSession(r)))
+
+	s.serveRoute(rt, w, r, routeMatch, start)
+	return routeMatch.Route
+}
+
+// serveRoute serves a request with the route of routeMatch, through the
+// route's own middleware if it has any
+func (s *Server) serveRoute(rt *routing, w http.ResponseWriter, r *http.Request, routeMatch *router.RouteMatch, start time.Time) {
+	if wrap := routeMatch.Route.Middleware; wrap != nil {
+		wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.serveMatchedRoute(rt, w, r, routeMatch, start)
+		})).ServeHTTP(w, r)
+		return
+	}
+	s.serveMatchedRoute(rt, w, r, routeMatch, start)
+}
+
+// serveMatchedRoute serves a request with the route of routeMatch
+func (s *Server) serveMatchedRoute(rt *routing, w http.ResponseWriter, r *http.Request, routeMatch *router.RouteMatch, start time.Time) {
+	// Signal deprecated routes to clients, and warn they're still being called
+	if deprecation := routeMatch.Route.Deprecation; deprecation != nil {
+		deprecation.SetHeaders(w.Header())
+		s.logger.Warn("deprecated route called",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", routeMatch.Route.Pattern,
+			"sunset", w.Header().Get("Sunset"),
+			"remote_addr", r.RemoteAddr,
+		)
+	}
+
+	// Track artificial delays and faults applied while serving this request
+	inj := &injections{}
+
+	// Wait in line for a slot of routes serving a limited number of requests at once
+	if routeMatch.Route.Concurrency != nil {
+		if status, ok := s.acquireSlot(rt, w, r, routeMatch.Route, inj, start); !ok {
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+		defer s.releaseSlot(routeMatch.Route)
+	}
+
+	// Replay the response remembered for a retried idempotency key, or
+	// remember the one this request gets
+	if routeMatch.Route.Idempotency != nil {
+		idempotent, status, ok := s.beginIdempotent(w, r, routeMatch.Route)
+		if !ok {
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+		if idempotent != nil {
+			w = idempotent
+			defer s.finishIdempotent(idempotent, r)
+		}
+	}
+
+	// Apply the route's artificial delay, giving up if the request is cancelled
+	if delay := routeMatch.Route.Delay.Duration(); delay > 0 {
+		if err := sleepContext(r.Context(), delay); err != nil {
+			status := s.handleRequestTimeout(w, r, time.Since(start))
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+		inj.Delay += delay
+	}
+
+	// Forward proxied routes to their upstream
+	if routeMatch.Route.Proxy != nil {
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+		status := s.serveProxy(w, r, routeMatch.Route.Proxy)
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
+		return
+	}
+
+	// Serve the sub-requests of batch endpoints with the other routes
+	if routeMatch.Route.Batch != nil {
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+		status := s.serveBatch(rt, w, r, routeMatch.Route.Batch)
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
+		return
+	}
+
+	// Freeze the clock the response is served with, at the time the request
+	// asks for if it sends one
+	clock, err := rt.requestClock(routeMatch.Route, r)
+	if err != nil {
+		s.handleInvalidTime(w, r, err)
+		s.logRequest(r, http.StatusBadRequest, time.Since(start), routeMatch.Route)
 		return
 	}
+	setDateHeader(w, clock)
 
 	// Build template context
-	ctx, err := s.engine.BuildTemplateContext(r, routeMatch.Params)
+	ctx, err := rt.engine.BuildTemplateContext(r, routeMatch.Params)
+	if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+		middleware.WritePayloadTooLarge(w, r, tooLarge.Limit)
+		s.logRequest(r, http.StatusRequestEntityTooLarge, time.Since(start), routeMatch.Route)
+		return
+	}
 	if err != nil {
 		s.handleServerError(w, r, fmt.Errorf("failed to build template context: %w", err))
 		s.logRequest(r, 500, time.Since(start), routeMatch.Route)
 		return
 	}
+	session := sessionID(s.requestSession(r))
+	ctx.Route = routeMatch.Route.Info
+	ctx.Tokens = s.tokens.forSession(session)
+	ctx.Logger = s.logger
+	ctx.Clock = clock
+	if rt.headerVars {
+		ctx.Vars = templatepkg.HeaderVars(r.Header)
+	}
+	rt.seedContext(ctx, routeMatch.Route, r)
+
+	// Reject requests without a valid token when the route requires one
+	if requirement := routeMatch.Route.RequireToken; requirement != nil {
+		token := requirement.Extract(ctx.Headers, ctx.Query, ctx.Body)
+		if err := s.tokens.check(requirement.Name, token, session, requirement.Revoke); err != nil {
+			s.handleUnauthorized(w, r, requirement, err)
+			s.logRequest(r, 401, time.Since(start), routeMatch.Route)
+			return
+		}
+	}
 
-	// Render custom response headers
-	if err := s.renderResponseHeaders(w, routeMatch.Route, ctx); err != nil {
-		s.handleTemplateError(w, r, fmt.Errorf("failed to render response headers: %w", err))
-		s.logRequest(r, 500, time.Since(start), routeMatch.Route)
+	// Move the route's transaction to its next state, rejecting transitions
+	// its current state doesn't allow
+	if step := routeMatch.Route.Transaction; step != nil {
+		info, err := s.performTransition(rt, step, ctx, session)
+		switch {
+		case errors.Is(err, errInvalidTransition):
+			s.handleInvalidTransition(w, r, err)
+			s.logRequest(r, 409, time.Since(start), routeMatch.Route)
+			return
+		case err != nil:
+			s.handleServerError(w, r, err)
+			s.logRequest(r, 500, time.Since(start), routeMatch.Route)
+			return
+		}
+		ctx.Transaction = info
+	}
+
+	// Upgrade WebSocket routes, whose session goes on after the request is
+	// served, sending the route's headers with the handshake
+	if routeMatch.Route.WebSocket != nil {
+		for _, headers := range []map[string]*template.Template{rt.responseHeaders, routeMatch.Route.ResponseHeaders} {
+			if err := s.renderResponseHeaders(rt, w, r, headers, ctx); err != nil {
+				status := s.handleResponseHeadersError(w, r, err, start)
+				s.logRequest(r, status, time.Since(start), routeMatch.Route)
+				return
+			}
+		}
+		setCookies(w, ctx.Response)
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+		status := s.serveWebSocket(rt, w, r, routeMatch.Route, ctx)
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
+		return
+	}
+
+	// Pick the body template and default status, which come from one of
+	// the route's alternative responses or variants when it defines any
+	tmpl, defaultStatus := routeMatch.Route.Tmpl, http.StatusOK
+	headerTemplates := []map[string]*template.Template{rt.responseHeaders, routeMatch.Route.ResponseHeaders}
+	if len(routeMatch.Route.Responses) > 0 {
+		var selected *router.Response
+		if routeMatch.Route.Sequence != nil {
+			selected = s.nextSequenceResponse(routeMatch.Route, r)
+		} else {
+			selected = routeMatch.Route.SelectResponse(ctx.Headers, ctx.Query, ctx.Body)
+		}
+		if selected == nil {
+			// Every response is conditional and none matched the request
+			s.handleNotFound(w, r)
+			s.logRequest(r, 404, time.Since(start), routeMatch.Route)
+			return
+		}
+
+		tmpl = selected.Tmpl
+		if selected.Status != 0 {
+			defaultStatus = selected.Status
+		}
+		headerTemplates = append(headerTemplates, selected.ResponseHeaders)
+	}
+	if variants := routeMatch.Route.Variants; variants != nil {
+		name, selected, err := s.selectVariant(rt, variants, ctx)
+		if err != nil {
+			status := s.handleTemplateError(w, r, fmt.Errorf("failed to render variants selector: %w", err))
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+		inj.Variant = name
+
+		tmpl = selected.Tmpl
+		if selected.Status != 0 {
+			defaultStatus = selected.Status
+		}
+		headerTemplates = append(headerTemplates, selected.ResponseHeaders)
+	}
+
+	// Render custom response headers, letting route-level headers override
+	// the global ones and response-level headers override route-level ones
+	for _, headers := range headerTemplates {
+		if err := s.renderResponseHeaders(rt, w, r, headers, ctx); err != nil {
+			status := s.handleResponseHeadersError(w, r, err, start)
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+	}
+
+	// Serve the files of static directories, with the route's headers and
+	// the cookies they set
+	if static := routeMatch.Route.StaticDir; static != nil {
+		setCookies(w, ctx.Response)
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+		status := s.serveStatic(w, r, static)
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
+		return
+	}
+
+	// Echo the request body, labeled with its content type unless the
+	// route's headers say otherwise
+	if echo := routeMatch.Route.EchoBody; echo != nil {
+		if echo.Status != 0 {
+			defaultStatus = echo.Status
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "" && w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+	}
+
+	// Label body files with their detected content type, unless the route's
+	// headers say otherwise
+	if bodyFile := routeMatch.Route.BodyFile; bodyFile != nil && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", bodyFile.ContentType)
+	}
+
+	// Answer HEAD requests with the headers alone on routes that skip
+	// rendering the body
+	if r.Method == http.MethodHead && !routeMatch.Route.Head.RendersBody() {
+		setCookies(w, ctx.Response)
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+		writeHeadResponse(w, routeMatch.Route.Head, defaultStatus, nil)
+		s.logRequest(r, defaultStatus, time.Since(start), routeMatch.Route)
+		return
+	}
+
+	// Stream the template output as it's rendered for routes asking to,
+	// instead of buffering the whole response
+	if routeMatch.Route.Stream != nil {
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+		status := s.streamTemplate(rt, w, r, routeMatch.Route.Stream, tmpl, ctx, defaultStatus, start)
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
 		return
 	}
 
@@ -131,15 +575,28 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				templateDone <- fmt.Errorf("template execution panicked: %v", recovered)
 			}
 		}()
-		templateDone <- s.engine.ExecuteTemplate(routeMatch.Route.Tmpl, &templateBuffer, ctx)
+		if echo := routeMatch.Route.EchoBody; echo != nil {
+			templateDone <- s.renderEcho(rt, &templateBuffer, echo, ctx)
+			return
+		}
+		if bodyFile := routeMatch.Route.BodyFile; bodyFile != nil {
+			_, err := templateBuffer.Write(bodyFile.Data)
+			templateDone <- err
+			return
+		}
+		if encoding := routeMatch.Route.BodyEncoding; encoding != "" {
+			templateDone <- s.renderEncodedBody(rt, &templateBuffer, encoding, tmpl, ctx)
+			return
+		}
+		templateDone <- rt.engine.ExecuteTemplate(tmpl, &templateBuffer, ctx)
 	}()
 
 	// Wait for template completion or context timeout
 	select {
 	case err = <-templateDone:
 		if err != nil {
-			s.handleTemplateError(w, r, err)
-			s.logRequest(r, 500, time.Since(start), routeMatch.Route)
+			status := s.handleTemplateError(w, r, err)
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
 			return
 		}
 
@@ -153,11 +610,91 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			"remote_addr", r.RemoteAddr,
 		)
 
+		// Fail the request when the rendered body breaks the route's assertions
+		if err := routeMatch.Route.AssertResponse.Check(templateBuffer.Bytes()); err != nil {
+			s.handleServerError(w, r, fmt.Errorf("response assertion failed for route %q: %w", routeMatch.Route.Pattern, err))
+			s.logRequest(r, 500, time.Since(start), routeMatch.Route)
+			return
+		}
+
+		// Roll the route's faults
+		fault := routeMatch.Route.PickFault()
+		if fault != nil {
+			inj.Faults = append(inj.Faults, fault.Type)
+		}
+
+		// Pick how long the response is held back before its first byte
+		firstByteDelay := routeMatch.Route.DelayFirstByte.Duration()
+		inj.Delay += firstByteDelay
+
+		// In dev mode, tell the client which delays and faults were injected
+		if rt.devMode {
+			inj.setHeaders(w.Header())
+		}
+
 		// Template rendered successfully - write the complete response
-		w.WriteHeader(http.StatusOK)
+		// using the status, cookies and content type chosen by the template, if any
+		status := ctx.Response.StatusOr(defaultStatus)
+		setCookies(w, ctx.Response)
+		if contentType := ctx.Response.ContentType(); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		setDefaultContentType(w.Header(), rt.contentType, templateBuffer.Bytes(), false)
+		if fault != nil {
+			// Checksums describe the whole body, so clients can tell when
+			// it's cut short
+			if fault.Type == config.FaultTruncate || fault.Type == config.FaultSlowBody {
+				setChecksumHeaders(w.Header(), routeMatch.Route.Checksums, templateBuffer.Bytes())
+			}
+			status = s.writeFault(w, r, fault, status, templateBuffer.Bytes())
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+
+		// In strict HTTP mode, fix or reject responses breaking basic HTTP rules
+		body, err := s.enforceHTTPRules(rt, w, r, status, templateBuffer.Bytes())
+		if err != nil {
+			s.handleServerError(w, r, fmt.Errorf("strict HTTP: %w", err))
+			s.logRequest(r, 500, time.Since(start), routeMatch.Route)
+			return
+		}
 
-		// Write the buffered content to the response
-		_, err = w.Write(templateBuffer.Bytes())
+		// Compress the body, breaking content negotiation if the route asks to
+		body, err = compressResponse(w, r, routeMatch.Route.Compression, body)
+		if err != nil {
+			s.handleServerError(w, r, fmt.Errorf("failed to compress response: %w", err))
+			s.logRequest(r, 500, time.Since(start), routeMatch.Route)
+			return
+		}
+
+		// Send the checksums of the body as it's written
+		setChecksumHeaders(w.Header(), routeMatch.Route.Checksums, body)
+
+		// Hold the response back before its first byte, giving up if the
+		// request is cancelled
+		if firstByteDelay > 0 {
+			if err := sleepContext(r.Context(), firstByteDelay); err != nil {
+				status := s.handleRequestTimeout(w, r, time.Since(start))
+				s.logRequest(r, status, time.Since(start), routeMatch.Route)
+				return
+			}
+		}
+
+		// Answer HEAD requests without the body
+		if r.Method == http.MethodHead {
+			writeHeadResponse(w, routeMatch.Route.Head, status, body)
+			s.logRequest(r, status, time.Since(start), routeMatch.Route)
+			return
+		}
+		w.WriteHeader(status)
+
+		// Write the buffered content to the response, a line at a time for
+		// routes pausing between chunks
+		if delay := routeMatch.Route.DelayBetweenChunks; delay != nil {
+			err = writeChunks(w, r, body, delay)
+		} else {
+			_, err = w.Write(body)
+		}
 		if err != nil {
 			// Log write error, but don't try to send another response as headers are already sent
 			s.logger.Error("failed to write template response",
@@ -170,6 +707,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
+
 	case <-r.Context().Done():
 		// Template execution was cancelled due to timeout
 		s.logger.Warn("request timeout - terminating",
@@ -181,11 +720,9 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		)
 
 		// Send timeout response immediately - don't wait for template completion
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		w.WriteHeader(http.StatusRequestTimeout)
-		fmt.Fprintf(w, "408 Request Timeout\n\nThe request exceeded the configured timeout and was terminated.\nTimeout occurred after: %s", time.Since(start))
+		status := s.handleRequestTimeout(w, r, time.Since(start))
 
-		s.logRequest(r, 408, time.Since(start), routeMatch.Route)
+		s.logRequest(r, status, time.Since(start), routeMatch.Route)
 
 		// Don't wait for template completion - let it finish in background
 		go func() {
@@ -193,32 +730,55 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}()
 		return
 	}
-
-	s.logRequest(r, 200, time.Since(start), routeMatch.Route)
 }
 
-// findMatchingRoute iterates through routes to find the first match
-func (s *Server) findMatchingRoute(r *http.Request) *router.RouteMatch {
-	for _, route := range s.routes {
+// findMatchingRoute iterates through the routes of rt to find the first
+// match. Runtime routes created through the admin API are checked first, and
+// HEAD requests fall back to the route answering GET.
+func (s *Server) findMatchingRoute(rt *routing, r *http.Request) *router.RouteMatch {
+	if match := s.runtimeRoutes.match(r); match != nil {
+		return match
+	}
+
+	for _, route := range rt.index.Candidates(r.Method, r.URL.Path) {
 		if match, ok := route.MatchRequest(r); ok {
 			return match
 		}
 	}
+
+	// Serve HEAD requests no route handles with the GET route of the path
+	if r.Method == http.MethodHead {
+		return s.findMatchingRoute(rt, headRequest(r))
+	}
 	return nil
 }
 
 // handleNotFound handles 404 errors
 func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, "404 Not Found: no route matches %s %s", r.Method, r.URL.Path)
+	detail := fmt.Sprintf("no route matches %s %s", r.Method, r.URL.Path)
+	problem.Write(w, r, http.StatusNotFound, problem.CodeRouteNotFound, detail, "404 Not Found: "+detail)
+}
+
+// limitRequestBody caps the request body at limit, so reading past it
+// fails. Requests declaring a larger Content-Length are answered with a 413
+// right away, and false is returned.
+func limitRequestBody(w http.ResponseWriter, r *http.Request, limit int64) bool {
+	if r.ContentLength > limit {
+		middleware.WritePayloadTooLarge(w, r, limit)
+		return false
+	}
+
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	return true
 }
 
 // handleServerError handles 500 errors
 func (s *Server) handleServerError(w http.ResponseWriter, r *http.Request, err error) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
-	fmt.Fprintln(w, "500 Internal Server Error")
+	problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternalError,
+		"the server encountered an error while processing the request",
+		"500 Internal Server Error\n")
 
 	s.logger.Error("server error",
 		"method", r.Method,
@@ -227,47 +787,122 @@ func (s *Server) handleServerError(w http.ResponseWriter, r *http.Request, err e
 	)
 }
 
-// handleTemplateError handles template execution errors
-func (s *Server) handleTemplateError(w http.ResponseWriter, r *http.Request, err error) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
-	fmt.Fprintln(w, "500 Internal Server Error: response template cannot be rendered due to an error in the template")
+// handleUnauthorized handles requests to token-protected routes without a valid token
+func (s *Server) handleUnauthorized(w http.ResponseWriter, r *http.Request, requirement *router.TokenRequirement, err error) {
+	if strings.EqualFold(requirement.Header, "Authorization") {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer error=%q, error_description=%q", "invalid_token", err.Error()))
+	}
+
+	detail := fmt.Sprintf("a valid %q token is required: %s", requirement.Name, err)
+	problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, detail, "401 Unauthorized: "+detail+"\n")
+}
+
+// handleRequestTimeout handles requests cancelled before a response was
+// written, returning the status it answered with
+func (s *Server) handleRequestTimeout(w http.ResponseWriter, r *http.Request, elapsed time.Duration) int {
+	detail := fmt.Sprintf("The request exceeded the configured timeout and was terminated after %s.", elapsed)
+	if status := s.writeErrorPage(w, r, s.routingFor(r).errorPages.Timeout, templatepkg.ErrorInfo{
+		Status: http.StatusRequestTimeout,
+		Code:   problem.CodeRequestTimeout,
+		Detail: detail,
+	}); status != 0 {
+		return status
+	}
+
+	problem.Write(w, r, http.StatusRequestTimeout, problem.CodeRequestTimeout, detail,
+		fmt.Sprintf("408 Request Timeout\n\nThe request exceeded the configured timeout and was terminated.\nTimeout occurred after: %s", elapsed))
+	return http.StatusRequestTimeout
+}
+
+// handleBadGateway handles errors reaching a proxied route's upstream
+func (s *Server) handleBadGateway(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, http.StatusBadGateway, problem.CodeBadGateway,
+		"the upstream server could not be reached",
+		"502 Bad Gateway: the upstream server could not be reached\n")
+
+	s.logger.Error("proxy error",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"error", err,
+	)
+}
+
+// handleTemplateError handles template execution errors, returning the
+// status it answered with
+func (s *Server) handleTemplateError(w http.ResponseWriter, r *http.Request, err error) int {
+	// Query options the client sent that templates can't apply are the
+	// client's fault
+	var queryErr *templatepkg.QueryError
+	if errors.As(err, &queryErr) {
+		detail := queryErr.Error()
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidQuery, detail, "400 Bad Request: "+detail+"\n")
+		return http.StatusBadRequest
+	}
 
 	s.logger.Error("template execution error",
 		"method", r.Method,
 		"path", r.URL.Path,
 		"error", err,
 	)
+
+	const detail = "response template cannot be rendered due to an error in the template"
+	if status := s.writeErrorPage(w, r, s.routingFor(r).errorPages.TemplateError, templatepkg.ErrorInfo{
+		Status:  http.StatusInternalServerError,
+		Code:    problem.CodeTemplateError,
+		Detail:  detail,
+		Message: err.Error(),
+	}); status != 0 {
+		return status
+	}
+
+	problem.Write(w, r, http.StatusInternalServerError, problem.CodeTemplateError, detail,
+		"500 Internal Server Error: "+detail+"\n")
+	return http.StatusInternalServerError
 }
 
 // logRequest logs details about the processed request
 func (s *Server) logRequest(r *http.Request, status int, duration time.Duration, route *router.Route) {
+	// The fallback route has no pattern, as it serves requests matching none
 	var routePattern string
-	if route != nil {
+	if route != nil && route.Pattern != "" {
 		routePattern = route.Pattern
 	} else {
 		routePattern = "no match"
 	}
 
-	s.logger.Info("request processed",
+	attrs := []any{
 		"method", r.Method,
 		"path", r.URL.Path,
 		"status", status,
 		"duration_ms", duration.Milliseconds(),
 		"route", routePattern,
 		"remote_addr", r.RemoteAddr,
-	)
+	}
+
+	// Tell which server name, protocol and cipher TLS clients negotiated
+	if info := templatepkg.NewTLSInfo(r.TLS); info != nil {
+		attrs = append(attrs,
+			"tls_sni", info.ServerName,
+			"tls_alpn", info.ALPN,
+			"tls_cipher", info.CipherSuite,
+			"tls_version", info.Version,
+		)
+	}
+
+	s.logger.Info("request processed", attrs...)
 }
 
 // Start starts the HTTP server
 func (s *Server) Start(ctx context.Context) error {
+	rt := s.current()
 	s.logger.Info("starting HTTP server",
 		"addr", s.httpServer.Addr,
-		"routes_count", len(s.routes),
+		"tls", s.httpServer.TLSConfig != nil,
+		"routes_count", len(rt.routes),
 	)
 
 	// Log route details
-	for i, route := range s.routes {
+	for i, route := range rt.routes {
 		s.logger.Debug("compiled route",
 			"index", i,
 			"pattern", route.Pattern,
@@ -278,29 +913,54 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	// Start server in a goroutine
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 1+len(rt.tenants))
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.listenAndServe(); err != nil && err != http.ErrServerClosed {
 			errCh <- err
 		}
 	}()
 
-	// Wait for context cancellation or server error
-	select {
-	case <-ctx.Done():
-		s.logger.Info("shutting down server", "reason", "exit signal received")
-		newCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	// Check remote includes for changes while running
+	go s.refreshIncludes(ctx)
 
-		if err := s.Shutdown(newCtx); err != nil && err != http.ErrServerClosed {
-			s.logger.Warn("error during graceful shutdown", "error", err)
-			return err
+	// Start the listeners of tenants addressed by their own port
+	for _, t := range rt.tenants {
+		if !t.hasListener() {
+			continue
 		}
 
-		return nil
+		s.logger.Info("starting tenant HTTP server", "tenant", t.config.Name, "addr", t.server.httpServer.Addr)
+		go func(t *tenant) {
+			if err := t.server.listenAndServe(); err != nil && err != http.ErrServerClosed {
+				errCh <- fmt.Errorf("tenant %q: %w", t.config.Name, err)
+			}
+		}(t)
+	}
+
+	// Stop once the maximum uptime is reached
+	s.recycler.start()
+	defer s.recycler.stop()
+
+	// Wait for context cancellation, the server's limits or a server error
+	var reason string
+	select {
+	case <-ctx.Done():
+		reason = "exit signal received"
+	case reason = <-s.recycler.reasons:
 	case err := <-errCh:
 		return fmt.Errorf("server failed to start: %w", err)
 	}
+
+	s.logger.Info("shutting down server", "reason", reason)
+	newCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.Shutdown(newCtx); err != nil && err != http.ErrServerClosed {
+		s.logger.Warn("error during graceful shutdown", "error", err)
+		return err
+	}
+
+	return nil
 }
 
 // Shutdown gracefully shuts down the server
@@ -310,7 +970,42 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 	s.logger.Info("gracefully shutting down server",
 		"timeout", s.shutdownTimeout)
-	return s.httpServer.Shutdown(shutdownCtx)
+
+	// Shut down tenant listeners alongside the main one
+	tenants := s.current().tenants
+
+	// Finish live journal streams and WebSocket connections, which would
+	// otherwise never go idle
+	s.journal.endStreams()
+	s.websockets.closeAll()
+	for _, t := range tenants {
+		if !t.hasListener() {
+			t.server.journal.endStreams()
+			t.server.websockets.closeAll()
+		}
+	}
+
+	for _, t := range tenants {
+		if !t.hasListener() {
+			continue
+		}
+		if err := t.server.Shutdown(shutdownCtx); err != nil && err != http.ErrServerClosed {
+			s.logger.Warn("error during tenant graceful shutdown", "tenant", t.config.Name, "error", err)
+		}
+	}
+
+	err := s.httpServer.Shutdown(shutdownCtx)
+
+	// Stop persisting captured data once in-flight requests are done,
+	// including that of tenants sharing this listener
+	for _, t := range tenants {
+		if !t.hasListener() {
+			t.server.closeStorage()
+		}
+	}
+	s.closeStorage()
+
+	return err
 }
 
 // GetAddr returns the server's listening address
@@ -318,77 +1013,210 @@ func (s *Server) GetAddr() string {
 	return s.httpServer.Addr
 }
 
-// renderResponseHeaders executes response header templates and sets them on the response
-func (s *Server) renderResponseHeaders(w http.ResponseWriter, route *router.Route, ctx *templatepkg.TemplateContext) error {
+// setCookies adds the cookies templates set on resp to the response, through
+// setCookie in header or body templates
+func setCookies(w http.ResponseWriter, resp *templatepkg.Response) {
+	for _, cookie := range resp.Cookies() {
+		http.SetCookie(w, cookie)
+	}
+}
+
+// renderResponseHeaders executes response header templates and sets them on
+// the response. Like response bodies, the templates run in the background,
+// so slow ones are given up on when the request is cancelled or times out,
+// returning the request context's error and leaving the response untouched.
+func (s *Server) renderResponseHeaders(rt *routing, w http.ResponseWriter, r *http.Request, headers map[string]*template.Template, ctx *templatepkg.TemplateContext) error {
 	// If no custom response headers, nothing to do
-	if len(route.ResponseHeaders) == 0 {
+	if len(headers) == 0 {
 		return nil
 	}
 
-	// Execute each response header template
-	for headerName, headerTemplate := range route.ResponseHeaders {
-		var buf bytes.Buffer
+	rendered := make(map[string]string, len(headers))
+	done := make(chan error, 1)
 
-		// Execute the header template
-		if err := headerTemplate.Execute(&buf, ctx); err != nil {
-			return fmt.Errorf("failed to execute template for header %q: %w", headerName, err)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("header template execution panicked: %v", recovered)
+			}
+		}()
+
+		// Execute each response header template
+		for headerName, headerTemplate := range headers {
+			var buf bytes.Buffer
+			if err := rt.engine.ExecuteTemplate(headerTemplate, &buf, ctx); err != nil {
+				done <- fmt.Errorf("failed to execute template for header %q: %w", headerName, err)
+				return
+			}
+			rendered[headerName] = strings.TrimSpace(buf.String())
 		}
+		done <- nil
+	}()
 
-		// Get the rendered header value and trim whitespace
-		headerValue := strings.TrimSpace(buf.String())
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-r.Context().Done():
+		// Let the templates finish in the background, discarding them
+		return r.Context().Err()
+	}
 
-		// Only set the header if the value is not empty
-		if headerValue != "" {
-			// Use proper header name capitalization (Go's http package handles this)
-			w.Header().Set(headerName, headerValue)
+	for headerName, headerValue := range rendered {
+		// Empty values leave the header out, removing it when the server's
+		// or the route's headers already set it
+		if headerValue == "" {
+			w.Header().Del(headerName)
+			continue
 		}
+		// Use proper header name capitalization (Go's http package handles this)
+		w.Header().Set(headerName, headerValue)
 	}
 
 	return nil
 }
 
+// handleResponseHeadersError handles errors rendering response headers,
+// answering with a timeout when the request was cancelled while they
+// rendered, and returning the status it answered with
+func (s *Server) handleResponseHeadersError(w http.ResponseWriter, r *http.Request, err error, start time.Time) int {
+	if ctxErr := r.Context().Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		s.logger.Warn("request timeout while rendering response headers - terminating",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"duration", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+		)
+		return s.handleRequestTimeout(w, r, time.Since(start))
+	}
+	return s.handleTemplateError(w, r, fmt.Errorf("failed to render response headers: %w", err))
+}
+
 // ReloadConfig reloads the configuration and recompiles routes
 func (s *Server) ReloadConfig() error {
 	// Load new configuration
-	cfg, err := config.LoadConfig(s.configFile)
+	cfg, err := config.LoadConfigs(s.current().configPaths)
 	if err != nil {
 		return fmt.Errorf("failed to load config during reload: %w", err)
 	}
 
+	return s.applyConfig(cfg)
+}
+
+// applyConfig recompiles routes and middleware from cfg and swaps them in.
+// Requests already being served finish with the routing they started with.
+func (s *Server) applyConfig(cfg *config.Config) error {
+	return s.applyConfigFiles(cfg, nil)
+}
+
+// applyConfigFiles is applyConfig for a configuration loaded from
+// configPaths, which later reloads read from. Nil paths keep the current ones.
+func (s *Server) applyConfigFiles(cfg *config.Config, configPaths []string) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if configPaths == nil {
+		configPaths = s.current().configPaths
+	}
+
 	// Create new router compiler and compile routes
-	compiler := router.NewCompilerWithConfig(cfg)
+	compiler, err := router.NewCompilerWithConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to compile template partials during reload: %w", err)
+	}
+	compiler.UseMiddlewareFactory(middleware.NewFactory(s.logger, s.metrics))
 	newRoutes, err := compiler.CompileRoutes(cfg.Routes)
 	if err != nil {
 		return fmt.Errorf("failed to compile routes during reload: %w", err)
 	}
+	newFallbackProxy, err := router.CompileFallbackProxy(cfg.FallbackProxy)
+	if err != nil {
+		return fmt.Errorf("failed to compile fallback proxy during reload: %w", err)
+	}
+	newFallback, err := compiler.CompileFallback(cfg.Fallback)
+	if err != nil {
+		return fmt.Errorf("failed to compile fallback response during reload: %w", err)
+	}
+	newErrorPages, err := compiler.CompileErrorPages(cfg.Server.ErrorPages)
+	if err != nil {
+		return fmt.Errorf("failed to compile error pages during reload: %w", err)
+	}
+	newGRPCMethods, err := compiler.CompileGRPC(cfg.GRPC)
+	if err != nil {
+		return fmt.Errorf("failed to compile gRPC methods during reload: %w", err)
+	}
+	newResponseHeaders, err := compiler.CompileResponseHeaders(cfg.ResponseHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to compile response headers during reload: %w", err)
+	}
+	adminTokens, err := newAdminTokens(cfg.Admin)
+	if err != nil {
+		return fmt.Errorf("failed to load admin tokens during reload: %w", err)
+	}
 
 	// Create new middleware chain
-	middlewareFactory := middleware.NewFactory(s.logger)
+	middlewareFactory := middleware.NewFactory(s.logger, s.metrics)
 	newChain, err := middlewareFactory.CreateChain(cfg.Middleware)
 	if err != nil {
 		return fmt.Errorf("failed to create middleware chain during reload: %w", err)
 	}
-	newMiddlewareChain := newChain.Then(s)
+	newMiddlewareChain := problem.Middleware(cfg.Errors.GetFormat(), newChain.Then(s))
 
-	// Acquire write lock to update routes, engine, and middleware atomically
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// Reconcile tenants with the new configuration
+	newTenants, err := s.reloadTenants(cfg)
+	if err != nil {
+		return err
+	}
 
-	// Update routes, engine, and middleware
-	s.routes = newRoutes
-	s.engine = compiler.GetEngine()
-	s.middlewareChain = newMiddlewareChain
+	// Apply the new journal bounds
+	s.checkStorageConfig(cfg)
+	if err := s.journal.configure(cfg.Journal); err != nil {
+		return fmt.Errorf("failed to configure request journal during reload: %w", err)
+	}
 
-	// Update the HTTP server handler to use the new middleware chain
-	s.httpServer.Handler = newMiddlewareChain
+	// Swap in the new routing for the requests to come
+	rt := &routing{
+		routes:          newRoutes,
+		index:           router.NewIndex(newRoutes, cfg.Server.MatchesSpecific()),
+		engine:          compiler.GetEngine(),
+		middlewareChain: newMiddlewareChain,
+		devMode:         cfg.Server.DevMode,
+		clockSkew:       cfg.Server.ClockSkew,
+		clockTime:       clockTime(cfg.Server),
+		strictHTTP:      cfg.Server.StrictHTTP,
+		headerVars:      cfg.Server.HeaderVars,
+		fakeSeed:        fakeSeed(cfg.Template),
+		bodyLimit:       cfg.Server.GetBodyLimit(),
+		contentType:     cfg.Server.DefaultContentType,
+		serverHeaders:   cfg.Server.Headers,
+		middlewares:     cfg.Middleware,
+		fallbackProxy:   newFallbackProxy,
+		fallback:        newFallback,
+		errorPages:      newErrorPages,
+		grpcMethods:     newGRPCMethods,
+		watchFiles:      cfg.WatchFiles(),
+		tenants:         newTenants,
+		adminTokens:     adminTokens,
+		headerCasing:    cfg.HeaderCasing(),
+		responseHeaders: newResponseHeaders,
+		configPaths:     configPaths,
+	}
+	s.routing.Store(rt)
+
+	s.tokens.configure(cfg.TokenBucket)
+	s.transactions.configure(cfg.Transactions)
+	s.dependencies.configure(cfg.Health)
+	s.includes.configure(cfg.RemoteIncludes())
+	s.logConfigWarnings(cfg)
 
 	s.logger.Info("configuration reloaded successfully",
-		"file", s.configFile,
-		"routes_count", len(s.routes),
+		"files", rt.configPaths,
+		"routes_count", len(rt.routes),
 	)
 
 	// Log new route details in debug mode
-	for i, route := range s.routes {
+	for i, route := range rt.routes {
 		s.logger.Debug("reloaded route",
 			"index", i,
 			"pattern", route.Pattern,
@@ -403,14 +1231,15 @@ func (s *Server) ReloadConfig() error {
 
 // HealthCheckResponse represents the JSON response for the health check endpoint
 type HealthCheckResponse struct {
-	Status     string            `json:"status"`
-	Version    string            `json:"version"`
-	Timestamp  time.Time         `json:"timestamp"`
-	Uptime     string            `json:"uptime"`
-	Routes     int               `json:"routes"`
-	ConfigFile string            `json:"config_file"`
-	GoVersion  string            `json:"go_version"`
-	Memory     map[string]uint64 `json:"memory"`
+	Status       string             `json:"status"`
+	Version      string             `json:"version"`
+	Timestamp    time.Time          `json:"timestamp"`
+	Uptime       string             `json:"uptime"`
+	Routes       int                `json:"routes"`
+	ConfigFile   string             `json:"config_file"`
+	GoVersion    string             `json:"go_version"`
+	Memory       map[string]uint64  `json:"memory"`
+	Dependencies []DependencyStatus `json:"dependencies,omitempty"`
 }
 
 // handleHealthCheck handles the built-in health check endpoint
@@ -422,19 +1251,22 @@ func (s *Server) handleHealthCheck(w http.ResponseWriter, _ *http.Request) {
 	// Calculate uptime
 	uptime := time.Since(s.startTime)
 
-	// Get route count (with read lock for thread safety)
-	s.mu.RLock()
-	routeCount := len(s.routes)
-	s.mu.RUnlock()
+	// Get route count
+	rt := s.current()
+	routeCount := len(rt.routes)
+
+	// Summarize the synthetic dependencies
+	deps := s.dependencies.list()
+	status := overallStatus(deps)
 
 	// Build response
 	response := HealthCheckResponse{
-		Status:     "healthy",
+		Status:     status,
 		Version:    s.appVersion,
 		Timestamp:  time.Now(),
 		Uptime:     uptime.String(),
 		Routes:     routeCount,
-		ConfigFile: s.configFile,
+		ConfigFile: strings.Join(rt.configPaths, ", "),
 		GoVersion:  runtime.Version(),
 		Memory: map[string]uint64{
 			"alloc_bytes":       memStats.Alloc,
@@ -442,11 +1274,18 @@ func (s *Server) handleHealthCheck(w http.ResponseWriter, _ *http.Request) {
 			"sys_bytes":         memStats.Sys,
 			"heap_alloc_bytes":  memStats.HeapAlloc,
 		},
+		Dependencies: deps,
+	}
+
+	// Report an unhealthy server with a 503, so orchestrators take it out of rotation
+	code := http.StatusOK
+	if status == healthUnhealthy {
+		code = http.StatusServiceUnavailable
 	}
 
 	// Set response headers
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(code)
 
 	// Encode and send response
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -2,18 +2,26 @@ package server
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
 )
 
 // TestServer represents a test server instance with utilities for integration testing
@@ -36,7 +44,7 @@ func NewTestServer(t *testing.T, cfg *config.Config) *TestServer {
 	}
 
 	// Create server instance
-	server, err := NewServer(cfg, "test-config.yaml", ":0", logger, "test-version")
+	server, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", logger, "test-version")
 	if err != nil {
 		t.Fatalf("Failed to create server: %v", err)
 	}
@@ -233,6 +241,300 @@ func TestServer_Integration_JSONEchoEndpoint(t *testing.T) {
 	}
 }
 
+func TestServer_Integration_RawBodyEcho(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/echo", Method: "POST", Template: `{{ bodyString . }}`},
+		{Path: "/digest", Method: "POST", Template: `{{ .RawBody | sha256sum }}`},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	// Formatting the JSON parser would lose is echoed back as-is
+	payload := "{ \"b\": 2,\n  \"a\": 1 }"
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	resp, err := ts.makeRequest("POST", "/echo", strings.NewReader(payload), headers)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != payload {
+		t.Errorf("Expected the exact request body %q, got %q", payload, body)
+	}
+
+	resp, err = ts.makeRequest("POST", "/digest", strings.NewReader(payload), headers)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	sum := sha256.Sum256([]byte(payload))
+	if body := readResponseBody(t, resp); body != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the SHA-256 of the request body, got %q", body)
+	}
+}
+
+func TestServer_Integration_XMLBody(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/soap",
+			Method: "POST",
+			Template: `{{ $request := .Body.Envelope.Body.GetUser -}}
+{{ dict "Envelope" (dict "Body" (dict "GetUserResponse" (dict "@id" $request.id "name" (printf "user %s" $request.id)))) | toXml }}`,
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	payload := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><GetUser><id>7</id></GetUser></soap:Body></soap:Envelope>`
+	resp, err := ts.makeRequest("POST", "/soap", strings.NewReader(payload), map[string]string{"Content-Type": "text/xml"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	expected := `<Envelope><Body><GetUserResponse id="7"><name>user 7</name></GetUserResponse></Body></Envelope>`
+	if body := readResponseBody(t, resp); body != expected {
+		t.Errorf("Expected %q, got %q", expected, body)
+	}
+}
+
+func TestServer_Integration_FormBodies(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/upload",
+			Method:   "POST",
+			Template: `{{ .Form.Get "title" }}:{{ range $field, $files := .Files }}{{ range $files }} {{ $field }}={{ .Filename }}/{{ .Size }}/{{ .ContentType }}{{ end }}{{ end }}`,
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("POST", "/upload", strings.NewReader("title=Hello+world"), map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "Hello world:" {
+		t.Errorf("Expected the urlencoded field, got %q", body)
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("title", "Report")
+	part, _ := w.CreateFormFile("attachment", "report.pdf")
+	_, _ = part.Write(bytes.Repeat([]byte("x"), 1234))
+	_ = w.Close()
+
+	resp, err = ts.makeRequest("POST", "/upload", &buf, map[string]string{"Content-Type": w.FormDataContentType()})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "Report: attachment=report.pdf/1234/application/octet-stream" {
+		t.Errorf("Expected the multipart field and file, got %q", body)
+	}
+}
+
+func TestServer_Integration_Cookies(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/login",
+			Method:   "POST",
+			Template: `{{ .Response.SetCookie "session" (.Form.Get "user" | sha256sum | trunc 16) (dict "path" "/" "http_only" true) }}welcome`,
+		},
+		{
+			Path:         "/profile",
+			Method:       "GET",
+			MatchCookies: map[string]string{"session": "/^[a-f0-9]{16}$/"},
+			Template:     `profile for {{ .Cookies.session }}`,
+		},
+		{
+			Path:     "/profile",
+			Method:   "GET",
+			Template: `{{ .Response.SetStatus 401 }}log in first`,
+		},
+		{
+			Path:     "/logout",
+			Method:   "POST",
+			Template: `{{ .Response.DeleteCookie "session" (dict "path" "/") }}bye`,
+			Stream:   &config.StreamConfig{},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("POST", "/login", strings.NewReader("user=ada"), map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "session" || len(cookies[0].Value) != 16 || !cookies[0].HttpOnly || cookies[0].Path != "/" {
+		t.Fatalf("Expected a session cookie, got %v", cookies)
+	}
+	session := cookies[0].Value
+
+	// Requests with the session cookie match the first route
+	resp, err = ts.makeRequest("GET", "/profile", nil, map[string]string{"Cookie": "session=" + session})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); resp.StatusCode != http.StatusOK || body != "profile for "+session {
+		t.Errorf("Expected the profile, got %d %q", resp.StatusCode, body)
+	}
+
+	for _, cookie := range []string{"", "session=forged"} {
+		resp, err = ts.makeRequest("GET", "/profile", nil, map[string]string{"Cookie": cookie})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected cookie %q to be unauthorized, got %d", cookie, resp.StatusCode)
+		}
+	}
+
+	// Streamed responses set cookies too
+	resp, err = ts.makeRequest("POST", "/logout", nil, map[string]string{"Cookie": "session=" + session})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if got := resp.Header.Get("Set-Cookie"); got != "session=; Path=/; Max-Age=0" {
+		t.Errorf("Expected the session cookie to be deleted, got %q", got)
+	}
+}
+
+func TestServer_Integration_CookieFunctions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("ok"), 0o644); err != nil {
+		t.Fatalf("Failed to write app.js: %v", err)
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/login",
+			Method:          "POST",
+			Template:        `{{ deleteCookie "guest" }}welcome`,
+			ResponseHeaders: map[string]string{"X-Session": `{{ setCookie "session" "abc123" (dict "path" "/" "maxAge" 3600 "httpOnly" true "sameSite" "strict") }}`},
+		},
+		{
+			Path:            "/assets",
+			Method:          "GET",
+			StaticDir:       &config.StaticDirConfig{Root: dir},
+			ResponseHeaders: map[string]string{"X-Visit": `{{ setCookie "visited" "yes" }}`},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected []string
+	}{
+		{method: "POST", path: "/login", expected: []string{"session=abc123; Path=/; Max-Age=3600; HttpOnly; SameSite=Strict", "guest=; Max-Age=0"}},
+		{method: "GET", path: "/assets/app.js", expected: []string{"visited=yes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			readResponseBody(t, resp)
+
+			if got := resp.Header.Values("Set-Cookie"); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected cookies %q, got %q", tt.expected, got)
+			}
+			// Header templates only setting cookies leave their header out
+			if got := resp.Header.Get("X-Session") + resp.Header.Get("X-Visit"); got != "" {
+				t.Errorf("Expected no empty header, got %q", got)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_LogEvent(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/orders/{id}/pay",
+			Method:   "POST",
+			Template: `{{ logEvent "payment_declined" (dict "order" .Params.id) }}declined`,
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+	var logs bytes.Buffer
+	ts.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	resp, err := ts.makeRequest("POST", "/orders/42/pay", nil, map[string]string{"X-Request-ID": "req-1"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "declined" {
+		t.Errorf("Expected %q, got %q", "declined", body)
+	}
+
+	// Wait for the request to be done before reading its logs
+	ts.Close()
+
+	expected := `msg="template event" event=payment_declined request_id=req-1 method=POST path=/orders/42/pay route=/orders/{id}/pay fields.order=42`
+	if !strings.Contains(logs.String(), expected) {
+		t.Errorf("Expected a log entry containing %q, got %q", expected, logs.String())
+	}
+}
+
+func TestServer_Integration_RoutePriority(t *testing.T) {
+	routes := []config.RouteConfig{
+		{Path: "/^/users/.*$/", Method: "GET", Template: "any user"},
+		{Path: "/users/me", Method: "GET", Template: "me"},
+		{Path: "/users/admin", Method: "GET", Template: "admin"},
+		{Path: "/users/{id}", Method: "GET", Template: "user", Priority: 1},
+	}
+
+	tests := []struct {
+		strategy string
+		path     string
+		expected string
+	}{
+		{path: "/users/me", expected: "user"},
+		{path: "/users/me/friends", expected: "any user"},
+		{strategy: config.RouteMatchingSpecific, path: "/users/me", expected: "user"},
+		{strategy: config.RouteMatchingSpecific, path: "/users/me/friends", expected: "any user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+" "+tt.path, func(t *testing.T) {
+			cfg := createTestConfig(routes)
+			cfg.Server.RouteMatching = tt.strategy
+			ts := NewTestServer(t, cfg)
+			defer ts.Close()
+
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if body := readResponseBody(t, resp); body != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, body)
+			}
+		})
+	}
+
+	// Without priorities, the most specific route wins over a broad regex
+	// listed before it
+	cfg := createTestConfig(routes[:3])
+	cfg.Server.RouteMatching = config.RouteMatchingSpecific
+	ts := NewTestServer(t, cfg)
+	defer ts.Close()
+
+	for path, expected := range map[string]string{"/users/me": "me", "/users/admin": "admin", "/users/42": "any user"} {
+		resp, err := ts.makeRequest("GET", path, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if body := readResponseBody(t, resp); body != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, path, body)
+		}
+	}
+}
+
 func TestServer_Integration_TemplateRenderingWithContext(t *testing.T) {
 	// Test template rendering with all context data
 	cfg := createTestConfig([]config.RouteConfig{
@@ -625,3 +927,699 @@ func TestServer_Integration_HeaderTemplateExecutionErrors(t *testing.T) {
 		t.Errorf("Expected 500 error message, got %q", body)
 	}
 }
+
+func TestServer_Integration_DevModeWithoutInjections(t *testing.T) {
+	// Dev mode should not emit diagnostic headers when nothing was injected
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/plain",
+			Method:   "GET",
+			Template: "plain",
+		},
+	})
+	cfg.Server.DevMode = true
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("GET", "/plain", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	if resp.Header.Get(headerInjectedDelay) != "" {
+		t.Errorf("Expected no %s header, got %q", headerInjectedDelay, resp.Header.Get(headerInjectedDelay))
+	}
+	if resp.Header.Get(headerInjectedFault) != "" {
+		t.Errorf("Expected no %s header, got %q", headerInjectedFault, resp.Header.Get(headerInjectedFault))
+	}
+}
+
+func TestServer_Integration_ConditionalResponses(t *testing.T) {
+	// Test that routes pick between alternative responses based on the request
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/login",
+			Method:          "POST",
+			ResponseHeaders: map[string]string{"X-Route": "login", "X-Result": "default"},
+			Responses: []config.ResponseConfig{
+				{
+					When:            &config.ResponseCondition{Body: map[string]string{"username": "admin"}},
+					Template:        `{"token":"admin-token"}`,
+					ResponseHeaders: map[string]string{"X-Result": "admin"},
+				},
+				{
+					When:     &config.ResponseCondition{Query: map[string]string{"locked": "true"}},
+					Status:   http.StatusLocked,
+					Template: `{"error":"locked"}`,
+				},
+				{
+					Status:   http.StatusUnauthorized,
+					Template: `{{ .Response.SetStatus 403 }}{"error":"forbidden"}`,
+				},
+			},
+		},
+		{
+			Path:   "/only-conditional",
+			Method: "GET",
+			Responses: []config.ResponseConfig{
+				{
+					When:     &config.ResponseCondition{Headers: map[string]string{"X-Beta": "on"}},
+					Template: "beta",
+				},
+			},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+		expectedResult string
+	}{
+		{
+			name:           "body condition",
+			method:         "POST",
+			path:           "/login",
+			body:           `{"username":"admin"}`,
+			headers:        map[string]string{"Content-Type": "application/json"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"token":"admin-token"}`,
+			expectedResult: "admin",
+		},
+		{
+			name:           "query condition with status",
+			method:         "POST",
+			path:           "/login?locked=true",
+			expectedStatus: http.StatusLocked,
+			expectedBody:   `{"error":"locked"}`,
+			expectedResult: "default",
+		},
+		{
+			name:           "template status overrides response status",
+			method:         "POST",
+			path:           "/login",
+			body:           `{"username":"guest"}`,
+			headers:        map[string]string{"Content-Type": "application/json"},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"forbidden"}`,
+			expectedResult: "default",
+		},
+		{
+			name:           "conditional match",
+			method:         "GET",
+			path:           "/only-conditional",
+			headers:        map[string]string{"X-Beta": "on"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "beta",
+		},
+		{
+			name:           "no eligible response",
+			method:         "GET",
+			path:           "/only-conditional",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, strings.NewReader(tt.body), tt.headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedBody != "" && body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+			if tt.expectedResult != "" {
+				if got := resp.Header.Get("X-Result"); got != tt.expectedResult {
+					t.Errorf("Expected X-Result %q, got %q", tt.expectedResult, got)
+				}
+				if got := resp.Header.Get("X-Route"); got != "login" {
+					t.Errorf("Expected X-Route %q, got %q", "login", got)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_Integration_TemplateControlledStatus(t *testing.T) {
+	// Test that templates can choose the response status code
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/^/users/(?P<id>\\d+)$/",
+			Method:   "GET",
+			Template: `{{ if eq .Params.id "999" }}{{ .Response.SetStatus 404 }}{"error":"not found"}{{ else }}{"id":{{ .Params.id }}}{{ end }}`,
+		},
+		{
+			Path:     "/invalid-status",
+			Method:   "GET",
+			Template: `{{ .Response.SetStatus 42 }}`,
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{path: "/users/1", expectedStatus: http.StatusOK},
+		{path: "/users/999", expectedStatus: http.StatusNotFound},
+		{path: "/invalid-status", expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_TemplateProblemJSON(t *testing.T) {
+	// Test that problemJSON answers with its status and the problem+json content type
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/^/orders/(?P<id>\\d+)$/",
+			Method:          "GET",
+			ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+			Template:        `{{ if eq .Params.id "1" }}{"id":1}{{ else }}{{ problemJSON 404 "Order Not Found" (printf "no order %s" .Params.id) (dict "code" "order_not_found") }}{{ end }}`,
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{path: "/orders/1", expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedBody: `{"id":1}`},
+		{path: "/orders/2", expectedStatus: http.StatusNotFound, expectedContentType: "application/problem+json", expectedBody: `{"code":"order_not_found","detail":"no order 2","status":404,"title":"Order Not Found","type":"about:blank"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.expectedContentType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.expectedContentType, got)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_TemplatePartials(t *testing.T) {
+	// Test that every route can include the configured partials
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/users",
+			Method:   "GET",
+			Template: `{{ template "page" (list "ana" "bob") }}`,
+		},
+		{
+			Path:     "/orders",
+			Method:   "GET",
+			Template: `{{ .Response.SetStatus 404 }}{{ template "error" "no orders yet" }}`,
+		},
+	})
+	cfg.Template.Partials = map[string]string{
+		"page":  `{"items": {{ toJson . }}, "count": {{ len . }}}`,
+		"error": `{"error": {{ toJson . }}}`,
+	}
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{path: "/users", expectedStatus: http.StatusOK, expectedBody: `{"items": ["ana","bob"], "count": 2}`},
+		{path: "/orders", expectedStatus: http.StatusNotFound, expectedBody: `{"error": "no orders yet"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_EscapeHTML(t *testing.T) {
+	// Test that only routes asking to escape HTML escape what templates print
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:       "/search",
+			Method:     "GET",
+			Template:   `<h1>Results for {{ .Query.q | first }}</h1>{{ template "footer" . }}`,
+			EscapeHTML: true,
+		},
+		{
+			Path:       "/users",
+			Method:     "GET",
+			Responses:  []config.ResponseConfig{{Template: `<p>{{ .Query.id | first }}</p>`}},
+			EscapeHTML: true,
+		},
+		{
+			Path:     "/api/search",
+			Method:   "GET",
+			Template: `{"query": {{ .Query.q | first | toJson }}, "footer": "{{ template "footer" . }}"}`,
+		},
+	})
+	cfg.Template.Partials = map[string]string{"footer": `<footer>{{ "&" }}</footer>`}
+
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		path         string
+		expectedBody string
+	}{
+		{path: "/search?q=%3Cscript%3E", expectedBody: `<h1>Results for &lt;script&gt;</h1><footer>&amp;</footer>`},
+		{path: "/users?id=%3Cb%3E", expectedBody: `<p>&lt;b&gt;</p>`},
+		{path: "/api/search?q=a%26b", expectedBody: `{"query": "a\u0026b", "footer": "<footer>&</footer>"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if body := readResponseBody(t, resp); body != tt.expectedBody {
+				t.Errorf("Expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_JSONErrors(t *testing.T) {
+	// Test that built-in errors use problem+json documents when configured
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/broken",
+			Method:   "GET",
+			Template: `{{ fail "boom" }}`,
+		},
+		{
+			Path:     "/slow",
+			Method:   "GET",
+			Template: `{{ sleep "500ms" }}done`,
+		},
+	})
+	cfg.Errors.Format = "json"
+	cfg.Middleware.Enabled = []middleware.MiddlewareConfig{
+		{Type: "timeout", Config: map[string]interface{}{"duration": "50ms"}},
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", logger, "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.handler())
+	defer httpServer.Close()
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{path: "/missing", expectedStatus: http.StatusNotFound, expectedCode: "route_not_found"},
+		{path: "/broken", expectedStatus: http.StatusInternalServerError, expectedCode: "template_error"},
+		{path: "/slow", expectedStatus: http.StatusRequestTimeout, expectedCode: "request_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(httpServer.URL + tt.path)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Expected problem+json content type, got %q", ct)
+			}
+
+			var doc struct {
+				Type     string `json:"type"`
+				Title    string `json:"title"`
+				Status   int    `json:"status"`
+				Instance string `json:"instance"`
+				Code     string `json:"code"`
+			}
+			if err := json.Unmarshal([]byte(body), &doc); err != nil {
+				t.Fatalf("Failed to decode problem document %q: %v", body, err)
+			}
+			if doc.Status != tt.expectedStatus || doc.Code != tt.expectedCode || doc.Instance != tt.path {
+				t.Errorf("Unexpected problem document: %+v", doc)
+			}
+			if doc.Type != "about:blank" || doc.Title != http.StatusText(tt.expectedStatus) {
+				t.Errorf("Expected about:blank type and status title, got %+v", doc)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_SlowResponseHeaders(t *testing.T) {
+	slow := map[string]string{"X-Slow": `{{ sleep "2s" }}slow`}
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/route", Method: "GET", Template: "done", ResponseHeaders: slow},
+		{Path: "/response", Method: "GET", Responses: []config.ResponseConfig{{Template: "done", ResponseHeaders: slow}}},
+		{Path: "/websocket", Method: "GET", WebSocket: &config.WebSocketConfig{}, ResponseHeaders: slow},
+	})
+
+	logger := slog.New(slog.DiscardHandler)
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", logger, "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	for _, path := range []string{"/route", "/response", "/websocket"} {
+		t.Run(path, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req := httptest.NewRequestWithContext(ctx, "GET", path, nil)
+			resp := httptest.NewRecorder()
+
+			start := time.Now()
+			srv.handler().ServeHTTP(resp, req)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected the slow header template to be given up on, took %s", elapsed)
+			}
+
+			if resp.Code != http.StatusRequestTimeout {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusRequestTimeout, resp.Code, resp.Body.String())
+			}
+			if got := resp.Header().Get("X-Slow"); got != "" {
+				t.Errorf("Expected no header from the abandoned template, got %q", got)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_ResponseHeaderContext(t *testing.T) {
+	// Test that header templates can use route parameters, route metadata and the request ID
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/^/users/(?P<id>\\d+)$/",
+			Method: "GET",
+			ResponseHeaders: map[string]string{
+				"Location":     "/users/{{ .Params.id }}",
+				"X-Route":      "{{ .Route.Method }} {{ .Route.Pattern }}",
+				"X-Request-ID": "{{ .RequestID }}",
+			},
+			Template: "{{ .RequestID }}",
+		},
+		{
+			Path:   "/^/orders/(?P<id>\\d+)$/",
+			Method: "GET",
+			Responses: []config.ResponseConfig{
+				{
+					Template:        "order",
+					ResponseHeaders: map[string]string{"X-Order": "{{ .Params.id }} via {{ .Route.Pattern }}"},
+				},
+			},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	resp, err := ts.makeRequest("GET", "/users/42", nil, map[string]string{"X-Request-ID": "req-1"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+
+	expectedHeaders := map[string]string{
+		"Location":     "/users/42",
+		"X-Route":      `GET /^/users/(?P<id>\d+)$/`,
+		"X-Request-Id": "req-1",
+	}
+	for name, want := range expectedHeaders {
+		if got := resp.Header.Get(name); got != want {
+			t.Errorf("Expected header %s %q, got %q", name, want, got)
+		}
+	}
+	if body != "req-1" {
+		t.Errorf("Expected body to use the same request ID, got %q", body)
+	}
+
+	// Without a client-provided ID, headers and body share the generated one
+	resp, err = ts.makeRequest("GET", "/users/7", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body = readResponseBody(t, resp)
+	if id := resp.Header.Get("X-Request-ID"); id == "" || id != body {
+		t.Errorf("Expected generated request ID %q to match body %q", id, body)
+	}
+
+	// Response-level header templates get the same context
+	resp, err = ts.makeRequest("GET", "/orders/9", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+	if got, want := resp.Header.Get("X-Order"), `9 via /^/orders/(?P<id>\d+)$/`; got != want {
+		t.Errorf("Expected X-Order %q, got %q", want, got)
+	}
+}
+
+func TestServer_Integration_RouteDelay(t *testing.T) {
+	// Test that routes wait for their configured delay and report it in dev mode
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/delayed",
+			Method:   "GET",
+			Template: "late",
+			Delay:    &config.DelayConfig{Min: 50 * time.Millisecond, Max: 80 * time.Millisecond},
+		},
+		{
+			Path:     "/stuck",
+			Method:   "GET",
+			Template: "never",
+			Delay:    &config.DelayConfig{Min: time.Minute, Max: time.Minute},
+		},
+	})
+	cfg.Server.DevMode = true
+
+	ts := NewTestServer(t, cfg)
+
+	start := time.Now()
+	resp, err := ts.makeRequest("GET", "/delayed", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected response after at least 50ms, got %s", elapsed)
+	}
+	if body != "late" {
+		t.Errorf("Expected body %q, got %q", "late", body)
+	}
+
+	injected, err := time.ParseDuration(resp.Header.Get(headerInjectedDelay))
+	if err != nil {
+		t.Fatalf("Expected a duration in %s, got %q", headerInjectedDelay, resp.Header.Get(headerInjectedDelay))
+	}
+	if injected < 50*time.Millisecond || injected > 80*time.Millisecond {
+		t.Errorf("Expected injected delay between 50ms and 80ms, got %s", injected)
+	}
+
+	// A cancelled request stops waiting
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.BaseURL+"/stuck", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	start = time.Now()
+	if resp, err := ts.Client.Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected the request to be cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to be quick, took %s", elapsed)
+	}
+}
+
+func TestServer_Integration_HeaderVars(t *testing.T) {
+	routes := []config.RouteConfig{
+		{
+			Path:            "/account",
+			Method:          "GET",
+			ResponseHeaders: map[string]string{"X-Plan": `{{ .Vars.Plan | default "free" }}`},
+			Template:        `{"plan": "{{ .Vars.Plan | default "free" }}", "user": "{{ index .Vars "User-Id" | default "anonymous" }}"}`,
+		},
+	}
+	headers := map[string]string{"X-Mockingjay-Var-Plan": "pro", "x-mockingjay-var-user-id": "42"}
+
+	tests := []struct {
+		name         string
+		enabled      bool
+		expectedBody string
+		expectedPlan string
+	}{
+		{name: "enabled", enabled: true, expectedBody: `{"plan": "pro", "user": "42"}`, expectedPlan: "pro"},
+		{name: "disabled", expectedBody: `{"plan": "free", "user": "anonymous"}`, expectedPlan: "free"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig(routes)
+			cfg.Server.HeaderVars = tt.enabled
+			ts := NewTestServer(t, cfg)
+
+			resp, err := ts.makeRequest("GET", "/account", nil, headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+			if plan := resp.Header.Get("X-Plan"); plan != tt.expectedPlan {
+				t.Errorf("Expected X-Plan header %q, got %q", tt.expectedPlan, plan)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_QueryOptions(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "users.json")
+	users := `[{"id": 1, "name": "Ada", "team": "core"}, {"id": 2, "name": "Grace", "team": "compilers"}, {"id": 3, "name": "Linus", "team": "core"}]`
+	if err := os.WriteFile(dataFile, []byte(users), 0o644); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{{
+		Path:     "/users",
+		Method:   "GET",
+		Template: `{{ $page := applyQuery .Query (dataFile "` + dataFile + `") }}{"count": {{ $page.Total }}, "value": {{ $page.Items | toJson }}}`,
+	}}))
+
+	tests := []struct {
+		name     string
+		query    string
+		status   int
+		expected string
+	}{
+		{
+			name:     "filter, sort and page",
+			query:    "?$filter=" + url.QueryEscape("team eq 'core'") + "&$orderby=" + url.QueryEscape("name desc") + "&$top=1",
+			status:   http.StatusOK,
+			expected: `{"count": 2, "value": [{"id":3,"name":"Linus","team":"core"}]}`,
+		},
+		{
+			name:     "generic parameters",
+			query:    "?sort=-id&limit=2&offset=1",
+			status:   http.StatusOK,
+			expected: `{"count": 3, "value": [{"id":2,"name":"Grace","team":"compilers"},{"id":1,"name":"Ada","team":"core"}]}`,
+		},
+		{
+			name:     "invalid options are the client's fault",
+			query:    "?$filter=" + url.QueryEscape("team is 'core'"),
+			status:   http.StatusBadRequest,
+			expected: "400 Bad Request: invalid $filter: unknown operator \"is\", supported operators: eq, ne, gt, ge, lt, le\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", "/users"+tt.query, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.status || body != tt.expected {
+				t.Errorf("Expected %d %q, got %d %q", tt.status, tt.expected, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestServer_Integration_DeprecatedRoutes(t *testing.T) {
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{
+		{Path: "/v1/users", Method: "GET", Template: "v1", Deprecated: true, Sunset: "2026-12-31"},
+		{Path: "/v1/orders", Method: "GET", Template: "v1", Deprecated: true},
+		{Path: "/v2/users", Method: "GET", Template: "v2"},
+	}))
+
+	tests := []struct {
+		path        string
+		deprecation string
+		sunset      string
+	}{
+		{path: "/v1/users", deprecation: "true", sunset: "Thu, 31 Dec 2026 00:00:00 GMT"},
+		{path: "/v1/orders", deprecation: "true"},
+		{path: "/v2/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			readResponseBody(t, resp)
+
+			if got := resp.Header.Get("Deprecation"); got != tt.deprecation {
+				t.Errorf("Expected Deprecation %q, got %q", tt.deprecation, got)
+			}
+			if got := resp.Header.Get("Sunset"); got != tt.sunset {
+				t.Errorf("Expected Sunset %q, got %q", tt.sunset, got)
+			}
+		})
+	}
+}
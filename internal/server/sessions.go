@@ -0,0 +1,277 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// headerSession is the request header naming the admin session a request
+// belongs to, on both admin API and mocked requests
+const headerSession = "X-Mockingjay-Session"
+
+// session isolates the runtime routes, sequence positions, transactions,
+// idempotency keys, call counts and tokens of one test suite, so several
+// suites can share a server in parallel
+type session struct {
+	ID        string
+	CreatedAt time.Time
+	ExpiresAt time.Time // Zero value means the session never expires
+}
+
+// expired reports whether the session has passed its expiry time
+func (sess *session) expired(now time.Time) bool {
+	return !sess.ExpiresAt.IsZero() && !now.Before(sess.ExpiresAt)
+}
+
+// sessionStore holds the open sessions. Sessions aren't persisted, as the
+// test suites that open them don't outlive the server.
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	now      func() time.Time
+}
+
+// newSessionStore creates an empty session store
+func newSessionStore() *sessionStore {
+	return &sessionStore{sessions: make(map[string]*session), now: time.Now}
+}
+
+// open starts a session with a random, unguessable ID, expiring after ttl
+// when ttl is positive
+func (ss *sessionStore) open(ttl time.Duration) *session {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	sess := &session{ID: hex.EncodeToString(b[:]), CreatedAt: ss.now()}
+	if ttl > 0 {
+		sess.ExpiresAt = sess.CreatedAt.Add(ttl)
+	}
+	ss.sessions[sess.ID] = sess
+	return sess
+}
+
+// get returns the open session with the given ID, or nil if there's none or
+// it has expired
+func (ss *sessionStore) get(id string) *session {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	sess, ok := ss.sessions[id]
+	if !ok || sess.expired(ss.now()) {
+		return nil
+	}
+	return sess
+}
+
+// close removes the session with the given ID, reporting whether it was open
+func (ss *sessionStore) close(id string) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	sess, ok := ss.sessions[id]
+	delete(ss.sessions, id)
+	return ok && !sess.expired(ss.now())
+}
+
+// closeAll removes every session and returns their IDs
+func (ss *sessionStore) closeAll() []string {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ids := make([]string, 0, len(ss.sessions))
+	for id := range ss.sessions {
+		ids = append(ids, id)
+	}
+	clear(ss.sessions)
+	return ids
+}
+
+// expire removes the sessions past their expiry time and returns their IDs
+func (ss *sessionStore) expire() []string {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var ids []string
+	now := ss.now()
+	for id, sess := range ss.sessions {
+		if sess.expired(now) {
+			ids = append(ids, id)
+			delete(ss.sessions, id)
+		}
+	}
+	return ids
+}
+
+// list returns the open sessions, oldest first
+func (ss *sessionStore) list() []*session {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	sessions := make([]*session, 0, len(ss.sessions))
+	for _, sess := range ss.sessions {
+		sessions = append(sessions, sess)
+	}
+	slices.SortFunc(sessions, func(a, b *session) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return sessions
+}
+
+// requestSession returns the open session a request names in its
+// X-Mockingjay-Session header, or nil if it names none or one that isn't open
+func (s *Server) requestSession(r *http.Request) *session {
+	id := r.Header.Get(headerSession)
+	if id == "" {
+		return nil
+	}
+	return s.sessions.get(id)
+}
+
+// sessionID returns the ID of sess, or an empty ID outside sessions
+func sessionID(sess *session) string {
+	if sess == nil {
+		return ""
+	}
+	return sess.ID
+}
+
+// adminSession returns the session an admin API request is made under, or
+// writes an error and returns false when it names one that isn't open
+func (s *Server) adminSession(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	id := r.Header.Get(headerSession)
+	if id == "" {
+		return nil, true
+	}
+	if sess := s.sessions.get(id); sess != nil {
+		return sess, true
+	}
+	writeAdminError(w, http.StatusNotFound, fmt.Sprintf("session %q not found", id))
+	return nil, false
+}
+
+// closeSession removes the state of a session, returning how much of each
+// kind of state was removed
+func (s *Server) closeSession(id string) map[string]int {
+	removed := map[string]int{
+		"routes":       s.runtimeRoutes.clearSession(id),
+		"scenarios":    s.scenarios.resetSession(id),
+		"transactions": s.transactions.resetSession(id),
+		"idempotency":  s.idempotency.resetSession(id),
+		"calls":        s.calls.resetSession(id),
+		"tokens":       s.tokens.revokeSession(id),
+	}
+	s.logger.Info("session closed", "id", id, "removed", removed)
+	return removed
+}
+
+// expireSessions closes the sessions past their expiry time
+func (s *Server) expireSessions() {
+	for _, id := range s.sessions.expire() {
+		s.closeSession(id)
+	}
+}
+
+// sessionRequest is the body accepted when opening a session
+type sessionRequest struct {
+	ExpiresIn time.Duration `yaml:"expires_in,omitempty"`
+}
+
+// sessionResponse describes a session in admin API responses
+type sessionResponse struct {
+	ID        string     `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Routes    int        `json:"routes"` // Runtime routes created under the session
+}
+
+// newSessionResponse converts a session into its API representation
+func (s *Server) newSessionResponse(sess *session) sessionResponse {
+	resp := sessionResponse{
+		ID:        sess.ID,
+		CreatedAt: sess.CreatedAt,
+		Routes:    len(s.runtimeRoutes.listSession(sess.ID)),
+	}
+	if !sess.ExpiresAt.IsZero() {
+		expiresAt := sess.ExpiresAt
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
+}
+
+// handleListSessions lists the open sessions
+func (s *Server) handleListSessions(w http.ResponseWriter, _ *http.Request) {
+	s.expireSessions()
+
+	sessions := s.sessions.list()
+	resp := make([]sessionResponse, 0, len(sessions))
+	for _, sess := range sessions {
+		resp = append(resp, s.newSessionResponse(sess))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": resp})
+}
+
+// handleOpenSession opens a session. The optional body may be JSON or YAML.
+func (s *Server) handleOpenSession(w http.ResponseWriter, r *http.Request) {
+	s.expireSessions()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	var req sessionRequest
+	if err := yaml.Unmarshal(body, &req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse session: %v", err))
+		return
+	}
+
+	if req.ExpiresIn < 0 {
+		writeAdminError(w, http.StatusBadRequest, "expires_in cannot be negative")
+		return
+	}
+
+	sess := s.sessions.open(req.ExpiresIn)
+	s.logger.Info("session opened", "id", sess.ID, "expires_in", req.ExpiresIn)
+
+	writeJSON(w, http.StatusCreated, s.newSessionResponse(sess))
+}
+
+// handleCloseSession closes a single session by ID, removing its state
+func (s *Server) handleCloseSession(w http.ResponseWriter, r *http.Request) {
+	s.expireSessions()
+
+	id := r.PathValue("id")
+	if !s.sessions.close(id) {
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("session %q not found", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.closeSession(id))
+}
+
+// handleCloseAllSessions closes every session, removing their state
+func (s *Server) handleCloseAllSessions(w http.ResponseWriter, _ *http.Request) {
+	ids := s.sessions.closeAll()
+	for _, id := range ids {
+		s.closeSession(id)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{"closed": len(ids)})
+}
@@ -0,0 +1,364 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// openTestSession opens a session through the admin API
+func openTestSession(t *testing.T, ts *TestServer, body string) sessionResponse {
+	t.Helper()
+
+	resp, err := ts.makeRequest("POST", "/__admin/sessions", strings.NewReader(body), nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	data := readResponseBody(t, resp)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", resp.StatusCode, data)
+	}
+
+	var sess sessionResponse
+	if err := json.Unmarshal([]byte(data), &sess); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(sess.ID) != 32 {
+		t.Fatalf("Expected a 32 character session ID, got %q", sess.ID)
+	}
+	return sess
+}
+
+func TestServer_Admin_SessionRoutes(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: "configured users"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	first := openTestSession(t, ts, "")
+	second := openTestSession(t, ts, "")
+
+	call := func(method, path, session string, body io.Reader) (int, string) {
+		t.Helper()
+		headers := map[string]string{}
+		if session != "" {
+			headers[headerSession] = session
+		}
+		resp, err := ts.makeRequest(method, path, body, headers)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp.StatusCode, readResponseBody(t, resp)
+	}
+
+	// Each session overrides the configured route with its own
+	for _, sess := range []sessionResponse{first, second} {
+		route := `{"path": "/users", "method": "GET", "template": "users of ` + sess.ID + `"}`
+		if status, body := call("POST", "/__admin/routes", sess.ID, strings.NewReader(route)); status != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", status, body)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		session  string
+		expected string
+	}{
+		{name: "first session", session: first.ID, expected: "users of " + first.ID},
+		{name: "second session", session: second.ID, expected: "users of " + second.ID},
+		{name: "outside sessions", expected: "configured users"},
+		{name: "unknown session", session: "unknown", expected: "configured users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, body := call("GET", "/users", tt.session, nil); body != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, body)
+			}
+		})
+	}
+
+	// Listing under a session only shows its routes
+	_, body := call("GET", "/__admin/routes", first.ID, nil)
+	var listed struct {
+		Routes []runtimeRouteResponse `json:"routes"`
+	}
+	if err := json.Unmarshal([]byte(body), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(listed.Routes) != 1 || listed.Routes[0].Session != first.ID {
+		t.Errorf("Expected the first session's route, got %+v", listed.Routes)
+	}
+
+	// Routes can only be deleted under the session they were created under
+	id := listed.Routes[0].ID
+	for _, session := range []string{second.ID, ""} {
+		if status, body := call("DELETE", "/__admin/routes/"+id, session, nil); status != http.StatusNotFound {
+			t.Errorf("Expected a 404 deleting another session's route under %q, got %d: %s", session, status, body)
+		}
+	}
+	if _, body := call("GET", "/users", first.ID, nil); body != "users of "+first.ID {
+		t.Errorf("Expected the first session's route to remain, got %q", body)
+	}
+
+	// Deleting every route outside sessions leaves the sessions' routes alone
+	if status, body := call("DELETE", "/__admin/routes", "", nil); status != http.StatusOK || !strings.Contains(body, `"deleted": 0`) {
+		t.Errorf("Expected no routes outside sessions to be deleted, got %d: %s", status, body)
+	}
+
+	// Admin requests under a session that isn't open fail
+	if status, body := call("POST", "/__admin/routes", "unknown", strings.NewReader(`{"path": "/x", "method": "GET", "template": "x"}`)); status != http.StatusNotFound || !strings.Contains(body, `session \"unknown\" not found`) {
+		t.Errorf("Expected a 404 for an unknown session, got %d: %s", status, body)
+	}
+
+	// Closing a session removes its routes, leaving the other's in place
+	status, body := call("DELETE", "/__admin/sessions/"+first.ID, "", nil)
+	if status != http.StatusOK || !strings.Contains(body, `"routes": 1`) {
+		t.Errorf("Expected the session's route to be removed, got %d: %s", status, body)
+	}
+	if _, body := call("GET", "/users", first.ID, nil); body != "configured users" {
+		t.Errorf("Expected the configured route after closing the session, got %q", body)
+	}
+	if _, body := call("GET", "/users", second.ID, nil); body != "users of "+second.ID {
+		t.Errorf("Expected the other session's route to remain, got %q", body)
+	}
+	if status, _ := call("DELETE", "/__admin/sessions/"+first.ID, "", nil); status != http.StatusNotFound {
+		t.Errorf("Expected a 404 when closing a closed session, got %d", status)
+	}
+
+	// Closing every session removes every session's routes
+	if status, body := call("DELETE", "/__admin/sessions", "", nil); status != http.StatusOK || !strings.Contains(body, `"closed": 1`) {
+		t.Errorf("Expected one session to be closed, got %d: %s", status, body)
+	}
+	if routes := ts.runtimeRoutes.list(); len(routes) != 0 {
+		t.Errorf("Expected no runtime routes left, got %d", len(routes))
+	}
+}
+
+func TestServer_Admin_SessionSequences(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/jobs/1",
+			Method: "GET",
+			Responses: []config.ResponseConfig{
+				{Template: "pending"},
+				{Template: "done"},
+			},
+			Sequence: &config.SequenceConfig{},
+		},
+	})
+
+	ts := NewTestServer(t, cfg)
+	sess := openTestSession(t, ts, "")
+
+	call := func(session string) string {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/jobs/1", nil, map[string]string{headerSession: session})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+
+	// The session advances a sequence of its own
+	for _, expected := range []string{"pending", "done"} {
+		if got := call(sess.ID); got != expected {
+			t.Errorf("Expected %q in the session, got %q", expected, got)
+		}
+	}
+	if got := call(""); got != "pending" {
+		t.Errorf("Expected the shared sequence to start over, got %q", got)
+	}
+
+	// Resets only cover the state of the session they're made under
+	reset := func(session string) string {
+		t.Helper()
+		resp, err := ts.makeRequest("DELETE", "/__admin/scenarios", nil, map[string]string{headerSession: session})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+	if body := reset(""); !strings.Contains(body, `"reset": 1`) {
+		t.Errorf("Expected only the shared position to be reset, got %s", body)
+	}
+	if got := call(sess.ID); got != "done" {
+		t.Errorf("Expected the session's sequence to be kept, got %q", got)
+	}
+	if body := reset(sess.ID); !strings.Contains(body, `"reset": 1`) {
+		t.Errorf("Expected the session's position to be reset, got %s", body)
+	}
+	if got := call(sess.ID); got != "pending" {
+		t.Errorf("Expected the session's sequence to start over, got %q", got)
+	}
+	call("")
+
+	// Closing the session drops its position
+	s := ts.Server
+	if removed := s.closeSession(sess.ID); removed["routes"] != 0 || removed["scenarios"] != 1 {
+		t.Errorf("Expected one sequence position removed, got %v", removed)
+	}
+	if states := s.scenarios.list(); len(states) != 1 || states[0].Session != "" {
+		t.Errorf("Expected only the shared position to remain, got %+v", states)
+	}
+}
+
+func TestServer_Admin_SessionStores(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/login", Method: "POST", Template: `{{ .Tokens.Mint "access" }}`, Expect: &config.ExpectConfig{Calls: intPtr(1)}},
+		{Path: "/me", Method: "GET", Template: "me", RequireToken: &config.RequireTokenConfig{Name: "access"}},
+		{
+			Path:        "/payments/{id}/authorize",
+			Method:      "POST",
+			Transaction: &config.TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: "authorize"},
+			Template:    "{{ .Transaction.State }}",
+		},
+		{Path: "/orders", Method: "POST", Template: "{{ uuidv4 }}", Idempotency: &config.IdempotencyConfig{}},
+	})
+	cfg.TokenBucket = map[string]config.TokenConfig{"access": {TTL: time.Hour}}
+	cfg.Transactions = map[string]config.TransactionConfig{"payment": paymentTransaction()}
+
+	ts := NewTestServer(t, cfg)
+	first := openTestSession(t, ts, "")
+	second := openTestSession(t, ts, "")
+
+	call := func(method, path, session string, headers map[string]string) (int, string) {
+		t.Helper()
+		all := map[string]string{headerSession: session}
+		for name, value := range headers {
+			all[name] = value
+		}
+		resp, err := ts.makeRequest(method, path, nil, all)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp.StatusCode, readResponseBody(t, resp)
+	}
+
+	// Tokens minted under a session are only accepted under it
+	_, token := call("POST", "/login", first.ID, nil)
+	bearer := map[string]string{"Authorization": "Bearer " + token}
+	if status, _ := call("GET", "/me", first.ID, bearer); status != http.StatusOK {
+		t.Errorf("Expected the token to be accepted in its session, got %d", status)
+	}
+	if status, _ := call("GET", "/me", second.ID, bearer); status != http.StatusUnauthorized {
+		t.Errorf("Expected the token to be rejected in another session, got %d", status)
+	}
+	if status, _ := call("GET", "/me", "", bearer); status != http.StatusUnauthorized {
+		t.Errorf("Expected the token to be rejected outside sessions, got %d", status)
+	}
+
+	// Each session moves transactions of its own
+	if status, body := call("POST", "/payments/1/authorize", first.ID, nil); status != http.StatusOK || body != "authorized" {
+		t.Errorf("Expected the payment to be authorized, got %d %s", status, body)
+	}
+	if status, body := call("POST", "/payments/1/authorize", second.ID, nil); status != http.StatusOK || body != "authorized" {
+		t.Errorf("Expected the other session's payment to be authorized, got %d %s", status, body)
+	}
+	if status, _ := call("POST", "/payments/1/authorize", first.ID, nil); status != http.StatusConflict {
+		t.Errorf("Expected status 409 authorizing twice in a session, got %d", status)
+	}
+
+	// Idempotency keys aren't replayed across sessions
+	key := map[string]string{config.DefaultIdempotencyHeader: "abc"}
+	_, firstOrder := call("POST", "/orders", first.ID, key)
+	if _, replayed := call("POST", "/orders", first.ID, key); replayed != firstOrder {
+		t.Errorf("Expected the order to be replayed in its session, got %q and %q", firstOrder, replayed)
+	}
+	if _, other := call("POST", "/orders", second.ID, key); other == firstOrder {
+		t.Errorf("Expected the order not to be replayed in another session, got %q", other)
+	}
+
+	// Expectations are verified against the calls of the session only
+	for session, expected := range map[string]int{first.ID: http.StatusOK, second.ID: http.StatusExpectationFailed, "": http.StatusExpectationFailed} {
+		if status, body := call("GET", "/__admin/verify", session, nil); status != expected {
+			t.Errorf("Expected status %d verifying under session %q, got %d: %s", expected, session, status, body)
+		}
+	}
+
+	// Closing a session removes its state, keeping the other session's
+	status, body := call("DELETE", "/__admin/sessions/"+first.ID, "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200 closing the session, got %d: %s", status, body)
+	}
+	var removed map[string]int
+	if err := json.Unmarshal([]byte(body), &removed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := map[string]int{"routes": 0, "scenarios": 0, "transactions": 1, "idempotency": 1, "calls": 4, "tokens": 1}
+	for kind, count := range expected {
+		if removed[kind] != count {
+			t.Errorf("Expected %d %s removed, got %d", count, kind, removed[kind])
+		}
+	}
+
+	s := ts.Server
+	if states := s.transactions.list(); len(states) != 1 || states[0].Session != second.ID {
+		t.Errorf("Expected only the other session's transaction to remain, got %+v", states)
+	}
+	if states := s.idempotency.list(); len(states) != 1 || states[0].Session != second.ID {
+		t.Errorf("Expected only the other session's idempotency key to remain, got %+v", states)
+	}
+	if states := s.tokens.list(); len(states) != 0 {
+		t.Errorf("Expected the session's token to be revoked, got %+v", states)
+	}
+	if stats := s.calls.get("POST /login", first.ID); stats.Calls != 0 {
+		t.Errorf("Expected the session's calls to be forgotten, got %d", stats.Calls)
+	}
+}
+
+func TestServer_Admin_SessionExpiry(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/", Method: "GET", Template: "root"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	now := time.Now()
+	ts.sessions.now = func() time.Time { return now }
+	ts.runtimeRoutes.now = func() time.Time { return now }
+
+	sess := openTestSession(t, ts, `{"expires_in": "1h"}`)
+	if sess.ExpiresAt == nil || !sess.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Expected expiry one hour from now, got %v", sess.ExpiresAt)
+	}
+
+	// Routes of the session expire with it, even when they'd last longer
+	resp, err := ts.makeRequest("POST", "/__admin/routes", strings.NewReader(`{"path": "/temporary", "method": "GET", "template": "x", "expires_in": "2h"}`), map[string]string{headerSession: sess.ID})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var created runtimeRouteResponse
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.ExpiresAt == nil || !created.ExpiresAt.Equal(*sess.ExpiresAt) {
+		t.Errorf("Expected the route to expire with the session, got %v", created.ExpiresAt)
+	}
+
+	now = now.Add(time.Hour)
+
+	resp, err = ts.makeRequest("GET", "/__admin/sessions", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); !strings.Contains(body, `"sessions": []`) {
+		t.Errorf("Expected the expired session to be gone, got %s", body)
+	}
+	if routes := ts.runtimeRoutes.list(); len(routes) != 0 {
+		t.Errorf("Expected the session's route to be gone, got %d routes", len(routes))
+	}
+
+	resp, err = ts.makeRequest("POST", "/__admin/sessions", strings.NewReader(`{"expires_in": "-1m"}`), nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "expires_in cannot be negative") {
+		t.Errorf("Expected a negative expiry to be rejected, got %d: %s", resp.StatusCode, body)
+	}
+}
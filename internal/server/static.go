@@ -0,0 +1,150 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// serveStatic answers with a file of the route's directory, returning the
+// status code sent. Directories are answered with their index file, or with
+// a listing of their files when the route allows it, and paths that don't
+// exist are answered with a 404.
+func (s *Server) serveStatic(w http.ResponseWriter, r *http.Request, static *router.StaticDir) int {
+	rw := middleware.NewResponseWriter(w)
+	dir := http.Dir(static.Root)
+	name := path.Clean(static.FilePath(r.URL.Path))
+
+	info, err := statFile(dir, name)
+	if err != nil {
+		s.handleStaticError(rw, r, err)
+		return rw.Status()
+	}
+
+	if info.IsDir() {
+		// Redirect to the trailing slash, so relative links in the index
+		// file or listing resolve inside the directory
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			target := r.URL.Path + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(rw, r, target, http.StatusMovedPermanently)
+			return rw.Status()
+		}
+
+		index := path.Join(name, static.Index)
+		if indexInfo, err := statFile(dir, index); err == nil && !indexInfo.IsDir() {
+			s.serveStaticFile(rw, r, dir, index)
+			return rw.Status()
+		}
+
+		if !static.Listing {
+			handleFileNotFound(rw, r)
+			return rw.Status()
+		}
+		if err := writeStaticListing(rw, dir, name); err != nil {
+			s.handleStaticError(rw, r, err)
+		}
+		return rw.Status()
+	}
+
+	s.serveStaticFile(rw, r, dir, name)
+	return rw.Status()
+}
+
+// serveStaticFile answers with the file at name, supporting range and
+// conditional requests
+func (s *Server) serveStaticFile(w http.ResponseWriter, r *http.Request, dir http.Dir, name string) {
+	file, err := dir.Open(name)
+	if err != nil {
+		s.handleStaticError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		s.handleStaticError(w, r, err)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// handleStaticError answers with a 404 for files that don't exist, and a
+// 500 for any other error reading them
+func (s *Server) handleStaticError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		handleFileNotFound(w, r)
+		return
+	}
+	s.handleServerError(w, r, fmt.Errorf("failed to read static file: %w", err))
+}
+
+// handleFileNotFound answers with a 404 for paths of a static directory
+// without a file to serve
+func handleFileNotFound(w http.ResponseWriter, r *http.Request) {
+	detail := fmt.Sprintf("no file to serve for %s", r.URL.Path)
+	problem.Write(w, r, http.StatusNotFound, problem.CodeFileNotFound, detail, "404 Not Found: "+detail)
+}
+
+// statFile returns the file info of the file at name in dir
+func statFile(dir http.Dir, name string) (fs.FileInfo, error) {
+	file, err := dir.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return file.Stat()
+}
+
+// writeStaticListing answers with an HTML page linking to the files of the
+// directory at name, directories first, each group in alphabetical order
+func writeStaticListing(w http.ResponseWriter, dir http.Dir, name string) error {
+	file, err := dir.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entries, err := file.Readdir(-1)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(entries, func(a, b fs.FileInfo) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	var page strings.Builder
+	title := html.EscapeString(name)
+	fmt.Fprintf(&page, "<!doctype html>\n<html>\n<head><title>Index of %s</title></head>\n<body>\n<h1>Index of %s</h1>\n<ul>\n", title, title)
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := url.URL{Path: entryName}
+		fmt.Fprintf(&page, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(link.String()), html.EscapeString(entryName))
+	}
+	page.WriteString("</ul>\n</body>\n</html>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err = w.Write([]byte(page.String()))
+	return err
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_StaticDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":      "<h1>home</h1>",
+		"sdk.js":          "export const version = 1",
+		"docs/guide.txt":  "read me",
+		"docs/start.htm":  "<h1>start</h1>",
+		"downloads/a.zip": "PK\x03\x04",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/api/users", Method: "GET", Template: "users"},
+		{
+			Path:            "/static",
+			Method:          "GET",
+			StaticDir:       &config.StaticDirConfig{Root: dir},
+			ResponseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+		},
+		{Path: "/browse", Method: "GET", StaticDir: &config.StaticDirConfig{Root: dir, Index: "start.htm", Listing: true}},
+	})
+
+	ts := NewTestServer(t, cfg)
+	ts.Client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		body        string
+		contains    []string
+		contentType string
+		location    string
+	}{
+		{name: "file", path: "/static/sdk.js", status: 200, body: files["sdk.js"], contentType: "text/javascript; charset=utf-8"},
+		{name: "nested file", path: "/static/docs/guide.txt", status: 200, body: files["docs/guide.txt"]},
+		{name: "index file", path: "/static/", status: 200, body: files["index.html"], contentType: "text/html; charset=utf-8"},
+		{name: "directory redirect", path: "/static/docs", status: 301, location: "/static/docs/"},
+		{name: "directory without index", path: "/static/downloads/", status: 404},
+		{name: "missing file", path: "/static/missing.js", status: 404},
+		{name: "outside the directory", path: "/static/../../etc/passwd", status: 404},
+		{name: "other routes", path: "/api/users", status: 200, body: "users"},
+		{name: "custom index", path: "/browse/docs/", status: 200, body: files["docs/start.htm"]},
+		{name: "listing", path: "/browse/downloads/", status: 200, contains: []string{`<a href="a.zip">a.zip</a>`}},
+		{name: "listing directories first", path: "/browse/", status: 200, contains: []string{`<li><a href="docs/">docs/</a></li>` + "\n" + `<li><a href="downloads/">downloads/</a></li>` + "\n" + `<li><a href="index.html">`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, resp.StatusCode, body)
+			}
+			if tt.body != "" && body != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, body)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("Expected body to contain %q, got:\n%s", want, body)
+				}
+			}
+			if tt.contentType != "" && resp.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.contentType, resp.Header.Get("Content-Type"))
+			}
+			if tt.location != "" && resp.Header.Get("Location") != tt.location {
+				t.Errorf("Expected Location %q, got %q", tt.location, resp.Header.Get("Location"))
+			}
+		})
+	}
+
+	t.Run("route headers", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/static/sdk.js", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+		if got := resp.Header.Get("Cache-Control"); got != "max-age=60" {
+			t.Errorf("Expected Cache-Control from the route, got %q", got)
+		}
+	})
+
+	t.Run("range request", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/static/sdk.js", nil, map[string]string{"Range": "bytes=0-5"})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if body := readResponseBody(t, resp); resp.StatusCode != 206 || body != "export" {
+			t.Errorf("Expected a partial response, got %d %q", resp.StatusCode, body)
+		}
+	})
+}
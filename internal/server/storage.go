@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/storage"
+)
+
+// Names of the storage collections, one per kind of captured data
+const (
+	journalCollection    = "journal"
+	scenariosCollection  = "scenarios"
+	recordingsCollection = "recordings"
+)
+
+// openStores opens the configured storage driver and creates the stores
+// keeping captured data, restoring what was persisted by a previous run
+func (s *Server) openStores(cfg *config.Config) error {
+	s.storageConfig = cfg.Storage.GetWithDefaults()
+
+	driver, err := storage.Open(s.storageConfig.Driver, s.storageConfig.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	s.storage = driver
+
+	err = func() error {
+		journalStore, err := driver.Open(journalCollection)
+		if err != nil {
+			return err
+		}
+		if s.journal, err = newJournal(cfg.Journal, journalStore, s.logger, s.metrics); err != nil {
+			return fmt.Errorf("failed to create request journal: %w", err)
+		}
+
+		scenarioStore, err := driver.Open(scenariosCollection)
+		if err != nil {
+			return err
+		}
+		if s.scenarios, err = newScenarioStore(scenarioStore, s.logger); err != nil {
+			return err
+		}
+
+		recordingStore, err := driver.Open(recordingsCollection)
+		if err != nil {
+			return err
+		}
+		s.recordings, err = newRecordingStore(recordingStore, s.logger)
+		return err
+	}()
+	if err != nil {
+		return errors.Join(err, driver.Close())
+	}
+
+	return nil
+}
+
+// checkStorageConfig warns when a reloaded configuration changes the storage
+// driver, which only takes effect after a restart
+func (s *Server) checkStorageConfig(cfg *config.Config) {
+	if next := cfg.Storage.GetWithDefaults(); next != s.storageConfig {
+		s.logger.Warn("storage changes require a restart to take effect",
+			"driver", next.Driver, "path", next.Path,
+			"current_driver", s.storageConfig.Driver, "current_path", s.storageConfig.Path)
+	}
+}
+
+// closeStorage stops persisting captured data
+func (s *Server) closeStorage() {
+	if err := s.journal.close(); err != nil {
+		s.logger.Warn("error closing request journal", "error", err)
+	}
+	if err := s.storage.Close(); err != nil {
+		s.logger.Warn("error closing storage", "error", err)
+	}
+}
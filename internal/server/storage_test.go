@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_PersistentStorage(t *testing.T) {
+	drivers := map[string]string{
+		"file":   "data",
+		"sqlite": "mockingjay.db",
+	}
+
+	for driver, path := range drivers {
+		t.Run(driver, func(t *testing.T) {
+			testPersistentStorage(t, config.StorageConfig{Driver: driver, Path: filepath.Join(t.TempDir(), path)})
+		})
+	}
+}
+
+// testPersistentStorage checks that a server using storage picks up where a
+// previous one using the same storage left off
+func testPersistentStorage(t *testing.T, storage config.StorageConfig) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("upstream " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/jobs/1",
+			Method: "GET",
+			Responses: []config.ResponseConfig{
+				{Template: "pending"},
+				{Template: "done"},
+			},
+			Sequence: &config.SequenceConfig{},
+		},
+		{
+			Path:   "/api/users",
+			Method: "GET",
+			Proxy:  &config.ProxyConfig{URL: upstream.URL, Record: true},
+		},
+	})
+	cfg.Storage = storage
+
+	call := func(ts *TestServer, path string) string {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", path, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+
+	ts := NewTestServer(t, cfg)
+	if body := call(ts, "/jobs/1"); body != "pending" {
+		t.Fatalf("Expected first sequenced response, got %q", body)
+	}
+	call(ts, "/api/users")
+	ts.Close()
+	ts.Server.closeStorage()
+
+	// A new server picks up where the previous one left off
+	restarted := NewTestServer(t, cfg)
+	defer restarted.Server.closeStorage()
+
+	if body := call(restarted, "/jobs/1"); body != "done" {
+		t.Errorf("Expected the sequence to continue after a restart, got %q", body)
+	}
+
+	if recordings := restarted.Server.recordings.list(); len(recordings) != 1 || !strings.HasPrefix(recordings[0].Body, "upstream /api/users") {
+		t.Errorf("Expected the recording to be restored, got %+v", recordings)
+	}
+
+	entries, total := restarted.Server.journal.find(journalQuery{Limit: 10, Oldest: true})
+	if total != 3 || entries[0].Path != "/jobs/1" || entries[1].Path != "/api/users" {
+		t.Errorf("Expected the journal to be restored with the new request, got %+v", entries)
+	}
+}
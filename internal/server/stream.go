@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// errStreamClosed is returned to templates writing after their response ended
+var errStreamClosed = errors.New("response stream is closed")
+
+// streamWriter writes template output to the client as it's rendered. The
+// first bytes are held back before the response starts, so templates can
+// still pick the status and headers, and errors rendering them can still get
+// a proper error response.
+type streamWriter struct {
+	mu    sync.Mutex
+	w     http.ResponseWriter
+	rc    *http.ResponseController
+	held  bytes.Buffer
+	limit int
+
+	// begin picks the status and final body of the held bytes right before
+	// the response starts. When final, the held bytes are the whole body.
+	begin func(held []byte, final bool) (int, []byte, error)
+
+	status    int
+	committed bool  // Whether the status and headers were sent
+	discard   bool  // Whether the status forbids a body, so output is dropped
+	pending   bool  // Whether there's output not flushed yet
+	closed    bool  // Whether the response ended, failing any later write
+	rejected  error // Why begin refused to start the response
+}
+
+// newStreamWriter creates a streamWriter holding back up to limit bytes
+func newStreamWriter(w http.ResponseWriter, limit int, begin func(held []byte, final bool) (int, []byte, error)) *streamWriter {
+	return &streamWriter{w: w, rc: http.NewResponseController(w), limit: limit, begin: begin}
+}
+
+// Write holds output back until the buffer fills up, then starts the response
+// and passes output straight through
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return 0, errStreamClosed
+	}
+	if sw.rejected != nil {
+		return 0, sw.rejected
+	}
+
+	if !sw.committed {
+		sw.held.Write(p)
+		if sw.held.Len() < sw.limit {
+			return len(p), nil
+		}
+		if err := sw.commit(false); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if sw.discard {
+		return len(p), nil
+	}
+	sw.pending = true
+	return sw.w.Write(p)
+}
+
+// commit starts the response with the held bytes. When final, the held bytes
+// are the whole body, so its length is known. Callers must hold sw.mu.
+func (sw *streamWriter) commit(final bool) error {
+	status, body, err := sw.begin(sw.held.Bytes(), final)
+	if err != nil {
+		sw.rejected = err
+		return err
+	}
+
+	sw.status = status
+	sw.committed = true
+	sw.discard = config.StatusForbidsBody(status)
+	sw.held.Reset()
+
+	if final && !sw.discard {
+		sw.w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	sw.w.WriteHeader(status)
+
+	if len(body) == 0 || sw.discard {
+		return nil
+	}
+	sw.pending = true
+	_, err = sw.w.Write(body)
+	return err
+}
+
+// flush sends pending output to the client
+func (sw *streamWriter) flush() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed || !sw.pending {
+		return
+	}
+	sw.pending = false
+	_ = sw.rc.Flush()
+}
+
+// flushEvery flushes pending output at the given interval until stop is closed
+func (sw *streamWriter) flushEvery(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sw.flush()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// finish ends the response once the template rendered, starting it first if
+// the whole output was held back
+func (sw *streamWriter) finish() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return errStreamClosed
+	}
+	if !sw.committed {
+		if err := sw.commit(true); err != nil {
+			return err
+		}
+	}
+	if sw.pending {
+		_ = sw.rc.Flush()
+	}
+	sw.closed = true
+	return nil
+}
+
+// close ends the response early, reporting whether it had already started
+// and with which status
+func (sw *streamWriter) close() (bool, int) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.closed = true
+	return sw.committed, sw.status
+}
+
+// streamTemplate renders a template straight to the client, flushing output
+// as it's written, and returns the status it answered with. Errors before the
+// response starts get an error response, while errors after it cut the
+// connection, since the status was already sent.
+func (s *Server) streamTemplate(rt *routing, w http.ResponseWriter, r *http.Request, stream *router.Stream, tmpl *template.Template, ctx *templatepkg.TemplateContext, defaultStatus int, start time.Time) int {
+	sw := newStreamWriter(w, stream.BufferSize, func(held []byte, final bool) (int, []byte, error) {
+		status := ctx.Response.StatusOr(defaultStatus)
+		setCookies(w, ctx.Response)
+		if contentType := ctx.Response.ContentType(); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		setDefaultContentType(w.Header(), rt.contentType, held, !final)
+
+		// In strict HTTP mode, fix or reject responses breaking basic HTTP rules
+		body, err := s.enforceHTTPRules(rt, w, r, status, held)
+		return status, body, err
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go sw.flushEvery(stream.FlushInterval, stop)
+
+	templateDone := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				templateDone <- fmt.Errorf("template execution panicked: %v", recovered)
+			}
+		}()
+		if err := rt.engine.ExecuteTemplate(tmpl, sw, ctx); err != nil {
+			templateDone <- err
+			return
+		}
+		templateDone <- sw.finish()
+	}()
+
+	select {
+	case err := <-templateDone:
+		if err == nil {
+			return sw.status
+		}
+
+		if started, status := sw.close(); started {
+			return s.abortStream(w, r, status, err)
+		}
+
+		if sw.rejected != nil {
+			s.handleServerError(w, r, fmt.Errorf("strict HTTP: %w", sw.rejected))
+			return http.StatusInternalServerError
+		}
+		return s.handleTemplateError(w, r, err)
+
+	case <-r.Context().Done():
+		// Stop the template from writing any further, letting it finish in
+		// the background
+		go func() {
+			<-templateDone
+		}()
+
+		if started, status := sw.close(); started {
+			return s.abortStream(w, r, status, r.Context().Err())
+		}
+
+		s.logger.Warn("request timeout - terminating",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"timeout", "context cancelled",
+			"remote_addr", r.RemoteAddr,
+		)
+		return s.handleRequestTimeout(w, r, time.Since(start))
+	}
+}
+
+// abortStream cuts the connection of a streamed response that failed after
+// it started, so clients see it end early instead of as complete
+func (s *Server) abortStream(w http.ResponseWriter, r *http.Request, status int, err error) int {
+	s.logger.Error("streamed response failed after it started",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", status,
+		"error", err,
+		"remote_addr", r.RemoteAddr,
+	)
+
+	_ = http.NewResponseController(w).Flush()
+	abortConnection(w, false)
+	return status
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_Stream(t *testing.T) {
+	route := func(path, tmpl string, stream config.StreamConfig) config.RouteConfig {
+		return config.RouteConfig{Path: path, Method: "GET", Template: tmpl, Stream: &stream}
+	}
+
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{
+		route("/small", `{"id": 1}`, config.StreamConfig{}),
+		route("/large", `{{ range until 100 }}row {{ . }}{{ "\n" }}{{ end }}`, config.StreamConfig{BufferSize: 64}),
+		route("/status", `{{ .Response.SetStatus 201 }}created`, config.StreamConfig{BufferSize: 1}),
+		route("/no-content", `{{ .Response.SetStatus 204 }}{{ range until 10 }}ignored{{ end }}`, config.StreamConfig{BufferSize: 4}),
+		route("/early-error", `{{ fail "broken" }}`, config.StreamConfig{}),
+		route("/late-error", `{{ range until 10 }}row {{ . }}{{ "\n" }}{{ end }}{{ fail "broken" }}`, config.StreamConfig{BufferSize: 8}),
+	}))
+
+	var rows strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&rows, "row %d\n", i)
+	}
+
+	tests := []struct {
+		name              string
+		path              string
+		wantStatus        int
+		wantBody          string
+		wantContentLength int64
+	}{
+		{name: "held back whole", path: "/small", wantStatus: 200, wantBody: `{"id": 1}`, wantContentLength: 9},
+		{name: "streamed", path: "/large", wantStatus: 200, wantBody: rows.String(), wantContentLength: -1},
+		{name: "status picked before start", path: "/status", wantStatus: 201, wantBody: "created", wantContentLength: -1},
+		{name: "status forbidding a body", path: "/no-content", wantStatus: 204, wantContentLength: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest("GET", tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if resp.ContentLength != tt.wantContentLength {
+				t.Errorf("Expected content length %d, got %d", tt.wantContentLength, resp.ContentLength)
+			}
+			if body != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, body)
+			}
+		})
+	}
+
+	t.Run("error before start", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/early-error", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body := readResponseBody(t, resp)
+
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", resp.StatusCode)
+		}
+		if !strings.Contains(body, "cannot be rendered") {
+			t.Errorf("Expected a template error response, got %q", body)
+		}
+	})
+
+	t.Run("error after start", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/late-error", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			t.Fatalf("Expected the connection to be cut, got complete body %q", body)
+		}
+		if !strings.HasPrefix(string(body), "row 0\n") {
+			t.Errorf("Expected the rows written before the error, got %q", body)
+		}
+	})
+}
+
+func TestServer_Integration_StreamFlushes(t *testing.T) {
+	ts := NewTestServer(t, createTestConfig([]config.RouteConfig{{
+		Path:     "/events",
+		Method:   "GET",
+		Template: `first{{ "\n" }}{{ sleep "1s" }}second{{ "\n" }}`,
+		Stream:   &config.StreamConfig{BufferSize: 1, FlushInterval: 10 * time.Millisecond},
+	}}))
+
+	start := time.Now()
+	resp, err := ts.makeRequest("GET", "/events", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The first line must arrive while the template is still sleeping
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the first line: %v", err)
+	}
+	if line != "first\n" {
+		t.Errorf("Expected the first line, got %q", line)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Expected the first line before the template finished, got it after %s", elapsed)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// defaultWWWAuthenticate is the challenge added to 401 responses lacking one
+// when strict HTTP mode fixes responses
+const defaultWWWAuthenticate = `Bearer realm="mockingjay"`
+
+// enforceHTTPRules checks a generated response against basic HTTP rules before
+// it's written. In "fix" mode, the headers are repaired in place and the body
+// to write is returned. In "reject" mode, the first violation is returned as
+// an error.
+func (s *Server) enforceHTTPRules(rt *routing, w http.ResponseWriter, r *http.Request, status int, body []byte) ([]byte, error) {
+	if rt.strictHTTP == "" {
+		return body, nil
+	}
+	reject := rt.strictHTTP == config.StrictHTTPReject
+
+	if status >= 100 && status < 200 && reject {
+		return nil, fmt.Errorf("status %d is informational and can't be used as a final response", status)
+	}
+
+	if config.StatusForbidsBody(status) && len(body) > 0 {
+		if reject {
+			return nil, fmt.Errorf("status %d responses must not have a body, got %d bytes", status, len(body))
+		}
+		body = nil
+	}
+
+	switch {
+	case status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "":
+		if reject {
+			return nil, fmt.Errorf("status 401 responses must include a WWW-Authenticate header")
+		}
+		w.Header().Set("WWW-Authenticate", defaultWWWAuthenticate)
+
+	case status == http.StatusMethodNotAllowed && w.Header().Get("Allow") == "":
+		if reject {
+			return nil, fmt.Errorf("status 405 responses must include an Allow header")
+		}
+		w.Header().Set("Allow", strings.Join(s.allowedMethods(rt, r.URL.Path), ", "))
+	}
+
+	return body, nil
+}
+
+// allowedMethods returns the methods of every route of rt serving the given
+// path, sorted
+func (s *Server) allowedMethods(rt *routing, path string) []string {
+	var methods []string
+	var add func(method string)
+	add = func(method string) {
+		method = strings.ToUpper(method)
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+		// GET routes serve HEAD requests too
+		if method == http.MethodGet {
+			add(http.MethodHead)
+		}
+	}
+
+	for _, rr := range s.runtimeRoutes.list() {
+		if rr.Route.MatchesPath(path) {
+			add(rr.Route.Method)
+		}
+	}
+	for _, route := range rt.routes {
+		if route.MatchesPath(path) {
+			add(route.Method)
+		}
+	}
+
+	slices.Sort(methods)
+	return methods
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// strictTestRoutes returns routes whose templates pick statuses that break
+// basic HTTP rules at runtime
+func strictTestRoutes() []config.RouteConfig {
+	return []config.RouteConfig{
+		{Path: "/items", Method: "DELETE", Template: `{{ .Response.SetStatus 204 }}{"deleted": true}`},
+		{Path: "/items", Method: "PUT", Template: `{{ .Response.SetStatus 405 }}read only`},
+		{Path: "/items", Method: "GET", Template: `[]`},
+		{Path: "/secret", Method: "GET", Template: `{{ .Response.SetStatus 401 }}login first`},
+		{Path: "/auth", Method: "GET", ResponseHeaders: map[string]string{"WWW-Authenticate": `Basic realm="test"`}, Template: `{{ .Response.SetStatus 401 }}login first`},
+	}
+}
+
+func TestServer_Integration_StrictHTTPFix(t *testing.T) {
+	cfg := createTestConfig(strictTestRoutes())
+	cfg.Server.StrictHTTP = config.StrictHTTPFix
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		status       int
+		header       string
+		headerValue  string
+		expectedBody string
+	}{
+		{name: "body dropped from 204", method: "DELETE", path: "/items", status: http.StatusNoContent},
+		{name: "allow added to 405", method: "PUT", path: "/items", status: http.StatusMethodNotAllowed, header: "Allow", headerValue: "DELETE, GET, HEAD, PUT", expectedBody: "read only"},
+		{name: "challenge added to 401", method: "GET", path: "/secret", status: http.StatusUnauthorized, header: "WWW-Authenticate", headerValue: defaultWWWAuthenticate, expectedBody: "login first"},
+		{name: "configured challenge kept", method: "GET", path: "/auth", status: http.StatusUnauthorized, header: "WWW-Authenticate", headerValue: `Basic realm="test"`, expectedBody: "login first"},
+		{name: "valid responses untouched", method: "GET", path: "/items", status: http.StatusOK, expectedBody: "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+			if tt.header != "" && resp.Header.Get(tt.header) != tt.headerValue {
+				t.Errorf("Expected %s header %q, got %q", tt.header, tt.headerValue, resp.Header.Get(tt.header))
+			}
+		})
+	}
+}
+
+func TestServer_Integration_StrictHTTPReject(t *testing.T) {
+	cfg := createTestConfig(strictTestRoutes())
+	cfg.Server.StrictHTTP = config.StrictHTTPReject
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: "DELETE", path: "/items", status: http.StatusInternalServerError},
+		{method: "PUT", path: "/items", status: http.StatusInternalServerError},
+		{method: "GET", path: "/secret", status: http.StatusInternalServerError},
+		{method: "GET", path: "/auth", status: http.StatusUnauthorized},
+		{method: "GET", path: "/items", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := ts.makeRequest(tt.method, tt.path, nil, nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// ConfigSummary describes the configuration a server is actually running
+// with, after defaults are applied and with secrets redacted
+type ConfigSummary struct {
+	Version        string              `json:"version"`
+	Addr           string              `json:"addr,omitempty"`    // Listening address, empty for tenants served by prefix
+	TLS            bool                `json:"tls"`               // Whether HTTPS is served
+	Routes         int                 `json:"routes"`            // Configured routes, without those created through the admin API
+	RoutesByMethod map[string]int      `json:"routes_by_method"`  // Configured routes by HTTP method
+	GRPCMethods    int                 `json:"grpc_methods"`      // Mocked gRPC methods
+	Tenants        []string            `json:"tenants,omitempty"` // Names of the tenants hosted alongside this server
+	Middleware     []MiddlewareSummary `json:"middleware"`        // Enabled middleware, in order
+	Timeouts       TimeoutsSummary     `json:"timeouts"`
+}
+
+// MiddlewareSummary describes an enabled middleware and its settings
+type MiddlewareSummary struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings,omitempty"` // Secret settings, like passwords, are redacted
+}
+
+// TimeoutsSummary describes the server's timeouts, with defaults applied
+type TimeoutsSummary struct {
+	Read       string `json:"read"`
+	Write      string `json:"write"`
+	Idle       string `json:"idle"`
+	ReadHeader string `json:"read_header"`
+	Shutdown   string `json:"shutdown"`
+}
+
+// summarizeTimeouts describes timeouts with their defaults applied
+func summarizeTimeouts(tc config.TimeoutConfig) TimeoutsSummary {
+	timeouts := tc.GetWithDefaults()
+	return TimeoutsSummary{
+		Read:       timeouts.Read.String(),
+		Write:      timeouts.Write.String(),
+		Idle:       timeouts.Idle.String(),
+		ReadHeader: timeouts.ReadHeader.String(),
+		Shutdown:   timeouts.Shutdown.String(),
+	}
+}
+
+// ConfigSummary returns a summary of the configuration the server is running
+// with. Routes, middleware and tenants follow reloads, while the address,
+// TLS and timeouts only change on restart.
+func (s *Server) ConfigSummary() ConfigSummary {
+	rt := s.current()
+	summary := ConfigSummary{
+		Version:        s.appVersion,
+		Addr:           s.httpServer.Addr,
+		TLS:            s.httpServer.TLSConfig != nil,
+		Routes:         len(rt.routes),
+		RoutesByMethod: make(map[string]int),
+		GRPCMethods:    len(rt.grpcMethods),
+		Middleware:     make([]MiddlewareSummary, 0, len(rt.middlewares.Enabled)),
+		Timeouts:       s.timeouts,
+	}
+
+	for _, route := range rt.routes {
+		summary.RoutesByMethod[route.Method]++
+	}
+	for _, t := range rt.tenants {
+		summary.Tenants = append(summary.Tenants, t.config.Name)
+	}
+	for _, mc := range rt.middlewares.Enabled {
+		summary.Middleware = append(summary.Middleware, MiddlewareSummary{Type: mc.Type, Settings: mc.Redacted()})
+	}
+
+	return summary
+}
+
+// Banner renders the summary as a few lines to print on startup
+func (cs ConfigSummary) Banner() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Mockingjay %s\n", cs.Version)
+
+	tls := "off"
+	if cs.TLS {
+		tls = "on"
+	}
+	fmt.Fprintf(&b, "   - Listening on %s (TLS %s)\n", cs.Addr, tls)
+
+	methods := slices.Sorted(maps.Keys(cs.RoutesByMethod))
+	counts := make([]string, 0, len(methods))
+	for _, method := range methods {
+		counts = append(counts, fmt.Sprintf("%s %d", method, cs.RoutesByMethod[method]))
+	}
+	fmt.Fprintf(&b, "   - Routes: %d", cs.Routes)
+	if len(counts) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(counts, ", "))
+	}
+	b.WriteString("\n")
+
+	if cs.GRPCMethods > 0 {
+		fmt.Fprintf(&b, "   - gRPC methods: %d\n", cs.GRPCMethods)
+	}
+	if len(cs.Tenants) > 0 {
+		fmt.Fprintf(&b, "   - Tenants: %s\n", strings.Join(cs.Tenants, ", "))
+	}
+
+	if len(cs.Middleware) == 0 {
+		b.WriteString("   - Middleware: none\n")
+	}
+	for _, m := range cs.Middleware {
+		fmt.Fprintf(&b, "   - Middleware: %s\n", m)
+	}
+
+	fmt.Fprintf(&b, "   - Timeouts: read %s, write %s, idle %s, read header %s, shutdown %s\n",
+		cs.Timeouts.Read, cs.Timeouts.Write, cs.Timeouts.Idle, cs.Timeouts.ReadHeader, cs.Timeouts.Shutdown)
+
+	return b.String()
+}
+
+// String renders the middleware as its type followed by the settings that
+// were set, e.g. "basicauth (password=[REDACTED], username=admin)"
+func (ms MiddlewareSummary) String() string {
+	var settings []string
+	for _, name := range slices.Sorted(maps.Keys(ms.Settings)) {
+		if isEmptySetting(ms.Settings[name]) {
+			continue
+		}
+		settings = append(settings, fmt.Sprintf("%s=%v", name, ms.Settings[name]))
+	}
+
+	if len(settings) == 0 {
+		return ms.Type
+	}
+	return fmt.Sprintf("%s (%s)", ms.Type, strings.Join(settings, ", "))
+}
+
+// isEmptySetting reports whether a setting's value is a zero value or an
+// empty list, or a mapping of only such values
+func isEmptySetting(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Len() == 0
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if !isEmptySetting(v.MapIndex(key).Interface()) {
+				return false
+			}
+		}
+		return true
+	default:
+		return v.IsZero()
+	}
+}
+
+// handleConfigSummary reports the configuration the server is running with
+func (s *Server) handleConfigSummary(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.ConfigSummary())
+}
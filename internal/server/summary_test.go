@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+)
+
+// summaryTestConfig is a configuration with a few routes, middleware with a
+// secret setting, and a custom timeout
+func summaryTestConfig() *config.Config {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: "[]"},
+		{Path: "/users/{id}", Method: "get", Template: "{}"},
+		{Path: "/users", Method: "POST", Template: "{}"},
+	})
+	cfg.Middleware = middleware.Config{Enabled: []middleware.MiddlewareConfig{
+		{Type: "basicauth", Config: map[string]interface{}{"username": "admin", "password": "hunter2", "realm": ""}},
+		{Type: "logger"},
+	}}
+	cfg.Server.Timeouts.Read = 5 * time.Second
+	return cfg
+}
+
+func TestServer_ConfigSummary(t *testing.T) {
+	ts := NewTestServer(t, summaryTestConfig())
+
+	summary := ts.ConfigSummary()
+	if summary.Version != "test-version" || summary.Addr != ":0" || summary.TLS {
+		t.Errorf("Unexpected server details: %+v", summary)
+	}
+	if summary.Routes != 3 || summary.RoutesByMethod["GET"] != 2 || summary.RoutesByMethod["POST"] != 1 {
+		t.Errorf("Expected 2 GET and 1 POST routes, got %d: %v", summary.Routes, summary.RoutesByMethod)
+	}
+	if summary.Timeouts.Read != "5s" || summary.Timeouts.Write != "15s" {
+		t.Errorf("Expected the configured read timeout and the default write timeout, got %+v", summary.Timeouts)
+	}
+
+	if len(summary.Middleware) != 2 {
+		t.Fatalf("Expected 2 middleware, got %+v", summary.Middleware)
+	}
+	if got := summary.Middleware[0].String(); got != "basicauth (password=[REDACTED], username=admin)" {
+		t.Errorf("Expected the password to be redacted, got %q", got)
+	}
+
+	banner := summary.Banner()
+	for _, want := range []string{
+		"Mockingjay test-version",
+		"Listening on :0 (TLS off)",
+		"Routes: 3 (GET 2, POST 1)",
+		"Middleware: basicauth (password=[REDACTED], username=admin)\n",
+		"Middleware: logger\n",
+		"Timeouts: read 5s, write 15s, idle 1m0s, read header 5s, shutdown 30s",
+	} {
+		if !strings.Contains(banner, want) {
+			t.Errorf("Expected banner to contain %q, got:\n%s", want, banner)
+		}
+	}
+	if strings.Contains(banner, "hunter2") {
+		t.Errorf("Expected banner not to leak the password, got:\n%s", banner)
+	}
+}
+
+func TestServer_Integration_AdminConfig(t *testing.T) {
+	ts := NewTestServer(t, summaryTestConfig())
+
+	resp, err := ts.makeRequest("GET", "/__admin/config", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, body)
+	}
+	if strings.Contains(body, "hunter2") {
+		t.Errorf("Expected the password to be redacted, got %s", body)
+	}
+
+	var summary ConfigSummary
+	if err := json.Unmarshal([]byte(body), &summary); err != nil {
+		t.Fatalf("Failed to decode summary: %v", err)
+	}
+	if summary.Routes != 3 || summary.Middleware[0].Settings["password"] != "[REDACTED]" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// tenant is an isolated mock server hosted by the same process, with its own
+// routes, template engine, middleware and state
+type tenant struct {
+	config config.TenantConfig
+	server *Server
+}
+
+// newTenant creates the isolated server backing a tenant definition
+func newTenant(tc config.TenantConfig, logger *slog.Logger, appVersion string) (*tenant, error) {
+	if tc.Loaded == nil {
+		return nil, fmt.Errorf("tenant %q configuration was not loaded", tc.Name)
+	}
+
+	var addr string
+	if tc.Port != "" {
+		addr = ":" + tc.Port
+	}
+
+	srv, err := NewServer(tc.Loaded, []string{tc.Config}, addr, logger.With("tenant", tc.Name), appVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenant %q: %w", tc.Name, err)
+	}
+
+	return &tenant{config: tc, server: srv}, nil
+}
+
+// newTenants creates the servers for all tenants defined in the configuration
+func newTenants(cfg *config.Config, logger *slog.Logger, appVersion string) ([]*tenant, error) {
+	tenants := make([]*tenant, 0, len(cfg.Tenants))
+	for _, tc := range cfg.Tenants {
+		t, err := newTenant(tc, logger, appVersion)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+// hasListener reports whether the tenant is addressed by its own port
+func (t *tenant) hasListener() bool {
+	return t.config.Port != ""
+}
+
+// ServeHTTP strips the tenant prefix from the request path and hands the request
+// to the tenant's own middleware chain
+func (t *tenant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, t.config.Prefix)
+	if path == "" {
+		path = "/"
+	}
+
+	r2 := r.WithContext(r.Context())
+	r2.URL = &url.URL{}
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+
+	t.server.serveCurrent(w, r2)
+}
+
+// reloadTenants reconciles the running tenants with a freshly loaded configuration.
+// Existing tenants are reloaded in place so their state survives; tenants addressed
+// by a new port cannot be started without a restart, so a tenant whose port
+// changed keeps being served where it was. Callers must hold s.reloadMu.
+func (s *Server) reloadTenants(cfg *config.Config) ([]*tenant, error) {
+	current := s.current().tenants
+	existing := make(map[string]*tenant, len(current))
+	for _, t := range current {
+		existing[t.config.Name] = t
+	}
+
+	tenants := make([]*tenant, 0, len(cfg.Tenants))
+	for _, tc := range cfg.Tenants {
+		if t, ok := existing[tc.Name]; ok {
+			if t.config.Port != tc.Port {
+				s.logger.Error("changing a tenant's port requires a restart, the tenant keeps being served where it was",
+					"tenant", tc.Name, "port", t.config.Port, "prefix", t.config.Prefix, "new_port", tc.Port)
+				tc.Port, tc.Prefix = t.config.Port, t.config.Prefix
+			}
+			if err := t.server.applyConfigFiles(tc.Loaded, []string{tc.Config}); err != nil {
+				return nil, fmt.Errorf("failed to reload tenant %q: %w", tc.Name, err)
+			}
+			tenants = append(tenants, &tenant{config: tc, server: t.server})
+			delete(existing, tc.Name)
+			continue
+		}
+
+		if tc.Port != "" {
+			s.logger.Warn("tenant with a new port requires a restart to start listening", "tenant", tc.Name, "port", tc.Port)
+			continue
+		}
+
+		t, err := newTenant(tc, s.logger, s.appVersion)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+
+	// Stop listeners of tenants that were removed from the configuration
+	for _, t := range existing {
+		if t.hasListener() {
+			go func(t *tenant) {
+				if err := t.server.Shutdown(context.Background()); err != nil && err != http.ErrServerClosed {
+					s.logger.Warn("error shutting down removed tenant", "tenant", t.config.Name, "error", err)
+				}
+			}(t)
+		}
+	}
+
+	return tenants, nil
+}
+
+// ConfigFiles returns the configuration files and directories backing this
+// server and its tenants, including included and template files, so callers
+// can watch all of them for changes
+func (s *Server) ConfigFiles() []string {
+	rt := s.current()
+	files := append(config.WatchPaths(rt.configPaths), rt.watchFiles...)
+	for _, t := range rt.tenants {
+		files = append(files, t.server.ConfigFiles()...)
+	}
+	return files
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/middleware"
+)
+
+func TestServer_Tenants_PrefixDispatch(t *testing.T) {
+	tenantCfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/charges",
+			Method:   "GET",
+			Template: "tenant charges",
+		},
+		{
+			Path:     "/",
+			Method:   "GET",
+			Template: "tenant root",
+		},
+	})
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/charges",
+			Method:   "GET",
+			Template: "root charges",
+		},
+	})
+	cfg.Middleware = middleware.Config{
+		Enabled: []middleware.MiddlewareConfig{
+			{Type: "basicauth", Config: map[string]interface{}{"username": "admin", "password": "secret"}},
+		},
+	}
+	cfg.Tenants = []config.TenantConfig{
+		{Name: "payments", Prefix: "/payments", Config: "payments.yaml", Loaded: tenantCfg},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", logger, "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewServer(srv.httpServer.Handler)
+	defer httpServer.Close()
+
+	tests := []struct {
+		name           string
+		path           string
+		auth           bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "tenant route without root middleware",
+			path:           "/payments/charges",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "tenant charges",
+		},
+		{
+			name:           "tenant prefix maps to tenant root",
+			path:           "/payments",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "tenant root",
+		},
+		{
+			name:           "root route still protected by root middleware",
+			path:           "/charges",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "root route with credentials",
+			path:           "/charges",
+			auth:           true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "root charges",
+		},
+		{
+			name:           "tenant does not fall through to root routes",
+			path:           "/payments/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, httpServer.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.auth {
+				req.SetBasicAuth("admin", "secret")
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedBody != "" && body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+
+	files := srv.ConfigFiles()
+	if len(files) != 2 || files[0] != "test-config.yaml" || files[1] != "payments.yaml" {
+		t.Errorf("Expected config files [test-config.yaml payments.yaml], got %v", files)
+	}
+}
+
+func TestServer_Tenants_ReloadChangedPort(t *testing.T) {
+	tenantConfig := func(body string) *config.Config {
+		return createTestConfig([]config.RouteConfig{{Path: "/charges", Method: "GET", Template: body}})
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/health", Method: "GET", Template: "ok"}})
+	cfg.Tenants = []config.TenantConfig{
+		{Name: "payments", Prefix: "/payments", Config: "payments.yaml", Loaded: tenantConfig("first")},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", logger, "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewServer(srv.httpServer.Handler)
+	defer httpServer.Close()
+
+	// Config files are read while the tenants are reloaded
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			srv.ConfigFiles()
+			srv.handleHealthCheck(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+		}
+	}()
+
+	// Moving the tenant to a port of its own needs a restart, so it keeps its
+	// prefix but picks up its new routes and configuration file
+	reloaded := createTestConfig([]config.RouteConfig{{Path: "/health", Method: "GET", Template: "ok"}})
+	reloaded.Tenants = []config.TenantConfig{
+		{Name: "payments", Port: "9999", Config: "payments-v2.yaml", Loaded: tenantConfig("second")},
+	}
+	if err := srv.applyConfig(reloaded); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	<-done
+
+	resp, err := http.Get(httpServer.URL + "/payments/charges")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "second" {
+		t.Errorf("Expected the reloaded tenant route under its prefix, got %d %q", resp.StatusCode, body)
+	}
+
+	tenants := srv.current().tenants
+	if len(tenants) != 1 || tenants[0].hasListener() || tenants[0].config.Prefix != "/payments" {
+		t.Errorf("Expected the tenant to keep its prefix, got %+v", tenants)
+	}
+
+	files := srv.ConfigFiles()
+	if len(files) != 2 || files[1] != "payments-v2.yaml" {
+		t.Errorf("Expected the tenant's new config file to be watched, got %v", files)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// newTLSConfig builds the TLS settings the server listens with. Client
+// certificates are verified against the client CAs when they're set, and
+// only inspected otherwise.
+func newTLSConfig(tc *config.TLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	verify := tc.ClientCAFile != ""
+	if verify {
+		if tlsConfig.ClientCAs, err = tc.LoadClientCAs(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch tc.GetClientAuth() {
+	case config.ClientAuthRequest:
+		tlsConfig.ClientAuth = tls.RequestClientCert
+		if verify {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	case config.ClientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+		if verify {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// listenAndServe listens on the server's address, with TLS when configured
+func (s *Server) listenAndServe() error {
+	if s.httpServer.TLSConfig != nil {
+		return s.httpServer.ListenAndServeTLS("", "")
+	}
+
+	// Plain HTTP connections are sorted first, so raw routes can parse their
+	// requests themselves
+	addr := s.httpServer.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.httpServer.Serve(s.newRawListener(ln))
+}
@@ -0,0 +1,294 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// testCertificate is a certificate signed by a test CA, or self-signed
+type testCertificate struct {
+	Cert    *x509.Certificate
+	Key     *ecdsa.PrivateKey
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// newTestCertificate creates a certificate from template, signed by parent
+// or self-signed when parent is nil
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.Cert, parent.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return &testCertificate{
+		Cert:    cert,
+		Key:     key,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// tlsCertificate returns the certificate as presented in TLS handshakes
+func (tc *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(tc.CertPEM, tc.KeyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load key pair: %v", err)
+	}
+	return cert
+}
+
+// mtlsTestServer starts an HTTPS test server for cfg, whose TLS settings
+// are filled in with a server certificate and client CA signed by ca
+func mtlsTestServer(t *testing.T, cfg *config.Config, ca *testCertificate, clientAuth string) *httptest.Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	serverCert := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+
+	files := map[string][]byte{"server.pem": serverCert.CertPEM, "server-key.pem": serverCert.KeyPEM, "ca.pem": ca.CertPEM}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg.Server.TLS = &config.TLSConfig{
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server-key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+		ClientAuth:   clientAuth,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid configuration: %v", err)
+	}
+
+	srv, err := NewServer(cfg, []string{"test-config.yaml"}, ":0", slog.New(slog.NewTextHandler(io.Discard, nil)), "test-version")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewUnstartedServer(srv.httpServer.Handler)
+	httpServer.TLS = srv.httpServer.TLSConfig
+	httpServer.Config.ErrorLog = log.New(io.Discard, "", 0) // Failed handshakes are expected
+	httpServer.StartTLS()
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+// mtlsClient creates a client trusting ca and presenting cert, if any, even
+// when it isn't signed by a CA the server asks for
+func mtlsClient(ca *testCertificate, cert ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	tlsConfig := &tls.Config{RootCAs: pool}
+	if len(cert) > 0 {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &cert[0], nil
+		}
+	}
+
+	return &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+}
+
+func TestServer_Integration_ClientCertMatching(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil)
+	client := func(cn string, signer *testCertificate) tls.Certificate {
+		return newTestCertificate(t, &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: cn},
+			DNSNames:     []string{cn + ".internal"},
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, signer).tlsCertificate(t)
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/invoices",
+			Method:          "GET",
+			MatchClientCert: &config.ClientCertMatchConfig{CN: "billing", SAN: "/\\.internal$/"},
+			Template:        `{"client":"{{ .ClientCert.CommonName }}","verified":{{ .ClientCert.Verified }}}`,
+		},
+		{
+			Path:     "/invoices",
+			Method:   "GET",
+			Template: `{"error":"forbidden"}`,
+		},
+	})
+	ts := mtlsTestServer(t, cfg, ca, config.ClientAuthRequest)
+
+	get := func(c *http.Client) (string, error) {
+		t.Helper()
+		resp, err := c.Get(ts.URL + "/invoices")
+		if err != nil {
+			return "", err
+		}
+		return readResponseBody(t, resp), nil
+	}
+
+	tests := []struct {
+		name     string
+		client   *http.Client
+		expected string
+	}{
+		{name: "matching certificate", client: mtlsClient(ca, client("billing", ca)), expected: `{"client":"billing","verified":true}`},
+		{name: "other certificate", client: mtlsClient(ca, client("orders", ca)), expected: `{"error":"forbidden"}`},
+		{name: "no certificate", client: mtlsClient(ca), expected: `{"error":"forbidden"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := get(tt.client)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if body != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, body)
+			}
+		})
+	}
+
+	// Certificates not signed by the client CA fail the handshake
+	if _, err := get(mtlsClient(ca, client("billing", nil))); err == nil {
+		t.Error("Expected the handshake to fail with an untrusted certificate")
+	}
+}
+
+func TestServer_Integration_RequiredClientCert(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil)
+	clientCert := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "billing"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca).tlsCertificate(t)
+
+	cfg := createTestConfig([]config.RouteConfig{{Path: "/ping", Method: "GET", Template: "pong"}})
+	ts := mtlsTestServer(t, cfg, ca, config.ClientAuthRequire)
+
+	resp, err := mtlsClient(ca, clientCert).Get(ts.URL + "/ping")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if body := readResponseBody(t, resp); body != "pong" {
+		t.Errorf("Expected pong, got %q", body)
+	}
+
+	if _, err := mtlsClient(ca).Get(ts.URL + "/ping"); err == nil {
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+}
+
+func TestServer_Integration_SNIMatching(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil)
+
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/whoami",
+			Method:   "GET",
+			MatchSNI: "/^api\\./",
+			Template: `api {{ .TLS.ServerName }} {{ .TLS.ALPN }} {{ .TLS.Version }} {{ if .TLS.CipherSuite }}cipher{{ end }}`,
+		},
+		{
+			Path:     "/whoami",
+			Method:   "GET",
+			Template: `default "{{ .TLS.ServerName }}"`,
+		},
+	})
+	ts := mtlsTestServer(t, cfg, ca, config.ClientAuthRequest)
+
+	// The server certificate only covers 127.0.0.1, so names aren't verified
+	get := func(serverName string) string {
+		t.Helper()
+		client := &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				ServerName:         serverName,
+				NextProtos:         []string{"http/1.1"},
+				MaxVersion:         tls.VersionTLS12,
+				InsecureSkipVerify: true,
+			}},
+		}
+		resp, err := client.Get(ts.URL + "/whoami")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return readResponseBody(t, resp)
+	}
+
+	if body := get("api.example.com"); body != "api api.example.com http/1.1 TLS 1.2 cipher" {
+		t.Errorf("Expected the SNI route with TLS details, got %q", body)
+	}
+	if body := get("www.example.com"); body != `default "www.example.com"` {
+		t.Errorf("Expected the fallback route, got %q", body)
+	}
+	if body := get(""); body != `default ""` {
+		t.Errorf("Expected the fallback route without SNI, got %q", body)
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// expiredTokenRetention is how long expired tokens are remembered, so clients
+// presenting them are told they expired rather than that they are unknown
+const expiredTokenRetention = time.Hour
+
+// Reasons a presented token is rejected
+var (
+	errTokenMissing = errors.New("no token was presented")
+	errTokenUnknown = errors.New("the token is unknown or was revoked")
+	errTokenExpired = errors.New("the token has expired")
+)
+
+// issuedToken is a token minted from the token bucket
+type issuedToken struct {
+	Name      string
+	Session   string // Admin session the token was minted under (empty outside sessions)
+	ExpiresAt time.Time
+}
+
+// tokenStore mints and validates the tokens defined in the token bucket.
+// Minted tokens survive configuration reloads and can be revoked through the
+// admin API.
+type tokenStore struct {
+	mu     sync.Mutex
+	ttls   map[string]time.Duration // Lifetime of each kind of token
+	tokens map[string]issuedToken   // Minted tokens by value
+	now    func() time.Time
+}
+
+// newTokenStore creates a token store for the given token bucket
+func newTokenStore(bucket map[string]config.TokenConfig) *tokenStore {
+	ts := &tokenStore{tokens: make(map[string]issuedToken), now: time.Now}
+	ts.configure(bucket)
+	return ts
+}
+
+// configure replaces the kinds of tokens that can be minted. Tokens already
+// minted keep their expiry.
+func (ts *tokenStore) configure(bucket map[string]config.TokenConfig) {
+	ttls := make(map[string]time.Duration, len(bucket))
+	for name, token := range bucket {
+		ttls[name] = token.TTL
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.ttls = ttls
+}
+
+// Mint issues a new token of the named kind outside sessions
+func (ts *tokenStore) Mint(name string) (string, error) {
+	return ts.mint(name, "")
+}
+
+// mint issues a new token of the named kind under session, or outside
+// sessions when it's empty
+func (ts *tokenStore) mint(name, session string) (string, error) {
+	var b [24]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	token := hex.EncodeToString(b[:])
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ttl, found := ts.ttls[name]
+	if !found {
+		return "", fmt.Errorf("token %q is not defined in the token bucket", name)
+	}
+
+	now := ts.now()
+	for value, issued := range ts.tokens {
+		if now.Sub(issued.ExpiresAt) > expiredTokenRetention {
+			delete(ts.tokens, value)
+		}
+	}
+
+	ts.tokens[token] = issuedToken{Name: name, Session: session, ExpiresAt: now.Add(ttl)}
+	return token, nil
+}
+
+// ExpiresIn returns the lifetime of the named kind of token in seconds
+func (ts *tokenStore) ExpiresIn(name string) (int, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ttl, found := ts.ttls[name]
+	if !found {
+		return 0, fmt.Errorf("token %q is not defined in the token bucket", name)
+	}
+	return int(ttl / time.Second), nil
+}
+
+// forSession returns what templates serving the requests of session mint
+// tokens with, so the tokens belong to the session
+func (ts *tokenStore) forSession(session string) templatepkg.Tokens {
+	if session == "" {
+		return ts
+	}
+	return sessionTokens{store: ts, session: session}
+}
+
+// check verifies that token is a valid, unexpired token of the named kind
+// minted under session, or outside sessions when it's empty, revoking it
+// afterwards when revoke is set
+func (ts *tokenStore) check(name, token, session string, revoke bool) error {
+	if token == "" {
+		return errTokenMissing
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	issued, found := ts.tokens[token]
+	if !found || issued.Name != name || issued.Session != session {
+		return errTokenUnknown
+	}
+
+	if !ts.now().Before(issued.ExpiresAt) {
+		return errTokenExpired
+	}
+
+	if revoke {
+		delete(ts.tokens, token)
+	}
+	return nil
+}
+
+// revoke removes every token of the named kind, or all tokens when name is
+// empty, minted under session, or outside sessions when it's empty, and
+// returns how many were removed
+func (ts *tokenStore) revoke(name, session string) int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	removed := 0
+	for value, issued := range ts.tokens {
+		if issued.Session != session || (name != "" && issued.Name != name) {
+			continue
+		}
+		delete(ts.tokens, value)
+		removed++
+	}
+	return removed
+}
+
+// revokeSession removes the tokens minted under the given session and returns
+// how many were removed
+func (ts *tokenStore) revokeSession(session string) int {
+	return ts.revoke("", session)
+}
+
+// tokenState represents the JSON form of a minted token
+type tokenState struct {
+	Token     string    `json:"token"`
+	Name      string    `json:"name"`
+	Session   string    `json:"session,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Expired   bool      `json:"expired"`
+}
+
+// list returns the minted tokens sorted by name and expiry
+func (ts *tokenStore) list() []tokenState {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := ts.now()
+	states := make([]tokenState, 0, len(ts.tokens))
+	for value, issued := range ts.tokens {
+		states = append(states, tokenState{
+			Token:     value,
+			Name:      issued.Name,
+			Session:   issued.Session,
+			ExpiresAt: issued.ExpiresAt,
+			Expired:   !now.Before(issued.ExpiresAt),
+		})
+	}
+
+	slices.SortFunc(states, func(a, b tokenState) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return a.ExpiresAt.Compare(b.ExpiresAt)
+	})
+	return states
+}
+
+// handleListTokens lists the tokens minted from the token bucket
+func (s *Server) handleListTokens(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"tokens": s.tokens.list()})
+}
+
+// handleRevokeTokens revokes the tokens minted under the session the request
+// is made under, or outside sessions, forcing clients through their token
+// refresh or login flow. The optional "name" query parameter limits the
+// revocation to one kind of token.
+func (s *Server) handleRevokeTokens(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	count := s.tokens.revoke(name, sessionID(sess))
+
+	s.logger.Info("tokens revoked", "name", name, "session", sessionID(sess), "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"revoked": count})
+}
+
+// sessionTokens mints tokens under a session, for templates serving the
+// requests of the session
+type sessionTokens struct {
+	store   *tokenStore
+	session string
+}
+
+// Mint issues a new token of the named kind under the session
+func (t sessionTokens) Mint(name string) (string, error) {
+	return t.store.mint(name, t.session)
+}
+
+// ExpiresIn returns the lifetime of the named kind of token in seconds
+func (t sessionTokens) ExpiresIn(name string) (int, error) {
+	return t.store.ExpiresIn(name)
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestTokenStore_Lifecycle(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newTokenStore(map[string]config.TokenConfig{
+		"access":  {TTL: time.Minute},
+		"refresh": {TTL: time.Hour},
+	})
+	store.now = func() time.Time { return now }
+
+	access, err := store.Mint("access")
+	if err != nil {
+		t.Fatalf("Expected no error minting a token, got %v", err)
+	}
+	refresh, err := store.Mint("refresh")
+	if err != nil {
+		t.Fatalf("Expected no error minting a token, got %v", err)
+	}
+
+	if _, err := store.Mint("session"); err == nil {
+		t.Error("Expected an error minting an undefined token")
+	}
+	if seconds, _ := store.ExpiresIn("access"); seconds != 60 {
+		t.Errorf("Expected access tokens to expire in 60 seconds, got %d", seconds)
+	}
+
+	checks := []struct {
+		name  string
+		kind  string
+		token string
+		want  error
+	}{
+		{"valid access token", "access", access, nil},
+		{"missing token", "access", "", errTokenMissing},
+		{"unknown token", "access", "nope", errTokenUnknown},
+		{"token of another kind", "access", refresh, errTokenUnknown},
+	}
+	for _, c := range checks {
+		if err := store.check(c.kind, c.token, "", false); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+
+	// Tokens minted under a session are only valid under it
+	scoped, err := store.mint("access", "suite")
+	if err != nil {
+		t.Fatalf("Expected no error minting a token, got %v", err)
+	}
+	if err := store.check("access", scoped, "", false); !errors.Is(err, errTokenUnknown) {
+		t.Errorf("Expected a session token to be unknown outside the session, got %v", err)
+	}
+	if err := store.check("access", access, "suite", false); !errors.Is(err, errTokenUnknown) {
+		t.Errorf("Expected a token minted outside sessions to be unknown in the session, got %v", err)
+	}
+	if err := store.check("access", scoped, "suite", false); err != nil {
+		t.Errorf("Expected a valid token in the session, got %v", err)
+	}
+	if count := store.revokeSession("suite"); count != 1 {
+		t.Errorf("Expected 1 session token to be revoked, got %d", count)
+	}
+	if err := store.check("access", access, "", false); err != nil {
+		t.Errorf("Expected revoking a session to keep other tokens, got %v", err)
+	}
+
+	// Access tokens expire while refresh tokens are still valid
+	now = now.Add(2 * time.Minute)
+	if err := store.check("access", access, "", false); !errors.Is(err, errTokenExpired) {
+		t.Errorf("Expected expired access token, got %v", err)
+	}
+
+	// Revoking on use allows each refresh token to be used once
+	if err := store.check("refresh", refresh, "", true); err != nil {
+		t.Errorf("Expected valid refresh token, got %v", err)
+	}
+	if err := store.check("refresh", refresh, "", true); !errors.Is(err, errTokenUnknown) {
+		t.Errorf("Expected used refresh token to be revoked, got %v", err)
+	}
+
+	// Long expired tokens are forgotten the next time a token is minted
+	now = now.Add(2 * time.Hour)
+	if _, err := store.Mint("access"); err != nil {
+		t.Fatalf("Expected no error minting a token, got %v", err)
+	}
+	if err := store.check("access", access, "", false); !errors.Is(err, errTokenUnknown) {
+		t.Errorf("Expected long expired token to be forgotten, got %v", err)
+	}
+}
+
+func TestServer_Integration_TokenLifecycle(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:     "/oauth/token",
+			Method:   "POST",
+			Template: `{"access_token":"{{ .Tokens.Mint "access" }}","refresh_token":"{{ .Tokens.Mint "refresh" }}","expires_in":{{ .Tokens.ExpiresIn "access" }}}`,
+		},
+		{
+			Path:         "/oauth/refresh",
+			Method:       "POST",
+			RequireToken: &config.RequireTokenConfig{Name: "refresh", Body: "refresh_token", Revoke: true},
+			Template:     `{"access_token":"{{ .Tokens.Mint "access" }}","refresh_token":"{{ .Tokens.Mint "refresh" }}"}`,
+		},
+		{
+			Path:         "/api/me",
+			Method:       "GET",
+			RequireToken: &config.RequireTokenConfig{Name: "access"},
+			Template:     `{"name":"Test User"}`,
+		},
+	})
+	cfg.TokenBucket = map[string]config.TokenConfig{
+		"access":  {TTL: time.Minute},
+		"refresh": {TTL: time.Hour},
+	}
+
+	ts := NewTestServer(t, cfg)
+
+	type tokens struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+
+	post := func(path, body string) (int, tokens) {
+		t.Helper()
+		resp, err := ts.makeRequest("POST", path, strings.NewReader(body), map[string]string{"Content-Type": "application/json"})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		raw := readResponseBody(t, resp)
+
+		var issued tokens
+		if resp.StatusCode == http.StatusOK {
+			if err := json.Unmarshal([]byte(raw), &issued); err != nil {
+				t.Fatalf("Failed to parse tokens from %q: %v", raw, err)
+			}
+		}
+		return resp.StatusCode, issued
+	}
+
+	me := func(accessToken string) *http.Response {
+		t.Helper()
+		headers := map[string]string{}
+		if accessToken != "" {
+			headers["Authorization"] = "Bearer " + accessToken
+		}
+		resp, err := ts.makeRequest("GET", "/api/me", nil, headers)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+		return resp
+	}
+
+	// Log in and use the access token
+	status, login := post("/oauth/token", "")
+	if status != http.StatusOK || login.AccessToken == "" || login.RefreshToken == "" {
+		t.Fatalf("Expected tokens from login, got %d %+v", status, login)
+	}
+	if login.ExpiresIn != 60 {
+		t.Errorf("Expected expires_in 60, got %d", login.ExpiresIn)
+	}
+	if resp := me(login.AccessToken); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 with a valid token, got %d", resp.StatusCode)
+	}
+
+	// Missing and expired tokens are rejected
+	resp := me("")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a token, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("WWW-Authenticate"); !strings.HasPrefix(got, `Bearer error="invalid_token"`) {
+		t.Errorf("Expected a bearer challenge, got %q", got)
+	}
+
+	ts.tokens.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if resp := me(login.AccessToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with an expired token, got %d", resp.StatusCode)
+	}
+
+	// Refresh the access token, rotating the refresh token
+	status, refreshed := post("/oauth/refresh", `{"refresh_token":"`+login.RefreshToken+`"}`)
+	if status != http.StatusOK || refreshed.AccessToken == "" {
+		t.Fatalf("Expected new tokens from refresh, got %d %+v", status, refreshed)
+	}
+	if resp := me(refreshed.AccessToken); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 with a refreshed token, got %d", resp.StatusCode)
+	}
+	if status, _ := post("/oauth/refresh", `{"refresh_token":"`+login.RefreshToken+`"}`); status != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 reusing a refresh token, got %d", status)
+	}
+
+	// Revoking access tokens through the admin API forces another refresh
+	req, _ := http.NewRequest("DELETE", ts.BaseURL+"/__admin/tokens?name=access", nil)
+	adminResp, err := ts.Client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var revoked map[string]int
+	if err := json.Unmarshal([]byte(readResponseBody(t, adminResp)), &revoked); err != nil {
+		t.Fatalf("Failed to parse admin response: %v", err)
+	}
+	if revoked["revoked"] != 2 {
+		t.Errorf("Expected 2 revoked access tokens, got %d", revoked["revoked"])
+	}
+	if resp := me(refreshed.AccessToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with a revoked token, got %d", resp.StatusCode)
+	}
+}
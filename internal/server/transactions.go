@@ -0,0 +1,187 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// errInvalidTransition is returned when a transaction's state doesn't allow
+// the transition a route performs
+var errInvalidTransition = errors.New("invalid transition")
+
+// transactionState is the current state of a transaction, as listed through
+// the admin API
+type transactionState struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Session string `json:"session,omitempty"`
+	State   string `json:"state"`
+}
+
+// transactionKey identifies one transaction of a kind
+type transactionKey struct {
+	Name    string // Kind of transaction
+	ID      string // Transaction ID, rendered from the route's template
+	Session string // Admin session the requests belong to (empty outside sessions)
+}
+
+// transactionStore tracks the state of every transaction. States survive
+// configuration reloads as long as their kind of transaction is still defined.
+type transactionStore struct {
+	mu       sync.Mutex
+	machines map[string]config.TransactionConfig // State machines by transaction kind
+	states   map[transactionKey]string
+}
+
+// newTransactionStore creates a store for the given transaction state machines
+func newTransactionStore(transactions map[string]config.TransactionConfig) *transactionStore {
+	ts := &transactionStore{states: make(map[transactionKey]string)}
+	ts.configure(transactions)
+	return ts
+}
+
+// configure replaces the transaction state machines, forgetting the
+// transactions of kinds that are no longer defined
+func (ts *transactionStore) configure(transactions map[string]config.TransactionConfig) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.machines = transactions
+	for key := range ts.states {
+		if _, found := transactions[key.Name]; !found {
+			delete(ts.states, key)
+		}
+	}
+}
+
+// transition performs an action on a transaction of session, or outside
+// sessions when it's empty, which starts in its kind's initial state, and
+// returns the states before and after it
+func (ts *transactionStore) transition(name, id, session, action string) (string, string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	machine, found := ts.machines[name]
+	if !found {
+		return "", "", fmt.Errorf("transaction %q is not defined", name)
+	}
+	transition, found := machine.Transitions[action]
+	if !found {
+		return "", "", fmt.Errorf("action %q of transaction %q is not defined", action, name)
+	}
+
+	key := transactionKey{Name: name, ID: id, Session: session}
+	from, found := ts.states[key]
+	if !found {
+		from = machine.Initial
+	}
+	if !slices.Contains(transition.From, from) {
+		return from, from, fmt.Errorf("%w: cannot %s %s %q in state %q, only from: %s", errInvalidTransition, action, name, id, from, strings.Join(transition.From, ", "))
+	}
+
+	ts.states[key] = transition.To
+	return from, transition.To, nil
+}
+
+// list returns the state of every transaction, sorted by kind, ID and session
+func (ts *transactionStore) list() []transactionState {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	states := make([]transactionState, 0, len(ts.states))
+	for key, state := range ts.states {
+		states = append(states, transactionState{Name: key.Name, ID: key.ID, Session: key.Session, State: state})
+	}
+	slices.SortFunc(states, func(a, b transactionState) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.ID, b.ID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Session, b.Session)
+	})
+	return states
+}
+
+// reset forgets the transactions of the named kind, or of all kinds when name
+// is empty, kept under session, or outside sessions when it's empty, and
+// returns how many were forgotten
+func (ts *transactionStore) reset(name, session string) int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	count := 0
+	for key := range ts.states {
+		if key.Session != session || (name != "" && key.Name != name) {
+			continue
+		}
+		delete(ts.states, key)
+		count++
+	}
+	return count
+}
+
+// resetSession forgets the transactions of the given session and returns how
+// many were forgotten
+func (ts *transactionStore) resetSession(session string) int {
+	return ts.reset("", session)
+}
+
+// performTransition renders the ID of the transaction a route acts on and
+// moves it to its next state. Requests of a session act on transactions of
+// their own.
+func (s *Server) performTransition(rt *routing, step *router.TransactionStep, ctx *templatepkg.TemplateContext, session string) (*templatepkg.TransactionInfo, error) {
+	var id bytes.Buffer
+	if err := rt.engine.ExecuteTemplate(step.ID, &id, ctx); err != nil {
+		return nil, fmt.Errorf("failed to render transaction ID: %w", err)
+	}
+	if strings.TrimSpace(id.String()) == "" {
+		return nil, fmt.Errorf("transaction ID of %q rendered empty", step.Name)
+	}
+
+	from, to, err := s.transactions.transition(step.Name, strings.TrimSpace(id.String()), session, step.Action)
+	if err != nil {
+		return nil, err
+	}
+	return &templatepkg.TransactionInfo{Name: step.Name, ID: strings.TrimSpace(id.String()), From: from, State: to}, nil
+}
+
+// handleInvalidTransition handles requests attempting a transition their
+// transaction's state doesn't allow
+func (s *Server) handleInvalidTransition(w http.ResponseWriter, r *http.Request, err error) {
+	detail := strings.TrimPrefix(err.Error(), errInvalidTransition.Error()+": ")
+	problem.Write(w, r, http.StatusConflict, problem.CodeInvalidTransition, detail, "409 Conflict: "+detail+"\n")
+}
+
+// handleListTransactions lists the state of every transaction
+func (s *Server) handleListTransactions(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"transactions": s.transactions.list()})
+}
+
+// handleResetTransactions forgets transactions, so they start over in their
+// initial state, for the session the request is made under or outside
+// sessions. The optional "name" query parameter limits the reset to one kind
+// of transaction.
+func (s *Server) handleResetTransactions(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.adminSession(w, r)
+	if !ok {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	count := s.transactions.reset(name, sessionID(sess))
+
+	s.logger.Info("transactions reset", "name", name, "session", sessionID(sess), "count", count)
+	writeJSON(w, http.StatusOK, map[string]int{"reset": count})
+}
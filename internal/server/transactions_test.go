@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func paymentTransaction() config.TransactionConfig {
+	return config.TransactionConfig{
+		Initial: "created",
+		Transitions: map[string]config.TransitionConfig{
+			"authorize": {From: []string{"created"}, To: "authorized"},
+			"capture":   {From: []string{"authorized"}, To: "captured"},
+			"void":      {From: []string{"created", "authorized"}, To: "voided"},
+		},
+	}
+}
+
+func TestTransactionStore_Transition(t *testing.T) {
+	store := newTransactionStore(map[string]config.TransactionConfig{"payment": paymentTransaction()})
+
+	from, to, err := store.transition("payment", "123", "", "authorize")
+	if err != nil || from != "created" || to != "authorized" {
+		t.Fatalf("Expected created -> authorized, got %q -> %q, %v", from, to, err)
+	}
+	if _, _, err := store.transition("payment", "123", "", "authorize"); !errors.Is(err, errInvalidTransition) {
+		t.Errorf("Expected an invalid transition authorizing twice, got %v", err)
+	}
+	if _, to, err := store.transition("payment", "123", "", "capture"); err != nil || to != "captured" {
+		t.Errorf("Expected captured, got %q, %v", to, err)
+	}
+
+	// Transactions are tracked separately by ID
+	if _, _, err := store.transition("payment", "456", "", "capture"); !errors.Is(err, errInvalidTransition) {
+		t.Errorf("Expected an invalid transition capturing a new payment, got %v", err)
+	}
+	if _, _, err := store.transition("payment", "456", "", "void"); err != nil {
+		t.Errorf("Expected to void a new payment, got %v", err)
+	}
+
+	if got := store.list(); len(got) != 2 || got[0].ID != "123" || got[0].State != "captured" || got[1].State != "voided" {
+		t.Errorf("Unexpected transactions: %+v", got)
+	}
+
+	// Sessions track transactions of their own
+	if from, _, err := store.transition("payment", "123", "suite", "authorize"); err != nil || from != "created" {
+		t.Errorf("Expected the session's payment to start over, got %q, %v", from, err)
+	}
+	if count := store.resetSession("suite"); count != 1 {
+		t.Errorf("Expected 1 session transaction to be reset, got %d", count)
+	}
+	if got := store.list(); len(got) != 2 {
+		t.Errorf("Expected resetting a session to keep other transactions, got %+v", got)
+	}
+
+	// Reloading without the transaction kind forgets its transactions
+	store.configure(map[string]config.TransactionConfig{"order": paymentTransaction()})
+	if got := store.list(); len(got) != 0 {
+		t.Errorf("Expected no transactions after removing their kind, got %+v", got)
+	}
+	if _, _, err := store.transition("payment", "123", "", "void"); err == nil || errors.Is(err, errInvalidTransition) {
+		t.Errorf("Expected an error for an undefined transaction, got %v", err)
+	}
+}
+
+func TestServer_Integration_Transactions(t *testing.T) {
+	route := func(action string) config.RouteConfig {
+		return config.RouteConfig{
+			Path:        "/^/payments/(?P<id>[^/]+)/" + action + "$/",
+			Method:      "POST",
+			Transaction: &config.TransactionStepConfig{Name: "payment", ID: "{{ .Params.id }}", Action: action},
+			Template:    `{"id":"{{ .Transaction.ID }}","from":"{{ .Transaction.From }}","status":"{{ .Transaction.State }}"}`,
+		}
+	}
+
+	cfg := createTestConfig([]config.RouteConfig{route("authorize"), route("capture"), route("void")})
+	cfg.Transactions = map[string]config.TransactionConfig{"payment": paymentTransaction()}
+
+	ts := NewTestServer(t, cfg)
+
+	post := func(path string) (int, string) {
+		t.Helper()
+		resp, err := ts.makeRequest("POST", path, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp.StatusCode, readResponseBody(t, resp)
+	}
+
+	// Authorize and capture a payment
+	if status, body := post("/payments/123/authorize"); status != http.StatusOK || body != `{"id":"123","from":"created","status":"authorized"}` {
+		t.Errorf("Expected the payment to be authorized, got %d %s", status, body)
+	}
+	if status, body := post("/payments/123/capture"); status != http.StatusOK || body != `{"id":"123","from":"authorized","status":"captured"}` {
+		t.Errorf("Expected the payment to be captured, got %d %s", status, body)
+	}
+
+	// Capturing twice or voiding a captured payment conflicts
+	status, body := post("/payments/123/capture")
+	if status != http.StatusConflict {
+		t.Errorf("Expected status 409 capturing twice, got %d", status)
+	}
+	if !strings.Contains(body, `cannot capture payment "123" in state "captured"`) {
+		t.Errorf("Expected the conflict to be explained, got %q", body)
+	}
+	if status, _ := post("/payments/123/void"); status != http.StatusConflict {
+		t.Errorf("Expected status 409 voiding a captured payment, got %d", status)
+	}
+
+	// Other payments have their own state
+	if status, _ := post("/payments/456/void"); status != http.StatusOK {
+		t.Errorf("Expected another payment to be voided, got %d", status)
+	}
+
+	// The admin API lists and resets transactions
+	resp, err := ts.makeRequest("GET", "/__admin/transactions", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var listed struct {
+		Transactions []transactionState `json:"transactions"`
+	}
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &listed); err != nil {
+		t.Fatalf("Failed to parse admin response: %v", err)
+	}
+	expected := []transactionState{{Name: "payment", ID: "123", State: "captured"}, {Name: "payment", ID: "456", State: "voided"}}
+	if len(listed.Transactions) != len(expected) || listed.Transactions[0] != expected[0] || listed.Transactions[1] != expected[1] {
+		t.Errorf("Expected transactions %+v, got %+v", expected, listed.Transactions)
+	}
+
+	resp, err = ts.makeRequest("DELETE", "/__admin/transactions?name=payment", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var reset map[string]int
+	if err := json.Unmarshal([]byte(readResponseBody(t, resp)), &reset); err != nil {
+		t.Fatalf("Failed to parse admin response: %v", err)
+	}
+	if reset["reset"] != 2 {
+		t.Errorf("Expected 2 reset transactions, got %d", reset["reset"])
+	}
+	if status, _ := post("/payments/123/authorize"); status != http.StatusOK {
+		t.Errorf("Expected a reset payment to start over, got %d", status)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+)
+
+// RouteUsage is how often a configured route was called
+type RouteUsage struct {
+	Route      string     `json:"route"`                 // The route, as "METHOD path"
+	Calls      int        `json:"calls"`                 // How many times the route was called
+	LastCalled *time.Time `json:"last_called,omitempty"` // When the route was last called, if it ever was
+}
+
+// UsageReport is how often every configured route was called, to find
+// routes that are never used
+type UsageReport struct {
+	Since  time.Time    `json:"since"`  // When calls started being counted
+	Total  int          `json:"total"`  // How many routes were reported on
+	Unused int          `json:"unused"` // How many of them were never called
+	Routes []RouteUsage `json:"routes"` // The routes, in configuration order
+}
+
+// usage reports how often the given routes were called, counting the calls
+// made under sessions too. Routes sharing a method and path are reported once.
+func (cs *callStore) usage(routes []*router.Route, unusedOnly bool) UsageReport {
+	cs.mu.Lock()
+	since := cs.since
+	cs.mu.Unlock()
+
+	report := UsageReport{Since: since, Routes: make([]RouteUsage, 0)}
+	reported := make(map[string]bool)
+
+	for _, route := range routes {
+		id := scenarioRouteID(route)
+		if reported[id] {
+			continue
+		}
+		reported[id] = true
+		report.Total++
+
+		stats := cs.total(id)
+		if stats.Calls == 0 {
+			report.Unused++
+		} else if unusedOnly {
+			continue
+		}
+
+		usage := RouteUsage{Route: id, Calls: stats.Calls}
+		if stats.Calls > 0 {
+			usage.LastCalled = &stats.Last
+		}
+		report.Routes = append(report.Routes, usage)
+	}
+
+	return report
+}
+
+// RouteUsage reports how often the routes of the configuration were called
+// since the server started or the calls were last reset, optionally listing
+// only those never called. Routes created through the admin API aren't
+// included, since they're usually short-lived.
+func (s *Server) RouteUsage(unusedOnly bool) UsageReport {
+	return s.calls.usage(s.current().routes, unusedOnly)
+}
+
+// handleRouteUsage reports how often configured routes were called, listing
+// only the unused ones with "?unused=true"
+func (s *Server) handleRouteUsage(w http.ResponseWriter, r *http.Request) {
+	var unusedOnly bool
+	if value := r.URL.Query().Get("unused"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid unused parameter: "+err.Error())
+			return
+		}
+		unusedOnly = parsed
+	}
+
+	writeJSON(w, http.StatusOK, s.RouteUsage(unusedOnly))
+}
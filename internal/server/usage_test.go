@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_RouteUsage(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{Path: "/users", Method: "GET", Template: "[]"},
+		{Path: "/users", Method: "GET", Template: "fallback", MatchHeaders: map[string]string{"X-Legacy": "1"}},
+		{Path: "/legacy/orders", Method: "GET", Template: "[]"},
+		{Path: "/orders", Method: "POST", Template: "{}"},
+	})
+
+	ts := NewTestServer(t, cfg)
+
+	for _, path := range []string{"/users", "/users", "/missing"} {
+		resp, err := ts.makeRequest("GET", path, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+	}
+
+	usage := func(query string) (int, UsageReport) {
+		t.Helper()
+		resp, err := ts.makeRequest("GET", "/__admin/routes/usage"+query, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var report UsageReport
+		if body := readResponseBody(t, resp); resp.StatusCode == http.StatusOK {
+			if err := json.Unmarshal([]byte(body), &report); err != nil {
+				t.Fatalf("Failed to parse usage report: %v", err)
+			}
+		}
+		return resp.StatusCode, report
+	}
+
+	// Routes sharing a method and path are reported once
+	_, report := usage("")
+	if report.Total != 3 || report.Unused != 2 || len(report.Routes) != 3 || report.Since.IsZero() {
+		t.Fatalf("Unexpected usage report: %+v", report)
+	}
+	users := report.Routes[0]
+	if users.Route != "GET /users" || users.Calls != 2 || users.LastCalled == nil {
+		t.Errorf("Expected GET /users to be called twice, got %+v", users)
+	}
+	if orders := report.Routes[2]; orders.Route != "POST /orders" || orders.Calls != 0 || orders.LastCalled != nil {
+		t.Errorf("Expected POST /orders to be unused, got %+v", orders)
+	}
+
+	_, report = usage("?unused=true")
+	if report.Total != 3 || len(report.Routes) != 2 || report.Routes[0].Route != "GET /legacy/orders" || report.Routes[1].Route != "POST /orders" {
+		t.Errorf("Expected only the unused routes, got %+v", report)
+	}
+
+	if status, _ := usage("?unused=maybe"); status != http.StatusBadRequest {
+		t.Errorf("Expected an invalid parameter to be rejected, got %d", status)
+	}
+
+	// Resetting the calls starts counting over
+	resp, err := ts.makeRequest("DELETE", "/__admin/verify", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	readResponseBody(t, resp)
+
+	_, after := usage("")
+	if after.Unused != 3 || !after.Since.After(report.Since) {
+		t.Errorf("Expected every route to be unused after a reset, got %+v", after)
+	}
+}
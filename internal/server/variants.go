@@ -0,0 +1,20 @@
+package server
+
+import (
+	"bytes"
+
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// selectVariant renders a route's selector and returns the variant it names,
+// or the default one when it names none, along with the variant's name
+func (s *Server) selectVariant(rt *routing, variants *router.Variants, ctx *templatepkg.TemplateContext) (string, *router.Response, error) {
+	var buf bytes.Buffer
+	if err := rt.engine.ExecuteTemplate(variants.Selector, &buf, ctx); err != nil {
+		return "", nil, err
+	}
+
+	name, selected := variants.Select(buf.String())
+	return name, selected, nil
+}
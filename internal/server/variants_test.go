@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func TestServer_Integration_Variants(t *testing.T) {
+	cfg := createTestConfig([]config.RouteConfig{
+		{
+			Path:            "/checkout",
+			Method:          "GET",
+			ResponseHeaders: map[string]string{"X-Route": "checkout"},
+			Variants: &config.VariantsConfig{
+				Selector: `{{ .Headers.Get "X-Experiment" }}`,
+				Cases: map[string]config.ResponseConfig{
+					"control":   {Template: "classic checkout"},
+					"one-click": {Status: http.StatusCreated, Template: "one-click checkout for {{ .Query.Get \"user\" }}", ResponseHeaders: map[string]string{"X-Route": "one-click"}},
+				},
+				Default: "control",
+			},
+		},
+		{
+			Path:   "/broken",
+			Method: "GET",
+			Variants: &config.VariantsConfig{
+				Selector: `{{ fail "no experiment" }}`,
+				Cases:    map[string]config.ResponseConfig{"control": {Template: "unreachable"}},
+				Default:  "control",
+			},
+		},
+	})
+	cfg.Server.DevMode = true
+	ts := NewTestServer(t, cfg)
+
+	tests := []struct {
+		name        string
+		path        string
+		experiment  string
+		wantStatus  int
+		wantBody    string
+		wantRoute   string
+		wantVariant string
+	}{
+		{name: "selected variant", path: "/checkout?user=ana", experiment: "one-click", wantStatus: 201, wantBody: "one-click checkout for ana", wantRoute: "one-click", wantVariant: "one-click"},
+		{name: "default variant by name", path: "/checkout", experiment: "control", wantStatus: 200, wantBody: "classic checkout", wantRoute: "checkout", wantVariant: "control"},
+		{name: "unknown variant", path: "/checkout", experiment: "dark-mode", wantStatus: 200, wantBody: "classic checkout", wantRoute: "checkout", wantVariant: "control"},
+		{name: "no selector value", path: "/checkout", wantStatus: 200, wantBody: "classic checkout", wantRoute: "checkout", wantVariant: "control"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.experiment != "" {
+				headers["X-Experiment"] = tt.experiment
+			}
+
+			resp, err := ts.makeRequest("GET", tt.path, nil, headers)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body := readResponseBody(t, resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if body != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, body)
+			}
+			if got := resp.Header.Get("X-Route"); got != tt.wantRoute {
+				t.Errorf("Expected X-Route %q, got %q", tt.wantRoute, got)
+			}
+			if got := resp.Header.Get(headerVariant); got != tt.wantVariant {
+				t.Errorf("Expected %s %q, got %q", headerVariant, tt.wantVariant, got)
+			}
+		})
+	}
+
+	t.Run("selector error", func(t *testing.T) {
+		resp, err := ts.makeRequest("GET", "/broken", nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		readResponseBody(t, resp)
+
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+	"github.com/patrickdappollonio/mockingjay/internal/router"
+	templatepkg "github.com/patrickdappollonio/mockingjay/internal/template"
+)
+
+// webSocketCloseTimeout is how long a session waits for the client to answer
+// its close frame before dropping the connection
+const webSocketCloseTimeout = time.Second
+
+// serveWebSocket upgrades a request to a WebSocket route and starts its
+// session, which outlives the request. It returns the status the request was
+// answered with.
+func (s *Server) serveWebSocket(rt *routing, w http.ResponseWriter, r *http.Request, route *router.Route, ctx *templatepkg.TemplateContext) int {
+	if !websocket.IsWebSocketUpgrade(r) {
+		w.Header().Set("Upgrade", "websocket")
+		detail := "this route only serves WebSocket connections"
+		problem.Write(w, r, http.StatusUpgradeRequired, problem.CodeUpgradeRequired, detail, "426 Upgrade Required: "+detail+"\n")
+		return http.StatusUpgradeRequired
+	}
+
+	status := http.StatusSwitchingProtocols
+	upgrader := websocket.Upgrader{
+		// Mocks serve browser clients from any origin
+		CheckOrigin: func(*http.Request) bool { return true },
+		Error: func(w http.ResponseWriter, r *http.Request, code int, reason error) {
+			status = code
+			if code >= http.StatusInternalServerError {
+				s.handleServerError(w, r, reason)
+				return
+			}
+			detail := reason.Error()
+			problem.Write(w, r, code, problem.CodeInvalidHandshake, detail, fmt.Sprintf("%d %s: %s\n", code, http.StatusText(code), detail))
+		},
+	}
+
+	// Headers set so far, like response_headers and Deprecation, are sent
+	// with the handshake response
+	conn, err := upgrader.Upgrade(w, r, w.Header())
+	if err != nil {
+		return status
+	}
+
+	session := &webSocketSession{
+		conn:   conn,
+		ws:     route.WebSocket,
+		reply:  route.Tmpl,
+		engine: rt.engine,
+		ctx:    ctx,
+		skew:   ctx.Clock.Skew(),
+		logger: s.logger.With("path", r.URL.Path, "remote_addr", r.RemoteAddr),
+	}
+	if !s.websockets.add(conn) {
+		// The server is shutting down
+		session.close(websocket.CloseGoingAway, "server shutting down")
+		_ = conn.Close()
+		return status
+	}
+
+	go func() {
+		defer s.websockets.remove(conn)
+		session.run()
+	}()
+	return status
+}
+
+// webSocketSession serves an upgraded WebSocket connection: it sends the
+// route's scripted messages while answering the messages the client sends
+type webSocketSession struct {
+	conn   *websocket.Conn
+	ws     *router.WebSocket
+	reply  *template.Template // Answers client messages in the "template" echo mode
+	engine *templatepkg.Engine
+	ctx    *templatepkg.TemplateContext // Context of the upgrade request
+	skew   time.Duration                // Clock skew of the route
+	logger *slog.Logger
+
+	writeMu sync.Mutex // Connections support one writer at a time
+}
+
+// run serves the session until the client disconnects, the script closes the
+// connection or a template fails
+func (ws *webSocketSession) run() {
+	start := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Read client messages until the connection closes
+	received := make(chan int, 1)
+	go func() {
+		defer cancel()
+		received <- ws.readMessages()
+	}()
+
+	sent, err := ws.sendScript(ctx)
+	switch {
+	case err != nil:
+		ws.logger.Error("websocket message failed", "error", err)
+		ws.close(websocket.CloseInternalServerErr, "template error")
+	case ws.ws.Close:
+		ws.close(websocket.CloseNormalClosure, "")
+	}
+
+	// Wait for the client to disconnect, or to answer the close frame
+	if err != nil || ws.ws.Close {
+		select {
+		case <-ctx.Done():
+		case <-time.After(webSocketCloseTimeout):
+			_ = ws.conn.Close()
+		}
+	}
+	<-ctx.Done()
+	_ = ws.conn.Close()
+
+	ws.logger.Info("websocket connection closed",
+		"messages_sent", sent,
+		"messages_received", <-received,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
+
+// sendScript sends the scripted messages in order, waiting for the delay of
+// each one, and returns how many were sent
+func (ws *webSocketSession) sendScript(ctx context.Context) (int, error) {
+	for i, msg := range ws.ws.Messages {
+		if delay := msg.Delay.Duration(); delay > 0 {
+			if err := sleepContext(ctx, delay); err != nil {
+				return i, nil
+			}
+		}
+
+		data, err := ws.render(msg.Tmpl, nil)
+		if err != nil {
+			return i, fmt.Errorf("message %d: %w", i, err)
+		}
+		if err := ws.write(websocket.TextMessage, data); err != nil {
+			return i, nil
+		}
+	}
+	return len(ws.ws.Messages), nil
+}
+
+// readMessages answers client messages according to the echo mode until the
+// connection closes, and returns how many were received
+func (ws *webSocketSession) readMessages() int {
+	received := 0
+	for {
+		messageType, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			return received
+		}
+		received++
+
+		switch ws.ws.Echo {
+		case config.WebSocketEchoRaw:
+			err = ws.write(messageType, data)
+		case config.WebSocketEchoTemplate:
+			var reply []byte
+			if reply, err = ws.render(ws.reply, data); err != nil {
+				ws.logger.Error("websocket reply failed", "error", err)
+				ws.close(websocket.CloseInternalServerErr, "template error")
+				return received
+			}
+			err = ws.write(websocket.TextMessage, reply)
+		}
+		if err != nil {
+			return received
+		}
+	}
+}
+
+// render renders a message template with the context of the upgrade request,
+// a clock set to the current time unless the route's is fixed and, for
+// replies, the client message as .Body and .RawBody
+func (ws *webSocketSession) render(tmpl *template.Template, message []byte) (out []byte, err error) {
+	ctx := *ws.ctx
+	ctx.Body, ctx.RawBody = nil, ""
+	if message != nil {
+		ctx.Body, ctx.RawBody = webSocketMessageBody(message), string(message)
+	}
+	if !ctx.Clock.Fixed() {
+		ctx.Clock = templatepkg.NewClock(ws.skew)
+	}
+	ctx.Response = templatepkg.NewResponse()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("template execution panicked: %v", recovered)
+		}
+	}()
+
+	var buf bytes.Buffer
+	if err := ws.engine.ExecuteTemplate(tmpl, &buf, &ctx); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write sends a message to the client
+func (ws *webSocketSession) write(messageType int, data []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	return ws.conn.WriteMessage(messageType, data)
+}
+
+// close sends a close frame to the client, which answers it by closing the
+// connection
+func (ws *webSocketSession) close(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = ws.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(webSocketCloseTimeout))
+}
+
+// webSocketMessageBody returns a client message as exposed to reply
+// templates: parsed when it's JSON, as text otherwise
+func webSocketMessageBody(data []byte) any {
+	var parsed any
+	if err := json.Unmarshal(data, &parsed); err == nil {
+		return parsed
+	}
+	return string(data)
+}
+
+// webSocketConns tracks open WebSocket connections, so shutdown can close
+// them: the HTTP server forgets connections once they're upgraded
+type webSocketConns struct {
+	mu     sync.Mutex
+	conns  map[*websocket.Conn]struct{}
+	closed bool // Set on shutdown, when no new connections are accepted
+}
+
+// newWebSocketConns creates an empty connection tracker
+func newWebSocketConns() *webSocketConns {
+	return &webSocketConns{conns: make(map[*websocket.Conn]struct{})}
+}
+
+// add tracks a connection, reporting false once the server is shutting down
+func (c *webSocketConns) add(conn *websocket.Conn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.conns[conn] = struct{}{}
+	return true
+}
+
+// remove stops tracking a connection
+func (c *webSocketConns) remove(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+}
+
+// closeAll closes every open connection and refuses new ones
+func (c *webSocketConns) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for conn := range c.conns {
+		message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+		_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(webSocketCloseTimeout))
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+func webSocketTestConfig() *config.Config {
+	return createTestConfig([]config.RouteConfig{
+		{
+			Path:   "/^/rooms/(?P<room>\\w+)$/",
+			Method: "GET",
+			WebSocket: &config.WebSocketConfig{
+				Messages: []config.WebSocketMessageConfig{
+					{Template: `{"welcome": "{{ .Params.room }}"}`},
+					{Template: `{"tick": 1}`, Delay: &config.DelayConfig{Min: 50 * time.Millisecond, Max: 50 * time.Millisecond}},
+				},
+				Close: true,
+			},
+			ResponseHeaders: map[string]string{"X-Room": "{{ .Params.room }}"},
+		},
+		{Path: "/echo", Method: "GET", WebSocket: &config.WebSocketConfig{}},
+		{
+			Path:      "/chat",
+			Method:    "GET",
+			Template:  `{"reply": "{{ .Body.text | upper }}", "user": "{{ .Query.Get "user" }}", "raw": {{ .RawBody | toJson }}}`,
+			WebSocket: &config.WebSocketConfig{Messages: []config.WebSocketMessageConfig{{Template: "ready"}}},
+		},
+		{
+			Path:      "/broken",
+			Method:    "GET",
+			WebSocket: &config.WebSocketConfig{Messages: []config.WebSocketMessageConfig{{Template: `{{ fail "boom" }}`}}},
+		},
+		{Path: "/idle", Method: "GET", WebSocket: &config.WebSocketConfig{Echo: config.WebSocketEchoOff}, Deprecated: true},
+	})
+}
+
+// dialWebSocket opens a WebSocket connection to a test server path
+func dialWebSocket(t *testing.T, ts *TestServer, path string) (*websocket.Conn, *http.Response) {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(ts.BaseURL, "http") + path
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", path, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn, resp
+}
+
+// readWebSocketText reads the next message of a connection as text
+func readWebSocketText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	return string(data)
+}
+
+// expectWebSocketClose reads from a connection until the server closes it
+// with the given code
+func expectWebSocketClose(t *testing.T, conn *websocket.Conn, code int) {
+	t.Helper()
+
+	_, data, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != code {
+		t.Fatalf("Expected close code %d, got message %q and error %v", code, data, err)
+	}
+}
+
+func TestServer_Integration_WebSocketScript(t *testing.T) {
+	ts := NewTestServer(t, webSocketTestConfig())
+
+	conn, resp := dialWebSocket(t, ts, "/rooms/lobby")
+	if got := resp.Header.Get("X-Room"); got != "lobby" {
+		t.Errorf("Expected the handshake to carry response headers, got X-Room %q", got)
+	}
+
+	if got := readWebSocketText(t, conn); got != `{"welcome": "lobby"}` {
+		t.Errorf("Expected the welcome message, got %q", got)
+	}
+
+	start := time.Now()
+	if got := readWebSocketText(t, conn); got != `{"tick": 1}` {
+		t.Errorf("Expected the tick message, got %q", got)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected the tick to be delayed by 50ms, got it after %v", elapsed)
+	}
+
+	expectWebSocketClose(t, conn, websocket.CloseNormalClosure)
+}
+
+func TestServer_Integration_WebSocketEcho(t *testing.T) {
+	ts := NewTestServer(t, webSocketTestConfig())
+
+	t.Run("raw", func(t *testing.T) {
+		conn, _ := dialWebSocket(t, ts, "/echo")
+
+		for _, msg := range []struct {
+			messageType int
+			data        string
+		}{
+			{websocket.TextMessage, "hello"},
+			{websocket.BinaryMessage, "\x00\x01"},
+		} {
+			if err := conn.WriteMessage(msg.messageType, []byte(msg.data)); err != nil {
+				t.Fatalf("Failed to send message: %v", err)
+			}
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("Failed to read message: %v", err)
+			}
+			if messageType != msg.messageType || string(data) != msg.data {
+				t.Errorf("Expected message %q of type %d back, got %q of type %d", msg.data, msg.messageType, data, messageType)
+			}
+		}
+	})
+
+	t.Run("template", func(t *testing.T) {
+		conn, _ := dialWebSocket(t, ts, "/chat?user=ada")
+
+		if got := readWebSocketText(t, conn); got != "ready" {
+			t.Errorf("Expected the scripted message first, got %q", got)
+		}
+
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"text": "hi"}`)); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+
+		var reply map[string]string
+		if err := json.Unmarshal([]byte(readWebSocketText(t, conn)), &reply); err != nil {
+			t.Fatalf("Failed to parse reply: %v", err)
+		}
+		if reply["reply"] != "HI" || reply["user"] != "ada" {
+			t.Errorf("Expected a reply rendered from the message and request, got %v", reply)
+		}
+		if reply["raw"] != `{"text": "hi"}` {
+			t.Errorf("Expected the message as received in .RawBody, got %q", reply["raw"])
+		}
+	})
+}
+
+func TestServer_Integration_WebSocketTemplateError(t *testing.T) {
+	ts := NewTestServer(t, webSocketTestConfig())
+
+	conn, _ := dialWebSocket(t, ts, "/broken")
+	expectWebSocketClose(t, conn, websocket.CloseInternalServerErr)
+}
+
+func TestServer_Integration_WebSocketPlainRequest(t *testing.T) {
+	ts := NewTestServer(t, webSocketTestConfig())
+
+	resp, err := ts.makeRequest("GET", "/echo", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body := readResponseBody(t, resp)
+
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("Expected status 426, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Upgrade"); got != "websocket" {
+		t.Errorf("Expected Upgrade: websocket, got %q", got)
+	}
+	if !strings.Contains(body, "only serves WebSocket connections") {
+		t.Errorf("Expected an explanation in the body, got %q", body)
+	}
+}
+
+func TestServer_Integration_WebSocketShutdown(t *testing.T) {
+	ts := NewTestServer(t, webSocketTestConfig())
+
+	conn, resp := dialWebSocket(t, ts, "/idle")
+	if got := resp.Header.Get("Deprecation"); got != "true" {
+		t.Errorf("Expected the handshake to carry the Deprecation header, got %q", got)
+	}
+
+	// Idle connections ignore client messages
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("anyone?")); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+
+	ts.Server.websockets.closeAll()
+	expectWebSocketClose(t, conn, websocket.CloseGoingAway)
+
+	// New connections are refused once shutting down
+	late, _ := dialWebSocket(t, ts, "/idle")
+	expectWebSocketClose(t, late, websocket.CloseGoingAway)
+}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileDriver is the name of the driver keeping each collection in a JSON Lines
+// file inside a directory
+const FileDriver = "file"
+
+// compactThreshold is how many superseded lines a file collection tolerates
+// before rewriting its file with only the live records
+const compactThreshold = 1000
+
+func init() {
+	Register(FileDriver, newFileDriver)
+}
+
+// fileDriver keeps every collection in "<name>.jsonl" inside a directory
+type fileDriver struct {
+	mu          sync.Mutex
+	dir         string
+	collections map[string]*FileCollection
+}
+
+// newFileDriver creates a file driver storing its collections in dir
+func newFileDriver(dir string) (Driver, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("the %q storage driver requires a path", FileDriver)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &fileDriver{dir: dir, collections: make(map[string]*FileCollection)}, nil
+}
+
+// Open returns the collection with the given name, creating its file if needed
+func (d *fileDriver) Open(name string) (Collection, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if c, ok := d.collections[name]; ok {
+		return c, nil
+	}
+
+	c, err := OpenFile(filepath.Join(d.dir, name+".jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	d.collections[name] = c
+	return c, nil
+}
+
+// Close closes every collection opened by the driver
+func (d *fileDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for name, c := range d.collections {
+		errs = append(errs, c.Close())
+		delete(d.collections, name)
+	}
+	return errors.Join(errs...)
+}
+
+// fileLine is one line of a collection file: a record being written, or
+// deleted when Deleted is set
+type fileLine struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Deleted bool            `json:"deleted,omitempty"`
+}
+
+// FileCollection is a collection kept in a JSON Lines file. Writes are appended
+// to the file, which is rewritten with only the live records once enough lines
+// have been superseded by later writes.
+type FileCollection struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	keys map[string]bool // Keys of the live records
+	dead int             // Lines superseded by a later write or delete
+}
+
+// OpenFile opens the collection kept in the file at path, creating the file if
+// it doesn't exist
+func OpenFile(path string) (*FileCollection, error) {
+	c := &FileCollection{path: path}
+
+	records, lines, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	c.keys = make(map[string]bool, len(records))
+	for _, r := range records {
+		c.keys[r.Key] = true
+	}
+	c.dead = lines - len(records)
+
+	if err := c.openFile(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// openFile opens the collection file for appending
+func (c *FileCollection) openFile() error {
+	file, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open storage file: %w", err)
+	}
+	c.file = file
+	return nil
+}
+
+// read replays the collection file, returning the live records and how many
+// lines the file has
+func (c *FileCollection) read() ([]Record, int, error) {
+	file, err := os.Open(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open storage file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	index := make(map[string]int) // Position of each live key in records
+	lines := 0
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines++
+
+		var line fileLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse storage file %q, line %d: %w", c.path, lines, err)
+		}
+
+		i, exists := index[line.Key]
+		switch {
+		case line.Deleted && exists:
+			records[i].Key = "" // Removed once the whole file is read
+			delete(index, line.Key)
+		case line.Deleted:
+		case exists:
+			records[i].Value = line.Value
+		default:
+			index[line.Key] = len(records)
+			records = append(records, Record{Key: line.Key, Value: line.Value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read storage file %q: %w", c.path, err)
+	}
+
+	live := records[:0]
+	for _, r := range records {
+		if r.Key != "" {
+			live = append(live, r)
+		}
+	}
+	return live, lines, nil
+}
+
+// Put stores value under key, replacing any previous value
+func (c *FileCollection) Put(key string, value json.RawMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys[key] {
+		c.dead++
+	}
+	c.keys[key] = true
+	return c.append(fileLine{Key: key, Value: value})
+}
+
+// Delete removes the records with the given keys, ignoring unknown ones
+func (c *FileCollection) Delete(keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if !c.keys[key] {
+			continue
+		}
+
+		delete(c.keys, key)
+		c.dead += 2 // Both the record and its deletion are superseded
+		if err := c.append(fileLine{Key: key, Deleted: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// append writes a line to the file, compacting it when too many lines are dead
+func (c *FileCollection) append(line fileLine) error {
+	if c.file == nil {
+		return fmt.Errorf("storage file %q is closed", c.path)
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("failed to encode record %q: %w", line.Key, err)
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+
+	if c.dead > compactThreshold && c.dead > len(c.keys) {
+		return c.compact()
+	}
+	return nil
+}
+
+// compact rewrites the file with only its live records
+func (c *FileCollection) compact() error {
+	records, _, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to compact storage file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	for _, r := range records {
+		data, err := json.Marshal(fileLine{Key: r.Key, Value: r.Value})
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to encode record %q: %w", r.Key, err)
+		}
+		w.Write(append(data, '\n')) // Errors are reported by Flush
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact storage file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact storage file: %w", err)
+	}
+
+	if err := c.file.Close(); err != nil {
+		return fmt.Errorf("failed to compact storage file: %w", err)
+	}
+	c.file = nil
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return errors.Join(fmt.Errorf("failed to compact storage file: %w", err), c.openFile())
+	}
+	c.dead = 0
+	return c.openFile()
+}
+
+// Clear removes every record
+func (c *FileCollection) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return fmt.Errorf("storage file %q is closed", c.path)
+	}
+	if err := c.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to clear storage file: %w", err)
+	}
+
+	clear(c.keys)
+	c.dead = 0
+	return nil
+}
+
+// Load returns every record in the collection
+func (c *FileCollection) Load() ([]Record, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	records, _, err := c.read()
+	return records, err
+}
+
+// Close closes the collection file
+func (c *FileCollection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.file.Close()
+	c.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close storage file: %w", err)
+	}
+	return nil
+}
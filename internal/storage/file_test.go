@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// recordKeys returns the keys and values of records as "key=value" pairs
+func recordKeys(records []Record) []string {
+	var out []string
+	for _, r := range records {
+		out = append(out, r.Key+"="+string(r.Value))
+	}
+	return out
+}
+
+func TestFileCollection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collection.jsonl")
+
+	c, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("expected collection to open, got %v", err)
+	}
+
+	steps := []func() error{
+		func() error { return c.Put("a", json.RawMessage(`1`)) },
+		func() error { return c.Put("b", json.RawMessage(`2`)) },
+		func() error { return c.Put("c", json.RawMessage(`3`)) },
+		func() error { return c.Put("a", json.RawMessage(`4`)) },
+		func() error { return c.Delete("b", "unknown") },
+		func() error { return c.Put("b", json.RawMessage(`5`)) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: expected no error, got %v", i, err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("expected collection to close, got %v", err)
+	}
+
+	// Reopening replays the file: updates keep their position, records
+	// written again after a delete move to the end
+	c, err = OpenFile(path)
+	if err != nil {
+		t.Fatalf("expected collection to reopen, got %v", err)
+	}
+	defer c.Close()
+
+	records, err := c.Load()
+	if err != nil {
+		t.Fatalf("expected records to load, got %v", err)
+	}
+	if got, expected := strings.Join(recordKeys(records), " "), "a=4 c=3 b=5"; got != expected {
+		t.Errorf("expected records %q, got %q", expected, got)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("expected clear to succeed, got %v", err)
+	}
+	if records, _ := c.Load(); len(records) != 0 {
+		t.Errorf("expected no records after clearing, got %v", recordKeys(records))
+	}
+	if err := c.Put("d", json.RawMessage(`6`)); err != nil {
+		t.Fatalf("expected put after clearing to succeed, got %v", err)
+	}
+	if records, _ := c.Load(); len(records) != 1 || records[0].Key != "d" {
+		t.Errorf("expected only record d, got %v", recordKeys(records))
+	}
+}
+
+func TestFileCollection_Compacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collection.jsonl")
+
+	c, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("expected collection to open, got %v", err)
+	}
+	defer c.Close()
+
+	// Keep a sliding window of 10 records, like a bounded journal does
+	total := compactThreshold * 2
+	for i := range total {
+		if err := c.Put(strconv.Itoa(i), json.RawMessage(strconv.Itoa(i))); err != nil {
+			t.Fatalf("expected put to succeed, got %v", err)
+		}
+		if i >= 10 {
+			if err := c.Delete(strconv.Itoa(i - 10)); err != nil {
+				t.Fatalf("expected delete to succeed, got %v", err)
+			}
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected file to be readable, got %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines > compactThreshold+20 {
+		t.Errorf("expected the file to be compacted, it has %d lines", lines)
+	}
+
+	records, err := c.Load()
+	if err != nil {
+		t.Fatalf("expected records to load, got %v", err)
+	}
+	if len(records) != 10 || records[0].Key != strconv.Itoa(total-10) {
+		t.Errorf("expected the last 10 records, got %v", recordKeys(records))
+	}
+}
+
+func TestFileCollection_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collection.jsonl")
+	if err := os.WriteFile(path, []byte("{\"key\":\"a\",\"value\":1}\nnot json\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	_, err := OpenFile(path)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a parse error on line 2, got %v", err)
+	}
+}
+
+func TestFileDriver(t *testing.T) {
+	if _, err := Open(FileDriver, ""); err == nil {
+		t.Error("expected the file driver to require a path")
+	}
+
+	dir := filepath.Join(t.TempDir(), "data")
+	driver, err := Open(FileDriver, dir)
+	if err != nil {
+		t.Fatalf("expected file driver to open, got %v", err)
+	}
+
+	c, err := driver.Open("journal")
+	if err != nil {
+		t.Fatalf("expected collection to open, got %v", err)
+	}
+	if again, _ := driver.Open("journal"); again != c {
+		t.Error("expected the same collection when opened twice")
+	}
+	if err := c.Put("1", json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("expected put to succeed, got %v", err)
+	}
+
+	if err := driver.Close(); err != nil {
+		t.Fatalf("expected driver to close, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journal.jsonl")); err != nil {
+		t.Errorf("expected the collection file to exist, got %v", err)
+	}
+	if err := c.Put("2", json.RawMessage(`{}`)); err == nil {
+		t.Error("expected writes to fail once the driver is closed")
+	}
+}
@@ -0,0 +1,26 @@
+package storage
+
+import "encoding/json"
+
+// MemoryDriver is the name of the default driver, which persists nothing
+const MemoryDriver = "memory"
+
+func init() {
+	Register(MemoryDriver, func(string) (Driver, error) { return memoryDriver{}, nil })
+}
+
+// memoryDriver keeps no copy of its own: data only lives in the stores using
+// it, in the process memory, and is lost when the process exits
+type memoryDriver struct{}
+
+func (memoryDriver) Open(string) (Collection, error) { return memoryCollection{}, nil }
+func (memoryDriver) Close() error                    { return nil }
+
+// memoryCollection discards every write, since its stores already hold the data
+type memoryCollection struct{}
+
+func (memoryCollection) Put(string, json.RawMessage) error { return nil }
+func (memoryCollection) Delete(...string) error            { return nil }
+func (memoryCollection) Clear() error                      { return nil }
+func (memoryCollection) Load() ([]Record, error)           { return nil, nil }
+func (memoryCollection) Close() error                      { return nil }
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver, without cgo
+)
+
+// SQLiteDriver is the name of the driver keeping every collection in a table
+// of a SQLite database file, which can be queried with any SQLite client
+const SQLiteDriver = "sqlite"
+
+// sqliteSchema creates the table holding the records of every collection.
+// Upserts keep a record's rowid, so ordering by it gives the order keys were
+// first written in.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS records (
+	collection TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	value      TEXT NOT NULL,
+	PRIMARY KEY (collection, key)
+)`
+
+func init() {
+	Register(SQLiteDriver, newSQLiteDriver)
+}
+
+// sqliteDriver keeps every collection in the records table of a database
+type sqliteDriver struct {
+	mu     sync.Mutex
+	db     *sql.DB
+	closed bool
+}
+
+// newSQLiteDriver creates a SQLite driver storing its collections in the
+// database file at path, creating the file and its directory if needed
+func newSQLiteDriver(path string) (Driver, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the %q storage driver requires a path", SQLiteDriver)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	// Writers wait for each other instead of failing, and readers don't
+	// block them
+	dsn := (&url.URL{
+		Scheme:   "file",
+		Opaque:   path,
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+	}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage database: %w", err)
+	}
+	db.SetMaxOpenConns(1) // SQLite serializes writes anyway
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open storage database %q: %w", path, err)
+	}
+	return &sqliteDriver{db: db}, nil
+}
+
+// Open returns the collection with the given name
+func (d *sqliteDriver) Open(name string) (Collection, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, fmt.Errorf("storage database is closed")
+	}
+	return &sqliteCollection{db: d.db, name: name}, nil
+}
+
+// Close closes the database, and with it every collection opened by the driver
+func (d *sqliteDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	return d.db.Close()
+}
+
+// sqliteCollection is the set of rows of the records table of one collection
+type sqliteCollection struct {
+	db   *sql.DB
+	name string
+}
+
+// Put stores value under key, replacing any previous value
+func (c *sqliteCollection) Put(key string, value json.RawMessage) error {
+	_, err := c.db.Exec(`INSERT INTO records (collection, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (collection, key) DO UPDATE SET value = excluded.value`, c.name, key, string(value))
+	if err != nil {
+		return fmt.Errorf("failed to write record %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the records with the given keys, ignoring unknown ones
+func (c *sqliteCollection) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	defer tx.Rollback() // No-op once committed
+
+	for _, key := range keys {
+		if _, err := tx.Exec(`DELETE FROM records WHERE collection = ? AND key = ?`, c.name, key); err != nil {
+			return fmt.Errorf("failed to delete record %q: %w", key, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every record
+func (c *sqliteCollection) Clear() error {
+	if _, err := c.db.Exec(`DELETE FROM records WHERE collection = ?`, c.name); err != nil {
+		return fmt.Errorf("failed to clear records: %w", err)
+	}
+	return nil
+}
+
+// Load returns every record in the collection
+func (c *sqliteCollection) Load() ([]Record, error) {
+	rows, err := c.db.Query(`SELECT key, value FROM records WHERE collection = ? ORDER BY rowid`, c.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read records: %w", err)
+		}
+		records = append(records, Record{Key: key, Value: json.RawMessage(value)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	return records, nil
+}
+
+// Close is a no-op, since the database is shared by the driver's collections
+// and closed with the driver
+func (c *sqliteCollection) Close() error {
+	return nil
+}
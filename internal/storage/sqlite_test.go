@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteDriver(t *testing.T) {
+	if _, err := Open(SQLiteDriver, ""); err == nil {
+		t.Error("expected the sqlite driver to require a path")
+	}
+
+	path := filepath.Join(t.TempDir(), "data", "mockingjay.db")
+	driver, err := Open(SQLiteDriver, path)
+	if err != nil {
+		t.Fatalf("expected sqlite driver to open, got %v", err)
+	}
+
+	journal, err := driver.Open("journal")
+	if err != nil {
+		t.Fatalf("expected collection to open, got %v", err)
+	}
+	scenarios, err := driver.Open("scenarios")
+	if err != nil {
+		t.Fatalf("expected collection to open, got %v", err)
+	}
+
+	steps := []func() error{
+		func() error { return journal.Put("a", json.RawMessage(`1`)) },
+		func() error { return journal.Put("b", json.RawMessage(`{"n": 2}`)) },
+		func() error { return journal.Put("c", json.RawMessage(`3`)) },
+		func() error { return journal.Put("a", json.RawMessage(`4`)) },
+		func() error { return journal.Delete("b", "unknown") },
+		func() error { return journal.Put("b", json.RawMessage(`5`)) },
+		func() error { return scenarios.Put("a", json.RawMessage(`"other"`)) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: expected no error, got %v", i, err)
+		}
+	}
+	if err := driver.Close(); err != nil {
+		t.Fatalf("expected driver to close, got %v", err)
+	}
+	if err := journal.Put("d", json.RawMessage(`6`)); err == nil {
+		t.Error("expected writes to fail once the driver is closed")
+	}
+
+	// Reopening reads the database back: updates keep their position,
+	// records written again after a delete move to the end, and collections
+	// don't see each other's records
+	driver, err = Open(SQLiteDriver, path)
+	if err != nil {
+		t.Fatalf("expected sqlite driver to reopen, got %v", err)
+	}
+	defer driver.Close()
+
+	journal, _ = driver.Open("journal")
+	scenarios, _ = driver.Open("scenarios")
+
+	records, err := journal.Load()
+	if err != nil {
+		t.Fatalf("expected records to load, got %v", err)
+	}
+	if got, expected := strings.Join(recordKeys(records), " "), "a=4 c=3 b=5"; got != expected {
+		t.Errorf("expected records %q, got %q", expected, got)
+	}
+
+	if err := journal.Clear(); err != nil {
+		t.Fatalf("expected clear to succeed, got %v", err)
+	}
+	if records, _ := journal.Load(); len(records) != 0 {
+		t.Errorf("expected no records after clearing, got %v", recordKeys(records))
+	}
+	if records, _ := scenarios.Load(); strings.Join(recordKeys(records), " ") != `a="other"` {
+		t.Errorf("expected clearing a collection to leave the others alone, got %v", recordKeys(records))
+	}
+}
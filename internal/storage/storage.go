@@ -0,0 +1,93 @@
+// Package storage defines where mockingjay keeps the data it captures while
+// serving requests, like the request journal and the state of sequenced
+// routes. Drivers register themselves by name and are selected in the
+// configuration.
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Record is a JSON value kept in a collection under a unique key
+type Record struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// Collection is a named set of records kept by a driver. Records are loaded in
+// the order their key was first written. Implementations must be safe for
+// concurrent use.
+type Collection interface {
+	// Put stores value under key, replacing any previous value
+	Put(key string, value json.RawMessage) error
+
+	// Delete removes the records with the given keys, ignoring unknown ones
+	Delete(keys ...string) error
+
+	// Clear removes every record
+	Clear() error
+
+	// Load returns every record in the collection
+	Load() ([]Record, error)
+
+	// Close releases the resources held by the collection
+	Close() error
+}
+
+// Driver opens the collections of a storage backend
+type Driver interface {
+	// Open returns the collection with the given name, creating it if needed
+	Open(name string) (Collection, error)
+
+	// Close closes every collection opened by the driver
+	Close() error
+}
+
+// Factory creates a driver keeping its data at path
+type Factory func(path string) (Driver, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+// Register makes a driver available under name. It panics if a driver is
+// already registered with that name.
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if _, exists := drivers[name]; exists {
+		panic(fmt.Sprintf("storage: driver %q registered twice", name))
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the names of the registered drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Open creates the driver registered under name
+func Open(name, path string) (Driver, error) {
+	driversMu.RLock()
+	factory, ok := drivers[name]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q, available drivers: %s", name, strings.Join(Drivers(), ", "))
+	}
+	return factory(path)
+}
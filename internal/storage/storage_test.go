@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	if drivers := Drivers(); !slices.Equal(drivers, []string{FileDriver, MemoryDriver, SQLiteDriver}) {
+		t.Errorf("expected the file, memory and sqlite drivers, got %v", drivers)
+	}
+
+	driver, err := Open(MemoryDriver, "")
+	if err != nil {
+		t.Fatalf("expected memory driver to open, got %v", err)
+	}
+	defer driver.Close()
+
+	_, err = Open("redis", "localhost:6379")
+	if err == nil || !strings.Contains(err.Error(), `unknown storage driver "redis", available drivers: file, memory, sqlite`) {
+		t.Errorf("expected unknown driver error, got %v", err)
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a driver twice to panic")
+		}
+	}()
+	Register(MemoryDriver, nil)
+}
+
+func TestMemoryDriver(t *testing.T) {
+	driver, err := Open(MemoryDriver, "")
+	if err != nil {
+		t.Fatalf("expected memory driver to open, got %v", err)
+	}
+
+	c, err := driver.Open("journal")
+	if err != nil {
+		t.Fatalf("expected collection to open, got %v", err)
+	}
+
+	// Data only lives in the stores using the collection
+	if err := c.Put("1", []byte(`{}`)); err != nil {
+		t.Fatalf("expected put to succeed, got %v", err)
+	}
+	records, err := c.Load()
+	if err != nil || len(records) != 0 {
+		t.Errorf("expected no persisted records, got %v (%v)", records, err)
+	}
+}
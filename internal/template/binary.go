@@ -0,0 +1,40 @@
+package template
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Templates render text, but Go strings hold any bytes, so the functions
+// below return raw bytes as strings. They can be written as-is, or piped to
+// b64enc for routes with "body_encoding: base64".
+
+// hexdec decodes a hex string into raw bytes, ignoring whitespace
+// Usage in templates: {{ hexdec "89504e47 0d0a1a0a" }}
+func hexdec(s string) (string, error) {
+	data, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return "", fmt.Errorf("hexdec: %w", err)
+	}
+	return string(data), nil
+}
+
+// hexenc encodes a string's bytes as lowercase hex
+// Usage in templates: {{ .RawBody | hexenc }}
+func hexenc(s string) string {
+	return hex.EncodeToString([]byte(s))
+}
+
+// rawBytes returns the bytes with the given values, each from 0 to 255
+// Usage in templates: {{ rawBytes 0x89 0x50 0x4e 0x47 }}
+func rawBytes(values ...int) (string, error) {
+	data := make([]byte, len(values))
+	for i, value := range values {
+		if value < 0 || value > 255 {
+			return "", fmt.Errorf("rawBytes: value %d is out of the 0-255 range", value)
+		}
+		data[i] = byte(value)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,47 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBinaryFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		want        string
+		errContains string
+	}{
+		{name: "hexdec", template: `{{ hexdec "89504e47 0d0a" }}`, want: "\x89PNG\r\n"},
+		{name: "hexdec invalid", template: `{{ hexdec "zz" }}`, errContains: "hexdec"},
+		{name: "hexenc", template: `{{ hexenc "\x00hi" }}`, want: "006869"},
+		{name: "rawBytes", template: `{{ rawBytes 0 0x89 255 }}`, want: "\x00\x89\xff"},
+		{name: "rawBytes out of range", template: `{{ rawBytes 256 }}`, errContains: "out of the 0-255 range"},
+		{name: "base64 round trip", template: `{{ rawBytes 0xff 0xfe | b64enc }}`, want: "//4="},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := engine.CompileInlineTemplate("body", tt.template)
+			if err != nil {
+				t.Fatalf("Failed to compile template: %v", err)
+			}
+
+			var buf strings.Builder
+			err = engine.ExecuteTemplate(tmpl, &buf, &TemplateContext{})
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, buf.String())
+			}
+		})
+	}
+}
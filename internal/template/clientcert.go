@@ -0,0 +1,54 @@
+package template
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"time"
+)
+
+// ClientCertInfo describes the TLS client certificate a request was sent with
+type ClientCertInfo struct {
+	Subject      string    `json:"subject"`       // Distinguished name of the subject
+	CommonName   string    `json:"common_name"`   // Common name of the subject
+	Issuer       string    `json:"issuer"`        // Distinguished name of the issuer
+	SerialNumber string    `json:"serial_number"` // Serial number, in decimal
+	SANs         []string  `json:"sans"`          // DNS, email, IP and URI subject alternative names
+	NotBefore    time.Time `json:"not_before"`    // Start of the validity period
+	NotAfter     time.Time `json:"not_after"`     // End of the validity period
+	Fingerprint  string    `json:"fingerprint"`   // SHA-256 fingerprint, in hex
+	Verified     bool      `json:"verified"`      // Whether the certificate was verified against the client CAs
+}
+
+// NewClientCertInfo describes the client certificate of a TLS connection,
+// returning nil when the request wasn't sent over TLS or without a certificate
+func NewClientCertInfo(state *tls.ConnectionState) *ClientCertInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	cert := state.PeerCertificates[0]
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return &ClientCertInfo{
+		Subject:      cert.Subject.String(),
+		CommonName:   cert.Subject.CommonName,
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: cert.SerialNumber.String(),
+		SANs:         sans,
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		Verified:     len(state.VerifiedChains) > 0,
+	}
+}
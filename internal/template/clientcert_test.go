@@ -0,0 +1,52 @@
+package template
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestNewClientCertInfo(t *testing.T) {
+	if info := NewClientCertInfo(nil); info != nil {
+		t.Errorf("Expected no certificate without TLS, got %+v", info)
+	}
+	if info := NewClientCertInfo(&tls.ConnectionState{}); info != nil {
+		t.Errorf("Expected no certificate without peer certificates, got %+v", info)
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.org/billing")
+	cert := &x509.Certificate{
+		Raw:            []byte("certificate"),
+		SerialNumber:   big.NewInt(42),
+		Subject:        pkix.Name{CommonName: "billing", Organization: []string{"Example"}},
+		Issuer:         pkix.Name{CommonName: "Example CA"},
+		DNSNames:       []string{"billing.internal"},
+		EmailAddresses: []string{"billing@example.org"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{spiffe},
+	}
+
+	info := NewClientCertInfo(&tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	})
+	if info == nil {
+		t.Fatal("Expected certificate info")
+	}
+
+	if info.CommonName != "billing" || info.Subject != "CN=billing,O=Example" || info.Issuer != "CN=Example CA" || info.SerialNumber != "42" {
+		t.Errorf("Unexpected subject info: %+v", info)
+	}
+	expectedSANs := []string{"billing.internal", "billing@example.org", "10.0.0.1", "spiffe://example.org/billing"}
+	if !slices.Equal(info.SANs, expectedSANs) {
+		t.Errorf("Expected SANs %v, got %v", expectedSANs, info.SANs)
+	}
+	if !info.Verified || len(info.Fingerprint) != 64 {
+		t.Errorf("Expected a verified certificate with a SHA-256 fingerprint, got %+v", info)
+	}
+}
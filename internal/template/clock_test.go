@@ -0,0 +1,100 @@
+package template
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	before := time.Now()
+	clock := NewClock(-time.Hour)
+
+	now := clock.Now()
+	if now.Before(before.Add(-time.Hour)) || now.After(time.Now().Add(-time.Hour)) {
+		t.Errorf("Expected the clock to be an hour behind, got %v", now)
+	}
+	if clock.Skew() != -time.Hour {
+		t.Errorf("Expected skew -1h, got %v", clock.Skew())
+	}
+
+	// The clock is frozen, so every reading agrees
+	time.Sleep(time.Millisecond)
+	if !clock.Now().Equal(now) {
+		t.Error("Expected the clock to stay frozen")
+	}
+
+	in, err := clock.In("15m")
+	if err != nil || !in.Equal(now.Add(15*time.Minute)) {
+		t.Errorf("Expected In(15m) to be 15 minutes later, got %v (%v)", in, err)
+	}
+	if unix, err := clock.UnixIn("-30s"); err != nil || unix != now.Add(-30*time.Second).Unix() {
+		t.Errorf("Expected UnixIn(-30s) to be 30 seconds earlier, got %d (%v)", unix, err)
+	}
+	if clock.Unix() != now.Unix() {
+		t.Errorf("Expected Unix to be %d, got %d", now.Unix(), clock.Unix())
+	}
+
+	if date := clock.HTTPDate(); date != now.UTC().Format(http.TimeFormat) {
+		t.Errorf("Unexpected HTTP date %q", date)
+	}
+	if date, err := clock.HTTPDateIn("24h"); err != nil || date != now.Add(24*time.Hour).UTC().Format(http.TimeFormat) {
+		t.Errorf("Unexpected HTTP date %q (%v)", date, err)
+	}
+
+	if _, err := clock.In("soon"); err == nil || !strings.Contains(err.Error(), `invalid clock offset "soon"`) {
+		t.Errorf("Expected invalid offset error, got %v", err)
+	}
+}
+
+func TestClock_ZeroValue(t *testing.T) {
+	// A context built without a clock uses the real time
+	var clock Clock
+	if d := time.Since(clock.Now()); d < 0 || d > time.Second {
+		t.Errorf("Expected the zero clock to use the real time, got %v", clock.Now())
+	}
+}
+
+func TestClockAt(t *testing.T) {
+	at := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClockAt(at, time.Hour)
+
+	if !clock.Now().Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected the clock at %v shifted by its skew, got %v", at, clock.Now())
+	}
+	if !clock.Fixed() {
+		t.Error("Expected the clock to be fixed")
+	}
+	if NewClock(0).Fixed() {
+		t.Error("Expected a clock following the real time not to be fixed")
+	}
+}
+
+func TestClock_TemplateFunctions(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("clock", `{{ now.Format "2006-01-02T15:04" }} {{ .Now.Format "2006-01-02T15:04" }} {{ fakeFuture.After now }} {{ fakePast.Before now }} {{ (fakeFuture.Sub now).Hours | ge 12.0 }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	at := time.Date(2030, time.March, 15, 9, 30, 0, 0, time.UTC)
+	ctx := &TemplateContext{Clock: NewClockAt(at, 0)}
+
+	var buf strings.Builder
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if expected := "2030-03-15T09:30 2030-03-15T09:30 true true true"; buf.String() != expected {
+		t.Errorf("Expected time functions to follow the clock, got %q, want %q", buf.String(), expected)
+	}
+
+	// Clocks following the real time leave the template's functions alone
+	if clocked, err := clockedTemplate(tmpl, NewClock(0)); err != nil || clocked != tmpl {
+		t.Errorf("Expected the same template for the real clock, got %p (%v)", clocked, err)
+	}
+	if !NewClock(0).Real() || NewClock(time.Minute).Real() || NewClockAt(at, 0).Real() {
+		t.Error("Expected only unskewed clocks following the real time to be real")
+	}
+}
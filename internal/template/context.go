@@ -1,8 +1,12 @@
 package template
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -19,46 +23,157 @@ type TemplateContext struct {
 	// Query contains all query parameters with full access to url.Values methods
 	Query url.Values `json:"query"`
 
-	// Body contains the parsed request body (JSON if applicable, string otherwise)
+	// Body contains the parsed request body (JSON or XML if applicable, string otherwise)
 	Body interface{} `json:"body"`
 
+	// RawBody contains the request body exactly as it was received
+	RawBody string `json:"-"`
+
+	// Form contains the fields of form bodies, urlencoded or multipart, with
+	// full access to url.Values methods
+	Form url.Values `json:"form,omitempty"`
+
+	// Files describes the files uploaded in multipart/form-data bodies
+	Files Files `json:"files,omitempty"`
+
+	// Cookies contains the cookies sent with the request, by name
+	Cookies map[string]string `json:"cookies,omitempty"`
+
 	// Params contains named capture groups from regex route patterns
 	Params map[string]string `json:"params"`
+
+	// Response lets the template control the response, e.g. its status code
+	Response *Response `json:"-"`
+
+	// Route describes the route that matched the request
+	Route RouteInfo `json:"route"`
+
+	// RequestID identifies the request, taken from X-Request-ID or generated
+	RequestID string `json:"request_id"`
+
+	// Tokens mints tokens defined in the token bucket
+	Tokens Tokens `json:"-"`
+
+	// Clock is the current time as seen by the mock, including any configured skew
+	Clock Clock `json:"-"`
+
+	// Vars contains values injected through X-Mockingjay-Var-* request headers,
+	// when the server enables header variables
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// Transaction describes the transition performed by the route, when it performs one
+	Transaction *TransactionInfo `json:"transaction,omitempty"`
+
+	// TLS describes the TLS connection of the request, when it was sent over TLS
+	TLS *TLSInfo `json:"tls,omitempty"`
+
+	// ClientCert describes the TLS client certificate of the request, when it was sent with one
+	ClientCert *ClientCertInfo `json:"client_cert,omitempty"`
+
+	// Logger receives the events templates log with logEvent (nil to drop them)
+	Logger *slog.Logger `json:"-"`
+
+	// Error describes the built-in error replaced by the error page being
+	// rendered, and is nil everywhere else
+	Error *ErrorInfo `json:"error,omitempty"`
+
+	// seed seeds the random functions of templates, when the route is deterministic
+	seed *uint64
+}
+
+// Tokens mints tokens for templates that simulate an auth token lifecycle.
+// Usage in templates: {{ .Tokens.Mint "access" }} and {{ .Tokens.ExpiresIn "access" }}
+type Tokens interface {
+	// Mint issues a new token of the named kind
+	Mint(name string) (string, error)
+
+	// ExpiresIn returns the lifetime of the named kind of token in seconds
+	ExpiresIn(name string) (int, error)
 }
 
+// RouteInfo describes a matched route to templates
+type RouteInfo struct {
+	Pattern string `json:"pattern"` // The path pattern as written in the configuration
+	Method  string `json:"method"`  // The HTTP method (uppercase)
+}
+
+// TransactionInfo describes a transaction moved to a new state by a route
+type TransactionInfo struct {
+	Name  string `json:"name"`  // The transaction kind, e.g. "payment"
+	ID    string `json:"id"`    // The transaction ID
+	From  string `json:"from"`  // The state before the transition
+	State string `json:"state"` // The state after the transition
+}
+
+// requestIDHeader is the request header whose value becomes the request ID
+const requestIDHeader = "X-Request-ID"
+
 // NewTemplateContext creates a new TemplateContext from an HTTP request and route parameters
 func NewTemplateContext(req *http.Request, params map[string]string) (*TemplateContext, error) {
 	ctx := &TemplateContext{
-		Request: req,
-		Headers: req.Header,
-		Query:   req.URL.Query(),
-		Params:  params,
+		Request:    req,
+		Headers:    req.Header,
+		Query:      req.URL.Query(),
+		Params:     params,
+		Cookies:    requestCookies(req),
+		Response:   NewResponse(),
+		RequestID:  requestID(req),
+		Vars:       make(map[string]string),
+		TLS:        NewTLSInfo(req.TLS),
+		ClientCert: NewClientCertInfo(req.TLS),
 	}
 
 	// Parse request body
-	body, err := parseRequestBody(req)
+	body, raw, err := parseRequestBody(req)
 	if err != nil {
-		// Don't fail the entire context creation for body parsing errors
-		// Just set body to the error message
+		// Bodies over the server's size limit fail the request
+		if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+			return nil, err
+		}
+
+		// Don't fail the entire context creation for other body parsing
+		// errors. Just set body to the error message
 		ctx.Body = err.Error()
 	} else {
 		ctx.Body = body
+		ctx.RawBody = string(raw)
+	}
+
+	// Malformed forms leave .Form and .Files empty, with the body still in
+	// .Body and .RawBody
+	if form, files, err := parseForm(req.Header.Get("Content-Type"), raw); err == nil {
+		ctx.Form = form
+		ctx.Files = files
 	}
 
 	return ctx, nil
 }
 
+// requestID returns the client-provided request ID, or a new random one
+func requestID(req *http.Request) string {
+	if id := strings.TrimSpace(req.Header.Get(requestIDHeader)); id != "" {
+		return id
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b[:])
+}
+
 // parseRequestBody attempts to parse the request body
-// Returns parsed JSON if Content-Type indicates JSON, otherwise returns raw string
-func parseRequestBody(req *http.Request) (interface{}, error) {
+// Returns parsed JSON or XML if Content-Type indicates either, otherwise returns raw string,
+// along with the bytes read.
+// Bodies are read up to the limit set by http.MaxBytesReader, if any, and a
+// *http.MaxBytesError is returned for larger ones.
+func parseRequestBody(req *http.Request) (interface{}, []byte, error) {
 	if req.Body == nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// Read the body
 	bodyBytes, err := io.ReadAll(req.Body)
 	if err != nil {
-		return nil, &ContextError{
+		return nil, nil, &ContextError{
 			Component: "body",
 			Message:   "failed to read request body",
 			Cause:     err,
@@ -67,7 +182,7 @@ func parseRequestBody(req *http.Request) (interface{}, error) {
 
 	// Check if body is empty
 	if len(bodyBytes) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// Get content type
@@ -81,13 +196,26 @@ func parseRequestBody(req *http.Request) (interface{}, error) {
 			return map[string]interface{}{
 				"raw":         string(bodyBytes),
 				"parse_error": err.Error(),
-			}, nil
+			}, bodyBytes, nil
+		}
+		return jsonBody, bodyBytes, nil
+	}
+
+	// Parse XML into maps navigable like JSON
+	if isXMLContentType(contentType) {
+		xmlBody, err := parseXML(bodyBytes)
+		if err != nil {
+			// If XML parsing fails, return as string with error info
+			return map[string]interface{}{
+				"raw":         string(bodyBytes),
+				"parse_error": err.Error(),
+			}, bodyBytes, nil
 		}
-		return jsonBody, nil
+		return xmlBody, bodyBytes, nil
 	}
 
-	// Return as string for non-JSON content
-	return string(bodyBytes), nil
+	// Return as string for other content
+	return string(bodyBytes), bodyBytes, nil
 }
 
 // isJSONContentType checks if the content type indicates JSON
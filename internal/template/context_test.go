@@ -3,6 +3,7 @@ package template
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
@@ -140,7 +141,7 @@ func TestParseRequestBody_JSON(t *testing.T) {
 			}
 			req.Header.Set("Content-Type", tt.contentType)
 
-			result, err := parseRequestBody(req)
+			result, _, err := parseRequestBody(req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseRequestBody() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -209,12 +210,6 @@ func TestParseRequestBody_NonJSON(t *testing.T) {
 			contentType: "",
 			expected:    "some data",
 		},
-		{
-			name:        "XML content",
-			body:        "<root><item>test</item></root>",
-			contentType: "application/xml",
-			expected:    "<root><item>test</item></root>",
-		},
 	}
 
 	for _, tt := range tests {
@@ -227,7 +222,7 @@ func TestParseRequestBody_NonJSON(t *testing.T) {
 				req.Header.Set("Content-Type", tt.contentType)
 			}
 
-			result, err := parseRequestBody(req)
+			result, _, err := parseRequestBody(req)
 			if err != nil {
 				t.Errorf("parseRequestBody() error = %v, expected no error", err)
 				return
@@ -271,7 +266,7 @@ func TestParseRequestBody_EdgeCases(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			req := tt.setupReq()
 
-			result, err := parseRequestBody(req)
+			result, _, err := parseRequestBody(req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseRequestBody() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -389,6 +384,15 @@ func TestNewTemplateContext_ErrorHandling(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "body over the size limit",
+			setupReq: func() (*http.Request, map[string]string) {
+				req, _ := http.NewRequest("POST", "/test", strings.NewReader(`{"name": "too long"}`))
+				req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, 8)
+				return req, nil
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -516,7 +520,7 @@ func BenchmarkParseRequestBody_JSON(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		req, _ := http.NewRequest("POST", "/test", strings.NewReader(jsonData))
 		req.Header.Set("Content-Type", "application/json")
-		_, err := parseRequestBody(req)
+		_, _, err := parseRequestBody(req)
 		if err != nil {
 			b.Fatalf("parseRequestBody() error = %v", err)
 		}
@@ -530,9 +534,94 @@ func BenchmarkParseRequestBody_Text(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		req, _ := http.NewRequest("POST", "/test", strings.NewReader(textData))
 		req.Header.Set("Content-Type", "text/plain")
-		_, err := parseRequestBody(req)
+		_, _, err := parseRequestBody(req)
 		if err != nil {
 			b.Fatalf("parseRequestBody() error = %v", err)
 		}
 	}
 }
+
+func TestNewTemplateContext_RequestID(t *testing.T) {
+	// A client-provided request ID is kept as-is
+	req, err := http.NewRequest("GET", "/test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("X-Request-ID", "client-id-42")
+
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("NewTemplateContext() error = %v, expected no error", err)
+	}
+	if ctx.RequestID != "client-id-42" {
+		t.Errorf("NewTemplateContext() RequestID = %q, expected %q", ctx.RequestID, "client-id-42")
+	}
+
+	// Otherwise a random ID is generated for every request
+	req.Header.Del("X-Request-ID")
+	first, _ := NewTemplateContext(req, nil)
+	second, _ := NewTemplateContext(req, nil)
+
+	if len(first.RequestID) != 32 {
+		t.Errorf("NewTemplateContext() generated RequestID %q, expected 32 hex characters", first.RequestID)
+	}
+	if first.RequestID == second.RequestID {
+		t.Errorf("NewTemplateContext() generated the same RequestID twice: %q", first.RequestID)
+	}
+}
+
+func TestTemplateContext_RouteAndRequestIDInJSON(t *testing.T) {
+	req, err := http.NewRequest("GET", "/users/1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("X-Request-ID", "abc")
+
+	ctx, err := NewTemplateContext(req, map[string]string{"id": "1"})
+	if err != nil {
+		t.Fatalf("NewTemplateContext() error = %v, expected no error", err)
+	}
+	ctx.Route = RouteInfo{Pattern: "/users/1", Method: "GET"}
+
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		t.Fatalf("Failed to marshal context: %v", err)
+	}
+
+	for _, want := range []string{`"request_id":"abc"`, `"route":{"pattern":"/users/1","method":"GET"}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected JSON %s to contain %s", data, want)
+		}
+	}
+}
+
+func TestNewTemplateContext_RawBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+	}{
+		{name: "JSON keeps its formatting", body: "{ \"b\": 2,\n  \"a\": 1 }", contentType: "application/json"},
+		{name: "invalid JSON", body: `{invalid`, contentType: "application/json"},
+		{name: "binary", body: "\x00\xff\x10binary", contentType: "application/octet-stream"},
+		{name: "empty", body: "", contentType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/upload", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+
+			ctx, err := NewTemplateContext(req, nil)
+			if err != nil {
+				t.Fatalf("NewTemplateContext() error = %v, expected no error", err)
+			}
+			if ctx.RawBody != tt.body {
+				t.Errorf("NewTemplateContext() RawBody = %q, expected %q", ctx.RawBody, tt.body)
+			}
+		})
+	}
+}
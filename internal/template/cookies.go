@@ -0,0 +1,201 @@
+package template
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestCookies returns the cookies sent with req by name. When a name is
+// sent more than once, the first value wins, like http.Request.Cookie.
+func requestCookies(req *http.Request) map[string]string {
+	cookies := req.Cookies()
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(cookies))
+	for _, cookie := range cookies {
+		if _, ok := values[cookie.Name]; !ok {
+			values[cookie.Name] = cookie.Value
+		}
+	}
+	return values
+}
+
+// setCookie checks a cookie can be sent, without setting it. Templates call
+// it as setCookie, which stands for Response.SetCookie while a response is
+// rendered, so header templates can set cookies as well as body templates.
+// Usage in templates: {{ setCookie "session" "abc123" (dict "maxAge" 3600 "httpOnly" true) }}
+func setCookie(name, value string, options ...map[string]any) (string, error) {
+	_, err := newCookie(name, value, options...)
+	return "", err
+}
+
+// deleteCookie checks a cookie can be deleted, like setCookie. Templates call
+// it as deleteCookie, which stands for Response.DeleteCookie while a response
+// is rendered.
+// Usage in templates: {{ deleteCookie "session" (dict "path" "/") }}
+func deleteCookie(name string, options ...map[string]any) (string, error) {
+	return setCookie(name, "", append(options, map[string]any{"max_age": -1})...)
+}
+
+// newCookie builds a cookie from template options, failing when it can't be
+// sent in a Set-Cookie header
+func newCookie(name, value string, options ...map[string]any) (*http.Cookie, error) {
+	cookie := &http.Cookie{Name: name, Value: value}
+	for _, opts := range options {
+		if err := applyCookieOptions(cookie, opts); err != nil {
+			return nil, fmt.Errorf("cookie %q: %w", name, err)
+		}
+	}
+	if err := cookie.Valid(); err != nil {
+		return nil, fmt.Errorf("invalid cookie %q: %w", name, err)
+	}
+	return cookie, nil
+}
+
+// SetCookie adds a Set-Cookie header to the response, replacing any cookie
+// the template already set with the same name. Options are given as a map
+// with the keys "path", "domain", "max_age" (in seconds, zero or negative to
+// delete the cookie), "expires" (a time, or an RFC 3339 or HTTP date),
+// "secure", "http_only", "same_site" ("lax", "strict" or "none") and
+// "partitioned". Keys can also be written in camel case, like "maxAge".
+// Usage in templates: {{ .Response.SetCookie "session" "abc123" (dict "path" "/" "http_only" true) }}
+// Returns an empty string so it doesn't affect template output.
+func (r *Response) SetCookie(name, value string, options ...map[string]any) (string, error) {
+	cookie, err := newCookie(name, value, options...)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.cookies {
+		if existing.Name == name {
+			r.cookies[i] = cookie
+			return "", nil
+		}
+	}
+	r.cookies = append(r.cookies, cookie)
+	return "", nil
+}
+
+// DeleteCookie tells the client to remove a cookie, by setting it empty and
+// already expired. Options are the same as SetCookie's, since the path and
+// domain must match those the cookie was set with.
+// Usage in templates: {{ .Response.DeleteCookie "session" }}
+// Returns an empty string so it doesn't affect template output.
+func (r *Response) DeleteCookie(name string, options ...map[string]any) (string, error) {
+	return r.SetCookie(name, "", append(options, map[string]any{"max_age": -1})...)
+}
+
+// Cookies returns the cookies set by the template, in the order they were
+// first set
+func (r *Response) Cookies() []*http.Cookie {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*http.Cookie(nil), r.cookies...)
+}
+
+// applyCookieOptions sets the attributes of cookie from template options,
+// whose keys are written in snake case, like "max_age", or camel case, like
+// "maxAge"
+func applyCookieOptions(cookie *http.Cookie, options map[string]any) error {
+	for key, value := range options {
+		var err error
+		switch strings.ToLower(strings.ReplaceAll(key, "_", "")) {
+		case "path":
+			cookie.Path = fmt.Sprint(value)
+		case "domain":
+			cookie.Domain = fmt.Sprint(value)
+		case "maxage":
+			cookie.MaxAge, err = cookieInt(value)
+			// http.Cookie reads 0 as unset, and negative values as "Max-Age=0"
+			if err == nil && cookie.MaxAge == 0 {
+				cookie.MaxAge = -1
+			}
+		case "expires":
+			cookie.Expires, err = cookieTime(value)
+		case "secure":
+			cookie.Secure, err = cookieBool(value)
+		case "httponly":
+			cookie.HttpOnly, err = cookieBool(value)
+		case "samesite":
+			cookie.SameSite, err = cookieSameSite(value)
+		case "partitioned":
+			cookie.Partitioned, err = cookieBool(value)
+		default:
+			return fmt.Errorf("unknown option %q, must be one of: path, domain, max_age, expires, secure, http_only, same_site, partitioned", key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// cookieInt converts a template value to an integer
+func cookieInt(value any) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(v))
+	}
+	return 0, fmt.Errorf("expected a number, got %T", value)
+}
+
+// cookieBool converts a template value to a boolean
+func cookieBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	}
+	return false, fmt.Errorf("expected a boolean, got %T", value)
+}
+
+// cookieTime converts a template value to the time a cookie expires at
+func cookieTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		v = strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 nor an HTTP date", v)
+	}
+	return time.Time{}, fmt.Errorf("expected a time, got %T", value)
+}
+
+// cookieSameSite converts a template value to a SameSite mode
+func cookieSameSite(value any) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(fmt.Sprint(value))) {
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return 0, fmt.Errorf("%q must be one of: lax, strict, none", fmt.Sprint(value))
+}
@@ -0,0 +1,206 @@
+package template
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewTemplateContext_Cookies(t *testing.T) {
+	req := httptest.NewRequest("GET", "/cart", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc123"})
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	req.AddCookie(&http.Cookie{Name: "session", Value: "shadowed"})
+
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	if ctx.Cookies["session"] != "abc123" || ctx.Cookies["theme"] != "dark" || len(ctx.Cookies) != 2 {
+		t.Errorf("Unexpected cookies: %v", ctx.Cookies)
+	}
+
+	ctx, err = NewTemplateContext(httptest.NewRequest("GET", "/cart", nil), nil)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	if ctx.Cookies != nil {
+		t.Errorf("Expected no cookies, got %v", ctx.Cookies)
+	}
+}
+
+func TestResponse_SetCookie(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		options     map[string]any
+		expected    string
+		errContains string
+	}{
+		{name: "plain", value: "abc123", expected: "session=abc123"},
+		{
+			name:     "all options",
+			value:    "abc123",
+			options:  map[string]any{"path": "/", "domain": "example.com", "max_age": 3600, "secure": true, "http_only": "true", "same_site": "Strict"},
+			expected: "session=abc123; Path=/; Domain=example.com; Max-Age=3600; HttpOnly; Secure; SameSite=Strict",
+		},
+		{name: "zero max age deletes", value: "x", options: map[string]any{"max_age": "0"}, expected: "session=x; Max-Age=0"},
+		{
+			name:     "camel case options",
+			value:    "abc123",
+			options:  map[string]any{"maxAge": 3600, "httpOnly": true, "sameSite": "lax", "Path": "/"},
+			expected: "session=abc123; Path=/; Max-Age=3600; HttpOnly; SameSite=Lax",
+		},
+		{
+			name:     "expires and partitioned",
+			value:    "abc123",
+			options:  map[string]any{"expires": "2030-01-01T00:00:00Z", "secure": true, "partitioned": true},
+			expected: "session=abc123; Expires=Tue, 01 Jan 2030 00:00:00 GMT; Secure; Partitioned",
+		},
+		{
+			name:     "expires as an HTTP date",
+			value:    "x",
+			options:  map[string]any{"expires": "Tue, 01 Jan 2030 00:00:00 GMT"},
+			expected: "session=x; Expires=Tue, 01 Jan 2030 00:00:00 GMT",
+		},
+		{name: "unknown option", value: "x", options: map[string]any{"lifetime": "1h"}, errContains: `unknown option "lifetime"`},
+		{name: "invalid expires", value: "x", options: map[string]any{"expires": "tomorrow"}, errContains: `"tomorrow" is neither an RFC 3339 nor an HTTP date`},
+		{name: "invalid max age", value: "x", options: map[string]any{"max_age": "soon"}, errContains: "invalid max_age"},
+		{name: "invalid same site", value: "x", options: map[string]any{"same_site": "sometimes"}, errContains: "invalid same_site"},
+		{name: "invalid value", value: "a;b", errContains: `invalid cookie "session"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewResponse()
+
+			var options []map[string]any
+			if tt.options != nil {
+				options = append(options, tt.options)
+			}
+			out, err := resp.SetCookie("session", tt.value, options...)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if out != "" {
+				t.Errorf("SetCookie should return empty string, got %q", out)
+			}
+
+			cookies := resp.Cookies()
+			if len(cookies) != 1 || cookies[0].String() != tt.expected {
+				t.Errorf("Expected cookie %q, got %v", tt.expected, cookies)
+			}
+		})
+	}
+}
+
+func TestResponse_SetCookieReplacesByName(t *testing.T) {
+	resp := NewResponse()
+	_, _ = resp.SetCookie("session", "first")
+	_, _ = resp.SetCookie("theme", "dark")
+	_, _ = resp.SetCookie("session", "second")
+
+	cookies := resp.Cookies()
+	if len(cookies) != 2 || cookies[0].Value != "second" || cookies[1].Name != "theme" {
+		t.Errorf("Unexpected cookies: %v", cookies)
+	}
+
+	var nilResp *Response
+	if got := nilResp.Cookies(); got != nil {
+		t.Errorf("Expected no cookies from a nil Response, got %v", got)
+	}
+}
+
+func TestResponse_CookiesInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("cookies", `{{ if .Cookies.session }}{{ .Response.DeleteCookie "session" (dict "path" "/") }}bye{{ else }}{{ .Response.SetCookie "session" "abc123" (dict "path" "/" "http_only" true) }}hi{{ end }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		session        string
+		expectedBody   string
+		expectedCookie string
+	}{
+		{name: "log in", expectedBody: "hi", expectedCookie: "session=abc123; Path=/; HttpOnly"},
+		{name: "log out", session: "abc123", expectedBody: "bye", expectedCookie: "session=; Path=/; Max-Age=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/session", nil)
+			if tt.session != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.session})
+			}
+			ctx, err := NewTemplateContext(req, nil)
+			if err != nil {
+				t.Fatalf("Failed to build context: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+			if buf.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, buf.String())
+			}
+
+			cookies := ctx.Response.Cookies()
+			if len(cookies) != 1 || cookies[0].String() != tt.expectedCookie {
+				t.Errorf("Expected cookie %q, got %v", tt.expectedCookie, cookies)
+			}
+		})
+	}
+}
+
+func TestSetCookieFunctions(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("cookies", `{{ setCookie "session" "abc123" (dict "maxAge" 3600 "httpOnly" true) }}{{ deleteCookie "theme" }}ok`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if buf.String() != "ok" {
+		t.Errorf("Expected body %q, got %q", "ok", buf.String())
+	}
+
+	var got []string
+	for _, cookie := range ctx.Response.Cookies() {
+		got = append(got, cookie.String())
+	}
+	expected := []string{"session=abc123; Max-Age=3600; HttpOnly", "theme=; Max-Age=0"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected cookies %q, got %q", expected, got)
+	}
+
+	// Without a response to set them on, the functions only check the cookie
+	if _, err := setCookie("session", "a;b"); err == nil {
+		t.Error("Expected an invalid cookie value to fail")
+	}
+	if out, err := deleteCookie("session", map[string]any{"path": "/"}); out != "" || err != nil {
+		t.Errorf("Expected an empty result, got %q, %v", out, err)
+	}
+}
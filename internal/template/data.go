@@ -0,0 +1,40 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// dataFile loads a JSON or YAML data file, such as the dataset served by a
+// list endpoint. The file is read on every call, so edits show up right away.
+// Usage in templates: {{ $users := dataFile "data/users.json" }}
+func dataFile(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	var value any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &value)
+	default:
+		err = json.Unmarshal(data, &value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid data file %q: %w", path, err)
+	}
+	return value, nil
+}
+
+// deniedDataFile stands in for dataFile in templates that can't read local
+// files, see Engine.WithoutLocalFiles
+func deniedDataFile(string) (any, error) {
+	return nil, errors.New("dataFile can't read local files from templates of remote files")
+}
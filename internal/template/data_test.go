@@ -0,0 +1,80 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDataFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.json":   `[{"name": "Ada"}, {"name": "Grace"}]`,
+		"users.yaml":   "- name: Ada\n- name: Grace\n",
+		"invalid.json": `[{"name": `,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"users.json", "users.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := dataFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			users, ok := data.([]any)
+			if !ok || len(users) != 2 || users[1].(map[string]any)["name"] != "Grace" {
+				t.Errorf("Unexpected data: %#v", data)
+			}
+		})
+	}
+
+	if _, err := dataFile(filepath.Join(dir, "invalid.json")); err == nil {
+		t.Error("Expected an error for an invalid data file")
+	}
+	if _, err := dataFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing data file")
+	}
+}
+
+func TestEngine_WithoutLocalFiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(file, []byte(`["Ada"]`), 0o644); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+
+	engine := NewEngine()
+	if err := engine.AddPartial("users", `{{ index (dataFile .) 0 }}`); err != nil {
+		t.Fatalf("Failed to add partial: %v", err)
+	}
+	restricted := engine.WithoutLocalFiles()
+
+	templates := map[string]string{
+		"direct call":     `{{ index (dataFile "` + file + `") 0 }}`,
+		"through partial": `{{ template "users" "` + file + `" }}`,
+	}
+	for name, content := range templates {
+		t.Run(name, func(t *testing.T) {
+			render := func(engine *Engine) (string, error) {
+				tmpl, err := engine.CompileInlineTemplate("remote", content)
+				if err != nil {
+					t.Fatalf("Failed to compile template: %v", err)
+				}
+				var buf strings.Builder
+				err = engine.ExecuteTemplate(tmpl, &buf, &TemplateContext{})
+				return buf.String(), err
+			}
+
+			if got, err := render(restricted); err == nil || !strings.Contains(err.Error(), "can't read local files") || strings.Contains(got, "Ada") {
+				t.Errorf("Expected dataFile to fail, got %q, %v", got, err)
+			}
+			if got, err := render(engine); err != nil || got != "Ada" {
+				t.Errorf("Expected the original engine to keep reading files, got %q, %v", got, err)
+			}
+		})
+	}
+}
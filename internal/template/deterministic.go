@@ -0,0 +1,129 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math/rand/v2"
+	"text/template"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// Alphabets of sprig's random string functions
+const (
+	alphaChars    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numericChars  = "0123456789"
+	alphaNumChars = alphaChars + numericChars
+)
+
+// Seed makes the random functions of templates rendered with the context
+// return the same values for the same request, so snapshot tests against the
+// mock don't flake. The seed is derived from key, which identifies the route,
+// and the request's method, path, query and body, ignoring headers. Request
+// IDs that weren't sent by the client are derived from it too.
+func (ctx *TemplateContext) Seed(key string) {
+	seed := hashStrings(key, ctx.Request.Method, ctx.Request.URL.Path, ctx.Query.Encode(), ctx.RawBody)
+	ctx.seed = &seed
+
+	if ctx.Request.Header.Get(requestIDHeader) == "" {
+		var b [16]byte
+		newSeededFaker(seed, "request-id").fillBytes(b[:])
+		ctx.RequestID = hex.EncodeToString(b[:])
+	}
+}
+
+// hashStrings returns a 64-bit hash of parts, keeping their boundaries
+func hashStrings(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// newSeededFaker returns a faker seeded from seed and name, so each template
+// of a request gets its own sequence of values, whatever order they run in
+func newSeededFaker(seed uint64, name string) faker {
+	return faker{gofakeit.NewFaker(rand.NewPCG(seed, hashStrings(name)), false)}
+}
+
+// seededTemplate returns a copy of tmpl whose random functions are seeded
+// from seed, and whose now is frozen to clock
+func seededTemplate(tmpl *template.Template, seed uint64, clock Clock) (*template.Template, error) {
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy template: %w", err)
+	}
+
+	f := newSeededFaker(seed, tmpl.Name())
+	funcs := f.funcMap()
+	maps.Copy(funcs, template.FuncMap{
+		// Sprig's random functions
+		"randInt":      f.randInt,
+		"randAlpha":    func(count int) string { return f.randString(count, alphaChars) },
+		"randNumeric":  func(count int) string { return f.randString(count, numericChars) },
+		"randAlphaNum": func(count int) string { return f.randString(count, alphaNumChars) },
+		"randAscii":    f.randAscii,
+		"randBytes":    f.randBytes,
+		"uuidv4":       f.UUID,
+		"shuffle":      f.shuffle,
+	})
+	maps.Copy(funcs, clock.funcMap(f)) // Functions reading the current time
+	return clone.Funcs(funcs), nil
+}
+
+// randInt returns a random integer in [min, max), like sprig's randInt
+func (f faker) randInt(min, max int) int {
+	return f.IntN(max-min) + min
+}
+
+// randString returns count random characters from alphabet
+func (f faker) randString(count int, alphabet string) string {
+	b := make([]byte, max(count, 0))
+	for i := range b {
+		b[i] = alphabet[f.IntN(len(alphabet))]
+	}
+	return string(b)
+}
+
+// randAscii returns count random printable ASCII characters, like sprig's
+// randAscii
+func (f faker) randAscii(count int) string {
+	b := make([]byte, max(count, 0))
+	for i := range b {
+		b[i] = byte(f.IntRange(32, 126))
+	}
+	return string(b)
+}
+
+// randBytes returns count random bytes encoded in base64, like sprig's
+// randBytes
+func (f faker) randBytes(count int) (string, error) {
+	if count < 0 {
+		return "", fmt.Errorf("byte count %d cannot be negative", count)
+	}
+	b := make([]byte, count)
+	f.fillBytes(b)
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// fillBytes fills b with random bytes
+func (f faker) fillBytes(b []byte) {
+	for i := range b {
+		b[i] = byte(f.IntN(256))
+	}
+}
+
+// shuffle returns the characters of s in random order, like sprig's shuffle
+func (f faker) shuffle(s string) string {
+	runes := []rune(s)
+	for i := len(runes) - 1; i > 0; i-- {
+		j := f.IntN(i + 1)
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
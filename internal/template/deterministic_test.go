@@ -0,0 +1,124 @@
+package template
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+// renderSeeded renders content for a request, seeded with key like a
+// deterministic route
+func renderSeeded(t *testing.T, content, key, target, body string) (string, *TemplateContext) {
+	t.Helper()
+
+	engine := NewEngine()
+	tmpl, err := engine.CompileInlineTemplate("body", content)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", target, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	ctx.Clock = NewClockAt(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 0)
+	ctx.Seed(key)
+
+	var buf strings.Builder
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	return buf.String(), ctx
+}
+
+func TestTemplateContext_Seed(t *testing.T) {
+	content := `{{ fakeName }}|{{ fakeEmail }}|{{ randInt 0 1000000 }}|{{ randFloat 0 1 }}|{{ randChoice "a" "b" "c" "d" }}|` +
+		`{{ randAlphaNum 12 }}|{{ randBytes 8 }}|{{ uuidv4 }}|{{ fakeUUID }}|{{ shuffle "abcdefgh" }}|{{ fakeK8sPodName }}|` +
+		`{{ now | unixEpoch }}|{{ fakeFuture | unixEpoch }}`
+
+	first, ctx := renderSeeded(t, content, "GET /users", "/users?page=1&size=10", `{"q": 1}`)
+	second, _ := renderSeeded(t, content, "GET /users", "/users?size=10&page=1", `{"q": 1}`)
+	if first != second {
+		t.Errorf("Expected the same request to render the same output, got:\n%s\n%s", first, second)
+	}
+	if !strings.Contains(first, "|1704067200|") {
+		t.Errorf("Expected now to be frozen to the clock, got %s", first)
+	}
+	if len(ctx.RequestID) != 32 {
+		t.Errorf("Expected a generated request ID of 32 hex characters, got %q", ctx.RequestID)
+	}
+
+	for name, other := range map[string]func() (string, *TemplateContext){
+		"route": func() (string, *TemplateContext) {
+			return renderSeeded(t, content, "GET /orders", "/users?page=1&size=10", `{"q": 1}`)
+		},
+		"path": func() (string, *TemplateContext) {
+			return renderSeeded(t, content, "GET /users", "/users/1?page=1&size=10", `{"q": 1}`)
+		},
+		"query": func() (string, *TemplateContext) {
+			return renderSeeded(t, content, "GET /users", "/users?page=2&size=10", `{"q": 1}`)
+		},
+		"body": func() (string, *TemplateContext) {
+			return renderSeeded(t, content, "GET /users", "/users?page=1&size=10", `{"q": 2}`)
+		},
+	} {
+		if got, otherCtx := other(); got == first || otherCtx.RequestID == ctx.RequestID {
+			t.Errorf("Expected a different %s to render different values, got the same", name)
+		}
+	}
+}
+
+func TestTemplateContext_SeedKeepsClientRequestID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/users", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("X-Request-ID", "client-id")
+
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	ctx.Seed("GET /users")
+	if ctx.RequestID != "client-id" {
+		t.Errorf("Expected the client's request ID to be kept, got %q", ctx.RequestID)
+	}
+}
+
+func TestSeededTemplate_IndependentOfOrder(t *testing.T) {
+	engine := NewEngine()
+	first, _ := engine.CompileInlineTemplate("first", "{{ fakeName }} {{ randInt 0 1000000 }}")
+	second, _ := engine.CompileInlineTemplate("second", "{{ fakeName }} {{ randInt 0 1000000 }}")
+
+	req, _ := http.NewRequest("GET", "/users", nil)
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	ctx.Seed("GET /users")
+
+	render := func(tmpl *template.Template) string {
+		var buf strings.Builder
+		if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+		return buf.String()
+	}
+
+	// Response headers render in random order, so each template's values
+	// mustn't depend on the ones rendered before it
+	a1, b1 := render(first), render(second)
+	b2, a2 := render(second), render(first)
+	if a1 != a2 || b1 != b2 {
+		t.Errorf("Expected each template to render the same values in any order, got %q/%q and %q/%q", a1, b1, a2, b2)
+	}
+	if a1 == b1 {
+		t.Errorf("Expected different templates to get their own values, both rendered %q", a1)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"maps"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"text/template"
 
@@ -16,6 +17,7 @@ type Engine struct {
 	funcMap        template.FuncMap
 	leftDelimiter  string
 	rightDelimiter string
+	partials       *template.Template // Named templates every compiled template can include, nil when none
 }
 
 // NewEngine creates a new template engine with all available functions and default delimiters
@@ -33,6 +35,16 @@ func NewEngineWithDelimiters(leftDelim, rightDelim string) *Engine {
 	return engine
 }
 
+// WithoutLocalFiles returns a copy of the engine whose templates can't read
+// local files, for templates of files the user doesn't control, like remote
+// includes. Calling dataFile from them fails, including through partials.
+func (e *Engine) WithoutLocalFiles() *Engine {
+	restricted := *e
+	restricted.funcMap = e.GetFuncMap()
+	restricted.funcMap["dataFile"] = deniedDataFile
+	return &restricted
+}
+
 // createFuncMap builds the complete function map combining Sprig functions with custom ones
 func createFuncMap() template.FuncMap {
 	// Start with sprig functions (provides 100+ utility functions)
@@ -42,165 +54,233 @@ func createFuncMap() template.FuncMap {
 	customFuncs := template.FuncMap{
 		"trimPrefix":   trimPrefix,
 		"sleep":        sleep,
-		"randFloat":    randFloat,
-		"randChoice":   randChoice,
 		"toJsonPretty": toJsonPretty,
+		"bodyString":   bodyString,
+
+		// XML documents
+		"fromXml": fromXml,
+		"xmlPath": xmlPath,
+		"toXml":   toXml,
+
+		// Raw bytes, for binary bodies
+		"hexdec":   hexdec,
+		"hexenc":   hexenc,
+		"rawBytes": rawBytes,
+
+		// Data files and list query options
+		"dataFile":   dataFile,
+		"parseQuery": parseQuery,
+		"applyQuery": applyQuery,
+
+		// Loops knowing where they are, for separating JSON array items
+		"loop": loop,
+
+		// RFC 7807 error documents
+		"problemJSON": problemJSON,
+
+		// Cookies set on the response
+		"setCookie":    setCookie,
+		"deleteCookie": deleteCookie,
+
+		// Structured log entries tagged with the request
+		"logEvent": logEvent,
+
+		// HTML printed as-is on routes escaping HTML
+		"safeHTML": safeHTML,
+	}
+
+	// Merge custom functions into the sprig function map
+	maps.Copy(funcMap, customFuncs)
+	maps.Copy(funcMap, defaultFaker.funcMap()) // Random values and fake data
+
+	return funcMap
+}
+
+// funcMap returns the random template functions, generating values with f
+func (f faker) funcMap() template.FuncMap {
+	return template.FuncMap{
+		"randFloat":  f.randFloat,
+		"randChoice": f.randChoice,
 
 		// Basic personal information
-		"fakeName":           fakeName,
-		"fakeFirstName":      fakeFirstName,
-		"fakeLastName":       fakeLastName,
-		"fakeEmail":          fakeEmail,
-		"fakePhone":          fakePhone,
-		"fakePhoneFormatted": fakePhoneFormatted,
+		"fakeName":           f.fakeName,
+		"fakeFirstName":      f.fakeFirstName,
+		"fakeLastName":       f.fakeLastName,
+		"fakeEmail":          f.fakeEmail,
+		"fakePhone":          f.fakePhone,
+		"fakePhoneFormatted": f.fakePhoneFormatted,
 
 		// Business and company data
-		"fakeBS":            fakeBS,
-		"fakeCompany":       fakeCompany,
-		"fakeCompanySuffix": fakeCompanySuffix,
-		"fakeJobTitle":      fakeJobTitle,
-		"fakeJobDescriptor": fakeJobDescriptor,
-		"fakeJobLevel":      fakeJobLevel,
+		"fakeBS":            f.fakeBS,
+		"fakeCompany":       f.fakeCompany,
+		"fakeCompanySuffix": f.fakeCompanySuffix,
+		"fakeJobTitle":      f.fakeJobTitle,
+		"fakeJobDescriptor": f.fakeJobDescriptor,
+		"fakeJobLevel":      f.fakeJobLevel,
 
 		// Financial data
-		"fakeCreditCardNumber": fakeCreditCardNumber,
-		"fakeCreditCardType":   fakeCreditCardType,
-		"fakeCurrency":         fakeCurrency,
-		"fakeCurrencyLong":     fakeCurrencyLong,
-		"fakeCurrencyAbbrv":    fakeCurrencyAbbrv,
-		"fakeCurrencyName":     fakeCurrencyName,
-		"fakePrice":            fakePrice,
+		"fakeCreditCardNumber": f.fakeCreditCardNumber,
+		"fakeCreditCardType":   f.fakeCreditCardType,
+		"fakeCurrency":         f.fakeCurrency,
+		"fakeCurrencyLong":     f.fakeCurrencyLong,
+		"fakeCurrencyAbbrv":    f.fakeCurrencyAbbrv,
+		"fakeCurrencyName":     f.fakeCurrencyName,
+		"fakePrice":            f.fakePrice,
 
 		// Colors
-		"fakeColor":     fakeColor,
-		"fakeHexColor":  fakeHexColor,
-		"fakeRGBColor":  fakeRGBColor,
-		"fakeSafeColor": fakeSafeColor,
+		"fakeColor":     f.fakeColor,
+		"fakeHexColor":  f.fakeHexColor,
+		"fakeRGBColor":  f.fakeRGBColor,
+		"fakeSafeColor": f.fakeSafeColor,
 
 		// Product data
-		"fakeProduct":            fakeProduct,
-		"fakeProductName":        fakeProductName,
-		"fakeProductDescription": fakeProductDescription,
-		"fakeProductCategory":    fakeProductCategory,
-		"fakeProductFeature":     fakeProductFeature,
-		"fakeProductMaterial":    fakeProductMaterial,
+		"fakeProduct":            f.fakeProduct,
+		"fakeProductName":        f.fakeProductName,
+		"fakeProductDescription": f.fakeProductDescription,
+		"fakeProductCategory":    f.fakeProductCategory,
+		"fakeProductFeature":     f.fakeProductFeature,
+		"fakeProductMaterial":    f.fakeProductMaterial,
 
 		// Person details
-		"fakeGender": fakeGender,
-		"fakeSSN":    fakeSSN,
-		"fakeHobby":  fakeHobby,
+		"fakeGender": f.fakeGender,
+		"fakeSSN":    f.fakeSSN,
+		"fakeHobby":  f.fakeHobby,
 
 		// Authentication data
-		"fakeUsername": fakeUsername,
-		"fakePassword": fakePassword,
+		"fakeUsername": f.fakeUsername,
+		"fakePassword": f.fakePassword,
 
 		// Address information
-		"fakeAddress":      fakeAddress,
-		"fakeStreet":       fakeStreet,
-		"fakeStreetName":   fakeStreetName,
-		"fakeStreetNumber": fakeStreetNumber,
-		"fakeCity":         fakeCity,
-		"fakeState":        fakeState,
-		"fakeStateAbbrv":   fakeStateAbbrv,
-		"fakeZip":          fakeZip,
-		"fakeCountry":      fakeCountry,
-		"fakeCountryAbbrv": fakeCountryAbbrv,
-		"fakeLatitude":     fakeLatitude,
-		"fakeLongitude":    fakeLongitude,
+		"fakeAddress":      f.fakeAddress,
+		"fakeStreet":       f.fakeStreet,
+		"fakeStreetName":   f.fakeStreetName,
+		"fakeStreetNumber": f.fakeStreetNumber,
+		"fakeCity":         f.fakeCity,
+		"fakeState":        f.fakeState,
+		"fakeStateAbbrv":   f.fakeStateAbbrv,
+		"fakeZip":          f.fakeZip,
+		"fakeCountry":      f.fakeCountry,
+		"fakeCountryAbbrv": f.fakeCountryAbbrv,
+		"fakeLatitude":     f.fakeLatitude,
+		"fakeLongitude":    f.fakeLongitude,
+
+		// Geo and locale data
+		"fakeCoordinatesNear": f.fakeCoordinatesNear,
+		"fakeCountryCode":     f.fakeCountryCode,
+		"fakeTimezoneFor":     f.fakeTimezoneFor,
+		"fakeLocale":          f.fakeLocale,
 
 		// Words and text
-		"fakeWord":                fakeWord,
-		"fakeWords":               fakeWords,
-		"fakeSentence":            fakeSentence,
-		"fakeParagraph":           fakeParagraph,
-		"fakeLoremIpsumWord":      fakeLoremIpsumWord,
-		"fakeLoremIpsumSentence":  fakeLoremIpsumSentence,
-		"fakeLoremIpsumParagraph": fakeLoremIpsumParagraph,
+		"fakeWord":                f.fakeWord,
+		"fakeWords":               f.fakeWords,
+		"fakeSentence":            f.fakeSentence,
+		"fakeParagraph":           f.fakeParagraph,
+		"fakeLoremIpsumWord":      f.fakeLoremIpsumWord,
+		"fakeLoremIpsumSentence":  f.fakeLoremIpsumSentence,
+		"fakeLoremIpsumParagraph": f.fakeLoremIpsumParagraph,
 
 		// Food
-		"fakeFood":      fakeFood,
-		"fakeFruit":     fakeFruit,
-		"fakeVegetable": fakeVegetable,
-		"fakeBreakfast": fakeBreakfast,
-		"fakeLunch":     fakeLunch,
-		"fakeDinner":    fakeDinner,
-		"fakeSnack":     fakeSnack,
-		"fakeDessert":   fakeDessert,
+		"fakeFood":      f.fakeFood,
+		"fakeFruit":     f.fakeFruit,
+		"fakeVegetable": f.fakeVegetable,
+		"fakeBreakfast": f.fakeBreakfast,
+		"fakeLunch":     f.fakeLunch,
+		"fakeDinner":    f.fakeDinner,
+		"fakeSnack":     f.fakeSnack,
+		"fakeDessert":   f.fakeDessert,
 
 		// Miscellaneous
-		"fakeFlipACoin":  fakeFlipACoin,
-		"fakeRandomBool": fakeRandomBool,
-		"fakeUUID":       fakeUUID,
+		"fakeFlipACoin":  f.fakeFlipACoin,
+		"fakeRandomBool": f.fakeRandomBool,
+		"fakeUUID":       f.fakeUUID,
+
+		// Values derived from a key, the same on every call
+		"fakeConsistent": fakeConsistent,
+
+		// Images and files, as raw bytes
+		"fakeImagePNG":  f.fakeImagePNG,
+		"fakeImagePng":  f.fakeImagePNG,
+		"fakeImageJPEG": f.fakeImageJPEG,
+		"fakeImageJpeg": f.fakeImageJPEG,
+		"fakeCSV":       f.fakeCSV,
+		"fakePDF":       f.fakePDF,
+
+		// Whole JSON documents and arrays, from a schema
+		"fakeJSON": f.fakeJSON,
+		"fakeList": f.fakeList,
 
 		// Internet values
-		"fakeURL":          fakeURL,
-		"fakeDomainName":   fakeDomainName,
-		"fakeDomainSuffix": fakeDomainSuffix,
-		"fakeIPv4Address":  fakeIPv4Address,
-		"fakeIPv6Address":  fakeIPv6Address,
-		"fakeMacAddress":   fakeMacAddress,
-		"fakeHTTPMethod":   fakeHTTPMethod,
-		"fakeUserAgent":    fakeUserAgent,
+		"fakeURL":          f.fakeURL,
+		"fakeDomainName":   f.fakeDomainName,
+		"fakeDomainSuffix": f.fakeDomainSuffix,
+		"fakeIPv4Address":  f.fakeIPv4Address,
+		"fakeIPv6Address":  f.fakeIPv6Address,
+		"fakeMacAddress":   f.fakeMacAddress,
+		"fakeHTTPMethod":   f.fakeHTTPMethod,
+		"fakeUserAgent":    f.fakeUserAgent,
+
+		// Network and infrastructure
+		"fakeCIDR":       f.fakeCIDR,
+		"fakeIPv6CIDR":   f.fakeIPv6CIDR,
+		"fakePort":       f.fakePort,
+		"fakeHostname":   f.fakeHostname,
+		"fakeK8sPodName": f.fakeK8sPodName,
+		"fakeSemver":     f.fakeSemver,
 
 		// Date and Time
-		"fakeDate":           fakeDate,
-		"fakeDateRange":      fakeDateRange,
-		"fakeFuture":         fakeFuture,
-		"fakePast":           fakePast,
-		"fakeWeekday":        fakeWeekday,
-		"fakeMonth":          fakeMonth,
-		"fakeMonthString":    fakeMonthString,
-		"fakeYear":           fakeYear,
-		"fakeHour":           fakeHour,
-		"fakeMinute":         fakeMinute,
-		"fakeSecond":         fakeSecond,
-		"fakeNanoSecond":     fakeNanoSecond,
-		"fakeTimeZone":       fakeTimeZone,
-		"fakeTimeZoneAbbrv":  fakeTimeZoneAbbrv,
-		"fakeTimeZoneFull":   fakeTimeZoneFull,
-		"fakeTimeZoneOffset": fakeTimeZoneOffset,
+		"fakeDate":           f.fakeDate,
+		"fakeDateRange":      f.fakeDateRange,
+		"fakeFuture":         f.fakeFuture,
+		"fakePast":           f.fakePast,
+		"fakeWeekday":        f.fakeWeekday,
+		"fakeMonth":          f.fakeMonth,
+		"fakeMonthString":    f.fakeMonthString,
+		"fakeYear":           f.fakeYear,
+		"fakeHour":           f.fakeHour,
+		"fakeMinute":         f.fakeMinute,
+		"fakeSecond":         f.fakeSecond,
+		"fakeNanoSecond":     f.fakeNanoSecond,
+		"fakeTimeZone":       f.fakeTimeZone,
+		"fakeTimeZoneAbbrv":  f.fakeTimeZoneAbbrv,
+		"fakeTimeZoneFull":   f.fakeTimeZoneFull,
+		"fakeTimeZoneOffset": f.fakeTimeZoneOffset,
 
 		// Payment information
-		"fakeCreditCard":        fakeCreditCard,
-		"fakeAchRouting":        fakeAchRouting,
-		"fakeAchAccount":        fakeAchAccount,
-		"fakeBitcoinAddress":    fakeBitcoinAddress,
-		"fakeBitcoinPrivateKey": fakeBitcoinPrivateKey,
+		"fakeCreditCard":        f.fakeCreditCard,
+		"fakeAchRouting":        f.fakeAchRouting,
+		"fakeAchAccount":        f.fakeAchAccount,
+		"fakeBitcoinAddress":    f.fakeBitcoinAddress,
+		"fakeBitcoinPrivateKey": f.fakeBitcoinPrivateKey,
 
 		// Animals
-		"fakeAnimal":     fakeAnimal,
-		"fakeAnimalType": fakeAnimalType,
-		"fakeFarmAnimal": fakeFarmAnimal,
-		"fakeCat":        fakeCat,
-		"fakeDog":        fakeDog,
-		"fakeBird":       fakeBird,
+		"fakeAnimal":     f.fakeAnimal,
+		"fakeAnimalType": f.fakeAnimalType,
+		"fakeFarmAnimal": f.fakeFarmAnimal,
+		"fakeCat":        f.fakeCat,
+		"fakeDog":        f.fakeDog,
+		"fakeBird":       f.fakeBird,
 
 		// Language
-		"fakeLanguage":            fakeLanguage,
-		"fakeLanguageAbbrv":       fakeLanguageAbbrv,
-		"fakeProgrammingLanguage": fakeProgrammingLanguage,
+		"fakeLanguage":            f.fakeLanguage,
+		"fakeLanguageAbbrv":       f.fakeLanguageAbbrv,
+		"fakeProgrammingLanguage": f.fakeProgrammingLanguage,
 
 		// Celebrities
-		"fakeCelebrityActor":    fakeCelebrityActor,
-		"fakeCelebrityBusiness": fakeCelebrityBusiness,
-		"fakeCelebritySport":    fakeCelebritySport,
+		"fakeCelebrityActor":    f.fakeCelebrityActor,
+		"fakeCelebrityBusiness": f.fakeCelebrityBusiness,
+		"fakeCelebritySport":    f.fakeCelebritySport,
 
 		// Books, Movies, and Songs
-		"fakeBook":       fakeBook,
-		"fakeBookTitle":  fakeBookTitle,
-		"fakeBookAuthor": fakeBookAuthor,
-		"fakeBookGenre":  fakeBookGenre,
-		"fakeMovie":      fakeMovie,
-		"fakeMovieName":  fakeMovieName,
-		"fakeMovieGenre": fakeMovieGenre,
-		"fakeSong":       fakeSong,
-		"fakeMusicGenre": fakeMusicGenre,
+		"fakeBook":       f.fakeBook,
+		"fakeBookTitle":  f.fakeBookTitle,
+		"fakeBookAuthor": f.fakeBookAuthor,
+		"fakeBookGenre":  f.fakeBookGenre,
+		"fakeMovie":      f.fakeMovie,
+		"fakeMovieName":  f.fakeMovieName,
+		"fakeMovieGenre": f.fakeMovieGenre,
+		"fakeSong":       f.fakeSong,
+		"fakeMusicGenre": f.fakeMusicGenre,
 	}
-
-	// Merge custom functions into the sprig function map
-	maps.Copy(funcMap, customFuncs)
-
-	return funcMap
 }
 
 // CompileInlineTemplate compiles an inline template string with the engine's function map
@@ -213,8 +293,11 @@ func (e *Engine) CompileInlineTemplate(name, content string) (*template.Template
 		return nil, NewCompilationError("inline", "template content cannot be empty", nil)
 	}
 
-	tmpl, err := template.New(name).Delims(e.leftDelimiter, e.rightDelimiter).Funcs(e.funcMap).Parse(content)
+	tmpl, err := e.newTemplate(name)
 	if err != nil {
+		return nil, NewCompilationError("inline", err.Error(), err)
+	}
+	if tmpl, err = tmpl.Parse(content); err != nil {
 		return nil, NewCompilationError("inline", fmt.Sprintf("failed to parse template: %v", err), err)
 	}
 
@@ -227,8 +310,12 @@ func (e *Engine) CompileFileTemplate(filename string) (*template.Template, error
 		return nil, NewCompilationError(filename, "filename cannot be empty", nil)
 	}
 
-	tmpl, err := template.New("").Delims(e.leftDelimiter, e.rightDelimiter).Funcs(e.funcMap).ParseFiles(filename)
+	// Name the template after the file, so it's the one ParseFiles fills in and Execute runs
+	tmpl, err := e.newTemplate(filepath.Base(filename))
 	if err != nil {
+		return nil, NewCompilationError(filename, err.Error(), err)
+	}
+	if tmpl, err = tmpl.ParseFiles(filename); err != nil {
 		return nil, NewCompilationError(filename, fmt.Sprintf("failed to parse template file: %v", err), err)
 	}
 
@@ -249,9 +336,32 @@ func (e *Engine) ExecuteTemplate(tmpl *template.Template, w io.Writer, ctx *Temp
 		return NewExecutionError(tmpl.Name(), "context is nil", nil)
 	}
 
-	// Execute the template
-	err := tmpl.Execute(w, ctx)
+	// Seed the random functions of deterministic routes
+	if ctx.seed != nil {
+		seeded, err := seededTemplate(tmpl, *ctx.seed, ctx.Clock)
+		if err != nil {
+			return NewExecutionError(tmpl.Name(), err.Error(), err)
+		}
+		tmpl = seeded
+	} else {
+		// Tell the time from the mock's clock when it's skewed or set
+		clocked, err := clockedTemplate(tmpl, ctx.Clock)
+		if err != nil {
+			return NewExecutionError(tmpl.Name(), err.Error(), err)
+		}
+		tmpl = clocked
+	}
+
+	// Let problemJSON and the cookie functions change the response, and
+	// logEvent log with the request's logger
+	bound, err := renderTemplate(tmpl, ctx)
 	if err != nil {
+		return NewExecutionError(tmpl.Name(), err.Error(), err)
+	}
+	tmpl = bound
+
+	// Execute the template
+	if err := tmpl.Execute(w, ctx); err != nil {
 		return NewExecutionError(tmpl.Name(), fmt.Sprintf("template execution failed: %v", err), err)
 	}
 
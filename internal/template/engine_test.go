@@ -208,6 +208,26 @@ func TestEngine_CompileFileTemplate(t *testing.T) {
 	}
 }
 
+func TestEngine_CompileFileTemplate_Execute(t *testing.T) {
+	engine := NewEngine()
+
+	tmpFile := createTempFile(t, `Hello {{ upper "file" }}`)
+	defer removeFile(tmpFile)
+
+	tmpl, err := engine.CompileFileTemplate(tmpFile)
+	if err != nil {
+		t.Fatalf("CompileFileTemplate() unexpected error: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := engine.ExecuteTemplate(tmpl, &buf, &TemplateContext{}); err != nil {
+		t.Fatalf("ExecuteTemplate() unexpected error: %v", err)
+	}
+	if buf.String() != "Hello FILE" {
+		t.Errorf("ExecuteTemplate() = %q, want %q", buf.String(), "Hello FILE")
+	}
+}
+
 func TestEngine_CompileFileTemplate_ErrorCases(t *testing.T) {
 	engine := NewEngine()
 
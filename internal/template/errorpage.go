@@ -0,0 +1,29 @@
+package template
+
+import "net/http"
+
+// ErrorInfo describes the built-in error an error page replaces
+type ErrorInfo struct {
+	Status  int    `json:"status"`  // Status of the built-in error, like 500
+	Code    string `json:"code"`    // Stable machine-readable error code, like "template_error"
+	Detail  string `json:"detail"`  // Explanation sent with the built-in error
+	Message string `json:"message"` // The underlying error, when there's one
+}
+
+// NewErrorContext creates the TemplateContext error pages are rendered with.
+// The request body isn't read, as it may have been consumed already or be
+// the cause of the error.
+func NewErrorContext(req *http.Request, info ErrorInfo) *TemplateContext {
+	return &TemplateContext{
+		Request:   req,
+		Headers:   req.Header,
+		Query:     req.URL.Query(),
+		Params:    make(map[string]string),
+		Cookies:   requestCookies(req),
+		Response:  NewResponse(),
+		RequestID: requestID(req),
+		Vars:      make(map[string]string),
+		TLS:       NewTLSInfo(req.TLS),
+		Error:     &info,
+	}
+}
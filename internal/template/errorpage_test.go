@@ -0,0 +1,38 @@
+package template
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewErrorContext(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/orders?page=2", strings.NewReader(`{"id": 1}`))
+	req.Header.Set("X-Request-ID", "req-1")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+	ctx := NewErrorContext(req, ErrorInfo{Status: http.StatusInternalServerError, Code: "template_error", Detail: "broken", Message: "boom"})
+
+	if ctx.Body != nil || ctx.RawBody != "" {
+		t.Errorf("Expected the body to be left unread, got %v", ctx.Body)
+	}
+	if ctx.RequestID != "req-1" || ctx.Query.Get("page") != "2" || ctx.Cookies["session"] != "abc" {
+		t.Errorf("Expected the request's ID, query and cookies, got %q, %v, %v", ctx.RequestID, ctx.Query, ctx.Cookies)
+	}
+
+	engine := NewEngine()
+	tmpl, err := engine.CompileInlineTemplate("error_page", `{{ .Error.Status }} {{ .Error.Code }} {{ .Error.Message }} {{ .Request.Method }} {{ .Request.URL.Path }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if got, want := buf.String(), "500 template_error boom POST /orders"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,82 @@
+package template
+
+import (
+	"fmt"
+	"text/template"
+	"text/template/parse"
+)
+
+// safeHTML marks a value as HTML to print as-is on routes escaping HTML. It
+// returns the value unchanged, so it does nothing elsewhere.
+// Usage in templates: {{ .Query.banner | safeHTML }}
+func safeHTML(value interface{}) interface{} {
+	return value
+}
+
+// EscapeHTML returns a copy of tmpl whose actions HTML-escape what they
+// print, as if each was piped to html, for routes serving HTML pages.
+// Actions ending in safeHTML or html are left alone. The templates tmpl
+// defines or includes, like partials, are escaped in the copy too, while tmpl
+// and the templates it was compiled from are left unchanged.
+func EscapeHTML(tmpl *template.Template) (*template.Template, error) {
+	escaped, err := tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy template: %w", err)
+	}
+
+	// Clones share their parse trees, so the escaped ones are copies
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		tree := t.Tree.Copy()
+		escapeNode(tree, tree.Root)
+		if _, err := escaped.AddParseTree(t.Name(), tree); err != nil {
+			return nil, fmt.Errorf("failed to escape template %q: %w", t.Name(), err)
+		}
+	}
+	return escaped, nil
+}
+
+// escapeNode pipes the output of the actions under node to html
+func escapeNode(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeNode(tree, child)
+		}
+	case *parse.ActionNode:
+		escapeAction(tree, n)
+	case *parse.IfNode:
+		escapeNode(tree, n.List)
+		escapeNode(tree, n.ElseList)
+	case *parse.RangeNode:
+		escapeNode(tree, n.List)
+		escapeNode(tree, n.ElseList)
+	case *parse.WithNode:
+		escapeNode(tree, n.List)
+		escapeNode(tree, n.ElseList)
+	}
+}
+
+// escapeAction pipes the output of an action to html, unless it prints
+// nothing or already ends in safeHTML or html
+func escapeAction(tree *parse.Tree, n *parse.ActionNode) {
+	if n.Pipe == nil || len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) == 0 {
+		return
+	}
+
+	last := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+	if ident, ok := last.Args[0].(*parse.IdentifierNode); ok && (ident.Ident == "safeHTML" || ident.Ident == "html") {
+		return
+	}
+
+	// Copy the last command so the new one belongs to the tree, which
+	// execution errors are reported against
+	cmd := last.Copy().(*parse.CommandNode)
+	cmd.Args = []parse.Node{parse.NewIdentifier("html").SetTree(tree).SetPos(cmd.Pos)}
+	n.Pipe.Cmds = append(n.Pipe.Cmds, cmd)
+}
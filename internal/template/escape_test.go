@@ -0,0 +1,55 @@
+package template
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestEscapeHTML(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.AddPartial("greeting", `<p>Hello, {{ . }}!</p>`); err != nil {
+		t.Fatalf("Failed to add partial: %v", err)
+	}
+
+	tmpl, err := engine.CompileInlineTemplate("page", `{{ $name := index .Query.name 0 }}<h1>{{ $name }}</h1>`+
+		`{{ if $name }}{{ template "greeting" $name }}{{ end }}`+
+		`{{ range list "<b>" }}<li>{{ . }}</li>{{ end }}`+
+		`{{ "<i>trusted</i>" | safeHTML }}{{ html "<u>" }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	escaped, err := EscapeHTML(tmpl)
+	if err != nil {
+		t.Fatalf("Failed to escape template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tmpl     *template.Template
+		expected string
+	}{
+		{name: "escaped", tmpl: escaped, expected: `<h1>&lt;script&gt;</h1><p>Hello, &lt;script&gt;!</p><li>&lt;b&gt;</li><i>trusted</i>&lt;u&gt;`},
+		{name: "original", tmpl: tmpl, expected: `<h1><script></h1><p>Hello, <script>!</p><li><b></li><i>trusted</i>&lt;u&gt;`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/?name=%3Cscript%3E", nil)
+			ctx, err := NewTemplateContext(req, nil)
+			if err != nil {
+				t.Fatalf("Failed to create context: %v", err)
+			}
+
+			var buf strings.Builder
+			if err := engine.ExecuteTemplate(tt.tmpl, &buf, ctx); err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// logEvent checks an event can be logged, without logging it. Templates call
+// it as logEvent, which stands for TemplateContext.LogEvent while a response
+// is rendered, so test frameworks reading the mock's logs can assert on
+// domain events instead of parsing response bodies.
+// Usage in templates: {{ logEvent "payment_declined" (dict "order" .Params.id) }}
+func logEvent(name string, fields ...map[string]any) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("logEvent: event name cannot be empty")
+	}
+	return "", nil
+}
+
+// LogEvent logs the event name as a structured entry tagged with the request,
+// with the fields as its attributes, sorted by name. Events are dropped when
+// the context has no Logger.
+func (c *TemplateContext) LogEvent(name string, fields ...map[string]any) (string, error) {
+	if _, err := logEvent(name, fields...); err != nil {
+		return "", err
+	}
+	if c.Logger == nil {
+		return "", nil
+	}
+
+	merged := make(map[string]any)
+	for _, f := range fields {
+		maps.Copy(merged, f)
+	}
+	attrs := make([]any, 0, len(merged))
+	for _, key := range slices.Sorted(maps.Keys(merged)) {
+		attrs = append(attrs, slog.Any(key, merged[key]))
+	}
+
+	var method, path string
+	if c.Request != nil {
+		method, path = c.Request.Method, c.Request.URL.Path
+	}
+
+	c.Logger.LogAttrs(context.Background(), slog.LevelInfo, "template event",
+		slog.String("event", name),
+		slog.String("request_id", c.RequestID),
+		slog.String("method", method),
+		slog.String("path", path),
+		slog.String("route", c.Route.Pattern),
+		slog.Group("fields", attrs...),
+	)
+	return "", nil
+}
@@ -0,0 +1,84 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogEvent(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("events", `{{ logEvent "payment_declined" (dict "order" .Params.id "amount" 42) }}declined`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	req, _ := http.NewRequest("POST", "/orders/7/pay", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	ctx, err := NewTemplateContext(req, map[string]string{"id": "7"})
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+	ctx.Route = RouteInfo{Pattern: "/orders/{id}/pay", Method: "POST"}
+
+	var logs bytes.Buffer
+	ctx.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+
+	var buf bytes.Buffer
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if buf.String() != "declined" {
+		t.Errorf("Expected body %q, got %q", "declined", buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON log entry, got %q: %v", logs.String(), err)
+	}
+	expected := map[string]any{
+		"msg":        "template event",
+		"event":      "payment_declined",
+		"request_id": "req-1",
+		"method":     "POST",
+		"path":       "/orders/7/pay",
+		"route":      "/orders/{id}/pay",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, entry[key])
+		}
+	}
+	fields, _ := entry["fields"].(map[string]any)
+	if fields["order"] != "7" || fields["amount"] != float64(42) {
+		t.Errorf("Expected the event's fields, got %v", entry["fields"])
+	}
+}
+
+func TestLogEvent_WithoutLogger(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("events", `{{ logEvent "seen" }}ok`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil || buf.String() != "ok" {
+		t.Errorf("Expected events to be dropped without a logger, got %q, %v", buf.String(), err)
+	}
+
+	if _, err := logEvent(""); err == nil || !strings.Contains(err.Error(), "event name cannot be empty") {
+		t.Errorf("Expected an empty name to fail, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// fakeConsistent returns the value of a fake data function derived from key
+// instead of at random, so the same key always gets the same value, across
+// requests, routes and restarts. name is the function, with or without its
+// "fake" prefix, and args are passed on to it.
+// Usage in templates: {{ fakeConsistent "name" .Params.id }} or
+// {{ fakeConsistent "fakePrice" .Params.sku 10.0 100.0 }}
+func fakeConsistent(name string, key interface{}, args ...interface{}) (interface{}, error) {
+	fn := consistentFuncName(name)
+	if fn == "fakeConsistent" {
+		return nil, fmt.Errorf("fakeConsistent cannot call itself")
+	}
+
+	// Seeding from the key alone, with the function name as the stream, gives
+	// each function of a key its own value
+	f := newSeededFaker(hashStrings(fmt.Sprint(key)), fn)
+	generator, ok := f.funcMap()[fn]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake data function %q", name)
+	}
+
+	return callGenerator(fn, reflect.ValueOf(generator), args)
+}
+
+// consistentFuncName returns the name of the fake data function name refers
+// to, adding the "fake" prefix when it's left out
+func consistentFuncName(name string) string {
+	if strings.HasPrefix(name, "fake") {
+		return name
+	}
+
+	r, size := utf8.DecodeRuneInString(name)
+	return "fake" + string(unicode.ToUpper(r)) + name[size:]
+}
+
+// callGenerator calls the fake data function fn with the arguments given in
+// a template, converting them to the types it takes, like numbers given as
+// integers to functions taking floats
+func callGenerator(name string, fn reflect.Value, args []interface{}) (interface{}, error) {
+	typ := fn.Type()
+	fixed := typ.NumIn()
+	if typ.IsVariadic() {
+		fixed--
+	}
+	switch {
+	case typ.IsVariadic() && len(args) < fixed:
+		return nil, fmt.Errorf("%s takes at least %d arguments, got %d", name, fixed, len(args))
+	case !typ.IsVariadic() && len(args) != fixed:
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", name, fixed, len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		want := typ.In(min(i, typ.NumIn()-1))
+		if i >= fixed {
+			want = want.Elem()
+		}
+
+		v := reflect.ValueOf(arg)
+		if !v.IsValid() || !v.Type().ConvertibleTo(want) || (v.Kind() == reflect.String) != (want.Kind() == reflect.String) {
+			return nil, fmt.Errorf("%s argument %d: expected %s, got %T", name, i+1, want, arg)
+		}
+		in[i] = v.Convert(want)
+	}
+
+	out := fn.Call(in)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
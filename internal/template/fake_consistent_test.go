@@ -0,0 +1,113 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFakeConsistent(t *testing.T) {
+	name, err := fakeConsistent("name", "123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for range 20 {
+		if again, _ := fakeConsistent("name", "123"); again != name {
+			t.Fatalf("Expected the same name for the same key, got %q and %q", name, again)
+		}
+	}
+	if prefixed, _ := fakeConsistent("fakeName", "123"); prefixed != name {
+		t.Errorf("Expected the fake prefix to be optional, got %q and %q", name, prefixed)
+	}
+	if number, _ := fakeConsistent("name", 123); number != name {
+		t.Errorf("Expected keys to be compared as text, got %q and %q", name, number)
+	}
+
+	others := make(map[interface{}]bool)
+	for _, key := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		v, _ := fakeConsistent("name", key)
+		others[v] = true
+	}
+	if len(others) < 2 {
+		t.Errorf("Expected different keys to get different names, got %v", others)
+	}
+
+	price, err := fakeConsistent("price", "sku-1", 10, 20.5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p, ok := price.(float64); !ok || p < 10 || p > 20.5 {
+		t.Errorf("Expected a price between 10 and 20.5, got %v", price)
+	}
+
+	csv, err := fakeConsistent("CSV", "export", 2, "name", "email")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.(string)), "\n"); len(lines) != 3 {
+		t.Errorf("Expected a header and 2 rows, got %q", csv)
+	}
+}
+
+func TestFakeConsistentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   string
+		args []interface{}
+		want string
+	}{
+		{name: "unknown function", fn: "nope", want: `unknown fake data function "nope"`},
+		{name: "itself", fn: "consistent", want: "cannot call itself"},
+		{name: "missing arguments", fn: "price", args: []interface{}{1.0}, want: "fakePrice takes 2 arguments, got 1"},
+		{name: "extra arguments", fn: "name", args: []interface{}{1}, want: "fakeName takes 0 arguments, got 1"},
+		{name: "missing variadic arguments", fn: "CSV", want: "fakeCSV takes at least 1 arguments, got 0"},
+		{name: "wrong type", fn: "price", args: []interface{}{"ten", 20.0}, want: "fakePrice argument 1: expected float64, got string"},
+		{name: "generator error", fn: "CIDR", args: []interface{}{40}, want: "prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fakeConsistent(tt.fn, "key", tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFakeConsistentInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("consistent", `{{ fakeConsistent "name" .Params.id }}|{{ fakeConsistent "email" .Params.id }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	render := func(id string) string {
+		var buf strings.Builder
+		if err := engine.ExecuteTemplate(tmpl, &buf, &TemplateContext{Params: map[string]string{"id": id}}); err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+		return buf.String()
+	}
+
+	first := render("123")
+	if !strings.Contains(first, "@") {
+		t.Errorf("Expected a name and an email, got %q", first)
+	}
+	if again := render("123"); again != first {
+		t.Errorf("Expected the same values for the same id, got %q and %q", first, again)
+	}
+
+	// Seeded templates of deterministic routes get the same values
+	seeded, err := seededTemplate(tmpl, 42, NewClock(0))
+	if err != nil {
+		t.Fatalf("Failed to seed template: %v", err)
+	}
+	var buf strings.Builder
+	if err := engine.ExecuteTemplate(seeded, &buf, &TemplateContext{Params: map[string]string{"id": "123"}}); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if buf.String() != first {
+		t.Errorf("Expected seeded templates to get the same values, got %q and %q", first, buf.String())
+	}
+}
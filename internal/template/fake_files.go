@@ -0,0 +1,138 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// The functions below generate files for mocked download endpoints, returned
+// as raw bytes in a string, like the functions of binary.go.
+
+// Limits on generated files, so a template can't make the server allocate
+// gigabytes
+const (
+	maxFakeImageSide = 4096
+	maxFakeCSVRows   = 10000
+	maxFakePDFPages  = 100
+)
+
+// defaultCSVColumns are the columns of fakeCSV when none are given
+var defaultCSVColumns = []string{"id", "name", "email", "company", "city"}
+
+// fakeImagePNG returns a PNG image of random pixels
+// Usage in templates: {{ fakeImagePNG 100 100 }} or {{ fakeImagePNG 100 100 | b64enc }}
+func (f faker) fakeImagePNG(width, height int) (string, error) {
+	if err := checkImageSize(width, height); err != nil {
+		return "", err
+	}
+	return string(f.ImagePng(width, height)), nil
+}
+
+// fakeImageJPEG returns a JPEG image of random pixels
+// Usage in templates: {{ fakeImageJPEG 640 480 }} or {{ fakeImageJPEG 640 480 | b64enc }}
+func (f faker) fakeImageJPEG(width, height int) (string, error) {
+	if err := checkImageSize(width, height); err != nil {
+		return "", err
+	}
+	return string(f.ImageJpeg(width, height)), nil
+}
+
+// checkImageSize verifies that the sides of a fake image are positive and
+// within maxFakeImageSide
+func checkImageSize(width, height int) error {
+	if width <= 0 || height <= 0 || width > maxFakeImageSide || height > maxFakeImageSide {
+		return fmt.Errorf("image size %dx%d must be between 1x1 and %dx%d", width, height, maxFakeImageSide, maxFakeImageSide)
+	}
+	return nil
+}
+
+// fakeCSV returns a CSV document with a header and rows of fake data. Columns
+// are named after gofakeit's generators, like "name", "email" or "city", and
+// "id" numbers the rows; without columns, id, name, email, company and city
+// are used.
+// Usage in templates: {{ fakeCSV 10 }} or {{ fakeCSV 100 "id" "firstname" "lastname" "email" }}
+func (f faker) fakeCSV(rows int, columns ...string) (string, error) {
+	if rows <= 0 || rows > maxFakeCSVRows {
+		return "", fmt.Errorf("fakeCSV: row count %d must be between 1 and %d", rows, maxFakeCSVRows)
+	}
+	if len(columns) == 0 {
+		columns = defaultCSVColumns
+	}
+
+	fields := make([]gofakeit.Field, len(columns))
+	for i, column := range columns {
+		function := column
+		if column == "id" {
+			function = "autoincrement"
+		} else if gofakeit.GetFuncLookup(column) == nil {
+			return "", fmt.Errorf("fakeCSV: unknown column %q, columns must be named after gofakeit generators like \"name\" or \"email\"", column)
+		}
+		fields[i] = gofakeit.Field{Name: column, Function: function}
+	}
+
+	data, err := f.CSV(&gofakeit.CSVOptions{RowCount: rows, Fields: fields})
+	if err != nil {
+		return "", fmt.Errorf("fakeCSV: %w", err)
+	}
+	return string(data), nil
+}
+
+// fakePDF returns a PDF document with pages of fake text, one page unless a
+// count is given. The document is minimal but valid, enough for viewers and
+// clients checking what they downloaded.
+// Usage in templates: {{ fakePDF }} or {{ fakePDF 3 }}
+func (f faker) fakePDF(pages ...int) (string, error) {
+	count := 1
+	if len(pages) > 0 {
+		count = pages[0]
+	}
+	if count <= 0 || count > maxFakePDFPages {
+		return "", fmt.Errorf("fakePDF: page count %d must be between 1 and %d", count, maxFakePDFPages)
+	}
+
+	// Objects are numbered from 1: the catalog, the page tree, the font,
+	// then a page and its contents for each page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // The page tree, once the pages are known
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	kids := make([]string, count)
+	for i := range count {
+		pageID, contentsID := len(objects)+1, len(objects)+2
+		kids[i] = fmt.Sprintf("%d 0 R", pageID)
+
+		stream := fmt.Sprintf("BT /F1 18 Tf 72 720 Td (%s) Tj /F1 12 Tf 0 -28 Td (%s) Tj ET",
+			pdfEscape(f.BookTitle()), pdfEscape(f.Sentence(10)))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contentsID),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), count)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.String(), nil
+}
+
+// pdfEscape escapes text for a PDF string literal
+func pdfEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+}
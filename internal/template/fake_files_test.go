@@ -0,0 +1,116 @@
+package template
+
+import (
+	"bytes"
+	"encoding/csv"
+	"image/jpeg"
+	"image/png"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFakeImages(t *testing.T) {
+	data, err := defaultFaker.fakeImagePNG(3, 2)
+	if err != nil {
+		t.Fatalf("fakeImagePNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatalf("Expected a PNG image, got %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 3 || size.Y != 2 {
+		t.Errorf("Expected a 3x2 image, got %v", size)
+	}
+
+	data, err = defaultFaker.fakeImageJPEG(4, 4)
+	if err != nil {
+		t.Fatalf("fakeImageJPEG() error = %v", err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader([]byte(data))); err != nil {
+		t.Errorf("Expected a JPEG image, got %v", err)
+	}
+
+	for _, size := range [][2]int{{0, 10}, {10, -1}, {5000, 1}} {
+		if _, err := defaultFaker.fakeImagePNG(size[0], size[1]); err == nil || !strings.Contains(err.Error(), "must be between") {
+			t.Errorf("Expected a size error for %dx%d, got %v", size[0], size[1], err)
+		}
+	}
+}
+
+func TestFakeCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        int
+		columns     []string
+		header      []string
+		errContains string
+	}{
+		{name: "default columns", rows: 3, header: defaultCSVColumns},
+		{name: "chosen columns", rows: 2, columns: []string{"id", "firstname", "uuid"}, header: []string{"id", "firstname", "uuid"}},
+		{name: "unknown column", rows: 1, columns: []string{"nope"}, errContains: `unknown column "nope"`},
+		{name: "no rows", rows: 0, errContains: "row count 0 must be between 1 and"},
+		{name: "too many rows", rows: maxFakeCSVRows + 1, errContains: "must be between 1 and"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := defaultFaker.fakeCSV(tt.rows, tt.columns...)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+			if err != nil {
+				t.Fatalf("Expected valid CSV, got %v:\n%s", err, data)
+			}
+			if len(records) != tt.rows+1 {
+				t.Fatalf("Expected a header and %d rows, got %d records", tt.rows, len(records))
+			}
+			if !reflect.DeepEqual(records[0], tt.header) {
+				t.Errorf("Expected header %v, got %v", tt.header, records[0])
+			}
+			if tt.header[0] == "id" && records[tt.rows][0] != strconv.Itoa(tt.rows) {
+				t.Errorf("Expected ids to number the rows, got %q on row %d", records[tt.rows][0], tt.rows)
+			}
+		})
+	}
+}
+
+func TestFakePDF(t *testing.T) {
+	data, err := defaultFaker.fakePDF(3)
+	if err != nil {
+		t.Fatalf("fakePDF() error = %v", err)
+	}
+
+	if !strings.HasPrefix(data, "%PDF-1.4\n") || !strings.HasSuffix(data, "%%EOF\n") {
+		t.Fatalf("Expected a PDF header and trailer, got:\n%s", data)
+	}
+	if !strings.Contains(data, "/Count 3") || strings.Count(data, "/Type /Page ") != 3 {
+		t.Errorf("Expected three pages, got:\n%s", data)
+	}
+
+	// Every object starts at the offset its cross-reference entry gives
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(data, -1)
+	if len(offsets) != 9 {
+		t.Fatalf("Expected 9 cross-reference entries, got %d", len(offsets))
+	}
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(offset[1])
+		if want := strconv.Itoa(i+1) + " 0 obj"; !strings.HasPrefix(data[at:], want) {
+			t.Errorf("Expected %q at offset %d, got %q", want, at, data[at:min(at+10, len(data))])
+		}
+	}
+
+	if _, err := defaultFaker.fakePDF(0); err == nil {
+		t.Error("Expected an error for zero pages")
+	}
+}
@@ -14,7 +14,7 @@ func TestFakeFunctions(t *testing.T) {
 	}{
 		{
 			name:     "fakeName returns non-empty string",
-			funcCall: func() interface{} { return fakeName() },
+			funcCall: func() interface{} { return defaultFaker.fakeName() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) > 0
@@ -22,7 +22,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeEmail returns valid email format",
-			funcCall: func() interface{} { return fakeEmail() },
+			funcCall: func() interface{} { return defaultFaker.fakeEmail() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && strings.Contains(s, "@") && strings.Contains(s, ".")
@@ -30,7 +30,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakePhone returns non-empty string",
-			funcCall: func() interface{} { return fakePhone() },
+			funcCall: func() interface{} { return defaultFaker.fakePhone() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) > 0
@@ -38,7 +38,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeCompany returns non-empty string",
-			funcCall: func() interface{} { return fakeCompany() },
+			funcCall: func() interface{} { return defaultFaker.fakeCompany() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) > 0
@@ -46,7 +46,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeJobTitle returns non-empty string",
-			funcCall: func() interface{} { return fakeJobTitle() },
+			funcCall: func() interface{} { return defaultFaker.fakeJobTitle() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) > 0
@@ -54,7 +54,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeCreditCardNumber returns non-empty string",
-			funcCall: func() interface{} { return fakeCreditCardNumber() },
+			funcCall: func() interface{} { return defaultFaker.fakeCreditCardNumber() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) > 0
@@ -62,7 +62,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeColor returns non-empty string",
-			funcCall: func() interface{} { return fakeColor() },
+			funcCall: func() interface{} { return defaultFaker.fakeColor() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) > 0
@@ -70,7 +70,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeUUID returns valid UUID format",
-			funcCall: func() interface{} { return fakeUUID() },
+			funcCall: func() interface{} { return defaultFaker.fakeUUID() },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(s) == 36 && strings.Count(s, "-") == 4
@@ -78,7 +78,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeDate returns valid time",
-			funcCall: func() interface{} { return fakeDate() },
+			funcCall: func() interface{} { return defaultFaker.fakeDate() },
 			validator: func(v interface{}) bool {
 				_, ok := v.(time.Time)
 				return ok
@@ -86,7 +86,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeMonth returns valid month number",
-			funcCall: func() interface{} { return fakeMonth() },
+			funcCall: func() interface{} { return defaultFaker.fakeMonth() },
 			validator: func(v interface{}) bool {
 				m, ok := v.(int)
 				return ok && m >= 1 && m <= 12
@@ -94,7 +94,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeYear returns reasonable year",
-			funcCall: func() interface{} { return fakeYear() },
+			funcCall: func() interface{} { return defaultFaker.fakeYear() },
 			validator: func(v interface{}) bool {
 				y, ok := v.(int)
 				return ok && y >= 1900 && y <= 2100
@@ -102,7 +102,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeRandomBool returns boolean",
-			funcCall: func() interface{} { return fakeRandomBool() },
+			funcCall: func() interface{} { return defaultFaker.fakeRandomBool() },
 			validator: func(v interface{}) bool {
 				_, ok := v.(bool)
 				return ok
@@ -110,7 +110,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakeWords generates requested number of words",
-			funcCall: func() interface{} { return fakeWords(3) },
+			funcCall: func() interface{} { return defaultFaker.fakeWords(3) },
 			validator: func(v interface{}) bool {
 				s, ok := v.(string)
 				return ok && len(strings.Fields(s)) == 3
@@ -118,7 +118,7 @@ func TestFakeFunctions(t *testing.T) {
 		},
 		{
 			name:     "fakePrice generates price in range",
-			funcCall: func() interface{} { return fakePrice(10.0, 20.0) },
+			funcCall: func() interface{} { return defaultFaker.fakePrice(10.0, 20.0) },
 			validator: func(v interface{}) bool {
 				p, ok := v.(float64)
 				return ok && p >= 10.0 && p <= 20.0
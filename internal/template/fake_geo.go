@@ -0,0 +1,150 @@
+package template
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the Earth, used to place coordinates
+// a distance away from a point
+const earthRadiusKm = 6371.0
+
+// geoCountry is the locale data bundled for a country, so helpers that take or
+// return a country agree with each other
+type geoCountry struct {
+	Languages []string // Languages spoken, most common first
+	Timezones []string // IANA time zones in use
+}
+
+// geoCountries holds the bundled locale data of each country by its ISO 3166-1
+// alpha-2 code
+var geoCountries = map[string]geoCountry{
+	"AR": {[]string{"es"}, []string{"America/Argentina/Buenos_Aires", "America/Argentina/Cordoba", "America/Argentina/Mendoza"}},
+	"AU": {[]string{"en"}, []string{"Australia/Sydney", "Australia/Melbourne", "Australia/Brisbane", "Australia/Perth", "Australia/Adelaide"}},
+	"BE": {[]string{"nl", "fr", "de"}, []string{"Europe/Brussels"}},
+	"BR": {[]string{"pt"}, []string{"America/Sao_Paulo", "America/Manaus", "America/Fortaleza", "America/Recife"}},
+	"CA": {[]string{"en", "fr"}, []string{"America/Toronto", "America/Vancouver", "America/Edmonton", "America/Winnipeg", "America/Halifax"}},
+	"CH": {[]string{"de", "fr", "it"}, []string{"Europe/Zurich"}},
+	"CL": {[]string{"es"}, []string{"America/Santiago"}},
+	"CN": {[]string{"zh"}, []string{"Asia/Shanghai"}},
+	"CO": {[]string{"es"}, []string{"America/Bogota"}},
+	"DE": {[]string{"de"}, []string{"Europe/Berlin"}},
+	"DK": {[]string{"da"}, []string{"Europe/Copenhagen"}},
+	"EG": {[]string{"ar"}, []string{"Africa/Cairo"}},
+	"ES": {[]string{"es", "ca"}, []string{"Europe/Madrid", "Atlantic/Canary"}},
+	"FI": {[]string{"fi", "sv"}, []string{"Europe/Helsinki"}},
+	"FR": {[]string{"fr"}, []string{"Europe/Paris"}},
+	"GB": {[]string{"en"}, []string{"Europe/London"}},
+	"IE": {[]string{"en", "ga"}, []string{"Europe/Dublin"}},
+	"IN": {[]string{"hi", "en"}, []string{"Asia/Kolkata"}},
+	"IT": {[]string{"it"}, []string{"Europe/Rome"}},
+	"JP": {[]string{"ja"}, []string{"Asia/Tokyo"}},
+	"KR": {[]string{"ko"}, []string{"Asia/Seoul"}},
+	"MX": {[]string{"es"}, []string{"America/Mexico_City", "America/Monterrey", "America/Tijuana", "America/Cancun"}},
+	"NG": {[]string{"en"}, []string{"Africa/Lagos"}},
+	"NL": {[]string{"nl"}, []string{"Europe/Amsterdam"}},
+	"NO": {[]string{"nb"}, []string{"Europe/Oslo"}},
+	"NZ": {[]string{"en", "mi"}, []string{"Pacific/Auckland"}},
+	"PL": {[]string{"pl"}, []string{"Europe/Warsaw"}},
+	"PT": {[]string{"pt"}, []string{"Europe/Lisbon", "Atlantic/Azores"}},
+	"SE": {[]string{"sv"}, []string{"Europe/Stockholm"}},
+	"SG": {[]string{"en", "zh", "ms"}, []string{"Asia/Singapore"}},
+	"TR": {[]string{"tr"}, []string{"Europe/Istanbul"}},
+	"US": {[]string{"en", "es"}, []string{"America/New_York", "America/Chicago", "America/Denver", "America/Phoenix", "America/Los_Angeles", "America/Anchorage", "Pacific/Honolulu"}},
+	"ZA": {[]string{"en", "af", "zu"}, []string{"Africa/Johannesburg"}},
+}
+
+// geoCountryCodes lists the codes in geoCountries, sorted so random picks only
+// depend on the random source
+var geoCountryCodes = func() []string {
+	codes := make([]string, 0, len(geoCountries))
+	for code := range geoCountries {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}()
+
+// Coordinates is a point on the Earth in decimal degrees
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// String formats the coordinates as "latitude,longitude"
+func (c Coordinates) String() string {
+	return fmt.Sprintf("%.6f,%.6f", c.Latitude, c.Longitude)
+}
+
+// fakeCoordinatesNear returns a random point within radius kilometers of the
+// given latitude and longitude, spread evenly over the area of the circle
+// Usage in templates: {{ $p := fakeCoordinatesNear 40.7128 -74.0060 5 }}{{ $p.Latitude }},{{ $p.Longitude }}
+func (f faker) fakeCoordinatesNear(lat, lon, radius interface{}) (Coordinates, error) {
+	latDeg, lonDeg, km := toFloat64(lat), toFloat64(lon), toFloat64(radius)
+	if latDeg < -90 || latDeg > 90 {
+		return Coordinates{}, fmt.Errorf("latitude %v must be between -90 and 90", lat)
+	}
+	if lonDeg < -180 || lonDeg > 180 {
+		return Coordinates{}, fmt.Errorf("longitude %v must be between -180 and 180", lon)
+	}
+	if km < 0 {
+		return Coordinates{}, fmt.Errorf("radius %v cannot be negative", radius)
+	}
+
+	// The square root keeps points from bunching up near the center
+	distance := km * math.Sqrt(f.Float64Range(0, 1)) / earthRadiusKm
+	bearing := f.Float64Range(0, 2*math.Pi)
+
+	lat1 := latDeg * math.Pi / 180
+	lon1 := lonDeg * math.Pi / 180
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(distance) + math.Cos(lat1)*math.Sin(distance)*math.Cos(bearing))
+	lon2 := lon1 + math.Atan2(math.Sin(bearing)*math.Sin(distance)*math.Cos(lat1), math.Cos(distance)-math.Sin(lat1)*math.Sin(lat2))
+
+	// Normalize the longitude back into -180..180 after crossing the antimeridian
+	lon2 = math.Mod(lon2+3*math.Pi, 2*math.Pi) - math.Pi
+
+	return Coordinates{Latitude: lat2 * 180 / math.Pi, Longitude: lon2 * 180 / math.Pi}, nil
+}
+
+// fakeCountryCode returns a random ISO 3166-1 alpha-2 country code, always one
+// that fakeTimezoneFor and fakeLocale know about
+func (f faker) fakeCountryCode() string {
+	return f.RandomString(geoCountryCodes)
+}
+
+// fakeTimezoneFor returns a random IANA time zone in use in the given country
+// Usage in templates: {{ fakeTimezoneFor "US" }} or {{ $c := fakeCountryCode }}{{ fakeTimezoneFor $c }}
+func (f faker) fakeTimezoneFor(country string) (string, error) {
+	data, err := lookupCountry(country)
+	if err != nil {
+		return "", err
+	}
+	return f.RandomString(data.Timezones), nil
+}
+
+// fakeLocale returns a random BCP 47 locale like "pt-BR", for the given country
+// or a random one
+// Usage in templates: {{ fakeLocale }} or {{ fakeLocale "CA" }}
+func (f faker) fakeLocale(country ...string) (string, error) {
+	code := f.fakeCountryCode()
+	if len(country) > 0 {
+		code = strings.ToUpper(strings.TrimSpace(country[0]))
+	}
+
+	data, err := lookupCountry(code)
+	if err != nil {
+		return "", err
+	}
+	return f.RandomString(data.Languages) + "-" + code, nil
+}
+
+// lookupCountry returns the bundled data of a country by its code
+func lookupCountry(country string) (geoCountry, error) {
+	data, ok := geoCountries[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return geoCountry{}, fmt.Errorf("unknown country code %q, expected one of: %s", country, strings.Join(geoCountryCodes, ", "))
+	}
+	return data, nil
+}
@@ -0,0 +1,145 @@
+package template
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// distanceKm returns the great-circle distance between two points
+func distanceKm(a, b Coordinates) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+func TestFakeCoordinatesNear(t *testing.T) {
+	tests := []struct {
+		name   string
+		center Coordinates
+		radius interface{}
+	}{
+		{name: "city", center: Coordinates{Latitude: 40.7128, Longitude: -74.0060}, radius: 5},
+		{name: "float radius", center: Coordinates{Latitude: -33.8688, Longitude: 151.2093}, radius: 0.5},
+		{name: "across the antimeridian", center: Coordinates{Latitude: 0, Longitude: 179.99}, radius: 50},
+		{name: "near a pole", center: Coordinates{Latitude: 89.9, Longitude: 0}, radius: 100},
+		{name: "zero radius", center: Coordinates{Latitude: 10, Longitude: 20}, radius: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				point, err := defaultFaker.fakeCoordinatesNear(tt.center.Latitude, tt.center.Longitude, tt.radius)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+					t.Fatalf("Expected valid coordinates, got %v", point)
+				}
+				if d := distanceKm(tt.center, point); d > toFloat64(tt.radius)+0.001 {
+					t.Fatalf("Expected a point within %vkm, got %v at %.3fkm", tt.radius, point, d)
+				}
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name             string
+		lat, lon, radius interface{}
+	}{
+		{name: "latitude out of range", lat: 91.0, lon: 0, radius: 1},
+		{name: "longitude out of range", lat: 0, lon: -181, radius: 1},
+		{name: "negative radius", lat: 0, lon: 0, radius: -1},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := defaultFaker.fakeCoordinatesNear(tt.lat, tt.lon, tt.radius); err == nil {
+				t.Error("Expected an error but got none")
+			}
+		})
+	}
+}
+
+func TestFakeGeoBundle(t *testing.T) {
+	for code, data := range geoCountries {
+		if len(code) != 2 || strings.ToUpper(code) != code {
+			t.Errorf("Expected an upper case alpha-2 code, got %q", code)
+		}
+		if len(data.Languages) == 0 || len(data.Timezones) == 0 {
+			t.Errorf("Expected %s to have languages and time zones", code)
+		}
+		for _, zone := range data.Timezones {
+			if _, err := time.LoadLocation(zone); err != nil {
+				t.Errorf("Expected %s time zone %q to be valid: %v", code, zone, err)
+			}
+		}
+	}
+}
+
+func TestFakeGeoFunctionsAreConsistent(t *testing.T) {
+	for range 100 {
+		code := defaultFaker.fakeCountryCode()
+
+		zone, err := defaultFaker.fakeTimezoneFor(code)
+		if err != nil {
+			t.Fatalf("fakeTimezoneFor(%q) unexpected error: %v", code, err)
+		}
+		if !slices.Contains(geoCountries[code].Timezones, zone) {
+			t.Errorf("Expected a time zone of %s, got %q", code, zone)
+		}
+
+		locale, err := defaultFaker.fakeLocale(code)
+		if err != nil {
+			t.Fatalf("fakeLocale(%q) unexpected error: %v", code, err)
+		}
+		language, region, _ := strings.Cut(locale, "-")
+		if region != code || !slices.Contains(geoCountries[code].Languages, language) {
+			t.Errorf("Expected a locale of %s, got %q", code, locale)
+		}
+	}
+
+	if locale, err := defaultFaker.fakeLocale(); err != nil || !strings.Contains(locale, "-") {
+		t.Errorf("Expected a random locale, got %q (error: %v)", locale, err)
+	}
+	if zone, err := defaultFaker.fakeTimezoneFor("jp"); err != nil || zone != "Asia/Tokyo" {
+		t.Errorf("Expected lower case codes to be accepted, got %q (error: %v)", zone, err)
+	}
+	if _, err := defaultFaker.fakeTimezoneFor("XX"); err == nil || !strings.Contains(err.Error(), `unknown country code "XX"`) {
+		t.Errorf("Expected an unknown country error, got %v", err)
+	}
+	if _, err := defaultFaker.fakeLocale("XX"); err == nil {
+		t.Error("Expected an error for an unknown country")
+	}
+}
+
+func TestFakeGeoFunctionsInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("geo", `{{ $c := fakeCountryCode }}{{ $p := fakeCoordinatesNear 51.5074 -0.1278 10 }}{{ $c }}|{{ fakeTimezoneFor $c }}|{{ fakeLocale $c }}|{{ $p.Latitude }}|{{ $p }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := engine.ExecuteTemplate(tmpl, &buf, &TemplateContext{}); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	parts := strings.Split(buf.String(), "|")
+	if len(parts) != 5 {
+		t.Fatalf("Expected 5 values, got %q", buf.String())
+	}
+	if !strings.HasSuffix(parts[2], "-"+parts[0]) {
+		t.Errorf("Expected the locale %q to match the country %q", parts[2], parts[0])
+	}
+	if !strings.Contains(parts[4], ",") {
+		t.Errorf("Expected coordinates formatted as latitude,longitude, got %q", parts[4])
+	}
+}
@@ -0,0 +1,173 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// maxFakeJSONItems limits the items of each array fakeJSON generates, so a
+// template can't make the server allocate gigabytes
+const maxFakeJSONItems = 1000
+
+// fakeJSON returns a JSON document of fake data shaped like schema, instead
+// of calling a fake function for every field. Strings in the schema name
+// gofakeit's generators, like "name" or "email", with parameters following a
+// colon, like "price:10:100"; "slice:N:generator" is an array of N values.
+// Dicts are objects, lists are arrays with a value for each of their items,
+// and a list of "slice:N" and a schema is an array of N values of the schema.
+// Numbers, booleans and nil are written as-is.
+// Usage in templates: {{ fakeJSON (dict "name" "name" "email" "email" "tags" "slice:3:word") }}
+func (f faker) fakeJSON(schema interface{}) (string, error) {
+	value, err := f.fakeJSONValue(schema, "$")
+	if err != nil {
+		return "", fmt.Errorf("fakeJSON: %w", err)
+	}
+	return marshalFakeJSON("fakeJSON", value)
+}
+
+// fakeList returns a JSON array of count values of schema, which takes the
+// same values as the schema of fakeJSON: a generator name like "name", or a
+// dict for an array of objects.
+// Usage in templates: {{ fakeList 10 "email" }} or {{ fakeList 10 (dict "id" "uuid" "name" "name") }}
+func (f faker) fakeList(count int, schema interface{}) (string, error) {
+	if count < 0 || count > maxFakeJSONItems {
+		return "", fmt.Errorf("fakeList: length %d must be between 0 and %d", count, maxFakeJSONItems)
+	}
+
+	values, err := f.fakeJSONRepeat(count, schema, "$")
+	if err != nil {
+		return "", fmt.Errorf("fakeList: %w", err)
+	}
+	return marshalFakeJSON("fakeList", values)
+}
+
+// marshalFakeJSON returns value in JSON, naming fn in errors
+func marshalFakeJSON(fn string, value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	return string(data), nil
+}
+
+// fakeJSONValue returns the fake value of a schema at path, which locates it
+// in errors
+func (f faker) fakeJSONValue(schema interface{}, path string) (interface{}, error) {
+	switch s := schema.(type) {
+	case string:
+		return f.fakeJSONGenerate(s, path)
+
+	case map[string]interface{}:
+		// Keys are filled in order, so seeded fakers fill them the same way
+		object := make(map[string]interface{}, len(s))
+		for _, key := range slices.Sorted(maps.Keys(s)) {
+			value, err := f.fakeJSONValue(s[key], path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+		}
+		return object, nil
+
+	case []interface{}:
+		// A list of "slice:N" and a schema repeats the schema
+		if len(s) == 2 {
+			if spec, ok := s[0].(string); ok && strings.HasPrefix(spec, "slice:") {
+				count, item, err := fakeJSONSlice(spec, path)
+				if err != nil {
+					return nil, err
+				}
+				if item == "" {
+					return f.fakeJSONRepeat(count, s[1], path)
+				}
+			}
+		}
+
+		array := make([]interface{}, len(s))
+		for i, item := range s {
+			value, err := f.fakeJSONValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			array[i] = value
+		}
+		return array, nil
+
+	case nil, bool, int, int64, float64:
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("%s: unsupported schema value of type %T", path, schema)
+}
+
+// fakeJSONGenerate returns the value of a generator spec, like "email",
+// "price:10:100" or "slice:5:word"
+func (f faker) fakeJSONGenerate(spec, path string) (interface{}, error) {
+	name, args, _ := strings.Cut(spec, ":")
+
+	if name == "slice" {
+		count, item, err := fakeJSONSlice(spec, path)
+		if err != nil {
+			return nil, err
+		}
+		if item == "" {
+			return nil, fmt.Errorf("%s: %q is missing the generator of its items, like \"slice:5:word\"", path, spec)
+		}
+		return f.fakeJSONRepeat(count, item, path)
+	}
+
+	info := gofakeit.GetFuncLookup(name)
+	if info == nil {
+		return nil, fmt.Errorf("%s: unknown generator %q, generators are named after gofakeit's, like \"name\" or \"email\"", path, name)
+	}
+
+	// Without parameters, generators fall back to their defaults
+	var params *gofakeit.MapParams
+	if args != "" {
+		values := strings.Split(args, ":")
+		if len(values) > len(info.Params) {
+			return nil, fmt.Errorf("%s: generator %q takes %d parameters, got %d", path, name, len(info.Params), len(values))
+		}
+
+		params = gofakeit.NewMapParams()
+		for i, value := range values {
+			params.Add(info.Params[i].Field, value)
+		}
+	}
+
+	value, err := info.Generate(f.Faker, params, info)
+	if err != nil {
+		return nil, fmt.Errorf("%s: generator %q: %w", path, name, err)
+	}
+	return value, nil
+}
+
+// fakeJSONRepeat returns an array of count values of schema
+func (f faker) fakeJSONRepeat(count int, schema interface{}, path string) ([]interface{}, error) {
+	array := make([]interface{}, count)
+	for i := range array {
+		value, err := f.fakeJSONValue(schema, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		array[i] = value
+	}
+	return array, nil
+}
+
+// fakeJSONSlice parses a "slice:N" or "slice:N:item" spec, returning the
+// length of the array and the spec of its items
+func fakeJSONSlice(spec, path string) (int, string, error) {
+	length, item, _ := strings.Cut(strings.TrimPrefix(spec, "slice:"), ":")
+	count, err := strconv.Atoi(length)
+	if err != nil || count < 0 || count > maxFakeJSONItems {
+		return 0, "", fmt.Errorf("%s: slice length %q must be a number between 0 and %d", path, length, maxFakeJSONItems)
+	}
+	return count, item, nil
+}
@@ -0,0 +1,174 @@
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFakeJSON(t *testing.T) {
+	schema := map[string]interface{}{
+		"name":   "name",
+		"email":  "email",
+		"price":  "price:10:20",
+		"tags":   "slice:3:word",
+		"active": true,
+		"count":  7,
+		"owner":  map[string]interface{}{"city": "city"},
+		"pair":   []interface{}{"firstname", "lastname"},
+		"items":  []interface{}{"slice:2", map[string]interface{}{"sku": "uuid", "qty": "number:1:5"}},
+		"none":   "slice:0:word",
+	}
+
+	out, err := defaultFaker.fakeJSON(schema)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Name   string            `json:"name"`
+		Email  string            `json:"email"`
+		Price  float64           `json:"price"`
+		Tags   []string          `json:"tags"`
+		Active bool              `json:"active"`
+		Count  int               `json:"count"`
+		Owner  map[string]string `json:"owner"`
+		Pair   []string          `json:"pair"`
+		Items  []struct {
+			SKU string `json:"sku"`
+			Qty int    `json:"qty"`
+		} `json:"items"`
+		None []string `json:"none"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", out, err)
+	}
+
+	if doc.Name == "" || !strings.Contains(doc.Email, "@") {
+		t.Errorf("Expected a name and an email, got %q and %q", doc.Name, doc.Email)
+	}
+	if doc.Price < 10 || doc.Price > 20 {
+		t.Errorf("Expected a price between 10 and 20, got %v", doc.Price)
+	}
+	if len(doc.Tags) != 3 || doc.Tags[0] == "" {
+		t.Errorf("Expected 3 tags, got %v", doc.Tags)
+	}
+	if !doc.Active || doc.Count != 7 {
+		t.Errorf("Expected literals to be kept, got %v and %v", doc.Active, doc.Count)
+	}
+	if doc.Owner["city"] == "" {
+		t.Errorf("Expected a nested object with a city, got %v", doc.Owner)
+	}
+	if len(doc.Pair) != 2 || doc.Pair[0] == "" || doc.Pair[1] == "" {
+		t.Errorf("Expected a first and last name, got %v", doc.Pair)
+	}
+	if len(doc.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %v", doc.Items)
+	}
+	for _, item := range doc.Items {
+		if len(item.SKU) != 36 || item.Qty < 1 || item.Qty > 5 {
+			t.Errorf("Expected an item with a UUID and a quantity between 1 and 5, got %+v", item)
+		}
+	}
+	if doc.None == nil || len(doc.None) != 0 {
+		t.Errorf("Expected an empty array, got %v", doc.None)
+	}
+}
+
+func TestFakeJSONErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema interface{}
+		want   string
+	}{
+		{name: "unknown generator", schema: map[string]interface{}{"a": "nope"}, want: `$.a: unknown generator "nope"`},
+		{name: "too many parameters", schema: "name:1", want: `generator "name" takes 0 parameters, got 1`},
+		{name: "bad parameter", schema: "number:a:b", want: `$: generator "number"`},
+		{name: "slice without item", schema: "slice:3", want: "missing the generator of its items"},
+		{name: "bad slice length", schema: "slice:x:word", want: `slice length "x" must be a number`},
+		{name: "slice too long", schema: "slice:1001:word", want: "between 0 and 1000"},
+		{name: "nested error", schema: []interface{}{"slice:2", map[string]interface{}{"b": "nope"}}, want: "$[0].b: unknown generator"},
+		{name: "unsupported value", schema: map[string]interface{}{"a": struct{}{}}, want: "$.a: unsupported schema value of type struct {}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := defaultFaker.fakeJSON(tt.schema)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFakeJSONInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("json", `{{ fakeJSON (dict "name" "name" "email" "email" "items" "slice:5:productname") }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	// Seeded templates render the same document every time
+	render := func() string {
+		seeded, err := seededTemplate(tmpl, 42, NewClock(0))
+		if err != nil {
+			t.Fatalf("Failed to seed template: %v", err)
+		}
+		var buf strings.Builder
+		if err := engine.ExecuteTemplate(seeded, &buf, &TemplateContext{}); err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+		return buf.String()
+	}
+
+	first := render()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(first), &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", first, err)
+	}
+	if items, ok := doc["items"].([]interface{}); !ok || len(items) != 5 {
+		t.Errorf("Expected 5 items, got %v", doc["items"])
+	}
+	for range 5 {
+		if again := render(); again != first {
+			t.Fatalf("Expected seeded templates to render the same document, got %q and %q", first, again)
+		}
+	}
+}
+
+func TestFakeList(t *testing.T) {
+	out, err := defaultFaker.fakeList(4, "email")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var emails []string
+	if err := json.Unmarshal([]byte(out), &emails); err != nil {
+		t.Fatalf("Expected a JSON array, got %q: %v", out, err)
+	}
+	if len(emails) != 4 || !strings.Contains(emails[3], "@") {
+		t.Errorf("Expected 4 emails, got %v", emails)
+	}
+
+	out, err = defaultFaker.fakeList(2, map[string]interface{}{"id": "uuid", "name": "name"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var users []map[string]string
+	if err := json.Unmarshal([]byte(out), &users); err != nil {
+		t.Fatalf("Expected a JSON array, got %q: %v", out, err)
+	}
+	if len(users) != 2 || users[1]["id"] == "" || users[1]["name"] == "" {
+		t.Errorf("Expected 2 users, got %v", users)
+	}
+
+	if out, err := defaultFaker.fakeList(0, "email"); err != nil || out != "[]" {
+		t.Errorf("Expected an empty array, got %q (error: %v)", out, err)
+	}
+	if _, err := defaultFaker.fakeList(1001, "email"); err == nil || !strings.Contains(err.Error(), "fakeList: length 1001 must be between 0 and 1000") {
+		t.Errorf("Expected a length error, got %v", err)
+	}
+	if _, err := defaultFaker.fakeList(2, "nope"); err == nil || !strings.Contains(err.Error(), `fakeList: $[0]: unknown generator "nope"`) {
+		t.Errorf("Expected an unknown generator error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package template
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// hostRoles are the names hostnames and pod names are built from, so generated
+// infrastructure looks like the services it stands in for
+var hostRoles = []string{
+	"api", "web", "db", "cache", "worker", "proxy", "gateway", "queue",
+	"auth", "ingest", "search", "scheduler", "metrics", "storage",
+}
+
+// podNameAlphabet is the alphabet Kubernetes uses for the random parts of
+// generated names, which avoids vowels and easily confused characters
+const podNameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+// fakeCIDR returns a random IPv4 network in CIDR notation, with a random prefix
+// length between 8 and 30 unless one is given
+// Usage in templates: {{ fakeCIDR }} or {{ fakeCIDR 24 }}
+func (f faker) fakeCIDR(prefix ...int) (string, error) {
+	return f.fakeNetwork(f.IPv4Address(), 32, 8, 30, prefix)
+}
+
+// fakeIPv6CIDR returns a random IPv6 network in CIDR notation, with a random
+// prefix length between 32 and 64 unless one is given
+// Usage in templates: {{ fakeIPv6CIDR }} or {{ fakeIPv6CIDR 48 }}
+func (f faker) fakeIPv6CIDR(prefix ...int) (string, error) {
+	return f.fakeNetwork(f.IPv6Address(), 128, 32, 64, prefix)
+}
+
+// fakeNetwork masks address into a network of the given prefix length, or of a
+// random length between min and max when none is given
+func (f faker) fakeNetwork(address string, bits, min, max int, prefix []int) (string, error) {
+	ones := f.IntRange(min, max)
+	if len(prefix) > 0 {
+		ones = prefix[0]
+		if ones < 0 || ones > bits {
+			return "", fmt.Errorf("prefix length %d must be between 0 and %d", ones, bits)
+		}
+	}
+
+	ip := net.ParseIP(address)
+	if bits == 32 {
+		ip = ip.To4()
+	}
+	network := net.IPNet{IP: ip.Mask(net.CIDRMask(ones, bits)), Mask: net.CIDRMask(ones, bits)}
+	return network.String(), nil
+}
+
+// fakePort returns a random port outside the well-known range, between 1024
+// and 65535
+func (f faker) fakePort() int { return f.IntRange(1024, 65535) }
+
+// fakeHostname returns a random hostname for a server, like "api-07.example.com"
+func (f faker) fakeHostname() string {
+	return fmt.Sprintf("%s-%02d.%s", f.RandomString(hostRoles), f.IntRange(1, 99), f.DomainName())
+}
+
+// fakeK8sPodName returns a random name for a pod managed by a Kubernetes
+// deployment, like "api-7d9f8b6c5d-x2k4q", named after the given deployment
+// or a random one
+// Usage in templates: {{ fakeK8sPodName }} or {{ fakeK8sPodName "checkout" }}
+func (f faker) fakeK8sPodName(deployment ...string) string {
+	name := f.RandomString(hostRoles)
+	if len(deployment) > 0 && deployment[0] != "" {
+		name = deployment[0]
+	}
+	return name + "-" + f.randomPodSuffix(10) + "-" + f.randomPodSuffix(5)
+}
+
+// randomPodSuffix returns n random characters from the Kubernetes name alphabet
+func (f faker) randomPodSuffix(n int) string {
+	var sb strings.Builder
+	for range n {
+		sb.WriteByte(podNameAlphabet[f.IntRange(0, len(podNameAlphabet)-1)])
+	}
+	return sb.String()
+}
+
+// fakeSemver returns a random semantic version, like "2.14.3"
+func (f faker) fakeSemver() string {
+	return fmt.Sprintf("%d.%d.%d", f.IntRange(0, 9), f.IntRange(0, 20), f.IntRange(0, 30))
+}
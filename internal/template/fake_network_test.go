@@ -0,0 +1,117 @@
+package template
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFakeCIDR(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(...int) (string, error)
+		prefix  []int
+		minOnes int
+		maxOnes int
+		bits    int
+	}{
+		{name: "IPv4 random prefix", fn: defaultFaker.fakeCIDR, minOnes: 8, maxOnes: 30, bits: 32},
+		{name: "IPv4 fixed prefix", fn: defaultFaker.fakeCIDR, prefix: []int{24}, minOnes: 24, maxOnes: 24, bits: 32},
+		{name: "IPv6 random prefix", fn: defaultFaker.fakeIPv6CIDR, minOnes: 32, maxOnes: 64, bits: 128},
+		{name: "IPv6 fixed prefix", fn: defaultFaker.fakeIPv6CIDR, prefix: []int{48}, minOnes: 48, maxOnes: 48, bits: 128},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 50 {
+				cidr, err := tt.fn(tt.prefix...)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				ip, network, err := net.ParseCIDR(cidr)
+				if err != nil {
+					t.Fatalf("Expected a valid CIDR, got %q: %v", cidr, err)
+				}
+				if !ip.Equal(network.IP) {
+					t.Errorf("Expected %q to be a network address", cidr)
+				}
+
+				ones, bits := network.Mask.Size()
+				if bits != tt.bits || ones < tt.minOnes || ones > tt.maxOnes {
+					t.Errorf("Expected a /%d-/%d prefix of %d bits, got %q", tt.minOnes, tt.maxOnes, tt.bits, cidr)
+				}
+			}
+		})
+	}
+
+	if _, err := defaultFaker.fakeCIDR(33); err == nil {
+		t.Error("Expected an error for an IPv4 prefix longer than 32 bits")
+	}
+	if _, err := defaultFaker.fakeIPv6CIDR(-1); err == nil {
+		t.Error("Expected an error for a negative prefix")
+	}
+}
+
+func TestFakeNetworkFunctions(t *testing.T) {
+	podName := regexp.MustCompile(`^[a-z0-9-]+-[` + podNameAlphabet + `]{10}-[` + podNameAlphabet + `]{5}$`)
+	semver := regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	hostname := regexp.MustCompile(`^[a-z]+-\d{2}\.\S+\.\S+$`)
+
+	for range 50 {
+		if port := defaultFaker.fakePort(); port < 1024 || port > 65535 {
+			t.Errorf("Expected a port between 1024 and 65535, got %d", port)
+		}
+		if name := defaultFaker.fakeHostname(); !hostname.MatchString(name) {
+			t.Errorf("Expected a hostname like api-07.example.com, got %q", name)
+		}
+		if name := defaultFaker.fakeK8sPodName(); !podName.MatchString(name) {
+			t.Errorf("Expected a pod name like api-7d9f8b6c5d-x2k4q, got %q", name)
+		}
+		if version := defaultFaker.fakeSemver(); !semver.MatchString(version) {
+			t.Errorf("Expected a semantic version, got %q", version)
+		}
+	}
+
+	if name := defaultFaker.fakeK8sPodName("checkout"); !strings.HasPrefix(name, "checkout-") || !podName.MatchString(name) {
+		t.Errorf("Expected a pod name for the checkout deployment, got %q", name)
+	}
+}
+
+func TestFakeNetworkFunctionsInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("network", `{{ fakeCIDR 16 }}|{{ fakePort }}|{{ fakeK8sPodName "api" }}|{{ fakeSemver }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := engine.ExecuteTemplate(tmpl, &buf, &TemplateContext{}); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	parts := strings.Split(buf.String(), "|")
+	if len(parts) != 4 {
+		t.Fatalf("Expected 4 values, got %q", buf.String())
+	}
+	if !strings.HasSuffix(parts[0], "/16") {
+		t.Errorf("Expected a /16 network, got %q", parts[0])
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		t.Errorf("Expected a numeric port, got %q", parts[1])
+	}
+	if !strings.HasPrefix(parts[2], "api-") {
+		t.Errorf("Expected a pod of the api deployment, got %q", parts[2])
+	}
+
+	bad, err := engine.CompileInlineTemplate("bad", `{{ fakeCIDR 40 }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+	if err := engine.ExecuteTemplate(bad, &buf, &TemplateContext{}); err == nil {
+		t.Error("Expected an error for an invalid prefix length")
+	}
+}
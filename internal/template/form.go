@@ -0,0 +1,109 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+)
+
+// FileInfo describes a file uploaded in a multipart/form-data request
+type FileInfo struct {
+	Filename    string `json:"filename"`     // Name of the file as sent by the client
+	Size        int64  `json:"size"`         // Size of the file, in bytes
+	ContentType string `json:"content_type"` // Content type of the file part, if sent
+}
+
+// Files contains the files uploaded in a multipart/form-data request, keyed
+// by form field. Usage in templates: {{ (.Files.Get "avatar").Filename }}
+type Files map[string][]FileInfo
+
+// Get returns the first file uploaded in the field, or an empty FileInfo
+// when there's none
+func (f Files) Get(field string) FileInfo {
+	if files := f[field]; len(files) > 0 {
+		return files[0]
+	}
+	return FileInfo{}
+}
+
+// Has checks if at least one file was uploaded in the field
+func (f Files) Has(field string) bool {
+	return len(f[field]) > 0
+}
+
+// parseForm parses the form fields and uploaded files of a request body, for
+// application/x-www-form-urlencoded and multipart/form-data content types.
+// Other bodies return no fields nor files.
+func parseForm(contentType string, body []byte) (url.Values, Files, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || len(body) == 0 {
+		return nil, nil, nil
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		return form, nil, err
+	case "multipart/form-data":
+		return parseMultipartForm(body, params["boundary"])
+	}
+	return nil, nil, nil
+}
+
+// parseMultipartForm parses a multipart/form-data body, keeping the values
+// of fields and the descriptions of files
+func parseMultipartForm(body []byte, boundary string) (url.Values, Files, error) {
+	if boundary == "" {
+		return nil, nil, errors.New("multipart/form-data body without a boundary")
+	}
+
+	// The multipart reader stops quietly at the end of truncated bodies
+	if !bytes.Contains(body, []byte("--"+boundary+"--")) {
+		return nil, nil, errors.New("multipart/form-data body without a closing boundary")
+	}
+
+	form := url.Values{}
+	files := Files{}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		field := part.FormName()
+		if field == "" {
+			continue
+		}
+
+		if filename := part.FileName(); filename != "" {
+			size, err := io.Copy(io.Discard, part)
+			if err != nil {
+				return nil, nil, err
+			}
+			files[field] = append(files[field], FileInfo{
+				Filename:    filename,
+				Size:        size,
+				ContentType: part.Header.Get("Content-Type"),
+			})
+			continue
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		form.Add(field, string(value))
+	}
+
+	if len(files) == 0 {
+		files = nil
+	}
+	return form, files, nil
+}
@@ -0,0 +1,150 @@
+package template
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// multipartBody builds a multipart/form-data body with a text field and two
+// files in the same field, returning it along with its content type
+func multipartBody(t *testing.T) ([]byte, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.WriteField("title", "Holiday"); err != nil {
+		t.Fatalf("Failed to write field: %v", err)
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="photos"; filename="beach.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		t.Fatalf("Failed to create part: %v", err)
+	}
+	part.Write([]byte("0123456789"))
+
+	part, err = w.CreateFormFile("photos", "notes.txt")
+	if err != nil {
+		t.Fatalf("Failed to create part: %v", err)
+	}
+	part.Write([]byte("hi"))
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+func TestParseForm(t *testing.T) {
+	body, contentType := multipartBody(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantForm    url.Values
+		wantFiles   Files
+		wantErr     bool
+	}{
+		{
+			name:        "urlencoded",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			body:        "name=Ada&tags=a&tags=b",
+			wantForm:    url.Values{"name": {"Ada"}, "tags": {"a", "b"}},
+		},
+		{
+			name:        "multipart",
+			contentType: contentType,
+			body:        string(body),
+			wantForm:    url.Values{"title": {"Holiday"}},
+			wantFiles: Files{"photos": {
+				{Filename: "beach.png", Size: 10, ContentType: "image/png"},
+				{Filename: "notes.txt", Size: 2, ContentType: "application/octet-stream"},
+			}},
+		},
+		{
+			name:        "other content type",
+			contentType: "application/json",
+			body:        `{"name": "Ada"}`,
+		},
+		{
+			name:        "multipart without boundary",
+			contentType: "multipart/form-data",
+			body:        string(body),
+			wantErr:     true,
+		},
+		{
+			name:        "truncated multipart",
+			contentType: contentType,
+			body:        string(body[:len(body)/2]),
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form, files, err := parseForm(tt.contentType, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseForm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(form, tt.wantForm) {
+				t.Errorf("parseForm() form = %v, want %v", form, tt.wantForm)
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("parseForm() files = %v, want %v", files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestFiles_Get(t *testing.T) {
+	files := Files{"avatar": {{Filename: "me.jpg", Size: 3}}}
+
+	if got := files.Get("avatar"); got.Filename != "me.jpg" || !files.Has("avatar") {
+		t.Errorf("Expected the uploaded file, got %+v", got)
+	}
+	if got := files.Get("missing"); got != (FileInfo{}) || files.Has("missing") {
+		t.Errorf("Expected no file, got %+v", got)
+	}
+
+	var none Files
+	if got := none.Get("avatar"); got != (FileInfo{}) {
+		t.Errorf("Expected no file from empty Files, got %+v", got)
+	}
+}
+
+func TestNewTemplateContext_Form(t *testing.T) {
+	body, contentType := multipartBody(t)
+	req, _ := http.NewRequest("POST", "/upload", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	ctx, err := NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("NewTemplateContext() error = %v", err)
+	}
+	if ctx.Form.Get("title") != "Holiday" || ctx.Files.Get("photos").Size != 10 {
+		t.Errorf("Expected the form and files to be parsed, got %v and %v", ctx.Form, ctx.Files)
+	}
+	if ctx.RawBody != string(body) {
+		t.Error("Expected the raw body to be kept")
+	}
+
+	// Malformed forms don't fail the context
+	req, _ = http.NewRequest("POST", "/upload", strings.NewReader("garbage"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	ctx, err = NewTemplateContext(req, nil)
+	if err != nil {
+		t.Fatalf("NewTemplateContext() error = %v", err)
+	}
+	if ctx.Form != nil || ctx.Files != nil || ctx.Body != "garbage" {
+		t.Errorf("Expected no form nor files, got %v and %v", ctx.Form, ctx.Files)
+	}
+}
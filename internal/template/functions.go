@@ -2,7 +2,6 @@ package template
 
 import (
 	"encoding/json"
-	"math/rand"
 	"strings"
 	"time"
 
@@ -44,10 +43,19 @@ func sleep(duration interface{}) string {
 	return "" // Return empty string so it doesn't affect template output
 }
 
+// faker generates the values of random template functions. Templates share
+// defaultFaker, while deterministic routes get one seeded for each request.
+type faker struct {
+	*gofakeit.Faker
+}
+
+// defaultFaker backs the random template functions of most routes
+var defaultFaker = faker{gofakeit.GlobalFaker}
+
 // randFloat generates a random float64 between min and max (inclusive)
 // Usage in templates: {{ randFloat 1.0 10.0 }} or {{ randFloat 0 1 }}
 // Takes the same parameters as sprig's randInt but returns a float64
-func randFloat(min, max interface{}) float64 {
+func (f faker) randFloat(min, max interface{}) float64 {
 	minFloat := toFloat64(min)
 	maxFloat := toFloat64(max)
 
@@ -57,13 +65,13 @@ func randFloat(min, max interface{}) float64 {
 	}
 
 	// Generate random float between 0 and 1, then scale to range
-	randomValue := rand.Float64()
+	randomValue := f.Float64()
 	return minFloat + randomValue*(maxFloat-minFloat)
 }
 
 // randChoice randomly selects one value from the provided options of any type
 // Usage in templates: {{ randChoice "red" "green" "blue" }} or {{ randChoice 1 2 3 }} or {{ randChoice 1.5 "text" true }}
-func randChoice(choices ...interface{}) interface{} {
+func (f faker) randChoice(choices ...interface{}) interface{} {
 	if len(choices) == 0 {
 		return nil
 	}
@@ -73,7 +81,7 @@ func randChoice(choices ...interface{}) interface{} {
 	}
 
 	// Generate random index
-	randomIndex := rand.Intn(len(choices))
+	randomIndex := f.IntN(len(choices))
 	return choices[randomIndex]
 }
 
@@ -101,168 +109,190 @@ func toJsonPretty(v any) string {
 	return string(data)
 }
 
+// bodyString renders a request body as a string: the bytes received for the
+// template context, and JSON for parsed values that aren't strings already
+// Usage in templates: {{ bodyString . }} or {{ bodyString .Body.items }}
+func bodyString(v any) string {
+	switch body := v.(type) {
+	case *TemplateContext:
+		return body.RawBody
+	case nil:
+		return ""
+	case string:
+		return body
+	case []byte:
+		return string(body)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
 // Fake data generation functions using gofakeit
 
 // Basic personal information
-func fakeName() string           { return gofakeit.Name() }
-func fakeFirstName() string      { return gofakeit.FirstName() }
-func fakeLastName() string       { return gofakeit.LastName() }
-func fakeEmail() string          { return gofakeit.Email() }
-func fakePhone() string          { return gofakeit.Phone() }
-func fakePhoneFormatted() string { return gofakeit.PhoneFormatted() }
+func (f faker) fakeName() string           { return f.Name() }
+func (f faker) fakeFirstName() string      { return f.FirstName() }
+func (f faker) fakeLastName() string       { return f.LastName() }
+func (f faker) fakeEmail() string          { return f.Email() }
+func (f faker) fakePhone() string          { return f.Phone() }
+func (f faker) fakePhoneFormatted() string { return f.PhoneFormatted() }
 
 // Business and company data
-func fakeBS() string            { return gofakeit.BS() }
-func fakeCompany() string       { return gofakeit.Company() }
-func fakeCompanySuffix() string { return gofakeit.CompanySuffix() }
-func fakeJobTitle() string      { return gofakeit.JobTitle() }
-func fakeJobDescriptor() string { return gofakeit.JobDescriptor() }
-func fakeJobLevel() string      { return gofakeit.JobLevel() }
+func (f faker) fakeBS() string            { return f.BS() }
+func (f faker) fakeCompany() string       { return f.Company() }
+func (f faker) fakeCompanySuffix() string { return f.CompanySuffix() }
+func (f faker) fakeJobTitle() string      { return f.JobTitle() }
+func (f faker) fakeJobDescriptor() string { return f.JobDescriptor() }
+func (f faker) fakeJobLevel() string      { return f.JobLevel() }
 
 // Financial data
-func fakeCreditCardNumber() string       { return gofakeit.CreditCardNumber(nil) }
-func fakeCreditCardType() string         { return gofakeit.CreditCardType() }
-func fakeCurrency() string               { return gofakeit.Currency().Short }
-func fakeCurrencyLong() string           { return gofakeit.Currency().Long }
-func fakeCurrencyAbbrv() string          { return gofakeit.CurrencyShort() }
-func fakeCurrencyName() string           { return gofakeit.CurrencyLong() }
-func fakePrice(min, max float64) float64 { return gofakeit.Price(min, max) }
+func (f faker) fakeCreditCardNumber() string       { return f.CreditCardNumber(nil) }
+func (f faker) fakeCreditCardType() string         { return f.CreditCardType() }
+func (f faker) fakeCurrency() string               { return f.Currency().Short }
+func (f faker) fakeCurrencyLong() string           { return f.Currency().Long }
+func (f faker) fakeCurrencyAbbrv() string          { return f.CurrencyShort() }
+func (f faker) fakeCurrencyName() string           { return f.CurrencyLong() }
+func (f faker) fakePrice(min, max float64) float64 { return f.Price(min, max) }
 
 // Colors
-func fakeColor() string     { return gofakeit.Color() }
-func fakeHexColor() string  { return gofakeit.HexColor() }
-func fakeRGBColor() []int   { return gofakeit.RGBColor() }
-func fakeSafeColor() string { return gofakeit.SafeColor() }
+func (f faker) fakeColor() string     { return f.Color() }
+func (f faker) fakeHexColor() string  { return f.HexColor() }
+func (f faker) fakeRGBColor() []int   { return f.RGBColor() }
+func (f faker) fakeSafeColor() string { return f.SafeColor() }
 
 // Product data
-func fakeProduct() string            { return gofakeit.ProductName() }
-func fakeProductName() string        { return gofakeit.ProductName() }
-func fakeProductDescription() string { return gofakeit.ProductDescription() }
-func fakeProductCategory() string    { return gofakeit.ProductCategory() }
-func fakeProductFeature() string     { return gofakeit.ProductFeature() }
-func fakeProductMaterial() string    { return gofakeit.ProductMaterial() }
+func (f faker) fakeProduct() string            { return f.ProductName() }
+func (f faker) fakeProductName() string        { return f.ProductName() }
+func (f faker) fakeProductDescription() string { return f.ProductDescription() }
+func (f faker) fakeProductCategory() string    { return f.ProductCategory() }
+func (f faker) fakeProductFeature() string     { return f.ProductFeature() }
+func (f faker) fakeProductMaterial() string    { return f.ProductMaterial() }
 
 // Person details
-func fakeGender() string { return gofakeit.Gender() }
-func fakeSSN() string    { return gofakeit.SSN() }
-func fakeHobby() string  { return gofakeit.Hobby() }
+func (f faker) fakeGender() string { return f.Gender() }
+func (f faker) fakeSSN() string    { return f.SSN() }
+func (f faker) fakeHobby() string  { return f.Hobby() }
 
 // Authentication data
-func fakeUsername() string { return gofakeit.Username() }
+func (f faker) fakeUsername() string { return f.Username() }
 
-func fakePassword(lower, upper, numeric, special, space bool, num int) string {
-	return gofakeit.Password(lower, upper, numeric, special, space, num)
+func (f faker) fakePassword(lower, upper, numeric, special, space bool, num int) string {
+	return f.Password(lower, upper, numeric, special, space, num)
 }
 
 // Address information
-func fakeAddress() string      { return gofakeit.Address().Address }
-func fakeStreet() string       { return gofakeit.Street() }
-func fakeStreetName() string   { return gofakeit.StreetName() }
-func fakeStreetNumber() string { return gofakeit.StreetNumber() }
-func fakeCity() string         { return gofakeit.City() }
-func fakeState() string        { return gofakeit.State() }
-func fakeStateAbbrv() string   { return gofakeit.StateAbr() }
-func fakeZip() string          { return gofakeit.Zip() }
-func fakeCountry() string      { return gofakeit.Country() }
-func fakeCountryAbbrv() string { return gofakeit.CountryAbr() }
-func fakeLatitude() float64    { return gofakeit.Latitude() }
-func fakeLongitude() float64   { return gofakeit.Longitude() }
+func (f faker) fakeAddress() string      { return f.Address().Address }
+func (f faker) fakeStreet() string       { return f.Street() }
+func (f faker) fakeStreetName() string   { return f.StreetName() }
+func (f faker) fakeStreetNumber() string { return f.StreetNumber() }
+func (f faker) fakeCity() string         { return f.City() }
+func (f faker) fakeState() string        { return f.State() }
+func (f faker) fakeStateAbbrv() string   { return f.StateAbr() }
+func (f faker) fakeZip() string          { return f.Zip() }
+func (f faker) fakeCountry() string      { return f.Country() }
+func (f faker) fakeCountryAbbrv() string { return f.CountryAbr() }
+func (f faker) fakeLatitude() float64    { return f.Latitude() }
+func (f faker) fakeLongitude() float64   { return f.Longitude() }
 
 // Words and text
-func fakeWord() string { return gofakeit.Word() }
+func (f faker) fakeWord() string { return f.Word() }
 
-func fakeWords(num int) string {
+func (f faker) fakeWords(num int) string {
 	var words []string
 	for range num {
-		words = append(words, gofakeit.Word())
+		words = append(words, f.Word())
 	}
 	return strings.Join(words, " ")
 }
-func fakeSentence(wordCount int) string { return gofakeit.Sentence(wordCount) }
-func fakeParagraph(paragraphCount int, sentenceCount int, wordCount int, separator string) string {
-	return gofakeit.Paragraph(paragraphCount, sentenceCount, wordCount, separator)
+func (f faker) fakeSentence(wordCount int) string { return f.Sentence(wordCount) }
+func (f faker) fakeParagraph(paragraphCount int, sentenceCount int, wordCount int, separator string) string {
+	return f.Paragraph(paragraphCount, sentenceCount, wordCount, separator)
 }
-func fakeLoremIpsumWord() string                  { return gofakeit.LoremIpsumWord() }
-func fakeLoremIpsumSentence(wordCount int) string { return gofakeit.LoremIpsumSentence(wordCount) }
-func fakeLoremIpsumParagraph(paragraphCount int, sentenceCount int, wordCount int, separator string) string {
-	return gofakeit.LoremIpsumParagraph(paragraphCount, sentenceCount, wordCount, separator)
+func (f faker) fakeLoremIpsumWord() string                  { return f.LoremIpsumWord() }
+func (f faker) fakeLoremIpsumSentence(wordCount int) string { return f.LoremIpsumSentence(wordCount) }
+func (f faker) fakeLoremIpsumParagraph(paragraphCount int, sentenceCount int, wordCount int, separator string) string {
+	return f.LoremIpsumParagraph(paragraphCount, sentenceCount, wordCount, separator)
 }
 
 // Food
-func fakeFood() string      { return gofakeit.Lunch() }
-func fakeFruit() string     { return gofakeit.Fruit() }
-func fakeVegetable() string { return gofakeit.Vegetable() }
-func fakeBreakfast() string { return gofakeit.Breakfast() }
-func fakeLunch() string     { return gofakeit.Lunch() }
-func fakeDinner() string    { return gofakeit.Dinner() }
-func fakeSnack() string     { return gofakeit.Snack() }
-func fakeDessert() string   { return gofakeit.Dessert() }
+func (f faker) fakeFood() string      { return f.Lunch() }
+func (f faker) fakeFruit() string     { return f.Fruit() }
+func (f faker) fakeVegetable() string { return f.Vegetable() }
+func (f faker) fakeBreakfast() string { return f.Breakfast() }
+func (f faker) fakeLunch() string     { return f.Lunch() }
+func (f faker) fakeDinner() string    { return f.Dinner() }
+func (f faker) fakeSnack() string     { return f.Snack() }
+func (f faker) fakeDessert() string   { return f.Dessert() }
 
 // Miscellaneous
-func fakeFlipACoin() string { return gofakeit.FlipACoin() }
-func fakeRandomBool() bool  { return gofakeit.Bool() }
-func fakeUUID() string      { return gofakeit.UUID() }
+func (f faker) fakeFlipACoin() string { return f.FlipACoin() }
+func (f faker) fakeRandomBool() bool  { return f.Bool() }
+func (f faker) fakeUUID() string      { return f.UUID() }
 
 // Internet values
-func fakeURL() string          { return gofakeit.URL() }
-func fakeDomainName() string   { return gofakeit.DomainName() }
-func fakeDomainSuffix() string { return gofakeit.DomainSuffix() }
-func fakeIPv4Address() string  { return gofakeit.IPv4Address() }
-func fakeIPv6Address() string  { return gofakeit.IPv6Address() }
-func fakeMacAddress() string   { return gofakeit.MacAddress() }
-func fakeHTTPMethod() string   { return gofakeit.HTTPMethod() }
-func fakeUserAgent() string    { return gofakeit.UserAgent() }
+func (f faker) fakeURL() string          { return f.URL() }
+func (f faker) fakeDomainName() string   { return f.DomainName() }
+func (f faker) fakeDomainSuffix() string { return f.DomainSuffix() }
+func (f faker) fakeIPv4Address() string  { return f.IPv4Address() }
+func (f faker) fakeIPv6Address() string  { return f.IPv6Address() }
+func (f faker) fakeMacAddress() string   { return f.MacAddress() }
+func (f faker) fakeHTTPMethod() string   { return f.HTTPMethod() }
+func (f faker) fakeUserAgent() string    { return f.UserAgent() }
 
 // Date and Time
-func fakeDate() time.Time                          { return gofakeit.Date() }
-func fakeDateRange(start, end time.Time) time.Time { return gofakeit.DateRange(start, end) }
-func fakeFuture() time.Time                        { return gofakeit.FutureDate() }
-func fakePast() time.Time                          { return gofakeit.PastDate() }
-func fakeWeekday() string                          { return gofakeit.WeekDay() }
-func fakeMonth() int                               { return gofakeit.Month() }
-func fakeMonthString() string                      { return gofakeit.MonthString() }
-func fakeYear() int                                { return gofakeit.Year() }
-func fakeHour() int                                { return gofakeit.Hour() }
-func fakeMinute() int                              { return gofakeit.Minute() }
-func fakeSecond() int                              { return gofakeit.Second() }
-func fakeNanoSecond() int                          { return gofakeit.NanoSecond() }
-func fakeTimeZone() string                         { return gofakeit.TimeZone() }
-func fakeTimeZoneAbbrv() string                    { return gofakeit.TimeZone() }
-func fakeTimeZoneFull() string                     { return gofakeit.TimeZoneFull() }
-func fakeTimeZoneOffset() float32                  { return gofakeit.TimeZoneOffset() }
+func (f faker) fakeDate() time.Time                          { return f.Date() }
+func (f faker) fakeDateRange(start, end time.Time) time.Time { return f.DateRange(start, end) }
+func (f faker) fakeFuture() time.Time                        { return f.FutureDate() }
+func (f faker) fakePast() time.Time                          { return f.PastDate() }
+func (f faker) fakeWeekday() string                          { return f.WeekDay() }
+func (f faker) fakeMonth() int                               { return f.Month() }
+func (f faker) fakeMonthString() string                      { return f.MonthString() }
+func (f faker) fakeYear() int                                { return f.Year() }
+func (f faker) fakeHour() int                                { return f.Hour() }
+func (f faker) fakeMinute() int                              { return f.Minute() }
+func (f faker) fakeSecond() int                              { return f.Second() }
+func (f faker) fakeNanoSecond() int                          { return f.NanoSecond() }
+func (f faker) fakeTimeZone() string                         { return f.TimeZone() }
+func (f faker) fakeTimeZoneAbbrv() string                    { return f.TimeZone() }
+func (f faker) fakeTimeZoneFull() string                     { return f.TimeZoneFull() }
+func (f faker) fakeTimeZoneOffset() float32                  { return f.TimeZoneOffset() }
 
 // Payment information
-func fakeCreditCard() gofakeit.CreditCardInfo { return *gofakeit.CreditCard() }
-func fakeAchRouting() string                  { return gofakeit.AchRouting() }
-func fakeAchAccount() string                  { return gofakeit.AchAccount() }
-func fakeBitcoinAddress() string              { return gofakeit.BitcoinAddress() }
-func fakeBitcoinPrivateKey() string           { return gofakeit.BitcoinPrivateKey() }
+func (f faker) fakeCreditCard() gofakeit.CreditCardInfo { return *f.CreditCard() }
+func (f faker) fakeAchRouting() string                  { return f.AchRouting() }
+func (f faker) fakeAchAccount() string                  { return f.AchAccount() }
+func (f faker) fakeBitcoinAddress() string              { return f.BitcoinAddress() }
+func (f faker) fakeBitcoinPrivateKey() string           { return f.BitcoinPrivateKey() }
 
 // Animals
-func fakeAnimal() string     { return gofakeit.Animal() }
-func fakeAnimalType() string { return gofakeit.AnimalType() }
-func fakeFarmAnimal() string { return gofakeit.FarmAnimal() }
-func fakeCat() string        { return gofakeit.Cat() }
-func fakeDog() string        { return gofakeit.Dog() }
-func fakeBird() string       { return gofakeit.Bird() }
+func (f faker) fakeAnimal() string     { return f.Animal() }
+func (f faker) fakeAnimalType() string { return f.AnimalType() }
+func (f faker) fakeFarmAnimal() string { return f.FarmAnimal() }
+func (f faker) fakeCat() string        { return f.Cat() }
+func (f faker) fakeDog() string        { return f.Dog() }
+func (f faker) fakeBird() string       { return f.Bird() }
 
 // Language
-func fakeLanguage() string            { return gofakeit.Language() }
-func fakeLanguageAbbrv() string       { return gofakeit.LanguageAbbreviation() }
-func fakeProgrammingLanguage() string { return gofakeit.ProgrammingLanguage() }
+func (f faker) fakeLanguage() string            { return f.Language() }
+func (f faker) fakeLanguageAbbrv() string       { return f.LanguageAbbreviation() }
+func (f faker) fakeProgrammingLanguage() string { return f.ProgrammingLanguage() }
 
 // Celebrities
-func fakeCelebrityActor() string    { return gofakeit.CelebrityActor() }
-func fakeCelebrityBusiness() string { return gofakeit.CelebrityBusiness() }
-func fakeCelebritySport() string    { return gofakeit.CelebritySport() }
+func (f faker) fakeCelebrityActor() string    { return f.CelebrityActor() }
+func (f faker) fakeCelebrityBusiness() string { return f.CelebrityBusiness() }
+func (f faker) fakeCelebritySport() string    { return f.CelebritySport() }
 
 // Books, Movies, and Songs
-func fakeBook() string       { return gofakeit.BookTitle() }
-func fakeBookTitle() string  { return gofakeit.BookTitle() }
-func fakeBookAuthor() string { return gofakeit.BookAuthor() }
-func fakeBookGenre() string  { return gofakeit.BookGenre() }
-func fakeMovie() string      { return gofakeit.MovieName() }
-func fakeMovieName() string  { return gofakeit.MovieName() }
-func fakeMovieGenre() string { return gofakeit.MovieGenre() }
-func fakeSong() string       { return gofakeit.SongName() }
-func fakeMusicGenre() string { return gofakeit.SongGenre() }
+func (f faker) fakeBook() string       { return f.BookTitle() }
+func (f faker) fakeBookTitle() string  { return f.BookTitle() }
+func (f faker) fakeBookAuthor() string { return f.BookAuthor() }
+func (f faker) fakeBookGenre() string  { return f.BookGenre() }
+func (f faker) fakeMovie() string      { return f.MovieName() }
+func (f faker) fakeMovieName() string  { return f.MovieName() }
+func (f faker) fakeMovieGenre() string { return f.MovieGenre() }
+func (f faker) fakeSong() string       { return f.SongName() }
+func (f faker) fakeMusicGenre() string { return f.SongGenre() }
@@ -236,7 +236,7 @@ func TestRandFloat(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Run the function multiple times to test randomness
 			for i := 0; i < 10; i++ {
-				result := randFloat(tt.min, tt.max)
+				result := defaultFaker.randFloat(tt.min, tt.max)
 				tt.testFunc(t, result)
 			}
 		})
@@ -250,7 +250,7 @@ func TestRandFloatDistribution(t *testing.T) {
 	results := make([]float64, count)
 
 	for i := 0; i < count; i++ {
-		results[i] = randFloat(min, max)
+		results[i] = defaultFaker.randFloat(min, max)
 	}
 
 	// Calculate basic statistics
@@ -442,7 +442,7 @@ func TestRandChoice(t *testing.T) {
 			}
 
 			for i := 0; i < iterations; i++ {
-				result := randChoice(tt.choices...)
+				result := defaultFaker.randChoice(tt.choices...)
 				tt.testFunc(t, result)
 			}
 		})
@@ -456,7 +456,7 @@ func TestRandChoiceDistribution(t *testing.T) {
 	results := make(map[interface{}]int)
 
 	for i := 0; i < count; i++ {
-		result := randChoice(choices...)
+		result := defaultFaker.randChoice(choices...)
 		results[result]++
 	}
 
@@ -504,7 +504,7 @@ func TestRandChoiceTypedDistribution(t *testing.T) {
 		results := make(map[interface{}]int)
 
 		for i := 0; i < count; i++ {
-			result := randChoice(choices...)
+			result := defaultFaker.randChoice(choices...)
 			results[result]++
 		}
 
@@ -524,7 +524,7 @@ func TestRandChoiceTypedDistribution(t *testing.T) {
 		results := make(map[interface{}]int)
 
 		for i := 0; i < count; i++ {
-			result := randChoice(choices...)
+			result := defaultFaker.randChoice(choices...)
 			results[result]++
 		}
 
@@ -612,3 +612,27 @@ func TestToJsonPrettyError(t *testing.T) {
 		t.Errorf("toJsonPretty() with unmarshalable input = %q, want %q", result, "{}")
 	}
 }
+
+func TestBodyString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{name: "template context", input: &TemplateContext{Body: map[string]any{"a": 1}, RawBody: "{ \"a\": 1 }"}, expected: "{ \"a\": 1 }"},
+		{name: "string", input: "plain text", expected: "plain text"},
+		{name: "bytes", input: []byte("bytes"), expected: "bytes"},
+		{name: "nil", input: nil, expected: ""},
+		{name: "parsed object", input: map[string]any{"items": []any{1, 2}}, expected: `{"items":[1,2]}`},
+		{name: "number", input: 42.5, expected: "42.5"},
+		{name: "unmarshalable", input: make(chan int), expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bodyString(tt.input); got != tt.expected {
+				t.Errorf("bodyString() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package template
+
+import (
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Usage lists what templates use, as found in their parse trees
+type Usage struct {
+	Fields    []string // Template context fields read, like ".Params.id", sorted
+	Functions []string // Functions called, sorted
+}
+
+// Inspect reports the template context fields and the functions the given
+// templates use, following the templates they include. Fields are only
+// reported where dot is the template context, so fields of range items or of
+// values passed to included templates are left out.
+func Inspect(tmpls ...*template.Template) Usage {
+	in := &inspector{fields: map[string]bool{}, functions: map[string]bool{}, visited: map[inspected]bool{}}
+	for _, tmpl := range tmpls {
+		if tmpl != nil {
+			in.visit(tmpl, tmpl.Name(), true)
+		}
+	}
+
+	var usage Usage
+	for field := range in.fields {
+		usage.Fields = append(usage.Fields, field)
+	}
+	for function := range in.functions {
+		usage.Functions = append(usage.Functions, function)
+	}
+	slices.Sort(usage.Fields)
+	slices.Sort(usage.Functions)
+	return usage
+}
+
+// inspector collects the fields and functions of the templates it visits
+type inspector struct {
+	fields    map[string]bool
+	functions map[string]bool
+	visited   map[inspected]bool
+}
+
+// inspected identifies a template visited by an inspector, which is visited
+// again when dot is the template context and it wasn't before
+type inspected struct {
+	name    string
+	context bool
+}
+
+// visit inspects the template called name in the set of tmpl. When context,
+// the template is executed with the template context as dot.
+func (in *inspector) visit(tmpl *template.Template, name string, context bool) {
+	key := inspected{name: name, context: context}
+	if in.visited[key] {
+		return
+	}
+	in.visited[key] = true
+
+	if t := tmpl.Lookup(name); t != nil && t.Tree != nil {
+		in.walk(tmpl, t.Tree.Root, context, context)
+	}
+}
+
+// walk inspects node. dot tells whether dot is the template context, and
+// root whether $ is.
+func (in *inspector) walk(tmpl *template.Template, node parse.Node, dot, root bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			in.walk(tmpl, child, dot, root)
+		}
+	case *parse.ActionNode:
+		in.walk(tmpl, n.Pipe, dot, root)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			in.walk(tmpl, cmd, dot, root)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			in.walk(tmpl, arg, dot, root)
+		}
+	case *parse.IdentifierNode:
+		in.functions[n.Ident] = true
+	case *parse.FieldNode:
+		if dot {
+			in.fields["."+strings.Join(n.Ident, ".")] = true
+		}
+	case *parse.VariableNode:
+		if root && n.Ident[0] == "$" && len(n.Ident) > 1 {
+			in.fields["."+strings.Join(n.Ident[1:], ".")] = true
+		}
+	case *parse.ChainNode:
+		in.walk(tmpl, n.Node, dot, root)
+	case *parse.IfNode:
+		in.walk(tmpl, n.Pipe, dot, root)
+		in.walk(tmpl, n.List, dot, root)
+		in.walk(tmpl, n.ElseList, dot, root)
+	case *parse.RangeNode:
+		// Dot is each item inside the loop, and the context again in its else
+		in.walk(tmpl, n.Pipe, dot, root)
+		in.walk(tmpl, n.List, false, root)
+		in.walk(tmpl, n.ElseList, dot, root)
+	case *parse.WithNode:
+		// Dot is the value inside with, and the context again in its else
+		in.walk(tmpl, n.Pipe, dot, root)
+		in.walk(tmpl, n.List, false, root)
+		in.walk(tmpl, n.ElseList, dot, root)
+	case *parse.TemplateNode:
+		in.walk(tmpl, n.Pipe, dot, root)
+		in.visit(tmpl, n.Name, dot && passesDot(n.Pipe))
+	}
+}
+
+// passesDot reports whether a template call passes dot itself
+func passesDot(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	_, ok := pipe.Cmds[0].Args[0].(*parse.DotNode)
+	return ok
+}
@@ -0,0 +1,38 @@
+package template
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.AddPartial("user", `{"name": {{ toJson .Body.name }}, "by": {{ toJson $.Headers.Get }}}`); err != nil {
+		t.Fatalf("Failed to add partial: %v", err)
+	}
+	if err := engine.AddPartial("item", `{"id": {{ .id }}, "at": {{ now | date "2006" }}}`); err != nil {
+		t.Fatalf("Failed to add partial: %v", err)
+	}
+
+	body, err := engine.CompileInlineTemplate("body", `{{ template "user" . }}`+
+		`{{ range .Query.ids }}{{ .Ignored }}{{ $.Params.id }}{{ template "item" . }}{{ else }}{{ .Query.none }}{{ end }}`+
+		`{{ with .Body.user }}{{ .ignored | upper }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+	header, err := engine.CompileInlineTemplate("header", `{{ .RequestID }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	usage := Inspect(body, header, nil)
+
+	expectedFields := []string{".Body.name", ".Body.user", ".Headers.Get", ".Params.id", ".Query.ids", ".Query.none", ".RequestID"}
+	if !slices.Equal(usage.Fields, expectedFields) {
+		t.Errorf("Expected fields %v, got %v", expectedFields, usage.Fields)
+	}
+	expectedFunctions := []string{"date", "now", "toJson", "upper"}
+	if !slices.Equal(usage.Functions, expectedFunctions) {
+		t.Errorf("Expected functions %v, got %v", expectedFunctions, usage.Functions)
+	}
+}
@@ -0,0 +1,69 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// maxLoopItems limits the iterations of loop over a count, so a template
+// can't make the server allocate gigabytes
+const maxLoopItems = 10000
+
+// LoopItem is an iteration of loop, knowing its position so templates can
+// write the separators of the JSON arrays they build without if-last checks
+type LoopItem struct {
+	Index  int         // Position of the item, from 0
+	Number int         // Position of the item, from 1
+	First  bool        // Whether it's the first item
+	Last   bool        // Whether it's the last item
+	Comma  string      // "," for every item but the last, to write after the item
+	Value  interface{} // The item of the list looped over, or Index when looping over a count
+}
+
+// loop returns the iterations of a loop over a count or the items of a list,
+// which range over to write JSON arrays with their commas in place.
+// Usage in templates: [{{ range loop 10 }}{"id": {{ .Number }}}{{ .Comma }}{{ end }}]
+// or [{{ range loop .Body.tags }}"{{ .Value }}"{{ .Comma }}{{ end }}]
+func loop(items interface{}) ([]LoopItem, error) {
+	var values []interface{}
+
+	switch v := reflect.ValueOf(items); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		count := v.Int()
+		if count < 0 || count > maxLoopItems {
+			return nil, fmt.Errorf("loop: count %d must be between 0 and %d", count, maxLoopItems)
+		}
+		values = make([]interface{}, count)
+		for i := range values {
+			values[i] = i
+		}
+
+	case reflect.Slice, reflect.Array:
+		values = make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Interface()
+		}
+
+	case reflect.Invalid:
+		// Missing lists, like absent body fields, loop zero times
+
+	default:
+		return nil, fmt.Errorf("loop: expected a count or a list, got %T", items)
+	}
+
+	loopItems := make([]LoopItem, len(values))
+	for i, value := range values {
+		last := i == len(values)-1
+		loopItems[i] = LoopItem{
+			Index:  i,
+			Number: i + 1,
+			First:  i == 0,
+			Last:   last,
+			Value:  value,
+		}
+		if !last {
+			loopItems[i].Comma = ","
+		}
+	}
+	return loopItems, nil
+}
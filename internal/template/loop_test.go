@@ -0,0 +1,98 @@
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoop(t *testing.T) {
+	items, err := loop(3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []LoopItem{
+		{Index: 0, Number: 1, First: true, Comma: ",", Value: 0},
+		{Index: 1, Number: 2, Comma: ",", Value: 1},
+		{Index: 2, Number: 3, Last: true, Value: 2},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("Expected %d items, got %d", len(want), len(items))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("Item %d: expected %+v, got %+v", i, want[i], items[i])
+		}
+	}
+
+	items, err = loop([]interface{}{"a", "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Value != "a" || items[1].Value != "b" || !items[1].Last {
+		t.Errorf("Expected the items of the list, got %+v", items)
+	}
+
+	if items, err := loop([]string{"only"}); err != nil || len(items) != 1 || !items[0].First || !items[0].Last || items[0].Comma != "" {
+		t.Errorf("Expected a single first and last item without a comma, got %+v (error: %v)", items, err)
+	}
+	if items, err := loop(nil); err != nil || len(items) != 0 {
+		t.Errorf("Expected no items for nil, got %+v (error: %v)", items, err)
+	}
+	if items, err := loop(0); err != nil || len(items) != 0 {
+		t.Errorf("Expected no items for 0, got %+v (error: %v)", items, err)
+	}
+}
+
+func TestLoopErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		items interface{}
+		want  string
+	}{
+		{name: "negative count", items: -1, want: "count -1 must be between 0 and 10000"},
+		{name: "count too large", items: 10001, want: "count 10001 must be between 0 and 10000"},
+		{name: "string", items: "abc", want: "expected a count or a list, got string"},
+		{name: "map", items: map[string]interface{}{}, want: "expected a count or a list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loop(tt.items); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoopInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("loop", `{"users": [{{ range loop 3 }}{"id": {{ .Number }}, "name": "{{ fakeName }}"}{{ .Comma }}{{ end }}], "tags": [{{ range loop $.Body.tags }}"{{ .Value }}"{{ .Comma }}{{ end }}]}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	var buf strings.Builder
+	ctx := &TemplateContext{Body: map[string]interface{}{"tags": []interface{}{"a", "b"}}}
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	var doc struct {
+		Users []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"users"`
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", buf.String(), err)
+	}
+	if len(doc.Users) != 3 || doc.Users[2].ID != 3 || doc.Users[0].Name == "" {
+		t.Errorf("Expected 3 numbered users, got %+v", doc.Users)
+	}
+	if strings.Join(doc.Tags, ",") != "a,b" {
+		t.Errorf("Expected the tags of the body, got %v", doc.Tags)
+	}
+}
@@ -0,0 +1,88 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// AddPartial parses a named template that templates compiled afterwards can
+// include with {{ template "name" . }}, for fragments shared by many routes,
+// like an error envelope or a pagination wrapper. Templates the partial
+// defines with {{ define }} can be included too.
+func (e *Engine) AddPartial(name, content string) error {
+	if strings.TrimSpace(name) == "" {
+		return NewCompilationError("partial", "partial name cannot be empty", nil)
+	}
+
+	if e.partials == nil {
+		e.partials = template.New("").Delims(e.leftDelimiter, e.rightDelimiter).Funcs(e.funcMap)
+	}
+	if e.partials.Lookup(name) != nil {
+		return NewCompilationError(name, "a partial with this name is already defined", nil)
+	}
+
+	if _, err := e.partials.New(name).Parse(content); err != nil {
+		return NewCompilationError(name, fmt.Sprintf("failed to parse partial: %v", err), err)
+	}
+	return nil
+}
+
+// AddPartialDir adds every file of dir as a partial named after the file
+// without its extension, so "envelope.tmpl" is included as "envelope".
+// Hidden files and subdirectories are skipped.
+func (e *Engine) AddPartialDir(dir string) error {
+	files, err := PartialFiles(dir)
+	if err != nil {
+		return NewCompilationError(dir, fmt.Sprintf("failed to read partials directory: %v", err), err)
+	}
+
+	for _, filename := range files {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return NewCompilationError(filename, fmt.Sprintf("failed to read partial: %v", err), err)
+		}
+		name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		if err := e.AddPartial(name, string(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PartialFiles returns the files of dir AddPartialDir adds as partials, in
+// lexical order
+func PartialFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files, nil
+}
+
+// newTemplate returns an empty template to parse a template named name into,
+// which can include the engine's partials. The partials run with the engine's
+// functions too, so they're restricted along with the template.
+func (e *Engine) newTemplate(name string) (*template.Template, error) {
+	if e.partials == nil {
+		return template.New(name).Delims(e.leftDelimiter, e.rightDelimiter).Funcs(e.funcMap), nil
+	}
+
+	// Every template gets its own copy of the partials, so templates can't
+	// redefine them for each other
+	partials, err := e.partials.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy partials: %w", err)
+	}
+	return partials.New(name).Funcs(e.funcMap), nil
+}
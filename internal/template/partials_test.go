@@ -0,0 +1,126 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestEngine_Partials(t *testing.T) {
+	engine := NewEngine()
+
+	if err := engine.AddPartial("envelope", `{"data": {{ template "item" . }}, "error": null}{{ define "item" }}{"id": {{ .id }}}{{ end }}`); err != nil {
+		t.Fatalf("Failed to add partial: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "page.tmpl"), []byte(`{"items": [{{ . }}], "next": null}`), 0o644); err != nil {
+		t.Fatalf("Failed to write partial: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".hidden.tmpl"), []byte(`{{ broken`), 0o644); err != nil {
+		t.Fatalf("Failed to write partial: %v", err)
+	}
+	if err := engine.AddPartialDir(dir); err != nil {
+		t.Fatalf("Failed to add partials directory: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "users.tmpl")
+	if err := os.WriteFile(file, []byte(`{{ template "page" 3 }}`), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	inline, err := engine.CompileInlineTemplate("user", `{{ template "envelope" (dict "id" 7) }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile inline template: %v", err)
+	}
+	fromFile, err := engine.CompileFileTemplate(file)
+	if err != nil {
+		t.Fatalf("Failed to compile file template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		render   func() (string, error)
+		expected string
+	}{
+		{name: "inline template", expected: `{"data": {"id": 7}, "error": null}`, render: func() (string, error) {
+			var buf strings.Builder
+			err := engine.ExecuteTemplate(inline, &buf, &TemplateContext{})
+			return buf.String(), err
+		}},
+		{name: "file template", expected: `{"items": [3], "next": null}`, render: func() (string, error) {
+			var buf strings.Builder
+			err := engine.ExecuteTemplate(fromFile, &buf, &TemplateContext{})
+			return buf.String(), err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.render()
+			if err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEngine_PartialsRedefined(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.AddPartial("greeting", "hello"); err != nil {
+		t.Fatalf("Failed to add partial: %v", err)
+	}
+
+	// A template redefining a partial doesn't change it for the others
+	custom, err := engine.CompileInlineTemplate("custom", `{{ define "greeting" }}howdy{{ end }}{{ template "greeting" }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+	plain, err := engine.CompileInlineTemplate("plain", `{{ template "greeting" }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		tmpl     *template.Template
+		expected string
+	}{
+		{name: "custom", tmpl: custom, expected: "howdy"},
+		{name: "plain", tmpl: plain, expected: "hello"},
+	} {
+		var buf strings.Builder
+		if err := engine.ExecuteTemplate(tt.tmpl, &buf, &TemplateContext{}); err != nil || buf.String() != tt.expected {
+			t.Errorf("Expected %s to render %q, got %q (%v)", tt.name, tt.expected, buf.String(), err)
+		}
+	}
+}
+
+func TestEngine_PartialErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		add     func(e *Engine) error
+		wantErr string
+	}{
+		{name: "empty name", add: func(e *Engine) error { return e.AddPartial(" ", "x") }, wantErr: "partial name cannot be empty"},
+		{name: "invalid syntax", add: func(e *Engine) error { return e.AddPartial("broken", "{{ .id ") }, wantErr: "failed to parse partial"},
+		{name: "duplicate", add: func(e *Engine) error {
+			_ = e.AddPartial("dup", "a")
+			return e.AddPartial("dup", "b")
+		}, wantErr: "already defined"},
+		{name: "missing directory", add: func(e *Engine) error { return e.AddPartialDir(filepath.Join(t.TempDir(), "missing")) }, wantErr: "failed to read partials directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.add(NewEngine()); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
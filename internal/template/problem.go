@@ -0,0 +1,162 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/patrickdappollonio/mockingjay/internal/problem"
+)
+
+// problemMembers are the members of problem documents problemJSON fills in
+// itself, which extensions can't replace
+var problemMembers = []string{"status", "title", "detail"}
+
+// problemJSON returns an RFC 7807 problem details document, so error
+// templates don't have to write one by hand. The title defaults to the
+// status text and an empty detail is left out. Extensions add members to the
+// document, like a "code" or "errors", and can replace the default "type" of
+// "about:blank" or set the "instance".
+// Usage in templates: {{ problemJSON 404 "Not Found" "no user with that ID" (dict "code" "user_not_found") }}
+func problemJSON(status int, title, detail string, extensions ...map[string]interface{}) (string, error) {
+	if status < 100 || status > 599 {
+		return "", fmt.Errorf("problemJSON: invalid HTTP status code %d, must be between 100 and 599", status)
+	}
+
+	doc := map[string]interface{}{"type": "about:blank"}
+	for _, ext := range extensions {
+		for _, member := range problemMembers {
+			if _, ok := ext[member]; ok {
+				return "", fmt.Errorf("problemJSON: %q is set by the status, title and detail arguments, not by extensions", member)
+			}
+		}
+		maps.Copy(doc, ext)
+	}
+
+	if title == "" {
+		title = http.StatusText(status)
+	}
+	doc["status"] = status
+	doc["title"] = title
+	if detail != "" {
+		doc["detail"] = detail
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("problemJSON: %w", err)
+	}
+	return string(data), nil
+}
+
+// ProblemJSON renders a problem details document like problemJSON, and
+// answers with its status and the application/problem+json content type.
+// Templates call it as problemJSON; this is the function it stands for while
+// a response is rendered.
+func (r *Response) ProblemJSON(status int, title, detail string, extensions ...map[string]interface{}) (string, error) {
+	doc, err := problemJSON(status, title, detail, extensions...)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status = status
+	r.contentType = problem.ContentType
+	return doc, nil
+}
+
+// renderFuncs are the template functions acting on the request being
+// rendered: logEvent, and those changing the response, by the Response method
+// they stand for
+func renderFuncs(ctx *TemplateContext) template.FuncMap {
+	funcs := template.FuncMap{"logEvent": ctx.LogEvent}
+	if resp := ctx.Response; resp != nil {
+		funcs["problemJSON"] = resp.ProblemJSON
+		funcs["setCookie"] = resp.SetCookie
+		funcs["deleteCookie"] = resp.DeleteCookie
+	}
+	return funcs
+}
+
+// renderTemplate returns a copy of tmpl whose logEvent, problemJSON and
+// cookie functions act on ctx, or tmpl itself when it calls none of them
+func renderTemplate(tmpl *template.Template, ctx *TemplateContext) (*template.Template, error) {
+	funcs := renderFuncs(ctx)
+	calls := false
+	for name := range funcs {
+		calls = calls || callsFunction(tmpl, name)
+	}
+	if !calls {
+		return tmpl, nil
+	}
+
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy template: %w", err)
+	}
+	return clone.Funcs(funcs), nil
+}
+
+// callsFunction reports whether tmpl, or a template it defines, calls the
+// function name
+func callsFunction(tmpl *template.Template, name string) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && nodeCallsFunction(t.Tree.Root, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeCallsFunction reports whether node, or a node under it, calls the
+// function name
+func nodeCallsFunction(node parse.Node, name string) bool {
+	switch n := node.(type) {
+	case *parse.IdentifierNode:
+		return n.Ident == name
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if nodeCallsFunction(child, name) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return nodeCallsFunction(n.Pipe, name)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if nodeCallsFunction(cmd, name) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if nodeCallsFunction(arg, name) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return nodeCallsFunction(n.Node, name)
+	case *parse.IfNode:
+		return nodeCallsFunction(&n.BranchNode, name)
+	case *parse.RangeNode:
+		return nodeCallsFunction(&n.BranchNode, name)
+	case *parse.WithNode:
+		return nodeCallsFunction(&n.BranchNode, name)
+	case *parse.BranchNode:
+		return nodeCallsFunction(n.Pipe, name) || nodeCallsFunction(n.List, name) || nodeCallsFunction(n.ElseList, name)
+	case *parse.TemplateNode:
+		return nodeCallsFunction(n.Pipe, name)
+	}
+	return false
+}
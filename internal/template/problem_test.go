@@ -0,0 +1,146 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProblemJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		title      string
+		detail     string
+		extensions []map[string]interface{}
+		expected   string
+		wantErr    string
+	}{
+		{
+			name:     "status only",
+			status:   404,
+			expected: `{"status":404,"title":"Not Found","type":"about:blank"}`,
+		},
+		{
+			name:     "title and detail",
+			status:   409,
+			title:    "Out of Stock",
+			detail:   "only 2 left",
+			expected: `{"detail":"only 2 left","status":409,"title":"Out of Stock","type":"about:blank"}`,
+		},
+		{
+			name:       "extensions",
+			status:     422,
+			detail:     "invalid email",
+			extensions: []map[string]interface{}{{"type": "https://example.com/probs/invalid", "instance": "/users/42", "code": "invalid_email"}},
+			expected:   `{"code":"invalid_email","detail":"invalid email","instance":"/users/42","status":422,"title":"Unprocessable Entity","type":"https://example.com/probs/invalid"}`,
+		},
+		{
+			name:       "extension replacing a member",
+			status:     400,
+			extensions: []map[string]interface{}{{"status": 500}},
+			wantErr:    `"status" is set by the status, title and detail arguments`,
+		},
+		{
+			name:    "invalid status",
+			status:  99,
+			wantErr: "invalid HTTP status code 99",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := problemJSON(tt.status, tt.title, tt.detail, tt.extensions...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProblemJSONInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("problem", `{{ define "missing" }}{{ problemJSON 404 "" (printf "no user %s" .Params.id) (dict "code" "user_not_found") }}{{ end }}`+
+		`{{ if eq .Params.id "42" }}{"id": 42}{{ else }}{{ template "missing" . }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	tests := []struct {
+		id                  string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{id: "42", expectedStatus: http.StatusOK, expectedContentType: "", expectedBody: `{"id": 42}`},
+		{id: "7", expectedStatus: http.StatusNotFound, expectedContentType: "application/problem+json", expectedBody: `{"code":"user_not_found","detail":"no user 7","status":404,"title":"Not Found","type":"about:blank"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/users/"+tt.id, nil)
+			ctx, err := NewTemplateContext(req, map[string]string{"id": tt.id})
+			if err != nil {
+				t.Fatalf("Failed to create context: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+
+			if buf.String() != tt.expectedBody {
+				t.Errorf("Expected body %s, got %s", tt.expectedBody, buf.String())
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Errorf("Expected valid JSON, got %s", buf.String())
+			}
+			if got := ctx.Response.StatusOr(http.StatusOK); got != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, got)
+			}
+			if got := ctx.Response.ContentType(); got != tt.expectedContentType {
+				t.Errorf("Expected content type %q, got %q", tt.expectedContentType, got)
+			}
+		})
+	}
+}
+
+func TestCallsFunction(t *testing.T) {
+	engine := NewEngine()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{name: "no call", content: `{"ok": true}`, expected: false},
+		{name: "action", content: `{{ problemJSON 500 "" "" }}`, expected: true},
+		{name: "pipeline", content: `{{ 404 | printf "%d" }}{{ if true }}{{ else }}{{ problemJSON 404 "" "" }}{{ end }}`, expected: true},
+		{name: "range", content: `{{ range list 1 }}{{ with . }}{{ problemJSON 400 "" "" | trim }}{{ end }}{{ end }}`, expected: true},
+		{name: "other function", content: `{{ toJson (dict "a" 1) }}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := engine.CompileInlineTemplate(tt.name, tt.content)
+			if err != nil {
+				t.Fatalf("Failed to compile template: %v", err)
+			}
+			if got := callsFunction(tmpl, "problemJSON"); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
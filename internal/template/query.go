@@ -0,0 +1,321 @@
+package template
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QueryError reports list query options sent by a client that can't be
+// parsed, so the server can answer them with a 400 Bad Request
+type QueryError struct {
+	Option string // The query parameter, e.g. "$filter"
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Option, e.Reason)
+}
+
+// QueryOptions are the list query options of a request, read from the OData
+// parameters $filter, $orderby, $top and $skip or their generic forms filter,
+// sort, limit and offset
+type QueryOptions struct {
+	Filter  string       // Expression items must match, empty when unset
+	OrderBy []OrderField // Fields items are sorted by, in order of precedence
+	Top     int          // Most items returned, -1 when unset
+	Skip    int          // Items skipped before the first one returned
+
+	filter filterExpr
+}
+
+// OrderField is a field items are sorted by
+type OrderField struct {
+	Field string // Field name, with "/" or "." between nested fields
+	Desc  bool   // Sorts in descending order
+}
+
+// QueryResult is a page of items selected by query options
+type QueryResult struct {
+	Items   []any // Items on the page
+	Total   int   // Items matching the filter, across all pages
+	HasMore bool  // Whether more items follow the page
+}
+
+// queryOption returns the value of a query option, preferring its OData form
+// over its generic form, and the name of the parameter it was read from
+func queryOption(query url.Values, odata, generic string) (string, string) {
+	if query.Has(odata) {
+		return query.Get(odata), odata
+	}
+	return query.Get(generic), generic
+}
+
+// ParseQueryOptions parses the list query options of a request
+func ParseQueryOptions(query url.Values) (*QueryOptions, error) {
+	opts := &QueryOptions{Top: -1}
+
+	if value, option := queryOption(query, "$filter", "filter"); strings.TrimSpace(value) != "" {
+		expr, err := parseFilter(value)
+		if err != nil {
+			return nil, &QueryError{Option: option, Reason: err.Error()}
+		}
+		opts.Filter, opts.filter = value, expr
+	}
+
+	if value, option := queryOption(query, "$orderby", "sort"); strings.TrimSpace(value) != "" {
+		fields, err := parseOrderBy(value, option == "sort")
+		if err != nil {
+			return nil, &QueryError{Option: option, Reason: err.Error()}
+		}
+		opts.OrderBy = fields
+	}
+
+	for _, count := range []struct {
+		odata, generic string
+		target         *int
+	}{
+		{"$top", "limit", &opts.Top},
+		{"$skip", "offset", &opts.Skip},
+	} {
+		value, option := queryOption(query, count.odata, count.generic)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, &QueryError{Option: option, Reason: fmt.Sprintf("must be a non-negative integer, got %q", value)}
+		}
+		*count.target = n
+	}
+
+	return opts, nil
+}
+
+// parseOrderBy parses the fields items are sorted by, either OData style as
+// "name desc, age" or, for the generic form, as "-name,age"
+func parseOrderBy(value string, generic bool) ([]OrderField, error) {
+	var fields []OrderField
+	for _, part := range strings.Split(value, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("empty field in %q", value)
+		}
+
+		field := OrderField{Field: words[0]}
+		switch {
+		case generic && len(words) == 1:
+			if name, desc := strings.CutPrefix(field.Field, "-"); desc {
+				field = OrderField{Field: name, Desc: true}
+			}
+			field.Field = strings.TrimPrefix(field.Field, "+")
+		case !generic && len(words) == 2 && strings.EqualFold(words[1], "desc"):
+			field.Desc = true
+		case !generic && len(words) == 2 && strings.EqualFold(words[1], "asc"):
+		case len(words) != 1:
+			return nil, fmt.Errorf("invalid field %q", strings.TrimSpace(part))
+		}
+
+		if field.Field == "" {
+			return nil, fmt.Errorf("empty field in %q", value)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Apply filters, sorts and pages items, which can be any list, such as a
+// dataset loaded with dataFile or built with list and dict
+func (o *QueryOptions) Apply(items any) (*QueryResult, error) {
+	list, err := toList(items)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]any, 0, len(list))
+	for _, item := range list {
+		if o.filter == nil || o.filter.match(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	if len(o.OrderBy) > 0 {
+		slices.SortStableFunc(matched, func(a, b any) int {
+			for _, field := range o.OrderBy {
+				path := fieldPath(field.Field)
+				left, _ := lookupField(a, path)
+				right, _ := lookupField(b, path)
+				if c := orderValues(left, right); c != 0 {
+					if field.Desc {
+						return -c
+					}
+					return c
+				}
+			}
+			return 0
+		})
+	}
+
+	result := &QueryResult{Total: len(matched), Items: []any{}}
+	start := min(o.Skip, len(matched))
+	end := len(matched)
+	if o.Top >= 0 {
+		end = min(start+o.Top, len(matched))
+	}
+	result.Items = append(result.Items, matched[start:end]...)
+	result.HasMore = end < len(matched)
+
+	return result, nil
+}
+
+// parseQuery parses the list query options of a request
+// Usage in templates: {{ $opts := parseQuery .Query }}{{ $opts.Top }}
+func parseQuery(query url.Values) (*QueryOptions, error) {
+	return ParseQueryOptions(query)
+}
+
+// applyQuery filters, sorts and pages items with the list query options of a
+// request
+// Usage in templates: {{ $page := applyQuery .Query $items }}{{ $page.Items | toJson }}
+func applyQuery(query url.Values, items any) (*QueryResult, error) {
+	opts, err := ParseQueryOptions(query)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Apply(items)
+}
+
+// toList converts any slice or array to a list of items
+func toList(items any) ([]any, error) {
+	if items == nil {
+		return nil, nil
+	}
+	if list, ok := items.([]any); ok {
+		return list, nil
+	}
+
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("query options can only be applied to lists, got %T", items)
+	}
+
+	list := make([]any, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, nil
+}
+
+// fieldPath splits a field name into the names of nested fields, which are
+// separated by "/" in OData and by "." elsewhere
+func fieldPath(field string) []string {
+	return strings.FieldsFunc(field, func(r rune) bool { return r == '/' || r == '.' })
+}
+
+// lookupField returns the value of a possibly nested field of an item
+func lookupField(item any, path []string) (any, bool) {
+	current := item
+	for _, name := range path {
+		if m, ok := current.(map[string]any); ok {
+			value, found := m[name]
+			if !found {
+				return nil, false
+			}
+			current = value
+			continue
+		}
+
+		v := reflect.ValueOf(current)
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		current = value.Interface()
+	}
+	return current, true
+}
+
+// numberValue returns the value of any numeric type as a float64
+func numberValue(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// compareValues compares two values of the same kind, reporting false when
+// they can't be compared
+func compareValues(a, b any) (int, bool) {
+	if x, ok := numberValue(a); ok {
+		y, ok := numberValue(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case y:
+				return -1, true
+			}
+			return 1, true
+		}
+	case nil:
+		if b == nil {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// orderValues orders any two values for sorting. Missing and null values
+// come first, and values of different kinds are ordered by kind.
+func orderValues(a, b any) int {
+	if c, ok := compareValues(a, b); ok {
+		return c
+	}
+
+	rank := func(v any) int {
+		if v == nil {
+			return 0
+		}
+		if _, ok := numberValue(v); ok {
+			return 2
+		}
+		switch v.(type) {
+		case bool:
+			return 1
+		case string:
+			return 3
+		}
+		return 4
+	}
+	return rank(a) - rank(b)
+}
@@ -0,0 +1,344 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// filterExpr is a parsed filter expression, matched against list items
+type filterExpr interface {
+	match(item any) bool
+}
+
+// logicalExpr combines two expressions with "and" or "or"
+type logicalExpr struct {
+	and         bool
+	left, right filterExpr
+}
+
+func (e *logicalExpr) match(item any) bool {
+	if e.and {
+		return e.left.match(item) && e.right.match(item)
+	}
+	return e.left.match(item) || e.right.match(item)
+}
+
+// notExpr negates an expression
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e *notExpr) match(item any) bool {
+	return !e.expr.match(item)
+}
+
+// compareExpr compares a field with a literal, e.g. "age gt 30"
+type compareExpr struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (e *compareExpr) match(item any) bool {
+	field, _ := lookupField(item, e.path)
+	c, ok := compareValues(field, e.value)
+
+	switch e.op {
+	case "eq":
+		return ok && c == 0
+	case "ne":
+		return !ok || c != 0
+	case "gt":
+		return ok && c > 0
+	case "ge":
+		return ok && c >= 0
+	case "lt":
+		return ok && c < 0
+	case "le":
+		return ok && c <= 0
+	}
+	return false
+}
+
+// functionExpr matches string fields with a function, e.g.
+// "contains(name, 'ada')"
+type functionExpr struct {
+	name  string
+	path  []string
+	value string
+}
+
+func (e *functionExpr) match(item any) bool {
+	field, _ := lookupField(item, e.path)
+	s, ok := field.(string)
+	if !ok {
+		return false
+	}
+
+	switch e.name {
+	case "contains":
+		return strings.Contains(s, e.value)
+	case "startswith":
+		return strings.HasPrefix(s, e.value)
+	case "endswith":
+		return strings.HasSuffix(s, e.value)
+	}
+	return false
+}
+
+// filterFunctions are the functions filter expressions can call
+var filterFunctions = []string{"contains", "startswith", "endswith"}
+
+// filterOperators are the comparison operators of filter expressions
+var filterOperators = []string{"eq", "ne", "gt", "ge", "lt", "le"}
+
+// filterToken is a token of a filter expression
+type filterToken struct {
+	text   string
+	quoted bool // A quoted string literal
+}
+
+// tokenizeFilter splits a filter expression into words, quoted strings,
+// parentheses and commas
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+
+		case c == '\'':
+			// Quotes are escaped by doubling them, as in 'O''Brien'
+			var b strings.Builder
+			for i++; ; i++ {
+				if i >= len(input) {
+					return nil, errors.New("unterminated string literal")
+				}
+				if input[i] == '\'' {
+					if i+1 < len(input) && input[i+1] == '\'' {
+						b.WriteByte('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(input[i])
+			}
+			tokens = append(tokens, filterToken{text: b.String(), quoted: true})
+
+		default:
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t(),'", rune(input[i])) {
+				i++
+			}
+			tokens = append(tokens, filterToken{text: input[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser parses filter expressions, an OData subset:
+//
+//	expr       = and ("or" and)*
+//	and        = unary ("and" unary)*
+//	unary      = "not" unary | "(" expr ")" | comparison | function
+//	comparison = field ("eq" | "ne" | "gt" | "ge" | "lt" | "le") literal
+//	function   = ("contains" | "startswith" | "endswith") "(" field "," string ")"
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+// parseFilter parses a filter expression
+func parseFilter(input string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+// peekKeyword reports whether the next token is the given keyword
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+// next returns the next token, failing at the end of the expression
+func (p *filterParser) next(expected string) (filterToken, error) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, fmt.Errorf("expected %s, got end of expression", expected)
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+// expect consumes a punctuation token
+func (p *filterParser) expect(text string) error {
+	token, err := p.next(fmt.Sprintf("%q", text))
+	if err != nil {
+		return err
+	}
+	if token.quoted || token.text != text {
+		return fmt.Errorf("expected %q, got %q", text, token.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: expr}, nil
+	}
+
+	if p.peekKeyword("(") {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	token, err := p.next("a field or function")
+	if err != nil {
+		return nil, err
+	}
+	if token.quoted || token.text == ")" || token.text == "," {
+		return nil, fmt.Errorf("expected a field or function, got %q", token.text)
+	}
+
+	if name := strings.ToLower(token.text); p.peekKeyword("(") {
+		return p.parseFunction(name)
+	}
+	return p.parseComparison(token.text)
+}
+
+func (p *filterParser) parseFunction(name string) (filterExpr, error) {
+	if !slices.Contains(filterFunctions, name) {
+		return nil, fmt.Errorf("unknown function %q, supported functions: %s", name, strings.Join(filterFunctions, ", "))
+	}
+	p.pos++ // The opening parenthesis
+
+	field, err := p.next("a field")
+	if err != nil {
+		return nil, err
+	}
+	if field.quoted {
+		return nil, fmt.Errorf("expected a field, got '%s'", field.text)
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+
+	value, err := p.next("a string")
+	if err != nil {
+		return nil, err
+	}
+	if !value.quoted {
+		return nil, fmt.Errorf("%s expects a quoted string, got %q", name, value.text)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return &functionExpr{name: name, path: fieldPath(field.text), value: value.text}, nil
+}
+
+func (p *filterParser) parseComparison(field string) (filterExpr, error) {
+	op, err := p.next("an operator")
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(op.text)
+	if op.quoted || !slices.Contains(filterOperators, name) {
+		return nil, fmt.Errorf("unknown operator %q, supported operators: %s", op.text, strings.Join(filterOperators, ", "))
+	}
+
+	literal, err := p.next("a value")
+	if err != nil {
+		return nil, err
+	}
+	if !literal.quoted && (literal.text == "(" || literal.text == ")" || literal.text == ",") {
+		return nil, fmt.Errorf("expected a value, got %q", literal.text)
+	}
+
+	return &compareExpr{path: fieldPath(field), op: name, value: literalValue(literal)}, nil
+}
+
+// literalValue returns the value of a literal. Unquoted values that aren't
+// numbers, booleans or null, such as dates, are strings.
+func literalValue(token filterToken) any {
+	if token.quoted {
+		return token.text
+	}
+
+	switch strings.ToLower(token.text) {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if n, err := strconv.ParseFloat(token.text, 64); err == nil {
+		return n
+	}
+	return token.text
+}
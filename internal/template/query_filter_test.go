@@ -0,0 +1,86 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	item := map[string]any{
+		"name":    "Ada O'Brien",
+		"age":     float64(36),
+		"active":  true,
+		"manager": nil,
+		"joined":  "2021-04-01T00:00:00Z",
+		"address": map[string]any{"city": "London"},
+		"level":   3, // Numbers built in templates are ints
+	}
+
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{"age eq 36", true},
+		{"age ne 36", false},
+		{"age gt 30 and age lt 40", true},
+		{"age ge 37 or active eq true", true},
+		{"not (age le 36)", false},
+		{"name eq 'Ada O''Brien'", true},
+		{"Name eq 'Ada O''Brien'", false},
+		{"active eq false", false},
+		{"manager eq null", true},
+		{"missing eq null", true},
+		{"missing ne 'x'", true},
+		{"joined gt 2021-01-01T00:00:00Z", true},
+		{"address/city eq 'London'", true},
+		{"address.city eq 'Paris'", false},
+		{"level ge 3", true},
+		{"age gt 'thirty'", false},
+		{"contains(name, 'Bri')", true},
+		{"startswith(name, 'Ada') and endswith(name, 'Brien')", true},
+		{"CONTAINS(address/city, 'on') AND NOT contains(name, 'Grace')", true},
+		{"contains(age, '3')", false},
+		{"(age eq 1 or age eq 36) and (active eq true)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			expr, err := parseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := expr.match(item); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		filter      string
+		errContains string
+	}{
+		{"name eq 'Ada", "unterminated string literal"},
+		{"name", "expected an operator, got end of expression"},
+		{"name is 'Ada'", `unknown operator "is"`},
+		{"name eq", "expected a value"},
+		{"name eq )", `expected a value, got ")"`},
+		{"(age eq 1", `expected ")"`},
+		{"age eq 1 age eq 2", `unexpected "age"`},
+		{"'name' eq 'Ada'", "expected a field or function"},
+		{"length(name) eq 3", `unknown function "length"`},
+		{"contains(name, Ada)", "contains expects a quoted string"},
+		{"contains(name 'Ada')", `expected ","`},
+		{"age eq 1 and", "expected a field or function, got end of expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := parseFilter(tt.filter)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
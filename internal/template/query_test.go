@@ -0,0 +1,149 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func queryTestItems() []any {
+	return []any{
+		map[string]any{"name": "Ada", "age": float64(36), "team": "core"},
+		map[string]any{"name": "Grace", "age": float64(45), "team": "compilers"},
+		map[string]any{"name": "Linus", "age": float64(28), "team": "core"},
+		map[string]any{"name": "Barbara", "team": "core"},
+		map[string]any{"name": "Ken", "age": float64(45), "team": "unix"},
+	}
+}
+
+// queryNames returns the names of the items of a query result
+func queryNames(result *QueryResult) string {
+	names := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		names = append(names, item.(map[string]any)["name"].(string))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestParseQueryOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		total    int
+		hasMore  bool
+	}{
+		{name: "no options", query: "", expected: "Ada,Grace,Linus,Barbara,Ken", total: 5},
+		{name: "OData filter", query: "$filter=team eq 'core'", expected: "Ada,Linus,Barbara", total: 3},
+		{name: "generic filter", query: "filter=age ge 36", expected: "Ada,Grace,Ken", total: 3},
+		{name: "OData order", query: "$orderby=age desc,name", expected: "Grace,Ken,Ada,Linus,Barbara", total: 5},
+		{name: "generic sort", query: "sort=-age,-name", expected: "Ken,Grace,Ada,Linus,Barbara", total: 5},
+		{name: "missing values first", query: "$orderby=age asc", expected: "Barbara,Linus,Ada,Grace,Ken", total: 5},
+		{name: "OData paging", query: "$orderby=name&$top=2&$skip=1", expected: "Barbara,Grace", total: 5, hasMore: true},
+		{name: "generic paging", query: "sort=name&limit=2&offset=3", expected: "Ken,Linus", total: 5},
+		{name: "top zero", query: "$top=0", expected: "", total: 5, hasMore: true},
+		{name: "skip past the end", query: "$skip=10", expected: "", total: 5},
+		{name: "OData options take precedence", query: "$top=1&limit=3", expected: "Ada", total: 5, hasMore: true},
+		{name: "filter and page", query: "$filter=team eq 'core'&$orderby=name&$top=2", expected: "Ada,Barbara", total: 3, hasMore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("Invalid query: %v", err)
+			}
+
+			result, err := applyQuery(query, queryTestItems())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := queryNames(result); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			if result.Total != tt.total || result.HasMore != tt.hasMore {
+				t.Errorf("Expected total %d and more %v, got %d and %v", tt.total, tt.hasMore, result.Total, result.HasMore)
+			}
+		})
+	}
+}
+
+func TestParseQueryOptions_Errors(t *testing.T) {
+	tests := []struct {
+		query       string
+		errContains string
+	}{
+		{"$filter=name is 'Ada'", `invalid $filter: unknown operator "is"`},
+		{"filter=(age", `invalid filter: expected an operator`},
+		{"$orderby=name up", `invalid $orderby: invalid field "name up"`},
+		{"sort=name desc", `invalid sort: invalid field "name desc"`},
+		{"$orderby=name,,age", "invalid $orderby: empty field"},
+		{"$top=-1", `invalid $top: must be a non-negative integer, got "-1"`},
+		{"offset=ten", `invalid offset: must be a non-negative integer, got "ten"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			_, err := ParseQueryOptions(query)
+
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected a QueryError containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestQueryOptions_Apply(t *testing.T) {
+	opts, err := ParseQueryOptions(url.Values{"$filter": {"size gt 1"}, "$orderby": {"size desc"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Filter != "size gt 1" || len(opts.OrderBy) != 1 || !opts.OrderBy[0].Desc || opts.Top != -1 {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	// Any list of maps can be queried, including ones built in templates
+	items := []map[string]int{{"size": 1}, {"size": 3}, {"size": 2}}
+	result, err := opts.Apply(items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].(map[string]int)["size"] != 3 {
+		t.Errorf("Unexpected items: %v", result.Items)
+	}
+
+	if result, err := opts.Apply(nil); err != nil || len(result.Items) != 0 {
+		t.Errorf("Expected no items from nil, got %v, %v", result, err)
+	}
+	if _, err := opts.Apply("not a list"); err == nil {
+		t.Error("Expected an error applying options to a string")
+	}
+}
+
+func TestApplyQuery_Template(t *testing.T) {
+	engine := NewEngine()
+	tmpl, err := engine.CompileInlineTemplate("query", `{{ $items := list (dict "id" 1) (dict "id" 3) (dict "id" 2) }}{{ $page := applyQuery .Query $items }}{{ $page.Total }} {{ $page.Items | toJson }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	ctx := &TemplateContext{Query: url.Values{"$filter": {"id ge 2"}, "$orderby": {"id"}}}
+	if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if got := buf.String(); got != `2 [{"id":2},{"id":3}]` {
+		t.Errorf("Unexpected output: %q", got)
+	}
+
+	// Invalid options sent by clients can be told apart from template errors
+	ctx.Query = url.Values{"$top": {"many"}}
+	var queryErr *QueryError
+	if err := engine.ExecuteTemplate(tmpl, &bytes.Buffer{}, ctx); !errors.As(err, &queryErr) || queryErr.Option != "$top" {
+		t.Errorf("Expected a QueryError for $top, got %v", err)
+	}
+}
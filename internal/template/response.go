@@ -0,0 +1,64 @@
+package template
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Response lets a template influence the HTTP response it is rendering,
+// such as choosing the status code based on request data or setting cookies
+type Response struct {
+	mu          sync.Mutex
+	status      int
+	cookies     []*http.Cookie
+	contentType string
+}
+
+// NewResponse creates a Response with no overrides
+func NewResponse() *Response {
+	return &Response{}
+}
+
+// SetStatus overrides the response status code.
+// Usage in templates: {{ .Response.SetStatus 404 }}
+// Returns an empty string so it doesn't affect template output.
+func (r *Response) SetStatus(code int) (string, error) {
+	if code < 100 || code > 599 {
+		return "", fmt.Errorf("invalid HTTP status code %d, must be between 100 and 599", code)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status = code
+	return "", nil
+}
+
+// StatusOr returns the status code set by the template, or fallback if none was set
+func (r *Response) StatusOr(fallback int) int {
+	if r == nil {
+		return fallback
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status == 0 {
+		return fallback
+	}
+	return r.status
+}
+
+// ContentType returns the content type chosen by the template, or an empty
+// string if it didn't choose one
+func (r *Response) ContentType() string {
+	if r == nil {
+		return ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.contentType
+}
@@ -0,0 +1,87 @@
+package template
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestResponse_SetStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		wantErr  bool
+		expected int
+	}{
+		{name: "not found", code: 404, wantErr: false, expected: 404},
+		{name: "created", code: 201, wantErr: false, expected: 201},
+		{name: "lowest valid", code: 100, wantErr: false, expected: 100},
+		{name: "highest valid", code: 599, wantErr: false, expected: 599},
+		{name: "too low", code: 99, wantErr: true, expected: 200},
+		{name: "too high", code: 600, wantErr: true, expected: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewResponse()
+
+			out, err := resp.SetStatus(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetStatus(%d) error = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+			if out != "" {
+				t.Errorf("SetStatus(%d) should return empty string, got %q", tt.code, out)
+			}
+			if got := resp.StatusOr(http.StatusOK); got != tt.expected {
+				t.Errorf("StatusOr() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResponse_StatusOrNil(t *testing.T) {
+	var resp *Response
+	if got := resp.StatusOr(http.StatusTeapot); got != http.StatusTeapot {
+		t.Errorf("StatusOr() on nil Response = %d, expected %d", got, http.StatusTeapot)
+	}
+}
+
+func TestResponse_SetStatusInTemplate(t *testing.T) {
+	engine := NewEngine()
+
+	tmpl, err := engine.CompileInlineTemplate("status", `{{ if eq .Params.id "999" }}{{ .Response.SetStatus 404 }}not found{{ else }}found{{ end }}`)
+	if err != nil {
+		t.Fatalf("Failed to compile template: %v", err)
+	}
+
+	tests := []struct {
+		id             string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{id: "1", expectedStatus: http.StatusOK, expectedBody: "found"},
+		{id: "999", expectedStatus: http.StatusNotFound, expectedBody: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/users/"+tt.id, nil)
+			ctx, err := NewTemplateContext(req, map[string]string{"id": tt.id})
+			if err != nil {
+				t.Fatalf("Failed to build context: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := engine.ExecuteTemplate(tmpl, &buf, ctx); err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+
+			if buf.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, buf.String())
+			}
+			if got := ctx.Response.StatusOr(http.StatusOK); got != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package template
+
+import "crypto/tls"
+
+// TLSInfo describes the TLS connection a request was sent over
+type TLSInfo struct {
+	ServerName  string `json:"server_name"`  // Server name the client asked for through SNI, empty when it sent none
+	ALPN        string `json:"alpn"`         // Protocol negotiated through ALPN, e.g. "h2", empty when none was
+	CipherSuite string `json:"cipher_suite"` // Name of the negotiated cipher suite, e.g. "TLS_AES_128_GCM_SHA256"
+	Version     string `json:"version"`      // Negotiated TLS version, e.g. "TLS 1.3"
+	Resumed     bool   `json:"resumed"`      // Whether the session was resumed from an earlier connection
+}
+
+// NewTLSInfo describes the TLS connection of a request, returning nil when
+// the request wasn't sent over TLS
+func NewTLSInfo(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	return &TLSInfo{
+		ServerName:  state.ServerName,
+		ALPN:        state.NegotiatedProtocol,
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		Version:     tls.VersionName(state.Version),
+		Resumed:     state.DidResume,
+	}
+}
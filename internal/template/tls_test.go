@@ -0,0 +1,31 @@
+package template
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestNewTLSInfo(t *testing.T) {
+	if info := NewTLSInfo(nil); info != nil {
+		t.Errorf("Expected no TLS info without TLS, got %+v", info)
+	}
+
+	info := NewTLSInfo(&tls.ConnectionState{
+		ServerName:         "api.example.com",
+		NegotiatedProtocol: "h2",
+		CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+		Version:            tls.VersionTLS13,
+		DidResume:          true,
+	})
+
+	want := TLSInfo{
+		ServerName:  "api.example.com",
+		ALPN:        "h2",
+		CipherSuite: "TLS_AES_128_GCM_SHA256",
+		Version:     "TLS 1.3",
+		Resumed:     true,
+	}
+	if info == nil || *info != want {
+		t.Errorf("Expected %+v, got %+v", want, info)
+	}
+}
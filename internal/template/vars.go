@@ -0,0 +1,25 @@
+package template
+
+import (
+	"net/http"
+	"strings"
+)
+
+// VarHeaderPrefix is the prefix of request headers whose values become
+// template variables, e.g. "X-Mockingjay-Var-Plan: pro" becomes .Vars.Plan
+const VarHeaderPrefix = "X-Mockingjay-Var-"
+
+// HeaderVars returns the template variables injected through request
+// headers, keyed by the canonical header name without VarHeaderPrefix. When
+// a header is repeated, its first value wins.
+func HeaderVars(headers http.Header) map[string]string {
+	vars := make(map[string]string)
+	for name, values := range headers {
+		key, found := strings.CutPrefix(http.CanonicalHeaderKey(name), VarHeaderPrefix)
+		if !found || key == "" || len(values) == 0 {
+			continue
+		}
+		vars[key] = values[0]
+	}
+	return vars
+}
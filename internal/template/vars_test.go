@@ -0,0 +1,37 @@
+package template
+
+import (
+	"maps"
+	"net/http"
+	"testing"
+)
+
+func TestHeaderVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  http.Header
+		expected map[string]string
+	}{
+		{name: "no headers", headers: http.Header{}, expected: map[string]string{}},
+		{
+			name: "variables among other headers",
+			headers: http.Header{
+				"X-Mockingjay-Var-Plan":    {"pro"},
+				"X-Mockingjay-Var-User-Id": {"42"},
+				"Content-Type":             {"application/json"},
+			},
+			expected: map[string]string{"Plan": "pro", "User-Id": "42"},
+		},
+		{name: "names are canonicalized", headers: http.Header{"x-mockingjay-var-region": {"eu"}}, expected: map[string]string{"Region": "eu"}},
+		{name: "first value wins", headers: http.Header{"X-Mockingjay-Var-Plan": {"pro", "free"}}, expected: map[string]string{"Plan": "pro"}},
+		{name: "prefix without a name", headers: http.Header{"X-Mockingjay-Var-": {"ignored"}}, expected: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeaderVars(tt.headers); !maps.Equal(got, tt.expected) {
+				t.Errorf("HeaderVars() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
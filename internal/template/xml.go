@@ -0,0 +1,278 @@
+package template
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// XML documents are turned into maps that templates navigate like parsed
+// JSON. Elements are keyed by their local name, without namespace prefixes,
+// and elements with neither attributes nor children become their text.
+// Otherwise, attributes are keyed with a leading "@", text is kept under
+// "#text", and repeated child elements become a list.
+const (
+	xmlAttrPrefix = "@"
+	xmlTextKey    = "#text"
+)
+
+// xmlElement is an element read while parsing an XML document
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlElement
+	text     strings.Builder
+}
+
+// parseXML parses an XML document into a map holding its root element
+func parseXML(data []byte) (map[string]any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root *xmlElement
+	var stack []*xmlElement
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: token.Name.Local, attrs: token.Attr}
+			switch {
+			case len(stack) > 0:
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+			case root != nil:
+				return nil, fmt.Errorf("XML documents must have a single root element, found %q after %q", element.name, root.name)
+			default:
+				root = element
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(token)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("XML document has no root element")
+	}
+	return map[string]any{root.name: root.value()}, nil
+}
+
+// value returns the template value of an element
+func (e *xmlElement) value() any {
+	text := strings.TrimSpace(e.text.String())
+
+	var attrs []xml.Attr
+	for _, attr := range e.attrs {
+		// Namespace declarations aren't data
+		if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+			attrs = append(attrs, attr)
+		}
+	}
+	if len(attrs) == 0 && len(e.children) == 0 {
+		return text
+	}
+
+	counts := make(map[string]int, len(e.children))
+	for _, child := range e.children {
+		counts[child.name]++
+	}
+
+	value := make(map[string]any, len(attrs)+len(counts)+1)
+	for _, attr := range attrs {
+		value[xmlAttrPrefix+attr.Name.Local] = attr.Value
+	}
+	for _, child := range e.children {
+		if counts[child.name] > 1 {
+			list, _ := value[child.name].([]any)
+			value[child.name] = append(list, child.value())
+			continue
+		}
+		value[child.name] = child.value()
+	}
+	if text != "" {
+		value[xmlTextKey] = text
+	}
+	return value
+}
+
+// isXMLContentType checks if the content type indicates XML
+func isXMLContentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	return strings.Contains(contentType, "application/xml") ||
+		strings.Contains(contentType, "text/xml") ||
+		strings.Contains(contentType, "+xml")
+}
+
+// fromXml parses an XML document into a map, like XML request bodies
+// Usage in templates: {{ $doc := fromXml .RawBody }}{{ $doc.order.id }}
+func fromXml(document string) (map[string]any, error) {
+	return parseXML([]byte(document))
+}
+
+// xmlPath returns the value at a slash-separated path of element names in an
+// XML document, given as a string or as parsed by fromXml. Numeric segments
+// index repeated elements, other segments read the first of them, and a
+// final "@name" segment reads an attribute. Missing paths return nil.
+// Usage in templates: {{ xmlPath .Body "Envelope/Body/GetUser/id" }} or {{ xmlPath .RawBody "orders/order/1/@id" }}
+func xmlPath(document any, path string) (any, error) {
+	current := document
+	if text, ok := document.(string); ok {
+		parsed, err := parseXML([]byte(text))
+		if err != nil {
+			return nil, err
+		}
+		current = parsed
+	}
+
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+
+		index, err := strconv.Atoi(segment)
+		isIndex := err == nil
+		switch value := current.(type) {
+		case []any:
+			if len(value) == 0 {
+				return nil, nil
+			}
+			if !isIndex {
+				current = value[0]
+				break
+			}
+			if index < 0 || index >= len(value) {
+				return nil, nil
+			}
+			current = value[index]
+			continue
+		default:
+			// A single element is the first of its kind
+			if isIndex {
+				if index != 0 {
+					return nil, nil
+				}
+				continue
+			}
+		}
+
+		element, ok := current.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		current = element[segment]
+	}
+	return current, nil
+}
+
+// toXml renders a value as XML, the reverse of fromXml: map keys become
+// elements, or attributes when they start with "@", lists repeat their
+// element, and other values become escaped text. Keys are written in
+// alphabetical order, except "#text", which goes first.
+// Usage in templates: {{ toXml .Body }} or {{ dict "user" (dict "@id" 1 "name" "Ada") | toXml }}
+func toXml(v any) (string, error) {
+	var buf bytes.Buffer
+	if err := writeXML(&buf, "", v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeXML writes v as the content of an element named name, or as a
+// fragment of elements when name is empty
+func writeXML(buf *bytes.Buffer, name string, v any) error {
+	if list, ok := v.([]any); ok && name != "" {
+		for _, item := range list {
+			if err := writeXML(buf, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	element, isMap := v.(map[string]any)
+	if name != "" {
+		if err := checkXMLName(name); err != nil {
+			return err
+		}
+		buf.WriteString("<" + name)
+		if isMap {
+			for _, key := range sortedKeys(element) {
+				if attr, ok := strings.CutPrefix(key, xmlAttrPrefix); ok {
+					if err := checkXMLName(attr); err != nil {
+						return err
+					}
+					buf.WriteString(" " + attr + `="`)
+					xml.EscapeText(buf, []byte(fmt.Sprint(element[key])))
+					buf.WriteString(`"`)
+				}
+			}
+		}
+		buf.WriteString(">")
+	}
+
+	switch {
+	case isMap:
+		if text, ok := element[xmlTextKey]; ok {
+			xml.EscapeText(buf, []byte(fmt.Sprint(text)))
+		}
+		for _, key := range sortedKeys(element) {
+			if key == xmlTextKey || strings.HasPrefix(key, xmlAttrPrefix) {
+				continue
+			}
+			if err := writeXML(buf, key, element[key]); err != nil {
+				return err
+			}
+		}
+	case v == nil:
+	case name == "":
+		return fmt.Errorf("toXml needs a map of elements, got %T", v)
+	default:
+		xml.EscapeText(buf, []byte(fmt.Sprint(v)))
+	}
+
+	if name != "" {
+		buf.WriteString("</" + name + ">")
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in alphabetical order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// checkXMLName verifies that name can be used as an element or attribute name
+func checkXMLName(name string) error {
+	for i, r := range name {
+		valid := r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 0x7f ||
+			(i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9')))
+		if !valid {
+			return fmt.Errorf("invalid XML name %q", name)
+		}
+	}
+	if name == "" {
+		return errors.New("XML names cannot be empty")
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package template
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const soapRequest = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetOrders xmlns="urn:shop">
+      <customer id="42">Ada</customer>
+      <order id="1"><total>9.99</total></order>
+      <order id="2"><total>20</total></order>
+    </GetOrders>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestParseXML(t *testing.T) {
+	got, err := parseXML([]byte(soapRequest))
+	if err != nil {
+		t.Fatalf("parseXML() error = %v", err)
+	}
+
+	want := map[string]any{
+		"Envelope": map[string]any{
+			"Body": map[string]any{
+				"GetOrders": map[string]any{
+					"customer": map[string]any{"@id": "42", "#text": "Ada"},
+					"order": []any{
+						map[string]any{"@id": "1", "total": "9.99"},
+						map[string]any{"@id": "2", "total": "20"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseXML() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseXML_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		document    string
+		errContains string
+	}{
+		{name: "empty", document: "", errContains: "no root element"},
+		{name: "unclosed", document: "<a><b></a>", errContains: "element <b> closed by </a>"},
+		{name: "several roots", document: "<a/><b/>", errContains: "single root element"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseXML([]byte(tt.document))
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("parseXML() error = %v, want one containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestParseRequestBody_XML(t *testing.T) {
+	for _, contentType := range []string{"application/xml", "text/xml; charset=utf-8", "application/soap+xml"} {
+		req, _ := http.NewRequest("POST", "/soap", strings.NewReader(soapRequest))
+		req.Header.Set("Content-Type", contentType)
+
+		result, _, err := parseRequestBody(req)
+		if err != nil {
+			t.Fatalf("parseRequestBody() error = %v", err)
+		}
+		if got, _ := xmlPath(result, "Envelope/Body/GetOrders/customer/@id"); got != "42" {
+			t.Errorf("Expected the %s body to be parsed, got %v", contentType, result)
+		}
+	}
+
+	// Invalid documents are kept along with the error, like invalid JSON
+	req, _ := http.NewRequest("POST", "/soap", strings.NewReader("<broken>"))
+	req.Header.Set("Content-Type", "application/xml")
+	result, _, err := parseRequestBody(req)
+	if err != nil {
+		t.Fatalf("parseRequestBody() error = %v", err)
+	}
+	body, ok := result.(map[string]interface{})
+	if !ok || body["raw"] != "<broken>" || body["parse_error"] == nil {
+		t.Errorf("Expected the raw body and parse error, got %v", result)
+	}
+}
+
+func TestXmlPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want any
+	}{
+		{path: "Envelope/Body/GetOrders/customer/#text", want: "Ada"},
+		{path: "/Envelope/Body/GetOrders/customer/@id", want: "42"},
+		{path: "Envelope/Body/GetOrders/order/total", want: "9.99"},
+		{path: "Envelope/Body/GetOrders/order/1/@id", want: "2"},
+		{path: "Envelope/Body/GetOrders/customer/0/@id", want: "42"},
+		{path: "Envelope/Body/GetOrders/order/5", want: nil},
+		{path: "Envelope/Body/Missing/id", want: nil},
+		{path: "Envelope/Body/GetOrders/customer/1", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := xmlPath(soapRequest, tt.path)
+			if err != nil {
+				t.Fatalf("xmlPath() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("xmlPath() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := xmlPath("<broken>", "a"); err == nil {
+		t.Error("Expected an error for an invalid document")
+	}
+}
+
+func TestToXml(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       any
+		want        string
+		errContains string
+	}{
+		{
+			name:  "elements, attributes and text",
+			input: map[string]any{"user": map[string]any{"@id": 1, "name": "Ada & co", "#text": "x"}},
+			want:  `<user id="1">x<name>Ada &amp; co</name></user>`,
+		},
+		{
+			name:  "repeated elements",
+			input: map[string]any{"orders": map[string]any{"order": []any{"a", "b"}}},
+			want:  `<orders><order>a</order><order>b</order></orders>`,
+		},
+		{
+			name:  "empty element",
+			input: map[string]any{"empty": nil},
+			want:  `<empty></empty>`,
+		},
+		{
+			name:        "not a map",
+			input:       "text",
+			errContains: "needs a map of elements",
+		},
+		{
+			name:        "invalid name",
+			input:       map[string]any{"1st": "x"},
+			errContains: `invalid XML name "1st"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toXml(tt.input)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("toXml() error = %v, want one containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("toXml() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	// Parsed documents render back, without namespaces
+	parsed, err := fromXml(`<order id="7"><item>a</item><item>b</item></order>`)
+	if err != nil {
+		t.Fatalf("fromXml() error = %v", err)
+	}
+	if got, _ := toXml(parsed); got != `<order id="7"><item>a</item><item>b</item></order>` {
+		t.Errorf("Expected the document to render back, got %q", got)
+	}
+}
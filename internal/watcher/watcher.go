@@ -0,0 +1,239 @@
+// Package watcher reloads the configuration when the files backing it change.
+//
+// Files are followed by watching their parent directories rather than the files
+// themselves, because many editors save by writing a new file and renaming it
+// over the old one, which silently ends a watch on the old file. Bursts of
+// events, like those of a single save, are collapsed into one reload.
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/patrickdappollonio/mockingjay/internal/config"
+)
+
+// DefaultDebounce is how long the watcher waits for events to settle before
+// reloading
+const DefaultDebounce = 100 * time.Millisecond
+
+// Reloader is the configuration the watcher keeps up to date
+type Reloader interface {
+	// ConfigFiles returns the files and directories the configuration is loaded from
+	ConfigFiles() []string
+
+	// ReloadConfig loads the configuration again
+	ReloadConfig() error
+}
+
+// Watcher reloads a Reloader when its configuration files change
+type Watcher struct {
+	fs       *fsnotify.Watcher
+	target   Reloader
+	logger   *slog.Logger
+	debounce time.Duration
+
+	mu      sync.Mutex      // Protects files and dirs, which only Run changes, from Files
+	files   map[string]bool // Files the configuration depends on
+	dirs    map[string]bool // Configuration directories, whose YAML files are all loaded
+	watched map[string]bool // Directories registered with fsnotify
+}
+
+// New creates a watcher following the configuration files of target
+func New(target Reloader, logger *slog.Logger, debounce time.Duration) (*Watcher, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		fs:       fs,
+		target:   target,
+		logger:   logger,
+		debounce: debounce,
+		files:    make(map[string]bool),
+		dirs:     make(map[string]bool),
+		watched:  make(map[string]bool),
+	}
+
+	if err := w.sync(); err != nil {
+		_ = fs.Close() // Error ignored - returning original error is more important
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Files returns the files and directories being followed, sorted
+func (w *Watcher) Files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	files := make([]string, 0, len(w.files)+len(w.dirs))
+	for file := range w.files {
+		files = append(files, file)
+	}
+	for dir := range w.dirs {
+		files = append(files, dir)
+	}
+	slices.Sort(files)
+	return files
+}
+
+// sync follows the current configuration files of the target, watching the
+// directories needed for new ones and dropping those no longer needed
+func (w *Watcher) sync() error {
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	needed := make(map[string]bool)
+
+	for _, path := range w.target.ConfigFiles() {
+		path = filepath.Clean(path)
+
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs[path] = true
+			needed[path] = true
+			continue
+		}
+
+		files[path] = true
+		needed[filepath.Dir(path)] = true
+	}
+
+	var errs []error
+	for dir := range needed {
+		if w.watched[dir] {
+			continue
+		}
+		if err := w.fs.Add(dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to watch config directory %q: %w", dir, err))
+			continue
+		}
+		w.watched[dir] = true
+	}
+
+	for dir := range w.watched {
+		if !needed[dir] {
+			_ = w.fs.Remove(dir) // Fails when the directory was deleted, which already ended the watch
+			delete(w.watched, dir)
+		}
+	}
+
+	w.mu.Lock()
+	w.files = files
+	w.dirs = dirs
+	w.mu.Unlock()
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// relevant reports whether an event affects the configuration
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	name := filepath.Clean(event.Name)
+	if w.files[name] {
+		return true
+	}
+
+	// Files and subdirectories added to or removed from configuration directories
+	if w.dirs[filepath.Dir(name)] {
+		if config.IsConfigFile(name) {
+			return true
+		}
+		if event.Has(fsnotify.Create) {
+			info, err := os.Stat(name)
+			return err == nil && info.IsDir()
+		}
+		return w.dirs[name] // A subdirectory that was removed or renamed
+	}
+
+	return false
+}
+
+// Run reloads the configuration whenever its files change, until ctx is done
+func (w *Watcher) Run(ctx context.Context) {
+	defer func() {
+		if err := w.fs.Close(); err != nil {
+			w.logger.Error("failed to close config watcher", "error", err)
+		}
+	}()
+
+	w.logger.Info("config file watcher started", "files", w.Files())
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+	var changed []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			w.logger.Debug("config watcher stopping due to context cancellation")
+			return
+
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				w.logger.Debug("config watcher events channel closed")
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+
+			w.logger.Debug("config file event", "file", event.Name, "op", event.Op.String())
+			if !slices.Contains(changed, event.Name) {
+				changed = append(changed, event.Name)
+			}
+
+			// Wait for the burst of events to settle before reloading
+			if timer == nil {
+				timer = time.NewTimer(w.debounce)
+				fire = timer.C
+			} else {
+				timer.Reset(w.debounce)
+			}
+
+		case <-fire:
+			timer, fire = nil, nil
+			w.logger.Info("config file changed, reloading", "files", changed)
+			changed = nil
+
+			if err := w.target.ReloadConfig(); err != nil {
+				w.logger.Error("failed to reload config", "error", err)
+			}
+
+			// Follow files that were added to or dropped from the configuration, even
+			// when the reload failed, so fixing the failure is noticed
+			if err := w.sync(); err != nil {
+				w.logger.Error("failed to update config watcher", "error", err)
+			}
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				w.logger.Debug("config watcher errors channel closed")
+				return
+			}
+			w.logger.Error("config file watcher error", "error", err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package watcher
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testDebounce = 50 * time.Millisecond
+
+// fakeReloader records reloads and serves a configurable list of files
+type fakeReloader struct {
+	mu      sync.Mutex
+	files   []string
+	reloads chan struct{}
+}
+
+func newFakeReloader(files ...string) *fakeReloader {
+	return &fakeReloader{files: files, reloads: make(chan struct{}, 100)}
+}
+
+func (f *fakeReloader) ConfigFiles() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.files)
+}
+
+func (f *fakeReloader) ReloadConfig() error {
+	f.reloads <- struct{}{}
+	return nil
+}
+
+func (f *fakeReloader) setFiles(files ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files = files
+}
+
+// startWatcher runs a watcher for target until the test ends
+func startWatcher(t *testing.T, target Reloader) *Watcher {
+	t.Helper()
+
+	w, err := New(target, slog.New(slog.NewTextHandler(io.Discard, nil)), testDebounce)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return w
+}
+
+// expectReloads waits for the watcher to settle and checks how many reloads happened
+func expectReloads(t *testing.T, f *fakeReloader, expected int) {
+	t.Helper()
+
+	deadline := time.After(10 * testDebounce)
+	got := 0
+	for got < expected {
+		select {
+		case <-f.reloads:
+			got++
+		case <-deadline:
+			t.Fatalf("Expected %d reloads, got %d", expected, got)
+		}
+	}
+
+	// No more reloads should follow
+	select {
+	case <-f.reloads:
+		t.Fatalf("Expected %d reloads, got more", expected)
+	case <-time.After(4 * testDebounce):
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %q: %v", path, err)
+	}
+}
+
+func TestWatcher_Write(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "routes: []")
+
+	f := newFakeReloader(file)
+	startWatcher(t, f)
+
+	writeFile(t, file, "routes: [1]")
+	expectReloads(t, f, 1)
+
+	// Unrelated files next to the configuration are ignored
+	writeFile(t, filepath.Join(dir, "notes.yaml"), "hello")
+	expectReloads(t, f, 0)
+}
+
+func TestWatcher_RenameOverFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "routes: []")
+
+	f := newFakeReloader(file)
+	startWatcher(t, f)
+
+	// Save the way many editors do: write a temporary file and rename it over the original
+	for i := range 2 {
+		tmp := filepath.Join(dir, ".config.yaml.swp")
+		writeFile(t, tmp, "routes: []")
+		if err := os.Rename(tmp, file); err != nil {
+			t.Fatalf("Failed to rename: %v", err)
+		}
+		expectReloads(t, f, 1)
+
+		// The file keeps being followed after its inode changed
+		writeFile(t, file, "routes: ["+string(rune('a'+i))+"]")
+		expectReloads(t, f, 1)
+	}
+}
+
+func TestWatcher_RemoveAndCreate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "routes: []")
+
+	f := newFakeReloader(file)
+	startWatcher(t, f)
+
+	if err := os.Remove(file); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	writeFile(t, file, "routes: [1]")
+	expectReloads(t, f, 1)
+}
+
+func TestWatcher_Debounce(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "routes: []")
+
+	f := newFakeReloader(file)
+	startWatcher(t, f)
+
+	for range 10 {
+		writeFile(t, file, "routes: []")
+		time.Sleep(testDebounce / 10)
+	}
+	expectReloads(t, f, 1)
+}
+
+func TestWatcher_ConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "users.yaml"), "routes: []")
+
+	f := newFakeReloader(dir)
+	startWatcher(t, f)
+
+	writeFile(t, filepath.Join(dir, "orders.yml"), "routes: []")
+	expectReloads(t, f, 1)
+
+	// Files that aren't loaded from the directory are ignored
+	writeFile(t, filepath.Join(dir, "README.md"), "docs")
+	writeFile(t, filepath.Join(dir, ".hidden.yaml"), "routes: []")
+	expectReloads(t, f, 0)
+
+	if err := os.Remove(filepath.Join(dir, "orders.yml")); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	expectReloads(t, f, 1)
+}
+
+func TestWatcher_FollowsNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "config.yaml")
+	included := filepath.Join(t.TempDir(), "users.yaml")
+	writeFile(t, main, "routes: []")
+	writeFile(t, included, "routes: []")
+
+	f := newFakeReloader(main)
+	w := startWatcher(t, f)
+
+	// The configuration starts including another file on the next reload
+	f.setFiles(main, included)
+	writeFile(t, main, "include: [users.yaml]")
+	expectReloads(t, f, 1)
+
+	writeFile(t, included, "routes: [1]")
+	expectReloads(t, f, 1)
+
+	// And stops following it once it's no longer included
+	f.setFiles(main)
+	writeFile(t, main, "routes: []")
+	expectReloads(t, f, 1)
+
+	writeFile(t, included, "routes: [2]")
+	expectReloads(t, f, 0)
+
+	if files := w.Files(); !slices.Equal(files, []string{main}) {
+		t.Errorf("Expected files [%s], got %v", main, files)
+	}
+}
+
+func TestNew_MissingDirectory(t *testing.T) {
+	f := newFakeReloader(filepath.Join(t.TempDir(), "missing", "config.yaml"))
+
+	if _, err := New(f, nil, testDebounce); err == nil {
+		t.Error("Expected an error when the configuration's directory doesn't exist")
+	}
+}
@@ -6,13 +6,14 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 
 	"github.com/patrickdappollonio/mockingjay/internal/config"
 	"github.com/patrickdappollonio/mockingjay/internal/server"
+	"github.com/patrickdappollonio/mockingjay/internal/watcher"
 )
 
 // Version is set via goreleaser ldflags
@@ -28,10 +29,12 @@ func main() {
 // createRootCommand builds and configures the root cobra command
 func createRootCommand() *cobra.Command {
 	// CLI flags
-	var configFile string
+	var configFiles []string
 	var port string
 	var debug bool
 	var validateOnly bool
+	var verify bool
+	var strictFiles bool
 
 	cmd := &cobra.Command{
 		Use:           "mockingjay",
@@ -44,64 +47,111 @@ mock API responses using YAML configuration files with Go template support.
 Perfect for testing, development, and prototyping when you need to simulate
 external APIs or services.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return run(configFile, port, debug, validateOnly)
+			return run(configFiles, port, debug, validateOnly, verify, strictFiles)
 		},
 		Version: version,
 	}
 
 	// Define flags with both short and long forms
-	cmd.Flags().StringVarP(&configFile, "config", "c", "config.yaml", "path to configuration file")
+	cmd.Flags().StringArrayVarP(&configFiles, "config", "c", []string{"config.yaml"}, "path to a configuration file or directory, can be repeated")
 	cmd.Flags().StringVarP(&port, "port", "p", "8080", "server port")
 	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "enable debug logging")
 	cmd.Flags().BoolVarP(&validateOnly, "validate", "", false, "validate configuration file and exit")
+	cmd.Flags().BoolVarP(&verify, "verify", "", false, "check route call expectations on shutdown and exit with an error if any is unmet")
+	cmd.Flags().BoolVarP(&strictFiles, "strict-files", "", false, "fail when the config directory has files no route uses or routes use files outside of it")
+
+	cmd.AddCommand(createReportCommand())
+	cmd.AddCommand(createDemoCommand())
+	cmd.AddCommand(createExplainCommand())
 
 	return cmd
 }
 
-func run(configFile, port string, debug, validateOnly bool) error {
-	// Set up structured logging
-	logger := setupLogger(debug)
+func run(configFiles []string, port string, debug, validateOnly, verify, strictFiles bool) error {
+	// Set up structured logging, with a level that can be changed at runtime
+	level := new(slog.LevelVar)
+	logger := setupLogger(level, debug)
 
 	// Load configuration
-	cfg, err := config.LoadConfig(configFile)
+	cfg, err := config.LoadConfigs(configFiles)
 	if err != nil {
-		logger.Error("failed to load configuration", "file", configFile, "error", err)
+		logger.Error("failed to load configuration", "files", configFiles, "error", err)
 		return err
 	}
 
 	logger.Info("configuration loaded successfully",
-		"file", configFile,
+		"files", configFiles,
 		"routes_count", len(cfg.Routes),
 	)
 
+	// Refuse stray files and files outside the configuration tree
+	if strictFiles {
+		if err := cfg.CheckFiles(configFiles); err != nil {
+			logger.Error("configuration files check failed", "files", configFiles, "error", err)
+			return err
+		}
+	}
+
 	// If validation-only mode, exit after successful validation
 	if validateOnly {
 		logger.Info("configuration validation completed successfully")
-		fmt.Printf("✅ Configuration file %q is valid\n", configFile)
+		fmt.Printf("✅ Configuration %q is valid\n", strings.Join(configFiles, ", "))
 		fmt.Printf("   - Found %d routes\n", len(cfg.Routes))
+		if cfg.GRPC != nil {
+			fmt.Printf("   - Found %d gRPC methods\n", len(cfg.GRPC.Methods))
+		}
 		fmt.Printf("   - All templates compiled successfully\n")
+		if strictFiles {
+			fmt.Printf("   - All files in the configuration tree are referenced\n")
+		}
 		fmt.Printf("   - All validation checks passed\n")
 		return nil
 	}
 
 	// Create server
 	addr := ":" + port
-	srv, err := server.NewServer(cfg, configFile, addr, logger, version)
+	srv, err := server.NewServer(cfg, configFiles, addr, logger, version)
 	if err != nil {
 		logger.Error("failed to create server", "error", err)
 		return err
 	}
 
+	srv.SetLogLevelVar(level)
+
+	if err := serve(srv, logger, addr, true); err != nil {
+		return err
+	}
+
+	// Report the route call expectations that weren't met
+	if verify {
+		return verifyExpectations(srv, logger)
+	}
+	return nil
+}
+
+// serve runs srv until an interrupt signal is received, hot-reloading its
+// configuration files when watch is set
+func serve(srv *server.Server, logger *slog.Logger, addr string, watch bool) error {
 	// Create context that cancels on interrupt signals
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Switch between debug and info logs when asked through a signal
+	go toggleDebugOnSignal(ctx, srv)
+
 	// Start config file watcher for hot-reload
-	if err := startConfigWatcher(configFile, srv, logger, ctx); err != nil {
-		logger.Error("failed to start config file watcher", "error", err)
-		return err
+	if watch {
+		w, err := watcher.New(srv, logger, watcher.DefaultDebounce)
+		if err != nil {
+			logger.Error("failed to start config file watcher", "error", err)
+			return err
+		}
+		go w.Run(ctx)
 	}
 
+	// Show operators what's actually running, before the logs start
+	fmt.Print(srv.ConfigSummary().Banner())
+
 	// Start server
 	logger.Info("starting mockingjay server", "version", version, "addr", addr)
 	if err := srv.Start(ctx); err != nil {
@@ -113,11 +163,37 @@ func run(configFile, port string, debug, validateOnly bool) error {
 	return nil
 }
 
-// setupLogger configures structured logging based on debug mode
-func setupLogger(debug bool) *slog.Logger {
-	level := slog.LevelInfo
+// verifyExpectations checks the call expectations of routes, returning an
+// error when any wasn't met
+func verifyExpectations(srv *server.Server, logger *slog.Logger) error {
+	unmet := 0
+	for _, result := range srv.VerifyExpectations() {
+		if result.Met {
+			continue
+		}
+		unmet++
+		logger.Error("route expectation not met",
+			"route", result.Route,
+			"expected", result.Expected,
+			"calls", result.Calls,
+			"problems", result.Problems,
+		)
+	}
+
+	if unmet > 0 {
+		return fmt.Errorf("expectations of %d routes were not met", unmet)
+	}
+
+	logger.Info("all route expectations met")
+	return nil
+}
+
+// setupLogger configures structured logging based on debug mode. The level
+// starts at debug or info and can be changed later through level.
+func setupLogger(level *slog.LevelVar, debug bool) *slog.Logger {
+	level.Set(slog.LevelInfo)
 	if debug {
-		level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	}
 
 	opts := &slog.HandlerOptions{
@@ -131,61 +207,3 @@ func setupLogger(debug bool) *slog.Logger {
 
 	return logger
 }
-
-// startConfigWatcher starts a file watcher to monitor config changes for hot-reload
-func startConfigWatcher(configFile string, srv *server.Server, logger *slog.Logger, ctx context.Context) error {
-	// Create file watcher
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
-	}
-
-	// Add config file to watcher
-	if err := watcher.Add(configFile); err != nil {
-		_ = watcher.Close() // Error ignored - returning original error is more important
-		return fmt.Errorf("failed to watch config file %q: %w", configFile, err)
-	}
-
-	logger.Info("config file watcher started", "file", configFile)
-
-	// Start watcher in background goroutine
-	go func() {
-		defer func() {
-			if closeErr := watcher.Close(); closeErr != nil {
-				logger.Error("failed to close config watcher", "error", closeErr)
-			}
-		}()
-
-		for {
-			select {
-			case <-ctx.Done():
-				logger.Debug("config watcher stopping due to context cancellation")
-				return
-
-			case event, ok := <-watcher.Events:
-				if !ok {
-					logger.Debug("config watcher events channel closed")
-					return
-				}
-
-				// Only handle write events (file modifications)
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					logger.Info("config file changed, reloading", "file", event.Name)
-
-					if err := srv.ReloadConfig(); err != nil {
-						logger.Error("failed to reload config", "error", err)
-					}
-				}
-
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					logger.Debug("config watcher errors channel closed")
-					return
-				}
-				logger.Error("config file watcher error", "error", err)
-			}
-		}
-	}()
-
-	return nil
-}
@@ -0,0 +1,105 @@
+// Package matcher is the public registry of the kinds of request matchers
+// routes can use beyond their method, path and built-in match_* settings.
+// Each kind is registered under a name, and routes enable it under that name
+// in their "match" section, so new kinds, including ones built into a
+// mockingjay binary from outside this module, can be added without changing
+// how routes match.
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Matcher decides whether a route accepts a request whose method and path
+// already matched
+type Matcher interface {
+	Match(req *http.Request) bool
+}
+
+// Func adapts a function to a Matcher
+type Func func(req *http.Request) bool
+
+// Match calls f(req)
+func (f Func) Match(req *http.Request) bool {
+	return f(req)
+}
+
+// builder decodes the raw settings of a matcher and creates it
+type builder func(settings any) (Matcher, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]builder)
+)
+
+// Register makes a matcher kind available to routes under name. The route's
+// settings for it are decoded into a value of type C, rejecting unknown
+// fields, before being handed to build. Register panics if the name is empty
+// or already registered.
+func Register[C any](name string, build func(settings C) (Matcher, error)) {
+	if name == "" {
+		panic("matcher: Register called with an empty name")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("matcher: Register called twice for %q", name))
+	}
+
+	registry[name] = func(raw any) (Matcher, error) {
+		data, err := yaml.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		var settings C
+		if err := yaml.UnmarshalWithOptions(data, &settings, yaml.Strict()); err != nil {
+			return nil, fmt.Errorf("invalid settings: %s", yamlErrorMessage(err))
+		}
+		return build(settings)
+	}
+}
+
+// Registered returns the sorted names of all registered matcher kinds
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Build creates the matcher registered under name from a route's settings
+func Build(name string, settings any) (Matcher, error) {
+	registryMu.RLock()
+	build, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown matcher %q, available matchers: %s", name, strings.Join(Registered(), ", "))
+	}
+	return build(settings)
+}
+
+// yamlErrorMessage returns the message of a YAML decoding error without the
+// source excerpt
+func yamlErrorMessage(err error) string {
+	var yamlErr yaml.Error
+	if errors.As(err, &yamlErr) {
+		return yamlErr.GetMessage()
+	}
+	return err.Error()
+}
@@ -0,0 +1,88 @@
+package matcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// unregister removes a matcher kind registered by a test
+func unregister(t *testing.T, name string) {
+	t.Helper()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, name)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	type methodSettings struct {
+		Methods []string `yaml:"methods"`
+	}
+
+	Register("test_methods", func(settings methodSettings) (Matcher, error) {
+		return Func(func(req *http.Request) bool {
+			return slices.Contains(settings.Methods, req.Method)
+		}), nil
+	})
+	unregister(t, "test_methods")
+
+	if !slices.Contains(Registered(), "test_methods") {
+		t.Fatalf("Expected test_methods to be registered, got %v", Registered())
+	}
+
+	m, err := Build("test_methods", map[string]any{"methods": []any{"GET", "HEAD"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !m.Match(httptest.NewRequest("HEAD", "/", nil)) || m.Match(httptest.NewRequest("POST", "/", nil)) {
+		t.Error("Expected the matcher to accept only GET and HEAD")
+	}
+
+	tests := []struct {
+		name        string
+		kind        string
+		settings    any
+		errContains string
+	}{
+		{name: "unknown field", kind: "test_methods", settings: map[string]any{"verbs": []any{"GET"}}, errContains: "invalid settings"},
+		{name: "wrong type", kind: "test_methods", settings: map[string]any{"methods": "GET"}, errContains: "invalid settings"},
+		{name: "unknown kind", kind: "geoip", errContains: `unknown matcher "geoip", available matchers: test_methods`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build(tt.kind, tt.settings)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	Register("test_taken", func(struct{}) (Matcher, error) { return nil, nil })
+	unregister(t, "test_taken")
+
+	tests := []struct {
+		name string
+		kind string
+	}{
+		{name: "empty name", kind: ""},
+		{name: "duplicate name", kind: "test_taken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected Register(%q) to panic", tt.kind)
+				}
+			}()
+			Register(tt.kind, func(struct{}) (Matcher, error) { return nil, nil })
+		})
+	}
+}
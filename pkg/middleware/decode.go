@@ -0,0 +1,351 @@
+package middleware
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// decodeConfig decodes a raw middleware config map into the typed config struct
+// pointed to by target. Wrong-typed values are reported against the dotted
+// path of the field that holds them, such as "paths.include", and keys that
+// don't match any field are rejected instead of silently ignored.
+func decodeConfig(middleware string, values map[string]interface{}, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("middleware %q config target must be a pointer to a struct, got %T", middleware, target)
+	}
+
+	// Configs built in Go rather than parsed from a file may hold typed values
+	// such as []string, which are brought back to their YAML shape first
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return NewConfigError(middleware, "", err.Error())
+	}
+	var normalized map[string]interface{}
+	if err := yaml.Unmarshal(data, &normalized); err != nil {
+		return NewConfigError(middleware, "", yamlErrorMessage(err))
+	}
+
+	d := &decoder{middleware: middleware}
+	if err := d.decodeStruct("", normalized, rv.Elem()); err != nil {
+		return err
+	}
+
+	if len(d.unknown) > 0 {
+		slices.Sort(d.unknown)
+		return NewConfigError(middleware, "", fmt.Sprintf("unknown field(s): %s", strings.Join(d.unknown, ", ")))
+	}
+
+	return nil
+}
+
+// decoder walks a raw config value alongside the Go value it's decoded into
+type decoder struct {
+	middleware string
+	unknown    []string // Fully qualified names of keys that match no field
+}
+
+// fail returns a ConfigError for field
+func (d *decoder) fail(field, format string, args ...interface{}) error {
+	return NewConfigError(d.middleware, field, fmt.Sprintf(format, args...))
+}
+
+// decodeStruct decodes the mapping values into the struct dst, whose fields
+// are named under prefix
+func (d *decoder) decodeStruct(prefix string, values map[string]interface{}, dst reflect.Value) error {
+	fields := yamlFieldIndexes(dst.Type())
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+
+		index, ok := fields[key]
+		if !ok {
+			d.unknown = append(d.unknown, field)
+			continue
+		}
+
+		if err := d.decode(field, values[key], dst.Field(index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decode decodes value into dst, reporting problems against field. A null
+// value leaves dst untouched.
+func (d *decoder) decode(field string, value interface{}, dst reflect.Value) error {
+	if value == nil {
+		return nil
+	}
+
+	if dst.Type() == durationType {
+		duration, err := parseDuration(value)
+		if err != nil {
+			return d.fail(field, "%s", err.Error())
+		}
+		dst.SetInt(int64(duration))
+		return nil
+	}
+
+	if hasCustomDecoding(dst.Type()) {
+		return d.decodeYAML(field, value, dst)
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return d.fail(field, "expected a string, got %s", describeType(value))
+		}
+		dst.SetString(s)
+
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return d.fail(field, "expected a boolean, got %s", describeType(value))
+		}
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt64(value)
+		if !ok {
+			return d.fail(field, "expected an integer, got %s", describeType(value))
+		}
+		if dst.OverflowInt(n) {
+			return d.fail(field, "%v is out of range", value)
+		}
+		dst.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toInt64(value)
+		if !ok {
+			return d.fail(field, "expected an integer, got %s", describeType(value))
+		}
+		if n < 0 {
+			return d.fail(field, "cannot be negative")
+		}
+		if dst.OverflowUint(uint64(n)) {
+			return d.fail(field, "%v is out of range", value)
+		}
+		dst.SetUint(uint64(n))
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		default:
+			n, ok := toInt64(value)
+			if !ok {
+				return d.fail(field, "expected a number, got %s", describeType(value))
+			}
+			f = float64(n)
+		}
+		dst.SetFloat(f)
+
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		isStrings := dst.Type().Elem().Kind() == reflect.String
+		if !ok {
+			if isStrings {
+				return d.fail(field, "expected a list of strings, got %s", describeType(value))
+			}
+			return d.fail(field, "expected a list, got %s", describeType(value))
+		}
+
+		list := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if isStrings {
+				if _, ok := item.(string); !ok {
+					return d.fail(field, "expected item %d to be a string, got %s", i, describeType(item))
+				}
+			}
+			if err := d.decode(fmt.Sprintf("%s[%d]", field, i), item, list.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(list)
+
+	case reflect.Map:
+		values, ok := value.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return d.fail(field, "expected a mapping, got %s", describeType(value))
+		}
+
+		mapping := reflect.MakeMapWithSize(dst.Type(), len(values))
+		for key, item := range values {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := d.decode(field+"."+key, item, elem); err != nil {
+				return err
+			}
+			mapping.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(mapping)
+
+	case reflect.Struct:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return d.fail(field, "expected a mapping, got %s", describeType(value))
+		}
+		return d.decodeStruct(field, values, dst)
+
+	case reflect.Pointer:
+		elem := reflect.New(dst.Type().Elem())
+		if err := d.decode(field, value, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+
+	case reflect.Interface:
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(dst.Type()) {
+			return d.fail(field, "unexpected %s", describeType(value))
+		}
+		dst.Set(v)
+
+	default:
+		return d.fail(field, "unsupported config field type %s", dst.Type())
+	}
+
+	return nil
+}
+
+// hasCustomDecoding reports whether values of type t decode themselves
+func hasCustomDecoding(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(reflect.TypeFor[yaml.BytesUnmarshaler]()) ||
+		p.Implements(reflect.TypeFor[yaml.InterfaceUnmarshaler]()) ||
+		p.Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+// decodeYAML hands value to the YAML decoder, for types that decode themselves
+func (d *decoder) decodeYAML(field string, value interface{}, dst reflect.Value) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return d.fail(field, "%s", err.Error())
+	}
+	if err := yaml.UnmarshalWithOptions(data, dst.Addr().Interface(), yaml.Strict()); err != nil {
+		return d.fail(field, "%s", yamlErrorMessage(err))
+	}
+	return nil
+}
+
+// yamlFieldIndexes maps the YAML name of every exported struct field to its index
+func yamlFieldIndexes(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = i
+	}
+	return fields
+}
+
+// yamlErrorMessage returns the message of a YAML decoding error without the
+// source snippet, which would point into a re-encoded fragment of the config
+func yamlErrorMessage(err error) string {
+	var yamlErr yaml.Error
+	if errors.As(err, &yamlErr) {
+		return yamlErr.GetMessage()
+	}
+	return err.Error()
+}
+
+// parseDuration parses a duration given either as a Go duration string
+// ("30s", "1h") or as a number of seconds
+func parseDuration(value interface{}) (time.Duration, error) {
+	var parsed time.Duration
+	switch v := value.(type) {
+	case string:
+		var err error
+		parsed, err = time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use a duration string like \"30s\" or a number of seconds", v)
+		}
+	case int:
+		parsed = time.Duration(v) * time.Second
+	case int64:
+		parsed = time.Duration(v) * time.Second
+	case uint64:
+		if v > math.MaxInt64/uint64(time.Second) {
+			return 0, fmt.Errorf("duration of %d seconds is too large", v)
+		}
+		parsed = time.Duration(v) * time.Second
+	case float64:
+		parsed = time.Duration(v * float64(time.Second))
+	default:
+		return 0, fmt.Errorf("expected a duration string or number of seconds, got %s", describeType(value))
+	}
+
+	if parsed < 0 {
+		return 0, fmt.Errorf("duration cannot be negative")
+	}
+	return parsed, nil
+}
+
+// toInt64 returns value as an int64 if it's a whole number that fits one
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// describeType names the YAML type of a decoded config value for error messages
+func describeType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "mapping"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
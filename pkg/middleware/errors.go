@@ -0,0 +1,26 @@
+package middleware
+
+import "fmt"
+
+// ConfigError represents an invalid value in a middleware configuration
+type ConfigError struct {
+	Middleware string // The middleware type being configured
+	Field      string // The configuration field that failed validation
+	Message    string // Human-readable error message
+}
+
+func (e *ConfigError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("invalid %s middleware config field %q: %s", e.Middleware, e.Field, e.Message)
+	}
+	return fmt.Sprintf("invalid %s middleware config: %s", e.Middleware, e.Message)
+}
+
+// NewConfigError creates a new ConfigError
+func NewConfigError(middleware, field, message string) *ConfigError {
+	return &ConfigError{
+		Middleware: middleware,
+		Field:      field,
+		Message:    message,
+	}
+}
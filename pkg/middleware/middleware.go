@@ -0,0 +1,15 @@
+// Package middleware is the public registry of middleware types that can be
+// enabled from a mockingjay configuration file. Code built into a mockingjay
+// binary adds its own types by calling Register from an init function.
+package middleware
+
+import "net/http"
+
+// Middleware represents a configurable middleware component
+type Middleware interface {
+	// Name returns the middleware name for logging and identification
+	Name() string
+
+	// Handler returns the standard Go middleware handler
+	Handler() func(http.Handler) http.Handler
+}
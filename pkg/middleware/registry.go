@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// builder decodes a raw middleware config map and creates the middleware
+type builder func(values map[string]interface{}, logger *slog.Logger) (Middleware, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]builder)
+)
+
+// Register makes a middleware type available to configuration files under name.
+// The middleware's YAML config is decoded into a value of type C, which must be
+// a struct with yaml tags, before being handed to build. Register panics if the
+// name is empty or already registered.
+func Register[C any](name string, build func(config C, logger *slog.Logger) (Middleware, error)) {
+	if name == "" {
+		panic("middleware: Register called with an empty name")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("middleware: Register called twice for %q", name))
+	}
+
+	registry[name] = func(values map[string]interface{}, logger *slog.Logger) (Middleware, error) {
+		var config C
+		if err := decodeConfig(name, values, &config); err != nil {
+			return nil, err
+		}
+		return build(config, logger)
+	}
+}
+
+// Registered returns the sorted names of all registered middleware types
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New decodes values into the config type registered under name and creates
+// the middleware from it
+func New(name string, values map[string]interface{}, logger *slog.Logger) (Middleware, error) {
+	registryMu.RLock()
+	build, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown middleware type %q, must be one of: %s", name, strings.Join(Registered(), ", "))
+	}
+	return build(values, logger)
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type echoMiddleware struct{}
+
+func (echoMiddleware) Name() string { return "test-echo" }
+
+func (echoMiddleware) Handler() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return next }
+}
+
+func TestRegister(t *testing.T) {
+	type echoConfig struct {
+		Message string        `yaml:"message"`
+		Delay   time.Duration `yaml:"delay"`
+		Retry   struct {
+			Codes []int `yaml:"codes"`
+		} `yaml:"retry"`
+	}
+
+	var received echoConfig
+	Register("test-echo", func(config echoConfig, _ *slog.Logger) (Middleware, error) {
+		received = config
+		return echoMiddleware{}, nil
+	})
+
+	if !slices.Contains(Registered(), "test-echo") {
+		t.Fatalf("Expected test-echo to be registered, got %v", Registered())
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	mw, err := New("test-echo", map[string]interface{}{
+		"message": "hello",
+		"delay":   2,
+		"retry":   map[string]interface{}{"codes": []int{502, 503}},
+	}, logger)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if mw.Name() != "test-echo" {
+		t.Errorf("Expected middleware name test-echo, got %q", mw.Name())
+	}
+	if received.Message != "hello" || received.Delay != 2*time.Second || !slices.Equal(received.Retry.Codes, []int{502, 503}) {
+		t.Errorf("Unexpected decoded config: %+v", received)
+	}
+
+	t.Run("wrong type in a nested list", func(t *testing.T) {
+		_, err := New("test-echo", map[string]interface{}{
+			"retry": map[string]interface{}{"codes": []interface{}{502, "soon"}},
+		}, logger)
+
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Fatalf("Expected a *ConfigError, got %T: %v", err, err)
+		}
+		if configErr.Field != "retry.codes[1]" || configErr.Message != "expected an integer, got string" {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("duplicate registration panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected Register to panic on duplicate name")
+			}
+		}()
+		Register("test-echo", func(config echoConfig, _ *slog.Logger) (Middleware, error) {
+			return nil, nil
+		})
+	})
+
+	t.Run("unknown type lists registered types", func(t *testing.T) {
+		_, err := New("nope", nil, logger)
+		if err == nil || !strings.Contains(err.Error(), "test-echo") {
+			t.Errorf("Expected unknown type error listing registered types, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/patrickdappollonio/mockingjay/internal/server"
+)
+
+// adminTokenEnv names the environment variable holding the admin API token
+// commands send, unless given through a flag
+const adminTokenEnv = "MOCKINGJAY_ADMIN_TOKEN"
+
+// createReportCommand builds the command reporting how often the routes of a
+// running server were called
+func createReportCommand() *cobra.Command {
+	var addr, token string
+	var unused bool
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report how often the routes of a running server were called",
+		Long: `Report how often each configured route of a running mockingjay server was
+called since it started, to find stale routes that can be pruned.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if token == "" {
+				token = os.Getenv(adminTokenEnv)
+			}
+
+			report, err := fetchUsageReport(addr, token, unused)
+			if err != nil {
+				// Errors are silenced by the root command, which logs its own
+				fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+				return err
+			}
+			return printUsageReport(cmd.OutOrStdout(), report, unused)
+		},
+	}
+
+	cmd.Flags().StringVarP(&addr, "addr", "a", "http://localhost:8080", "address of the running server, http:// is assumed without a scheme")
+	cmd.Flags().BoolVarP(&unused, "unused", "", false, "only list routes that were never called")
+	cmd.Flags().StringVarP(&token, "token", "t", "", "admin API token with the \"read\" scope, for servers protecting it (env "+adminTokenEnv+")")
+
+	return cmd
+}
+
+// fetchUsageReport asks the server at addr how often its routes were called,
+// authenticating with token when it's set
+func fetchUsageReport(addr, token string, unused bool) (*server.UsageReport, error) {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	endpoint, err := url.JoinPath(addr, "/__admin/routes/usage")
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if unused {
+		endpoint += "?unused=true"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("server answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var report server.UsageReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to read the usage report: %w", err)
+	}
+	return &report, nil
+}
+
+// printUsageReport writes a usage report as a table
+func printUsageReport(w io.Writer, report *server.UsageReport, unused bool) error {
+	since := report.Since.Format(time.RFC3339)
+	if unused {
+		fmt.Fprintf(w, "%d of %d routes were never called since %s\n", report.Unused, report.Total, since)
+	} else {
+		fmt.Fprintf(w, "%d routes, %d never called since %s\n", report.Total, report.Unused, since)
+	}
+	if len(report.Routes) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROUTE\tCALLS\tLAST CALLED")
+	for _, route := range report.Routes {
+		last := "never"
+		if route.LastCalled != nil {
+			last = route.LastCalled.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", route.Route, route.Calls, last)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/patrickdappollonio/mockingjay/internal/server"
+)
+
+// toggleDebugOnSignal switches the server's logs between the debug and info
+// levels every time the process gets SIGUSR1, until ctx is done
+func toggleDebugOnSignal(ctx context.Context, srv *server.Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			srv.ToggleDebug()
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+
+	"github.com/patrickdappollonio/mockingjay/internal/server"
+)
+
+// toggleDebugOnSignal does nothing on Windows, which has no SIGUSR1. The log
+// level can still be changed through the admin API.
+func toggleDebugOnSignal(_ context.Context, _ *server.Server) {}